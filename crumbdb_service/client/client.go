@@ -0,0 +1,349 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+const (
+	SERVICE_NAME    = "crumbdb_service"
+	DEFAULT_ADDRESS = "localhost:50051"
+
+	AUTHORIZATION_HEADER = "authorization"
+	BEARER_PREFIX        = "Bearer "
+
+	// VERSION_MISMATCH is the reason of the ErrorInfo of an Update or AppendToTrail at another version than the
+	// current one, CURRENT_VERSION_KEY the metadata key of the current version of the crumb or trail
+	VERSION_MISMATCH    = "VERSION_MISMATCH"
+	CURRENT_VERSION_KEY = "current_version"
+)
+
+// SERVICE_CONFIG balances calls across the resolved instances and retries the read methods while the service is
+// unavailable. Create is never retried since a retry could drop the crumb twice, nor are CreateTrail,
+// AppendToTrail and ImportCrumbs which could create the trail, append or import the crumbs twice, or UnlockCrumb
+// which could record the unlock twice. Update and Delete are not retried either, since a retry of a call that
+// already landed fails with Aborted or NotFound
+const SERVICE_CONFIG = `{
+	"loadBalancingConfig": [{"round_robin": {}}],
+	"methodConfig": [{
+		"name": [
+			{"service": "crumbdb.CrumbDB", "method": "GetCrumbs"},
+			{"service": "crumbdb.CrumbDB", "method": "GetCrumb"},
+			{"service": "crumbdb.CrumbDB", "method": "BatchGetCrumbs"},
+			{"service": "crumbdb.CrumbDB", "method": "ListCrumbsByUser"},
+			{"service": "crumbdb.CrumbDB", "method": "GetTrail"},
+			{"service": "crumbdb.CrumbDB", "method": "FindTrailsNear"},
+			{"service": "crumbdb.CrumbDB", "method": "Export"}
+		],
+		"retryPolicy": {
+			"maxAttempts": 4,
+			"initialBackoff": "0.1s",
+			"maxBackoff": "1s",
+			"backoffMultiplier": 2,
+			"retryableStatusCodes": ["UNAVAILABLE"]
+		}
+	}]
+}`
+
+// Crumb, Point, Id, Ids, Visibility, Trail, ExportRequest, ExportFormat, ImportCrumbsRequest, ImportReport and
+// UnlockCrumbResponse are the messages of the CrumbDB API
+type (
+	Crumb               = pb.Crumb
+	Point               = pb.Point
+	Id                  = pb.Id
+	Ids                 = pb.Ids
+	Visibility          = pb.Visibility
+	Trail               = pb.Trail
+	ExportRequest       = pb.ExportRequest
+	ExportFormat        = pb.ExportFormat
+	ImportCrumbsRequest = pb.ImportCrumbsRequest
+	ImportReport        = pb.ImportReport
+	UnlockCrumbResponse = pb.UnlockCrumbResponse
+
+	CrumbDBClient = pb.CrumbDBClient
+)
+
+// CrumbSeq yields the crumbs of a stream in order. A failed stream yields its error as the last element.
+// It has the shape of iter.Seq2 so it can be ranged over with Go 1.23 or later
+type CrumbSeq func(yield func(*Crumb, error) bool)
+
+// Client calls the CrumbDB API of the crumbdb service
+type Client struct {
+	conn *grpc.ClientConn
+	api  pb.CrumbDBClient
+}
+
+// New returns a Client connected to DEFAULT_ADDRESS unless configured otherwise by opts
+func New(opts ...Option) (*Client, error) {
+	o := &options{address: DEFAULT_ADDRESS}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	target := o.address
+	dialOptions := []grpc.DialOption{grpc.WithDefaultServiceConfig(SERVICE_CONFIG)}
+
+	if o.consul != "" {
+		builder, err := newConsulBuilder(o.consul)
+		if err != nil {
+			return nil, err
+		}
+		target = CONSUL_SCHEME + ":///" + SERVICE_NAME
+		dialOptions = append(dialOptions, grpc.WithResolvers(builder))
+	}
+
+	creds := o.creds
+	if creds == nil {
+		creds = insecure.NewCredentials()
+	}
+	dialOptions = append(dialOptions, grpc.WithTransportCredentials(creds))
+
+	if o.tokenSource != nil {
+		requireTLS := creds.Info().SecurityProtocol != insecure.NewCredentials().Info().SecurityProtocol
+		dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(&tokenCredentials{source: o.tokenSource, requireTLS: requireTLS}))
+	}
+
+	conn, err := grpc.NewClient(target, append(dialOptions, o.dialOptions...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %v: %v", SERVICE_NAME, err)
+	}
+
+	return &Client{
+		conn: conn,
+		api:  pb.NewCrumbDBClient(conn),
+	}, nil
+}
+
+// Close closes the connection of the Client
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// API returns the generated client for calls not wrapped by Client
+func (c *Client) API() CrumbDBClient {
+	return c.api
+}
+
+// Create drops crumb and returns its id
+func (c *Client) Create(ctx context.Context, crumb *Crumb) (string, error) {
+	id, err := c.api.Create(ctx, crumb)
+	if err != nil {
+		return "", err
+	}
+	return id.GetValue(), nil
+}
+
+// GetCrumbs returns the crumbs near point. The stream is opened when the sequence is iterated and closed
+// when the iteration stops
+func (c *Client) GetCrumbs(ctx context.Context, point *Point) CrumbSeq {
+	return func(yield func(*Crumb, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stream, err := c.api.GetCrumbs(ctx, point)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for {
+			crumb, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(crumb, nil) {
+				return
+			}
+		}
+	}
+}
+
+// GetCrumb returns the crumb with id
+func (c *Client) GetCrumb(ctx context.Context, id string) (*Crumb, error) {
+	return c.api.GetCrumb(ctx, &Id{Value: id})
+}
+
+// BatchGetCrumbs returns the crumbs with ids, in the order of ids. It fails with NotFound if any is missing
+func (c *Client) BatchGetCrumbs(ctx context.Context, ids []string) ([]*Crumb, error) {
+	crumbs, err := c.api.BatchGetCrumbs(ctx, &Ids{Values: ids})
+	if err != nil {
+		return nil, err
+	}
+	return crumbs.GetCrumbs(), nil
+}
+
+// ListCrumbsByUser returns the crumbs of user, newest first. The pages of pageSize crumbs, or the default size
+// of the service if it is zero, are requested as the sequence is iterated
+func (c *Client) ListCrumbsByUser(ctx context.Context, user string, pageSize int32) CrumbSeq {
+	return func(yield func(*Crumb, error) bool) {
+		req := &pb.ListCrumbsByUserRequest{User: user, PageSize: pageSize}
+		for {
+			page, err := c.api.ListCrumbsByUser(ctx, req)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			for _, crumb := range page.GetCrumbs() {
+				if !yield(crumb, nil) {
+					return
+				}
+			}
+			if page.GetNextPageToken() == "" {
+				return
+			}
+			req.PageToken = page.GetNextPageToken()
+		}
+	}
+}
+
+// Update changes the fields in paths of the crumb with the id of crumb, only its message if there are none, and
+// returns the updated crumb. The update fails with Aborted unless the version of crumb is the current version,
+// or with FailedPrecondition if it is zero and the crumb is versioned, see CurrentVersion. It is not retried
+func (c *Client) Update(ctx context.Context, crumb *Crumb, paths ...string) (*Crumb, error) {
+	req := &pb.UpdateCrumbRequest{Crumb: crumb}
+	if len(paths) > 0 {
+		req.UpdateMask = &fieldmaskpb.FieldMask{Paths: paths}
+	}
+	return c.api.Update(ctx, req)
+}
+
+// CurrentVersion returns the current version of the crumb or trail carried by the error of an Update or
+// AppendToTrail at another version
+func CurrentVersion(err error) (int64, bool) {
+	st, ok := status.FromError(err)
+	if !ok || (st.Code() != codes.Aborted && st.Code() != codes.FailedPrecondition) {
+		return 0, false
+	}
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.GetReason() != VERSION_MISMATCH {
+			continue
+		}
+		version, err := strconv.ParseInt(info.GetMetadata()[CURRENT_VERSION_KEY], 10, 64)
+		return version, err == nil
+	}
+	return 0, false
+}
+
+// UnlockCrumb returns the crumb with id, with its message if it is not locked or position is within its unlock
+// radius, and the distance to it. It fails with FailedPrecondition if position is too far. It is not retried
+// since each unlock is recorded
+func (c *Client) UnlockCrumb(ctx context.Context, id string, position *Point) (*UnlockCrumbResponse, error) {
+	return c.api.UnlockCrumb(ctx, &pb.UnlockCrumbRequest{Id: id, Position: position})
+}
+
+// Delete removes the crumb with id, and removes it from the trails containing it. It is not retried
+func (c *Client) Delete(ctx context.Context, id string) error {
+	_, err := c.api.Delete(ctx, &Id{Value: id})
+	return err
+}
+
+// CreateTrail creates a trail of user through the crumbs with ids, in order, and returns it
+func (c *Client) CreateTrail(ctx context.Context, user string, name string, ids ...string) (*Trail, error) {
+	return c.api.CreateTrail(ctx, &Trail{User: user, Name: name, CrumbIds: ids})
+}
+
+// AppendToTrail appends the crumbs with ids to the trail with id and returns it. If version is not zero it
+// fails with Aborted unless it is the current version of the trail, see CurrentVersion
+func (c *Client) AppendToTrail(ctx context.Context, id string, version int64, ids ...string) (*Trail, error) {
+	return c.api.AppendToTrail(ctx, &pb.AppendToTrailRequest{TrailId: id, CrumbIds: ids, Version: version})
+}
+
+// GetTrail returns the trail with id
+func (c *Client) GetTrail(ctx context.Context, id string) (*Trail, error) {
+	return c.api.GetTrail(ctx, &Id{Value: id})
+}
+
+// FindTrailsNear returns the trails passing within radius meters of a Point, or the default radius of the
+// service if it is zero, or through a Polygon
+func (c *Client) FindTrailsNear(ctx context.Context, location *Point, radius float64) ([]*Trail, error) {
+	trails, err := c.api.FindTrailsNear(ctx, &pb.FindTrailsNearRequest{Location: location, Radius: radius})
+	if err != nil {
+		return nil, err
+	}
+	return trails.GetTrails(), nil
+}
+
+// Export writes the document exported for req to w as its chunks are received and returns its content type.
+// A failed export may have written part of the document
+func (c *Client) Export(ctx context.Context, req *ExportRequest, w io.Writer) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.api.Export(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	var contentType string
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return contentType, nil
+		}
+		if err != nil {
+			return contentType, err
+		}
+		if chunk.GetContentType() != "" {
+			contentType = chunk.GetContentType()
+		}
+		if _, err := w.Write(chunk.GetData()); err != nil {
+			return contentType, fmt.Errorf("failed to write export: %v", err)
+		}
+	}
+}
+
+// ImportCrumbs imports the records, of user if they have none, and returns the report of which were accepted.
+// A dry run validates the records without inserting them. The options of the import are set on the first record
+func (c *Client) ImportCrumbs(ctx context.Context, user string, dryRun bool, records ...*ImportCrumbsRequest) (*ImportReport, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.api.ImportCrumbs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, record := range records {
+		if i == 0 {
+			record = &ImportCrumbsRequest{Record: record.GetRecord(), User: user, DryRun: dryRun}
+		}
+		// the error of a failed send is returned by CloseAndRecv
+		if err := stream.Send(record); err != nil {
+			break
+		}
+	}
+	return stream.CloseAndRecv()
+}
+
+// Collect returns all crumbs of the sequence, or the error that ended it
+func (s CrumbSeq) Collect() ([]*Crumb, error) {
+	var crumbs []*Crumb
+	var seqErr error
+	s(func(crumb *Crumb, err error) bool {
+		if err != nil {
+			seqErr = err
+			return false
+		}
+		crumbs = append(crumbs, crumb)
+		return true
+	})
+
+	return crumbs, seqErr
+}
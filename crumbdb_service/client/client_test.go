@@ -0,0 +1,431 @@
+package client
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/haguru/horus/crumbdb/config"
+	"github.com/haguru/horus/crumbdb/internal/routes"
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces/mocks"
+	appMetrics "github.com/haguru/horus/crumbdb/pkg/prometheus"
+	"github.com/haguru/horus/crumbdb/pkg/security"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// TEST_USER is the user the unary calls to the testServer are made by, as a client certificate would tell
+const TEST_USER = "user_1"
+
+// testServer hosts a real Route on a bufconn listener. The first call of every method in unavailable fails with
+// UNAVAILABLE and the authorization metadata of every call is recorded
+type testServer struct {
+	mu            sync.Mutex
+	unavailable   map[string]bool
+	attempts      map[string]int
+	authorization []string
+}
+
+func (s *testServer) intercept(ctx context.Context, method string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.authorization = append(s.authorization, md.Get(AUTHORIZATION_HEADER)...)
+
+	s.attempts[method]++
+	if s.unavailable[method] && s.attempts[method] == 1 {
+		return status.Error(codes.Unavailable, "unavailable")
+	}
+	return nil
+}
+
+// authenticate is the unary interceptor making every call as TEST_USER
+func authenticate(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(security.ContextWithIdentity(ctx, &security.Identity{Subject: TEST_USER}), req)
+}
+
+func (s *testServer) calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts[method]
+}
+
+func newTestClient(t *testing.T, dbClient *mocks.Client, unavailable []string, opts ...Option) (*Client, *testServer) {
+	t.Helper()
+
+	ts := &testServer{unavailable: map[string]bool{}, attempts: map[string]int{}}
+	for _, method := range unavailable {
+		ts.unavailable[method] = true
+	}
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := ts.intercept(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return authenticate(ctx, req, info, handler)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := ts.intercept(ss.Context(), info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)
+	route := routes.NewRoute(logger.NewMockClient(), &config.Database{DatabaseName: "test", Collection: "test"}, dbClient,
+		appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"}), validator.New(), nil, nil, nil, nil)
+	pb.RegisterCrumbDBServer(server, route)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	opts = append([]Option{
+		WithAddress("passthrough:///bufnet"),
+		WithDialOptions(grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) })),
+	}, opts...)
+	c, err := New(opts...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	return c, ts
+}
+
+func TestClient(t *testing.T) {
+	point := &Point{Type: "Point", Coordinates: []float64{-122.4, 37.8}}
+	docs := []bson.D{
+		{{Key: "id", Value: "1"}, {Key: "user", Value: "user_1"}},
+		{{Key: "id", Value: "2"}, {Key: "user", Value: "user_2"}},
+		{{Key: "id", Value: "3"}, {Key: "user", Value: "user_3"}},
+	}
+
+	owned := []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()}
+	var ownedDocs []bson.D
+	for i, id := range owned {
+		ownedDocs = append(ownedDocs, bson.D{{Key: "_id", Value: id}, {Key: "user", Value: "user_1"}, {Key: "created_at", Value: int64(3000 - i)}})
+	}
+
+	tests := []struct {
+		name        string
+		unavailable []string
+		setup       func(dbClient *mocks.Client)
+		call        func(t *testing.T, c *Client) error
+		wantErr     bool
+		wantCode    codes.Code
+		wantCalls   map[string]int
+	}{
+		{
+			name: "create",
+			setup: func(dbClient *mocks.Client) {
+				dbClient.On("InsertRecord", mock.Anything, "test", "test", mock.Anything).Return("42", nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				id, err := c.Create(context.Background(), &Crumb{Location: point, User: "user_1", Message: "hi"})
+				if id != "42" {
+					t.Errorf("Create() = %v, want 42", id)
+				}
+				return err
+			},
+		},
+		{
+			name:        "create is not retried",
+			unavailable: []string{"/crumbdb.CrumbDB/Create"},
+			setup:       func(dbClient *mocks.Client) {},
+			call: func(t *testing.T, c *Client) error {
+				_, err := c.Create(context.Background(), &Crumb{Location: point, User: "user_1", Message: "hi"})
+				return err
+			},
+			wantErr:   true,
+			wantCode:  codes.Unavailable,
+			wantCalls: map[string]int{"/crumbdb.CrumbDB/Create": 1},
+		},
+		{
+			name: "get crumbs collects the stream",
+			setup: func(dbClient *mocks.Client) {
+				dbClient.On("SpaitalQuery", mock.Anything, "Point", point.Coordinates, "test", "test").Return(docs, nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				crumbs, err := c.GetCrumbs(context.Background(), point).Collect()
+				if len(crumbs) != len(docs) {
+					t.Errorf("Collect() returned %v crumbs, want %v", len(crumbs), len(docs))
+				}
+				return err
+			},
+		},
+		{
+			name:        "get crumbs stops early and is retried",
+			unavailable: []string{"/crumbdb.CrumbDB/GetCrumbs"},
+			setup: func(dbClient *mocks.Client) {
+				dbClient.On("SpaitalQuery", mock.Anything, "Point", point.Coordinates, "test", "test").Return(docs, nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				var users []string
+				var seqErr error
+				c.GetCrumbs(context.Background(), point)(func(crumb *Crumb, err error) bool {
+					if err != nil {
+						seqErr = err
+						return false
+					}
+					users = append(users, crumb.GetUser())
+					return len(users) < 2
+				})
+				if len(users) != 2 || users[0] != "user_1" || users[1] != "user_2" {
+					t.Errorf("users = %v, want [user_1 user_2]", users)
+				}
+				return seqErr
+			},
+			wantCalls: map[string]int{"/crumbdb.CrumbDB/GetCrumbs": 2},
+		},
+		{
+			name:  "get crumbs yields the error of the stream",
+			setup: func(dbClient *mocks.Client) {},
+			call: func(t *testing.T, c *Client) error {
+				_, err := c.GetCrumbs(context.Background(), &Point{}).Collect()
+				return err
+			},
+			wantErr:  true,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:        "get crumb is retried",
+			unavailable: []string{"/crumbdb.CrumbDB/GetCrumb"},
+			setup: func(dbClient *mocks.Client) {
+				dbClient.On("FindOne", mock.Anything, "test", "test", owned[0].Hex()).Return(&bson.D{{Key: "_id", Value: owned[0]}, {Key: "user", Value: "user_1"}}, nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				crumb, err := c.GetCrumb(context.Background(), owned[0].Hex())
+				if crumb.GetId() != owned[0].Hex() || crumb.GetUser() != "user_1" {
+					t.Errorf("GetCrumb() = %v, want the crumb of user_1", crumb)
+				}
+				return err
+			},
+			wantCalls: map[string]int{"/crumbdb.CrumbDB/GetCrumb": 2},
+		},
+		{
+			name: "batch get crumbs",
+			setup: func(dbClient *mocks.Client) {
+				dbClient.On("FindMany", mock.Anything, "test", "test", []string{owned[1].Hex(), owned[0].Hex()}).Return(ownedDocs[:2], nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				crumbs, err := c.BatchGetCrumbs(context.Background(), []string{owned[1].Hex(), owned[0].Hex()})
+				if len(crumbs) != 2 || crumbs[0].GetId() != owned[1].Hex() || crumbs[1].GetId() != owned[0].Hex() {
+					t.Errorf("BatchGetCrumbs() = %v, want the crumbs in the order of the ids", crumbs)
+				}
+				return err
+			},
+		},
+		{
+			name: "list crumbs by user requests the next pages",
+			setup: func(dbClient *mocks.Client) {
+				dbClient.On("FindByUser", mock.Anything, "test", "test", "user_1", (*interfaces.Cursor)(nil), 3).Return(ownedDocs, nil)
+				dbClient.On("FindByUser", mock.Anything, "test", "test", "user_1", &interfaces.Cursor{Created: 2999, Id: owned[1].Hex()}, 3).Return(ownedDocs[2:], nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				crumbs, err := c.ListCrumbsByUser(context.Background(), "user_1", 2).Collect()
+				if len(crumbs) != len(ownedDocs) {
+					t.Errorf("Collect() returned %v crumbs, want %v", len(crumbs), len(ownedDocs))
+				}
+				return err
+			},
+			wantCalls: map[string]int{"/crumbdb.CrumbDB/ListCrumbsByUser": 2},
+		},
+		{
+			name: "update",
+			setup: func(dbClient *mocks.Client) {
+				dbClient.On("FindOne", mock.Anything, "test", "test", owned[0].Hex()).Return(&ownedDocs[0], nil)
+				dbClient.On("Update", mock.Anything, "test", "test", owned[0].Hex(), int64(0), map[string]interface{}{"message": "bye"}).
+					Return(&bson.D{{Key: "_id", Value: owned[0]}, {Key: "message", Value: "bye"}, {Key: "version", Value: int64(2)}}, nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				crumb, err := c.Update(context.Background(), &Crumb{Id: owned[0].Hex(), Message: "bye"})
+				if crumb.GetMessage() != "bye" || crumb.GetVersion() != 2 {
+					t.Errorf("Update() = %v, want the crumb at version 2", crumb)
+				}
+				return err
+			},
+		},
+		{
+			name:        "update is not retried",
+			unavailable: []string{"/crumbdb.CrumbDB/Update"},
+			setup:       func(dbClient *mocks.Client) {},
+			call: func(t *testing.T, c *Client) error {
+				_, err := c.Update(context.Background(), &Crumb{Id: owned[0].Hex(), Message: "bye"})
+				return err
+			},
+			wantErr:   true,
+			wantCode:  codes.Unavailable,
+			wantCalls: map[string]int{"/crumbdb.CrumbDB/Update": 1},
+		},
+		{
+			name: "update of a stale version",
+			setup: func(dbClient *mocks.Client) {
+				dbClient.On("Update", mock.Anything, "test", "test", owned[0].Hex(), int64(1), map[string]interface{}{"tags": []string{"a"}}).
+					Return(nil, interfaces.ErrVersionMismatch)
+				dbClient.On("FindOne", mock.Anything, "test", "test", owned[0].Hex()).
+					Return(&bson.D{{Key: "_id", Value: owned[0]}, {Key: "user", Value: "user_1"}, {Key: "version", Value: int64(3)}}, nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				_, err := c.Update(context.Background(), &Crumb{Id: owned[0].Hex(), Tags: []string{"a"}, Version: 1}, "tags")
+				if version, ok := CurrentVersion(err); !ok || version != 3 {
+					t.Errorf("CurrentVersion() = %v, %v, want 3, true", version, ok)
+				}
+				return err
+			},
+			wantErr:  true,
+			wantCode: codes.Aborted,
+		},
+		{
+			name:        "delete is not retried",
+			unavailable: []string{"/crumbdb.CrumbDB/Delete"},
+			setup:       func(dbClient *mocks.Client) {},
+			call: func(t *testing.T, c *Client) error {
+				return c.Delete(context.Background(), "42")
+			},
+			wantErr:   true,
+			wantCode:  codes.Unavailable,
+			wantCalls: map[string]int{"/crumbdb.CrumbDB/Delete": 1},
+		},
+		{
+			name: "create trail",
+			setup: func(dbClient *mocks.Client) {
+				dbClient.On("FindMany", mock.Anything, "test", "test", []string{owned[0].Hex()}).
+					Return([]bson.D{{{Key: "_id", Value: owned[0]}, {Key: "user", Value: "user_1"}, {Key: "location", Value: point}}}, nil)
+				dbClient.On("InsertRecord", mock.Anything, "test", routes.DEFAULT_TRAIL_COLLECTION, mock.Anything).Return(owned[1].Hex(), nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				trail, err := c.CreateTrail(context.Background(), "user_1", "walk", owned[0].Hex())
+				if trail.GetId() != owned[1].Hex() || trail.GetName() != "walk" || trail.GetPath().GetType() != "Point" {
+					t.Errorf("CreateTrail() = %v, want the trail through the crumb", trail)
+				}
+				return err
+			},
+		},
+		{
+			name:        "find trails near is retried",
+			unavailable: []string{"/crumbdb.CrumbDB/FindTrailsNear"},
+			setup: func(dbClient *mocks.Client) {
+				dbClient.On("SpatialIntersects", mock.Anything, "test", routes.DEFAULT_TRAIL_COLLECTION, "Polygon", mock.Anything).
+					Return([]bson.D{{{Key: "_id", Value: owned[1]}, {Key: "name", Value: "walk"}}}, nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				trails, err := c.FindTrailsNear(context.Background(), point, 0)
+				if len(trails) != 1 || trails[0].GetName() != "walk" {
+					t.Errorf("FindTrailsNear() = %v, want the walk", trails)
+				}
+				return err
+			},
+			wantCalls: map[string]int{"/crumbdb.CrumbDB/FindTrailsNear": 2},
+		},
+		{
+			name:        "unlock crumb is not retried",
+			unavailable: []string{"/crumbdb.CrumbDB/UnlockCrumb"},
+			setup:       func(dbClient *mocks.Client) {},
+			call: func(t *testing.T, c *Client) error {
+				_, err := c.UnlockCrumb(context.Background(), owned[0].Hex(), point)
+				return err
+			},
+			wantErr:   true,
+			wantCode:  codes.Unavailable,
+			wantCalls: map[string]int{"/crumbdb.CrumbDB/UnlockCrumb": 1},
+		},
+		{
+			name: "unlock crumb",
+			setup: func(dbClient *mocks.Client) {
+				dbClient.On("FindOne", mock.Anything, "test", "test", owned[0].Hex()).
+					Return(&bson.D{{Key: "_id", Value: owned[0]}, {Key: "user", Value: "user_1"}, {Key: "message", Value: "hi"}, {Key: "location", Value: point}}, nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				unlocked, err := c.UnlockCrumb(context.Background(), owned[0].Hex(), point)
+				if unlocked.GetCrumb().GetMessage() != "hi" || unlocked.GetDistance() != 0 {
+					t.Errorf("UnlockCrumb() = %v, want the crumb at 0 meters", unlocked)
+				}
+				return err
+			},
+		},
+		{
+			name: "import crumbs",
+			setup: func(dbClient *mocks.Client) {
+				dbClient.On("InsertMany", mock.Anything, "test", "test", mock.Anything).Return([]string{owned[0].Hex()}, nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				report, err := c.ImportCrumbs(context.Background(), "user_1", false,
+					&ImportCrumbsRequest{Record: &pb.ImportCrumbsRequest_Waypoint{Waypoint: `<wpt lat="37.8" lon="-122.4"><name>hi</name></wpt>`}},
+					&ImportCrumbsRequest{Record: &pb.ImportCrumbsRequest_Feature{Feature: `{"type":"Feature"}`}},
+				)
+				if report.GetAccepted() != 1 || report.GetRejected() != 1 || report.GetResults()[0].GetId() != owned[0].Hex() {
+					t.Errorf("ImportCrumbs() = %v, want the waypoint imported and the feature rejected", report)
+				}
+				return err
+			},
+		},
+		{
+			name:        "export is retried",
+			unavailable: []string{"/crumbdb.CrumbDB/Export"},
+			setup: func(dbClient *mocks.Client) {
+				dbClient.On("SpaitalQuery", mock.Anything, "Point", point.GetCoordinates(), "test", "test").
+					Return([]bson.D{{{Key: "_id", Value: owned[0]}, {Key: "user", Value: "user_1"}, {Key: "location", Value: point}}}, nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				var buf strings.Builder
+				contentType, err := c.Export(context.Background(), &ExportRequest{Location: point, Format: pb.ExportFormat_EXPORT_FORMAT_KML}, &buf)
+				if contentType != "application/vnd.google-earth.kml+xml" || !strings.Contains(buf.String(), "<coordinates>-122.4,37.8</coordinates>") {
+					t.Errorf("Export() = %v, %v, want the kml of the crumb", contentType, buf.String())
+				}
+				return err
+			},
+			wantCalls: map[string]int{"/crumbdb.CrumbDB/Export": 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbClient := mocks.NewClient(t)
+			tt.setup(dbClient)
+			c, ts := newTestClient(t, dbClient, tt.unavailable)
+
+			err := tt.call(t, c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && status.Code(err) != tt.wantCode {
+				t.Errorf("code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+			for method, want := range tt.wantCalls {
+				if got := ts.calls(method); got != want {
+					t.Errorf("%v called %v times, want %v", method, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithToken(t *testing.T) {
+	dbClient := mocks.NewClient(t)
+	dbClient.On("FindOne", mock.Anything, "test", "test", "42").Return(&bson.D{{Key: "_id", Value: "42"}, {Key: "user", Value: TEST_USER}}, nil)
+	dbClient.On("FindByField", mock.Anything, "test", routes.DEFAULT_TRAIL_COLLECTION, routes.TRAIL_CRUMB_IDS_KEY, "42").Return(nil, nil)
+	dbClient.On("Delete", mock.Anything, "test", "test", "42").Return(nil)
+	c, ts := newTestClient(t, dbClient, nil, WithToken("secret"))
+
+	if err := c.Delete(context.Background(), "42"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if len(ts.authorization) != 1 || ts.authorization[0] != BEARER_PREFIX+"secret" {
+		t.Errorf("authorization = %v, want [%vsecret]", ts.authorization, BEARER_PREFIX)
+	}
+}
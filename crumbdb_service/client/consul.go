@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"google.golang.org/grpc/resolver"
+)
+
+const (
+	CONSUL_SCHEME = "consul"
+
+	CONSUL_WAIT_TIME      = 5 * time.Minute
+	CONSUL_RETRY_INTERVAL = 5 * time.Second
+)
+
+// consulBuilder resolves consul:///<service> targets to the healthy instances of the service
+type consulBuilder struct {
+	client *consulapi.Client
+}
+
+func newConsulBuilder(address string) (*consulBuilder, error) {
+	client, err := consulapi.NewClient(&consulapi.Config{Address: address})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consul client: %v", err)
+	}
+	return &consulBuilder{client: client}, nil
+}
+
+func (b *consulBuilder) Scheme() string {
+	return CONSUL_SCHEME
+}
+
+func (b *consulBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &consulResolver{
+		client:  b.client,
+		service: target.Endpoint(),
+		cc:      cc,
+		cancel:  cancel,
+	}
+	go r.watch(ctx)
+
+	return r, nil
+}
+
+type consulResolver struct {
+	client  *consulapi.Client
+	service string
+	cc      resolver.ClientConn
+	cancel  context.CancelFunc
+}
+
+// ResolveNow does nothing, watch already follows every change of the instances
+func (r *consulResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (r *consulResolver) Close() {
+	r.cancel()
+}
+
+// watch updates the addresses of cc every time the healthy instances of the service change until ctx is done
+func (r *consulResolver) watch(ctx context.Context) {
+	var waitIndex uint64
+	for {
+		opts := (&consulapi.QueryOptions{WaitIndex: waitIndex, WaitTime: CONSUL_WAIT_TIME}).WithContext(ctx)
+		entries, meta, err := r.client.Health().Service(r.service, "", true, opts)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			r.cc.ReportError(fmt.Errorf("failed to resolve %v in consul: %v", r.service, err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(CONSUL_RETRY_INTERVAL):
+				continue
+			}
+		}
+
+		// the blocking query timed out without any change
+		if meta.LastIndex == waitIndex {
+			continue
+		}
+
+		// the index went backwards, start over as recommended by Consul
+		if meta.LastIndex < waitIndex {
+			waitIndex = 0
+			continue
+		}
+		waitIndex = meta.LastIndex
+
+		addresses := make([]resolver.Address, 0, len(entries))
+		for _, entry := range entries {
+			host := entry.Service.Address
+			if host == "" {
+				host = entry.Node.Address
+			}
+			addresses = append(addresses, resolver.Address{Addr: net.JoinHostPort(host, strconv.Itoa(entry.Service.Port))})
+		}
+		if len(addresses) == 0 {
+			r.cc.ReportError(fmt.Errorf("no healthy instance of %v registered in consul", r.service))
+			continue
+		}
+
+		_ = r.cc.UpdateState(resolver.State{Addresses: addresses})
+	}
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// Option configures a Client
+type Option func(*options)
+
+type options struct {
+	address     string
+	consul      string
+	creds       credentials.TransportCredentials
+	tokenSource TokenSource
+	dialOptions []grpc.DialOption
+}
+
+// TokenSource returns the bearer token sent with every call
+type TokenSource func(ctx context.Context) (string, error)
+
+// WithAddress connects to the service at address, a host:port or any gRPC target
+func WithAddress(address string) Option {
+	return func(o *options) {
+		o.address = address
+	}
+}
+
+// WithConsul resolves the healthy instances of the service through the Consul agent at address, e.g.
+// localhost:8500, and balances calls across them
+func WithConsul(address string) Option {
+	return func(o *options) {
+		o.consul = address
+	}
+}
+
+// WithTransportCredentials secures the connection, e.g. with credentials.NewTLS. Connections are plaintext by default
+func WithTransportCredentials(creds credentials.TransportCredentials) Option {
+	return func(o *options) {
+		o.creds = creds
+	}
+}
+
+// WithToken sends token as bearer token with every call
+func WithToken(token string) Option {
+	return WithTokenSource(func(context.Context) (string, error) {
+		return token, nil
+	})
+}
+
+// WithTokenSource sends the token returned by source as bearer token with every call
+func WithTokenSource(source TokenSource) Option {
+	return func(o *options) {
+		o.tokenSource = source
+	}
+}
+
+// WithDialOptions adds options to the ones the Client dials with
+func WithDialOptions(dialOptions ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOptions = append(o.dialOptions, dialOptions...)
+	}
+}
+
+// tokenCredentials adds the token of source to the metadata of every call
+type tokenCredentials struct {
+	source     TokenSource
+	requireTLS bool
+}
+
+func (t *tokenCredentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	token, err := t.source(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{AUTHORIZATION_HEADER: BEARER_PREFIX + token}, nil
+}
+
+func (t *tokenCredentials) RequireTransportSecurity() bool {
+	return t.requireTLS
+}
@@ -2,31 +2,63 @@ package config
 
 import (
 	"os"
+	"reflect"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 const (
 	CONFIG_PATH = "./res/config.yaml"
+
+	DRIVER_MONGODB = "mongodb"
+	DRIVER_MEMORY  = "memory"
+	DRIVER_BBOLT   = "bbolt"
+	DRIVER_POSTGIS = "postgis"
+
+	SETTING_LOG_LEVEL     = "loglevel"
+	SETTING_PING_INTERVAL = "database.ping_interval"
+	SETTING_MAX_DISTANCE  = "query.max_distance"
+	SETTING_MIN_DISTANCE  = "query.min_distance"
 )
 
+// RELOADABLE_SETTINGS are the settings that can be changed while the service is running
+var RELOADABLE_SETTINGS = []string{SETTING_LOG_LEVEL, SETTING_PING_INTERVAL, SETTING_MAX_DISTANCE, SETTING_MIN_DISTANCE}
+
 type ServiceConfig struct {
-	ServiceName string   `yaml:"service_name" validate:"required"`
-	Consul      Consul   `yaml:"consul" validate:"required"`
-	LogLevel    string   `yaml:"loglevel" validate:"required"`
-	Port        int      `yaml:"port" validate:"required"`
-	Database    Database `yaml:"database" validate:"required"`
-	Metrics     Metrics  `yaml:"metrics" validate:"required"`
+	ServiceName string    `yaml:"service_name" validate:"required"`
+	Consul      Consul    `yaml:"consul" validate:"required"`
+	LogLevel    string    `yaml:"loglevel" validate:"required"`
+	LogFormat   string    `yaml:"log_format" validate:"omitempty,oneof=logfmt json"`
+	Port        int       `yaml:"port" validate:"required"`
+	Database    Database  `yaml:"database" validate:"required"`
+	Metrics     Metrics   `yaml:"metrics" validate:"required"`
+	Query       Query     `yaml:"query"`
+	TLS         TLS       `yaml:"tls"`
+	Tracing     Tracing   `yaml:"tracing"`
+	Admin       Admin     `yaml:"admin"`
+	RateLimit   RateLimit `yaml:"rate_limit"`
+	Gateway     Gateway   `yaml:"gateway"`
+	Push        Push      `yaml:"push"`
+	Follower    Follower  `yaml:"follower"`
+	Spoofing    Spoofing  `yaml:"spoofing"`
+	Policy      Policy    `yaml:"policy"`
 }
 
 type Database struct {
-	Collection   string        `yaml:"collection" validate:"required"`
-	Host         string        `yaml:"host" validate:"required"`
-	DatabaseName string        `yaml:"database_name" validate:"required"`
-	Options      ServerOptions `yaml:"options"`
-	PingInterval string        `yaml:"ping_interval" validate:"required"`
-	Port         int           `yaml:"port" validate:"required"`
-	Timeout      string        `yaml:"timeout" validate:"required"`
+	Driver           string        `yaml:"driver" validate:"omitempty,oneof=mongodb memory bbolt postgis"`
+	Collection       string        `yaml:"collection" validate:"required"`
+	TrailCollection  string        `yaml:"trail_collection"`
+	UnlockCollection string        `yaml:"unlock_collection"`
+	ZoneCollection   string        `yaml:"zone_collection"`
+	Host             string        `yaml:"host" validate:"required_unless=Driver memory Driver bbolt Driver postgis"`
+	DatabaseName     string        `yaml:"database_name" validate:"required"`
+	Options          ServerOptions `yaml:"options"`
+	PingInterval     string        `yaml:"ping_interval" validate:"required"`
+	Port             int           `yaml:"port" validate:"required_unless=Driver memory Driver bbolt Driver postgis"`
+	Path             string        `yaml:"path" validate:"required_if=Driver bbolt"`
+	DSN              string        `yaml:"dsn" validate:"required_if=Driver postgis"`
+	Timeout          string        `yaml:"timeout" validate:"required"`
 }
 
 type Metrics struct {
@@ -34,8 +66,130 @@ type Metrics struct {
 }
 
 type Consul struct {
-	Host string `yaml:"host" validate:"required"`
-	Port int    `yaml:"port" validate:"required"`
+	Host     string `yaml:"host" validate:"required"`
+	Port     int    `yaml:"port" validate:"required"`
+	KVPrefix string `yaml:"kv_prefix"`
+}
+
+// TLS configures transport security for the gRPC server and the metrics endpoint
+type TLS struct {
+	Enabled      bool   `yaml:"enabled"`
+	CertFile     string `yaml:"cert_file"`
+	KeyFile      string `yaml:"key_file"`
+	ClientCAFile string `yaml:"client_ca_file"`
+	MinVersion   string `yaml:"min_version" validate:"omitempty,oneof=1.0 1.1 1.2 1.3"`
+	MutualTLS    bool   `yaml:"mutual_tls"`
+}
+
+// Tracing configures the OpenTelemetry span exporter
+type Tracing struct {
+	Enabled     bool              `yaml:"enabled"`
+	Exporter    string            `yaml:"exporter" validate:"omitempty,oneof=otlpgrpc otlphttp"`
+	Endpoint    string            `yaml:"endpoint"`
+	Insecure    bool              `yaml:"insecure"`
+	SampleRatio float64           `yaml:"sample_ratio" validate:"gte=0,lte=1"`
+	Attributes  map[string]string `yaml:"attributes,omitempty"`
+}
+
+// Admin configures the diagnostic endpoints served on the metrics port. Callers must present the bearer
+// token stored in TokenFile or a client certificate whose SPIFFE ID or common name is listed in Identities
+type Admin struct {
+	Pprof      bool     `yaml:"pprof"`
+	Channelz   bool     `yaml:"channelz"`
+	TokenFile  string   `yaml:"token_file"`
+	Identities []string `yaml:"identities,omitempty"`
+}
+
+// Query holds the bounds, in meters, applied to spatial queries
+type Query struct {
+	MaxDistance int `yaml:"max_distance" validate:"gte=0,gtefield=MinDistance"`
+	MinDistance int `yaml:"min_distance" validate:"gte=0"`
+}
+
+// Gateway configures the REST/JSON gateway served in process that proxies to the gRPC server
+type Gateway struct {
+	Enabled bool `yaml:"enabled"`
+	Port    int  `yaml:"port" validate:"required_with=Enabled"`
+}
+
+// Push configures the WebSocket endpoint on the gateway port that pushes the crumbs created near the position
+// of a subscriber. Subscribers present their client certificate, or a bearer token of TokenFile which holds a
+// "user token" line per subscriber, a token alone authenticating anonymous subscribers. Origins lists the browser
+// origins allowed besides the gateway host. MaxRadius, in meters, bounds the radius subscribers can request
+type Push struct {
+	Enabled      bool     `yaml:"enabled"`
+	TokenFile    string   `yaml:"token_file" validate:"required_with=Enabled"`
+	PingInterval string   `yaml:"ping_interval"`
+	MaxRadius    int      `yaml:"max_radius" validate:"required_with=Enabled,gte=0"`
+	Origins      []string `yaml:"origins,omitempty"`
+}
+
+// Follower configures the follower_service client GetCrumbs asks which crumb owners the caller follows.
+// Without an Address only the owners of followers-only crumbs can see them. Answers are cached for CacheTTL
+type Follower struct {
+	Address  string    `yaml:"address"`
+	CacheTTL string    `yaml:"cache_ttl"`
+	Timeout  string    `yaml:"timeout"`
+	TLS      ClientTLS `yaml:"tls"`
+}
+
+// ClientTLS configures transport security for the connections to another service. CAFile verifies the service,
+// the system pool if empty, and CertFile and KeyFile are the client certificate sent to services requiring
+// mutual TLS
+type ClientTLS struct {
+	Enabled    bool   `yaml:"enabled"`
+	CAFile     string `yaml:"ca_file"`
+	CertFile   string `yaml:"cert_file"`
+	KeyFile    string `yaml:"key_file"`
+	ServerName string `yaml:"server_name"`
+	MinVersion string `yaml:"min_version" validate:"omitempty,oneof=1.0 1.1 1.2 1.3"`
+}
+
+// Spoofing configures the checks of the positions users report when creating and unlocking crumbs. A position is
+// suspicious when reaching it from the previous one takes a speed above MaxSpeed, in meters per second, when its
+// accuracy, in meters, is below MinAccuracy or above MaxAccuracy, or when its coordinates, given with at least
+// RepeatDecimals decimals, were reported MaxRepeats times before. A zero threshold disables its check. Mode
+// "reject" fails those requests while "flag" only logs and counts them. The last HistorySize positions of a user
+// are kept for HistoryTTL, backend "mongodb" shares them across replicas
+type Spoofing struct {
+	Enabled        bool    `yaml:"enabled"`
+	Mode           string  `yaml:"mode" validate:"omitempty,oneof=reject flag"`
+	Backend        string  `yaml:"backend" validate:"omitempty,oneof=memory mongodb"`
+	Collection     string  `yaml:"collection"`
+	HistorySize    int     `yaml:"history_size" validate:"gte=0"`
+	HistoryTTL     string  `yaml:"history_ttl"`
+	MaxSpeed       float64 `yaml:"max_speed" validate:"gte=0"`
+	MinAccuracy    float64 `yaml:"min_accuracy" validate:"gte=0"`
+	MaxAccuracy    float64 `yaml:"max_accuracy" validate:"gte=0"`
+	RepeatDecimals int     `yaml:"repeat_decimals" validate:"gte=0"`
+	MaxRepeats     int     `yaml:"max_repeats" validate:"gte=0"`
+}
+
+// Policy configures the zones, kept in the zone collection of the database, where crumbs are forbidden or their
+// number capped. Only the callers whose client certificate SPIFFE ID or common name is listed in Operators can
+// manage the zones. The zones of the GeoJSON FeatureCollection at File, if set, are loaded on start, replacing
+// the zones with the same rule id
+type Policy struct {
+	Enabled   bool     `yaml:"enabled"`
+	File      string   `yaml:"file"`
+	Operators []string `yaml:"operators,omitempty"`
+}
+
+// RateLimit configures the token buckets applied per caller, the authenticated identity or else the peer IP,
+// and method. Methods without an entry in Methods use Default. Backend "mongodb" shares the buckets across replicas
+type RateLimit struct {
+	Enabled    bool             `yaml:"enabled"`
+	Backend    string           `yaml:"backend" validate:"omitempty,oneof=memory mongodb"`
+	Collection string           `yaml:"collection"`
+	Default    Limit            `yaml:"default"`
+	Methods    map[string]Limit `yaml:"methods,omitempty" validate:"dive"`
+}
+
+// Limit is a token bucket refilled at Rate requests per second that holds at most Burst requests.
+// A zero Rate disables the limit
+type Limit struct {
+	Rate  float64 `yaml:"rate" validate:"gte=0"`
+	Burst int     `yaml:"burst" validate:"gte=0"`
 }
 
 type ServerOptions struct {
@@ -58,3 +212,61 @@ func ReadLocalConfig(configPath string) (*ServiceConfig, error) {
 
 	return config, nil
 }
+
+// MergeYAML returns a copy of the config with the values found in data applied on top of it.
+// Settings missing from data keep their current value
+func (c *ServiceConfig) MergeYAML(data []byte) (*ServiceConfig, error) {
+	// round trip through yaml so that maps are copied rather than shared with c
+	current, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := &ServiceConfig{}
+	err = yaml.Unmarshal(current, merged)
+	if err != nil {
+		return nil, err
+	}
+
+	err = yaml.Unmarshal(data, merged)
+	if err != nil {
+		return nil, err
+	}
+
+	return merged, nil
+}
+
+// Changed returns the yaml path of every setting that differs between c and next, e.g. "database.host"
+func (c *ServiceConfig) Changed(next *ServiceConfig) []string {
+	var changed []string
+	diffFields("", reflect.ValueOf(*c), reflect.ValueOf(*next), &changed)
+	return changed
+}
+
+// IsReloadable returns true if setting can be applied without restarting the service
+func IsReloadable(setting string) bool {
+	for _, reloadable := range RELOADABLE_SETTINGS {
+		if setting == reloadable {
+			return true
+		}
+	}
+	return false
+}
+
+func diffFields(prefix string, current reflect.Value, next reflect.Value, changed *[]string) {
+	for i := 0; i < current.NumField(); i++ {
+		name := strings.Split(current.Type().Field(i).Tag.Get("yaml"), ",")[0]
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		if current.Field(i).Kind() == reflect.Struct {
+			diffFields(name, current.Field(i), next.Field(i), changed)
+			continue
+		}
+
+		if !reflect.DeepEqual(current.Field(i).Interface(), next.Field(i).Interface()) {
+			*changed = append(*changed, name)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package routes
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"time"
+
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	"github.com/haguru/horus/crumbdb/pkg/export"
+	appLogging "github.com/haguru/horus/crumbdb/pkg/logging"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// EXPORT_CHUNK_SIZE is the size in bytes of the chunks of an export, a feature larger than a chunk is sent
+	// in a chunk of its own
+	EXPORT_CHUNK_SIZE = 32 * 1024
+
+	// EXPORT_PAGE_SIZE is how many documents of a user an export reads from the database at a time
+	EXPORT_PAGE_SIZE = 200
+
+	// VISIBILITY_PREFIX is the prefix of the Visibility values left out of the visibility property of features
+	VISIBILITY_PREFIX = "VISIBILITY_"
+)
+
+// EXPORT_FORMATS are the export formats of the ExportFormat values
+var EXPORT_FORMATS = map[pb.ExportFormat]string{
+	pb.ExportFormat_EXPORT_FORMAT_UNSPECIFIED: export.FORMAT_GEOJSON,
+	pb.ExportFormat_EXPORT_FORMAT_GEOJSON:     export.FORMAT_GEOJSON,
+	pb.ExportFormat_EXPORT_FORMAT_GPX:         export.FORMAT_GPX,
+	pb.ExportFormat_EXPORT_FORMAT_KML:         export.FORMAT_KML,
+}
+
+func (r *Route) Export(req *pb.ExportRequest, stream pb.CrumbDB_ExportServer) error {
+	ctx := stream.Context()
+	lc := appLogging.FromContext(ctx, r.lc)
+	lc.Debug("received new Export request", "user", req.GetUser(), "format", req.GetFormat().String())
+
+	format, ok := EXPORT_FORMATS[req.GetFormat()]
+	if !ok {
+		return status.Errorf(codes.InvalidArgument, "invalid format %v", req.GetFormat())
+	}
+	switch {
+	case req.GetUser() == "" && req.GetLocation() == nil:
+		return status.Error(codes.InvalidArgument, "user or location is required")
+	case req.GetUser() != "" && req.GetLocation() != nil:
+		return status.Error(codes.InvalidArgument, "only one of user and location can be exported")
+	case req.GetLocation() != nil:
+		if err := validateGeometry("location", req.GetLocation(), QUERY_TYPES...); err != nil {
+			return invalidArgument(err)
+		}
+	}
+
+	chunks := &chunkWriter{stream: stream, contentType: export.ContentType(format)}
+	w := bufio.NewWriterSize(chunks, EXPORT_CHUNK_SIZE)
+	enc, err := export.NewEncoder(format, w)
+	if err != nil {
+		return err
+	}
+
+	if req.GetUser() != "" {
+		err = r.exportUser(stream, enc, req.GetUser())
+	} else {
+		err = r.exportLocation(stream, enc, req.GetLocation())
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := enc.Close(); err != nil {
+		lc.Errorf("failed to end export: %v", err)
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		lc.Errorf("failed to send export: %v", err)
+		return err
+	}
+	return nil
+}
+
+// exportUser encodes the crumbs the caller can see and then the trails of user, newest first, a page at a time
+func (r *Route) exportUser(stream pb.CrumbDB_ExportServer, enc export.Encoder, user string) error {
+	err := r.exportPages(stream, r.dbConfig.Collection, user, func(item bson.D) (*interfaces.Cursor, error) {
+		crumb, err := toCrumb(item)
+		if err != nil {
+			return nil, err
+		}
+		cursor := &interfaces.Cursor{Created: crumb.GetCreatedAt(), Id: crumb.GetId()}
+		// the crumbs share their owner so the follower client answers all but the first from its cache
+		if len(r.visibleCrumbs(stream.Context(), []*pb.Crumb{crumb})) == 0 {
+			return cursor, nil
+		}
+		redactLocked([]*pb.Crumb{crumb}, callerUser(stream.Context()))
+		return cursor, enc.Encode(crumbFeature(crumb))
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.exportPages(stream, TrailCollection(r.dbConfig), user, func(item bson.D) (*interfaces.Cursor, error) {
+		trail, err := toTrail(item)
+		if err != nil {
+			return nil, err
+		}
+		cursor := &interfaces.Cursor{Created: trail.GetCreatedAt(), Id: trail.GetId()}
+		// the trail is exported through the crumbs the caller can see, like GetTrail returns it
+		trail, _, err = r.visibleTrail(stream.Context(), trail)
+		if err != nil {
+			return nil, err
+		}
+		// a trail without crumbs has no path to export
+		if trail.GetPath() == nil {
+			return cursor, nil
+		}
+		return cursor, enc.Encode(trailFeature(trail))
+	})
+}
+
+// exportPages calls encode with the documents of user in collection, newest first, reading EXPORT_PAGE_SIZE
+// documents at a time. encode returns the cursor of the document
+func (r *Route) exportPages(stream pb.CrumbDB_ExportServer, collection string, user string, encode func(item bson.D) (*interfaces.Cursor, error)) error {
+	lc := appLogging.FromContext(stream.Context(), r.lc)
+
+	var after *interfaces.Cursor
+	for {
+		data, err := r.dbClient.FindByUser(stream.Context(), r.dbConfig.DatabaseName, collection, user, after, EXPORT_PAGE_SIZE)
+		if err != nil {
+			lc.Errorf("failed to find data of user '%v' in '%v': %v", user, collection, err)
+			return err
+		}
+		for _, item := range data {
+			after, err = encode(item)
+			if err != nil {
+				lc.Errorf("failed to export an item in data: %v", err)
+				return err
+			}
+		}
+		if len(data) < EXPORT_PAGE_SIZE {
+			return nil
+		}
+	}
+}
+
+// exportLocation encodes the crumbs GetCrumbs returns for location
+func (r *Route) exportLocation(stream pb.CrumbDB_ExportServer, enc export.Encoder, location *pb.Point) error {
+	lc := appLogging.FromContext(stream.Context(), r.lc)
+
+	data, err := r.dbClient.SpaitalQuery(stream.Context(), location.GetType(), location.GetCoordinates(), r.dbConfig.DatabaseName, r.dbConfig.Collection)
+	if err != nil {
+		lc.Errorf("failed to run spatial query: %v", err)
+		return err
+	}
+	crumbs := make([]*pb.Crumb, 0, len(data))
+	for _, item := range data {
+		crumb, err := toCrumb(item)
+		if err != nil {
+			lc.Errorf("failed to convert an item in data: %v", err)
+			return err
+		}
+		crumbs = append(crumbs, crumb)
+	}
+	visible := r.visibleCrumbs(stream.Context(), crumbs)
+	redactLocked(visible, callerUser(stream.Context()))
+	for _, crumb := range visible {
+		if err := enc.Encode(crumbFeature(crumb)); err != nil {
+			lc.Errorf("failed to export an item in data: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// crumbFeature returns the export feature of crumb, with its fields other than the location as properties
+func crumbFeature(crumb *pb.Crumb) export.Feature {
+	properties := map[string]interface{}{
+		"id":         crumb.GetId(),
+		"user":       crumb.GetUser(),
+		"message":    crumb.GetMessage(),
+		"visibility": strings.ToLower(strings.TrimPrefix(crumb.GetVisibility().String(), VISIBILITY_PREFIX)),
+		"tags":       crumb.GetTags(),
+		"created_at": crumb.GetCreatedAt(),
+		"version":    crumb.GetVersion(),
+	}
+	if crumb.GetTags() == nil {
+		properties["tags"] = []string{}
+	}
+	if crumb.GetLocation().Accuracy != nil {
+		properties["accuracy"] = crumb.GetLocation().GetAccuracy()
+	}
+
+	return export.Feature{
+		Name:        crumb.GetMessage(),
+		Time:        time.UnixMilli(crumb.GetCreatedAt()),
+		Coordinates: [][]float64{crumb.GetLocation().GetCoordinates()},
+		Altitude:    crumb.GetLocation().Altitude,
+		Properties:  properties,
+	}
+}
+
+// trailFeature returns the export feature of trail with its fields other than the path as properties
+func trailFeature(trail *pb.Trail) export.Feature {
+	coordinates := trail.GetPath().GetCoordinates()
+	var shape [][]float64
+	for i := 0; i+1 < len(coordinates); i += 2 {
+		shape = append(shape, coordinates[i:i+2])
+	}
+	// the path of a trail whose crumbs are at the same location is a Point, it is exported as a line of no
+	// length so a trail is always a line
+	if len(shape) == 1 {
+		shape = append(shape, shape[0])
+	}
+
+	return export.Feature{
+		Name:        trail.GetName(),
+		Time:        time.UnixMilli(trail.GetCreatedAt()),
+		Coordinates: shape,
+		Properties: map[string]interface{}{
+			"id":         trail.GetId(),
+			"user":       trail.GetUser(),
+			"name":       trail.GetName(),
+			"crumb_ids":  trail.GetCrumbIds(),
+			"distance":   trail.GetDistance(),
+			"created_at": trail.GetCreatedAt(),
+			"version":    trail.GetVersion(),
+		},
+	}
+}
+
+// chunkWriter sends every write as a chunk of an export, the first with the content type of the document
+type chunkWriter struct {
+	stream      pb.CrumbDB_ExportServer
+	contentType string
+	sent        bool
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	chunk := &pb.ExportChunk{Data: bytes.Clone(p)}
+	if !w.sent {
+		chunk.ContentType = w.contentType
+	}
+	if err := w.stream.Send(chunk); err != nil {
+		return 0, err
+	}
+	w.sent = true
+	return len(p), nil
+}
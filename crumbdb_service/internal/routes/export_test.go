@@ -0,0 +1,201 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	grpcMock "github.com/haguru/horus/crumbdb/internal/routes/protos/mocks"
+	"github.com/haguru/horus/crumbdb/pkg/export"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb"
+
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRoute_Export(t *testing.T) {
+	r, ids := newTrailRoute(t)
+	if _, err := r.CreateTrail(context.Background(), &pb.Trail{User: "alice", Name: "walk", CrumbIds: []string{ids["start"], ids["north"]}}); err != nil {
+		t.Fatalf("Route.CreateTrail() error = %v", err)
+	}
+	if _, err := r.CreateTrail(context.Background(), &pb.Trail{User: "alice", Name: "empty"}); err != nil {
+		t.Fatalf("Route.CreateTrail() error = %v", err)
+	}
+	// enough crumbs of carol to read them in several pages
+	for i := 0; i < EXPORT_PAGE_SIZE+1; i++ {
+		_, err := r.Create(context.Background(), &pb.Crumb{
+			User:     "carol",
+			Message:  "hi",
+			Location: &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{2.35, 48.85}},
+		})
+		if err != nil {
+			t.Fatalf("Route.Create() error = %v", err)
+		}
+	}
+
+	_, err := r.Create(context.Background(), &pb.Crumb{
+		User:       "erin",
+		Message:    "secret",
+		Visibility: pb.Visibility_VISIBILITY_PRIVATE,
+		Location:   &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{2.35, 48.85}},
+	})
+	if err != nil {
+		t.Fatalf("Route.Create() error = %v", err)
+	}
+	// a trail of frank through a private crumb between two public ones
+	var frank []string
+	for _, crumb := range []*pb.Crumb{
+		{Message: "first", Location: &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{1, 1}}},
+		{Message: "hidden", Visibility: pb.Visibility_VISIBILITY_PRIVATE, Location: &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{1.5, 1.5}}},
+		{Message: "last", Location: &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{1, 1.01}}},
+	} {
+		crumb.User = "frank"
+		id, err := r.Create(context.Background(), crumb)
+		if err != nil {
+			t.Fatalf("Route.Create() error = %v", err)
+		}
+		frank = append(frank, id.GetValue())
+	}
+	if _, err := r.CreateTrail(context.Background(), &pb.Trail{User: "frank", Name: "detour", CrumbIds: frank}); err != nil {
+		t.Fatalf("Route.CreateTrail() error = %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		ctx             context.Context
+		req             *pb.ExportRequest
+		wantCode        codes.Code
+		wantContentType string
+		want            []string
+		wantNot         []string
+		wantCount       map[string]int
+	}{
+		{
+			name:            "crumbs and trails of a user as geojson",
+			req:             &pb.ExportRequest{User: "alice"},
+			wantContentType: export.CONTENT_TYPE_GEOJSON,
+			want:            []string{`"type":"FeatureCollection"`, `"message":"north"`, `"name":"walk"`},
+			wantCount:       map[string]int{`"type":"Point"`: 4, `"type":"LineString"`: 1},
+		},
+		{
+			name:            "crumbs and trails of a user as gpx",
+			req:             &pb.ExportRequest{User: "alice", Format: pb.ExportFormat_EXPORT_FORMAT_GPX},
+			wantContentType: export.CONTENT_TYPE_GPX,
+			want:            []string{`<name>start again</name>`, `<property xmlns="urn:horus:crumbdb" name="user">alice</property>`},
+			wantCount:       map[string]int{"<wpt ": 4, "<trk>": 1},
+		},
+		{
+			name:            "crumbs near a location as kml",
+			req:             &pb.ExportRequest{Location: &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{-122.3, 37.7}}, Format: pb.ExportFormat_EXPORT_FORMAT_KML},
+			wantContentType: export.CONTENT_TYPE_KML,
+			want:            []string{"<coordinates>-122.3,37.7</coordinates>", `<Data name="user"><value>bob</value></Data>`},
+			wantCount:       map[string]int{"<Placemark>": 1},
+		},
+		{
+			name:            "crumbs of a user in several pages",
+			req:             &pb.ExportRequest{User: "carol"},
+			wantContentType: export.CONTENT_TYPE_GEOJSON,
+			wantCount:       map[string]int{`"type":"Point"`: EXPORT_PAGE_SIZE + 1},
+		},
+		{
+			name:            "private crumbs of another user",
+			req:             &pb.ExportRequest{User: "erin"},
+			wantContentType: export.CONTENT_TYPE_GEOJSON,
+			wantCount:       map[string]int{`"type":"Point"`: 0},
+		},
+		{
+			name:            "own private crumbs",
+			ctx:             asCaller("erin"),
+			req:             &pb.ExportRequest{User: "erin"},
+			wantContentType: export.CONTENT_TYPE_GEOJSON,
+			want:            []string{`"message":"secret"`},
+			wantCount:       map[string]int{`"type":"Point"`: 1},
+		},
+		{
+			name:            "trail through private crumbs of another user",
+			req:             &pb.ExportRequest{User: "frank"},
+			wantContentType: export.CONTENT_TYPE_GEOJSON,
+			want:            []string{`"name":"detour"`, `[[1,1],[1,1.01]]`, `"crumb_ids":["` + frank[0] + `","` + frank[2] + `"]`},
+			wantNot:         []string{frank[1], "[1.5,1.5]"},
+			wantCount:       map[string]int{`"type":"Point"`: 2, `"type":"LineString"`: 1},
+		},
+		{
+			name:            "own trail through private crumbs",
+			ctx:             asCaller("frank"),
+			req:             &pb.ExportRequest{User: "frank"},
+			wantContentType: export.CONTENT_TYPE_GEOJSON,
+			want:            []string{`[[1,1],[1.5,1.5],[1,1.01]]`, frank[1]},
+			wantCount:       map[string]int{`"type":"Point"`: 3, `"type":"LineString"`: 1},
+		},
+		{
+			name:            "user without crumbs",
+			req:             &pb.ExportRequest{User: "dave", Format: pb.ExportFormat_EXPORT_FORMAT_GPX},
+			wantContentType: export.CONTENT_TYPE_GPX,
+			want:            []string{"<gpx ", "</gpx>"},
+		},
+		{name: "missing user and location", req: &pb.ExportRequest{}, wantCode: codes.InvalidArgument},
+		{name: "user and location", req: &pb.ExportRequest{User: "alice", Location: &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{0, 0}}}, wantCode: codes.InvalidArgument},
+		{name: "invalid location", req: &pb.ExportRequest{Location: &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{0, 91}}}, wantCode: codes.InvalidArgument},
+		{name: "invalid format", req: &pb.ExportRequest{User: "alice", Format: 42}, wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var chunks []*pb.ExportChunk
+			stream := grpcMock.NewServerStreamingServer[pb.ExportChunk](t)
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			stream.On("Context").Return(ctx).Maybe()
+			stream.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+				chunks = append(chunks, args.Get(0).(*pb.ExportChunk))
+			}).Return(nil).Maybe()
+
+			err := r.Export(tt.req, stream)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Route.Export() error = %v, want %v", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+
+			var data bytes.Buffer
+			for i, chunk := range chunks {
+				if (i == 0) != (chunk.GetContentType() != "") {
+					t.Errorf("chunk %v content type = %q, want it on the first chunk only", i, chunk.GetContentType())
+				}
+				if len(chunk.GetData()) > EXPORT_CHUNK_SIZE {
+					t.Errorf("chunk %v has %v bytes, want at most %v", i, len(chunk.GetData()), EXPORT_CHUNK_SIZE)
+				}
+				data.Write(chunk.GetData())
+			}
+			if got := chunks[0].GetContentType(); got != tt.wantContentType {
+				t.Errorf("content type = %v, want %v", got, tt.wantContentType)
+			}
+
+			out := data.String()
+			if tt.wantContentType == export.CONTENT_TYPE_GEOJSON && !json.Valid(data.Bytes()) {
+				t.Errorf("export is not valid json: %v", out)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("export does not contain %v: %v", want, out)
+				}
+			}
+			for _, notWant := range tt.wantNot {
+				if strings.Contains(out, notWant) {
+					t.Errorf("export contains %v: %v", notWant, out)
+				}
+			}
+			for want, count := range tt.wantCount {
+				if got := strings.Count(out, want); got != count {
+					t.Errorf("export contains %v %v times, want %v", want, got, count)
+				}
+			}
+		})
+	}
+}
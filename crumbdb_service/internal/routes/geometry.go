@@ -0,0 +1,55 @@
+package routes
+
+import (
+	"errors"
+
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	"github.com/haguru/horus/crumbdb/pkg/geojson"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// LOCATION_TYPES are the geometries of the location of a crumb, QUERY_TYPES the ones GetCrumbs searches
+var (
+	LOCATION_TYPES = []string{geojson.TYPE_POINT}
+	QUERY_TYPES    = []string{geojson.TYPE_POINT, geojson.TYPE_POLYGON}
+)
+
+// validateGeometry returns the geojson.Errors of point, with fields named under field, if it is missing or is
+// not a valid geometry of one of types
+func validateGeometry(field string, point *pb.Point, types ...string) error {
+	if point == nil {
+		return geojson.Errors{{Field: field, Description: "is required"}}
+	}
+	return geojson.Validate(field, geojson.Geometry{
+		Type:        point.GetType(),
+		Coordinates: point.GetCoordinates(),
+		Altitude:    point.Altitude,
+		Accuracy:    point.Accuracy,
+	}, types...)
+}
+
+// invalidArgument returns the InvalidArgument status of err, detailing the fields at fault of geojson.Errors
+func invalidArgument(err error) error {
+	st := status.New(codes.InvalidArgument, err.Error())
+
+	var fieldErrors geojson.Errors
+	if !errors.As(err, &fieldErrors) {
+		return st.Err()
+	}
+	badRequest := &errdetails.BadRequest{}
+	for _, fieldError := range fieldErrors {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fieldError.Field,
+			Description: fieldError.Description,
+		})
+	}
+
+	detailed, detailsErr := st.WithDetails(badRequest)
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
@@ -0,0 +1,282 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	"github.com/haguru/horus/crumbdb/pkg/export"
+	appLogging "github.com/haguru/horus/crumbdb/pkg/logging"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// IMPORT_BATCH_SIZE is how many accepted records an import inserts at a time
+	IMPORT_BATCH_SIZE = 100
+
+	// MAX_IMPORT_RECORDS is the most records of an import, which bounds the size of its report
+	MAX_IMPORT_RECORDS = 10000
+)
+
+// importedCrumb is an accepted record waiting to be inserted with the result it reports to
+type importedCrumb struct {
+	crumb  *pb.Crumb
+	result *pb.ImportResult
+}
+
+func (r *Route) ImportCrumbs(stream pb.CrumbDB_ImportCrumbsServer) error {
+	ctx := stream.Context()
+	lc := appLogging.FromContext(ctx, r.lc)
+	lc.Debug("received new ImportCrumbs request")
+
+	report := &pb.ImportReport{}
+	var user string
+	var batch []importedCrumb
+	// accepted crumbs not inserted yet by rule id of their zones, counted against the most crumbs of the zones
+	pending := map[string]int64{}
+	for index := 0; ; index++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			lc.Errorf("failed to receive import record %v: %v", index, err)
+			return err
+		}
+		if index == MAX_IMPORT_RECORDS {
+			return status.Errorf(codes.InvalidArgument, "an import has at most %v records", MAX_IMPORT_RECORDS)
+		}
+		if index == 0 {
+			report.DryRun = req.GetDryRun()
+			user = req.GetUser()
+			// an authenticated caller only imports its own crumbs
+			if caller := callerUser(ctx); caller != "" {
+				if user != "" && user != caller {
+					return status.Errorf(codes.PermissionDenied, "%v cannot import the crumbs of %v", caller, user)
+				}
+				user = caller
+			}
+		}
+
+		result := &pb.ImportResult{Index: int32(index)}
+		report.Results = append(report.Results, result)
+		crumb, err := r.importCrumb(req, user)
+		if err == nil {
+			// the batch is inserted later, so the pending crumbs are counted instead of holding the zones
+			var release func()
+			release, err = r.checkZones(ctx, "", crumb.GetLocation(), pending)
+			release()
+		}
+		if err != nil {
+			result.Reason = status.Convert(err).Message()
+			continue
+		}
+		result.Accepted = true
+		if report.DryRun {
+			continue
+		}
+
+		batch = append(batch, importedCrumb{crumb: crumb, result: result})
+		if len(batch) == IMPORT_BATCH_SIZE {
+			r.insertImported(ctx, batch)
+			batch = nil
+			pending = map[string]int64{}
+		}
+	}
+	if len(batch) > 0 {
+		r.insertImported(ctx, batch)
+	}
+
+	for _, result := range report.Results {
+		if result.GetAccepted() {
+			report.Accepted++
+		} else {
+			report.Rejected++
+		}
+	}
+	lc.Debug("imported crumbs", "accepted", report.GetAccepted(), "rejected", report.GetRejected(), "dry_run", report.GetDryRun())
+
+	return stream.SendAndClose(report)
+}
+
+// insertImported inserts the crumbs of batch and sets their results, rejecting those which failed
+func (r *Route) insertImported(ctx context.Context, batch []importedCrumb) {
+	lc := appLogging.FromContext(ctx, r.lc)
+
+	docs := make([]interface{}, 0, len(batch))
+	for _, imported := range batch {
+		docs = append(docs, imported.crumb)
+	}
+
+	ids, err := r.dbClient.InsertMany(ctx, r.dbConfig.DatabaseName, r.dbConfig.Collection, docs)
+	var insertErr *interfaces.InsertManyError
+	if err != nil && !errors.As(err, &insertErr) {
+		lc.Errorf("failed to insert %v imported crumbs: %v", len(batch), err)
+		for _, imported := range batch {
+			imported.result.Accepted = false
+			imported.result.Reason = fmt.Sprintf("failed to insert: %v", err)
+		}
+		return
+	}
+
+	inserted := 0
+	for i, imported := range batch {
+		if insertErr != nil && insertErr.Errors[i] != nil {
+			imported.result.Accepted = false
+			imported.result.Reason = fmt.Sprintf("failed to insert: %v", insertErr.Errors[i])
+			continue
+		}
+		imported.result.Id = ids[i]
+		inserted++
+	}
+	r.metrics.CrumbsCreated.Add(float64(inserted))
+}
+
+// importCrumb returns the crumb of user of the record of req, or the reason it is rejected
+func (r *Route) importCrumb(req *pb.ImportCrumbsRequest, user string) (*pb.Crumb, error) {
+	var feature export.Feature
+	var err error
+	switch record := req.GetRecord().(type) {
+	case *pb.ImportCrumbsRequest_Feature:
+		feature, err = export.DecodeFeature([]byte(record.Feature))
+	case *pb.ImportCrumbsRequest_Waypoint:
+		feature, err = export.DecodeWaypoint([]byte(record.Waypoint))
+	default:
+		return nil, fmt.Errorf("record is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	crumb, err := featureCrumb(feature, user)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.validator.Struct(crumb); err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			return nil, fmt.Errorf("validation error: %s", validationErrors)
+		}
+		return nil, err
+	}
+	if err := validateGeometry("location", crumb.GetLocation(), LOCATION_TYPES...); err != nil {
+		return nil, err
+	}
+	return crumb, nil
+}
+
+// featureCrumb returns the crumb of user of an imported feature, the reverse of crumbFeature. Its message is the
+// message property or else the name or description of the feature, and it was created at the created_at property
+// or else the time of the feature, if any. A user property, as exported, must be user
+func featureCrumb(feature export.Feature, user string) (*pb.Crumb, error) {
+	crumb := &pb.Crumb{
+		Location: &pb.Point{
+			Type:        mongodb.POINT_TYPE_POINT,
+			Coordinates: feature.Coordinates[0],
+			Altitude:    feature.Altitude,
+		},
+		User:    user,
+		Message: feature.Name,
+		Version: 1,
+	}
+	if crumb.Message == "" {
+		crumb.Message = feature.Description
+	}
+	if !feature.Time.IsZero() {
+		crumb.CreatedAt = feature.Time.UnixMilli()
+	}
+
+	for name, value := range feature.Properties {
+		var err error
+		switch name {
+		case "message":
+			crumb.Message, err = stringProperty(name, value)
+		case "user":
+			var owner string
+			owner, err = stringProperty(name, value)
+			if err == nil && owner != user {
+				err = fmt.Errorf("the record is of user %q, the import of %q", owner, user)
+			}
+		case "tags":
+			crumb.Tags, err = tagsProperty(value)
+		case "visibility":
+			var visibility string
+			visibility, err = stringProperty(name, value)
+			v, ok := pb.Visibility_value[VISIBILITY_PREFIX+strings.ToUpper(visibility)]
+			if err == nil && !ok {
+				err = fmt.Errorf("invalid visibility %q", visibility)
+			}
+			crumb.Visibility = pb.Visibility(v)
+		case "accuracy":
+			var accuracy float64
+			accuracy, err = numberProperty(name, value)
+			crumb.Location.Accuracy = &accuracy
+		case "created_at":
+			var created float64
+			created, err = numberProperty(name, value)
+			crumb.CreatedAt = int64(created)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if crumb.CreatedAt == 0 {
+		crumb.CreatedAt = time.Now().UnixMilli()
+	}
+	return crumb, nil
+}
+
+func stringProperty(name string, value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("property %v must be a string, got %v", name, value)
+	}
+	return s, nil
+}
+
+// numberProperty returns a number of GeoJSON properties, or of GPX properties which are strings
+func numberProperty(name string, value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err == nil {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("property %v must be a number, got %v", name, value)
+}
+
+// tagsProperty returns the tags of a list of GeoJSON properties, or of the comma separated GPX property
+func tagsProperty(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil, nil
+		}
+		return strings.Split(v, ","), nil
+	case []interface{}:
+		tags := make([]string, 0, len(v))
+		for _, tag := range v {
+			s, ok := tag.(string)
+			if !ok {
+				return nil, fmt.Errorf("property tags must be a list of strings, got %v", value)
+			}
+			tags = append(tags, s)
+		}
+		return tags, nil
+	}
+	return nil, fmt.Errorf("property tags must be a list of strings, got %v", value)
+}
@@ -0,0 +1,187 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/haguru/horus/crumbdb/config"
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces/mocks"
+	appMetrics "github.com/haguru/horus/crumbdb/pkg/prometheus"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// importStream is the stream of an ImportCrumbs call sending requests and receiving the report
+type importStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	requests []*pb.ImportCrumbsRequest
+	report   *pb.ImportReport
+}
+
+func (s *importStream) Context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+func (s *importStream) Recv() (*pb.ImportCrumbsRequest, error) {
+	if len(s.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	return req, nil
+}
+
+func (s *importStream) SendAndClose(report *pb.ImportReport) error {
+	s.report = report
+	return nil
+}
+
+func feature(geojson string) *pb.ImportCrumbsRequest {
+	return &pb.ImportCrumbsRequest{Record: &pb.ImportCrumbsRequest_Feature{Feature: geojson}}
+}
+
+func waypoint(gpx string) *pb.ImportCrumbsRequest {
+	return &pb.ImportCrumbsRequest{Record: &pb.ImportCrumbsRequest_Waypoint{Waypoint: gpx}}
+}
+
+func TestRoute_ImportCrumbs(t *testing.T) {
+	records := []*pb.ImportCrumbsRequest{
+		feature(`{"type":"Feature","geometry":{"type":"Point","coordinates":[2.35,48.85,35]},` +
+			`"properties":{"user":"bob","message":"paris","tags":["a","b"],"visibility":"followers","created_at":1700000000000,"accuracy":5}}`),
+		waypoint(`<wpt lat="37.8" lon="-122.4"><time>2023-11-14T22:13:20Z</time><name>legacy</name>` +
+			`<extensions><property xmlns="urn:horus:crumbdb" name="tags">c</property></extensions></wpt>`),
+		feature(`{"type":`),
+		feature(`{"type":"Feature","geometry":{"type":"Point","coordinates":[2.35,91]},"properties":{"message":"north"}}`),
+		waypoint(`<wpt lat="1" lon="2"></wpt>`),
+		feature(`{"type":"Feature","geometry":{"type":"Point","coordinates":[2.35,48.85]},"properties":{"message":"hi","visibility":"everyone"}}`),
+		{},
+		feature(`{"type":"Feature","geometry":{"type":"Point","coordinates":[2.35,48.85]},"properties":{"user":"alice","message":"forged"}}`),
+	}
+	wantReasons := []string{"", "", "invalid geojson", "latitude must be between", "validation error", "invalid visibility", "record is required", `the record is of user "alice"`}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		user     string
+		dryRun   bool
+		wantCode codes.Code
+	}{
+		{name: "dry run", user: "bob", dryRun: true},
+		{name: "import", user: "bob"},
+		{name: "import as the caller", ctx: asCaller("bob")},
+		{name: "import the crumbs of another user", ctx: asCaller("eve"), user: "bob", wantCode: codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTrailRoute(t)
+			requests := append([]*pb.ImportCrumbsRequest{}, records...)
+			requests[0] = &pb.ImportCrumbsRequest{Record: records[0].GetRecord(), DryRun: tt.dryRun, User: tt.user}
+			stream := &importStream{ctx: tt.ctx, requests: requests}
+
+			err := r.ImportCrumbs(stream)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Route.ImportCrumbs() error = %v, want code %v", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			report := stream.report
+			if report.GetAccepted() != 2 || report.GetRejected() != 6 || report.GetDryRun() != tt.dryRun || len(report.GetResults()) != len(records) {
+				t.Fatalf("Route.ImportCrumbs() = %v, want 2 accepted and 6 rejected records", report)
+			}
+			for i, result := range report.GetResults() {
+				if result.GetIndex() != int32(i) || result.GetAccepted() != (wantReasons[i] == "") || !strings.Contains(result.GetReason(), wantReasons[i]) {
+					t.Errorf("result %v = %v, want reason %q", i, result, wantReasons[i])
+				}
+				if result.GetAccepted() && (result.GetId() == "") != tt.dryRun {
+					t.Errorf("result %v id = %q, want an id unless it is a dry run", i, result.GetId())
+				}
+			}
+
+			// bob has the crumb of newTrailRoute, and the imported ones unless it is a dry run
+			if tt.dryRun {
+				bob, err := r.ListCrumbsByUser(asCaller("bob"), &pb.ListCrumbsByUserRequest{User: "bob"})
+				if err != nil {
+					t.Fatalf("Route.ListCrumbsByUser() error = %v", err)
+				}
+				if len(bob.GetCrumbs()) != 1 {
+					t.Errorf("crumbs of bob = %v, want none imported", bob.GetCrumbs())
+				}
+				return
+			}
+			imported, err := r.GetCrumb(asCaller("bob"), &pb.Id{Value: report.GetResults()[0].GetId()})
+			if err != nil {
+				t.Fatalf("Route.GetCrumb() error = %v", err)
+			}
+			if imported.GetUser() != "bob" || imported.GetMessage() != "paris" || imported.GetCreatedAt() != 1700000000000 || imported.GetVisibility() != pb.Visibility_VISIBILITY_FOLLOWERS ||
+				strings.Join(imported.GetTags(), ",") != "a,b" || imported.GetLocation().GetAltitude() != 35 || imported.GetLocation().GetAccuracy() != 5 || imported.GetVersion() != 1 {
+				t.Errorf("imported crumb = %v, want the properties of the feature", imported)
+			}
+
+			legacy, err := r.GetCrumb(context.Background(), &pb.Id{Value: report.GetResults()[1].GetId()})
+			if err != nil {
+				t.Fatalf("Route.GetCrumb() error = %v", err)
+			}
+			if legacy.GetUser() != "bob" || legacy.GetMessage() != "legacy" || legacy.GetCreatedAt() != 1700000000000 || strings.Join(legacy.GetTags(), ",") != "c" {
+				t.Errorf("imported waypoint = %v, want the legacy crumb of bob", legacy)
+			}
+		})
+	}
+}
+
+func TestRoute_ImportCrumbs_Batches(t *testing.T) {
+	var requests []*pb.ImportCrumbsRequest
+	for i := 0; i < IMPORT_BATCH_SIZE+2; i++ {
+		requests = append(requests, feature(fmt.Sprintf(`{"type":"Feature","geometry":{"type":"Point","coordinates":[2.35,48.85]},`+
+			`"properties":{"user":"alice","message":"crumb %v"}}`, i)))
+	}
+	requests[0].User = "alice"
+
+	dbClient := mocks.NewClient(t)
+	dbClient.On("InsertMany", mock.Anything, "test", "test", mock.MatchedBy(func(docs []interface{}) bool { return len(docs) == IMPORT_BATCH_SIZE })).
+		Return(func(_ context.Context, _ string, _ string, docs []interface{}) ([]string, error) {
+			ids := make([]string, len(docs))
+			for i := range ids {
+				ids[i] = fmt.Sprint(i)
+			}
+			ids[1] = ""
+			return ids, &interfaces.InsertManyError{Errors: map[int]error{1: errors.New("duplicate key error")}}
+		}).Once()
+	dbClient.On("InsertMany", mock.Anything, "test", "test", mock.MatchedBy(func(docs []interface{}) bool { return len(docs) == 2 })).
+		Return(nil, errors.New("connection refused")).Once()
+	r := NewRoute(logger.NewMockClient(), &config.Database{DatabaseName: "test", Collection: "test"}, dbClient,
+		appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"}), validator.New(), nil, nil, nil, nil)
+
+	stream := &importStream{requests: requests}
+	if err := r.ImportCrumbs(stream); err != nil {
+		t.Fatalf("Route.ImportCrumbs() error = %v", err)
+	}
+
+	report := stream.report
+	if report.GetAccepted() != IMPORT_BATCH_SIZE-1 || report.GetRejected() != 3 {
+		t.Errorf("Route.ImportCrumbs() accepted %v and rejected %v, want %v and 3", report.GetAccepted(), report.GetRejected(), IMPORT_BATCH_SIZE-1)
+	}
+	for _, i := range []int{1, IMPORT_BATCH_SIZE, IMPORT_BATCH_SIZE + 1} {
+		if result := report.GetResults()[i]; result.GetAccepted() || !strings.HasPrefix(result.GetReason(), "failed to insert") {
+			t.Errorf("result %v = %v, want a failed insert", i, result)
+		}
+	}
+	if result := report.GetResults()[2]; result.GetId() != "2" {
+		t.Errorf("result 2 = %v, want id 2", result)
+	}
+}
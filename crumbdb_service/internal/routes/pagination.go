@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// encodePageToken returns the opaque token of the page following crumb
+func encodePageToken(crumb *pb.Crumb) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%v:%v", crumb.GetCreatedAt(), crumb.GetId())))
+}
+
+// decodePageToken returns the cursor encoded by encodePageToken, nil for the empty token of the first page
+func decodePageToken(token string) (*interfaces.Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("malformed token")
+	}
+	createdAt, id, found := strings.Cut(string(data), ":")
+	if !found {
+		return nil, fmt.Errorf("malformed token")
+	}
+	created, err := strconv.ParseInt(createdAt, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed token")
+	}
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	return &interfaces.Cursor{Created: created, Id: id}, nil
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"context"
+
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+)
+
+// Positions checks the positions users report for spoofing, see spoof.Detector
+type Positions interface {
+	Check(ctx context.Context, method string, user string, coordinates []float64, accuracy *float64) error
+}
+
+// checkPosition returns an error if position, reported by user to method, is rejected as spoofed
+func (r *Route) checkPosition(ctx context.Context, method string, user string, position *pb.Point) error {
+	if r.positions == nil {
+		return nil
+	}
+	return r.positions.Check(ctx, method, user, position.GetCoordinates(), position.Accuracy)
+}
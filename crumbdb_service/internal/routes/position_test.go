@@ -0,0 +1,92 @@
+package routes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/haguru/horus/crumbdb/config"
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	"github.com/haguru/horus/crumbdb/pkg/spoof"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRoute_checkPosition(t *testing.T) {
+	far := position(2.35, 48.85)
+	tests := []struct {
+		name     string
+		call     func(r *Route, ids map[string]string) error
+		wantCode codes.Code
+		method   string
+	}{
+		{
+			name: "create near the last position",
+			call: func(r *Route, _ map[string]string) error {
+				_, err := r.Create(asCaller("bob"), &pb.Crumb{User: "bob", Message: "hi", Location: position(-122.4, 37.801)})
+				return err
+			},
+			method: pb.CrumbDB_Create_FullMethodName,
+		},
+		{
+			name: "create too far from the last position",
+			call: func(r *Route, _ map[string]string) error {
+				_, err := r.Create(asCaller("bob"), &pb.Crumb{User: "bob", Message: "hi", Location: far})
+				return err
+			},
+			wantCode: codes.FailedPrecondition,
+			method:   pb.CrumbDB_Create_FullMethodName,
+		},
+		{
+			name: "anonymous create leaves the history of the crumb user alone",
+			call: func(r *Route, _ map[string]string) error {
+				if _, err := r.Create(context.Background(), &pb.Crumb{User: "bob", Message: "hi", Location: far}); err != nil {
+					return err
+				}
+				// bob is still near the start rather than far away
+				_, err := r.Create(asCaller("bob"), &pb.Crumb{User: "bob", Message: "hi", Location: position(-122.4, 37.801)})
+				return err
+			},
+			method: pb.CrumbDB_Create_FullMethodName,
+		},
+		{
+			name: "unlock too far from the last position",
+			call: func(r *Route, ids map[string]string) error {
+				_, err := r.UnlockCrumb(asCaller("bob"), &pb.UnlockCrumbRequest{Id: ids["north"], Position: far})
+				return err
+			},
+			wantCode: codes.FailedPrecondition,
+			method:   pb.CrumbDB_UnlockCrumb_FullMethodName,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ids := newTrailRoute(t)
+			detector, err := spoof.NewDetector(&config.Spoofing{Enabled: true, Mode: spoof.MODE_REJECT, MaxSpeed: 300},
+				spoof.NewMemoryStore(), logger.NewMockClient(), r.metrics)
+			if err != nil {
+				t.Fatalf("NewDetector() error = %v", err)
+			}
+			r.positions = detector
+
+			// bob was last at the start
+			if _, err := r.UnlockCrumb(asCaller("bob"), &pb.UnlockCrumbRequest{Id: ids["start"], Position: position(-122.4, 37.8)}); err != nil {
+				t.Fatalf("Route.UnlockCrumb() error = %v", err)
+			}
+
+			err = tt.call(r, ids)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("error = %v, want code %v", err, tt.wantCode)
+			}
+			want := 0.0
+			if tt.wantCode != codes.OK {
+				want = 1
+			}
+			if got := testutil.ToFloat64(r.metrics.PositionChecks.WithLabelValues(tt.method, spoof.RESULT_REJECTED)); got != want {
+				t.Errorf("rejected checks = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -2,36 +2,82 @@ package routes
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/haguru/horus/crumbdb/config"
 	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	appLogging "github.com/haguru/horus/crumbdb/pkg/logging"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb"
 	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces"
+	appMetrics "github.com/haguru/horus/crumbdb/pkg/prometheus"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	"github.com/go-playground/validator/v10"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
+const (
+	// MAX_BATCH_SIZE is the most ids BatchGetCrumbs accepts
+	MAX_BATCH_SIZE = 100
+
+	// DEFAULT_PAGE_SIZE is the page size of ListCrumbsByUser when none is requested, MAX_PAGE_SIZE the largest
+	DEFAULT_PAGE_SIZE = 50
+	MAX_PAGE_SIZE     = 500
+)
+
+// Publisher is notified of every crumb created through the route
+type Publisher interface {
+	Publish(crumb *pb.Crumb)
+}
+
 type Route struct {
 	dbConfig  *config.Database
 	dbClient  interfaces.Client
+	follows   Follows
 	lc        logger.LoggingClient
+	metrics   *appMetrics.Metrics
+	policy    *config.Policy
+	positions Positions
+	publisher Publisher
 	validator *validator.Validate
+	// serializes the writes of crumbs to zones limiting their crumbs, see checkZones
+	zoneMu sync.Mutex
 	pb.UnimplementedCrumbDBServer
 }
 
-func NewRoute(lc logger.LoggingClient, config *config.Database, dbclient interfaces.Client, validator *validator.Validate) *Route {
+func NewRoute(lc logger.LoggingClient, config *config.Database, dbclient interfaces.Client, metrics *appMetrics.Metrics, validator *validator.Validate, publisher Publisher, follows Follows, positions Positions, policy *config.Policy) *Route {
 	return &Route{
 		dbConfig:  config,
 		dbClient:  dbclient,
+		follows:   follows,
 		lc:        lc,
+		metrics:   metrics,
+		policy:    policy,
+		positions: positions,
+		publisher: publisher,
 		validator: validator,
 	}
 }
 
 func (r *Route) Create(ctx context.Context, crumb *pb.Crumb) (*pb.Id, error) {
-	r.lc.Debugf("received Create request: %v", crumb)
+	lc := appLogging.FromContext(ctx, r.lc)
+	lc.Debug("received Create request", appLogging.Redact(crumb)...)
+
+	// an authenticated caller only creates its own crumbs
+	if caller := callerUser(ctx); caller != "" {
+		if crumb.GetUser() != "" && crumb.GetUser() != caller {
+			return nil, status.Errorf(codes.PermissionDenied, "%v cannot create the crumbs of %v", caller, crumb.GetUser())
+		}
+		crumb.User = caller
+	}
 
 	// Validate the User struct
 	err := r.validator.Struct(crumb)
@@ -41,51 +87,72 @@ func (r *Route) Create(ctx context.Context, crumb *pb.Crumb) (*pb.Id, error) {
 
 		return nil, fmt.Errorf("validation error: %s", errors)
 	}
+	if err := validateGeometry("location", crumb.GetLocation(), LOCATION_TYPES...); err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	// the history of a user only holds the positions the user is authenticated for
+	if err := r.checkPosition(ctx, pb.CrumbDB_Create_FullMethodName, callerUser(ctx), crumb.GetLocation()); err != nil {
+		return nil, err
+	}
+	release, err := r.checkZones(ctx, "", crumb.GetLocation(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// the id is generated by the database so it round-trips as a hex ObjectID
+	crumb.Id = ""
+	crumb.CreatedAt = time.Now().UnixMilli()
+	crumb.Version = 1
 
-	id, err := r.dbClient.InsertRecord(r.dbConfig.DatabaseName, r.dbConfig.Collection, crumb)
+	id, err := r.dbClient.InsertRecord(ctx, r.dbConfig.DatabaseName, r.dbConfig.Collection, crumb)
+	release()
 	if err != nil {
 		return nil, err
 	}
+	appMetrics.IncWithExemplar(ctx, r.metrics.CrumbsCreated)
+
+	if r.publisher != nil {
+		created := proto.Clone(crumb).(*pb.Crumb)
+		created.Id = id
+		r.publisher.Publish(created)
+	}
 
 	return &pb.Id{Value: id}, nil
 }
 
 func (r *Route) GetCrumbs(point *pb.Point, stream pb.CrumbDB_GetCrumbsServer) error {
-	r.lc.Debug("received new GetCumbs request")
-
-	// Validate the User struct
-	err := r.validator.Struct(point)
-	if err != nil {
-		// Validation failed, handle the error
-		errors := err.(validator.ValidationErrors)
+	lc := appLogging.FromContext(stream.Context(), r.lc)
+	lc.Debug("received new GetCrumbs request", appLogging.Redact(point)...)
 
-		return fmt.Errorf("validation error: %s", errors)
+	if err := validateGeometry("", point, QUERY_TYPES...); err != nil {
+		return invalidArgument(err)
 	}
 
-	data, err := r.dbClient.SpaitalQuery(point.Type, point.GetCoordinates(), r.dbConfig.DatabaseName, r.dbConfig.Collection)
+	data, err := r.dbClient.SpaitalQuery(stream.Context(), point.Type, point.GetCoordinates(), r.dbConfig.DatabaseName, r.dbConfig.Collection)
 	if err != nil {
-		r.lc.Errorf("failed to run spatial query: %v", err)
+		lc.Errorf("failed to run spatial query: %v", err)
 		return err
 	}
-	for _, item := range data {
-		// unmarshall data to grpc data type
-		doc, err := bson.Marshal(item)
-		if err != nil {
-			r.lc.Errorf("failed to marshal an item in data: %v", err)
-			return err
-		}
+	appMetrics.ObserveWithExemplar(stream.Context(), r.metrics.SpatialQueryResults, float64(len(data)))
 
-		crumb := &pb.Crumb{}
-		err = bson.Unmarshal(doc, crumb)
+	crumbs := make([]*pb.Crumb, 0, len(data))
+	for _, item := range data {
+		crumb, err := toCrumb(item)
 		if err != nil {
-			r.lc.Errorf("failed to unmarshal an item in data: %v", err)
+			lc.Errorf("failed to convert an item in data: %v", err)
 			return err
 		}
+		crumbs = append(crumbs, crumb)
+	}
 
+	visible := r.visibleCrumbs(stream.Context(), crumbs)
+	redactLocked(visible, callerUser(stream.Context()))
+	for _, crumb := range visible {
 		// send crumb
 		err = stream.Send(crumb)
 		if err != nil {
-			r.lc.Errorf("failed to send item in data: %v", err)
+			lc.Errorf("failed to send item in data: %v", err)
 			return err
 		}
 
@@ -93,29 +160,226 @@ func (r *Route) GetCrumbs(point *pb.Point, stream pb.CrumbDB_GetCrumbsServer) er
 	return nil
 }
 
-func (r *Route) Update(ctx context.Context, crumb *pb.Crumb) (*pb.Id, error) {
-	r.lc.Debug("received new update request")
+func (r *Route) GetCrumb(ctx context.Context, id *pb.Id) (*pb.Crumb, error) {
+	lc := appLogging.FromContext(ctx, r.lc)
+	lc.Debug("received new GetCrumb request", "id", id.GetValue())
+
+	doc, err := r.dbClient.FindOne(ctx, r.dbConfig.DatabaseName, r.dbConfig.Collection, id.GetValue())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, status.Errorf(codes.NotFound, "crumb %v not found", id.GetValue())
+	}
+	if err != nil {
+		lc.Errorf("failed to find data with id '%v': %v", id.GetValue(), err)
+		return nil, err
+	}
+
+	crumb, err := toCrumb(*doc)
+	if err != nil {
+		return nil, err
+	}
+	// a crumb the caller cannot see is not found rather than denied so its existence is not disclosed
+	if len(r.visibleCrumbs(ctx, []*pb.Crumb{crumb})) == 0 {
+		return nil, status.Errorf(codes.NotFound, "crumb %v not found", id.GetValue())
+	}
+	redactLocked([]*pb.Crumb{crumb}, callerUser(ctx))
+	return crumb, nil
+}
+
+func (r *Route) BatchGetCrumbs(ctx context.Context, ids *pb.Ids) (*pb.Crumbs, error) {
+	lc := appLogging.FromContext(ctx, r.lc)
+	lc.Debug("received new BatchGetCrumbs request", "ids", len(ids.GetValues()))
 
-	messageItem := map[string]interface{}{"message": crumb.Message}
+	if len(ids.GetValues()) > MAX_BATCH_SIZE {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %v ids can be read at once, got %v", MAX_BATCH_SIZE, len(ids.GetValues()))
+	}
+	if len(ids.GetValues()) == 0 {
+		return &pb.Crumbs{}, nil
+	}
 
-	err := r.dbClient.Update(r.dbConfig.DatabaseName, r.dbConfig.Collection, crumb.Id, messageItem)
+	data, err := r.dbClient.FindMany(ctx, r.dbConfig.DatabaseName, r.dbConfig.Collection, ids.GetValues())
 	if err != nil {
-		r.lc.Errorf("failed to update data with id '%v' : %v", crumb.GetId(), err)
+		lc.Errorf("failed to find data by ids: %v", err)
 		return nil, err
 	}
 
-	return &pb.Id{
-		Value: crumb.GetId(),
-	}, nil
+	converted := make([]*pb.Crumb, 0, len(data))
+	for _, item := range data {
+		crumb, err := toCrumb(item)
+		if err != nil {
+			lc.Errorf("failed to convert an item in data: %v", err)
+			return nil, err
+		}
+		converted = append(converted, crumb)
+	}
+	// crumbs the caller cannot see are reported as not found
+	found := map[string]*pb.Crumb{}
+	for _, crumb := range r.visibleCrumbs(ctx, converted) {
+		found[crumb.GetId()] = crumb
+	}
+
+	// the crumbs follow the order of the ids, repeated ids included
+	crumbs := make([]*pb.Crumb, 0, len(ids.GetValues()))
+	var missing []string
+	for _, id := range ids.GetValues() {
+		crumb, ok := found[strings.ToLower(id)]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		crumbs = append(crumbs, crumb)
+	}
+	if len(missing) > 0 {
+		return nil, status.Errorf(codes.NotFound, "crumbs %v not found", strings.Join(missing, ", "))
+	}
+	redactLocked(crumbs, callerUser(ctx))
+
+	return &pb.Crumbs{Crumbs: crumbs}, nil
+}
+
+func (r *Route) ListCrumbsByUser(ctx context.Context, req *pb.ListCrumbsByUserRequest) (*pb.ListCrumbsByUserResponse, error) {
+	lc := appLogging.FromContext(ctx, r.lc)
+	lc.Debug("received new ListCrumbsByUser request", "user", req.GetUser(), "page_size", req.GetPageSize())
+
+	if req.GetUser() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user is required")
+	}
+	if req.GetPageSize() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid page size %v", req.GetPageSize())
+	}
+	pageSize := int(req.GetPageSize())
+	if pageSize == 0 {
+		pageSize = DEFAULT_PAGE_SIZE
+	}
+	pageSize = min(pageSize, MAX_PAGE_SIZE)
+
+	after, err := decodePageToken(req.GetPageToken())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid page token: %v", err)
+	}
+
+	// one more crumb than the page tells whether there is a next page
+	data, err := r.dbClient.FindByUser(ctx, r.dbConfig.DatabaseName, r.dbConfig.Collection, req.GetUser(), after, pageSize+1)
+	if err != nil {
+		lc.Errorf("failed to find data of user '%v': %v", req.GetUser(), err)
+		return nil, err
+	}
+
+	res := &pb.ListCrumbsByUserResponse{}
+	for i, item := range data {
+		if i == pageSize {
+			res.NextPageToken = encodePageToken(res.Crumbs[i-1])
+			break
+		}
+		crumb, err := toCrumb(item)
+		if err != nil {
+			lc.Errorf("failed to convert an item in data: %v", err)
+			return nil, err
+		}
+		res.Crumbs = append(res.Crumbs, crumb)
+	}
+	// the page token is taken before the hidden crumbs are left out so a page may be shorter than its size
+	res.Crumbs = r.visibleCrumbs(ctx, res.Crumbs)
+	redactLocked(res.Crumbs, callerUser(ctx))
+
+	return res, nil
+}
+
+func (r *Route) Update(ctx context.Context, req *pb.UpdateCrumbRequest) (*pb.Crumb, error) {
+	lc := appLogging.FromContext(ctx, r.lc)
+	lc.Debug("received new update request", appLogging.Redact(req)...)
+
+	crumb := req.GetCrumb()
+	if crumb.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "crumb id is required")
+	}
+	paths := req.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		paths = DEFAULT_UPDATE_PATHS
+	}
+	items, err := r.updateItems(crumb, paths)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	// only the owner changes a crumb, the version is compared once the caller is known to own it
+	if _, err := r.ownedCrumb(ctx, crumb.GetId()); err != nil {
+		return nil, err
+	}
+	release := func() {}
+	if _, ok := items[mongodb.SPATIAL_INDEX_KEY]; ok {
+		var err error
+		release, err = r.checkZones(ctx, crumb.GetId(), crumb.GetLocation(), nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	doc, err := r.dbClient.Update(ctx, r.dbConfig.DatabaseName, r.dbConfig.Collection, crumb.GetId(), crumb.GetVersion(), items)
+	release()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, status.Errorf(codes.NotFound, "crumb %v not found", crumb.GetId())
+	}
+	if errors.Is(err, interfaces.ErrVersionMismatch) {
+		return nil, r.versionMismatch(ctx, crumb)
+	}
+	if err != nil {
+		lc.Errorf("failed to update data with id '%v' : %v", crumb.GetId(), err)
+		return nil, err
+	}
+	appMetrics.IncWithExemplar(ctx, r.metrics.CrumbsUpdated)
+	// the trails through a moved crumb follow it
+	if _, ok := items[mongodb.SPATIAL_INDEX_KEY]; ok {
+		if err := r.moveInTrails(ctx, crumb.GetId()); err != nil {
+			lc.Errorf("failed to move '%v' in its trails: %v", crumb.GetId(), err)
+			return nil, err
+		}
+	}
+
+	updated, err := toCrumb(*doc)
+	if err != nil {
+		lc.Errorf("failed to convert the updated data: %v", err)
+		return nil, err
+	}
+	redactLocked([]*pb.Crumb{updated}, callerUser(ctx))
+	return updated, nil
 }
 
 func (r *Route) Delete(ctx context.Context, id *pb.Id) (*pb.Id, error) {
-	r.lc.Debug("received new Delete request")
-	// TODO: if nothing was deleted an error should be returned
-	err := r.dbClient.Delete(r.dbConfig.DatabaseName, r.dbConfig.Collection, id.GetValue())
+	lc := appLogging.FromContext(ctx, r.lc)
+	lc.Debug("received new Delete request", "id", id.GetValue())
+
+	if _, err := r.ownedCrumb(ctx, id.GetValue()); err != nil {
+		return nil, err
+	}
+	// the trails are changed first, so a trail never refers to a deleted crumb
+	if err := r.removeFromTrails(ctx, id.GetValue()); err != nil {
+		lc.Errorf("failed to remove '%v' from its trails: %v", id.GetValue(), err)
+		return nil, err
+	}
+
+	err := r.dbClient.Delete(ctx, r.dbConfig.DatabaseName, r.dbConfig.Collection, id.GetValue())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, status.Errorf(codes.NotFound, "crumb %v not found", id.GetValue())
+	}
 	if err != nil {
-		r.lc.Errorf("failed to delete data with id '%v': %v", id.GetValue(), err)
+		lc.Errorf("failed to delete data with id '%v': %v", id.GetValue(), err)
 		return nil, err
 	}
+	appMetrics.IncWithExemplar(ctx, r.metrics.CrumbsDeleted)
+
 	return id, nil
 }
+
+// toCrumb converts a document returned by the database to a crumb
+func toCrumb(item bson.D) (*pb.Crumb, error) {
+	doc, err := bson.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal document: %v", err)
+	}
+
+	crumb := &pb.Crumb{}
+	err = bson.Unmarshal(doc, crumb)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal document: %v", err)
+	}
+	return crumb, nil
+}
@@ -2,22 +2,42 @@ package routes
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/haguru/horus/crumbdb/config"
 	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
 	grpcMock "github.com/haguru/horus/crumbdb/internal/routes/protos/mocks"
 	"github.com/haguru/horus/crumbdb/pkg/mongodb"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces"
 	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces/mocks"
+	appMetrics "github.com/haguru/horus/crumbdb/pkg/prometheus"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	"github.com/go-playground/validator/v10"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
+type recordingPublisher struct {
+	crumbs []*pb.Crumb
+}
+
+func (p *recordingPublisher) Publish(crumb *pb.Crumb) {
+	p.crumbs = append(p.crumbs, crumb)
+}
+
 func TestRoute_Create(t *testing.T) {
 	type fields struct {
 		dbCconfig *config.Database
@@ -88,20 +108,47 @@ func TestRoute_Create(t *testing.T) {
 			want:            nil,
 			wantErr:         true,
 		},
+		{
+			name:   "crumb of the caller",
+			fields: fields{dbCconfig: &config.Database{DatabaseName: "test", Collection: "test"}, lc: logger.MockLogger{}},
+			args: args{
+				ctx:   asCaller("test_user"),
+				crumb: &pb.Crumb{Location: &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{-122.6, 45.6}}, Message: "mine"},
+			},
+			insertRecordRtn: "test_id",
+			want:            &pb.Id{Value: "test_id"},
+		},
+		{
+			name:   "crumb of another user than the caller",
+			fields: fields{dbCconfig: &config.Database{DatabaseName: "test", Collection: "test"}, lc: logger.MockLogger{}},
+			args: args{
+				ctx:   asCaller("mallory"),
+				crumb: &pb.Crumb{Location: &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{-122.6, 45.6}}, User: "test_user", Message: "yours"},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient := mocks.NewClient(t)
-			mockClient.On("InsertRecord", mock.Anything, mock.Anything, mock.Anything).Return(tt.insertRecordRtn, tt.errorRtn)
+			if tt.insertRecordRtn != "" || tt.errorRtn != nil {
+				mockClient.On("InsertRecord", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.insertRecordRtn, tt.errorRtn)
+			}
+			publisher := &recordingPublisher{}
 
 			r := &Route{
 				dbConfig:  tt.fields.dbCconfig,
 				dbClient:  mockClient,
 				lc:        tt.fields.lc,
+				metrics:   appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"}),
+				publisher: publisher,
 				validator: validator.New(),
 			}
 
 			got, err := r.Create(tt.args.ctx, tt.args.crumb)
+			if want := successCount(tt.wantErr); testutil.ToFloat64(r.metrics.CrumbsCreated) != want {
+				t.Errorf("CrumbsCreated = %v, want %v", testutil.ToFloat64(r.metrics.CrumbsCreated), want)
+			}
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Route.Create() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -109,6 +156,21 @@ func TestRoute_Create(t *testing.T) {
 			if !tt.wantErr && !reflect.DeepEqual(got.Value, tt.want.Value) {
 				t.Errorf("Route.Create() = %v, want %v", got, tt.want)
 			}
+			if want := int(successCount(tt.wantErr)); len(publisher.crumbs) != want {
+				t.Fatalf("published %v crumbs, want %v", len(publisher.crumbs), want)
+			}
+			if !tt.wantErr && publisher.crumbs[0].GetId() != tt.want.Value {
+				t.Errorf("published crumb id = %v, want %v", publisher.crumbs[0].GetId(), tt.want.Value)
+			}
+			if !tt.wantErr && publisher.crumbs[0].GetCreatedAt() == 0 {
+				t.Errorf("published crumb has no creation time")
+			}
+			if !tt.wantErr && publisher.crumbs[0].GetVersion() != 1 {
+				t.Errorf("published crumb version = %v, want 1", publisher.crumbs[0].GetVersion())
+			}
+			if !tt.wantErr && publisher.crumbs[0].GetUser() != "test_user" {
+				t.Errorf("published crumb user = %v, want test_user", publisher.crumbs[0].GetUser())
+			}
 		})
 	}
 }
@@ -187,12 +249,14 @@ func TestRoute_GetCrumbs(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			stream := grpcMock.NewServerStreamingServer[pb.Crumb](t)
 			stream.On("Send", mock.Anything).Return(tt.streamErrRtn).Maybe()
+			stream.On("Context").Return(context.Background()).Maybe()
 			mockClient := mocks.NewClient(t)
-			mockClient.On("SpaitalQuery", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.clientRtn, tt.clientErrorRtn)
+			mockClient.On("SpaitalQuery", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.clientRtn, tt.clientErrorRtn)
 			r := &Route{
 				dbConfig:  tt.fields.dbCconfig,
 				dbClient:  mockClient,
 				lc:        tt.fields.lc,
+				metrics:   appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"}),
 				validator: validator.New(),
 			}
 			if err := r.GetCrumbs(tt.point, stream); (err != nil) != tt.wantErr {
@@ -203,84 +267,332 @@ func TestRoute_GetCrumbs(t *testing.T) {
 }
 
 func TestRoute_Update(t *testing.T) {
-	type fields struct {
-		dbCconfig *config.Database
-		lc        logger.LoggingClient
-	}
-	type args struct {
-		ctx   context.Context
-		crumb *pb.Crumb
+	id := primitive.NewObjectID()
+	point := &pb.Point{Type: "Point", Coordinates: []float64{2.35, 48.85}}
+	stored := func(fields ...bson.E) *bson.D {
+		doc := append(bson.D{{Key: "_id", Value: id}, {Key: "user", Value: "test_user"}}, fields...)
+		return &doc
 	}
+
 	tests := []struct {
-		name           string
-		fields         fields
-		args           args
-		clientRtn      string
-		clientErrorRtn error
-		want           *pb.Id
-		wantErr        bool
+		name string
+		req  *pb.UpdateCrumbRequest
+		// caller is the owner of the crumb unless set
+		caller      string
+		version     int64
+		items       map[string]interface{}
+		updateRtn   *bson.D
+		updateErr   error
+		findRtn     *bson.D
+		findErr     error
+		want        *pb.Crumb
+		wantCode    codes.Code
+		wantCurrent string
 	}{
 		{
-			name: "successful update",
-			fields: fields{
-				dbCconfig: &config.Database{
-					DatabaseName: "test",
-					Collection:   "test",
-				},
-				lc: logger.NewMockClient(),
+			name:      "message by default",
+			req:       &pb.UpdateCrumbRequest{Crumb: &pb.Crumb{Id: id.Hex(), Message: "bye", User: "ignored"}},
+			items:     map[string]interface{}{"message": "bye"},
+			updateRtn: stored(bson.E{Key: "message", Value: "bye"}, bson.E{Key: "version", Value: int64(2)}),
+			want:      &pb.Crumb{Id: id.Hex(), User: "test_user", Message: "bye", Version: 2},
+		},
+		{
+			name: "location",
+			req: &pb.UpdateCrumbRequest{
+				Crumb:      &pb.Crumb{Id: id.Hex(), Location: point},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"location"}},
 			},
-			args: args{
-				ctx: context.Background(),
-				crumb: &pb.Crumb{
-					Id:      "test_id",
-					Message: "testing update method",
-				},
+			items:     map[string]interface{}{"location": point},
+			updateRtn: stored(bson.E{Key: "version", Value: int64(2)}),
+			want:      &pb.Crumb{Id: id.Hex(), User: "test_user", Version: 2},
+		},
+		{
+			name: "visibility and cleared tags at the current version",
+			req: &pb.UpdateCrumbRequest{
+				Crumb:      &pb.Crumb{Id: id.Hex(), Visibility: pb.Visibility_VISIBILITY_PRIVATE, Version: 3},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"visibility", "tags"}},
 			},
-			clientErrorRtn: nil,
-			want: &pb.Id{
-				Value: "test_id",
+			version: 3,
+			items:   map[string]interface{}{"visibility": pb.Visibility_VISIBILITY_PRIVATE, "tags": []string{}},
+			updateRtn: stored(
+				bson.E{Key: "visibility", Value: int32(2)},
+				bson.E{Key: "tags", Value: bson.A{}},
+				bson.E{Key: "version", Value: int64(4)},
+			),
+			want: &pb.Crumb{Id: id.Hex(), User: "test_user", Visibility: pb.Visibility_VISIBILITY_PRIVATE, Tags: []string{}, Version: 4},
+		},
+		{
+			name: "specific users",
+			req: &pb.UpdateCrumbRequest{
+				Crumb:      &pb.Crumb{Id: id.Hex(), Visibility: pb.Visibility_VISIBILITY_SPECIFIC_USERS, AllowedUsers: []string{"alice"}},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"visibility", "allowed_users"}},
 			},
-			wantErr: false,
+			items: map[string]interface{}{"visibility": pb.Visibility_VISIBILITY_SPECIFIC_USERS, "allowed_users": []string{"alice"}},
+			updateRtn: stored(
+				bson.E{Key: "visibility", Value: int32(3)},
+				bson.E{Key: "allowed_users", Value: bson.A{"alice"}},
+				bson.E{Key: "version", Value: int64(2)},
+			),
+			want: &pb.Crumb{Id: id.Hex(), User: "test_user", Visibility: pb.Visibility_VISIBILITY_SPECIFIC_USERS, AllowedUsers: []string{"alice"}, Version: 2},
 		},
 		{
-			name: "client failed to update",
-			fields: fields{
-				dbCconfig: &config.Database{
-					DatabaseName: "test",
-					Collection:   "test",
-				},
-				lc: logger.NewMockClient(),
+			name:        "stale version",
+			req:         &pb.UpdateCrumbRequest{Crumb: &pb.Crumb{Id: id.Hex(), Message: "bye", Version: 3}},
+			version:     3,
+			items:       map[string]interface{}{"message": "bye"},
+			updateErr:   interfaces.ErrVersionMismatch,
+			findRtn:     stored(bson.E{Key: "version", Value: int64(5)}),
+			wantCode:    codes.Aborted,
+			wantCurrent: "5",
+		},
+		{
+			name:        "missing version of a versioned crumb",
+			req:         &pb.UpdateCrumbRequest{Crumb: &pb.Crumb{Id: id.Hex(), Message: "bye"}},
+			items:       map[string]interface{}{"message": "bye"},
+			updateErr:   interfaces.ErrVersionMismatch,
+			findRtn:     stored(bson.E{Key: "version", Value: int64(2)}),
+			wantCode:    codes.FailedPrecondition,
+			wantCurrent: "2",
+		},
+		{
+			name:     "not found",
+			req:      &pb.UpdateCrumbRequest{Crumb: &pb.Crumb{Id: id.Hex(), Message: "bye"}},
+			items:    map[string]interface{}{"message": "bye"},
+			findErr:  mongo.ErrNoDocuments,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:      "deleted concurrently",
+			req:       &pb.UpdateCrumbRequest{Crumb: &pb.Crumb{Id: id.Hex(), Message: "bye"}},
+			items:     map[string]interface{}{"message": "bye"},
+			updateErr: mongo.ErrNoDocuments,
+			wantCode:  codes.NotFound,
+		},
+		{
+			name: "tags of a locked crumb of another user",
+			req: &pb.UpdateCrumbRequest{
+				Crumb:      &pb.Crumb{Id: id.Hex(), Tags: []string{"mine"}},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"tags"}},
 			},
-			args: args{
-				ctx: context.Background(),
-				crumb: &pb.Crumb{
-					Id:      "test_id",
-					Message: "testing update method",
-				},
+			caller:   "mallory",
+			items:    map[string]interface{}{"tags": []string{"mine"}},
+			findRtn:  stored(bson.E{Key: "message", Value: "secret"}, bson.E{Key: "locked", Value: true}),
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name: "unlocking a crumb of another user",
+			req: &pb.UpdateCrumbRequest{
+				Crumb:      &pb.Crumb{Id: id.Hex()},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"locked"}},
 			},
-			clientErrorRtn: fmt.Errorf("fail"),
-			want:           nil,
-			wantErr:        true,
+			caller:   "mallory",
+			items:    map[string]interface{}{"locked": false},
+			findRtn:  stored(bson.E{Key: "locked", Value: true}),
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name: "publishing a private crumb of another user",
+			req: &pb.UpdateCrumbRequest{
+				Crumb:      &pb.Crumb{Id: id.Hex(), Visibility: pb.Visibility_VISIBILITY_PUBLIC, Version: 1},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"visibility"}},
+			},
+			caller:   "mallory",
+			items:    map[string]interface{}{"visibility": pb.Visibility_VISIBILITY_PUBLIC},
+			findRtn:  stored(bson.E{Key: "visibility", Value: int32(2)}, bson.E{Key: "version", Value: int64(5)}),
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "anonymous caller",
+			req:      &pb.UpdateCrumbRequest{Crumb: &pb.Crumb{Id: id.Hex(), Message: "bye"}},
+			caller:   "-",
+			items:    map[string]interface{}{"message": "bye"},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:      "database error",
+			req:       &pb.UpdateCrumbRequest{Crumb: &pb.Crumb{Id: id.Hex(), Message: "bye"}},
+			items:     map[string]interface{}{"message": "bye"},
+			updateErr: fmt.Errorf("fail"),
+			wantCode:  codes.Unknown,
+		},
+		{
+			name:     "missing id",
+			req:      &pb.UpdateCrumbRequest{Crumb: &pb.Crumb{Message: "bye"}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "empty message",
+			req:      &pb.UpdateCrumbRequest{Crumb: &pb.Crumb{Id: id.Hex()}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "missing location",
+			req: &pb.UpdateCrumbRequest{
+				Crumb:      &pb.Crumb{Id: id.Hex()},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"location"}},
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "invalid location",
+			req: &pb.UpdateCrumbRequest{
+				Crumb:      &pb.Crumb{Id: id.Hex(), Location: &pb.Point{Type: "LineString", Coordinates: []float64{2.35, 48.85}}},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"location"}},
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "invalid visibility",
+			req: &pb.UpdateCrumbRequest{
+				Crumb:      &pb.Crumb{Id: id.Hex(), Visibility: 42},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"visibility"}},
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "empty allowed user",
+			req: &pb.UpdateCrumbRequest{
+				Crumb:      &pb.Crumb{Id: id.Hex(), AllowedUsers: []string{"alice", ""}},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"allowed_users"}},
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "unlock radius too large",
+			req: &pb.UpdateCrumbRequest{
+				Crumb:      &pb.Crumb{Id: id.Hex(), Locked: true, UnlockRadius: MAX_UNLOCK_RADIUS + 1},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"locked", "unlock_radius"}},
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "immutable path",
+			req: &pb.UpdateCrumbRequest{
+				Crumb:      &pb.Crumb{Id: id.Hex(), User: "mallory"},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"user"}},
+			},
+			wantCode: codes.InvalidArgument,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			ctx := asCaller("test_user")
+			switch tt.caller {
+			case "":
+			case "-":
+				ctx = context.Background()
+			default:
+				ctx = asCaller(tt.caller)
+			}
+			findRtn := tt.findRtn
+			if findRtn == nil {
+				findRtn = stored()
+			}
+
 			mockClient := mocks.NewClient(t)
-			mockClient.On("Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.clientErrorRtn)
-			r := &Route{
-				dbConfig:  tt.fields.dbCconfig,
-				dbClient:  mockClient,
-				lc:        tt.fields.lc,
-				validator: validator.New(),
+			if tt.items != nil {
+				mockClient.On("FindOne", mock.Anything, "test", "test", id.Hex()).Return(findRtn, tt.findErr)
 			}
-			got, err := r.Update(tt.args.ctx, tt.args.crumb)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Route.Update() error = %v, wantErr %v", err, tt.wantErr)
-				return
+			if tt.items != nil && tt.findErr == nil && tt.caller == "" {
+				mockClient.On("Update", mock.Anything, "test", "test", id.Hex(), tt.version, tt.items).Return(tt.updateRtn, tt.updateErr)
 			}
-			if !reflect.DeepEqual(got, tt.want) {
+			if _, moved := tt.items["location"]; moved && tt.updateErr == nil {
+				mockClient.On("FindByField", mock.Anything, "test", DEFAULT_TRAIL_COLLECTION, TRAIL_CRUMB_IDS_KEY, id.Hex()).Return(nil, nil)
+			}
+			r := newTestRoute(mockClient)
+
+			got, err := r.Update(ctx, tt.req)
+			if want := successCount(err != nil); testutil.ToFloat64(r.metrics.CrumbsUpdated) != want {
+				t.Errorf("CrumbsUpdated = %v, want %v", testutil.ToFloat64(r.metrics.CrumbsUpdated), want)
+			}
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Route.Update() error = %v, want code %v", err, tt.wantCode)
+			}
+			if !proto.Equal(got, tt.want) {
 				t.Errorf("Route.Update() = %v, want %v", got, tt.want)
 			}
+			if tt.wantCurrent == "" {
+				return
+			}
+			var current string
+			for _, detail := range status.Convert(err).Details() {
+				if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetReason() == VERSION_MISMATCH {
+					current = info.GetMetadata()[CURRENT_VERSION_KEY]
+				}
+			}
+			if current != tt.wantCurrent {
+				t.Errorf("Route.Update() current version = %q, want %q", current, tt.wantCurrent)
+			}
+		})
+	}
+}
+
+func TestRoute_InvalidGeometry(t *testing.T) {
+	id := primitive.NewObjectID().Hex()
+	swapped := &pb.Point{Type: "Point", Coordinates: []float64{37.8, -122.4}}
+	clockwise := &pb.Point{Type: "Polygon", Coordinates: []float64{-122.5, 37.7, -122.5, 37.9, -122.3, 37.9, -122.3, 37.7}}
+
+	tests := []struct {
+		name string
+		call func(r *Route) error
+		want []string
+	}{
+		{
+			name: "create",
+			call: func(r *Route) error {
+				_, err := r.Create(context.Background(), &pb.Crumb{User: "test_user", Message: "hi", Location: swapped})
+				return err
+			},
+			want: []string{"location.coordinates[1]"},
+		},
+		{
+			name: "create with a polygon",
+			call: func(r *Route) error {
+				_, err := r.Create(context.Background(), &pb.Crumb{User: "test_user", Message: "hi", Location: &pb.Point{Type: "Polygon"}})
+				return err
+			},
+			want: []string{"location.type"},
+		},
+		{
+			name: "update",
+			call: func(r *Route) error {
+				_, err := r.Update(context.Background(), &pb.UpdateCrumbRequest{
+					Crumb:      &pb.Crumb{Id: id, Location: &pb.Point{Type: "NotAPoint", Coordinates: []float64{-122.4, 37.8}}},
+					UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"location"}},
+				})
+				return err
+			},
+			want: []string{"crumb.location.type"},
+		},
+		{
+			name: "get crumbs",
+			call: func(r *Route) error {
+				stream := grpcMock.NewServerStreamingServer[pb.Crumb](t)
+				stream.On("Context").Return(context.Background())
+				return r.GetCrumbs(clockwise, stream)
+			},
+			want: []string{"coordinates"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRoute(mocks.NewClient(t))
+
+			err := tt.call(r)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Fatalf("error = %v, want code %v", err, codes.InvalidArgument)
+			}
+			var fields []string
+			for _, detail := range status.Convert(err).Details() {
+				if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+					for _, violation := range badRequest.GetFieldViolations() {
+						fields = append(fields, violation.GetField())
+					}
+				}
+			}
+			if !reflect.DeepEqual(fields, tt.want) {
+				t.Errorf("field violations = %v, want %v", fields, tt.want)
+			}
 		})
 	}
 }
@@ -294,13 +606,17 @@ func TestRoute_Delete(t *testing.T) {
 		ctx context.Context
 		id  *pb.Id
 	}
+	owned := &bson.D{{Key: "_id", Value: "test_id"}, {Key: "user", Value: "test_user"}}
 	tests := []struct {
 		name           string
 		fields         fields
 		args           args
+		findRtn        *bson.D
+		findErr        error
 		clientErrorRtn error
 		want           *pb.Id
 		wantErr        bool
+		wantCode       codes.Code
 	}{
 		{
 			name: "succesful delete",
@@ -312,7 +628,7 @@ func TestRoute_Delete(t *testing.T) {
 				lc: logger.NewMockClient(),
 			},
 			args: args{
-				ctx: context.Background(),
+				ctx: asCaller("test_user"),
 				id: &pb.Id{
 					Value: "test_id",
 				},
@@ -324,7 +640,7 @@ func TestRoute_Delete(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "succesful delete",
+			name: "failed delete",
 			fields: fields{
 				dbCconfig: &config.Database{
 					DatabaseName: "test",
@@ -333,7 +649,7 @@ func TestRoute_Delete(t *testing.T) {
 				lc: logger.NewMockClient(),
 			},
 			args: args{
-				ctx: context.Background(),
+				ctx: asCaller("test_user"),
 				id: &pb.Id{
 					Value: "test_id",
 				},
@@ -341,21 +657,87 @@ func TestRoute_Delete(t *testing.T) {
 			clientErrorRtn: fmt.Errorf("failed"),
 			want:           nil,
 			wantErr:        true,
+			wantCode:       codes.Unknown,
+		},
+		{
+			name: "deleted concurrently",
+			fields: fields{
+				dbCconfig: &config.Database{
+					DatabaseName: "test",
+					Collection:   "test",
+				},
+				lc: logger.NewMockClient(),
+			},
+			args: args{
+				ctx: asCaller("test_user"),
+				id: &pb.Id{
+					Value: "test_id",
+				},
+			},
+			clientErrorRtn: mongo.ErrNoDocuments,
+			want:           nil,
+			wantErr:        true,
+			wantCode:       codes.NotFound,
+		},
+		{
+			name:     "missing crumb",
+			fields:   fields{dbCconfig: &config.Database{DatabaseName: "test", Collection: "test"}, lc: logger.NewMockClient()},
+			args:     args{ctx: asCaller("test_user"), id: &pb.Id{Value: "test_id"}},
+			findErr:  mongo.ErrNoDocuments,
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "crumb of another user",
+			fields:   fields{dbCconfig: &config.Database{DatabaseName: "test", Collection: "test"}, lc: logger.NewMockClient()},
+			args:     args{ctx: asCaller("mallory"), id: &pb.Id{Value: "test_id"}},
+			wantErr:  true,
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:   "private crumb of another user",
+			fields: fields{dbCconfig: &config.Database{DatabaseName: "test", Collection: "test"}, lc: logger.NewMockClient()},
+			args:   args{ctx: asCaller("mallory"), id: &pb.Id{Value: "test_id"}},
+			findRtn: &bson.D{
+				{Key: "_id", Value: "test_id"}, {Key: "user", Value: "test_user"}, {Key: "visibility", Value: int32(pb.Visibility_VISIBILITY_PRIVATE)},
+			},
+			wantErr:  true,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "anonymous caller",
+			fields:   fields{dbCconfig: &config.Database{DatabaseName: "test", Collection: "test"}, lc: logger.NewMockClient()},
+			args:     args{ctx: context.Background(), id: &pb.Id{Value: "test_id"}},
+			wantErr:  true,
+			wantCode: codes.PermissionDenied,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			findRtn := tt.findRtn
+			if findRtn == nil {
+				findRtn = owned
+			}
 			mockClient := mocks.NewClient(t)
-			mockClient.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(tt.clientErrorRtn)
+			mockClient.On("FindOne", mock.Anything, "test", "test", "test_id").Return(findRtn, tt.findErr)
+			// only the owner reaches the trails and the crumb
+			if tt.findErr == nil && callerUser(tt.args.ctx) == "test_user" {
+				mockClient.On("FindByField", mock.Anything, mock.Anything, DEFAULT_TRAIL_COLLECTION, TRAIL_CRUMB_IDS_KEY, "test_id").Return(nil, nil)
+				mockClient.On("Delete", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.clientErrorRtn)
+			}
 			r := &Route{
 				dbConfig:  tt.fields.dbCconfig,
 				dbClient:  mockClient,
 				lc:        tt.fields.lc,
+				metrics:   appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"}),
 				validator: validator.New(),
 			}
 			got, err := r.Delete(tt.args.ctx, tt.args.id)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Route.Delete() error = %v, wantErr %v", err, tt.wantErr)
+			if want := successCount(tt.wantErr); testutil.ToFloat64(r.metrics.CrumbsDeleted) != want {
+				t.Errorf("CrumbsDeleted = %v, want %v", testutil.ToFloat64(r.metrics.CrumbsDeleted), want)
+			}
+			if (err != nil) != tt.wantErr || status.Code(err) != tt.wantCode {
+				t.Errorf("Route.Delete() error = %v, wantErr %v, want code %v", err, tt.wantErr, tt.wantCode)
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
@@ -364,3 +746,238 @@ func TestRoute_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestRoute_GetCrumb(t *testing.T) {
+	id := primitive.NewObjectID()
+	tests := []struct {
+		name     string
+		doc      *bson.D
+		errorRtn error
+		want     *pb.Crumb
+		wantCode codes.Code
+	}{
+		{
+			name: "found",
+			doc:  &bson.D{{Key: "_id", Value: id}, {Key: "user", Value: "test_user"}, {Key: "message", Value: "hi"}, {Key: "created_at", Value: int64(1000)}},
+			want: &pb.Crumb{Id: id.Hex(), User: "test_user", Message: "hi", CreatedAt: 1000},
+		},
+		{
+			name:     "private crumb of another user",
+			doc:      &bson.D{{Key: "_id", Value: id}, {Key: "user", Value: "test_user"}, {Key: "message", Value: "hi"}, {Key: "visibility", Value: int32(pb.Visibility_VISIBILITY_PRIVATE)}},
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "not found",
+			errorRtn: mongo.ErrNoDocuments,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "database error",
+			errorRtn: fmt.Errorf("failed"),
+			wantCode: codes.Unknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewClient(t)
+			mockClient.On("FindOne", mock.Anything, "test", "test", id.Hex()).Return(tt.doc, tt.errorRtn)
+			r := newTestRoute(mockClient)
+
+			got, err := r.GetCrumb(context.Background(), &pb.Id{Value: id.Hex()})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Route.GetCrumb() error = %v, want code %v", err, tt.wantCode)
+			}
+			if !proto.Equal(got, tt.want) {
+				t.Errorf("Route.GetCrumb() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoute_BatchGetCrumbs(t *testing.T) {
+	a, b := primitive.NewObjectID(), primitive.NewObjectID()
+	docs := []bson.D{
+		{{Key: "_id", Value: b}, {Key: "user", Value: "b"}},
+		{{Key: "_id", Value: a}, {Key: "user", Value: "a"}},
+	}
+	tooMany := make([]string, MAX_BATCH_SIZE+1)
+
+	tests := []struct {
+		name     string
+		ids      []string
+		findRtn  []bson.D
+		want     []string
+		wantCode codes.Code
+	}{
+		{name: "in the order of the ids", ids: []string{a.Hex(), b.Hex(), a.Hex()}, findRtn: docs, want: []string{"a", "b", "a"}},
+		{name: "upper case ids", ids: []string{strings.ToUpper(b.Hex())}, findRtn: docs[:1], want: []string{"b"}},
+		{name: "missing", ids: []string{a.Hex(), primitive.NewObjectID().Hex()}, findRtn: docs[1:], wantCode: codes.NotFound},
+		{
+			name:     "hidden",
+			ids:      []string{a.Hex(), b.Hex()},
+			findRtn:  []bson.D{docs[1], {{Key: "_id", Value: b}, {Key: "user", Value: "b"}, {Key: "visibility", Value: int32(pb.Visibility_VISIBILITY_FOLLOWERS)}}},
+			wantCode: codes.NotFound,
+		},
+		{name: "no ids", ids: nil, want: []string{}},
+		{name: "too many ids", ids: tooMany, wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewClient(t)
+			if tt.findRtn != nil {
+				mockClient.On("FindMany", mock.Anything, "test", "test", tt.ids).Return(tt.findRtn, nil)
+			}
+			r := newTestRoute(mockClient)
+
+			got, err := r.BatchGetCrumbs(context.Background(), &pb.Ids{Values: tt.ids})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Route.BatchGetCrumbs() error = %v, want code %v", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			users := []string{}
+			for _, crumb := range got.GetCrumbs() {
+				users = append(users, crumb.GetUser())
+			}
+			if !reflect.DeepEqual(users, tt.want) {
+				t.Errorf("Route.BatchGetCrumbs() users = %v, want %v", users, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoute_ListCrumbsByUser(t *testing.T) {
+	ids := []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()}
+	var docs []bson.D
+	for i, id := range ids {
+		docs = append(docs, bson.D{{Key: "_id", Value: id}, {Key: "user", Value: "test_user"}, {Key: "created_at", Value: int64(3000 - i)}})
+	}
+	cursor := &interfaces.Cursor{Created: 2999, Id: ids[1].Hex()}
+
+	tests := []struct {
+		name      string
+		req       *pb.ListCrumbsByUserRequest
+		after     *interfaces.Cursor
+		limit     int
+		findRtn   []bson.D
+		wantCount int
+		wantNext  *interfaces.Cursor
+		wantCode  codes.Code
+	}{
+		{
+			name:      "first page",
+			req:       &pb.ListCrumbsByUserRequest{User: "test_user", PageSize: 2},
+			limit:     3,
+			findRtn:   docs,
+			wantCount: 2,
+			wantNext:  cursor,
+		},
+		{
+			name:      "last page",
+			req:       &pb.ListCrumbsByUserRequest{User: "test_user", PageSize: 2, PageToken: encodePageToken(&pb.Crumb{CreatedAt: 2999, Id: ids[1].Hex()})},
+			after:     cursor,
+			limit:     3,
+			findRtn:   docs[2:],
+			wantCount: 1,
+		},
+		{
+			name:      "hidden crumbs left out",
+			req:       &pb.ListCrumbsByUserRequest{User: "test_user", PageSize: 2},
+			limit:     3,
+			findRtn:   []bson.D{docs[0], append(bson.D{{Key: "visibility", Value: int32(pb.Visibility_VISIBILITY_PRIVATE)}}, docs[1]...), docs[2]},
+			wantCount: 1,
+			wantNext:  cursor,
+		},
+		{
+			name:      "default page size",
+			req:       &pb.ListCrumbsByUserRequest{User: "test_user"},
+			limit:     DEFAULT_PAGE_SIZE + 1,
+			findRtn:   docs,
+			wantCount: 3,
+		},
+		{
+			name:      "page size capped",
+			req:       &pb.ListCrumbsByUserRequest{User: "test_user", PageSize: MAX_PAGE_SIZE + 1},
+			limit:     MAX_PAGE_SIZE + 1,
+			findRtn:   docs,
+			wantCount: 3,
+		},
+		{name: "missing user", req: &pb.ListCrumbsByUserRequest{}, wantCode: codes.InvalidArgument},
+		{name: "negative page size", req: &pb.ListCrumbsByUserRequest{User: "test_user", PageSize: -1}, wantCode: codes.InvalidArgument},
+		{name: "invalid page token", req: &pb.ListCrumbsByUserRequest{User: "test_user", PageToken: "%%"}, wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewClient(t)
+			if tt.findRtn != nil {
+				mockClient.On("FindByUser", mock.Anything, "test", "test", "test_user", tt.after, tt.limit).Return(tt.findRtn, nil)
+			}
+			r := newTestRoute(mockClient)
+
+			got, err := r.ListCrumbsByUser(context.Background(), tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Route.ListCrumbsByUser() error = %v, want code %v", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if len(got.GetCrumbs()) != tt.wantCount {
+				t.Errorf("Route.ListCrumbsByUser() returned %v crumbs, want %v", len(got.GetCrumbs()), tt.wantCount)
+			}
+			next, err := decodePageToken(got.GetNextPageToken())
+			if err != nil {
+				t.Fatalf("decodePageToken() error = %v", err)
+			}
+			if !reflect.DeepEqual(next, tt.wantNext) {
+				t.Errorf("Route.ListCrumbsByUser() next page = %v, want %v", next, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestDecodePageToken(t *testing.T) {
+	id := primitive.NewObjectID().Hex()
+	tests := []struct {
+		name    string
+		token   string
+		want    *interfaces.Cursor
+		wantErr bool
+	}{
+		{name: "first page", token: "", want: nil},
+		{name: "round trip", token: encodePageToken(&pb.Crumb{CreatedAt: -5, Id: id}), want: &interfaces.Cursor{Created: -5, Id: id}},
+		{name: "not base64", token: "%%", wantErr: true},
+		{name: "no separator", token: base64.RawURLEncoding.EncodeToString([]byte("1000")), wantErr: true},
+		{name: "invalid time", token: base64.RawURLEncoding.EncodeToString([]byte("now:" + id)), wantErr: true},
+		{name: "invalid id", token: base64.RawURLEncoding.EncodeToString([]byte("1000:42")), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodePageToken(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodePageToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodePageToken() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func newTestRoute(dbClient *mocks.Client) *Route {
+	return &Route{
+		dbConfig:  &config.Database{DatabaseName: "test", Collection: "test"},
+		dbClient:  dbClient,
+		lc:        logger.NewMockClient(),
+		metrics:   appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"}),
+		validator: validator.New(),
+	}
+}
+
+// successCount is the expected value of a counter incremented once by a successful call
+func successCount(wantErr bool) float64 {
+	if wantErr {
+		return 0
+	}
+	return 1
+}
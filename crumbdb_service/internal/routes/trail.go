@@ -0,0 +1,521 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/haguru/horus/crumbdb/config"
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	"github.com/haguru/horus/crumbdb/pkg/document"
+	"github.com/haguru/horus/crumbdb/pkg/geo"
+	appLogging "github.com/haguru/horus/crumbdb/pkg/logging"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DEFAULT_TRAIL_COLLECTION is the collection of the trails when database.trail_collection is not set
+	DEFAULT_TRAIL_COLLECTION = "trails"
+	TRAIL_CRUMB_IDS_KEY      = "crumb_ids"
+	TRAIL_DISTANCE_KEY       = "distance"
+	TRAIL_BBOX_KEY           = "bbox"
+
+	// MAX_TRAIL_CRUMBS is the most crumbs of a trail, which bounds the cost of deriving its path
+	MAX_TRAIL_CRUMBS = 1000
+
+	// DEFAULT_TRAIL_RADIUS is the radius in meters of FindTrailsNear around a Point when none is requested,
+	// MAX_TRAIL_RADIUS the largest
+	DEFAULT_TRAIL_RADIUS = 100
+	MAX_TRAIL_RADIUS     = 10000
+
+	// CIRCLE_VERTICES are the vertices of the polygon FindTrailsNear searches around a Point
+	CIRCLE_VERTICES = 32
+
+	// MAX_TRAIL_RETRIES is how many times a change of a trail is retried after a concurrent change
+	MAX_TRAIL_RETRIES = 3
+)
+
+// trailDocument is a trail as it is stored. Its location is its path, so the spatial index of the trails
+// collection covers it like the location of crumbs
+type trailDocument struct {
+	Id        string      `bson:"_id,omitempty"`
+	User      string      `bson:"user"`
+	Name      string      `bson:"name"`
+	CrumbIds  []string    `bson:"crumb_ids"`
+	Location  interface{} `bson:"location,omitempty"`
+	Distance  float64     `bson:"distance"`
+	Bbox      []float64   `bson:"bbox"`
+	CreatedAt int64       `bson:"created_at"`
+	Version   int64       `bson:"version"`
+}
+
+// trailPath is the geometry of a trail derived from the locations of its crumbs
+type trailPath struct {
+	// Location is a mongodb.LineString, a mongodb.Point if the crumbs are all at the same location or nil if
+	// there are none
+	Location interface{}
+	Distance float64
+	Bbox     []float64
+}
+
+// TrailCollection returns the collection of the trails of dbConfig
+func TrailCollection(dbConfig *config.Database) string {
+	if dbConfig.TrailCollection == "" {
+		return DEFAULT_TRAIL_COLLECTION
+	}
+	return dbConfig.TrailCollection
+}
+
+func (r *Route) CreateTrail(ctx context.Context, trail *pb.Trail) (*pb.Trail, error) {
+	lc := appLogging.FromContext(ctx, r.lc)
+	lc.Debug("received new CreateTrail request", "user", trail.GetUser(), "crumbs", len(trail.GetCrumbIds()))
+
+	// an authenticated caller only creates its own trails
+	if caller := callerUser(ctx); caller != "" {
+		if trail.GetUser() != "" && trail.GetUser() != caller {
+			return nil, status.Errorf(codes.PermissionDenied, "%v cannot create the trails of %v", caller, trail.GetUser())
+		}
+		trail.User = caller
+	}
+	if trail.GetUser() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user is required")
+	}
+	if len(trail.GetCrumbIds()) > MAX_TRAIL_CRUMBS {
+		return nil, status.Errorf(codes.InvalidArgument, "a trail has at most %v crumbs, got %v", MAX_TRAIL_CRUMBS, len(trail.GetCrumbIds()))
+	}
+
+	ids := normalizeIds(trail.GetCrumbIds())
+	path, err := r.trailPath(ctx, trail.GetUser(), ids)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := trailDocument{
+		User:      trail.GetUser(),
+		Name:      trail.GetName(),
+		CrumbIds:  ids,
+		Location:  path.Location,
+		Distance:  path.Distance,
+		Bbox:      path.Bbox,
+		CreatedAt: time.Now().UnixMilli(),
+		Version:   1,
+	}
+	doc.Id, err = r.dbClient.InsertRecord(ctx, r.dbConfig.DatabaseName, TrailCollection(r.dbConfig), doc)
+	if err != nil {
+		lc.Errorf("failed to insert trail: %v", err)
+		return nil, err
+	}
+
+	created, err := document.ToDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	return toTrail(created)
+}
+
+func (r *Route) AppendToTrail(ctx context.Context, req *pb.AppendToTrailRequest) (*pb.Trail, error) {
+	lc := appLogging.FromContext(ctx, r.lc)
+	lc.Debug("received new AppendToTrail request", "id", req.GetTrailId(), "crumbs", len(req.GetCrumbIds()), "version", req.GetVersion())
+
+	if req.GetTrailId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "trail id is required")
+	}
+	if len(req.GetCrumbIds()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "crumb ids are required")
+	}
+	if len(req.GetCrumbIds()) > MAX_BATCH_SIZE {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %v crumbs can be appended at once, got %v", MAX_BATCH_SIZE, len(req.GetCrumbIds()))
+	}
+
+	if err := r.checkTrailOwner(ctx, req.GetTrailId()); err != nil {
+		return nil, err
+	}
+
+	appended := normalizeIds(req.GetCrumbIds())
+	return r.changeTrail(ctx, req.GetTrailId(), req.GetVersion(), func(ids []string) ([]string, error) {
+		if len(ids)+len(appended) > MAX_TRAIL_CRUMBS {
+			return nil, status.Errorf(codes.InvalidArgument, "a trail has at most %v crumbs, got %v", MAX_TRAIL_CRUMBS, len(ids)+len(appended))
+		}
+		return append(ids, appended...), nil
+	})
+}
+
+func (r *Route) GetTrail(ctx context.Context, id *pb.Id) (*pb.Trail, error) {
+	lc := appLogging.FromContext(ctx, r.lc)
+	lc.Debug("received new GetTrail request", "id", id.GetValue())
+
+	doc, err := r.dbClient.FindOne(ctx, r.dbConfig.DatabaseName, TrailCollection(r.dbConfig), id.GetValue())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, status.Errorf(codes.NotFound, "trail %v not found", id.GetValue())
+	}
+	if err != nil {
+		lc.Errorf("failed to find trail with id '%v': %v", id.GetValue(), err)
+		return nil, err
+	}
+
+	trail, err := toTrail(*doc)
+	if err != nil {
+		return nil, err
+	}
+	trail, _, err = r.visibleTrail(ctx, trail)
+	if err != nil {
+		lc.Errorf("failed to find the crumbs of trail '%v': %v", id.GetValue(), err)
+		return nil, err
+	}
+	return trail, nil
+}
+
+func (r *Route) FindTrailsNear(ctx context.Context, req *pb.FindTrailsNearRequest) (*pb.Trails, error) {
+	lc := appLogging.FromContext(ctx, r.lc)
+	lc.Debug("received new FindTrailsNear request", appLogging.Redact(req)...)
+
+	if err := validateGeometry("location", req.GetLocation(), QUERY_TYPES...); err != nil {
+		return nil, invalidArgument(err)
+	}
+	radius := req.GetRadius()
+	if math.IsNaN(radius) || radius < 0 || radius > MAX_TRAIL_RADIUS {
+		return nil, status.Errorf(codes.InvalidArgument, "radius must be between 0 and %v meters, got %v", MAX_TRAIL_RADIUS, radius)
+	}
+	if radius == 0 {
+		radius = DEFAULT_TRAIL_RADIUS
+	}
+
+	// the trails near a point are the ones crossing the circle around it
+	polygon := req.GetLocation().GetCoordinates()
+	if req.GetLocation().GetType() == mongodb.POINT_TYPE_POINT {
+		polygon = geo.Circle(polygon, radius, CIRCLE_VERTICES)
+	}
+
+	area, err := document.Geometry(mongodb.POINT_TYPE_POLYGON, polygon)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	data, err := r.dbClient.SpatialIntersects(ctx, r.dbConfig.DatabaseName, TrailCollection(r.dbConfig), mongodb.POINT_TYPE_POLYGON, polygon)
+	if err != nil {
+		lc.Errorf("failed to run spatial query: %v", err)
+		return nil, err
+	}
+
+	res := &pb.Trails{}
+	for _, item := range data {
+		trail, err := toTrail(item)
+		if err != nil {
+			lc.Errorf("failed to convert an item in data: %v", err)
+			return nil, err
+		}
+		trail, path, err := r.visibleTrail(ctx, trail)
+		if err != nil {
+			lc.Errorf("failed to find the crumbs of trail '%v': %v", trail.GetId(), err)
+			return nil, err
+		}
+		// a trail found through crumbs hidden from the caller is left out
+		if path != nil && !path.intersects(area) {
+			continue
+		}
+		res.Trails = append(res.Trails, trail)
+	}
+	return res, nil
+}
+
+// checkTrailOwner returns NotFound if the trail with id does not exist and PermissionDenied if the caller of ctx
+// does not own it
+func (r *Route) checkTrailOwner(ctx context.Context, id string) error {
+	lc := appLogging.FromContext(ctx, r.lc)
+
+	doc, err := r.dbClient.FindOne(ctx, r.dbConfig.DatabaseName, TrailCollection(r.dbConfig), id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return status.Errorf(codes.NotFound, "trail %v not found", id)
+	}
+	if err != nil {
+		lc.Errorf("failed to find trail with id '%v': %v", id, err)
+		return err
+	}
+	trail, err := toTrail(*doc)
+	if err != nil {
+		return err
+	}
+	if caller := callerUser(ctx); caller == "" || caller != trail.GetUser() {
+		return status.Errorf(codes.PermissionDenied, "trail %v is not a trail of the caller", id)
+	}
+	return nil
+}
+
+// visibleTrail returns trail with the crumbs and the path the caller of ctx can see. When some crumbs are hidden
+// from the caller the path is derived again from the others and returned too, nil otherwise
+func (r *Route) visibleTrail(ctx context.Context, trail *pb.Trail) (*pb.Trail, *trailPath, error) {
+	if caller := callerUser(ctx); caller != "" && caller == trail.GetUser() {
+		return trail, nil, nil
+	}
+
+	found, err := r.findCrumbs(ctx, trail.GetCrumbIds())
+	if err != nil {
+		return nil, nil, err
+	}
+	crumbs := make([]*pb.Crumb, 0, len(found))
+	for _, crumb := range found {
+		crumbs = append(crumbs, crumb)
+	}
+	visible := map[string]*pb.Crumb{}
+	for _, crumb := range r.visibleCrumbs(ctx, crumbs) {
+		visible[crumb.GetId()] = crumb
+	}
+	if len(visible) == len(found) {
+		return trail, nil, nil
+	}
+
+	ids := slices.DeleteFunc(slices.Clone(trail.GetCrumbIds()), func(id string) bool { return visible[id] == nil })
+	path := derivePath(ids, visible)
+	trail.CrumbIds = ids
+	trail.Path = path.point()
+	trail.Distance = path.Distance
+	trail.Bbox = path.Bbox
+	return trail, path, nil
+}
+
+// removeFromTrails removes the crumb with id from the trails containing it
+func (r *Route) removeFromTrails(ctx context.Context, id string) error {
+	id = strings.ToLower(id)
+	return r.changeTrailsOf(ctx, id, func(ids []string) ([]string, error) {
+		return slices.DeleteFunc(ids, func(existing string) bool { return existing == id }), nil
+	})
+}
+
+// moveInTrails derives the path of the trails containing the crumb with id again, once it moved
+func (r *Route) moveInTrails(ctx context.Context, id string) error {
+	return r.changeTrailsOf(ctx, strings.ToLower(id), func(ids []string) ([]string, error) {
+		return ids, nil
+	})
+}
+
+// changeTrailsOf changes the trails containing the crumb with id, see changeTrail
+func (r *Route) changeTrailsOf(ctx context.Context, id string, change func(ids []string) ([]string, error)) error {
+	data, err := r.dbClient.FindByField(ctx, r.dbConfig.DatabaseName, TrailCollection(r.dbConfig), TRAIL_CRUMB_IDS_KEY, id)
+	if err != nil {
+		return fmt.Errorf("failed to find the trails of crumb %v: %v", id, err)
+	}
+
+	for _, item := range data {
+		trail, err := toTrail(item)
+		if err != nil {
+			return err
+		}
+		if _, err := r.changeTrail(ctx, trail.GetId(), 0, change); err != nil {
+			return fmt.Errorf("failed to change trail %v of crumb %v: %v", trail.GetId(), id, err)
+		}
+	}
+	return nil
+}
+
+// changeTrail replaces the crumbs of the trail with id by the ones returned by change and derives its path
+// again. A non zero version must be the version of the trail, otherwise the change is retried on the trail as
+// it is after a concurrent change
+func (r *Route) changeTrail(ctx context.Context, id string, version int64, change func(ids []string) ([]string, error)) (*pb.Trail, error) {
+	lc := appLogging.FromContext(ctx, r.lc)
+	collection := TrailCollection(r.dbConfig)
+
+	for attempt := 0; ; attempt++ {
+		doc, err := r.dbClient.FindOne(ctx, r.dbConfig.DatabaseName, collection, id)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, status.Errorf(codes.NotFound, "trail %v not found", id)
+		}
+		if err != nil {
+			lc.Errorf("failed to find trail with id '%v': %v", id, err)
+			return nil, err
+		}
+		trail, err := toTrail(*doc)
+		if err != nil {
+			return nil, err
+		}
+		if version != 0 && version != trail.GetVersion() {
+			return nil, aborted(fmt.Sprintf("trail %v is not at version %v", id, version), trail.GetVersion())
+		}
+
+		ids, err := change(trail.GetCrumbIds())
+		if err != nil {
+			return nil, err
+		}
+		path, err := r.trailPath(ctx, trail.GetUser(), ids)
+		if err != nil {
+			return nil, err
+		}
+
+		// the version read is compared so the path matches the crumbs it was derived from
+		updated, err := r.dbClient.Update(ctx, r.dbConfig.DatabaseName, collection, id, trail.GetVersion(), map[string]interface{}{
+			TRAIL_CRUMB_IDS_KEY:       ids,
+			mongodb.SPATIAL_INDEX_KEY: path.Location,
+			TRAIL_DISTANCE_KEY:        path.Distance,
+			TRAIL_BBOX_KEY:            path.Bbox,
+		})
+		if errors.Is(err, interfaces.ErrVersionMismatch) && attempt < MAX_TRAIL_RETRIES {
+			lc.Debugf("trail %v changed concurrently, retrying", id)
+			continue
+		}
+		if errors.Is(err, interfaces.ErrVersionMismatch) {
+			return nil, status.Errorf(codes.Aborted, "trail %v keeps changing concurrently", id)
+		}
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, status.Errorf(codes.NotFound, "trail %v not found", id)
+		}
+		if err != nil {
+			lc.Errorf("failed to update trail with id '%v': %v", id, err)
+			return nil, err
+		}
+
+		return toTrail(*updated)
+	}
+}
+
+// trailPath returns the path of the trail of user through the crumbs with ids, in order. Every crumb must
+// exist and be a crumb of user. The crumbs of other users are reported missing, so the ids of their crumbs
+// cannot be probed
+func (r *Route) trailPath(ctx context.Context, user string, ids []string) (*trailPath, error) {
+	if len(ids) == 0 {
+		return &trailPath{}, nil
+	}
+
+	found, err := r.findCrumbs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, id := range ids {
+		if crumb, ok := found[id]; !ok || crumb.GetUser() != user {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, status.Errorf(codes.NotFound, "crumbs %v not found", strings.Join(missing, ", "))
+	}
+
+	return derivePath(ids, found), nil
+}
+
+// findCrumbs returns the crumbs with ids by id, the ones which do not exist are left out
+func (r *Route) findCrumbs(ctx context.Context, ids []string) (map[string]*pb.Crumb, error) {
+	found := map[string]*pb.Crumb{}
+	if len(ids) == 0 {
+		return found, nil
+	}
+
+	data, err := r.dbClient.FindMany(ctx, r.dbConfig.DatabaseName, r.dbConfig.Collection, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the crumbs of the trail: %v", err)
+	}
+	for _, item := range data {
+		crumb, err := toCrumb(item)
+		if err != nil {
+			return nil, err
+		}
+		found[crumb.GetId()] = crumb
+	}
+	return found, nil
+}
+
+// derivePath returns the path through the crumbs with ids, in order, skipping the ids missing from crumbs
+func derivePath(ids []string, crumbs map[string]*pb.Crumb) *trailPath {
+	var line [][]float64
+	for _, id := range ids {
+		crumb, ok := crumbs[id]
+		if !ok {
+			continue
+		}
+
+		// crumbs at the location of the previous one add no vertex
+		coordinates := crumb.GetLocation().GetCoordinates()
+		if !geo.Valid(coordinates) || (len(line) > 0 && slices.Equal(line[len(line)-1], coordinates)) {
+			continue
+		}
+		line = append(line, coordinates)
+	}
+
+	path := &trailPath{}
+	switch len(line) {
+	case 0:
+		return path
+	case 1:
+		path.Location = mongodb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: line[0]}
+	default:
+		path.Location = mongodb.LineString{Type: mongodb.POINT_TYPE_LINE_STRING, Coordinates: line}
+		path.Distance = geo.Length(line)
+	}
+	box := geo.RingBounds(line)
+	path.Bbox = []float64{box.Min[0], box.Min[1], box.Max[0], box.Max[1]}
+	return path
+}
+
+// point returns the path in flat pairs like the other geometries of the API, nil if it is unset
+func (p *trailPath) point() *pb.Point {
+	switch location := p.Location.(type) {
+	case mongodb.Point:
+		return &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: location.Coordinates}
+	case mongodb.LineString:
+		return &pb.Point{Type: mongodb.POINT_TYPE_LINE_STRING, Coordinates: slices.Concat(location.Coordinates...)}
+	}
+	return nil
+}
+
+// intersects returns true if the path intersects area, a Point or a Polygon as returned by document.Geometry
+func (p *trailPath) intersects(area [][]float64) bool {
+	if p.Location == nil {
+		return false
+	}
+	doc, err := document.ToDocument(bson.D{{Key: mongodb.SPATIAL_INDEX_KEY, Value: p.Location}})
+	return err == nil && document.Intersects(doc, area)
+}
+
+// toTrail converts a trail document returned by the database to a trail
+func toTrail(item bson.D) (*pb.Trail, error) {
+	data, err := bson.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal document: %v", err)
+	}
+
+	var doc trailDocument
+	err = bson.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal document: %v", err)
+	}
+
+	trail := &pb.Trail{
+		Id:        doc.Id,
+		User:      doc.User,
+		Name:      doc.Name,
+		CrumbIds:  doc.CrumbIds,
+		Distance:  doc.Distance,
+		Bbox:      doc.Bbox,
+		CreatedAt: doc.CreatedAt,
+		Version:   doc.Version,
+	}
+
+	// the path is given in flat pairs like the other geometries of the API
+	shape, ok := document.Shape(item)
+	switch {
+	case !ok:
+	case len(shape) == 1:
+		trail.Path = &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: shape[0]}
+	default:
+		trail.Path = &pb.Point{Type: mongodb.POINT_TYPE_LINE_STRING, Coordinates: slices.Concat(shape...)}
+	}
+	return trail, nil
+}
+
+// normalizeIds returns ids in lower case, as the hex ids returned by the database are
+func normalizeIds(ids []string) []string {
+	normalized := make([]string, 0, len(ids))
+	for _, id := range ids {
+		normalized = append(normalized, strings.ToLower(id))
+	}
+	return normalized
+}
@@ -0,0 +1,340 @@
+package routes
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/haguru/horus/crumbdb/config"
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	"github.com/haguru/horus/crumbdb/pkg/memory"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb"
+	appMetrics "github.com/haguru/horus/crumbdb/pkg/prometheus"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestRoute_CreateTrail(t *testing.T) {
+	r, ids := newTrailRoute(t)
+
+	tests := []struct {
+		name         string
+		caller       string
+		trail        *pb.Trail
+		wantCode     codes.Code
+		wantPath     *pb.Point
+		wantDistance float64
+		wantBbox     []float64
+	}{
+		{
+			name:         "line through the crumbs",
+			trail:        &pb.Trail{User: "alice", Name: "walk", CrumbIds: []string{ids["start"], ids["north"], ids["east"]}},
+			wantPath:     &pb.Point{Type: mongodb.POINT_TYPE_LINE_STRING, Coordinates: []float64{-122.4, 37.8, -122.4, 37.81, -122.39, 37.81}},
+			wantDistance: 1112 + 879,
+			wantBbox:     []float64{-122.4, 37.8, -122.39, 37.81},
+		},
+		{
+			name:     "crumbs at the same location",
+			trail:    &pb.Trail{User: "alice", CrumbIds: []string{ids["start"], ids["start again"]}},
+			wantPath: &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{-122.4, 37.8}},
+			wantBbox: []float64{-122.4, 37.8, -122.4, 37.8},
+		},
+		{name: "no crumbs", trail: &pb.Trail{User: "alice"}},
+		{name: "missing user", trail: &pb.Trail{CrumbIds: []string{ids["start"]}}, wantCode: codes.InvalidArgument},
+		{name: "crumb of another user", trail: &pb.Trail{User: "alice", CrumbIds: []string{ids["start"], ids["bob"]}}, wantCode: codes.NotFound},
+		{name: "missing crumb", trail: &pb.Trail{User: "alice", CrumbIds: []string{ids["start"], "65f1a2b3c4d5e6f7a8b9c0d1"}}, wantCode: codes.NotFound},
+		{name: "trail of the caller", caller: "alice", trail: &pb.Trail{CrumbIds: []string{ids["start"]}}, wantPath: &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{-122.4, 37.8}}, wantBbox: []float64{-122.4, 37.8, -122.4, 37.8}},
+		{name: "trail of another user than the caller", caller: "bob", trail: &pb.Trail{User: "alice", CrumbIds: []string{ids["start"]}}, wantCode: codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.caller != "" {
+				ctx = asCaller(tt.caller)
+			}
+
+			got, err := r.CreateTrail(ctx, tt.trail)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Route.CreateTrail() error = %v, want %v", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if got.GetId() == "" || got.GetVersion() != 1 || got.GetCreatedAt() == 0 {
+				t.Errorf("Route.CreateTrail() = %v, want an id, version 1 and a creation time", got)
+			}
+			if !reflect.DeepEqual(got.GetPath(), tt.wantPath) {
+				t.Errorf("Route.CreateTrail() path = %v, want %v", got.GetPath(), tt.wantPath)
+			}
+			if math.Abs(got.GetDistance()-tt.wantDistance) > 2 {
+				t.Errorf("Route.CreateTrail() distance = %v, want %v", got.GetDistance(), tt.wantDistance)
+			}
+			if !reflect.DeepEqual(got.GetBbox(), tt.wantBbox) {
+				t.Errorf("Route.CreateTrail() bbox = %v, want %v", got.GetBbox(), tt.wantBbox)
+			}
+
+			read, err := r.GetTrail(context.Background(), &pb.Id{Value: got.GetId()})
+			if err != nil {
+				t.Fatalf("Route.GetTrail() error = %v", err)
+			}
+			if !proto.Equal(read, got) {
+				t.Errorf("Route.GetTrail() = %v, want %v", read, got)
+			}
+		})
+	}
+
+	if _, err := r.GetTrail(context.Background(), &pb.Id{Value: "65f1a2b3c4d5e6f7a8b9c0d1"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Route.GetTrail() of a missing trail error = %v, want NotFound", err)
+	}
+}
+
+func TestRoute_AppendToTrail(t *testing.T) {
+	r, ids := newTrailRoute(t)
+	trail, err := r.CreateTrail(context.Background(), &pb.Trail{User: "alice", CrumbIds: []string{ids["start"]}})
+	if err != nil {
+		t.Fatalf("Route.CreateTrail() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		req  *pb.AppendToTrailRequest
+		// caller is alice, the owner of the trail, unless set
+		caller      string
+		wantCode    codes.Code
+		wantCrumbs  []string
+		wantVersion int64
+	}{
+		{
+			name:        "current version",
+			req:         &pb.AppendToTrailRequest{TrailId: trail.GetId(), CrumbIds: []string{ids["north"]}, Version: 1},
+			wantCrumbs:  []string{ids["start"], ids["north"]},
+			wantVersion: 2,
+		},
+		{
+			name:        "unconditional",
+			req:         &pb.AppendToTrailRequest{TrailId: trail.GetId(), CrumbIds: []string{ids["east"], ids["start"]}},
+			wantCrumbs:  []string{ids["start"], ids["north"], ids["east"], ids["start"]},
+			wantVersion: 3,
+		},
+		{name: "stale version", req: &pb.AppendToTrailRequest{TrailId: trail.GetId(), CrumbIds: []string{ids["north"]}, Version: 1}, wantCode: codes.Aborted},
+		{name: "no crumbs", req: &pb.AppendToTrailRequest{TrailId: trail.GetId()}, wantCode: codes.InvalidArgument},
+		{name: "crumb of another user", req: &pb.AppendToTrailRequest{TrailId: trail.GetId(), CrumbIds: []string{ids["bob"]}}, wantCode: codes.NotFound},
+		{name: "missing trail", req: &pb.AppendToTrailRequest{TrailId: "65f1a2b3c4d5e6f7a8b9c0d1", CrumbIds: []string{ids["north"]}}, wantCode: codes.NotFound},
+		{name: "trail of another user", req: &pb.AppendToTrailRequest{TrailId: trail.GetId(), CrumbIds: []string{ids["bob"]}}, caller: "bob", wantCode: codes.PermissionDenied},
+		{name: "anonymous caller", req: &pb.AppendToTrailRequest{TrailId: trail.GetId(), CrumbIds: []string{ids["north"]}}, caller: "-", wantCode: codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := asCaller("alice")
+			switch tt.caller {
+			case "":
+			case "-":
+				ctx = context.Background()
+			default:
+				ctx = asCaller(tt.caller)
+			}
+
+			got, err := r.AppendToTrail(ctx, tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Route.AppendToTrail() error = %v, want %v", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got.GetCrumbIds(), tt.wantCrumbs) || got.GetVersion() != tt.wantVersion {
+				t.Errorf("Route.AppendToTrail() = %v at version %v, want %v at version %v", got.GetCrumbIds(), got.GetVersion(), tt.wantCrumbs, tt.wantVersion)
+			}
+			if got.GetPath().GetType() != mongodb.POINT_TYPE_LINE_STRING {
+				t.Errorf("Route.AppendToTrail() path = %v, want a LineString", got.GetPath())
+			}
+		})
+	}
+
+	// the aborted append reports the current version like Update
+	_, err = r.AppendToTrail(asCaller("alice"), &pb.AppendToTrailRequest{TrailId: trail.GetId(), CrumbIds: []string{ids["north"]}, Version: 1})
+	var current string
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetReason() == VERSION_MISMATCH {
+			current = info.GetMetadata()[CURRENT_VERSION_KEY]
+		}
+	}
+	if current != "3" {
+		t.Errorf("Route.AppendToTrail() current version = %q, want \"3\"", current)
+	}
+}
+
+func TestRoute_FindTrailsNear(t *testing.T) {
+	r, ids := newTrailRoute(t)
+	walk, err := r.CreateTrail(context.Background(), &pb.Trail{User: "alice", Name: "walk", CrumbIds: []string{ids["start"], ids["north"]}})
+	if err != nil {
+		t.Fatalf("Route.CreateTrail() error = %v", err)
+	}
+	if _, err := r.CreateTrail(context.Background(), &pb.Trail{User: "bob", Name: "away", CrumbIds: []string{ids["bob"]}}); err != nil {
+		t.Fatalf("Route.CreateTrail() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		req      *pb.FindTrailsNearRequest
+		wantCode codes.Code
+		want     []string
+	}{
+		{
+			// 44 meters east of the middle of the walk, away from its crumbs
+			name: "point near the path",
+			req:  &pb.FindTrailsNearRequest{Location: &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{-122.3995, 37.805}}},
+			want: []string{"walk"},
+		},
+		{
+			name: "point beyond the radius",
+			req:  &pb.FindTrailsNearRequest{Location: &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{-122.3995, 37.805}}, Radius: 10},
+		},
+		{
+			name: "polygon crossing the path",
+			req:  &pb.FindTrailsNearRequest{Location: &pb.Point{Type: mongodb.POINT_TYPE_POLYGON, Coordinates: []float64{-122.41, 37.804, -122.39, 37.804, -122.39, 37.806, -122.41, 37.806}}},
+			want: []string{"walk"},
+		},
+		{name: "radius too large", req: &pb.FindTrailsNearRequest{Location: &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{-122.4, 37.8}}, Radius: MAX_TRAIL_RADIUS + 1}, wantCode: codes.InvalidArgument},
+		{name: "missing location", req: &pb.FindTrailsNearRequest{}, wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.FindTrailsNear(context.Background(), tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Route.FindTrailsNear() error = %v, want %v", err, tt.wantCode)
+			}
+			var names []string
+			for _, trail := range got.GetTrails() {
+				names = append(names, trail.GetName())
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("Route.FindTrailsNear() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+
+	// deleting a crumb takes it out of the path
+	if _, err := r.Delete(asCaller("alice"), &pb.Id{Value: ids["north"]}); err != nil {
+		t.Fatalf("Route.Delete() error = %v", err)
+	}
+	got, err := r.FindTrailsNear(context.Background(), tests[0].req)
+	if err != nil || len(got.GetTrails()) != 0 {
+		t.Errorf("Route.FindTrailsNear() after the delete = %v, %v, want no trails", got, err)
+	}
+	trail, err := r.GetTrail(context.Background(), &pb.Id{Value: walk.GetId()})
+	if err != nil {
+		t.Fatalf("Route.GetTrail() error = %v", err)
+	}
+	wantPath := &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{-122.4, 37.8}}
+	if !reflect.DeepEqual(trail.GetCrumbIds(), []string{ids["start"]}) || !reflect.DeepEqual(trail.GetPath(), wantPath) || trail.GetDistance() != 0 {
+		t.Errorf("Route.GetTrail() after the delete = %v, want only the start crumb", trail)
+	}
+}
+
+func TestRoute_TrailOfChangedCrumbs(t *testing.T) {
+	r, ids := newTrailRoute(t)
+	walk, err := r.CreateTrail(asCaller("alice"), &pb.Trail{User: "alice", Name: "walk", CrumbIds: []string{ids["start"], ids["north"], ids["east"]}})
+	if err != nil {
+		t.Fatalf("Route.CreateTrail() error = %v", err)
+	}
+
+	// moving east next to north derives the path again
+	moved := &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{-122.4, 37.82}}
+	if _, err := r.Update(asCaller("alice"), &pb.UpdateCrumbRequest{
+		Crumb:      &pb.Crumb{Id: ids["east"], Location: moved, Version: 1},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"location"}},
+	}); err != nil {
+		t.Fatalf("Route.Update() error = %v", err)
+	}
+	trail, err := r.GetTrail(context.Background(), &pb.Id{Value: walk.GetId()})
+	if err != nil {
+		t.Fatalf("Route.GetTrail() error = %v", err)
+	}
+	wantPath := []float64{-122.4, 37.8, -122.4, 37.81, -122.4, 37.82}
+	wantBbox := []float64{-122.4, 37.8, -122.4, 37.82}
+	if !reflect.DeepEqual(trail.GetPath().GetCoordinates(), wantPath) || !reflect.DeepEqual(trail.GetBbox(), wantBbox) || math.Abs(trail.GetDistance()-2224) > 1 {
+		t.Errorf("Route.GetTrail() after the move = %v, want the path through the new location", trail)
+	}
+
+	// a private crumb is left out of the trail of the other callers
+	if _, err := r.Update(asCaller("alice"), &pb.UpdateCrumbRequest{
+		Crumb:      &pb.Crumb{Id: ids["east"], Visibility: pb.Visibility_VISIBILITY_PRIVATE, Version: 2},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"visibility"}},
+	}); err != nil {
+		t.Fatalf("Route.Update() error = %v", err)
+	}
+	near := &pb.FindTrailsNearRequest{Location: moved, Radius: 10}
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		wantIds   []string
+		wantPath  []float64
+		wantTrail bool
+	}{
+		{name: "owner", ctx: asCaller("alice"), wantIds: []string{ids["start"], ids["north"], ids["east"]}, wantPath: wantPath, wantTrail: true},
+		{name: "other user", ctx: asCaller("bob"), wantIds: []string{ids["start"], ids["north"]}, wantPath: wantPath[:4]},
+		{name: "anonymous", ctx: context.Background(), wantIds: []string{ids["start"], ids["north"]}, wantPath: wantPath[:4]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trail, err := r.GetTrail(tt.ctx, &pb.Id{Value: walk.GetId()})
+			if err != nil {
+				t.Fatalf("Route.GetTrail() error = %v", err)
+			}
+			if !reflect.DeepEqual(trail.GetCrumbIds(), tt.wantIds) || !reflect.DeepEqual(trail.GetPath().GetCoordinates(), tt.wantPath) {
+				t.Errorf("Route.GetTrail() = %v, want crumbs %v along %v", trail, tt.wantIds, tt.wantPath)
+			}
+
+			got, err := r.FindTrailsNear(tt.ctx, near)
+			if err != nil {
+				t.Fatalf("Route.FindTrailsNear() error = %v", err)
+			}
+			if found := len(got.GetTrails()) == 1; found != tt.wantTrail {
+				t.Errorf("Route.FindTrailsNear() near the private crumb = %v, want the trail %v", got, tt.wantTrail)
+			}
+		})
+	}
+}
+
+// newTrailRoute returns a route backed by an in-memory database holding crumbs named after their position
+func newTrailRoute(t *testing.T) (*Route, map[string]string) {
+	t.Helper()
+	db := memory.NewMemory(logger.NewMockClient())
+	dbConfig := &config.Database{DatabaseName: "test", Collection: "crumbs"}
+	if err := db.CreateSpatialIndex(context.Background(), dbConfig.DatabaseName, dbConfig.Collection, mongodb.SPATIAL_INDEX_TYPE); err != nil {
+		t.Fatalf("CreateSpatialIndex() error = %v", err)
+	}
+	r := NewRoute(logger.NewMockClient(), dbConfig, db, appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"}), validator.New(), nil, nil, nil, nil)
+
+	crumbs := []struct {
+		name     string
+		user     string
+		lng, lat float64
+	}{
+		{name: "start", user: "alice", lng: -122.4, lat: 37.8},
+		{name: "start again", user: "alice", lng: -122.4, lat: 37.8},
+		{name: "north", user: "alice", lng: -122.4, lat: 37.81},
+		{name: "east", user: "alice", lng: -122.39, lat: 37.81},
+		{name: "bob", user: "bob", lng: -122.3, lat: 37.7},
+	}
+	ids := map[string]string{}
+	for _, c := range crumbs {
+		id, err := r.Create(context.Background(), &pb.Crumb{
+			User:     c.user,
+			Message:  c.name,
+			Location: &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{c.lng, c.lat}},
+		})
+		if err != nil {
+			t.Fatalf("Route.Create() error = %v", err)
+		}
+		ids[c.name] = id.GetValue()
+	}
+	return r, ids
+}
@@ -0,0 +1,134 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/haguru/horus/crumbdb/config"
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	"github.com/haguru/horus/crumbdb/pkg/geo"
+	"github.com/haguru/horus/crumbdb/pkg/geojson"
+	appLogging "github.com/haguru/horus/crumbdb/pkg/logging"
+	appMetrics "github.com/haguru/horus/crumbdb/pkg/prometheus"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// DEFAULT_UNLOCK_RADIUS is the unlock radius, in meters, of a locked crumb without one
+	DEFAULT_UNLOCK_RADIUS = 50
+
+	// DEFAULT_UNLOCK_COLLECTION is the collection of the unlocks when the database config has none
+	DEFAULT_UNLOCK_COLLECTION = "unlocks"
+
+	// UNLOCK_CRUMB_ID_KEY is the field of an unlock indexed to find the unlocks of a crumb
+	UNLOCK_CRUMB_ID_KEY = "crumb_id"
+)
+
+// UnlockCollection returns the collection of the unlocks of dbConfig
+func UnlockCollection(dbConfig *config.Database) string {
+	if dbConfig.UnlockCollection == "" {
+		return DEFAULT_UNLOCK_COLLECTION
+	}
+	return dbConfig.UnlockCollection
+}
+
+func (r *Route) UnlockCrumb(ctx context.Context, req *pb.UnlockCrumbRequest) (*pb.UnlockCrumbResponse, error) {
+	lc := appLogging.FromContext(ctx, r.lc)
+	lc.Debug("received new UnlockCrumb request", "id", req.GetId())
+
+	if err := validateGeometry("position", req.GetPosition(), LOCATION_TYPES...); err != nil {
+		return nil, invalidArgument(err)
+	}
+	if err := r.checkPosition(ctx, pb.CrumbDB_UnlockCrumb_FullMethodName, callerUser(ctx), req.GetPosition()); err != nil {
+		return nil, err
+	}
+
+	doc, err := r.dbClient.FindOne(ctx, r.dbConfig.DatabaseName, r.dbConfig.Collection, req.GetId())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, status.Errorf(codes.NotFound, "crumb %v not found", req.GetId())
+	}
+	if err != nil {
+		lc.Errorf("failed to find data with id '%v': %v", req.GetId(), err)
+		return nil, err
+	}
+	crumb, err := toCrumb(*doc)
+	if err != nil {
+		lc.Errorf("failed to convert the data: %v", err)
+		return nil, err
+	}
+
+	// a crumb the caller cannot see is not found, so unlocking does not reveal that it exists
+	if len(r.visibleCrumbs(ctx, []*pb.Crumb{crumb})) == 0 {
+		return nil, status.Errorf(codes.NotFound, "crumb %v not found", req.GetId())
+	}
+
+	caller := callerUser(ctx)
+	distance := geo.Distance(req.GetPosition().GetCoordinates(), crumb.GetLocation().GetCoordinates())
+	if !canRead(crumb, caller, req.GetPosition()) {
+		return nil, status.Errorf(codes.FailedPrecondition, "crumb %v is %.0f meters away, it unlocks within %v meters",
+			req.GetId(), distance, unlockRadius(crumb))
+	}
+
+	if crumb.GetLocked() && crumb.GetUser() != caller {
+		unlock := &pb.Unlock{
+			CrumbId:    crumb.GetId(),
+			User:       caller,
+			Position:   req.GetPosition(),
+			Distance:   distance,
+			UnlockedAt: time.Now().UnixMilli(),
+		}
+		if _, err := r.dbClient.InsertRecord(ctx, r.dbConfig.DatabaseName, UnlockCollection(r.dbConfig), unlock); err != nil {
+			lc.Errorf("failed to record the unlock of '%v': %v", req.GetId(), err)
+			return nil, err
+		}
+		appMetrics.IncWithExemplar(ctx, r.metrics.CrumbsUnlocked)
+	}
+
+	return &pb.UnlockCrumbResponse{Crumb: crumb, Distance: distance}, nil
+}
+
+// Reveal returns crumb as the caller of ctx sees it in GetCrumbs, or false if the caller cannot see it. crumb is
+// left untouched
+func (r *Route) Reveal(ctx context.Context, crumb *pb.Crumb) (*pb.Crumb, bool) {
+	if len(r.visibleCrumbs(ctx, []*pb.Crumb{crumb})) == 0 {
+		return nil, false
+	}
+
+	revealed := proto.Clone(crumb).(*pb.Crumb)
+	redactLocked([]*pb.Crumb{revealed}, callerUser(ctx))
+	return revealed, true
+}
+
+// redactLocked clears the message of the locked crumbs of other users than caller. A query point is not a
+// checked position, so UnlockCrumb is the only way to read them
+func redactLocked(crumbs []*pb.Crumb, caller string) {
+	for _, crumb := range crumbs {
+		if !canRead(crumb, caller, nil) {
+			crumb.Message = ""
+		}
+	}
+}
+
+// canRead returns true if caller can read the message of crumb from position. Only the owner can read a locked
+// crumb without being within its unlock radius
+func canRead(crumb *pb.Crumb, caller string, position *pb.Point) bool {
+	if !crumb.GetLocked() || caller != "" && crumb.GetUser() == caller {
+		return true
+	}
+	if position.GetType() != geojson.TYPE_POINT || len(position.GetCoordinates()) != 2 || len(crumb.GetLocation().GetCoordinates()) != 2 {
+		return false
+	}
+	return geo.Distance(position.GetCoordinates(), crumb.GetLocation().GetCoordinates()) <= unlockRadius(crumb)
+}
+
+func unlockRadius(crumb *pb.Crumb) float64 {
+	if crumb.GetUnlockRadius() == 0 {
+		return DEFAULT_UNLOCK_RADIUS
+	}
+	return crumb.GetUnlockRadius()
+}
@@ -0,0 +1,130 @@
+package routes
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	grpcMock "github.com/haguru/horus/crumbdb/internal/routes/protos/mocks"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb"
+	"github.com/haguru/horus/crumbdb/pkg/security"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newLockedRoute returns the route of newTrailRoute with a crumb of carol locked within 30 meters of the start
+// and a private one, by their message
+func newLockedRoute(t *testing.T) (*Route, map[string]string) {
+	t.Helper()
+	r, ids := newTrailRoute(t)
+
+	for _, crumb := range []*pb.Crumb{
+		{User: "carol", Message: "secret", Locked: true, UnlockRadius: 30},
+		{User: "carol", Message: "private secret", Locked: true, Visibility: pb.Visibility_VISIBILITY_PRIVATE},
+	} {
+		crumb.Location = position(-122.4, 37.8)
+		id, err := r.Create(context.Background(), crumb)
+		if err != nil {
+			t.Fatalf("Route.Create() error = %v", err)
+		}
+		ids[crumb.GetMessage()] = id.GetValue()
+	}
+	return r, ids
+}
+
+func position(lng, lat float64) *pb.Point {
+	return &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{lng, lat}}
+}
+
+func asCaller(user string) context.Context {
+	return security.ContextWithIdentity(context.Background(), &security.Identity{Subject: user})
+}
+
+func TestRoute_UnlockCrumb(t *testing.T) {
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		crumb      string
+		position   *pb.Point
+		wantCode   codes.Code
+		wantUnlock bool
+	}{
+		{name: "within the unlock radius", ctx: asCaller("bob"), crumb: "secret", position: position(-122.4, 37.8002), wantUnlock: true},
+		{name: "anonymous caller", ctx: context.Background(), crumb: "secret", position: position(-122.4, 37.8), wantUnlock: true},
+		{name: "too far", ctx: asCaller("bob"), crumb: "secret", position: position(-122.4, 37.8005), wantCode: codes.FailedPrecondition},
+		{name: "owner from afar", ctx: asCaller("carol"), crumb: "secret", position: position(-122.3, 37.7)},
+		{name: "crumb which is not locked", ctx: asCaller("bob"), crumb: "north", position: position(-122.3, 37.7)},
+		{name: "crumb the caller cannot see", ctx: asCaller("bob"), crumb: "private secret", position: position(-122.4, 37.8), wantCode: codes.NotFound},
+		{name: "missing position", ctx: asCaller("bob"), crumb: "secret", wantCode: codes.InvalidArgument},
+		{name: "polygon position", ctx: asCaller("bob"), crumb: "secret", position: &pb.Point{Type: "Polygon", Coordinates: []float64{0, 0, 1, 0, 1, 1}}, wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ids := newLockedRoute(t)
+
+			got, err := r.UnlockCrumb(tt.ctx, &pb.UnlockCrumbRequest{Id: ids[tt.crumb], Position: tt.position})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Route.UnlockCrumb() error = %v, want code %v", err, tt.wantCode)
+			}
+			if err == nil && got.GetCrumb().GetMessage() != tt.crumb {
+				t.Errorf("Route.UnlockCrumb() = %v, want the message %q", got, tt.crumb)
+			}
+
+			unlocks, err := r.dbClient.FindByField(context.Background(), "test", DEFAULT_UNLOCK_COLLECTION, UNLOCK_CRUMB_ID_KEY, ids[tt.crumb])
+			if err != nil {
+				t.Fatalf("FindByField() error = %v", err)
+			}
+			if want := successCount(!tt.wantUnlock); len(unlocks) != int(want) || testutil.ToFloat64(r.metrics.CrumbsUnlocked) != want {
+				t.Errorf("recorded %v unlocks and counted %v, want %v", len(unlocks), testutil.ToFloat64(r.metrics.CrumbsUnlocked), want)
+			}
+		})
+	}
+}
+
+func TestRoute_GetCrumbs_Locked(t *testing.T) {
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		point *pb.Point
+		want  string
+	}{
+		// the query point is not a checked position so it never unlocks the crumb
+		{name: "within the unlock radius", ctx: asCaller("bob"), point: position(-122.4, 37.8001)},
+		{name: "outside the unlock radius", ctx: asCaller("bob"), point: position(-122.4, 37.8005)},
+		{name: "owner outside the unlock radius", ctx: asCaller("carol"), point: position(-122.4, 37.8005), want: "secret"},
+		{name: "polygon", ctx: asCaller("bob"), point: &pb.Point{Type: "Polygon", Coordinates: []float64{-122.5, 37.7, -122.3, 37.7, -122.3, 37.9, -122.5, 37.9}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ids := newLockedRoute(t)
+
+			var locked *pb.Crumb
+			stream := grpcMock.NewServerStreamingServer[pb.Crumb](t)
+			stream.On("Context").Return(tt.ctx)
+			stream.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+				if crumb := args.Get(0).(*pb.Crumb); crumb.GetId() == ids["secret"] {
+					locked = crumb
+				}
+			}).Return(nil)
+
+			if err := r.GetCrumbs(tt.point, stream); err != nil {
+				t.Fatalf("Route.GetCrumbs() error = %v", err)
+			}
+			if locked == nil || locked.GetMessage() != tt.want {
+				t.Errorf("locked crumb = %v, want message %q", locked, tt.want)
+			}
+
+			// reading the crumb by id never reveals its message to others
+			got, err := r.GetCrumb(tt.ctx, &pb.Id{Value: ids["secret"]})
+			if err != nil {
+				t.Fatalf("Route.GetCrumb() error = %v", err)
+			}
+			if owner := callerUser(tt.ctx) == "carol"; (got.GetMessage() != "") != owner {
+				t.Errorf("Route.GetCrumb() message = %q, want it only for the owner", got.GetMessage())
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	appLogging "github.com/haguru/horus/crumbdb/pkg/logging"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	UPDATE_PATH_MESSAGE    = "message"
+	UPDATE_PATH_LOCATION   = "location"
+	UPDATE_PATH_VISIBILITY = "visibility"
+	UPDATE_PATH_TAGS       = "tags"
+
+	UPDATE_PATH_ALLOWED_USERS = "allowed_users"
+	UPDATE_PATH_LOCKED        = "locked"
+	UPDATE_PATH_UNLOCK_RADIUS = "unlock_radius"
+
+	// MAX_UNLOCK_RADIUS is the largest unlock radius of a crumb in meters, as validated on Create
+	MAX_UNLOCK_RADIUS = 10000
+
+	// MAX_ALLOWED_USERS is the most allowed users of a crumb, as validated on Create
+	MAX_ALLOWED_USERS = 100
+
+	// VERSION_MISMATCH is the reason of the ErrorInfo of an Aborted update, its metadata has the current version
+	VERSION_MISMATCH    = "VERSION_MISMATCH"
+	CURRENT_VERSION_KEY = "current_version"
+	ERROR_INFO_DOMAIN   = "crumbdb.horus"
+)
+
+// DEFAULT_UPDATE_PATHS are the fields updated when the update mask is empty
+var DEFAULT_UPDATE_PATHS = []string{UPDATE_PATH_MESSAGE}
+
+// updateItems returns the fields of crumb in paths as they are stored, or an error naming the first invalid one
+func (r *Route) updateItems(crumb *pb.Crumb, paths []string) (map[string]interface{}, error) {
+	items := map[string]interface{}{}
+	for _, path := range paths {
+		switch path {
+		case UPDATE_PATH_MESSAGE:
+			if crumb.GetMessage() == "" {
+				return nil, fmt.Errorf("message is required")
+			}
+			items[UPDATE_PATH_MESSAGE] = crumb.GetMessage()
+		case UPDATE_PATH_LOCATION:
+			if err := validateGeometry("crumb.location", crumb.GetLocation(), LOCATION_TYPES...); err != nil {
+				return nil, err
+			}
+			items[mongodb.SPATIAL_INDEX_KEY] = crumb.GetLocation()
+		case UPDATE_PATH_VISIBILITY:
+			if _, ok := pb.Visibility_name[int32(crumb.GetVisibility())]; !ok {
+				return nil, fmt.Errorf("invalid visibility %v", crumb.GetVisibility())
+			}
+			items[UPDATE_PATH_VISIBILITY] = crumb.GetVisibility()
+		case UPDATE_PATH_TAGS:
+			// an empty list clears the tags rather than storing null
+			tags := crumb.GetTags()
+			if tags == nil {
+				tags = []string{}
+			}
+			items[UPDATE_PATH_TAGS] = tags
+		case UPDATE_PATH_ALLOWED_USERS:
+			allowed := crumb.GetAllowedUsers()
+			if len(allowed) > MAX_ALLOWED_USERS {
+				return nil, fmt.Errorf("a crumb has at most %v allowed users, got %v", MAX_ALLOWED_USERS, len(allowed))
+			}
+			for _, user := range allowed {
+				if user == "" {
+					return nil, fmt.Errorf("allowed users cannot be empty")
+				}
+			}
+			if allowed == nil {
+				allowed = []string{}
+			}
+			items[UPDATE_PATH_ALLOWED_USERS] = allowed
+		case UPDATE_PATH_LOCKED:
+			items[UPDATE_PATH_LOCKED] = crumb.GetLocked()
+		case UPDATE_PATH_UNLOCK_RADIUS:
+			if crumb.GetUnlockRadius() < 0 || crumb.GetUnlockRadius() > MAX_UNLOCK_RADIUS {
+				return nil, fmt.Errorf("unlock radius must be between 0 and %v meters, got %v", MAX_UNLOCK_RADIUS, crumb.GetUnlockRadius())
+			}
+			items[UPDATE_PATH_UNLOCK_RADIUS] = crumb.GetUnlockRadius()
+		default:
+			return nil, fmt.Errorf("invalid update mask path %q", path)
+		}
+	}
+	return items, nil
+}
+
+// versionMismatch returns the Aborted error of an update of crumb at a version other than the current one, or
+// the FailedPrecondition error of an update without the version of a versioned crumb. The current version is
+// only told to the owner of the crumb
+func (r *Route) versionMismatch(ctx context.Context, crumb *pb.Crumb) error {
+	lc := appLogging.FromContext(ctx, r.lc)
+
+	code, msg := codes.Aborted, fmt.Sprintf("crumb %v is not at version %v", crumb.GetId(), crumb.GetVersion())
+	if crumb.GetVersion() == 0 {
+		code, msg = codes.FailedPrecondition, fmt.Sprintf("crumb %v is versioned, its version is required", crumb.GetId())
+	}
+	current, err := r.ownedCrumb(ctx, crumb.GetId())
+	if _, ok := status.FromError(err); ok && err != nil {
+		return err
+	}
+	if err != nil {
+		lc.Errorf("failed to find the current version of '%v': %v", crumb.GetId(), err)
+		return status.Error(code, msg)
+	}
+	return versionError(code, msg, current.GetVersion())
+}
+
+// aborted returns the Aborted error msg of a change expecting another version than current, with current in
+// the metadata of its ErrorInfo
+func aborted(msg string, current int64) error {
+	return versionError(codes.Aborted, msg, current)
+}
+
+// versionError returns the error msg with code of a change expecting another version than current, with current
+// in the metadata of its ErrorInfo
+func versionError(code codes.Code, msg string, current int64) error {
+	st, err := status.New(code, fmt.Sprintf("%v, the current version is %v", msg, current)).
+		WithDetails(&errdetails.ErrorInfo{
+			Reason:   VERSION_MISMATCH,
+			Domain:   ERROR_INFO_DOMAIN,
+			Metadata: map[string]string{CURRENT_VERSION_KEY: strconv.FormatInt(current, 10)},
+		})
+	if err != nil {
+		return status.Error(code, msg)
+	}
+	return st.Err()
+}
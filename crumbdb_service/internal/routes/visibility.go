@@ -0,0 +1,112 @@
+package routes
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	appLogging "github.com/haguru/horus/crumbdb/pkg/logging"
+	"github.com/haguru/horus/crumbdb/pkg/security"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Follows tells which users a follower follows, see follower.Client
+type Follows interface {
+	Follows(ctx context.Context, follower string, users []string) (map[string]bool, error)
+}
+
+// callerUser returns the user authenticated by the client certificate of the call, empty for anonymous callers
+func callerUser(ctx context.Context) string {
+	identity, ok := security.IdentityFromContext(ctx)
+	if !ok {
+		return ""
+	}
+	return identity.Subject
+}
+
+// ownedCrumb returns the crumb with id if the caller of ctx owns it. A crumb the caller cannot see is not found,
+// so its existence is not disclosed, and one the caller can see without owning it is denied
+func (r *Route) ownedCrumb(ctx context.Context, id string) (*pb.Crumb, error) {
+	lc := appLogging.FromContext(ctx, r.lc)
+
+	doc, err := r.dbClient.FindOne(ctx, r.dbConfig.DatabaseName, r.dbConfig.Collection, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, status.Errorf(codes.NotFound, "crumb %v not found", id)
+	}
+	if err != nil {
+		lc.Errorf("failed to find data with id '%v': %v", id, err)
+		return nil, err
+	}
+	crumb, err := toCrumb(*doc)
+	if err != nil {
+		lc.Errorf("failed to convert the data: %v", err)
+		return nil, err
+	}
+
+	if len(r.visibleCrumbs(ctx, []*pb.Crumb{crumb})) == 0 {
+		return nil, status.Errorf(codes.NotFound, "crumb %v not found", id)
+	}
+	if caller := callerUser(ctx); caller == "" || caller != crumb.GetUser() {
+		return nil, status.Errorf(codes.PermissionDenied, "crumb %v is not a crumb of the caller", id)
+	}
+	return crumb, nil
+}
+
+// visibleCrumbs returns the crumbs the caller of ctx can see, in order. Whether the caller follows the owners of
+// followers-only crumbs is asked once for all of them. If that fails those crumbs are left out
+func (r *Route) visibleCrumbs(ctx context.Context, crumbs []*pb.Crumb) []*pb.Crumb {
+	lc := appLogging.FromContext(ctx, r.lc)
+	caller := callerUser(ctx)
+
+	var owners []string
+	for _, crumb := range crumbs {
+		if caller != "" && crumb.GetVisibility() == pb.Visibility_VISIBILITY_FOLLOWERS && crumb.GetUser() != caller {
+			owners = append(owners, crumb.GetUser())
+		}
+	}
+	follows := map[string]bool{}
+	if len(owners) > 0 && r.follows != nil {
+		var err error
+		follows, err = r.follows.Follows(ctx, caller, owners)
+		if err != nil {
+			lc.Warnf("failed to check the follows of %v, hiding followers-only crumbs: %v", caller, err)
+			follows = map[string]bool{}
+		}
+	}
+
+	visible := make([]*pb.Crumb, 0, len(crumbs))
+	for _, crumb := range crumbs {
+		if isVisible(crumb, caller, follows) {
+			visible = append(visible, crumb)
+		}
+	}
+	return visible
+}
+
+// isVisible returns true if caller can see crumb, follows telling which owners caller follows
+func isVisible(crumb *pb.Crumb, caller string, follows map[string]bool) bool {
+	if crumb.GetVisibility() == pb.Visibility_VISIBILITY_PUBLIC {
+		return true
+	}
+	if caller == "" {
+		return false
+	}
+	if crumb.GetUser() == caller {
+		return true
+	}
+
+	switch crumb.GetVisibility() {
+	case pb.Visibility_VISIBILITY_FOLLOWERS:
+		return follows[crumb.GetUser()]
+	case pb.Visibility_VISIBILITY_SPECIFIC_USERS:
+		for _, user := range crumb.GetAllowedUsers() {
+			if user == caller {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package routes
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	followerpb "github.com/haguru/horus/crumbdb/internal/follower/protos"
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	grpcMock "github.com/haguru/horus/crumbdb/internal/routes/protos/mocks"
+	"github.com/haguru/horus/crumbdb/pkg/follower"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces/mocks"
+	"github.com/haguru/horus/crumbdb/pkg/security"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// followerServer answers GetFollowed with the users followed by "me" and counts the calls
+type followerServer struct {
+	followerpb.UnimplementedFollowerDBServer
+	mu       sync.Mutex
+	followed map[string]bool
+	calls    int
+	err      error
+}
+
+func (s *followerServer) GetFollowed(_ context.Context, req *followerpb.FollowedRequest) (*followerpb.Ids, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	ids := &followerpb.Ids{}
+	for _, id := range req.GetIds() {
+		if req.GetFollowerId() == "me" && s.followed[id] {
+			ids.Values = append(ids.Values, id)
+		}
+	}
+	return ids, nil
+}
+
+// newFollowerClient returns a follower.Client of server served over bufconn
+func newFollowerClient(t *testing.T, server *followerServer) *follower.Client {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	followerpb.RegisterFollowerDBServer(s, server)
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return follower.NewClient(conn, time.Minute, time.Second, logger.NewMockClient())
+}
+
+func TestRoute_GetCrumbs_Visibility(t *testing.T) {
+	crumb := func(message string, user string, visibility pb.Visibility, allowed ...string) bson.D {
+		return bson.D{
+			{Key: "user", Value: user},
+			{Key: "message", Value: message},
+			{Key: "visibility", Value: int32(visibility)},
+			{Key: "allowed_users", Value: allowed},
+		}
+	}
+	docs := []bson.D{
+		crumb("public", "bob", pb.Visibility_VISIBILITY_PUBLIC),
+		crumb("followed", "alice", pb.Visibility_VISIBILITY_FOLLOWERS),
+		crumb("not followed", "carol", pb.Visibility_VISIBILITY_FOLLOWERS),
+		crumb("followed again", "alice", pb.Visibility_VISIBILITY_FOLLOWERS),
+		crumb("private", "alice", pb.Visibility_VISIBILITY_PRIVATE),
+		crumb("own private", "me", pb.Visibility_VISIBILITY_PRIVATE),
+		crumb("own followers", "me", pb.Visibility_VISIBILITY_FOLLOWERS),
+		crumb("allowed", "dave", pb.Visibility_VISIBILITY_SPECIFIC_USERS, "erin", "me"),
+		crumb("not allowed", "dave", pb.Visibility_VISIBILITY_SPECIFIC_USERS, "erin"),
+	}
+
+	tests := []struct {
+		name      string
+		caller    string
+		followErr error
+		want      []string
+		wantCalls int
+	}{
+		{
+			name:      "caller",
+			caller:    "me",
+			want:      []string{"public", "followed", "followed again", "own private", "own followers", "allowed"},
+			wantCalls: 1,
+		},
+		{
+			name:      "follower service unavailable",
+			caller:    "me",
+			followErr: status.Error(codes.Internal, "failed"),
+			want:      []string{"public", "own private", "own followers", "allowed"},
+			wantCalls: 1,
+		},
+		{
+			name: "anonymous caller",
+			want: []string{"public"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &followerServer{followed: map[string]bool{"alice": true}, err: tt.followErr}
+			mockClient := mocks.NewClient(t)
+			mockClient.On("SpaitalQuery", mock.Anything, mock.Anything, mock.Anything, "test", "test").Return(docs, nil)
+			r := newTestRoute(mockClient)
+			r.follows = newFollowerClient(t, server)
+
+			ctx := context.Background()
+			if tt.caller != "" {
+				ctx = security.ContextWithIdentity(ctx, &security.Identity{Subject: tt.caller})
+			}
+			var got []string
+			stream := grpcMock.NewServerStreamingServer[pb.Crumb](t)
+			stream.On("Context").Return(ctx)
+			stream.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+				got = append(got, args.Get(0).(*pb.Crumb).GetMessage())
+			}).Return(nil).Maybe()
+
+			err := r.GetCrumbs(&pb.Point{Type: "Point", Coordinates: []float64{2.35, 48.85}}, stream)
+			if err != nil {
+				t.Fatalf("Route.GetCrumbs() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Route.GetCrumbs() = %v, want %v", got, tt.want)
+			}
+			// the owners of the followers-only crumbs are checked in one call
+			if server.calls != tt.wantCalls {
+				t.Errorf("GetFollowed called %v times, want %v", server.calls, tt.wantCalls)
+			}
+		})
+	}
+}
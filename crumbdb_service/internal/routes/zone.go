@@ -0,0 +1,424 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/haguru/horus/crumbdb/config"
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	"github.com/haguru/horus/crumbdb/pkg/document"
+	"github.com/haguru/horus/crumbdb/pkg/geo"
+	"github.com/haguru/horus/crumbdb/pkg/geojson"
+	appLogging "github.com/haguru/horus/crumbdb/pkg/logging"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces"
+	appMetrics "github.com/haguru/horus/crumbdb/pkg/prometheus"
+	"github.com/haguru/horus/crumbdb/pkg/security"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DEFAULT_ZONE_COLLECTION is the collection of the zones when the database config has none
+	DEFAULT_ZONE_COLLECTION = "zones"
+
+	// ZONE_RULE_ID_KEY is the field of a zone indexed to find it by rule id, the rule ids of zones are unique
+	ZONE_RULE_ID_KEY = "rule_id"
+
+	// VIOLATION_ZONE_RESTRICTED and VIOLATION_ZONE_DENSITY are the types of the PreconditionFailure of a crumb
+	// in a zone forbidding crumbs or holding its most crumbs, the subject is the rule id of the zone
+	VIOLATION_ZONE_RESTRICTED = "ZONE_RESTRICTED"
+	VIOLATION_ZONE_DENSITY    = "ZONE_DENSITY"
+
+	FEATURE_COLLECTION = "FeatureCollection"
+)
+
+// ZoneCollection returns the collection of the zones of dbConfig
+func ZoneCollection(dbConfig *config.Database) string {
+	if dbConfig.ZoneCollection == "" {
+		return DEFAULT_ZONE_COLLECTION
+	}
+	return dbConfig.ZoneCollection
+}
+
+// zoneDocument is a zone as it is stored. Its location is its area, so the spatial index of the zones
+// collection covers it like the location of crumbs
+type zoneDocument struct {
+	Id        string          `bson:"_id,omitempty"`
+	RuleId    string          `bson:"rule_id"`
+	Name      string          `bson:"name"`
+	Location  mongodb.Polygon `bson:"location"`
+	MaxCrumbs int64           `bson:"max_crumbs"`
+}
+
+func (r *Route) PutZone(ctx context.Context, zone *pb.Zone) (*pb.Zone, error) {
+	lc := appLogging.FromContext(ctx, r.lc)
+	lc.Debug("received new PutZone request", "id", zone.GetId(), "rule_id", zone.GetRuleId())
+
+	if err := r.authorizeOperator(ctx); err != nil {
+		return nil, err
+	}
+	if err := r.validateZone(zone); err != nil {
+		return nil, err
+	}
+
+	existing, err := r.findZone(ctx, zone.GetRuleId())
+	if err != nil {
+		lc.Errorf("failed to find the zone of rule '%v': %v", zone.GetRuleId(), err)
+		return nil, err
+	}
+	if existing != nil && existing.GetId() != zone.GetId() {
+		return nil, status.Errorf(codes.AlreadyExists, "rule %v is the rule of zone %v", zone.GetRuleId(), existing.GetId())
+	}
+
+	return r.saveZone(ctx, zone)
+}
+
+func (r *Route) DeleteZone(ctx context.Context, id *pb.Id) (*pb.Id, error) {
+	lc := appLogging.FromContext(ctx, r.lc)
+	lc.Debug("received new DeleteZone request", "id", id.GetValue())
+
+	if err := r.authorizeOperator(ctx); err != nil {
+		return nil, err
+	}
+
+	err := r.dbClient.Delete(ctx, r.dbConfig.DatabaseName, ZoneCollection(r.dbConfig), id.GetValue())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, status.Errorf(codes.NotFound, "zone %v not found", id.GetValue())
+	}
+	if err != nil {
+		lc.Errorf("failed to delete zone with id '%v': %v", id.GetValue(), err)
+		return nil, err
+	}
+	return id, nil
+}
+
+func (r *Route) ListZones(ctx context.Context, _ *pb.ListZonesRequest) (*pb.Zones, error) {
+	lc := appLogging.FromContext(ctx, r.lc)
+	lc.Debug("received new ListZones request")
+
+	if err := r.authorizeOperator(ctx); err != nil {
+		return nil, err
+	}
+
+	data, err := r.dbClient.FindAll(ctx, r.dbConfig.DatabaseName, ZoneCollection(r.dbConfig))
+	if err != nil {
+		lc.Errorf("failed to find zones: %v", err)
+		return nil, err
+	}
+	zones, err := toZones(data)
+	if err != nil {
+		lc.Errorf("failed to convert the zones: %v", err)
+		return nil, err
+	}
+	return &pb.Zones{Zones: zones}, nil
+}
+
+// LoadZones saves the zones of the GeoJSON FeatureCollection at path, replacing the zones with the same rule id.
+// The id of a feature is the rule id of its zone, its geometry is a Polygon without holes and its properties are
+// the name and max_crumbs of the zone
+func (r *Route) LoadZones(ctx context.Context, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read zones: %v", err)
+	}
+
+	var collection struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Id       interface{} `json:"id"`
+			Geometry *struct {
+				Type        string        `json:"type"`
+				Coordinates [][][]float64 `json:"coordinates"`
+			} `json:"geometry"`
+			Properties struct {
+				Name      string `json:"name"`
+				MaxCrumbs int64  `json:"max_crumbs"`
+			} `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return fmt.Errorf("failed to decode zones: %v", err)
+	}
+	if collection.Type != FEATURE_COLLECTION {
+		return fmt.Errorf("zones must be a %v, got %q", FEATURE_COLLECTION, collection.Type)
+	}
+
+	for i, feature := range collection.Features {
+		if feature.Id == nil {
+			return fmt.Errorf("zone %v has no id", i)
+		}
+		if feature.Geometry == nil || feature.Geometry.Type != geojson.TYPE_POLYGON || len(feature.Geometry.Coordinates) != 1 {
+			return fmt.Errorf("zone %v must be a Polygon without holes", feature.Id)
+		}
+		var coordinates []float64
+		for _, vertex := range feature.Geometry.Coordinates[0] {
+			coordinates = append(coordinates, vertex...)
+		}
+
+		zone := &pb.Zone{
+			RuleId:    fmt.Sprint(feature.Id),
+			Name:      feature.Properties.Name,
+			Area:      &pb.Point{Type: geojson.TYPE_POLYGON, Coordinates: coordinates},
+			MaxCrumbs: feature.Properties.MaxCrumbs,
+		}
+		if err := r.validateZone(zone); err != nil {
+			return fmt.Errorf("invalid zone %v: %v", zone.GetRuleId(), status.Convert(err).Message())
+		}
+
+		existing, err := r.findZone(ctx, zone.GetRuleId())
+		if err != nil {
+			return fmt.Errorf("failed to find zone %v: %v", zone.GetRuleId(), err)
+		}
+		if existing != nil {
+			zone.Id = existing.GetId()
+		}
+		if _, err := r.saveZone(ctx, zone); err != nil {
+			return fmt.Errorf("failed to save zone %v: %v", zone.GetRuleId(), err)
+		}
+	}
+
+	r.lc.Infof("loaded %v zones from %v", len(collection.Features), path)
+	return nil
+}
+
+// checkZones returns a FailedPrecondition status naming the rule id of a zone containing location which forbids
+// crumbs or already holds its most crumbs, not counting the crumb with id. Zones forbidding crumbs are checked
+// first. The crumbs of an import not inserted yet are counted by rule id in pending, which the accepted crumb is
+// added to.
+//
+// When location is in a zone limiting its crumbs, the returned release must be called once the crumb is written
+// or failed to be: the checks and writes of a replica are serialized until then, so its concurrent writes cannot
+// exceed the limit together. Replicas do not lock each other, so with several of them a zone may exceed its
+// most crumbs by a crumb per replica writing to it at the same time
+func (r *Route) checkZones(ctx context.Context, id string, location *pb.Point, pending map[string]int64) (func(), error) {
+	release := func() {}
+	if r.policy == nil || !r.policy.Enabled {
+		return release, nil
+	}
+	lc := appLogging.FromContext(ctx, r.lc)
+
+	data, err := r.dbClient.SpatialIntersects(ctx, r.dbConfig.DatabaseName, ZoneCollection(r.dbConfig), mongodb.POINT_TYPE_POINT, location.GetCoordinates())
+	if err != nil {
+		lc.Errorf("failed to find the zones of a location: %v", err)
+		return release, err
+	}
+	zones, err := toZones(data)
+	if err != nil {
+		lc.Errorf("failed to convert the zones: %v", err)
+		return release, err
+	}
+	sort.Slice(zones, func(i, j int) bool {
+		if (zones[i].GetMaxCrumbs() == 0) != (zones[j].GetMaxCrumbs() == 0) {
+			return zones[i].GetMaxCrumbs() == 0
+		}
+		return zones[i].GetRuleId() < zones[j].GetRuleId()
+	})
+	if len(zones) == 0 {
+		return release, nil
+	}
+	if zones[0].GetMaxCrumbs() == 0 {
+		return release, r.zoneViolation(ctx, VIOLATION_ZONE_RESTRICTED, zones[0], "crumbs are not allowed in the zone")
+	}
+
+	r.zoneMu.Lock()
+	release = r.zoneMu.Unlock
+	// an updated crumb already in a zone is not counted against it
+	var current *bson.D
+	if id != "" {
+		current, err = r.dbClient.FindOne(ctx, r.dbConfig.DatabaseName, r.dbConfig.Collection, id)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			lc.Errorf("failed to find crumb with id '%v': %v", id, err)
+			release()
+			return func() {}, err
+		}
+	}
+
+	for _, zone := range zones {
+		count, err := r.dbClient.CountIntersects(ctx, r.dbConfig.DatabaseName, r.dbConfig.Collection, mongodb.POINT_TYPE_POLYGON, zone.GetArea().GetCoordinates())
+		if err != nil {
+			lc.Errorf("failed to count the crumbs of zone '%v': %v", zone.GetRuleId(), err)
+			release()
+			return func() {}, err
+		}
+		if current != nil {
+			if area, err := document.Geometry(mongodb.POINT_TYPE_POLYGON, zone.GetArea().GetCoordinates()); err == nil && document.Intersects(*current, area) {
+				count--
+			}
+		}
+		if count+pending[zone.GetRuleId()] >= zone.GetMaxCrumbs() {
+			release()
+			return func() {}, r.zoneViolation(ctx, VIOLATION_ZONE_DENSITY, zone, fmt.Sprintf("the zone holds at most %v crumbs", zone.GetMaxCrumbs()))
+		}
+	}
+
+	if pending != nil {
+		for _, zone := range zones {
+			pending[zone.GetRuleId()]++
+		}
+	}
+	return release, nil
+}
+
+// zoneViolation returns the FailedPrecondition status of a crumb rejected by zone, with a PreconditionFailure of
+// violation whose subject is the rule id of zone
+func (r *Route) zoneViolation(ctx context.Context, violation string, zone *pb.Zone, description string) error {
+	appMetrics.IncWithExemplar(ctx, r.metrics.PolicyViolations.WithLabelValues(violation))
+
+	st := status.New(codes.FailedPrecondition, fmt.Sprintf("rule %v: %v", zone.GetRuleId(), description))
+	detailed, err := st.WithDetails(&errdetails.PreconditionFailure{
+		Violations: []*errdetails.PreconditionFailure_Violation{{
+			Type:        violation,
+			Subject:     zone.GetRuleId(),
+			Description: description,
+		}},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// authorizeOperator returns a PermissionDenied status unless the client certificate of the caller of ctx is
+// listed in the operators of the policy
+func (r *Route) authorizeOperator(ctx context.Context) error {
+	if r.policy == nil || !r.policy.Enabled {
+		return status.Error(codes.FailedPrecondition, "zones are disabled")
+	}
+	if identity, ok := security.IdentityFromContext(ctx); ok {
+		for _, allowed := range r.policy.Operators {
+			if allowed != "" && (allowed == identity.SpiffeID || allowed == identity.Subject) {
+				return nil
+			}
+		}
+	}
+	return status.Error(codes.PermissionDenied, "only operators can manage zones")
+}
+
+// validateZone returns an InvalidArgument status if zone is invalid
+func (r *Route) validateZone(zone *pb.Zone) error {
+	if err := r.validator.Struct(zone); err != nil {
+		return status.Errorf(codes.InvalidArgument, "validation error: %v", err)
+	}
+	if err := validateGeometry("area", zone.GetArea(), geojson.TYPE_POLYGON); err != nil {
+		return invalidArgument(err)
+	}
+	return nil
+}
+
+// findZone returns the zone of ruleId, nil if there is none
+func (r *Route) findZone(ctx context.Context, ruleId string) (*pb.Zone, error) {
+	data, err := r.dbClient.FindByField(ctx, r.dbConfig.DatabaseName, ZoneCollection(r.dbConfig), ZONE_RULE_ID_KEY, ruleId)
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	return toZone(data[0])
+}
+
+// saveZone inserts zone if it has no id, or replaces the zone with its id, and returns it
+func (r *Route) saveZone(ctx context.Context, zone *pb.Zone) (*pb.Zone, error) {
+	ring, err := geo.Ring(zone.GetArea().GetCoordinates())
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	doc := zoneDocument{
+		RuleId:    zone.GetRuleId(),
+		Name:      zone.GetName(),
+		Location:  mongodb.Polygon{Type: mongodb.POINT_TYPE_POLYGON, Coordinates: [][][]float64{ring}},
+		MaxCrumbs: zone.GetMaxCrumbs(),
+	}
+
+	if zone.GetId() == "" {
+		doc.Id, err = r.dbClient.InsertRecord(ctx, r.dbConfig.DatabaseName, ZoneCollection(r.dbConfig), doc)
+		if errors.Is(err, interfaces.ErrDuplicateKey) {
+			return nil, status.Errorf(codes.AlreadyExists, "rule %v is the rule of another zone", zone.GetRuleId())
+		}
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		items := map[string]interface{}{
+			ZONE_RULE_ID_KEY:          doc.RuleId,
+			"name":                    doc.Name,
+			mongodb.SPATIAL_INDEX_KEY: doc.Location,
+			"max_crumbs":              doc.MaxCrumbs,
+		}
+		// the zone is replaced whatever its version, which is only read to satisfy the compare and swap
+		stored, err := r.dbClient.FindOne(ctx, r.dbConfig.DatabaseName, ZoneCollection(r.dbConfig), zone.GetId())
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, status.Errorf(codes.NotFound, "zone %v not found", zone.GetId())
+		}
+		if err != nil {
+			return nil, err
+		}
+		_, err = r.dbClient.Update(ctx, r.dbConfig.DatabaseName, ZoneCollection(r.dbConfig), zone.GetId(), document.Version(*stored), items)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, status.Errorf(codes.NotFound, "zone %v not found", zone.GetId())
+		}
+		if errors.Is(err, interfaces.ErrVersionMismatch) {
+			return nil, status.Errorf(codes.Aborted, "zone %v changed concurrently", zone.GetId())
+		}
+		if errors.Is(err, interfaces.ErrDuplicateKey) {
+			return nil, status.Errorf(codes.AlreadyExists, "rule %v is the rule of another zone", zone.GetRuleId())
+		}
+		if err != nil {
+			return nil, err
+		}
+		doc.Id = zone.GetId()
+	}
+
+	saved, err := document.ToDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	return toZone(saved)
+}
+
+func toZones(data []bson.D) ([]*pb.Zone, error) {
+	zones := make([]*pb.Zone, 0, len(data))
+	for _, item := range data {
+		zone, err := toZone(item)
+		if err != nil {
+			return nil, err
+		}
+		zones = append(zones, zone)
+	}
+	return zones, nil
+}
+
+// toZone converts a zone document returned by the database to a zone
+func toZone(item bson.D) (*pb.Zone, error) {
+	data, err := bson.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal document: %v", err)
+	}
+
+	var doc zoneDocument
+	err = bson.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal document: %v", err)
+	}
+
+	zone := &pb.Zone{
+		Id:        doc.Id,
+		RuleId:    doc.RuleId,
+		Name:      doc.Name,
+		MaxCrumbs: doc.MaxCrumbs,
+	}
+	if len(doc.Location.Coordinates) > 0 {
+		area := &pb.Point{Type: mongodb.POINT_TYPE_POLYGON}
+		for _, vertex := range doc.Location.Coordinates[0] {
+			area.Coordinates = append(area.Coordinates, vertex...)
+		}
+		zone.Area = area
+	}
+	return zone, nil
+}
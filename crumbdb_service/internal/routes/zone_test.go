@@ -0,0 +1,310 @@
+package routes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/haguru/horus/crumbdb/config"
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// square returns the polygon of the square of side 2*half centered on lng, lat
+func square(lng, lat, half float64) *pb.Point {
+	return &pb.Point{Type: mongodb.POINT_TYPE_POLYGON, Coordinates: []float64{
+		lng - half, lat - half, lng + half, lat - half, lng + half, lat + half, lng - half, lat + half, lng - half, lat - half,
+	}}
+}
+
+// newZoneRoute returns the route of newTrailRoute with a zone forbidding crumbs around bob and a zone full with
+// the two crumbs at the start
+func newZoneRoute(t *testing.T) (*Route, map[string]string) {
+	t.Helper()
+	r, ids := newTrailRoute(t)
+	if err := r.dbClient.CreateSpatialIndex(context.Background(), r.dbConfig.DatabaseName, ZoneCollection(r.dbConfig), mongodb.SPATIAL_INDEX_TYPE); err != nil {
+		t.Fatalf("CreateSpatialIndex() error = %v", err)
+	}
+	if err := r.dbClient.CreateUniqueIndex(context.Background(), r.dbConfig.DatabaseName, ZoneCollection(r.dbConfig), ZONE_RULE_ID_KEY); err != nil {
+		t.Fatalf("CreateUniqueIndex() error = %v", err)
+	}
+	r.policy = &config.Policy{Enabled: true, Operators: []string{"ops"}}
+
+	for _, zone := range []*pb.Zone{
+		{RuleId: "harbor", Name: "Harbor", Area: square(-122.3, 37.7, 0.01)},
+		{RuleId: "plaza", Name: "Plaza", Area: square(-122.4, 37.8, 0.001), MaxCrumbs: 2},
+	} {
+		if _, err := r.PutZone(asCaller("ops"), zone); err != nil {
+			t.Fatalf("Route.PutZone() error = %v", err)
+		}
+	}
+	return r, ids
+}
+
+// wantViolation fails t unless err is a FailedPrecondition naming rule with a violation of type violation
+func wantViolation(t *testing.T, err error, violation string, rule string) {
+	t.Helper()
+	st := status.Convert(err)
+	if st.Code() != codes.FailedPrecondition {
+		t.Fatalf("error = %v, want code %v", err, codes.FailedPrecondition)
+	}
+	for _, detail := range st.Details() {
+		if failure, ok := detail.(*errdetails.PreconditionFailure); ok && len(failure.GetViolations()) == 1 {
+			got := failure.GetViolations()[0]
+			if got.GetType() != violation || got.GetSubject() != rule {
+				t.Errorf("violation = %v %v, want %v %v", got.GetType(), got.GetSubject(), violation, rule)
+			}
+			return
+		}
+	}
+	t.Errorf("error = %v, want a PreconditionFailure", err)
+}
+
+func TestRoute_CreateInZone(t *testing.T) {
+	tests := []struct {
+		name          string
+		location      *pb.Point
+		wantViolation string
+		wantRule      string
+	}{
+		{name: "restricted zone", location: position(-122.3, 37.7), wantViolation: VIOLATION_ZONE_RESTRICTED, wantRule: "harbor"},
+		{name: "full zone", location: position(-122.4, 37.8005), wantViolation: VIOLATION_ZONE_DENSITY, wantRule: "plaza"},
+		{name: "outside zones", location: position(-122.5, 37.9)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newZoneRoute(t)
+			_, err := r.Create(context.Background(), &pb.Crumb{User: "alice", Message: "hi", Location: tt.location})
+			if tt.wantViolation == "" {
+				if err != nil {
+					t.Fatalf("Route.Create() error = %v", err)
+				}
+				return
+			}
+			wantViolation(t, err, tt.wantViolation, tt.wantRule)
+		})
+	}
+}
+
+func TestRoute_CreateInZoneConcurrently(t *testing.T) {
+	r, _ := newZoneRoute(t)
+	if _, err := r.PutZone(asCaller("ops"), &pb.Zone{RuleId: "park", Area: square(-122.45, 37.75, 0.001), MaxCrumbs: 5}); err != nil {
+		t.Fatalf("Route.PutZone() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = r.Create(context.Background(), &pb.Crumb{User: "alice", Message: "hi", Location: position(-122.45, 37.75)})
+		}()
+	}
+	wg.Wait()
+
+	count, err := r.dbClient.CountIntersects(context.Background(), r.dbConfig.DatabaseName, r.dbConfig.Collection, mongodb.POINT_TYPE_POLYGON, square(-122.45, 37.75, 0.001).GetCoordinates())
+	if err != nil || count != 5 {
+		t.Errorf("crumbs in the zone = %v, %v, want 5", count, err)
+	}
+}
+
+func TestRoute_ImportInZone(t *testing.T) {
+	record := feature(`{"type":"Feature","geometry":{"type":"Point","coordinates":[-122.45,37.75]},"properties":{"message":"park"}}`)
+	outside := feature(`{"type":"Feature","geometry":{"type":"Point","coordinates":[-122.5,37.9]},"properties":{"message":"outside"}}`)
+
+	tests := []struct {
+		name         string
+		dryRun       bool
+		wantAccepted []bool
+	}{
+		{name: "import", wantAccepted: []bool{true, true, false, true, false}},
+		{name: "dry run", dryRun: true, wantAccepted: []bool{true, true, false, true, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newZoneRoute(t)
+			if _, err := r.PutZone(asCaller("ops"), &pb.Zone{RuleId: "park", Area: square(-122.45, 37.75, 0.001), MaxCrumbs: 2}); err != nil {
+				t.Fatalf("Route.PutZone() error = %v", err)
+			}
+
+			stream := &importStream{ctx: asCaller("alice"), requests: []*pb.ImportCrumbsRequest{
+				{Record: record.GetRecord(), DryRun: tt.dryRun}, record, record, outside, record,
+			}}
+			if err := r.ImportCrumbs(stream); err != nil {
+				t.Fatalf("Route.ImportCrumbs() error = %v", err)
+			}
+			for i, result := range stream.report.GetResults() {
+				if result.GetAccepted() != tt.wantAccepted[i] {
+					t.Errorf("result %v = %v, want accepted %v", i, result, tt.wantAccepted[i])
+				}
+				if !result.GetAccepted() && !strings.Contains(result.GetReason(), "rule park") {
+					t.Errorf("result %v reason = %q, want the rule park", i, result.GetReason())
+				}
+			}
+		})
+	}
+}
+
+func TestRoute_UpdateInZone(t *testing.T) {
+	tests := []struct {
+		name          string
+		crumb         string
+		location      *pb.Point
+		paths         []string
+		wantViolation string
+		wantRule      string
+	}{
+		{name: "into restricted zone", crumb: "north", location: position(-122.3, 37.7), paths: []string{UPDATE_PATH_LOCATION}, wantViolation: VIOLATION_ZONE_RESTRICTED, wantRule: "harbor"},
+		{name: "into full zone", crumb: "north", location: position(-122.4, 37.8005), paths: []string{UPDATE_PATH_LOCATION}, wantViolation: VIOLATION_ZONE_DENSITY, wantRule: "plaza"},
+		{name: "within full zone", crumb: "start", location: position(-122.4, 37.8005), paths: []string{UPDATE_PATH_LOCATION}},
+		{name: "message only", crumb: "bob", location: position(-122.3, 37.7), paths: []string{UPDATE_PATH_MESSAGE}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ids := newZoneRoute(t)
+			owner := "alice"
+			if tt.crumb == "bob" {
+				owner = "bob"
+			}
+			_, err := r.Update(asCaller(owner), &pb.UpdateCrumbRequest{
+				Crumb:      &pb.Crumb{Id: ids[tt.crumb], Message: "moved", Location: tt.location, Version: 1},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: tt.paths},
+			})
+			if tt.wantViolation == "" {
+				if err != nil {
+					t.Fatalf("Route.Update() error = %v", err)
+				}
+				return
+			}
+			wantViolation(t, err, tt.wantViolation, tt.wantRule)
+		})
+	}
+}
+
+func TestRoute_PutZone(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		policy   *config.Policy
+		zone     *pb.Zone
+		wantCode codes.Code
+	}{
+		{name: "operator", ctx: asCaller("ops"), zone: &pb.Zone{RuleId: "park", Area: square(-122.45, 37.75, 0.01)}, wantCode: codes.OK},
+		{name: "not an operator", ctx: asCaller("alice"), zone: &pb.Zone{RuleId: "park", Area: square(-122.45, 37.75, 0.01)}, wantCode: codes.PermissionDenied},
+		{name: "anonymous", ctx: context.Background(), zone: &pb.Zone{RuleId: "park", Area: square(-122.45, 37.75, 0.01)}, wantCode: codes.PermissionDenied},
+		{name: "policy disabled", ctx: asCaller("ops"), policy: &config.Policy{Operators: []string{"ops"}}, zone: &pb.Zone{RuleId: "park", Area: square(-122.45, 37.75, 0.01)}, wantCode: codes.FailedPrecondition},
+		{name: "rule of another zone", ctx: asCaller("ops"), zone: &pb.Zone{RuleId: "harbor", Area: square(-122.45, 37.75, 0.01)}, wantCode: codes.AlreadyExists},
+		{name: "no rule id", ctx: asCaller("ops"), zone: &pb.Zone{Area: square(-122.45, 37.75, 0.01)}, wantCode: codes.InvalidArgument},
+		{name: "point area", ctx: asCaller("ops"), zone: &pb.Zone{RuleId: "park", Area: position(-122.45, 37.75)}, wantCode: codes.InvalidArgument},
+		{name: "negative max crumbs", ctx: asCaller("ops"), zone: &pb.Zone{RuleId: "park", Area: square(-122.45, 37.75, 0.01), MaxCrumbs: -1}, wantCode: codes.InvalidArgument},
+		{name: "unknown zone", ctx: asCaller("ops"), zone: &pb.Zone{Id: "000000000000000000000000", RuleId: "park", Area: square(-122.45, 37.75, 0.01)}, wantCode: codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newZoneRoute(t)
+			if tt.policy != nil {
+				r.policy = tt.policy
+			}
+			got, err := r.PutZone(tt.ctx, tt.zone)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Route.PutZone() error = %v, want code %v", err, tt.wantCode)
+			}
+			if err == nil && (got.GetId() == "" || got.GetRuleId() != tt.zone.GetRuleId()) {
+				t.Errorf("Route.PutZone() = %v", got)
+			}
+		})
+	}
+}
+
+func TestRoute_ManageZones(t *testing.T) {
+	r, _ := newZoneRoute(t)
+	ctx := asCaller("ops")
+
+	zones, err := r.ListZones(ctx, &pb.ListZonesRequest{})
+	if err != nil || len(zones.GetZones()) != 2 {
+		t.Fatalf("Route.ListZones() = %v, %v, want 2 zones", zones, err)
+	}
+	var harbor *pb.Zone
+	for _, zone := range zones.GetZones() {
+		if zone.GetRuleId() == "harbor" {
+			harbor = zone
+		}
+	}
+
+	// allowing crumbs in the harbor lets them be created there
+	harbor.MaxCrumbs = 5
+	if _, err := r.PutZone(ctx, harbor); err != nil {
+		t.Fatalf("Route.PutZone() error = %v", err)
+	}
+	if _, err := r.Create(context.Background(), &pb.Crumb{User: "alice", Message: "hi", Location: position(-122.3, 37.7)}); err != nil {
+		t.Fatalf("Route.Create() error = %v", err)
+	}
+
+	// a zone saved with the rule of another one, after both were looked up, is rejected by the unique index
+	if _, err := r.saveZone(ctx, &pb.Zone{RuleId: "plaza", Area: square(-122.45, 37.75, 0.01)}); status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("Route.saveZone() error = %v, want code %v", err, codes.AlreadyExists)
+	}
+	if _, err := r.saveZone(ctx, &pb.Zone{Id: harbor.GetId(), RuleId: "plaza", Area: harbor.GetArea()}); status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("Route.saveZone() error = %v, want code %v", err, codes.AlreadyExists)
+	}
+
+	if _, err := r.DeleteZone(asCaller("alice"), &pb.Id{Value: harbor.GetId()}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("Route.DeleteZone() error = %v, want code %v", err, codes.PermissionDenied)
+	}
+	if _, err := r.DeleteZone(ctx, &pb.Id{Value: harbor.GetId()}); err != nil {
+		t.Fatalf("Route.DeleteZone() error = %v", err)
+	}
+	zones, err = r.ListZones(ctx, &pb.ListZonesRequest{})
+	if err != nil || len(zones.GetZones()) != 1 || zones.GetZones()[0].GetRuleId() != "plaza" {
+		t.Errorf("Route.ListZones() = %v, %v, want the plaza", zones, err)
+	}
+}
+
+func TestRoute_LoadZones(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantErr   bool
+		wantZones int
+	}{
+		{
+			name:      "replaces zones by rule id",
+			data:      `{"type": "FeatureCollection", "features": [{"type": "Feature", "id": "harbor", "geometry": {"type": "Polygon", "coordinates": [[[-122.31, 37.69], [-122.29, 37.69], [-122.29, 37.71], [-122.31, 37.69]]]}, "properties": {"name": "Harbor", "max_crumbs": 3}}, {"type": "Feature", "id": 7, "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}, "properties": {}}]}`,
+			wantZones: 3,
+		},
+		{name: "not a feature collection", data: `{"type": "Feature"}`, wantErr: true, wantZones: 2},
+		{name: "no id", data: `{"type": "FeatureCollection", "features": [{"type": "Feature", "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}}]}`, wantErr: true, wantZones: 2},
+		{name: "point", data: `{"type": "FeatureCollection", "features": [{"type": "Feature", "id": "a", "geometry": {"type": "Point", "coordinates": [0, 0]}}]}`, wantErr: true, wantZones: 2},
+		{name: "invalid json", data: `{`, wantErr: true, wantZones: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newZoneRoute(t)
+			path := filepath.Join(t.TempDir(), "zones.geojson")
+			if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := r.LoadZones(context.Background(), path); (err != nil) != tt.wantErr {
+				t.Fatalf("Route.LoadZones() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			zones, err := r.ListZones(asCaller("ops"), &pb.ListZonesRequest{})
+			if err != nil || len(zones.GetZones()) != tt.wantZones {
+				t.Fatalf("Route.ListZones() = %v, %v, want %v zones", zones, err, tt.wantZones)
+			}
+			for _, zone := range zones.GetZones() {
+				if zone.GetRuleId() == "harbor" && zone.GetMaxCrumbs() != 3 && !tt.wantErr {
+					t.Errorf("harbor max crumbs = %v, want 3", zone.GetMaxCrumbs())
+				}
+			}
+		})
+	}
+}
@@ -18,14 +18,16 @@ func main() {
 			app.LoggingClient.Errorf("failed to disconnect db server: %v", err)
 		}
 	}()
+	// runs once the server stopped on SIGINT or SIGTERM, so the spans of the last calls are exported
+	defer func() {
+		if err = app.ShutdownTracing(context.TODO()); err != nil {
+			app.LoggingClient.Errorf("failed to shutdown tracer provider: %v", err)
+		}
+	}()
 
 	err = app.RunServer()
 	if err != nil {
 		app.LoggingClient.Errorf("failed to start grpc server: %v", err)
 		return
 	}
-	defer func() {
-		app.GrpcServer.Stop()
-	}()
-
 }
@@ -2,19 +2,35 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/haguru/horus/crumbdb/config"
 	"github.com/haguru/horus/crumbdb/internal/routes"
 	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	"github.com/haguru/horus/crumbdb/pkg/admin"
+	"github.com/haguru/horus/crumbdb/pkg/boltdb"
 	"github.com/haguru/horus/crumbdb/pkg/consul"
+	"github.com/haguru/horus/crumbdb/pkg/follower"
+	"github.com/haguru/horus/crumbdb/pkg/gateway"
 	"github.com/haguru/horus/crumbdb/pkg/healthcheck"
+	appLogging "github.com/haguru/horus/crumbdb/pkg/logging"
+	"github.com/haguru/horus/crumbdb/pkg/memory"
 	"github.com/haguru/horus/crumbdb/pkg/mongodb"
 	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces"
+	"github.com/haguru/horus/crumbdb/pkg/postgis"
 	appMetrics "github.com/haguru/horus/crumbdb/pkg/prometheus"
+	"github.com/haguru/horus/crumbdb/pkg/push"
+	"github.com/haguru/horus/crumbdb/pkg/ratelimit"
+	"github.com/haguru/horus/crumbdb/pkg/reload"
+	"github.com/haguru/horus/crumbdb/pkg/security"
+	"github.com/haguru/horus/crumbdb/pkg/spoof"
+	"github.com/haguru/horus/crumbdb/pkg/tracing"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	"github.com/go-playground/validator/v10"
@@ -22,12 +38,17 @@ import (
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
 )
 
 const (
 	METRICS_ENDPOINT = "/metrics"
 	READ_TIMEOUT     = 500 * time.Millisecond
+	// SHUTDOWN_TIMEOUT bounds the wait for the calls in flight, and then for the spans to be flushed, on shutdown
+	SHUTDOWN_TIMEOUT = 10 * time.Second
 )
 
 type App struct {
@@ -36,9 +57,14 @@ type App struct {
 	DbServerClient interfaces.Client
 	GrpcServer     *grpc.Server
 	LoggingClient  logger.LoggingClient
+	Reloader       *reload.Reloader
 	Route          *routes.Route
 	ServiceConfig  *config.ServiceConfig
+	TracerProvider *sdktrace.TracerProvider
+	limiter        *ratelimit.Limiter
 	metrics        *appMetrics.Metrics
+	pushServer     *push.Push
+	validator      *validator.Validate
 }
 
 func NewApp() (*App, error) {
@@ -56,31 +82,155 @@ func NewApp() (*App, error) {
 		return nil, fmt.Errorf("validation error: %s", errors)
 	}
 
-	lc := logger.NewClient(serviceConfig.ServiceName, serviceConfig.LogLevel)
+	lc := appLogging.NewClient(serviceConfig.ServiceName, serviceConfig.LogLevel, serviceConfig.LogFormat)
 
-	host := serviceConfig.Database.Host
-	port := serviceConfig.Database.Port
+	var tp *sdktrace.TracerProvider
+	if serviceConfig.Tracing.Enabled {
+		exporter, err := tracing.NewExporter(context.Background(), &serviceConfig.Tracing)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create trace exporter: %v", err)
+		}
 
-	timeout, err := time.ParseDuration(serviceConfig.Database.Timeout)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse timeout: %v", err)
+		tp, err = tracing.NewTracerProvider(&serviceConfig.Tracing, serviceConfig.ServiceName, exporter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create tracer provider: %v", err)
+		}
 	}
-	db, err := mongodb.NewMongoDB(host, port, lc, timeout, nil)
+
+	metrics := appMetrics.NewMetrics(serviceConfig)
+
+	db, err := newDatabase(&serviceConfig.Database, lc, metrics)
 	if err != nil {
 		lc.Errorf("failed to connect, %v\n", err)
 		return nil, err
 	}
 
+	if serviceConfig.Query.MaxDistance > 0 {
+		db.SetDistanceLimits(serviceConfig.Query.MinDistance, serviceConfig.Query.MaxDistance)
+	}
+
 	dbConfig := serviceConfig.Database
-	err = db.CreateSpatialIndex(dbConfig.DatabaseName, dbConfig.Collection, mongodb.SPATIAL_INDEX_TYPE)
+	err = db.CreateSpatialIndex(context.Background(), dbConfig.DatabaseName, dbConfig.Collection, mongodb.SPATIAL_INDEX_TYPE)
 	if err != nil {
 		lc.Errorf("failed to create spatial index: %v", err)
 		return nil, err
 	}
+	err = db.CreateUserIndex(context.Background(), dbConfig.DatabaseName, dbConfig.Collection)
+	if err != nil {
+		lc.Errorf("failed to create user index: %v", err)
+		return nil, err
+	}
 
-	metrics := appMetrics.NewMetrics(serviceConfig)
+	trailCollection := routes.TrailCollection(&dbConfig)
+	err = db.CreateSpatialIndex(context.Background(), dbConfig.DatabaseName, trailCollection, mongodb.SPATIAL_INDEX_TYPE)
+	if err != nil {
+		lc.Errorf("failed to create trail spatial index: %v", err)
+		return nil, err
+	}
+	err = db.CreateFieldIndex(context.Background(), dbConfig.DatabaseName, trailCollection, routes.TRAIL_CRUMB_IDS_KEY)
+	if err != nil {
+		lc.Errorf("failed to create trail crumbs index: %v", err)
+		return nil, err
+	}
+	err = db.CreateUserIndex(context.Background(), dbConfig.DatabaseName, trailCollection)
+	if err != nil {
+		lc.Errorf("failed to create trail user index: %v", err)
+		return nil, err
+	}
+	err = db.CreateFieldIndex(context.Background(), dbConfig.DatabaseName, routes.UnlockCollection(&dbConfig), routes.UNLOCK_CRUMB_ID_KEY)
+	if err != nil {
+		lc.Errorf("failed to create unlock crumbs index: %v", err)
+		return nil, err
+	}
 
-	route := routes.NewRoute(lc, &serviceConfig.Database, db, validate)
+	var policy *config.Policy
+	if serviceConfig.Policy.Enabled {
+		policy = &serviceConfig.Policy
+		zoneCollection := routes.ZoneCollection(&dbConfig)
+		err = db.CreateSpatialIndex(context.Background(), dbConfig.DatabaseName, zoneCollection, mongodb.SPATIAL_INDEX_TYPE)
+		if err != nil {
+			lc.Errorf("failed to create zone spatial index: %v", err)
+			return nil, err
+		}
+		err = db.CreateUniqueIndex(context.Background(), dbConfig.DatabaseName, zoneCollection, routes.ZONE_RULE_ID_KEY)
+		if err != nil {
+			lc.Errorf("failed to create zone rules index: %v", err)
+			return nil, err
+		}
+	}
+
+	var store ratelimit.Store = ratelimit.NewMemoryStore()
+	if serviceConfig.RateLimit.Enabled && serviceConfig.RateLimit.Backend == ratelimit.BACKEND_MONGODB {
+		mongoDB, ok := db.(*mongodb.MongoDB)
+		if !ok {
+			return nil, fmt.Errorf("rate limit backend %v requires a mongodb database", ratelimit.BACKEND_MONGODB)
+		}
+
+		collection := serviceConfig.RateLimit.Collection
+		if collection == "" {
+			collection = ratelimit.DEFAULT_COLLECTION
+		}
+		store, err = ratelimit.NewMongoStore(context.Background(), mongoDB, dbConfig.DatabaseName, collection)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create rate limit store: %v", err)
+		}
+	}
+	limiter := ratelimit.NewLimiter(&serviceConfig.RateLimit, store, lc, metrics)
+
+	var positions routes.Positions
+	if serviceConfig.Spoofing.Enabled {
+		var history spoof.Store = spoof.NewMemoryStore()
+		if serviceConfig.Spoofing.Backend == spoof.BACKEND_MONGODB {
+			mongoDB, ok := db.(*mongodb.MongoDB)
+			if !ok {
+				return nil, fmt.Errorf("spoofing backend %v requires a mongodb database", spoof.BACKEND_MONGODB)
+			}
+
+			collection := serviceConfig.Spoofing.Collection
+			if collection == "" {
+				collection = spoof.DEFAULT_COLLECTION
+			}
+			history, err = spoof.NewMongoStore(context.Background(), mongoDB, dbConfig.DatabaseName, collection)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create position history store: %v", err)
+			}
+		}
+		positions, err = spoof.NewDetector(&serviceConfig.Spoofing, history, lc, metrics)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var follows routes.Follows
+	if serviceConfig.Follower.Address != "" {
+		followerClient, err := follower.Dial(&serviceConfig.Follower, lc)
+		if err != nil {
+			return nil, err
+		}
+		follows = followerClient
+	} else {
+		lc.Warn("no follower service configured, followers-only crumbs are only visible to their owners")
+	}
+
+	hub := push.NewHub(lc)
+	route := routes.NewRoute(lc, &serviceConfig.Database, db, metrics, validate, hub, follows, positions, policy)
+	if policy != nil && policy.File != "" {
+		if err := route.LoadZones(context.Background(), policy.File); err != nil {
+			return nil, err
+		}
+	}
+
+	var pushServer *push.Push
+	if serviceConfig.Push.Enabled {
+		if !serviceConfig.Gateway.Enabled {
+			return nil, fmt.Errorf("push requires the gateway to be enabled")
+		}
+
+		pushServer, err = push.NewPush(&serviceConfig.Push, hub, route, lc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create push endpoint: %v", err)
+		}
+	}
 
 	consulClient, err := consul.NewConsul(&serviceConfig.Consul)
 	if err != nil {
@@ -94,7 +244,11 @@ func NewApp() (*App, error) {
 		LoggingClient:  lc,
 		Route:          route,
 		ServiceConfig:  serviceConfig,
+		TracerProvider: tp,
+		limiter:        limiter,
 		metrics:        metrics,
+		pushServer:     pushServer,
+		validator:      validate,
 	}, nil
 }
 
@@ -104,25 +258,81 @@ func (app *App) RunServer() error {
 		return fmt.Errorf("failed to listen: %v", err)
 	}
 
-	err = app.Consul.RegisterService(app.ServiceConfig.ServiceName, app.ServiceConfig.Port)
+	err = app.Consul.RegisterService(app.ServiceConfig.ServiceName, app.ServiceConfig.Port, app.ServiceConfig.TLS.Enabled)
 	if err != nil {
 		return fmt.Errorf("failed to register service: %v", err)
 	}
 
-	// Create a gRPC Server with gRPC interceptor.
-	app.GrpcServer = grpc.NewServer(
+	serverOpts := []grpc.ServerOption{
 		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+	}
+
+	var metricsTLSConfig *tls.Config
+	var gatewayTLSConfig *tls.Config
+	var gatewayCreds credentials.TransportCredentials
+	var isGateway func(context.Context) bool
+	if app.ServiceConfig.TLS.Enabled {
+		app.LoggingClient.Debug("loading tls certificates")
+		certs, err := security.NewCertReloader(&app.ServiceConfig.TLS, app.LoggingClient)
+		if err != nil {
+			return fmt.Errorf("failed to load tls certificates: %v", err)
+		}
+
+		grpcTLSConfig, err := certs.ServerTLSConfig(security.ALPN_HTTP2)
+		if err != nil {
+			return fmt.Errorf("failed to create tls config: %v", err)
+		}
+
+		metricsTLSConfig, err = certs.ServerTLSConfig()
+		if err != nil {
+			return fmt.Errorf("failed to create tls config: %v", err)
+		}
+
+		gatewayTLSConfig, err = certs.ServerTLSConfig()
+		if err != nil {
+			return fmt.Errorf("failed to create tls config: %v", err)
+		}
+
+		loopbackTLSConfig, err := certs.LoopbackTLSConfig()
+		if err != nil {
+			return fmt.Errorf("failed to create tls config: %v", err)
+		}
+		gatewayCreds = credentials.NewTLS(loopbackTLSConfig)
+		// the gateway presents the service certificate, so only its forwarded client IPs and identities are trusted
+		isGateway = certs.IsLoopback
+		app.limiter.TrustGateway(isGateway)
+
+		go func() {
+			if err := certs.Watch(app.AppCtx); err != nil {
+				app.LoggingClient.Errorf("failed to watch tls certificates: %v", err)
+			}
+		}()
+
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(grpcTLSConfig)))
+	} else if app.ServiceConfig.Gateway.Enabled && app.ServiceConfig.RateLimit.Enabled {
+		app.LoggingClient.Warn("the gateway cannot be recognized without tls, the requests it proxies share its rate limit")
+	}
+
+	// Create a gRPC Server with gRPC interceptor.
+	serverOpts = append(serverOpts,
 		grpc.ChainUnaryInterceptor(
+			security.UnaryServerInterceptor(isGateway),
 			grpc.UnaryServerInterceptor(app.metrics.GrpcMetrics.UnaryServerInterceptor(grpcprom.WithExemplarFromContext(appMetrics.ExemplarFromContext))),
-			logging.UnaryServerInterceptor(appMetrics.InterceptorLogger(app.LoggingClient), logging.WithFieldsFromContext(appMetrics.LogTraceID)),
+			logging.UnaryServerInterceptor(appMetrics.InterceptorLogger(app.LoggingClient), logging.WithFieldsFromContext(appLogging.RequestFields)),
+			app.limiter.UnaryServerInterceptor(),
 		),
 		grpc.ChainStreamInterceptor(
+			security.StreamServerInterceptor(isGateway),
 			grpc.StreamServerInterceptor(app.metrics.GrpcMetrics.StreamServerInterceptor(grpcprom.WithExemplarFromContext(appMetrics.ExemplarFromContext))),
-			logging.StreamServerInterceptor(appMetrics.InterceptorLogger(app.LoggingClient), logging.WithFieldsFromContext(appMetrics.LogTraceID)),
+			logging.StreamServerInterceptor(appMetrics.InterceptorLogger(app.LoggingClient), logging.WithFieldsFromContext(appLogging.RequestFields)),
+			app.limiter.StreamServerInterceptor(),
 		),
 	)
+	app.GrpcServer = grpc.NewServer(serverOpts...)
 
 	pb.RegisterCrumbDBServer(app.GrpcServer, app.Route)
+	// server reflection lets horusctl and grpcurl describe the methods
+	reflection.Register(app.GrpcServer)
 	app.metrics.GrpcMetrics.InitializeMetrics(app.GrpcServer)
 
 	pingInterval, err := time.ParseDuration(app.ServiceConfig.Database.PingInterval)
@@ -140,21 +350,86 @@ func (app *App) RunServer() error {
 	app.LoggingClient.Debug("starting healthcheck service")
 	go health.StartHealthCheckService(app.DbServerClient)
 
+	app.Reloader = reload.NewReloader(config.CONFIG_PATH, app.ServiceConfig, app.LoggingClient, app.validator, app.metrics, app.Consul, health, app.DbServerClient)
+	go func() {
+		if err := app.Reloader.Start(app.AppCtx); err != nil {
+			app.LoggingClient.Errorf("failed to watch for config changes: %v", err)
+		}
+	}()
+
+	var adminEndpoints *admin.Admin
+	if app.ServiceConfig.Admin.Pprof || app.ServiceConfig.Admin.Channelz {
+		adminEndpoints, err = admin.NewAdmin(&app.ServiceConfig.Admin, app.LoggingClient)
+		if err != nil {
+			return fmt.Errorf("failed to create admin endpoints: %v", err)
+		}
+	}
+
 	go func() {
 		addr := fmt.Sprintf(":%d", app.ServiceConfig.Metrics.Port)
-		metricsServer := &http.Server{Addr: addr, ReadTimeout: READ_TIMEOUT}
+		metricsServer := &http.Server{Addr: addr, ReadTimeout: READ_TIMEOUT, TLSConfig: metricsTLSConfig}
 		muxHandler := http.NewServeMux()
 		muxHandler.Handle(METRICS_ENDPOINT, promhttp.HandlerFor(app.metrics.Registry, promhttp.HandlerOpts{
 			EnableOpenMetrics: true,
 		}))
+		if adminEndpoints != nil {
+			adminEndpoints.Register(muxHandler)
+		}
 
 		metricsServer.Handler = muxHandler
 		app.LoggingClient.Debugf("server(prometheus) listening at %v", app.ServiceConfig.Metrics.Port)
-		if err := metricsServer.ListenAndServe(); err != nil {
+		var err error
+		if metricsServer.TLSConfig != nil {
+			// certificates are served by the tls config
+			err = metricsServer.ListenAndServeTLS("", "")
+		} else {
+			err = metricsServer.ListenAndServe()
+		}
+		if err != nil {
 			app.LoggingClient.Error("failed to start prometheus client")
 		}
 	}()
 
+	if app.ServiceConfig.Gateway.Enabled {
+		conn, err := gateway.Dial(app.ServiceConfig.Port, gatewayCreds)
+		if err != nil {
+			return fmt.Errorf("failed to create gateway: %v", err)
+		}
+		handler, err := gateway.NewHandler(app.AppCtx, conn)
+		if err != nil {
+			return fmt.Errorf("failed to create gateway: %v", err)
+		}
+		if app.pushServer != nil {
+			mux := http.NewServeMux()
+			mux.Handle("/", handler)
+			mux.Handle(push.PUSH_ENDPOINT, app.pushServer)
+			handler = mux
+		}
+
+		go func() {
+			addr := fmt.Sprintf(":%d", app.ServiceConfig.Gateway.Port)
+			gatewayServer := &http.Server{Addr: addr, Handler: handler, ReadHeaderTimeout: READ_TIMEOUT, TLSConfig: gatewayTLSConfig}
+			app.LoggingClient.Debugf("server(gateway) listening at %v", app.ServiceConfig.Gateway.Port)
+			var err error
+			if gatewayServer.TLSConfig != nil {
+				err = gatewayServer.ListenAndServeTLS("", "")
+			} else {
+				err = gatewayServer.ListenAndServe()
+			}
+			if err != nil {
+				app.LoggingClient.Errorf("failed to start gateway: %v", err)
+			}
+		}()
+	}
+
+	// SIGINT and SIGTERM stop the server, so RunServer returns and the spans can be flushed before exiting
+	signals, stop := signal.NotifyContext(app.AppCtx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-signals.Done()
+		app.stopServer()
+	}()
+
 	app.LoggingClient.Debugf("server listening at %v", lis.Addr())
 	err = app.GrpcServer.Serve(lis)
 	if err != nil {
@@ -163,3 +438,51 @@ func (app *App) RunServer() error {
 
 	return nil
 }
+
+// stopServer stops the gRPC server once the calls in flight end, cancelling those still running after
+// SHUTDOWN_TIMEOUT
+func (app *App) stopServer() {
+	app.LoggingClient.Info("stopping the server")
+	stopped := make(chan struct{})
+	go func() {
+		app.GrpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(SHUTDOWN_TIMEOUT):
+		app.LoggingClient.Warn("calls still running after the shutdown timeout are cancelled")
+		app.GrpcServer.Stop()
+	}
+}
+
+// ShutdownTracing flushes any buffered spans, waiting at most SHUTDOWN_TIMEOUT, and stops the tracer provider
+func (app *App) ShutdownTracing(ctx context.Context) error {
+	if app.TracerProvider == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, SHUTDOWN_TIMEOUT)
+	defer cancel()
+	return app.TracerProvider.Shutdown(ctx)
+}
+
+// newDatabase returns the client of the database selected by dbConfig.Driver
+func newDatabase(dbConfig *config.Database, lc logger.LoggingClient, metrics *appMetrics.Metrics) (interfaces.Client, error) {
+	timeout, err := time.ParseDuration(dbConfig.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse timeout: %v", err)
+	}
+
+	switch dbConfig.Driver {
+	case config.DRIVER_MEMORY:
+		lc.Warn("using the in-memory database, documents are lost when the service stops")
+		return memory.NewMemory(lc), nil
+	case config.DRIVER_BBOLT:
+		return boltdb.NewBoltDB(dbConfig.Path, lc, timeout)
+	case config.DRIVER_POSTGIS:
+		return postgis.NewPostGIS(dbConfig.DSN, lc, timeout)
+	}
+	return mongodb.NewMongoDB(dbConfig.Host, dbConfig.Port, lc, metrics, timeout, nil)
+}
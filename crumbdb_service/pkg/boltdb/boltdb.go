@@ -0,0 +1,755 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/haguru/horus/crumbdb/pkg/document"
+	"github.com/haguru/horus/crumbdb/pkg/geo"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	FILE_MODE = 0600
+	DIR_MODE  = 0700
+
+	DOCUMENTS_BUCKET = "documents"
+	IDS_BUCKET       = "ids"
+	GEOHASH_BUCKET   = "geohash"
+	USERS_BUCKET     = "users"
+	UNIQUE_BUCKET    = "unique"
+	INDEX_KEY        = "index"
+)
+
+// BoltDB is an implementation of interfaces.Client storing documents in a single bbolt file for single-node
+// deployments. Every collection is a bucket holding the BSON documents, keyed by insertion sequence, an index
+// of their ids, a geohash index of their points and an index of their users
+type BoltDB struct {
+	Path        string
+	DB          *bbolt.DB
+	timeout     time.Duration
+	lc          logger.LoggingClient
+	maxDistance int
+	minDistance int
+	mu          sync.RWMutex
+}
+
+// NewBoltDB opens the database file at path. Returns a interface for db client and error if it occurs
+func NewBoltDB(path string, lc logger.LoggingClient, timeout time.Duration) (interfaces.Client, error) {
+	db := &BoltDB{
+		Path:        path,
+		timeout:     timeout,
+		lc:          lc,
+		maxDistance: mongodb.MAX_DISTANCE,
+		minDistance: mongodb.MIN_DISTANCE,
+	}
+	err := db.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Connect opens the database file, creating it and its directory if needed. Fails if another process holds
+// the file for longer than the timeout
+func (db *BoltDB) Connect() error {
+	if err := os.MkdirAll(filepath.Dir(db.Path), DIR_MODE); err != nil {
+		return fmt.Errorf("failed to create database directory: %v", err)
+	}
+
+	db.lc.Debugf("opening database: %v", db.Path)
+	var err error
+	db.DB, err = bbolt.Open(db.Path, FILE_MODE, &bbolt.Options{Timeout: db.timeout})
+	if err != nil {
+		return fmt.Errorf("failed to open database %v: %v", db.Path, err)
+	}
+	if err := db.DB.Update(migrateUsers); err != nil {
+		db.DB.Close()
+		return fmt.Errorf("failed to migrate database %v: %v", db.Path, err)
+	}
+
+	db.lc.Debugf("successfully opened database: %v", db.Path)
+	return nil
+}
+
+// Ping returns error if the database file is closed
+func (db *BoltDB) Ping() error {
+	return db.DB.View(func(*bbolt.Tx) error { return nil })
+}
+
+// Disconnect closes the database file
+func (db *BoltDB) Disconnect(context.Context) error {
+	return db.DB.Close()
+}
+
+// CreateSpatialIndex enables near queries on the collection. Only 2dsphere indexes are supported
+func (db *BoltDB) CreateSpatialIndex(_ context.Context, databaseName string, collectionName string, spatialType string) error {
+	if spatialType != mongodb.SPATIAL_INDEX_TYPE {
+		return fmt.Errorf("index type %v not supported", spatialType)
+	}
+
+	return db.DB.Update(func(tx *bbolt.Tx) error {
+		c, err := createCollection(tx, databaseName, collectionName)
+		if err != nil {
+			return err
+		}
+		return c.root.Put([]byte(INDEX_KEY), []byte(spatialType))
+	})
+}
+
+// CreateUserIndex does nothing, the users index of FindByUser is kept for every collection
+func (db *BoltDB) CreateUserIndex(context.Context, string, string) error {
+	return nil
+}
+
+// CreateFieldIndex does nothing, FindByField scans the collection
+func (db *BoltDB) CreateFieldIndex(context.Context, string, string, string) error {
+	return nil
+}
+
+// CreateUniqueIndex records key in the unique bucket of the collection, InsertRecord and Update then scan the
+// collection for the value of key of the documents they write
+func (db *BoltDB) CreateUniqueIndex(_ context.Context, databaseName string, collectionName string, key string) error {
+	return db.DB.Update(func(tx *bbolt.Tx) error {
+		c, err := createCollection(tx, databaseName, collectionName)
+		if err != nil {
+			return err
+		}
+		unique, err := c.root.CreateBucketIfNotExists([]byte(UNIQUE_BUCKET))
+		if err != nil {
+			return fmt.Errorf("failed to create unique index: %v", err)
+		}
+		return unique.Put([]byte(key), []byte{})
+	})
+}
+
+// InsertRecord returns ID, as string, and error.
+// if error occurs an empty string is returned along with the error
+func (db *BoltDB) InsertRecord(_ context.Context, databaseName string, collectionName string, doc interface{}) (string, error) {
+	record, err := document.ToDocument(doc)
+	if err != nil {
+		return "", err
+	}
+
+	// like mongodb, a missing _id is generated and stored as the first field
+	var objId primitive.ObjectID
+	if value, found := document.Lookup(record, document.IDFIELD); found {
+		var ok bool
+		objId, ok = value.(primitive.ObjectID)
+		if !ok {
+			return "", fmt.Errorf("failed to get objectID")
+		}
+	} else {
+		objId = primitive.NewObjectID()
+		record = append(bson.D{{Key: document.IDFIELD, Value: objId}}, record...)
+	}
+
+	err = db.DB.Update(func(tx *bbolt.Tx) error {
+		c, err := createCollection(tx, databaseName, collectionName)
+		if err != nil {
+			return err
+		}
+		if c.ids.Get(objId[:]) != nil {
+			return fmt.Errorf("duplicate key error: %v", objId.Hex())
+		}
+		if err := c.checkUnique(objId, record); err != nil {
+			return err
+		}
+
+		next, err := c.docs.NextSequence()
+		if err != nil {
+			return err
+		}
+		seq := binary.BigEndian.AppendUint64(nil, next)
+		if err := c.ids.Put(objId[:], seq); err != nil {
+			return err
+		}
+		return c.put(seq, objId, record)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return objId.Hex(), nil
+}
+
+// InsertMany inserts the documents one at a time, see interfaces.InsertEach
+func (db *BoltDB) InsertMany(ctx context.Context, databaseName string, collectionName string, docs []interface{}) ([]string, error) {
+	return interfaces.InsertEach(docs, func(doc interface{}) (string, error) {
+		return db.InsertRecord(ctx, databaseName, collectionName, doc)
+	})
+}
+
+// SpaitalQuery returns the documents within the distance limits of a Point, nearest first, or the documents
+// inside a Polygon in insertion order
+func (db *BoltDB) SpaitalQuery(_ context.Context, pointType string, coordinates []float64, databaseName string, collectionName string) ([]bson.D, error) {
+	var ring [][]float64
+	switch pointType {
+	case mongodb.POINT_TYPE_POINT:
+		if !geo.Valid(coordinates) {
+			return nil, fmt.Errorf("failed to perforom spatial query: invalid point %v", coordinates)
+		}
+	case mongodb.POINT_TYPE_POLYGON:
+		var err error
+		ring, err = geo.Ring(coordinates)
+		if err != nil {
+			return nil, fmt.Errorf("failed to perforom spatial query: invalid polygon: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("failed to perforom spatial query: point type %v not supported", pointType)
+	}
+
+	db.mu.RLock()
+	maxDistance, minDistance := float64(db.maxDistance), float64(db.minDistance)
+	db.mu.RUnlock()
+
+	var docs []bson.D
+	err := db.DB.View(func(tx *bbolt.Tx) error {
+		c := collection(tx, databaseName, collectionName)
+		if c == nil {
+			return nil
+		}
+
+		var seqs [][]byte
+		if ring != nil {
+			seqs = c.within(ring)
+		} else {
+			// like mongodb, $near needs the index while $geoWithin does not
+			if c.root.Get([]byte(INDEX_KEY)) == nil {
+				return fmt.Errorf("unable to find index for $geoNear query")
+			}
+			seqs = c.near(coordinates, minDistance, maxDistance)
+		}
+
+		docs = make([]bson.D, 0, len(seqs))
+		for _, seq := range seqs {
+			doc, err := c.get(seq)
+			if err != nil {
+				return err
+			}
+			docs = append(docs, doc)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return docs, nil
+}
+
+// SpatialIntersects returns the documents whose Point, LineString or Polygon location intersects a Point or a
+// Polygon in insertion order
+func (db *BoltDB) SpatialIntersects(_ context.Context, databaseName string, collectionName string, pointType string, coordinates []float64) ([]bson.D, error) {
+	geometry, err := document.Geometry(pointType, coordinates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to perforom spatial query: %v", err)
+	}
+
+	// lines and polygons are not in the geohash index, so the collection is scanned
+	return db.filter(databaseName, collectionName, func(doc bson.D) (bool, error) {
+		return document.Intersects(doc, geometry), nil
+	})
+}
+
+// CountIntersects returns the number of documents SpatialIntersects retrieves
+func (db *BoltDB) CountIntersects(ctx context.Context, databaseName string, collectionName string, pointType string, coordinates []float64) (int64, error) {
+	docs, err := db.SpatialIntersects(ctx, databaseName, collectionName, pointType, coordinates)
+	return int64(len(docs)), err
+}
+
+// SetDistanceLimits sets the distance bounds, in meters, used by SpaitalQuery
+func (db *BoltDB) SetDistanceLimits(minDistance int, maxDistance int) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.minDistance = minDistance
+	db.maxDistance = maxDistance
+}
+
+// FindAll retrieves all documents in the collection in insertion order
+func (db *BoltDB) FindAll(_ context.Context, databaseName string, collectionName string) ([]bson.D, error) {
+	var results []bson.D
+	err := db.DB.View(func(tx *bbolt.Tx) error {
+		c := collection(tx, databaseName, collectionName)
+		if c == nil {
+			return nil
+		}
+
+		return c.docs.ForEach(func(_, v []byte) error {
+			var doc bson.D
+			if err := bson.Unmarshal(v, &doc); err != nil {
+				return fmt.Errorf("failed to unmarshal document: %v", err)
+			}
+			results = append(results, doc)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// FindOne retrieves a document by ID. Returns mongo.ErrNoDocuments if there is none
+func (db *BoltDB) FindOne(_ context.Context, databaseName string, collectionName string, id string) (*bson.D, error) {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	var data bson.D
+	err = db.DB.View(func(tx *bbolt.Tx) error {
+		c := collection(tx, databaseName, collectionName)
+		if c == nil {
+			return mongo.ErrNoDocuments
+		}
+		seq := c.ids.Get(objId[:])
+		if seq == nil {
+			return mongo.ErrNoDocuments
+		}
+
+		data, err = c.get(seq)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// FindMany retrieves the documents with the given IDs in the order of ids. IDs matching no document are skipped
+func (db *BoltDB) FindMany(_ context.Context, databaseName string, collectionName string, ids []string) ([]bson.D, error) {
+	var results []bson.D
+	err := db.DB.View(func(tx *bbolt.Tx) error {
+		c := collection(tx, databaseName, collectionName)
+		if c == nil {
+			return nil
+		}
+
+		seen := map[primitive.ObjectID]bool{}
+		for _, id := range ids {
+			// like FindOne, a malformed id matches no document
+			objId, err := primitive.ObjectIDFromHex(id)
+			if err != nil || seen[objId] {
+				continue
+			}
+			seen[objId] = true
+
+			seq := c.ids.Get(objId[:])
+			if seq == nil {
+				continue
+			}
+			doc, err := c.get(seq)
+			if err != nil {
+				return err
+			}
+			results = append(results, doc)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// FindByField retrieves the documents whose key equals value, or is an array containing it, in insertion order
+func (db *BoltDB) FindByField(_ context.Context, databaseName string, collectionName string, key string, value interface{}) ([]bson.D, error) {
+	return db.filter(databaseName, collectionName, func(doc bson.D) (bool, error) {
+		return document.Matches(doc, key, value)
+	})
+}
+
+// filter returns the documents of the collection matching fn in insertion order
+func (db *BoltDB) filter(databaseName string, collectionName string, fn func(doc bson.D) (bool, error)) ([]bson.D, error) {
+	var results []bson.D
+	err := db.DB.View(func(tx *bbolt.Tx) error {
+		c := collection(tx, databaseName, collectionName)
+		if c == nil {
+			return nil
+		}
+
+		return c.docs.ForEach(func(_, v []byte) error {
+			var doc bson.D
+			if err := bson.Unmarshal(v, &doc); err != nil {
+				return fmt.Errorf("failed to unmarshal document: %v", err)
+			}
+			matches, err := fn(doc)
+			if err != nil || !matches {
+				return err
+			}
+			results = append(results, doc)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// FindByUser retrieves at most limit documents of user, newest first, starting after the cursor if it is not nil
+func (db *BoltDB) FindByUser(_ context.Context, databaseName string, collectionName string, user string, after *interfaces.Cursor, limit int) ([]bson.D, error) {
+	// the listing starts before the largest key of the user unless a cursor is given
+	start := userKey(user, math.MaxInt64, primitive.ObjectID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	if after != nil {
+		objId, err := primitive.ObjectIDFromHex(after.Id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor id %v", after.Id)
+		}
+		start = userKey(user, after.Created, objId)
+	}
+	prefix := userPrefix(user)
+
+	var results []bson.D
+	err := db.DB.View(func(tx *bbolt.Tx) error {
+		c := collection(tx, databaseName, collectionName)
+		if c == nil {
+			return nil
+		}
+
+		cursor := c.users.Cursor()
+		k, seq := cursor.Seek(start)
+		if k == nil {
+			k, seq = cursor.Last()
+		} else {
+			k, seq = cursor.Prev()
+		}
+		for ; k != nil && bytes.HasPrefix(k, prefix) && (limit <= 0 || len(results) < limit); k, seq = cursor.Prev() {
+			doc, err := c.get(seq)
+			if err != nil {
+				return err
+			}
+			results = append(results, doc)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Update sets items on the document with ID and increments its version, returning the updated document.
+// The version must match the version of the document, zero matching a document without one
+func (db *BoltDB) Update(_ context.Context, databaseName string, collectionName string, id string, version int64, items map[string]interface{}) (*bson.D, error) {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		// like FindOne, a malformed id matches no document
+		return nil, mongo.ErrNoDocuments
+	}
+	set, err := document.ToDocument(items)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated bson.D
+	err = db.DB.Update(func(tx *bbolt.Tx) error {
+		c := collection(tx, databaseName, collectionName)
+		if c == nil {
+			return mongo.ErrNoDocuments
+		}
+		seq := c.ids.Get(objId[:])
+		if seq == nil {
+			return mongo.ErrNoDocuments
+		}
+
+		doc, err := c.get(seq)
+		if err != nil {
+			return err
+		}
+		if err := c.unindex(seq, objId, doc); err != nil {
+			return err
+		}
+		updated, err = document.Update(doc, version, set)
+		if err != nil {
+			return err
+		}
+		if err := c.checkUnique(objId, updated); err != nil {
+			return err
+		}
+		return c.put(seq, objId, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// Delete removes the document with ID. Returns mongo.ErrNoDocuments if there is no document with ID
+func (db *BoltDB) Delete(_ context.Context, databaseName string, collectionName string, id string) error {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		// like FindOne, a malformed id matches no document
+		return mongo.ErrNoDocuments
+	}
+
+	return db.DB.Update(func(tx *bbolt.Tx) error {
+		c := collection(tx, databaseName, collectionName)
+		if c == nil {
+			return mongo.ErrNoDocuments
+		}
+		seq := c.ids.Get(objId[:])
+		if seq == nil {
+			return mongo.ErrNoDocuments
+		}
+
+		doc, err := c.get(seq)
+		if err != nil {
+			return err
+		}
+		if err := c.unindex(seq, objId, doc); err != nil {
+			return err
+		}
+		if err := c.docs.Delete(seq); err != nil {
+			return err
+		}
+		return c.ids.Delete(objId[:])
+	})
+}
+
+// buckets is a collection within a transaction
+type buckets struct {
+	root    *bbolt.Bucket
+	docs    *bbolt.Bucket
+	ids     *bbolt.Bucket
+	geohash *bbolt.Bucket
+	users   *bbolt.Bucket
+}
+
+// collection returns the buckets of the collection, nil if it was never written to
+func collection(tx *bbolt.Tx, databaseName string, collectionName string) *buckets {
+	root := tx.Bucket([]byte(namespace(databaseName, collectionName)))
+	if root == nil {
+		return nil
+	}
+	return &buckets{
+		root:    root,
+		docs:    root.Bucket([]byte(DOCUMENTS_BUCKET)),
+		ids:     root.Bucket([]byte(IDS_BUCKET)),
+		geohash: root.Bucket([]byte(GEOHASH_BUCKET)),
+		users:   root.Bucket([]byte(USERS_BUCKET)),
+	}
+}
+
+// createCollection returns the buckets of the collection, creating them like mongodb does on first use
+func createCollection(tx *bbolt.Tx, databaseName string, collectionName string) (*buckets, error) {
+	root, err := tx.CreateBucketIfNotExists([]byte(namespace(databaseName, collectionName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create collection: %v", err)
+	}
+	for _, name := range []string{DOCUMENTS_BUCKET, IDS_BUCKET, GEOHASH_BUCKET, USERS_BUCKET} {
+		if _, err := root.CreateBucketIfNotExists([]byte(name)); err != nil {
+			return nil, fmt.Errorf("failed to create collection: %v", err)
+		}
+	}
+	return collection(tx, databaseName, collectionName), nil
+}
+
+func (c *buckets) get(seq []byte) (bson.D, error) {
+	var doc bson.D
+	if err := bson.Unmarshal(c.docs.Get(seq), &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal document: %v", err)
+	}
+	return doc, nil
+}
+
+// checkUnique returns ErrDuplicateKey if a document other than the one with objId has the value of doc for a
+// key of the unique bucket
+func (c *buckets) checkUnique(objId primitive.ObjectID, doc bson.D) error {
+	unique := c.root.Bucket([]byte(UNIQUE_BUCKET))
+	if unique == nil {
+		return nil
+	}
+
+	return unique.ForEach(func(key, _ []byte) error {
+		return c.docs.ForEach(func(_, v []byte) error {
+			var other bson.D
+			if err := bson.Unmarshal(v, &other); err != nil {
+				return fmt.Errorf("failed to unmarshal document: %v", err)
+			}
+			if otherId, _ := document.Lookup(other, document.IDFIELD); otherId == objId {
+				return nil
+			}
+			if document.SameValue(doc, other, string(key)) {
+				return fmt.Errorf("%w: %v", interfaces.ErrDuplicateKey, string(key))
+			}
+			return nil
+		})
+	})
+}
+
+// put stores doc with objId under seq and indexes its user and point
+func (c *buckets) put(seq []byte, objId primitive.ObjectID, doc bson.D) error {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal document: %v", err)
+	}
+	if err := c.docs.Put(seq, data); err != nil {
+		return err
+	}
+	if user, created := document.Author(doc); user != "" {
+		if err := c.users.Put(userKey(user, created, objId), seq); err != nil {
+			return err
+		}
+	}
+
+	point, ok := document.Location(doc)
+	if !ok {
+		return nil
+	}
+	return c.geohash.Put(indexKey(point, seq), indexValue(point))
+}
+
+// unindex removes the user and point of doc, stored with objId under seq, from the indexes
+func (c *buckets) unindex(seq []byte, objId primitive.ObjectID, doc bson.D) error {
+	if user, created := document.Author(doc); user != "" {
+		if err := c.users.Delete(userKey(user, created, objId)); err != nil {
+			return err
+		}
+	}
+
+	point, ok := document.Location(doc)
+	if !ok {
+		return nil
+	}
+	return c.geohash.Delete(indexKey(point, seq))
+}
+
+// near returns the sequences of the documents between minDistance and maxDistance meters of coordinates,
+// nearest first. A zero maxDistance does not limit the query, like mongodb
+func (c *buckets) near(coordinates []float64, minDistance float64, maxDistance float64) [][]byte {
+	prefixes := []string{""}
+	if maxDistance > 0 {
+		prefixes = nil
+		for _, box := range geo.Bounds(coordinates, maxDistance) {
+			prefixes = append(prefixes, cover(box)...)
+		}
+	} else {
+		maxDistance = math.Inf(1)
+	}
+
+	type match struct {
+		seq      []byte
+		distance float64
+	}
+	var matches []match
+	c.scan(prefixes, func(seq []byte, point []float64) {
+		distance := geo.Distance(coordinates, point)
+		if distance >= minDistance && distance <= maxDistance {
+			matches = append(matches, match{seq: seq, distance: distance})
+		}
+	})
+
+	// ties keep the insertion order
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance == matches[j].distance {
+			return bytes.Compare(matches[i].seq, matches[j].seq) < 0
+		}
+		return matches[i].distance < matches[j].distance
+	})
+
+	seqs := make([][]byte, 0, len(matches))
+	for _, m := range matches {
+		seqs = append(seqs, m.seq)
+	}
+	return seqs
+}
+
+// within returns the sequences of the documents inside ring in insertion order
+func (c *buckets) within(ring [][]float64) [][]byte {
+	var seqs [][]byte
+	c.scan(cover(geo.RingBounds(ring)), func(seq []byte, point []float64) {
+		if geo.InRing(point, ring) {
+			seqs = append(seqs, seq)
+		}
+	})
+
+	sort.Slice(seqs, func(i, j int) bool { return bytes.Compare(seqs[i], seqs[j]) < 0 })
+	return seqs
+}
+
+// scan calls fn once with every point of the geohash index under prefixes
+func (c *buckets) scan(prefixes []string, fn func(seq []byte, point []float64)) {
+	seen := map[string]bool{}
+	cursor := c.geohash.Cursor()
+	for _, prefix := range prefixes {
+		for k, v := cursor.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, v = cursor.Next() {
+			seq := k[GEOHASH_PRECISION:]
+			if seen[string(seq)] {
+				continue
+			}
+			seen[string(seq)] = true
+
+			// keys and values are only valid during the transaction
+			fn(bytes.Clone(seq), decodeIndexValue(v))
+		}
+	}
+}
+
+// migrateUsers creates the users index of the collections written before it existed
+func migrateUsers(tx *bbolt.Tx) error {
+	return tx.ForEach(func(_ []byte, root *bbolt.Bucket) error {
+		if root.Bucket([]byte(USERS_BUCKET)) != nil {
+			return nil
+		}
+		users, err := root.CreateBucket([]byte(USERS_BUCKET))
+		if err != nil {
+			return err
+		}
+
+		return root.Bucket([]byte(DOCUMENTS_BUCKET)).ForEach(func(seq, v []byte) error {
+			var doc bson.D
+			if err := bson.Unmarshal(v, &doc); err != nil {
+				return fmt.Errorf("failed to unmarshal document: %v", err)
+			}
+			id, _ := document.Lookup(doc, document.IDFIELD)
+			objId, ok := id.(primitive.ObjectID)
+			user, created := document.Author(doc)
+			if !ok || user == "" {
+				return nil
+			}
+			return users.Put(userKey(user, created, objId), bytes.Clone(seq))
+		})
+	})
+}
+
+// userPrefix returns the prefix of the users index keys of user. The length of the user comes first so no user
+// is the prefix of another
+func userPrefix(user string) []byte {
+	return append(binary.AppendUvarint(nil, uint64(len(user))), user...)
+}
+
+// userKey returns the users index key of the document with objId created by user, ordered by creation time and id
+func userKey(user string, created int64, objId primitive.ObjectID) []byte {
+	// flipping the sign bit orders negative times before positive ones
+	key := binary.BigEndian.AppendUint64(userPrefix(user), uint64(created)^(1<<63))
+	return append(key, objId[:]...)
+}
+
+func namespace(databaseName string, collectionName string) string {
+	return databaseName + "." + collectionName
+}
@@ -0,0 +1,224 @@
+package boltdb
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/haguru/horus/crumbdb/pkg/document"
+	"github.com/haguru/horus/crumbdb/pkg/geo"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces/conformance"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	DATABASE   = "horus"
+	COLLECTION = "crumbs"
+	TIMEOUT    = time.Second
+)
+
+func newTestBoltDB(t *testing.T, path string) interfaces.Client {
+	t.Helper()
+
+	db, err := NewBoltDB(path, logger.NewMockClient(), TIMEOUT)
+	if err != nil {
+		t.Fatalf("NewBoltDB() error = %v", err)
+	}
+	t.Cleanup(func() { db.Disconnect(context.Background()) })
+	return db
+}
+
+func crumb(user string, lng, lat float64) bson.D {
+	return bson.D{
+		{Key: "location", Value: mongodb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{lng, lat}}},
+		{Key: "user", Value: user},
+	}
+}
+
+func users(docs []bson.D) []interface{} {
+	var names []interface{}
+	for _, doc := range docs {
+		user, _ := document.Lookup(doc, "user")
+		names = append(names, user)
+	}
+	return names
+}
+
+func TestBoltDB_Conformance(t *testing.T) {
+	conformance.Run(t, func(t *testing.T) interfaces.Client {
+		return newTestBoltDB(t, filepath.Join(t.TempDir(), "horus.db"))
+	})
+}
+
+func TestBoltDB_SpaitalQuery(t *testing.T) {
+	db := newTestBoltDB(t, filepath.Join(t.TempDir(), "horus.db"))
+	ctx := context.Background()
+	if err := db.CreateSpatialIndex(ctx, DATABASE, COLLECTION, mongodb.SPATIAL_INDEX_TYPE); err != nil {
+		t.Fatalf("CreateSpatialIndex() error = %v", err)
+	}
+	for _, c := range []bson.D{
+		crumb("here", -122.4, 37.8),
+		crumb("paris", 2.35, 48.85),
+		crumb("west of the antimeridian", 179.9995, 0),
+		crumb("east of the antimeridian", -179.9995, 0),
+		crumb("north pole", 45, 89.9999),
+		crumb("across the pole", -135, 89.9999),
+		{{Key: "user", Value: "nowhere"}},
+	} {
+		if _, err := db.InsertRecord(ctx, DATABASE, COLLECTION, c); err != nil {
+			t.Fatalf("InsertRecord() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		pointType   string
+		coordinates []float64
+		maxDistance int
+		want        []interface{}
+	}{
+		{
+			name:        "across the antimeridian",
+			pointType:   mongodb.POINT_TYPE_POINT,
+			coordinates: []float64{180, 0},
+			maxDistance: 100,
+			want:        []interface{}{"west of the antimeridian", "east of the antimeridian"},
+		},
+		{
+			name:        "around a pole",
+			pointType:   mongodb.POINT_TYPE_POINT,
+			coordinates: []float64{45, 89.9999},
+			maxDistance: 100,
+			want:        []interface{}{"north pole", "across the pole"},
+		},
+		{
+			name:        "zero max distance is not limited",
+			pointType:   mongodb.POINT_TYPE_POINT,
+			coordinates: []float64{2.35, 48.85},
+			want: []interface{}{"paris", "north pole", "across the pole", "here", "west of the antimeridian",
+				"east of the antimeridian"},
+		},
+		{
+			name:        "polygon larger than the finest cells",
+			pointType:   mongodb.POINT_TYPE_POLYGON,
+			coordinates: []float64{-130, 30, 10, 30, 10, 60, -130, 60},
+			want:        []interface{}{"here", "paris"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db.SetDistanceLimits(0, tt.maxDistance)
+			docs, err := db.SpaitalQuery(ctx, tt.pointType, tt.coordinates, DATABASE, COLLECTION)
+			if err != nil {
+				t.Fatalf("SpaitalQuery() error = %v", err)
+			}
+			if got := users(docs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SpaitalQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoltDB_Reopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "data", "horus.db")
+
+	db := newTestBoltDB(t, path)
+	if err := db.CreateSpatialIndex(ctx, DATABASE, COLLECTION, mongodb.SPATIAL_INDEX_TYPE); err != nil {
+		t.Fatalf("CreateSpatialIndex() error = %v", err)
+	}
+	id, err := db.InsertRecord(ctx, DATABASE, COLLECTION, crumb("here", -122.4, 37.8))
+	if err != nil {
+		t.Fatalf("InsertRecord() error = %v", err)
+	}
+	if err := db.Disconnect(ctx); err != nil {
+		t.Fatalf("Disconnect() error = %v", err)
+	}
+	if err := db.Ping(); err == nil {
+		t.Errorf("Ping() of a closed database succeeded")
+	}
+
+	db = newTestBoltDB(t, path)
+	if _, err := db.FindOne(ctx, DATABASE, COLLECTION, id); err != nil {
+		t.Errorf("FindOne() after reopening error = %v", err)
+	}
+	docs, err := db.SpaitalQuery(ctx, mongodb.POINT_TYPE_POINT, []float64{-122.4, 37.8}, DATABASE, COLLECTION)
+	if err != nil {
+		t.Fatalf("SpaitalQuery() after reopening error = %v", err)
+	}
+	if got := users(docs); !reflect.DeepEqual(got, []interface{}{"here"}) {
+		t.Errorf("SpaitalQuery() after reopening = %v, want [here]", got)
+	}
+}
+
+func TestBoltDB_MigrateUsers(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "horus.db")
+
+	// a database written before the users index existed
+	db := newTestBoltDB(t, path)
+	if _, err := db.InsertRecord(ctx, DATABASE, COLLECTION, append(crumb("here", -122.4, 37.8), bson.E{Key: mongodb.CREATED_INDEX_KEY, Value: int64(1000)})); err != nil {
+		t.Fatalf("InsertRecord() error = %v", err)
+	}
+	err := db.(*BoltDB).DB.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket([]byte(namespace(DATABASE, COLLECTION))).DeleteBucket([]byte(USERS_BUCKET))
+	})
+	if err != nil {
+		t.Fatalf("failed to delete the users index: %v", err)
+	}
+	if err := db.Disconnect(ctx); err != nil {
+		t.Fatalf("Disconnect() error = %v", err)
+	}
+
+	db = newTestBoltDB(t, path)
+	docs, err := db.FindByUser(ctx, DATABASE, COLLECTION, "here", nil, 10)
+	if err != nil {
+		t.Fatalf("FindByUser() after migrating error = %v", err)
+	}
+	if got := users(docs); !reflect.DeepEqual(got, []interface{}{"here"}) {
+		t.Errorf("FindByUser() after migrating = %v, want [here]", got)
+	}
+}
+
+func TestCover(t *testing.T) {
+	tests := []struct {
+		name      string
+		box       geo.Box
+		wantLen   int
+		precision int
+	}{
+		{name: "a few meters", box: geo.Bounds([]float64{-122.4, 37.8}, 10)[0], precision: 8},
+		{name: "a few kilometers", box: geo.Bounds([]float64{-122.4, 37.8}, 1000)[0], precision: 6},
+		{name: "the world", box: geo.Box{Min: [2]float64{-180, -90}, Max: [2]float64{180, 90}}, precision: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cells := cover(tt.box)
+			if len(cells) > MAX_COVER_CELLS {
+				t.Errorf("cover() = %v cells, want at most %v", len(cells), MAX_COVER_CELLS)
+			}
+			if len(cells[0]) != tt.precision {
+				t.Errorf("cover() precision = %v, want %v", len(cells[0]), tt.precision)
+			}
+
+			// every corner is in a cell
+			for _, corner := range [][]float64{{tt.box.Min[0], tt.box.Min[1]}, {tt.box.Max[0], tt.box.Max[1]}, {tt.box.Min[0], tt.box.Max[1]}} {
+				key := string(indexKey(corner, nil))
+				found := false
+				for _, cell := range cells {
+					found = found || len(key) >= len(cell) && key[:len(cell)] == cell
+				}
+				if !found {
+					t.Errorf("cover() = %v does not contain %v", cells, corner)
+				}
+			}
+		})
+	}
+}
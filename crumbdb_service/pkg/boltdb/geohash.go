@@ -0,0 +1,73 @@
+package boltdb
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/haguru/horus/crumbdb/pkg/geo"
+
+	"github.com/mmcloughlin/geohash"
+)
+
+const (
+	// GEOHASH_PRECISION is the number of characters of the indexed geohashes, cells of about 4 cm
+	GEOHASH_PRECISION = 12
+
+	// MAX_COVER_CELLS is the most cells scanned for a box, larger boxes are scanned at a coarser precision
+	MAX_COVER_CELLS = 16
+)
+
+// indexKey returns the geohash index key of the point of the document stored under seq. The geohash comes first
+// so the points of a cell are contiguous and the sequence makes the key unique
+func indexKey(point []float64, seq []byte) []byte {
+	return append([]byte(geohash.EncodeWithPrecision(point[1], point[0], GEOHASH_PRECISION)), seq...)
+}
+
+// indexValue returns the point stored in the geohash index, so queries only decode the documents they return
+func indexValue(point []float64) []byte {
+	value := binary.BigEndian.AppendUint64(nil, math.Float64bits(point[0]))
+	return binary.BigEndian.AppendUint64(value, math.Float64bits(point[1]))
+}
+
+func decodeIndexValue(value []byte) []float64 {
+	return []float64{
+		math.Float64frombits(binary.BigEndian.Uint64(value[:8])),
+		math.Float64frombits(binary.BigEndian.Uint64(value[8:])),
+	}
+}
+
+// cover returns the geohash prefixes of the cells covering box, at the finest precision needing no more than
+// MAX_COVER_CELLS cells. The empty prefix covers the whole index
+func cover(box geo.Box) []string {
+	for precision := uint(GEOHASH_PRECISION); precision > 0; precision-- {
+		// a geohash character holds 5 bits, alternating between longitude and latitude starting with longitude
+		bits := 5 * precision
+		lngCells := math.Exp2(float64((bits + 1) / 2))
+		latCells := math.Exp2(float64(bits / 2))
+		width := 2 * geo.MAX_LONGITUDE / lngCells
+		height := 2 * geo.MAX_LATITUDE / latCells
+
+		minX, maxX := cell(box.Min[0]+geo.MAX_LONGITUDE, width, lngCells), cell(box.Max[0]+geo.MAX_LONGITUDE, width, lngCells)
+		minY, maxY := cell(box.Min[1]+geo.MAX_LATITUDE, height, latCells), cell(box.Max[1]+geo.MAX_LATITUDE, height, latCells)
+		if (maxX-minX+1)*(maxY-minY+1) > MAX_COVER_CELLS {
+			continue
+		}
+
+		var cells []string
+		for x := minX; x <= maxX; x++ {
+			for y := minY; y <= maxY; y++ {
+				lng := (x+0.5)*width - geo.MAX_LONGITUDE
+				lat := (y+0.5)*height - geo.MAX_LATITUDE
+				cells = append(cells, geohash.EncodeWithPrecision(lat, lng, precision))
+			}
+		}
+		return cells
+	}
+
+	return []string{""}
+}
+
+// cell returns the index of the cell of size containing offset, the last cell includes the upper bound
+func cell(offset float64, size float64, cells float64) float64 {
+	return math.Min(math.Floor(offset/size), cells-1)
+}
@@ -0,0 +1,262 @@
+package document
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/haguru/horus/crumbdb/pkg/geo"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const IDFIELD = "_id"
+
+// Lookup returns the value of the top level field key of doc
+func Lookup(doc bson.D, key string) (interface{}, bool) {
+	for _, e := range doc {
+		if e.Key == key {
+			return e.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Set returns doc with the fields of items replaced or appended, like the $set of mongodb.Update
+func Set(doc bson.D, items bson.D) (bson.D, error) {
+	if _, found := Lookup(items, IDFIELD); found {
+		return nil, fmt.Errorf("performing an update on the path '_id' would modify the immutable field '_id'")
+	}
+
+	for _, item := range items {
+		if i := slices.IndexFunc(doc, func(e bson.E) bool { return e.Key == item.Key }); i >= 0 {
+			doc[i].Value = item.Value
+			continue
+		}
+		doc = append(doc, item)
+	}
+	return doc, nil
+}
+
+// Update returns doc with the fields of items set and its version incremented, like mongodb.Update. It returns
+// interfaces.ErrVersionMismatch if version is not the version of doc
+func Update(doc bson.D, version int64, items bson.D) (bson.D, error) {
+	current := Version(doc)
+	if version != current {
+		return nil, interfaces.ErrVersionMismatch
+	}
+	if _, found := Lookup(items, mongodb.VERSION_KEY); found {
+		return nil, fmt.Errorf("updating the path '%v' would create a conflict at '%v'", mongodb.VERSION_KEY, mongodb.VERSION_KEY)
+	}
+
+	doc, err := Set(doc, items)
+	if err != nil {
+		return nil, err
+	}
+	return Set(doc, bson.D{{Key: mongodb.VERSION_KEY, Value: current + 1}})
+}
+
+// Version returns the version stored under mongodb.VERSION_KEY, zero if doc has none
+func Version(doc bson.D) int64 {
+	value, _ := Lookup(doc, mongodb.VERSION_KEY)
+	return integer(value)
+}
+
+// Location returns the coordinates of the GeoJSON point stored under mongodb.SPATIAL_INDEX_KEY
+func Location(doc bson.D) ([]float64, bool) {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, false
+	}
+
+	var record struct {
+		Location *mongodb.Point `bson:"location"`
+	}
+	if err := bson.Unmarshal(data, &record); err != nil || record.Location == nil {
+		return nil, false
+	}
+	if record.Location.Type != mongodb.POINT_TYPE_POINT || !geo.Valid(record.Location.Coordinates) {
+		return nil, false
+	}
+	return record.Location.Coordinates, true
+}
+
+// Shape returns the vertices of the GeoJSON Point or LineString stored under mongodb.SPATIAL_INDEX_KEY, a
+// single vertex for a Point
+func Shape(doc bson.D) ([][]float64, bool) {
+	if point, ok := Location(doc); ok {
+		return [][]float64{point}, true
+	}
+
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, false
+	}
+
+	var record struct {
+		Location *mongodb.LineString `bson:"location"`
+	}
+	if err := bson.Unmarshal(data, &record); err != nil || record.Location == nil {
+		return nil, false
+	}
+	if record.Location.Type != mongodb.POINT_TYPE_LINE_STRING || len(record.Location.Coordinates) < 2 {
+		return nil, false
+	}
+	for _, vertex := range record.Location.Coordinates {
+		if !geo.Valid(vertex) {
+			return nil, false
+		}
+	}
+	return record.Location.Coordinates, true
+}
+
+// Area returns the closed outer ring of the GeoJSON Polygon stored under mongodb.SPATIAL_INDEX_KEY
+func Area(doc bson.D) ([][]float64, bool) {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, false
+	}
+
+	var record struct {
+		Location *mongodb.Polygon `bson:"location"`
+	}
+	if err := bson.Unmarshal(data, &record); err != nil || record.Location == nil {
+		return nil, false
+	}
+	if record.Location.Type != mongodb.POINT_TYPE_POLYGON || len(record.Location.Coordinates) == 0 || len(record.Location.Coordinates[0]) < 4 {
+		return nil, false
+	}
+	for _, vertex := range record.Location.Coordinates[0] {
+		if !geo.Valid(vertex) {
+			return nil, false
+		}
+	}
+	return record.Location.Coordinates[0], true
+}
+
+// Geometry returns the vertices of the Point or Polygon, given as flat [longitude, latitude] pairs, of a
+// SpatialIntersects query, a single vertex for a Point and the closed ring of a Polygon
+func Geometry(pointType string, coordinates []float64) ([][]float64, error) {
+	switch pointType {
+	case mongodb.POINT_TYPE_POINT:
+		if !geo.Valid(coordinates) {
+			return nil, fmt.Errorf("invalid point %v", coordinates)
+		}
+		return [][]float64{coordinates}, nil
+	case mongodb.POINT_TYPE_POLYGON:
+		ring, err := geo.Ring(coordinates)
+		if err != nil {
+			return nil, fmt.Errorf("invalid polygon: %v", err)
+		}
+		return ring, nil
+	}
+	return nil, fmt.Errorf("point type %v not supported", pointType)
+}
+
+// Intersects returns true if the location of doc, a Point, a LineString or a Polygon, touches the geometry
+// returned by Geometry, like $geoIntersects
+func Intersects(doc bson.D, geometry [][]float64) bool {
+	polygon := len(geometry) > 1
+	if area, ok := Area(doc); ok {
+		// the polygons intersect unless neither has a vertex inside the other and their edges do not cross
+		return geo.Intersects(geometry, area) || polygon && geo.InRing(area[0], geometry)
+	}
+
+	shape, ok := Shape(doc)
+	if !ok {
+		return false
+	}
+	if polygon {
+		return geo.Intersects(shape, geometry)
+	}
+
+	// a point only intersects the points and lines it is on
+	point := geometry[0]
+	if len(shape) == 1 {
+		return slices.Equal(shape[0], point)
+	}
+	for i := 1; i < len(shape); i++ {
+		if geo.SegmentsIntersect(shape[i-1], shape[i], point, point) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches returns true if the top level field key of doc equals value or is an array containing it, like the
+// equality filter of mongodb. value is compared as it would be stored, so numbers only match numbers of the
+// same BSON type
+func Matches(doc bson.D, key string, value interface{}) (bool, error) {
+	field, found := Lookup(doc, key)
+	if !found {
+		return false, nil
+	}
+	stored, err := ToDocument(bson.D{{Key: key, Value: value}})
+	if err != nil {
+		return false, err
+	}
+	want := stored[0].Value
+
+	if reflect.DeepEqual(field, want) {
+		return true, nil
+	}
+	if array, ok := field.(bson.A); ok {
+		for _, element := range array {
+			if reflect.DeepEqual(element, want) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// SameValue returns true if doc and other both have key with the same value, like two documents a unique index
+// on key rejects
+func SameValue(doc bson.D, other bson.D, key string) bool {
+	value, found := Lookup(doc, key)
+	if !found {
+		return false
+	}
+	otherValue, found := Lookup(other, key)
+	return found && reflect.DeepEqual(value, otherValue)
+}
+
+// Author returns the user and creation time stored under mongodb.USER_INDEX_KEY and mongodb.CREATED_INDEX_KEY,
+// the empty user and zero time if doc has none
+func Author(doc bson.D) (string, int64) {
+	user, _ := Lookup(doc, mongodb.USER_INDEX_KEY)
+	name, _ := user.(string)
+
+	created, _ := Lookup(doc, mongodb.CREATED_INDEX_KEY)
+	return name, integer(created)
+}
+
+// integer returns the BSON number value as an int64, zero if it is not a number
+func integer(value interface{}) int64 {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case int32:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+// ToDocument converts v to the bson.D mongodb would store for it
+func ToDocument(v interface{}) (bson.D, error) {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal document: %v", err)
+	}
+
+	var doc bson.D
+	err = bson.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal document: %v", err)
+	}
+	return doc, nil
+}
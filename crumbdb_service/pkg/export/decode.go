@@ -0,0 +1,93 @@
+package export
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// DecodeFeature returns the Feature of a GeoJSON Feature with a Point geometry, as written by the GeoJSON
+// Encoder. A third coordinate is the altitude
+func DecodeFeature(data []byte) (Feature, error) {
+	var feature struct {
+		Type     string `json:"type"`
+		Geometry *struct {
+			Type        string    `json:"type"`
+			Coordinates []float64 `json:"coordinates"`
+		} `json:"geometry"`
+		Properties map[string]interface{} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &feature); err != nil {
+		return Feature{}, fmt.Errorf("invalid geojson: %v", err)
+	}
+
+	switch {
+	case feature.Type != "Feature":
+		return Feature{}, fmt.Errorf("geojson type must be Feature, got %q", feature.Type)
+	case feature.Geometry == nil:
+		return Feature{}, fmt.Errorf("geojson feature has no geometry")
+	case feature.Geometry.Type != "Point":
+		return Feature{}, fmt.Errorf("geojson geometry must be a Point, got %q", feature.Geometry.Type)
+	}
+
+	coordinates := feature.Geometry.Coordinates
+	decoded := Feature{Properties: feature.Properties}
+	switch len(coordinates) {
+	case 2:
+	case 3:
+		decoded.Altitude = &coordinates[2]
+		coordinates = coordinates[:2]
+	default:
+		return Feature{}, fmt.Errorf("geojson point has 2 or 3 coordinates, got %v", len(coordinates))
+	}
+	decoded.Coordinates = [][]float64{coordinates}
+	return decoded, nil
+}
+
+// DecodeWaypoint returns the Feature of a GPX wpt element, as written by the GPX Encoder. The properties of its
+// extensions are strings
+func DecodeWaypoint(data []byte) (Feature, error) {
+	var waypoint struct {
+		XMLName    xml.Name
+		Lat        *float64 `xml:"lat,attr"`
+		Lon        *float64 `xml:"lon,attr"`
+		Ele        *float64 `xml:"ele"`
+		Time       string   `xml:"time"`
+		Name       string   `xml:"name"`
+		Desc       string   `xml:"desc"`
+		Properties []struct {
+			Name  string `xml:"name,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"extensions>property"`
+	}
+	if err := xml.Unmarshal(data, &waypoint); err != nil {
+		return Feature{}, fmt.Errorf("invalid gpx: %v", err)
+	}
+
+	switch {
+	case waypoint.XMLName.Local != "wpt":
+		return Feature{}, fmt.Errorf("gpx element must be a wpt, got %q", waypoint.XMLName.Local)
+	case waypoint.Lat == nil || waypoint.Lon == nil:
+		return Feature{}, fmt.Errorf("gpx waypoint requires lat and lon")
+	}
+
+	decoded := Feature{
+		Name:        waypoint.Name,
+		Description: waypoint.Desc,
+		Coordinates: [][]float64{{*waypoint.Lon, *waypoint.Lat}},
+		Altitude:    waypoint.Ele,
+		Properties:  map[string]interface{}{},
+	}
+	if waypoint.Time != "" {
+		t, err := time.Parse(time.RFC3339Nano, waypoint.Time)
+		if err != nil {
+			return Feature{}, fmt.Errorf("invalid gpx time %q", waypoint.Time)
+		}
+		decoded.Time = t
+	}
+	for _, property := range waypoint.Properties {
+		decoded.Properties[property.Name] = property.Value
+	}
+	return decoded, nil
+}
@@ -0,0 +1,88 @@
+package export
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDecodeFeature(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Feature
+		wantErr bool
+	}{
+		{
+			name: "point with properties",
+			data: `{"type":"Feature","geometry":{"type":"Point","coordinates":[2.35,48.85]},"properties":{"message":"hi","tags":["a"]}}`,
+			want: Feature{
+				Coordinates: [][]float64{{2.35, 48.85}},
+				Properties:  map[string]interface{}{"message": "hi", "tags": []interface{}{"a"}},
+			},
+		},
+		{
+			name: "point with altitude",
+			data: `{"type":"Feature","geometry":{"type":"Point","coordinates":[2.35,48.85,35.5]},"properties":null}`,
+			want: Feature{Coordinates: [][]float64{{2.35, 48.85}}, Altitude: &ALTITUDE},
+		},
+		{name: "not json", data: `{"type":`, wantErr: true},
+		{name: "feature collection", data: `{"type":"FeatureCollection","features":[]}`, wantErr: true},
+		{name: "no geometry", data: `{"type":"Feature","properties":{}}`, wantErr: true},
+		{name: "line", data: `{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],[1,1]]}}`, wantErr: true},
+		{name: "one coordinate", data: `{"type":"Feature","geometry":{"type":"Point","coordinates":[2.35]}}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeFeature([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeFeature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeFeature() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeWaypoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Feature
+		wantErr bool
+	}{
+		{
+			name: "waypoint written by the encoder",
+			data: `<wpt lat="48.85" lon="2.35"><ele>35.5</ele><time>2023-11-14T22:13:20Z</time><name>hi</name>` +
+				`<extensions><property xmlns="urn:horus:crumbdb" name="tags">a,b</property></extensions></wpt>`,
+			want: Feature{
+				Name:        "hi",
+				Time:        time.UnixMilli(1700000000000).UTC(),
+				Coordinates: [][]float64{{2.35, 48.85}},
+				Altitude:    &ALTITUDE,
+				Properties:  map[string]interface{}{"tags": "a,b"},
+			},
+		},
+		{
+			name: "waypoint in the gpx namespace",
+			data: `<wpt xmlns="http://www.topografix.com/GPX/1/1" lat="1" lon="2"><desc>legacy</desc></wpt>`,
+			want: Feature{Description: "legacy", Coordinates: [][]float64{{2, 1}}, Properties: map[string]interface{}{}},
+		},
+		{name: "not xml", data: `<wpt`, wantErr: true},
+		{name: "track", data: `<trk><name>walk</name></trk>`, wantErr: true},
+		{name: "missing lon", data: `<wpt lat="1"/>`, wantErr: true},
+		{name: "invalid time", data: `<wpt lat="1" lon="2"><time>yesterday</time></wpt>`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeWaypoint([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeWaypoint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeWaypoint() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
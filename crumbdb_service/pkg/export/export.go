@@ -0,0 +1,378 @@
+// Package export writes crumbs and trails in the formats of other mapping tools, a feature at a time so an
+// export is never held in memory as a whole, and reads crumbs back from them
+package export
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FORMAT_GEOJSON = "geojson"
+	FORMAT_GPX     = "gpx"
+	FORMAT_KML     = "kml"
+
+	CONTENT_TYPE_GEOJSON = "application/geo+json"
+	CONTENT_TYPE_GPX     = "application/gpx+xml"
+	CONTENT_TYPE_KML     = "application/vnd.google-earth.kml+xml"
+
+	GPX_NAMESPACE = "http://www.topografix.com/GPX/1/1"
+	KML_NAMESPACE = "http://www.opengis.net/kml/2.2"
+	// PROPERTIES_NAMESPACE is the namespace of the properties of GPX features, which are written as extensions
+	PROPERTIES_NAMESPACE = "urn:horus:crumbdb"
+
+	CREATOR = "horus crumbdb"
+)
+
+// Feature is a crumb, a single vertex written as a waypoint, or a trail, several vertices written as a track
+type Feature struct {
+	Name        string
+	Description string
+	// Time is left out if it is zero
+	Time time.Time
+	// Coordinates are [longitude, latitude] pairs
+	Coordinates [][]float64
+	// Altitude in meters of a single vertex, optional
+	Altitude *float64
+	// Properties are the metadata of the feature, written in the order of their names
+	Properties map[string]interface{}
+}
+
+// Encoder writes features to a document started by NewEncoder
+type Encoder interface {
+	Encode(feature Feature) error
+	// Close ends the document, it does not close the writer
+	Close() error
+}
+
+// NewEncoder starts a document of format on w and returns the Encoder of its features
+func NewEncoder(format string, w io.Writer) (Encoder, error) {
+	switch format {
+	case FORMAT_GEOJSON:
+		return newGeoJSONEncoder(w)
+	case FORMAT_GPX:
+		return newGPXEncoder(w)
+	case FORMAT_KML:
+		return newKMLEncoder(w)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// ContentType returns the media type of the documents of format
+func ContentType(format string) string {
+	switch format {
+	case FORMAT_GEOJSON:
+		return CONTENT_TYPE_GEOJSON
+	case FORMAT_GPX:
+		return CONTENT_TYPE_GPX
+	case FORMAT_KML:
+		return CONTENT_TYPE_KML
+	default:
+		return ""
+	}
+}
+
+type geoJSONEncoder struct {
+	w        io.Writer
+	features int
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+func newGeoJSONEncoder(w io.Writer) (*geoJSONEncoder, error) {
+	if _, err := io.WriteString(w, `{"type":"FeatureCollection","features":[`); err != nil {
+		return nil, fmt.Errorf("failed to write feature collection: %v", err)
+	}
+	return &geoJSONEncoder{w: w}, nil
+}
+
+func (e *geoJSONEncoder) Encode(feature Feature) error {
+	if err := validate(feature); err != nil {
+		return err
+	}
+
+	geometry := geoJSONGeometry{Type: "LineString", Coordinates: feature.Coordinates}
+	if len(feature.Coordinates) == 1 {
+		position := slices.Clone(feature.Coordinates[0])
+		if feature.Altitude != nil {
+			position = append(position, *feature.Altitude)
+		}
+		geometry = geoJSONGeometry{Type: "Point", Coordinates: position}
+	}
+	properties := feature.Properties
+	if properties == nil {
+		properties = map[string]interface{}{}
+	}
+
+	data, err := json.Marshal(geoJSONFeature{Type: "Feature", Geometry: geometry, Properties: properties})
+	if err != nil {
+		return fmt.Errorf("failed to marshal feature: %v", err)
+	}
+	separator := "\n"
+	if e.features > 0 {
+		separator = ",\n"
+	}
+	if _, err := io.WriteString(e.w, separator); err != nil {
+		return fmt.Errorf("failed to write feature: %v", err)
+	}
+	if _, err := e.w.Write(data); err != nil {
+		return fmt.Errorf("failed to write feature: %v", err)
+	}
+	e.features++
+	return nil
+}
+
+func (e *geoJSONEncoder) Close() error {
+	if _, err := io.WriteString(e.w, "\n]}\n"); err != nil {
+		return fmt.Errorf("failed to end feature collection: %v", err)
+	}
+	return nil
+}
+
+// gpxEncoder writes crumbs as waypoints and trails as tracks. GPX lists the waypoints of a document before its
+// tracks, so a crumb encoded after a trail is an error
+type gpxEncoder struct {
+	enc    *xml.Encoder
+	tracks bool
+}
+
+type gpxWaypoint struct {
+	XMLName    xml.Name       `xml:"wpt"`
+	Lat        float64        `xml:"lat,attr"`
+	Lon        float64        `xml:"lon,attr"`
+	Ele        *float64       `xml:"ele,omitempty"`
+	Time       string         `xml:"time,omitempty"`
+	Name       string         `xml:"name,omitempty"`
+	Desc       string         `xml:"desc,omitempty"`
+	Extensions *gpxExtensions `xml:"extensions,omitempty"`
+}
+
+type gpxTrack struct {
+	XMLName    xml.Name       `xml:"trk"`
+	Name       string         `xml:"name,omitempty"`
+	Desc       string         `xml:"desc,omitempty"`
+	Extensions *gpxExtensions `xml:"extensions,omitempty"`
+	Points     []gpxPoint     `xml:"trkseg>trkpt"`
+}
+
+type gpxPoint struct {
+	Lat float64 `xml:"lat,attr"`
+	Lon float64 `xml:"lon,attr"`
+}
+
+type gpxExtensions struct {
+	Properties []gpxProperty `xml:"urn:horus:crumbdb property"`
+}
+
+type gpxProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+func newGPXEncoder(w io.Writer) (*gpxEncoder, error) {
+	e := &gpxEncoder{enc: xml.NewEncoder(w)}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return nil, fmt.Errorf("failed to write gpx header: %v", err)
+	}
+	err := e.enc.EncodeToken(xml.StartElement{
+		Name: xml.Name{Local: "gpx"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "version"}, Value: "1.1"},
+			{Name: xml.Name{Local: "creator"}, Value: CREATOR},
+			{Name: xml.Name{Local: "xmlns"}, Value: GPX_NAMESPACE},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to write gpx header: %v", err)
+	}
+	return e, nil
+}
+
+func (e *gpxEncoder) Encode(feature Feature) error {
+	if err := validate(feature); err != nil {
+		return err
+	}
+
+	var extensions *gpxExtensions
+	if len(feature.Properties) > 0 {
+		extensions = &gpxExtensions{}
+		for _, name := range names(feature.Properties) {
+			extensions.Properties = append(extensions.Properties, gpxProperty{Name: name, Value: format(feature.Properties[name])})
+		}
+	}
+
+	var element interface{}
+	if len(feature.Coordinates) == 1 {
+		if e.tracks {
+			return fmt.Errorf("gpx waypoints must be encoded before tracks")
+		}
+		waypoint := gpxWaypoint{
+			Lat:        feature.Coordinates[0][1],
+			Lon:        feature.Coordinates[0][0],
+			Ele:        feature.Altitude,
+			Name:       feature.Name,
+			Desc:       feature.Description,
+			Extensions: extensions,
+		}
+		if !feature.Time.IsZero() {
+			waypoint.Time = feature.Time.UTC().Format(time.RFC3339Nano)
+		}
+		element = waypoint
+	} else {
+		e.tracks = true
+		track := gpxTrack{Name: feature.Name, Desc: feature.Description, Extensions: extensions}
+		for _, vertex := range feature.Coordinates {
+			track.Points = append(track.Points, gpxPoint{Lat: vertex[1], Lon: vertex[0]})
+		}
+		element = track
+	}
+
+	if err := e.enc.Encode(element); err != nil {
+		return fmt.Errorf("failed to write feature: %v", err)
+	}
+	return nil
+}
+
+func (e *gpxEncoder) Close() error {
+	if err := e.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "gpx"}}); err != nil {
+		return fmt.Errorf("failed to end gpx: %v", err)
+	}
+	return e.enc.Flush()
+}
+
+type kmlEncoder struct {
+	enc *xml.Encoder
+}
+
+type kmlPlacemark struct {
+	XMLName     xml.Name     `xml:"Placemark"`
+	Name        string       `xml:"name,omitempty"`
+	Description string       `xml:"description,omitempty"`
+	When        string       `xml:"TimeStamp>when,omitempty"`
+	Data        []kmlData    `xml:"ExtendedData>Data,omitempty"`
+	Point       *kmlGeometry `xml:"Point,omitempty"`
+	LineString  *kmlGeometry `xml:"LineString,omitempty"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlGeometry struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+func newKMLEncoder(w io.Writer) (*kmlEncoder, error) {
+	e := &kmlEncoder{enc: xml.NewEncoder(w)}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return nil, fmt.Errorf("failed to write kml header: %v", err)
+	}
+	for _, start := range []xml.StartElement{
+		{Name: xml.Name{Local: "kml"}, Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: KML_NAMESPACE}}},
+		{Name: xml.Name{Local: "Document"}},
+	} {
+		if err := e.enc.EncodeToken(start); err != nil {
+			return nil, fmt.Errorf("failed to write kml header: %v", err)
+		}
+	}
+	return e, nil
+}
+
+func (e *kmlEncoder) Encode(feature Feature) error {
+	if err := validate(feature); err != nil {
+		return err
+	}
+
+	placemark := kmlPlacemark{Name: feature.Name, Description: feature.Description}
+	if !feature.Time.IsZero() {
+		placemark.When = feature.Time.UTC().Format(time.RFC3339Nano)
+	}
+	for _, name := range names(feature.Properties) {
+		placemark.Data = append(placemark.Data, kmlData{Name: name, Value: format(feature.Properties[name])})
+	}
+
+	// KML coordinates are longitude,latitude[,altitude] tuples separated by spaces
+	tuples := make([]string, 0, len(feature.Coordinates))
+	for _, vertex := range feature.Coordinates {
+		tuples = append(tuples, formatFloat(vertex[0])+","+formatFloat(vertex[1]))
+	}
+	if len(feature.Coordinates) == 1 {
+		if feature.Altitude != nil {
+			tuples[0] += "," + formatFloat(*feature.Altitude)
+		}
+		placemark.Point = &kmlGeometry{Coordinates: tuples[0]}
+	} else {
+		placemark.LineString = &kmlGeometry{Coordinates: strings.Join(tuples, " ")}
+	}
+
+	if err := e.enc.Encode(placemark); err != nil {
+		return fmt.Errorf("failed to write feature: %v", err)
+	}
+	return nil
+}
+
+func (e *kmlEncoder) Close() error {
+	for _, name := range []string{"Document", "kml"} {
+		if err := e.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}}); err != nil {
+			return fmt.Errorf("failed to end kml: %v", err)
+		}
+	}
+	return e.enc.Flush()
+}
+
+// validate returns an error if feature has no vertices or a vertex is not a [longitude, latitude] pair
+func validate(feature Feature) error {
+	if len(feature.Coordinates) == 0 {
+		return fmt.Errorf("feature %q has no coordinates", feature.Name)
+	}
+	for _, vertex := range feature.Coordinates {
+		if len(vertex) != 2 {
+			return fmt.Errorf("feature %q has a vertex of %v coordinates, want [longitude, latitude]", feature.Name, len(vertex))
+		}
+	}
+	return nil
+}
+
+func names(properties map[string]interface{}) []string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// format returns the text of a property value, lists of strings are comma separated
+func format(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ",")
+	case float64:
+		return formatFloat(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
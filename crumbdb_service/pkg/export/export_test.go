@@ -0,0 +1,215 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	ALTITUDE = 35.5
+
+	CRUMB = Feature{
+		Name:        "hello <world> & co",
+		Time:        time.UnixMilli(1700000000000),
+		Coordinates: [][]float64{{2.35, 48.85}},
+		Altitude:    &ALTITUDE,
+		Properties:  map[string]interface{}{"id": "c1", "tags": []string{"paris", "seine"}, "version": int64(2)},
+	}
+	TRAIL = Feature{
+		Name:        "walk",
+		Coordinates: [][]float64{{2.35, 48.85}, {2.36, 48.86}},
+		Properties:  map[string]interface{}{"id": "t1", "distance": 1332.5},
+	}
+)
+
+func encode(t *testing.T, format string, features ...Feature) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	enc, err := NewEncoder(format, &buf)
+	if err != nil {
+		t.Fatalf("NewEncoder() error = %v", err)
+	}
+	for _, feature := range features {
+		if err := enc.Encode(feature); err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestGeoJSON(t *testing.T) {
+	var collection struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"features"`
+	}
+
+	tests := []struct {
+		name     string
+		features []Feature
+		want     []string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:     "crumb and trail",
+			features: []Feature{CRUMB, TRAIL},
+			want:     []string{"Point", "LineString"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := encode(t, FORMAT_GEOJSON, tt.features...)
+			if err := json.Unmarshal([]byte(out), &collection); err != nil {
+				t.Fatalf("invalid json %q: %v", out, err)
+			}
+			if collection.Type != "FeatureCollection" || len(collection.Features) != len(tt.want) {
+				t.Fatalf("collection = %+v, want %v features", collection, len(tt.want))
+			}
+			for i, feature := range collection.Features {
+				if feature.Geometry.Type != tt.want[i] {
+					t.Errorf("feature %v geometry = %v, want %v", i, feature.Geometry.Type, tt.want[i])
+				}
+			}
+		})
+	}
+
+	if got := string(collection.Features[0].Geometry.Coordinates); got != "[2.35,48.85,35.5]" {
+		t.Errorf("point coordinates = %v, want the altitude third", got)
+	}
+	if got := collection.Features[0].Properties["id"]; got != "c1" {
+		t.Errorf("properties = %v, want id c1", collection.Features[0].Properties)
+	}
+}
+
+func TestGPX(t *testing.T) {
+	out := encode(t, FORMAT_GPX, CRUMB, TRAIL)
+
+	var gpx struct {
+		XMLName   xml.Name `xml:"http://www.topografix.com/GPX/1/1 gpx"`
+		Waypoints []struct {
+			Lat        float64 `xml:"lat,attr"`
+			Lon        float64 `xml:"lon,attr"`
+			Ele        float64 `xml:"ele"`
+			Time       string  `xml:"time"`
+			Name       string  `xml:"name"`
+			Properties []struct {
+				Name  string `xml:"name,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"extensions>property"`
+		} `xml:"wpt"`
+		Tracks []struct {
+			Points []struct {
+				Lat float64 `xml:"lat,attr"`
+			} `xml:"trkseg>trkpt"`
+		} `xml:"trk"`
+	}
+	if err := xml.Unmarshal([]byte(out), &gpx); err != nil {
+		t.Fatalf("invalid xml %q: %v", out, err)
+	}
+
+	if len(gpx.Waypoints) != 1 || len(gpx.Tracks) != 1 {
+		t.Fatalf("gpx = %+v, want a waypoint and a track", gpx)
+	}
+	waypoint := gpx.Waypoints[0]
+	if waypoint.Lat != 48.85 || waypoint.Lon != 2.35 || waypoint.Ele != ALTITUDE || waypoint.Name != CRUMB.Name {
+		t.Errorf("waypoint = %+v, want %+v", waypoint, CRUMB)
+	}
+	if waypoint.Time != "2023-11-14T22:13:20Z" {
+		t.Errorf("time = %v, want 2023-11-14T22:13:20Z", waypoint.Time)
+	}
+	if len(waypoint.Properties) != 3 || waypoint.Properties[1].Name != "tags" || waypoint.Properties[1].Value != "paris,seine" {
+		t.Errorf("properties = %+v, want id, tags and version", waypoint.Properties)
+	}
+	if points := gpx.Tracks[0].Points; len(points) != 2 || points[1].Lat != 48.86 {
+		t.Errorf("track points = %+v, want the vertices of the trail", points)
+	}
+}
+
+func TestGPX_WaypointAfterTrack(t *testing.T) {
+	enc, err := NewEncoder(FORMAT_GPX, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("NewEncoder() error = %v", err)
+	}
+	if err := enc.Encode(TRAIL); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if err := enc.Encode(CRUMB); err == nil {
+		t.Errorf("Encode() of a waypoint after a track succeeded, want an error")
+	}
+}
+
+func TestKML(t *testing.T) {
+	out := encode(t, FORMAT_KML, CRUMB, TRAIL)
+
+	var kml struct {
+		XMLName    xml.Name `xml:"http://www.opengis.net/kml/2.2 kml"`
+		Placemarks []struct {
+			Name  string `xml:"name"`
+			When  string `xml:"TimeStamp>when"`
+			Point string `xml:"Point>coordinates"`
+			Line  string `xml:"LineString>coordinates"`
+			Data  []struct {
+				Name  string `xml:"name,attr"`
+				Value string `xml:"value"`
+			} `xml:"ExtendedData>Data"`
+		} `xml:"Document>Placemark"`
+	}
+	if err := xml.Unmarshal([]byte(out), &kml); err != nil {
+		t.Fatalf("invalid xml %q: %v", out, err)
+	}
+
+	if len(kml.Placemarks) != 2 {
+		t.Fatalf("kml = %+v, want 2 placemarks", kml)
+	}
+	if crumb := kml.Placemarks[0]; crumb.Point != "2.35,48.85,35.5" || crumb.Name != CRUMB.Name || crumb.When == "" || len(crumb.Data) != 3 {
+		t.Errorf("crumb placemark = %+v, want %+v", crumb, CRUMB)
+	}
+	if trail := kml.Placemarks[1]; trail.Line != "2.35,48.85 2.36,48.86" || trail.When != "" {
+		t.Errorf("trail placemark = %+v, want %+v", trail, TRAIL)
+	}
+}
+
+func TestNewEncoder(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		feature Feature
+		wantErr bool
+	}{
+		{name: "unsupported format", format: "shp", wantErr: true},
+		{name: "no coordinates", format: FORMAT_GEOJSON, feature: Feature{Name: "empty"}, wantErr: true},
+		{name: "vertex of 3 coordinates", format: FORMAT_KML, feature: Feature{Coordinates: [][]float64{{1, 2, 3}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, err := NewEncoder(tt.format, &bytes.Buffer{})
+			if err == nil {
+				err = enc.Encode(tt.feature)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	for _, format := range []string{FORMAT_GEOJSON, FORMAT_GPX, FORMAT_KML} {
+		if !strings.Contains(ContentType(format), format[:3]) {
+			t.Errorf("ContentType(%v) = %v", format, ContentType(format))
+		}
+	}
+}
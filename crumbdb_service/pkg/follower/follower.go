@@ -0,0 +1,197 @@
+package follower
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/haguru/horus/crumbdb/config"
+	pb "github.com/haguru/horus/crumbdb/internal/follower/protos"
+	"github.com/haguru/horus/crumbdb/pkg/security"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"google.golang.org/grpc"
+)
+
+const (
+	// DEFAULT_CACHE_TTL is how long an answer of follower_service is reused when no cache ttl is configured
+	DEFAULT_CACHE_TTL = 30 * time.Second
+
+	// DEFAULT_TIMEOUT bounds a call to follower_service when no timeout is configured
+	DEFAULT_TIMEOUT = 2 * time.Second
+
+	// MAX_BATCH_SIZE is the most users asked about in one GetFollowed call, the limit of follower_service
+	MAX_BATCH_SIZE = 1000
+
+	// MAX_CACHE_ENTRIES bounds the cache. A full cache drops its expired entries, then all of them
+	MAX_CACHE_ENTRIES = 100000
+)
+
+// SERVICE_CONFIG retries GetFollowed while follower_service is unavailable
+const SERVICE_CONFIG = `{
+	"methodConfig": [{
+		"name": [{"service": "followerdb.FollowerDB", "method": "GetFollowed"}],
+		"retryPolicy": {
+			"maxAttempts": 3,
+			"initialBackoff": "0.05s",
+			"maxBackoff": "0.2s",
+			"backoffMultiplier": 2,
+			"retryableStatusCodes": ["UNAVAILABLE"]
+		}
+	}]
+}`
+
+// Client tells which users a follower follows, asking follower_service once per batch of users and caching
+// the answers
+type Client struct {
+	conn    *grpc.ClientConn
+	api     pb.FollowerDBClient
+	ttl     time.Duration
+	timeout time.Duration
+	lc      logger.LoggingClient
+
+	mu    sync.Mutex
+	cache map[follow]answer
+	now   func() time.Time
+}
+
+// follow is a cache key, follower following user
+type follow struct {
+	follower string
+	user     string
+}
+
+type answer struct {
+	follows bool
+	expires time.Time
+}
+
+// NewClient returns a Client of the FollowerDB API served on conn, caching answers for ttl
+func NewClient(conn grpc.ClientConnInterface, ttl time.Duration, timeout time.Duration, lc logger.LoggingClient) *Client {
+	return &Client{
+		api:     pb.NewFollowerDBClient(conn),
+		ttl:     ttl,
+		timeout: timeout,
+		lc:      lc,
+		cache:   map[follow]answer{},
+		now:     time.Now,
+	}
+}
+
+// Dial returns a Client connected to the follower_service at followerConfig.Address, with TLS if enabled
+func Dial(followerConfig *config.Follower, lc logger.LoggingClient) (*Client, error) {
+	ttl := DEFAULT_CACHE_TTL
+	if followerConfig.CacheTTL != "" {
+		var err error
+		ttl, err = time.ParseDuration(followerConfig.CacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse follower cache ttl: %v", err)
+		}
+	}
+	timeout := DEFAULT_TIMEOUT
+	if followerConfig.Timeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(followerConfig.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse follower timeout: %v", err)
+		}
+	}
+
+	creds, err := security.ClientCredentials(&followerConfig.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create follower service credentials: %v", err)
+	}
+
+	conn, err := grpc.NewClient(followerConfig.Address,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultServiceConfig(SERVICE_CONFIG),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to follower service: %v", err)
+	}
+
+	client := NewClient(conn, ttl, timeout, lc)
+	client.conn = conn
+	return client, nil
+}
+
+// Close closes the connection opened by Dial
+func (c *Client) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// Follows returns which of users are followed by follower. Cached answers are reused and the other users are
+// asked of follower_service in batches of MAX_BATCH_SIZE
+func (c *Client) Follows(ctx context.Context, follower string, users []string) (map[string]bool, error) {
+	follows := make(map[string]bool, len(users))
+	var missing []string
+
+	c.mu.Lock()
+	now := c.now()
+	for _, user := range users {
+		if _, seen := follows[user]; seen {
+			continue
+		}
+		cached, ok := c.cache[follow{follower: follower, user: user}]
+		if ok && now.Before(cached.expires) {
+			follows[user] = cached.follows
+			continue
+		}
+		follows[user] = false
+		missing = append(missing, user)
+	}
+	c.mu.Unlock()
+
+	for start := 0; start < len(missing); start += MAX_BATCH_SIZE {
+		batch := missing[start:min(start+MAX_BATCH_SIZE, len(missing))]
+		followed, err := c.getFollowed(ctx, follower, batch)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range followed {
+			follows[user] = true
+		}
+		c.store(follower, batch, follows)
+	}
+
+	return follows, nil
+}
+
+func (c *Client) getFollowed(ctx context.Context, follower string, users []string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	followed, err := c.api.GetFollowed(ctx, &pb.FollowedRequest{FollowerId: follower, Ids: users})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the users followed by %v: %v", follower, err)
+	}
+	return followed.GetValues(), nil
+}
+
+// store caches the answers of follows for the users of a batch
+func (c *Client) store(follower string, users []string, follows map[string]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if len(c.cache)+len(users) > MAX_CACHE_ENTRIES {
+		for key, cached := range c.cache {
+			if !now.Before(cached.expires) {
+				delete(c.cache, key)
+			}
+		}
+	}
+	if len(c.cache)+len(users) > MAX_CACHE_ENTRIES {
+		c.lc.Debug("follower cache is full, dropping all entries", "entries", len(c.cache))
+		c.cache = map[follow]answer{}
+	}
+
+	expires := now.Add(c.ttl)
+	for _, user := range users {
+		c.cache[follow{follower: follower, user: user}] = answer{follows: follows[user], expires: expires}
+	}
+}
@@ -0,0 +1,140 @@
+package follower
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/haguru/horus/crumbdb/internal/follower/protos"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// followerServer answers GetFollowed from follows, a set of follower and user pairs, and records the batches
+type followerServer struct {
+	pb.UnimplementedFollowerDBServer
+	mu      sync.Mutex
+	follows map[[2]string]bool
+	batches [][]string
+	err     error
+}
+
+func (s *followerServer) GetFollowed(_ context.Context, req *pb.FollowedRequest) (*pb.Ids, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.batches = append(s.batches, req.GetIds())
+	if s.err != nil {
+		return nil, s.err
+	}
+	followed := &pb.Ids{}
+	for _, id := range req.GetIds() {
+		if s.follows[[2]string{req.GetFollowerId(), id}] {
+			followed.Values = append(followed.Values, id)
+		}
+	}
+	return followed, nil
+}
+
+func newTestClient(t *testing.T, server *followerServer) *Client {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	pb.RegisterFollowerDBServer(s, server)
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return NewClient(conn, time.Minute, time.Second, logger.NewMockClient())
+}
+
+func TestClient_Follows(t *testing.T) {
+	server := &followerServer{follows: map[[2]string]bool{{"me", "alice"}: true, {"me", "carol"}: true, {"bob", "dave"}: true}}
+	c := newTestClient(t, server)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	got, err := c.Follows(context.Background(), "me", []string{"alice", "bob", "alice", "carol"})
+	if err != nil {
+		t.Fatalf("Client.Follows() error = %v", err)
+	}
+	if want := map[string]bool{"alice": true, "bob": false, "carol": true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Client.Follows() = %v, want %v", got, want)
+	}
+
+	// the cached answers are reused and only dave is asked about
+	got, err = c.Follows(context.Background(), "me", []string{"bob", "dave", "carol"})
+	if err != nil {
+		t.Fatalf("Client.Follows() error = %v", err)
+	}
+	if want := map[string]bool{"bob": false, "carol": true, "dave": false}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Client.Follows() = %v, want %v", got, want)
+	}
+
+	// answers expire after the ttl
+	now = now.Add(time.Minute)
+	if _, err := c.Follows(context.Background(), "me", []string{"alice"}); err != nil {
+		t.Fatalf("Client.Follows() error = %v", err)
+	}
+
+	want := [][]string{{"alice", "bob", "carol"}, {"dave"}, {"alice"}}
+	if !reflect.DeepEqual(server.batches, want) {
+		t.Errorf("batches = %v, want %v", server.batches, want)
+	}
+}
+
+func TestClient_Follows_Batches(t *testing.T) {
+	server := &followerServer{follows: map[[2]string]bool{{"me", "user 0"}: true, {"me", fmt.Sprint("user ", MAX_BATCH_SIZE)}: true}}
+	c := newTestClient(t, server)
+
+	users := make([]string, MAX_BATCH_SIZE+1)
+	for i := range users {
+		users[i] = fmt.Sprint("user ", i)
+	}
+	got, err := c.Follows(context.Background(), "me", users)
+	if err != nil {
+		t.Fatalf("Client.Follows() error = %v", err)
+	}
+	if len(server.batches) != 2 || len(server.batches[0]) != MAX_BATCH_SIZE || len(server.batches[1]) != 1 {
+		t.Errorf("batches of %v users, want %v and 1", len(server.batches[0]), MAX_BATCH_SIZE)
+	}
+	if !got["user 0"] || !got[users[MAX_BATCH_SIZE]] || got["user 1"] {
+		t.Errorf("Client.Follows() = %v, want user 0 and %v followed", got, users[MAX_BATCH_SIZE])
+	}
+}
+
+func TestClient_Follows_Error(t *testing.T) {
+	server := &followerServer{err: fmt.Errorf("failed")}
+	c := newTestClient(t, server)
+
+	if _, err := c.Follows(context.Background(), "me", []string{"alice"}); err == nil {
+		t.Fatal("Client.Follows() error = nil, want the error of follower_service")
+	}
+
+	// failures are not cached
+	server.err = nil
+	if _, err := c.Follows(context.Background(), "me", []string{"alice"}); err != nil {
+		t.Fatalf("Client.Follows() error = %v", err)
+	}
+	if len(server.batches) != 2 {
+		t.Errorf("GetFollowed called %v times, want 2", len(server.batches))
+	}
+}
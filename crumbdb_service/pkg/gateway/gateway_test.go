@@ -0,0 +1,232 @@
+package gateway
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	"github.com/haguru/horus/crumbdb/pkg/security"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+type crumbServer struct {
+	pb.UnimplementedCrumbDBServer
+
+	mu sync.Mutex
+	md metadata.MD
+}
+
+func (s *crumbServer) Create(ctx context.Context, crumb *pb.Crumb) (*pb.Id, error) {
+	s.mu.Lock()
+	s.md, _ = metadata.FromIncomingContext(ctx)
+	s.mu.Unlock()
+
+	if crumb.GetUser() == "flooder" {
+		st, _ := status.New(codes.ResourceExhausted, "rate limit exceeded").
+			WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(1500 * time.Millisecond)})
+		return nil, st.Err()
+	}
+	return &pb.Id{Value: "42"}, nil
+}
+
+func (s *crumbServer) GetCrumbs(point *pb.Point, stream grpc.ServerStreamingServer[pb.Crumb]) error {
+	for _, id := range []string{"1", "2"} {
+		if err := stream.Send(&pb.Crumb{Id: id, Location: point}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newTestHandler(t *testing.T, crumbs *crumbServer) http.Handler {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	pb.RegisterCrumbDBServer(server, crumbs)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	handler, err := NewHandler(context.Background(), conn)
+	if err != nil {
+		t.Fatalf("NewHandler() error = %v", err)
+	}
+	return handler
+}
+
+func TestNewHandler(t *testing.T) {
+	handler := newTestHandler(t, &crumbServer{})
+
+	tests := []struct {
+		name            string
+		method          string
+		path            string
+		body            string
+		accept          string
+		wantStatus      int
+		wantContentType string
+		wantBody        []string
+		wantRetryAfter  string
+	}{
+		{
+			name:            "unary",
+			method:          http.MethodPost,
+			path:            "/v1/crumbs",
+			body:            `{"location":{"type":"Point","coordinates":[-122.4,37.8]},"user":"user_1","message":"hi"}`,
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        []string{`{"value":"42"}`},
+		},
+		{
+			name:            "rate limited",
+			method:          http.MethodPost,
+			path:            "/v1/crumbs",
+			body:            `{"user":"flooder"}`,
+			wantStatus:      http.StatusTooManyRequests,
+			wantContentType: "application/json",
+			wantRetryAfter:  "2",
+		},
+		{
+			name:            "stream as newline delimited json",
+			method:          http.MethodGet,
+			path:            "/v1/crumbs?type=Point&coordinates=-122.4&coordinates=37.8",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        []string{`{"result":{"id":"1",`, "}}\n{\"result\":{\"id\":\"2\","},
+		},
+		{
+			name:            "stream with ndjson content type",
+			method:          http.MethodGet,
+			path:            "/v1/crumbs?type=Point&coordinates=-122.4&coordinates=37.8",
+			accept:          MIME_NDJSON,
+			wantStatus:      http.StatusOK,
+			wantContentType: MIME_NDJSON,
+			wantBody:        []string{`{"result":{"id":"1",`},
+		},
+		{
+			name:            "stream as server-sent events",
+			method:          http.MethodGet,
+			path:            "/v1/crumbs?type=Point&coordinates=-122.4&coordinates=37.8",
+			accept:          MIME_SSE,
+			wantStatus:      http.StatusOK,
+			wantContentType: MIME_SSE,
+			wantBody:        []string{`data: {"result":{"id":"1",`, "}}\n\ndata: {\"result\":{\"id\":\"2\","},
+		},
+		{
+			name:            "openapi document",
+			method:          http.MethodGet,
+			path:            OPENAPI_ENDPOINT,
+			wantStatus:      http.StatusOK,
+			wantContentType: MIME_OPENAPI,
+			wantBody:        []string{"openapi: 3.0.3", "/v1/crumbs/{crumb.id}:"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %v, want %v: %v", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Content-Type = %v, want %v", got, tt.wantContentType)
+			}
+			if got := rec.Header().Get(HEADER_RETRY_AFTER); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("body %q does not contain %q", rec.Body.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewHandler_Identity(t *testing.T) {
+	crumbs := &crumbServer{}
+	handler := newTestHandler(t, crumbs)
+	alice := &x509.Certificate{Subject: pkix.Name{CommonName: "alice"}}
+
+	tests := []struct {
+		name        string
+		tls         *tls.ConnectionState
+		header      map[string]string
+		wantSubject []string
+	}{
+		{
+			name:        "verified client certificate",
+			tls:         &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{alice}}},
+			wantSubject: []string{"alice"},
+		},
+		{
+			name:        "unverified client certificate",
+			tls:         &tls.ConnectionState{PeerCertificates: []*x509.Certificate{alice}},
+			wantSubject: nil,
+		},
+		{
+			name:        "subject set by the client",
+			header:      map[string]string{"Grpc-Metadata-" + security.METADATA_SUBJECT: "alice"},
+			wantSubject: nil,
+		},
+		{
+			name:        "subject set by a client with a certificate",
+			tls:         &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{alice}}},
+			header:      map[string]string{"Grpc-Metadata-X-Horus-Subject": "bob"},
+			wantSubject: []string{"alice"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/crumbs", strings.NewReader(`{"user":"user_1"}`))
+			req.TLS = tt.tls
+			for key, value := range tt.header {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %v, want %v: %v", rec.Code, http.StatusOK, rec.Body.String())
+			}
+
+			crumbs.mu.Lock()
+			defer crumbs.mu.Unlock()
+			got := crumbs.md.Get(security.METADATA_SUBJECT)
+			if strings.Join(got, ",") != strings.Join(tt.wantSubject, ",") {
+				t.Errorf("forwarded subject = %v, want %v", got, tt.wantSubject)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+const (
+	// EARTH_RADIUS is the mean radius of the earth in meters
+	EARTH_RADIUS = 6371008.8
+
+	MAX_LONGITUDE = 180
+	MAX_LATITUDE  = 90
+)
+
+// Box is a [longitude, latitude] bounding box
+type Box struct {
+	Min [2]float64
+	Max [2]float64
+}
+
+// Distance returns the great circle distance in meters between two [longitude, latitude] coordinates
+func Distance(a, b []float64) float64 {
+	lat1 := a[1] * math.Pi / 180
+	lat2 := b[1] * math.Pi / 180
+	dLat := lat2 - lat1
+	dLng := (b[0] - a[0]) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EARTH_RADIUS * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Bounds returns the boxes covering every coordinate within radius meters of center. A circle crossing the
+// antimeridian is split in two boxes, one on each side
+func Bounds(center []float64, radius float64) []Box {
+	dLat := radius / EARTH_RADIUS * 180 / math.Pi
+	minLat := math.Max(center[1]-dLat, -MAX_LATITUDE)
+	maxLat := math.Min(center[1]+dLat, MAX_LATITUDE)
+
+	// the circle contains a pole, every longitude is in range
+	if minLat == -MAX_LATITUDE || maxLat == MAX_LATITUDE {
+		return []Box{{Min: [2]float64{-MAX_LONGITUDE, minLat}, Max: [2]float64{MAX_LONGITUDE, maxLat}}}
+	}
+
+	// widest longitude span of the circle, reached at the latitude furthest from the equator
+	dLng := math.Asin(math.Min(1, math.Sin(radius/EARTH_RADIUS)/math.Cos(center[1]*math.Pi/180))) * 180 / math.Pi
+	minLng := center[0] - dLng
+	maxLng := center[0] + dLng
+
+	switch {
+	case dLng >= MAX_LONGITUDE:
+		return []Box{{Min: [2]float64{-MAX_LONGITUDE, minLat}, Max: [2]float64{MAX_LONGITUDE, maxLat}}}
+	case minLng < -MAX_LONGITUDE:
+		return []Box{
+			{Min: [2]float64{-MAX_LONGITUDE, minLat}, Max: [2]float64{maxLng, maxLat}},
+			{Min: [2]float64{minLng + 2*MAX_LONGITUDE, minLat}, Max: [2]float64{MAX_LONGITUDE, maxLat}},
+		}
+	case maxLng > MAX_LONGITUDE:
+		return []Box{
+			{Min: [2]float64{minLng, minLat}, Max: [2]float64{MAX_LONGITUDE, maxLat}},
+			{Min: [2]float64{-MAX_LONGITUDE, minLat}, Max: [2]float64{maxLng - 2*MAX_LONGITUDE, maxLat}},
+		}
+	}
+
+	return []Box{{Min: [2]float64{minLng, minLat}, Max: [2]float64{maxLng, maxLat}}}
+}
+
+// Contains returns true if the box contains the [longitude, latitude] coordinates
+func (b Box) Contains(coordinates []float64) bool {
+	return coordinates[0] >= b.Min[0] && coordinates[0] <= b.Max[0] && coordinates[1] >= b.Min[1] && coordinates[1] <= b.Max[1]
+}
+
+// Valid returns true if coordinates is a [longitude, latitude] pair within range
+func Valid(coordinates []float64) bool {
+	return len(coordinates) == 2 && math.Abs(coordinates[0]) <= MAX_LONGITUDE && math.Abs(coordinates[1]) <= MAX_LATITUDE
+}
+
+// Ring returns the closed ring of the polygon whose vertices are given as flat [longitude, latitude] pairs,
+// e.g. [lng1, lat1, lng2, lat2, lng3, lat3]. The ring may already be closed
+func Ring(coordinates []float64) ([][]float64, error) {
+	if len(coordinates)%2 != 0 {
+		return nil, fmt.Errorf("odd number of coordinates %v", len(coordinates))
+	}
+
+	var ring [][]float64
+	for i := 0; i < len(coordinates); i += 2 {
+		vertex := []float64{coordinates[i], coordinates[i+1]}
+		if !Valid(vertex) {
+			return nil, fmt.Errorf("invalid vertex %v", vertex)
+		}
+		ring = append(ring, vertex)
+	}
+	if len(ring) > 0 && !slices.Equal(ring[0], ring[len(ring)-1]) {
+		ring = append(ring, ring[0])
+	}
+
+	// a closed ring repeats its first vertex
+	if len(ring) < 4 {
+		return nil, fmt.Errorf("a polygon needs at least 3 vertices")
+	}
+	return ring, nil
+}
+
+// InRing returns true if the [longitude, latitude] coordinates are inside the closed ring. Edges are straight
+// lines in longitude and latitude, which is close to the spherical edges of mongodb for polygons of a few
+// kilometers
+func InRing(coordinates []float64, ring [][]float64) bool {
+	inside := false
+	x, y := coordinates[0], coordinates[1]
+	for i := 1; i < len(ring); i++ {
+		a, b := ring[i-1], ring[i]
+		if (a[1] > y) != (b[1] > y) && x < (b[0]-a[0])*(y-a[1])/(b[1]-a[1])+a[0] {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Intersects returns true if the shape, a point or the vertices of a line, touches or is inside the closed ring
+func Intersects(shape [][]float64, ring [][]float64) bool {
+	for _, vertex := range shape {
+		if InRing(vertex, ring) {
+			return true
+		}
+	}
+	for i := 1; i < len(shape); i++ {
+		for j := 1; j < len(ring); j++ {
+			if SegmentsIntersect(shape[i-1], shape[i], ring[j-1], ring[j]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SegmentsIntersect returns true if the segments pq and rs touch or cross
+func SegmentsIntersect(p, q, r, s []float64) bool {
+	d1 := orientation(r, s, p)
+	d2 := orientation(r, s, q)
+	d3 := orientation(p, q, r)
+	d4 := orientation(p, q, s)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	return (d1 == 0 && onSegment(r, s, p)) || (d2 == 0 && onSegment(r, s, q)) ||
+		(d3 == 0 && onSegment(p, q, r)) || (d4 == 0 && onSegment(p, q, s))
+}
+
+// orientation returns the cross product of ab and ac, positive if c is left of ab
+func orientation(a, b, c []float64) float64 {
+	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
+
+// onSegment returns true if c, collinear with ab, is between a and b
+func onSegment(a, b, c []float64) bool {
+	return math.Min(a[0], b[0]) <= c[0] && c[0] <= math.Max(a[0], b[0]) &&
+		math.Min(a[1], b[1]) <= c[1] && c[1] <= math.Max(a[1], b[1])
+}
+
+// Circle returns the counterclockwise vertices, as flat [longitude, latitude] pairs, of the polygon inscribed in
+// the circle of radius meters around center. Vertices past the antimeridian or a pole are clamped, so the
+// circle should stay clear of them
+func Circle(center []float64, radius float64, vertices int) []float64 {
+	lat1 := center[1] * math.Pi / 180
+	lng1 := center[0] * math.Pi / 180
+	angular := radius / EARTH_RADIUS
+
+	coordinates := make([]float64, 0, 2*vertices)
+	for i := 0; i < vertices; i++ {
+		// bearings clockwise from north, walked backwards for a counterclockwise ring
+		bearing := -2 * math.Pi * float64(i) / float64(vertices)
+		lat2 := math.Asin(math.Sin(lat1)*math.Cos(angular) + math.Cos(lat1)*math.Sin(angular)*math.Cos(bearing))
+		lng2 := lng1 + math.Atan2(math.Sin(bearing)*math.Sin(angular)*math.Cos(lat1), math.Cos(angular)-math.Sin(lat1)*math.Sin(lat2))
+		coordinates = append(coordinates,
+			math.Max(-MAX_LONGITUDE, math.Min(MAX_LONGITUDE, lng2*180/math.Pi)),
+			math.Max(-MAX_LATITUDE, math.Min(MAX_LATITUDE, lat2*180/math.Pi)))
+	}
+	return coordinates
+}
+
+// Length returns the great circle length in meters of the line through the [longitude, latitude] vertices
+func Length(line [][]float64) float64 {
+	var length float64
+	for i := 1; i < len(line); i++ {
+		length += Distance(line[i-1], line[i])
+	}
+	return length
+}
+
+// RingBounds returns the box covering the ring
+func RingBounds(ring [][]float64) Box {
+	box := Box{Min: [2]float64{MAX_LONGITUDE, MAX_LATITUDE}, Max: [2]float64{-MAX_LONGITUDE, -MAX_LATITUDE}}
+	for _, vertex := range ring {
+		box.Min[0] = math.Min(box.Min[0], vertex[0])
+		box.Min[1] = math.Min(box.Min[1], vertex[1])
+		box.Max[0] = math.Max(box.Max[0], vertex[0])
+		box.Max[1] = math.Max(box.Max[1], vertex[1])
+	}
+	return box
+}
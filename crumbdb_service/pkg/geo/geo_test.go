@@ -0,0 +1,217 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	here := []float64{-122.4, 37.8}
+	there := []float64{-122.4, 37.81}
+
+	tests := []struct {
+		name string
+		a    []float64
+		b    []float64
+		want float64
+	}{
+		{name: "same point", a: here, b: here, want: 0},
+		{name: "one hundredth of a degree of latitude", a: here, b: there, want: 1112},
+		{name: "across the antimeridian", a: []float64{179.99, 0}, b: []float64{-179.99, 0}, want: 2224},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Distance(tt.a, tt.b); math.Abs(got-tt.want) > 1 {
+				t.Errorf("Distance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBounds(t *testing.T) {
+	tests := []struct {
+		name      string
+		center    []float64
+		radius    float64
+		wantBoxes int
+		inside    [][]float64
+		outside   [][]float64
+	}{
+		{
+			name:      "one box",
+			center:    []float64{-122.4, 37.8},
+			radius:    1000,
+			wantBoxes: 1,
+			inside:    [][]float64{{-122.4, 37.8}, {-122.4, 37.808}, {-122.41, 37.8}},
+			outside:   [][]float64{{-122.4, 37.81}, {-122.42, 37.8}},
+		},
+		{
+			name:      "across the antimeridian",
+			center:    []float64{179.999, 0},
+			radius:    1000,
+			wantBoxes: 2,
+			inside:    [][]float64{{179.995, 0}, {-179.996, 0}},
+			outside:   [][]float64{{-179.98, 0}, {179.98, 0}},
+		},
+		{
+			name:      "around a pole",
+			center:    []float64{0, 89.999},
+			radius:    1000,
+			wantBoxes: 1,
+			inside:    [][]float64{{180, 89.999}, {-90, 89.995}},
+			outside:   [][]float64{{0, 89.98}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			boxes := Bounds(tt.center, tt.radius)
+			if len(boxes) != tt.wantBoxes {
+				t.Fatalf("Bounds() returned %v boxes, want %v", len(boxes), tt.wantBoxes)
+			}
+
+			contains := func(coordinates []float64) bool {
+				for _, box := range boxes {
+					if box.Contains(coordinates) {
+						return true
+					}
+				}
+				return false
+			}
+			for _, coordinates := range tt.inside {
+				if Distance(tt.center, coordinates) > tt.radius {
+					t.Fatalf("%v is not within the radius", coordinates)
+				}
+				if !contains(coordinates) {
+					t.Errorf("Bounds() = %v does not contain %v", boxes, coordinates)
+				}
+			}
+			for _, coordinates := range tt.outside {
+				if contains(coordinates) {
+					t.Errorf("Bounds() = %v contains %v", boxes, coordinates)
+				}
+			}
+		})
+	}
+}
+
+func TestRing(t *testing.T) {
+	tests := []struct {
+		name        string
+		coordinates []float64
+		wantLen     int
+		wantErr     bool
+	}{
+		{name: "open ring is closed", coordinates: []float64{0, 0, 1, 0, 1, 1}, wantLen: 4},
+		{name: "closed ring", coordinates: []float64{0, 0, 1, 0, 1, 1, 0, 0}, wantLen: 4},
+		{name: "odd number of coordinates", coordinates: []float64{0, 0, 1, 0, 1}, wantErr: true},
+		{name: "two vertices", coordinates: []float64{0, 0, 1, 0, 0, 0}, wantErr: true},
+		{name: "invalid vertex", coordinates: []float64{0, 0, 1, 0, 1, 91}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring, err := Ring(tt.coordinates)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Ring() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(ring) != tt.wantLen {
+				t.Errorf("Ring() = %v, want %v vertices", ring, tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestInRing(t *testing.T) {
+	// a U shape opening to the north
+	ring, err := Ring([]float64{0, 0, 3, 0, 3, 3, 2, 3, 2, 1, 1, 1, 1, 3, 0, 3})
+	if err != nil {
+		t.Fatalf("Ring() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		coordinates []float64
+		want        bool
+	}{
+		{name: "base", coordinates: []float64{1.5, 0.5}, want: true},
+		{name: "left arm", coordinates: []float64{0.5, 2.5}, want: true},
+		{name: "right arm", coordinates: []float64{2.5, 2.5}, want: true},
+		{name: "between the arms", coordinates: []float64{1.5, 2}, want: false},
+		{name: "outside the bounds", coordinates: []float64{4, 1}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InRing(tt.coordinates, ring); got != tt.want {
+				t.Errorf("InRing() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if box := RingBounds(ring); box.Min != [2]float64{0, 0} || box.Max != [2]float64{3, 3} {
+		t.Errorf("RingBounds() = %v, want [0 0] to [3 3]", box)
+	}
+}
+
+func TestIntersects(t *testing.T) {
+	// the same U shape opening to the north
+	ring, err := Ring([]float64{0, 0, 3, 0, 3, 3, 2, 3, 2, 1, 1, 1, 1, 3, 0, 3})
+	if err != nil {
+		t.Fatalf("Ring() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		shape [][]float64
+		want  bool
+	}{
+		{name: "point inside", shape: [][]float64{{1.5, 0.5}}, want: true},
+		{name: "point between the arms", shape: [][]float64{{1.5, 2}}, want: false},
+		{name: "line with a vertex inside", shape: [][]float64{{1.5, 2}, {1.5, 0.5}}, want: true},
+		{name: "line crossing the arms", shape: [][]float64{{-1, 2}, {4, 2}}, want: true},
+		{name: "line between the arms", shape: [][]float64{{1.5, 1.5}, {1.5, 4}}, want: false},
+		{name: "line touching an edge", shape: [][]float64{{4, 0}, {3, 1}}, want: true},
+		{name: "line outside", shape: [][]float64{{4, 0}, {4, 4}}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Intersects(tt.shape, ring); got != tt.want {
+				t.Errorf("Intersects() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCircle(t *testing.T) {
+	center := []float64{-122.4, 37.8}
+	coordinates := Circle(center, 1000, 32)
+	ring, err := Ring(coordinates)
+	if err != nil {
+		t.Fatalf("Ring() error = %v", err)
+	}
+	if len(ring) != 33 {
+		t.Fatalf("Circle() returned %v vertices, want 32", len(ring)-1)
+	}
+
+	var area float64
+	for i := 1; i < len(ring); i++ {
+		if d := Distance(center, ring[i]); math.Abs(d-1000) > 1 {
+			t.Errorf("vertex %v is %v meters from the center, want 1000", ring[i], d)
+		}
+		area += ring[i-1][0]*ring[i][1] - ring[i][0]*ring[i-1][1]
+	}
+	if area <= 0 {
+		t.Errorf("Circle() is clockwise")
+	}
+	if !InRing(center, ring) {
+		t.Errorf("Circle() does not contain its center")
+	}
+}
+
+func TestLength(t *testing.T) {
+	line := [][]float64{{-122.4, 37.8}, {-122.4, 37.81}, {-122.4, 37.8}}
+	if got := Length(line); math.Abs(got-2224) > 2 {
+		t.Errorf("Length() = %v, want 2224", got)
+	}
+	if got := Length(line[:1]); got != 0 {
+		t.Errorf("Length() of a point = %v, want 0", got)
+	}
+}
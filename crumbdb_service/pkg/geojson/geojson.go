@@ -0,0 +1,188 @@
+// Package geojson validates the GeoJSON geometries received by the API before they reach the database, so a
+// malformed geometry is rejected with the fields at fault rather than by the spatial index
+package geojson
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/haguru/horus/crumbdb/pkg/geo"
+)
+
+const (
+	TYPE_POINT   = "Point"
+	TYPE_POLYGON = "Polygon"
+
+	// MAX_VERTICES is the most vertices of a polygon, which bounds the cost of the self-intersection check
+	MAX_VERTICES = 1000
+)
+
+// Geometry is a geometry as the API receives it. The vertices of a Polygon are given as flat
+// [longitude, latitude] pairs and its ring is closed if it is not already, see geo.Ring
+type Geometry struct {
+	Type        string
+	Coordinates []float64
+	// Altitude, in meters, and Accuracy, the radius in meters of the horizontal uncertainty, are optional
+	Altitude *float64
+	Accuracy *float64
+}
+
+// FieldError is a field of a geometry breaking a rule
+type FieldError struct {
+	Field       string
+	Description string
+}
+
+// Errors are the field errors of an invalid geometry
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldError := range e {
+		messages = append(messages, fmt.Sprintf("%v: %v", fieldError.Field, fieldError.Description))
+	}
+	return "invalid geometry: " + strings.Join(messages, "; ")
+}
+
+// Validate returns the Errors of geometry, with fields named under field, if it is not a valid geometry of
+// one of types. Returns nil if it is valid
+func Validate(field string, geometry Geometry, types ...string) error {
+	v := &validation{field: field}
+
+	switch {
+	case geometry.Type == "":
+		v.add("type", "is required")
+	case !slices.Contains(types, geometry.Type):
+		v.add("type", fmt.Sprintf("must be one of %v, got %q", strings.Join(types, ", "), geometry.Type))
+	case geometry.Type == TYPE_POINT:
+		v.point(geometry.Coordinates)
+	case geometry.Type == TYPE_POLYGON:
+		v.polygon(geometry.Coordinates)
+	}
+
+	if geometry.Altitude != nil && !finite(*geometry.Altitude) {
+		v.add("altitude", "must be a finite number")
+	}
+	if geometry.Accuracy != nil && (!finite(*geometry.Accuracy) || *geometry.Accuracy < 0) {
+		v.add("accuracy", "must be a finite number of meters, not negative")
+	}
+
+	if len(v.errors) > 0 {
+		return v.errors
+	}
+	return nil
+}
+
+type validation struct {
+	field  string
+	errors Errors
+}
+
+func (v *validation) add(field string, description string) {
+	if v.field != "" {
+		field = v.field + "." + field
+	}
+	v.errors = append(v.errors, FieldError{Field: field, Description: description})
+}
+
+func (v *validation) point(coordinates []float64) {
+	if len(coordinates) != 2 {
+		v.add("coordinates", fmt.Sprintf("a point has 2 coordinates, [longitude, latitude], got %v", len(coordinates)))
+		return
+	}
+	v.position(0, coordinates)
+}
+
+// position adds the errors of the [longitude, latitude] pair at index i of the coordinates
+func (v *validation) position(i int, coordinates []float64) bool {
+	lng, lat := coordinates[0], coordinates[1]
+	valid := true
+	if !finite(lng) || math.Abs(lng) > geo.MAX_LONGITUDE {
+		v.add(fmt.Sprintf("coordinates[%v]", i), fmt.Sprintf("longitude must be between -%v and %v, got %v", geo.MAX_LONGITUDE, geo.MAX_LONGITUDE, lng))
+		valid = false
+	}
+	if !finite(lat) || math.Abs(lat) > geo.MAX_LATITUDE {
+		description := fmt.Sprintf("latitude must be between -%v and %v, got %v", geo.MAX_LATITUDE, geo.MAX_LATITUDE, lat)
+		if math.Abs(lng) <= geo.MAX_LATITUDE {
+			description += ", the coordinates may be [latitude, longitude] instead of [longitude, latitude]"
+		}
+		v.add(fmt.Sprintf("coordinates[%v]", i+1), description)
+		valid = false
+	}
+	return valid
+}
+
+func (v *validation) polygon(coordinates []float64) {
+	if len(coordinates)%2 != 0 {
+		v.add("coordinates", fmt.Sprintf("a polygon has [longitude, latitude] pairs of coordinates, got %v coordinates", len(coordinates)))
+		return
+	}
+	if len(coordinates)/2 > MAX_VERTICES {
+		v.add("coordinates", fmt.Sprintf("a polygon has at most %v vertices, got %v", MAX_VERTICES, len(coordinates)/2))
+		return
+	}
+
+	valid := true
+	for i := 0; i < len(coordinates); i += 2 {
+		valid = v.position(i, coordinates[i:i+2]) && valid
+	}
+	if !valid {
+		return
+	}
+
+	ring, err := geo.Ring(coordinates)
+	if err != nil {
+		v.add("coordinates", err.Error())
+		return
+	}
+	for i := 1; i < len(ring); i++ {
+		if slices.Equal(ring[i-1], ring[i]) {
+			v.add("coordinates", fmt.Sprintf("the ring repeats vertex %v", ring[i]))
+			return
+		}
+	}
+
+	switch area := signedArea(ring); {
+	case area == 0:
+		v.add("coordinates", "the ring has no area")
+		return
+	case area < 0:
+		v.add("coordinates", "the ring is clockwise, the vertices of a polygon are counterclockwise")
+	}
+	if a, b, found := selfIntersection(ring); found {
+		v.add("coordinates", fmt.Sprintf("the ring intersects itself, edges from %v and %v cross", ring[a], ring[b]))
+	}
+}
+
+// signedArea returns twice the area of the closed ring, positive if it is counterclockwise
+func signedArea(ring [][]float64) float64 {
+	var area float64
+	for i := 1; i < len(ring); i++ {
+		area += ring[i-1][0]*ring[i][1] - ring[i][0]*ring[i-1][1]
+	}
+	return area
+}
+
+// selfIntersection returns the first vertices of two edges of the closed ring which are not adjacent and
+// touch or cross
+func selfIntersection(ring [][]float64) (int, int, bool) {
+	edges := len(ring) - 1
+	for i := 0; i < edges; i++ {
+		for j := i + 2; j < edges; j++ {
+			// the first and last edges share the closing vertex
+			if i == 0 && j == edges-1 {
+				continue
+			}
+			if geo.SegmentsIntersect(ring[i], ring[i+1], ring[j], ring[j+1]) {
+				return i, j, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func finite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
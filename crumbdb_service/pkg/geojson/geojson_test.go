@@ -0,0 +1,70 @@
+package geojson
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	negative := -5.0
+	nan := math.NaN()
+	altitude := 52.5
+
+	// a square around San Francisco, counterclockwise
+	square := []float64{-122.5, 37.7, -122.3, 37.7, -122.3, 37.9, -122.5, 37.9}
+
+	tests := []struct {
+		name     string
+		field    string
+		geometry Geometry
+		types    []string
+		want     []string
+	}{
+		{name: "point", geometry: Geometry{Type: TYPE_POINT, Coordinates: []float64{-122.4, 37.8}}, types: []string{TYPE_POINT}},
+		{name: "point with altitude and accuracy", geometry: Geometry{Type: TYPE_POINT, Coordinates: []float64{-122.4, 37.8}, Altitude: &altitude, Accuracy: &altitude}, types: []string{TYPE_POINT}},
+		{name: "missing type", geometry: Geometry{Coordinates: []float64{-122.4, 37.8}}, types: []string{TYPE_POINT}, want: []string{"type"}},
+		{name: "type not allowed", geometry: Geometry{Type: "NotAPoint", Coordinates: []float64{-122.4, 37.8}}, types: []string{TYPE_POINT}, want: []string{"type"}},
+		{name: "polygon where only points are allowed", geometry: Geometry{Type: TYPE_POLYGON, Coordinates: square}, types: []string{TYPE_POINT}, want: []string{"type"}},
+		{name: "three coordinates", geometry: Geometry{Type: TYPE_POINT, Coordinates: []float64{-122.4, 37.8, 10}}, types: []string{TYPE_POINT}, want: []string{"coordinates"}},
+		{name: "no coordinates", geometry: Geometry{Type: TYPE_POINT}, types: []string{TYPE_POINT}, want: []string{"coordinates"}},
+		{name: "longitude out of range", geometry: Geometry{Type: TYPE_POINT, Coordinates: []float64{-182.4, 37.8}}, types: []string{TYPE_POINT}, want: []string{"coordinates[0]"}},
+		{name: "swapped coordinates", geometry: Geometry{Type: TYPE_POINT, Coordinates: []float64{37.8, -122.4}}, types: []string{TYPE_POINT}, want: []string{"coordinates[1]"}},
+		{name: "not a number", geometry: Geometry{Type: TYPE_POINT, Coordinates: []float64{nan, nan}}, types: []string{TYPE_POINT}, want: []string{"coordinates[0]", "coordinates[1]"}},
+		{name: "negative accuracy", geometry: Geometry{Type: TYPE_POINT, Coordinates: []float64{-122.4, 37.8}, Accuracy: &negative}, types: []string{TYPE_POINT}, want: []string{"accuracy"}},
+		{name: "infinite altitude", geometry: Geometry{Type: TYPE_POINT, Coordinates: []float64{-122.4, 37.8}, Altitude: ptr(math.Inf(1))}, types: []string{TYPE_POINT}, want: []string{"altitude"}},
+		{name: "fields under a parent", field: "location", geometry: Geometry{Type: TYPE_POINT, Coordinates: []float64{-122.4, 97.8}}, types: []string{TYPE_POINT}, want: []string{"location.coordinates[1]"}},
+		{name: "polygon", geometry: Geometry{Type: TYPE_POLYGON, Coordinates: square}, types: []string{TYPE_POINT, TYPE_POLYGON}},
+		{name: "closed polygon", geometry: Geometry{Type: TYPE_POLYGON, Coordinates: append(square, -122.5, 37.7)}, types: []string{TYPE_POLYGON}},
+		{name: "odd number of coordinates", geometry: Geometry{Type: TYPE_POLYGON, Coordinates: square[:7]}, types: []string{TYPE_POLYGON}, want: []string{"coordinates"}},
+		{name: "two vertices", geometry: Geometry{Type: TYPE_POLYGON, Coordinates: square[:4]}, types: []string{TYPE_POLYGON}, want: []string{"coordinates"}},
+		{name: "too many vertices", geometry: Geometry{Type: TYPE_POLYGON, Coordinates: make([]float64, 2*MAX_VERTICES+2)}, types: []string{TYPE_POLYGON}, want: []string{"coordinates"}},
+		{name: "vertex out of range", geometry: Geometry{Type: TYPE_POLYGON, Coordinates: []float64{-122.5, 37.7, -122.3, 37.7, -122.3, 97.9}}, types: []string{TYPE_POLYGON}, want: []string{"coordinates[5]"}},
+		{name: "repeated vertex", geometry: Geometry{Type: TYPE_POLYGON, Coordinates: []float64{-122.5, 37.7, -122.3, 37.7, -122.3, 37.7, -122.3, 37.9}}, types: []string{TYPE_POLYGON}, want: []string{"coordinates"}},
+		{name: "collinear vertices", geometry: Geometry{Type: TYPE_POLYGON, Coordinates: []float64{-122.5, 37.7, -122.4, 37.7, -122.3, 37.7}}, types: []string{TYPE_POLYGON}, want: []string{"coordinates"}},
+		{name: "clockwise", geometry: Geometry{Type: TYPE_POLYGON, Coordinates: []float64{-122.5, 37.7, -122.5, 37.9, -122.3, 37.9, -122.3, 37.7}}, types: []string{TYPE_POLYGON}, want: []string{"coordinates"}},
+		{name: "bow tie", geometry: Geometry{Type: TYPE_POLYGON, Coordinates: []float64{-122.5, 37.7, -122.3, 37.9, -122.3, 37.7, -122.5, 37.9, -122.6, 37.8}}, types: []string{TYPE_POLYGON}, want: []string{"coordinates"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.field, tt.geometry, tt.types...)
+			var fields []string
+			var fieldErrors Errors
+			if errors.As(err, &fieldErrors) {
+				for _, fieldError := range fieldErrors {
+					fields = append(fields, fieldError.Field)
+				}
+			} else if err != nil {
+				t.Fatalf("Validate() error = %v, want Errors", err)
+			}
+			if !reflect.DeepEqual(fields, tt.want) {
+				t.Errorf("Validate() fields = %v, want %v (%v)", fields, tt.want, err)
+			}
+		})
+	}
+}
+
+func ptr(f float64) *float64 {
+	return &f
+}
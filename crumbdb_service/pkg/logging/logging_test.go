@@ -0,0 +1,170 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/haguru/horus/crumbdb/pkg/security"
+
+	"google.golang.org/grpc/peer"
+)
+
+func TestClient_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	lc := newClient(&buf, "crumbdb_service", "DEBUG", FORMAT_JSON)
+
+	lc.With("traceID", "abc").Debug("received request", "id", "42")
+
+	entry := map[string]interface{}{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("failed to decode log entry %q: %v", buf.String(), err)
+	}
+	want := map[string]interface{}{
+		"app":     "crumbdb_service",
+		"level":   "DEBUG",
+		"traceID": "abc",
+		"id":      "42",
+		"msg":     "received request",
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("log entry %v = %v, want %v", key, entry[key], value)
+		}
+	}
+	if source, _ := entry["source"].(string); !strings.HasPrefix(source, "logging_test.go:") {
+		t.Errorf("log entry source = %v, want the calling file", entry["source"])
+	}
+}
+
+func TestClient_Logfmt(t *testing.T) {
+	tests := []struct {
+		name     string
+		logLevel string
+		log      func(lc *Client)
+		want     []string
+	}{
+		{
+			name:     "formatted message keeps fields",
+			logLevel: "DEBUG",
+			log:      func(lc *Client) { lc.With("peer", "10.0.0.1:5000").Errorf("failed to update %v", "42") },
+			want:     []string{"level=ERROR", "peer=10.0.0.1:5000", `msg="failed to update 42"`},
+		},
+		{
+			name:     "odd number of fields",
+			logLevel: "DEBUG",
+			log:      func(lc *Client) { lc.Info("done", "count") },
+			want:     []string{"level=INFO", "count=", "msg=done"},
+		},
+		{
+			name:     "below log level",
+			logLevel: "INFO",
+			log:      func(lc *Client) { lc.Debug("hidden") },
+			want:     nil,
+		},
+		{
+			name:     "invalid log level defaults to info",
+			logLevel: "LOUD",
+			log:      func(lc *Client) { lc.Info("shown") },
+			want:     []string{"level=INFO", "msg=shown"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.log(newClient(&buf, "crumbdb_service", tt.logLevel, FORMAT_LOGFMT))
+
+			if tt.want == nil && buf.Len() != 0 {
+				t.Errorf("expected no output, got %q", buf.String())
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("log line %q does not contain %q", buf.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_SetLogLevel(t *testing.T) {
+	lc := newClient(&bytes.Buffer{}, "crumbdb_service", "DEBUG", FORMAT_LOGFMT)
+	child := lc.With("traceID", "abc")
+
+	if err := lc.SetLogLevel("WARN"); err != nil {
+		t.Fatalf("SetLogLevel() error = %v", err)
+	}
+	if child.LogLevel() != "WARN" {
+		t.Errorf("child LogLevel() = %v, want WARN", child.LogLevel())
+	}
+	if err := lc.SetLogLevel("LOUD"); err == nil {
+		t.Errorf("SetLogLevel() expected error for invalid level")
+	}
+}
+
+func TestClient_SetLogLevelConcurrently(t *testing.T) {
+	lc := newClient(io.Discard, "test", "DEBUG", FORMAT_LOGFMT)
+	child := lc.With("traceID", "abc")
+
+	// run with -race, the reloader sets the level while requests log
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = lc.SetLogLevel(LOG_LEVELS[i%len(LOG_LEVELS)])
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			child.Debug("received request")
+		}
+	}()
+	wg.Wait()
+
+	if err := lc.SetLogLevel("ERROR"); err != nil {
+		t.Fatalf("SetLogLevel() error = %v", err)
+	}
+	if child.enabled("WARN") || !child.enabled("ERROR") {
+		t.Errorf("child enabled WARN = %v, ERROR = %v, want false, true", child.enabled("WARN"), child.enabled("ERROR"))
+	}
+}
+
+func TestRequestFields(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want []interface{}
+	}{
+		{
+			name: "empty context",
+			ctx:  context.Background(),
+			want: []interface{}{},
+		},
+		{
+			name: "peer and spiffe identity",
+			ctx: security.ContextWithIdentity(peer.NewContext(context.Background(), &peer.Peer{Addr: addr}),
+				&security.Identity{SpiffeID: "spiffe://horus/gateway", Subject: "gateway"}),
+			want: []interface{}{"caller", "spiffe://horus/gateway", "peer", "10.0.0.1:5000"},
+		},
+		{
+			name: "identity without spiffe id",
+			ctx:  security.ContextWithIdentity(context.Background(), &security.Identity{Subject: "gateway"}),
+			want: []interface{}{"caller", "gateway"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RequestFields(tt.ctx); !reflect.DeepEqual([]interface{}(got), tt.want) {
+				t.Errorf("RequestFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
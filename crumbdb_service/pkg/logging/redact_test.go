@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"reflect"
+	"testing"
+
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  proto.Message
+		want []interface{}
+	}{
+		{
+			name: "nil message",
+			msg:  nil,
+			want: nil,
+		},
+		{
+			name: "crumb",
+			msg: &pb.Crumb{
+				Id:       "42",
+				Location: &pb.Point{Type: "Point", Coordinates: []float64{-122.41942, 37.77493}},
+				User:     "user_1",
+				Message:  "meet me at the pier",
+			},
+			want: []interface{}{
+				"id", "42",
+				"location.type", "Point",
+				"location.coordinates", "[-122.42 37.77]",
+				"user", "user_1",
+				"message", REDACTED,
+			},
+		},
+		{
+			name: "trail",
+			msg: &pb.Trail{
+				Id:       "7",
+				Path:     &pb.Point{Type: "LineString", Coordinates: []float64{-122.41942, 37.77493, -122.40863, 37.78561}},
+				Distance: 1523.4,
+				Bbox:     []float64{-122.41942, 37.77493, -122.40863, 37.78561},
+			},
+			want: []interface{}{
+				"id", "7",
+				"path.type", "LineString",
+				"path.coordinates", "[-122.42 37.77 -122.41 37.79]",
+				"distance", 1523.4,
+				"bbox", "[-122.42 37.77 -122.41 37.79]",
+			},
+		},
+		{
+			name: "unset fields are skipped",
+			msg:  &pb.Crumb{Id: "42"},
+			want: []interface{}{"id", "42"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Redact(tt.msg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Redact() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
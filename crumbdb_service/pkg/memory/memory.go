@@ -0,0 +1,584 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/haguru/horus/crumbdb/pkg/document"
+	"github.com/haguru/horus/crumbdb/pkg/geo"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/tidwall/rtree"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Memory is an in-memory implementation of interfaces.Client for tests and local development. Documents are
+// kept as bson.D so they decode like the ones returned by mongodb, and the points of collections are indexed
+// in an R-tree once CreateSpatialIndex is called
+type Memory struct {
+	collections map[string]*collection
+	lc          logger.LoggingClient
+	maxDistance int
+	minDistance int
+	mu          sync.RWMutex
+}
+
+type collection struct {
+	ids      []string
+	docs     map[string]bson.D
+	order    map[string]int
+	inserted int
+	points   map[string][]float64
+	index    *rtree.RTreeG[string]
+	indexed  bool
+	unique   []string
+}
+
+// NewMemory returns an empty in-memory database
+func NewMemory(lc logger.LoggingClient) interfaces.Client {
+	return &Memory{
+		collections: map[string]*collection{},
+		lc:          lc,
+		maxDistance: mongodb.MAX_DISTANCE,
+		minDistance: mongodb.MIN_DISTANCE,
+	}
+}
+
+// Connect does nothing, the database lives in the process
+func (db *Memory) Connect() error {
+	return nil
+}
+
+// Ping always succeeds
+func (db *Memory) Ping() error {
+	return nil
+}
+
+// Disconnect does nothing, the documents are kept until the process exits
+func (db *Memory) Disconnect(context.Context) error {
+	return nil
+}
+
+// CreateSpatialIndex enables SpaitalQuery on the collection. Only 2dsphere indexes are supported
+func (db *Memory) CreateSpatialIndex(_ context.Context, databaseName string, collectionName string, spatialType string) error {
+	if spatialType != mongodb.SPATIAL_INDEX_TYPE {
+		return fmt.Errorf("index type %v not supported", spatialType)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.collection(databaseName, collectionName).indexed = true
+	db.lc.Debugf("created %v index on %v", spatialType, namespace(databaseName, collectionName))
+	return nil
+}
+
+// CreateUserIndex does nothing, FindByUser scans the collection
+func (db *Memory) CreateUserIndex(context.Context, string, string) error {
+	return nil
+}
+
+// CreateFieldIndex does nothing, FindByField scans the collection
+func (db *Memory) CreateFieldIndex(context.Context, string, string, string) error {
+	return nil
+}
+
+// CreateUniqueIndex makes InsertRecord and Update reject the documents with the value of key of another one
+func (db *Memory) CreateUniqueIndex(_ context.Context, databaseName string, collectionName string, key string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	c := db.collection(databaseName, collectionName)
+	if !slices.Contains(c.unique, key) {
+		c.unique = append(c.unique, key)
+	}
+	return nil
+}
+
+// InsertRecord returns ID, as string, and error.
+// if error occurs an empty string is returned along with the error
+func (db *Memory) InsertRecord(_ context.Context, databaseName string, collectionName string, doc interface{}) (string, error) {
+	record, err := document.ToDocument(doc)
+	if err != nil {
+		return "", err
+	}
+
+	// like mongodb, a missing _id is generated and stored as the first field
+	var objId primitive.ObjectID
+	if value, found := document.Lookup(record, document.IDFIELD); found {
+		var ok bool
+		objId, ok = value.(primitive.ObjectID)
+		if !ok {
+			return "", fmt.Errorf("failed to get objectID")
+		}
+	} else {
+		objId = primitive.NewObjectID()
+		record = append(bson.D{{Key: document.IDFIELD, Value: objId}}, record...)
+	}
+	id := objId.Hex()
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	c := db.collection(databaseName, collectionName)
+	if _, exists := c.docs[id]; exists {
+		return "", fmt.Errorf("duplicate key error: %v", id)
+	}
+	if err := c.checkUnique(id, record); err != nil {
+		return "", err
+	}
+	c.ids = append(c.ids, id)
+	c.docs[id] = record
+	c.order[id] = c.inserted
+	c.inserted++
+	c.reindex(id)
+
+	return id, nil
+}
+
+// InsertMany inserts the documents one at a time, see interfaces.InsertEach
+func (db *Memory) InsertMany(ctx context.Context, databaseName string, collectionName string, docs []interface{}) ([]string, error) {
+	return interfaces.InsertEach(docs, func(doc interface{}) (string, error) {
+		return db.InsertRecord(ctx, databaseName, collectionName, doc)
+	})
+}
+
+// SpaitalQuery returns the documents within the distance limits of a Point, nearest first, or the documents
+// inside a Polygon in insertion order
+func (db *Memory) SpaitalQuery(_ context.Context, pointType string, coordinates []float64, databaseName string, collectionName string) ([]bson.D, error) {
+	var ring [][]float64
+	switch pointType {
+	case mongodb.POINT_TYPE_POINT:
+		if !geo.Valid(coordinates) {
+			return nil, fmt.Errorf("failed to perforom spatial query: invalid point %v", coordinates)
+		}
+	case mongodb.POINT_TYPE_POLYGON:
+		var err error
+		ring, err = geo.Ring(coordinates)
+		if err != nil {
+			return nil, fmt.Errorf("failed to perforom spatial query: invalid polygon: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("failed to perforom spatial query: point type %v not supported", pointType)
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	c, ok := db.collections[namespace(databaseName, collectionName)]
+	if !ok {
+		return nil, nil
+	}
+
+	var ids []string
+	if ring != nil {
+		ids = c.within(ring)
+	} else {
+		// like mongodb, $near needs the index while $geoWithin does not
+		if !c.indexed {
+			return nil, fmt.Errorf("unable to find index for $geoNear query")
+		}
+		ids = c.near(coordinates, float64(db.minDistance), float64(db.maxDistance))
+	}
+
+	docs := make([]bson.D, 0, len(ids))
+	for _, id := range ids {
+		doc, err := clone(c.docs[id])
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+// SpatialIntersects returns the documents whose Point, LineString or Polygon location intersects a Point or a
+// Polygon in insertion order
+func (db *Memory) SpatialIntersects(_ context.Context, databaseName string, collectionName string, pointType string, coordinates []float64) ([]bson.D, error) {
+	geometry, err := document.Geometry(pointType, coordinates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to perforom spatial query: %v", err)
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	c, ok := db.collections[namespace(databaseName, collectionName)]
+	if !ok {
+		return nil, nil
+	}
+
+	// lines and polygons are not in the point index, so the collection is scanned
+	var docs []bson.D
+	for _, id := range c.ids {
+		if !document.Intersects(c.docs[id], geometry) {
+			continue
+		}
+		doc, err := clone(c.docs[id])
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// CountIntersects returns the number of documents SpatialIntersects retrieves
+func (db *Memory) CountIntersects(ctx context.Context, databaseName string, collectionName string, pointType string, coordinates []float64) (int64, error) {
+	docs, err := db.SpatialIntersects(ctx, databaseName, collectionName, pointType, coordinates)
+	return int64(len(docs)), err
+}
+
+// SetDistanceLimits sets the distance bounds, in meters, used by SpaitalQuery
+func (db *Memory) SetDistanceLimits(minDistance int, maxDistance int) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.minDistance = minDistance
+	db.maxDistance = maxDistance
+}
+
+// FindAll retrieves all documents in the collection in insertion order
+func (db *Memory) FindAll(_ context.Context, databaseName string, collectionName string) ([]bson.D, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	c, ok := db.collections[namespace(databaseName, collectionName)]
+	if !ok {
+		return nil, nil
+	}
+
+	var results []bson.D
+	for _, id := range c.ids {
+		doc, err := clone(c.docs[id])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, doc)
+	}
+	return results, nil
+}
+
+// FindOne retrieves a document by ID. Returns mongo.ErrNoDocuments if there is none
+func (db *Memory) FindOne(_ context.Context, databaseName string, collectionName string, id string) (*bson.D, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	c, ok := db.collections[namespace(databaseName, collectionName)]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	doc, ok := c.docs[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	data, err := clone(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// FindMany retrieves the documents with the given IDs in the order of ids. IDs matching no document are skipped
+func (db *Memory) FindMany(_ context.Context, databaseName string, collectionName string, ids []string) ([]bson.D, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	c, ok := db.collections[namespace(databaseName, collectionName)]
+	if !ok {
+		return nil, nil
+	}
+
+	var results []bson.D
+	seen := map[string]bool{}
+	for _, id := range ids {
+		doc, ok := c.docs[id]
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		data, err := clone(doc)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, data)
+	}
+	return results, nil
+}
+
+// FindByField retrieves the documents whose key equals value, or is an array containing it, in insertion order
+func (db *Memory) FindByField(_ context.Context, databaseName string, collectionName string, key string, value interface{}) ([]bson.D, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	c, ok := db.collections[namespace(databaseName, collectionName)]
+	if !ok {
+		return nil, nil
+	}
+
+	var results []bson.D
+	for _, id := range c.ids {
+		matches, err := document.Matches(c.docs[id], key, value)
+		if err != nil {
+			return nil, err
+		}
+		if !matches {
+			continue
+		}
+		doc, err := clone(c.docs[id])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, doc)
+	}
+	return results, nil
+}
+
+// FindByUser retrieves at most limit documents of user, newest first, starting after the cursor if it is not nil
+func (db *Memory) FindByUser(_ context.Context, databaseName string, collectionName string, user string, after *interfaces.Cursor, limit int) ([]bson.D, error) {
+	if after != nil {
+		objId, err := primitive.ObjectIDFromHex(after.Id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor id %v", after.Id)
+		}
+		after = &interfaces.Cursor{Created: after.Created, Id: objId.Hex()}
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	c, ok := db.collections[namespace(databaseName, collectionName)]
+	if !ok {
+		return nil, nil
+	}
+
+	var matches []interfaces.Cursor
+	for _, id := range c.ids {
+		author, created := document.Author(c.docs[id])
+		position := interfaces.Cursor{Created: created, Id: id}
+		if author == user && (after == nil || before(position, *after)) {
+			matches = append(matches, position)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return before(matches[j], matches[i]) })
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	results := make([]bson.D, 0, len(matches))
+	for _, m := range matches {
+		doc, err := clone(c.docs[m.Id])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, doc)
+	}
+	return results, nil
+}
+
+// Update sets items on the document with ID and increments its version, returning the updated document.
+// The version must match the version of the document, zero matching a document without one
+func (db *Memory) Update(_ context.Context, databaseName string, collectionName string, id string, version int64, items map[string]interface{}) (*bson.D, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		// like FindOne, a malformed id matches no document
+		return nil, mongo.ErrNoDocuments
+	}
+	set, err := document.ToDocument(items)
+	if err != nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	c := db.collection(databaseName, collectionName)
+	stored, ok := c.docs[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	// the stored document is left as it was if the update is rejected
+	doc, err := clone(stored)
+	if err != nil {
+		return nil, err
+	}
+	doc, err = document.Update(doc, version, set)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkUnique(id, doc); err != nil {
+		return nil, err
+	}
+	c.docs[id] = doc
+	c.reindex(id)
+
+	data, err := clone(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// Delete removes the document with ID. Returns mongo.ErrNoDocuments if there is no document with ID
+func (db *Memory) Delete(_ context.Context, databaseName string, collectionName string, id string) error {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		// like FindOne, a malformed id matches no document
+		return mongo.ErrNoDocuments
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	c := db.collection(databaseName, collectionName)
+	if _, ok := c.docs[id]; !ok {
+		return mongo.ErrNoDocuments
+	}
+
+	c.unindex(id)
+	delete(c.docs, id)
+	delete(c.order, id)
+	c.ids = slices.DeleteFunc(c.ids, func(existing string) bool { return existing == id })
+
+	return nil
+}
+
+// collection returns the collection, creating it like mongodb does on first use. db.mu must be held for writing
+func (db *Memory) collection(databaseName string, collectionName string) *collection {
+	name := namespace(databaseName, collectionName)
+	c, ok := db.collections[name]
+	if !ok {
+		c = &collection{
+			docs:   map[string]bson.D{},
+			order:  map[string]int{},
+			points: map[string][]float64{},
+			index:  &rtree.RTreeG[string]{},
+		}
+		db.collections[name] = c
+	}
+	return c
+}
+
+// checkUnique returns ErrDuplicateKey if a document other than the one with id has the value of doc for a
+// unique key. db.mu must be held
+func (c *collection) checkUnique(id string, doc bson.D) error {
+	for _, key := range c.unique {
+		for otherId, other := range c.docs {
+			if otherId != id && document.SameValue(doc, other, key) {
+				return fmt.Errorf("%w: %v", interfaces.ErrDuplicateKey, key)
+			}
+		}
+	}
+	return nil
+}
+
+// near returns the ids of the documents between minDistance and maxDistance meters of coordinates, nearest
+// first. A zero maxDistance does not limit the query, like mongodb
+func (c *collection) near(coordinates []float64, minDistance float64, maxDistance float64) []string {
+	boxes := []geo.Box{{Min: [2]float64{-geo.MAX_LONGITUDE, -geo.MAX_LATITUDE}, Max: [2]float64{geo.MAX_LONGITUDE, geo.MAX_LATITUDE}}}
+	if maxDistance > 0 {
+		boxes = geo.Bounds(coordinates, maxDistance)
+	} else {
+		maxDistance = math.Inf(1)
+	}
+
+	type match struct {
+		id       string
+		distance float64
+	}
+	var matches []match
+	seen := map[string]bool{}
+	for _, box := range boxes {
+		c.index.Search(box.Min, box.Max, func(_, _ [2]float64, id string) bool {
+			if seen[id] {
+				return true
+			}
+			seen[id] = true
+
+			distance := geo.Distance(coordinates, c.points[id])
+			if distance >= minDistance && distance <= maxDistance {
+				matches = append(matches, match{id: id, distance: distance})
+			}
+			return true
+		})
+	}
+
+	// ties keep the insertion order
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance == matches[j].distance {
+			return c.order[matches[i].id] < c.order[matches[j].id]
+		}
+		return matches[i].distance < matches[j].distance
+	})
+
+	ids := make([]string, 0, len(matches))
+	for _, m := range matches {
+		ids = append(ids, m.id)
+	}
+	return ids
+}
+
+// within returns the ids of the documents inside ring in insertion order
+func (c *collection) within(ring [][]float64) []string {
+	box := geo.RingBounds(ring)
+
+	var ids []string
+	c.index.Search(box.Min, box.Max, func(_, _ [2]float64, id string) bool {
+		if geo.InRing(c.points[id], ring) {
+			ids = append(ids, id)
+		}
+		return true
+	})
+
+	sort.Slice(ids, func(i, j int) bool { return c.order[ids[i]] < c.order[ids[j]] })
+	return ids
+}
+
+// reindex updates the index entry of the document with id after it was inserted or updated
+func (c *collection) reindex(id string) {
+	c.unindex(id)
+
+	point, ok := document.Location(c.docs[id])
+	if !ok {
+		return
+	}
+	c.points[id] = point
+	c.index.Insert([2]float64{point[0], point[1]}, [2]float64{point[0], point[1]}, id)
+}
+
+func (c *collection) unindex(id string) {
+	point, ok := c.points[id]
+	if !ok {
+		return
+	}
+	c.index.Delete([2]float64{point[0], point[1]}, [2]float64{point[0], point[1]}, id)
+	delete(c.points, id)
+}
+
+// before reports whether a comes after b when listing newest first. Hex ids of the same length sort like the
+// ObjectIDs they encode
+func before(a interfaces.Cursor, b interfaces.Cursor) bool {
+	if a.Created == b.Created {
+		return a.Id < b.Id
+	}
+	return a.Created < b.Created
+}
+
+func namespace(databaseName string, collectionName string) string {
+	return databaseName + "." + collectionName
+}
+
+// clone returns a deep copy of doc so callers cannot modify the stored document
+func clone(doc bson.D) (bson.D, error) {
+	return document.ToDocument(doc)
+}
@@ -0,0 +1,312 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	"github.com/haguru/horus/crumbdb/pkg/document"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces/conformance"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	DATABASE   = "horus"
+	COLLECTION = "crumbs"
+)
+
+func crumb(user string, lng, lat float64) *pb.Crumb {
+	return &pb.Crumb{User: user, Message: "hi", Location: &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{lng, lat}}}
+}
+
+func users(t *testing.T, docs []bson.D) []string {
+	t.Helper()
+
+	var names []string
+	for _, doc := range docs {
+		data, err := bson.Marshal(doc)
+		if err != nil {
+			t.Fatalf("failed to marshal document: %v", err)
+		}
+		c := &pb.Crumb{}
+		if err := bson.Unmarshal(data, c); err != nil {
+			t.Fatalf("failed to unmarshal document: %v", err)
+		}
+		names = append(names, c.GetUser())
+	}
+	return names
+}
+
+func newIndexedMemory(t *testing.T, crumbs ...*pb.Crumb) (*Memory, []string) {
+	t.Helper()
+
+	db := NewMemory(logger.NewMockClient()).(*Memory)
+	if err := db.CreateSpatialIndex(context.Background(), DATABASE, COLLECTION, mongodb.SPATIAL_INDEX_TYPE); err != nil {
+		t.Fatalf("CreateSpatialIndex() error = %v", err)
+	}
+
+	var ids []string
+	for _, c := range crumbs {
+		id, err := db.InsertRecord(context.Background(), DATABASE, COLLECTION, c)
+		if err != nil {
+			t.Fatalf("InsertRecord() error = %v", err)
+		}
+		ids = append(ids, id)
+	}
+	return db, ids
+}
+
+func TestMemory_SpaitalQuery(t *testing.T) {
+	crumbs := []*pb.Crumb{
+		crumb("far", -122.4, 37.8008),
+		crumb("here", -122.4, 37.8),
+		crumb("near", -122.4, 37.8003),
+		crumb("out of range", -122.4, 37.81),
+		crumb("west of the antimeridian", 179.9995, 0),
+		crumb("east of the antimeridian", -179.9995, 0),
+	}
+
+	tests := []struct {
+		name        string
+		pointType   string
+		coordinates []float64
+		minDistance int
+		maxDistance int
+		noIndex     bool
+		want        []string
+		wantErr     bool
+	}{
+		{
+			name:        "nearest first within max distance",
+			pointType:   mongodb.POINT_TYPE_POINT,
+			coordinates: []float64{-122.4, 37.8},
+			maxDistance: 100,
+			want:        []string{"here", "near", "far"},
+		},
+		{
+			name:        "min distance",
+			pointType:   mongodb.POINT_TYPE_POINT,
+			coordinates: []float64{-122.4, 37.8},
+			minDistance: 50,
+			maxDistance: 100,
+			want:        []string{"far"},
+		},
+		{
+			name:        "zero max distance is not limited",
+			pointType:   mongodb.POINT_TYPE_POINT,
+			coordinates: []float64{-122.4, 37.8},
+			want:        []string{"here", "near", "far", "out of range", "east of the antimeridian", "west of the antimeridian"},
+		},
+		{
+			name:        "across the antimeridian",
+			pointType:   mongodb.POINT_TYPE_POINT,
+			coordinates: []float64{180, 0},
+			maxDistance: 100,
+			want:        []string{"west of the antimeridian", "east of the antimeridian"},
+		},
+		{
+			name:        "inside a polygon in insertion order",
+			pointType:   mongodb.POINT_TYPE_POLYGON,
+			coordinates: []float64{-122.401, 37.7995, -122.399, 37.7995, -122.399, 37.8005, -122.401, 37.8005},
+			want:        []string{"here", "near"},
+		},
+		{
+			name:        "polygons do not need the spatial index",
+			pointType:   mongodb.POINT_TYPE_POLYGON,
+			coordinates: []float64{-122.402, 37.7995, -122.399, 37.7995, -122.399, 37.801},
+			noIndex:     true,
+			want:        []string{"here", "near"},
+		},
+		{
+			name:        "invalid polygon",
+			pointType:   mongodb.POINT_TYPE_POLYGON,
+			coordinates: []float64{-122.4, 37.8},
+			wantErr:     true,
+		},
+		{
+			name:        "unsupported point type",
+			pointType:   mongodb.POINT_TYPE_MULTI_POLYGON,
+			coordinates: []float64{-122.4, 37.8},
+			wantErr:     true,
+		},
+		{
+			name:        "invalid point",
+			pointType:   mongodb.POINT_TYPE_POINT,
+			coordinates: []float64{-122.4, 97.8},
+			wantErr:     true,
+		},
+		{
+			name:        "missing spatial index",
+			pointType:   mongodb.POINT_TYPE_POINT,
+			coordinates: []float64{-122.4, 37.8},
+			noIndex:     true,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := newIndexedMemory(t, crumbs...)
+			if tt.noIndex {
+				db.collections[namespace(DATABASE, COLLECTION)].indexed = false
+			}
+			db.SetDistanceLimits(tt.minDistance, tt.maxDistance)
+
+			docs, err := db.SpaitalQuery(context.Background(), tt.pointType, tt.coordinates, DATABASE, COLLECTION)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SpaitalQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := users(t, docs); !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SpaitalQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemory_Update(t *testing.T) {
+	db, ids := newIndexedMemory(t, crumb("here", -122.4, 37.8))
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		id      string
+		version int64
+		items   map[string]interface{}
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "set message",
+			id:    ids[0],
+			items: map[string]interface{}{"message": "bye"},
+			want:  []string{"here"},
+		},
+		{
+			name:    "set location moves the crumb in the index",
+			id:      ids[0],
+			version: 1,
+			items:   map[string]interface{}{"location": &pb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{2.35, 48.85}}},
+			want:    nil,
+		},
+		{
+			name:    "not found",
+			id:      primitive.NewObjectID().Hex(),
+			items:   map[string]interface{}{"message": "bye"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid id",
+			id:      "42",
+			items:   map[string]interface{}{"message": "bye"},
+			wantErr: true,
+		},
+		{
+			name:    "immutable id",
+			id:      ids[0],
+			items:   map[string]interface{}{"_id": primitive.NewObjectID()},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := db.Update(ctx, DATABASE, COLLECTION, tt.id, tt.version, tt.items)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Update() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			doc, err := db.FindOne(ctx, DATABASE, COLLECTION, tt.id)
+			if err != nil {
+				t.Fatalf("FindOne() error = %v", err)
+			}
+			for key, value := range tt.items {
+				want, _ := document.ToDocument(map[string]interface{}{key: value})
+				if got, _ := document.Lookup(*doc, key); !reflect.DeepEqual(got, want[0].Value) {
+					t.Errorf("FindOne() %v = %v, want %v", key, got, want[0].Value)
+				}
+			}
+
+			docs, err := db.SpaitalQuery(ctx, mongodb.POINT_TYPE_POINT, []float64{-122.4, 37.8}, DATABASE, COLLECTION)
+			if err != nil {
+				t.Fatalf("SpaitalQuery() error = %v", err)
+			}
+			if got := users(t, docs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SpaitalQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemory_Delete(t *testing.T) {
+	db, ids := newIndexedMemory(t, crumb("here", -122.4, 37.8), crumb("near", -122.4, 37.8003))
+	ctx := context.Background()
+
+	if err := db.Delete(ctx, DATABASE, COLLECTION, ids[0]); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := db.Delete(ctx, DATABASE, COLLECTION, ids[0]); err == nil {
+		t.Errorf("Delete() of a deleted document succeeded")
+	}
+	if _, err := db.FindOne(ctx, DATABASE, COLLECTION, ids[0]); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("FindOne() error = %v, want %v", err, mongo.ErrNoDocuments)
+	}
+
+	docs, err := db.FindAll(ctx, DATABASE, COLLECTION)
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if got := users(t, docs); !reflect.DeepEqual(got, []string{"near"}) {
+		t.Errorf("FindAll() = %v, want [near]", got)
+	}
+
+	docs, err = db.SpaitalQuery(ctx, mongodb.POINT_TYPE_POINT, []float64{-122.4, 37.8}, DATABASE, COLLECTION)
+	if err != nil {
+		t.Fatalf("SpaitalQuery() error = %v", err)
+	}
+	if got := users(t, docs); !reflect.DeepEqual(got, []string{"near"}) {
+		t.Errorf("SpaitalQuery() = %v, want [near]", got)
+	}
+}
+
+func TestMemory_InsertRecord(t *testing.T) {
+	db, ids := newIndexedMemory(t, crumb("here", -122.4, 37.8))
+	ctx := context.Background()
+
+	doc, err := db.FindOne(ctx, DATABASE, COLLECTION, ids[0])
+	if err != nil {
+		t.Fatalf("FindOne() error = %v", err)
+	}
+	if (*doc)[0].Key != document.IDFIELD {
+		t.Errorf("FindOne() first field = %v, want %v", (*doc)[0].Key, document.IDFIELD)
+	}
+
+	// the returned document is a copy
+	(*doc)[1].Value = "changed"
+	again, _ := db.FindOne(ctx, DATABASE, COLLECTION, ids[0])
+	if reflect.DeepEqual(again, doc) {
+		t.Errorf("FindOne() returned the stored document")
+	}
+
+	objId, _ := primitive.ObjectIDFromHex(ids[0])
+	if _, err := db.InsertRecord(ctx, DATABASE, COLLECTION, bson.D{{Key: document.IDFIELD, Value: objId}}); err == nil {
+		t.Errorf("InsertRecord() of a duplicate id succeeded")
+	}
+	if _, err := db.InsertRecord(ctx, DATABASE, COLLECTION, bson.D{{Key: document.IDFIELD, Value: "42"}}); err == nil {
+		t.Errorf("InsertRecord() of a string id succeeded")
+	}
+}
+
+func TestMemory_Conformance(t *testing.T) {
+	conformance.Run(t, func(t *testing.T) interfaces.Client {
+		return NewMemory(logger.NewMockClient())
+	})
+}
@@ -0,0 +1,81 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appMetrics "github.com/haguru/horus/crumbdb/pkg/prometheus"
+	"github.com/haguru/horus/crumbdb/pkg/tracing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	OPERATION_COUNT        = "count"
+	OPERATION_CREATE_INDEX = "createIndexes"
+	OPERATION_DELETE       = "delete"
+	OPERATION_FIND         = "find"
+	OPERATION_INSERT       = "insert"
+	OPERATION_UPDATE       = "update"
+)
+
+// operation instruments a single mongodb command with a span, a latency observation and an error count
+type operation struct {
+	ctx        context.Context
+	span       trace.Span
+	name       string
+	collection string
+	start      time.Time
+	metrics    *appMetrics.Metrics
+}
+
+// startOperation starts a client span named after the mongodb command and the collection it runs against
+func (db *MongoDB) startOperation(ctx context.Context, name string, databaseName string, collectionName string) (context.Context, *operation) {
+	ctx, span := otel.Tracer(tracing.TRACER_NAME).Start(ctx, fmt.Sprintf("%v %v", name, collectionName),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemMongoDB,
+			semconv.DBNamespace(databaseName),
+			semconv.DBCollectionName(collectionName),
+			semconv.DBOperationName(name),
+			semconv.ServerAddress(db.Host),
+			semconv.ServerPort(db.Port),
+		),
+	)
+
+	return ctx, &operation{
+		ctx:        ctx,
+		span:       span,
+		name:       name,
+		collection: collectionName,
+		start:      time.Now(),
+		metrics:    db.metrics,
+	}
+}
+
+// Instrument starts the span, latency observation and error count of a command run by a store on a collection of
+// its own, like the commands of the client. The returned function ends them with the error of the command
+func (db *MongoDB) Instrument(ctx context.Context, name string, databaseName string, collectionName string) (context.Context, func(error)) {
+	ctx, op := db.startOperation(ctx, name, databaseName, collectionName)
+	return ctx, op.end
+}
+
+// end records the latency of the operation and err, if any, and ends the span
+func (o *operation) end(err error) {
+	if o.metrics != nil {
+		appMetrics.ObserveWithExemplar(o.ctx, o.metrics.DbOperationDuration.WithLabelValues(o.name, o.collection), time.Since(o.start).Seconds())
+		if err != nil {
+			appMetrics.IncWithExemplar(o.ctx, o.metrics.DbOperationErrors.WithLabelValues(o.name, o.collection))
+		}
+	}
+
+	if err != nil {
+		o.span.RecordError(err)
+		o.span.SetStatus(codes.Error, err.Error())
+	}
+	o.span.End()
+}
@@ -2,10 +2,53 @@ package interfaces
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"go.mongodb.org/mongo-driver/bson"
 )
 
+// ErrVersionMismatch is returned by Update when the version of the document is not the expected one
+var ErrVersionMismatch = errors.New("document version mismatch")
+
+// ErrDuplicateKey is returned by InsertRecord and Update when another document has the value of a key with a
+// unique index
+var ErrDuplicateKey = errors.New("duplicate key")
+
+// InsertManyError is returned by InsertMany when some of the documents were not inserted, Errors has the error
+// of each of them by its index in the documents
+type InsertManyError struct {
+	Errors map[int]error
+}
+
+func (e *InsertManyError) Error() string {
+	return fmt.Sprintf("failed to insert %v documents", len(e.Errors))
+}
+
+// InsertEach implements InsertMany for clients without a bulk insert, inserting the documents one at a time
+func InsertEach(docs []interface{}, insert func(doc interface{}) (string, error)) ([]string, error) {
+	ids := make([]string, len(docs))
+	failed := map[int]error{}
+	for i, doc := range docs {
+		id, err := insert(doc)
+		if err != nil {
+			failed[i] = err
+			continue
+		}
+		ids[i] = id
+	}
+	if len(failed) > 0 {
+		return ids, &InsertManyError{Errors: failed}
+	}
+	return ids, nil
+}
+
+// Cursor is the position of a document in the listing of FindByUser, which continues after it
+type Cursor struct {
+	Created int64
+	Id      string
+}
+
 type Client interface {
 	// Connect returns a mongodb client and error.
 	// If an error occurs mongodb client will be nil
@@ -13,32 +56,78 @@ type Client interface {
 
 	// CreateSpatialIndex returns error if client is unable to create a spatial index
 	// this is needed to search database by (longitude, latitude) coordinates
-	CreateSpatialIndex(databaseName string, collectionName string, spatialType string) error
+	CreateSpatialIndex(ctx context.Context, databaseName string, collectionName string, spatialType string) error
 
-	// Delete removes a document from the database. Returns nil error if successful
-	Delete(databaseName string, collectionName string, id string) error
+	// CreateFieldIndex returns error if client is unable to create the index of FindByField on key.
+	// Clients without indexes do nothing
+	CreateFieldIndex(ctx context.Context, databaseName string, collectionName string, key string) error
+
+	// CreateUniqueIndex returns error if client is unable to create an index on key rejecting, with
+	// ErrDuplicateKey, the documents which have the value of key of another document. Documents without key
+	// are not indexed
+	CreateUniqueIndex(ctx context.Context, databaseName string, collectionName string, key string) error
+
+	// CreateUserIndex returns error if client is unable to create the index of FindByUser
+	// on the user and creation time of the documents
+	CreateUserIndex(ctx context.Context, databaseName string, collectionName string) error
+
+	// CountIntersects returns the number of documents SpatialIntersects retrieves, without reading them
+	CountIntersects(ctx context.Context, databaseName string, collectionName string, pointType string, coordinates []float64) (int64, error)
+
+	// Delete removes a document from the database. Returns mongo.ErrNoDocuments if there is no document with ID
+	Delete(ctx context.Context, databaseName string, collectionName string, id string) error
 
 	// Disconnect returns error if client is unable to disconnect from mongodb
 	Disconnect(context.Context) error
 
 	// FindAll retrieves all documents in the database. Returns an array of bson.D and error.
 	// if an error occurs then a nil is return and an error
-	FindAll(databaseName string, collectionName string) ([]bson.D, error)
+	FindAll(ctx context.Context, databaseName string, collectionName string) ([]bson.D, error)
+
+	// FindByField retrieves the documents whose key equals value, or is an array containing value, in no
+	// particular order
+	FindByField(ctx context.Context, databaseName string, collectionName string, key string, value interface{}) ([]bson.D, error)
+
+	// FindByUser retrieves at most limit documents of user, newest first, starting after the cursor if it is
+	// not nil. Documents created at the same time are ordered by descending ID
+	FindByUser(ctx context.Context, databaseName string, collectionName string, user string, after *Cursor, limit int) ([]bson.D, error)
+
+	// FindMany retrieves the documents with the given IDs in no particular order. IDs matching no document are
+	// skipped
+	FindMany(ctx context.Context, databaseName string, collectionName string, ids []string) ([]bson.D, error)
 
 	// FindOne retrieves a document by ID. Returns a bson.D
-	FindOne(databaseName string, collectionName string, id string) (*bson.D, error)
+	FindOne(ctx context.Context, databaseName string, collectionName string, id string) (*bson.D, error)
 
 	// InsertRecord returns ID, as string, and error.
 	// if error occurs an empty string is returned along with the error
-	InsertRecord(databaseName string, collectionName string, doc interface{}) (string, error)
+	InsertRecord(ctx context.Context, databaseName string, collectionName string, doc interface{}) (string, error)
+
+	// InsertMany inserts the documents without stopping at the first one which fails and returns their IDs, as
+	// strings in the order of docs. If some were not inserted their IDs are empty and the error is an
+	// *InsertManyError, any other error means none were
+	InsertMany(ctx context.Context, databaseName string, collectionName string, docs []interface{}) ([]string, error)
 
 	// Ping returns error if mongodb is unreachable
 	Ping() error
 
+	// SetDistanceLimits sets the distance bounds, in meters, used by SpaitalQuery
+	SetDistanceLimits(minDistance int, maxDistance int)
+
 	// SpaitalQuery queries database for data based on coordinates. Returns array of bson.D and error
-	// if error occurs a nil is returned as well as an error
-	SpaitalQuery(pointType string, coordinates []float64, databaseName string, collectionName string) ([]bson.D, error)
+	// if error occurs a nil is returned as well as an error. A Point returns the documents within the distance
+	// limits, nearest first. A Polygon, given as flat [longitude, latitude] pairs of its vertices, returns the
+	// documents inside it
+	SpaitalQuery(ctx context.Context, pointType string, coordinates []float64, databaseName string, collectionName string) ([]bson.D, error)
+
+	// SpatialIntersects retrieves the documents whose location, a Point, a LineString or a Polygon, intersects
+	// the geometry in no particular order. The geometry is a Point or a Polygon, given as flat
+	// [longitude, latitude] pairs of its vertices
+	SpatialIntersects(ctx context.Context, databaseName string, collectionName string, pointType string, coordinates []float64) ([]bson.D, error)
 
-	// Update modifies a document given a ID. Returns a nil error when sucessful
-	Update(databaseName string, collectionName string, id string, items map[string]interface{}) error
+	// Update sets items on the document with ID and increments its version, returning the updated document.
+	// The version is compared with the version of the document first, a document without one being at version
+	// zero, and ErrVersionMismatch is returned if they differ. Returns mongo.ErrNoDocuments if there is no
+	// document with ID
+	Update(ctx context.Context, databaseName string, collectionName string, id string, version int64, items map[string]interface{}) (*bson.D, error)
 }
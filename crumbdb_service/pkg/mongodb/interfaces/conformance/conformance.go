@@ -0,0 +1,710 @@
+// Package conformance is the test suite every interfaces.Client implementation must pass, so the service
+// behaves the same whichever database.driver is configured
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haguru/horus/crumbdb/pkg/document"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const DATABASE = "horus_conformance"
+
+// Run runs the suite against the clients returned by newClient. Every test writes to its own collection, so
+// newClient may return the same database each time
+func Run(t *testing.T, newClient func(t *testing.T) interfaces.Client) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, db interfaces.Client, collection string)
+	}{
+		{name: "insert and find", test: testInsertFind},
+		{name: "inserted id", test: testInsertedId},
+		{name: "insert many", test: testInsertMany},
+		{name: "find many", test: testFindMany},
+		{name: "find by user", test: testFindByUser},
+		{name: "update", test: testUpdate},
+		{name: "update version", test: testUpdateVersion},
+		{name: "delete", test: testDelete},
+		{name: "not found", test: testNotFound},
+		{name: "near", test: testNear},
+		{name: "near without index", test: testNearWithoutIndex},
+		{name: "within polygon", test: testWithin},
+		{name: "unsupported geometry", test: testUnsupportedGeometry},
+		{name: "intersects polygon", test: testIntersects},
+		{name: "intersects point", test: testIntersectsPoint},
+		{name: "find by field", test: testFindByField},
+		{name: "unique index", test: testUniqueIndex},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newClient(t)
+			if err := db.Ping(); err != nil {
+				t.Fatalf("Ping() error = %v", err)
+			}
+			db.SetDistanceLimits(mongodb.MIN_DISTANCE, mongodb.MAX_DISTANCE)
+			tt.test(t, db, collection(t))
+		})
+	}
+}
+
+func testInsertFind(t *testing.T, db interfaces.Client, collection string) {
+	ctx := context.Background()
+	ids := insert(t, db, collection, crumb("a", -122.4, 37.8), crumb("b", -122.4, 37.8003))
+
+	docs, err := db.FindAll(ctx, DATABASE, collection)
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if got := users(docs); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("FindAll() = %v, want [a b]", got)
+	}
+
+	doc, err := db.FindOne(ctx, DATABASE, collection, ids[1])
+	if err != nil {
+		t.Fatalf("FindOne() error = %v", err)
+	}
+	if (*doc)[0].Key != document.IDFIELD {
+		t.Errorf("FindOne() first field = %v, want %v", (*doc)[0].Key, document.IDFIELD)
+	}
+	if got := users([]bson.D{*doc}); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("FindOne() = %v, want [b]", got)
+	}
+
+	for _, id := range []string{primitive.NewObjectID().Hex(), "42"} {
+		if _, err := db.FindOne(ctx, DATABASE, collection, id); !errors.Is(err, mongo.ErrNoDocuments) {
+			t.Errorf("FindOne(%v) error = %v, want %v", id, err, mongo.ErrNoDocuments)
+		}
+	}
+}
+
+// testInsertedId checks the id returned by InsertRecord is the one FindOne, Update and Delete expect
+func testInsertedId(t *testing.T, db interfaces.Client, collection string) {
+	ctx := context.Background()
+
+	id, err := db.InsertRecord(ctx, DATABASE, collection, crumb("a", -122.4, 37.8))
+	if err != nil {
+		t.Fatalf("InsertRecord() error = %v", err)
+	}
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		t.Fatalf("InsertRecord() id = %v, want a hex ObjectID", id)
+	}
+
+	doc, err := db.FindOne(ctx, DATABASE, collection, id)
+	if err != nil {
+		t.Fatalf("FindOne() error = %v", err)
+	}
+	if value, _ := document.Lookup(*doc, document.IDFIELD); value.(primitive.ObjectID).Hex() != id {
+		t.Errorf("FindOne() id = %v, want %v", value, id)
+	}
+	if _, err := db.Update(ctx, DATABASE, collection, id, 0, map[string]interface{}{"message": "bye"}); err != nil {
+		t.Errorf("Update() error = %v", err)
+	}
+	if err := db.Delete(ctx, DATABASE, collection, id); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+}
+
+func testInsertMany(t *testing.T, db interfaces.Client, collection string) {
+	ctx := context.Background()
+
+	existing := primitive.NewObjectID()
+	duplicate := append(bson.D{{Key: document.IDFIELD, Value: existing}}, authored("bob", "duplicate", 2)...)
+	if _, err := db.InsertRecord(ctx, DATABASE, collection, duplicate); err != nil {
+		t.Fatalf("InsertRecord() error = %v", err)
+	}
+
+	// the documents after the one which fails are still inserted
+	ids, err := db.InsertMany(ctx, DATABASE, collection, []interface{}{authored("alice", "one", 1), duplicate, authored("alice", "two", 3)})
+	var insertErr *interfaces.InsertManyError
+	if !errors.As(err, &insertErr) {
+		t.Fatalf("InsertMany() error = %v, want an InsertManyError", err)
+	}
+	if len(insertErr.Errors) != 1 || insertErr.Errors[1] == nil {
+		t.Errorf("InsertMany() errors = %v, want the error of the duplicate", insertErr.Errors)
+	}
+	if len(ids) != 3 || ids[1] != "" {
+		t.Fatalf("InsertMany() ids = %v, want 3 with the duplicate empty", ids)
+	}
+
+	docs, err := db.FindMany(ctx, DATABASE, collection, []string{ids[0], ids[2]})
+	if err != nil {
+		t.Fatalf("FindMany() error = %v", err)
+	}
+	got := messages(docs)
+	sort.Strings(got)
+	if want := []string{"one", "two"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindMany() of the inserted ids = %v, want %v", got, want)
+	}
+
+	ids, err = db.InsertMany(ctx, DATABASE, collection, []interface{}{})
+	if err != nil || len(ids) != 0 {
+		t.Errorf("InsertMany() of no documents = %v, %v, want none", ids, err)
+	}
+}
+
+func testFindMany(t *testing.T, db interfaces.Client, collection string) {
+	ctx := context.Background()
+
+	docs, err := db.FindMany(ctx, DATABASE, collection, []string{primitive.NewObjectID().Hex()})
+	if err != nil {
+		t.Fatalf("FindMany() of a missing collection error = %v", err)
+	}
+	if len(docs) != 0 {
+		t.Errorf("FindMany() of a missing collection = %v, want none", users(docs))
+	}
+
+	ids := insert(t, db, collection, crumb("a", -122.4, 37.8), crumb("b", -122.4, 37.8), crumb("c", -122.4, 37.8))
+
+	tests := []struct {
+		ids  []string
+		want []string
+	}{
+		{ids: []string{ids[2], ids[0]}, want: []string{"a", "c"}},
+		{ids: []string{ids[1], primitive.NewObjectID().Hex(), "42", ids[1]}, want: []string{"b"}},
+		{ids: []string{}, want: []string{}},
+	}
+	for _, tt := range tests {
+		docs, err := db.FindMany(ctx, DATABASE, collection, tt.ids)
+		if err != nil {
+			t.Fatalf("FindMany(%v) error = %v", tt.ids, err)
+		}
+		// the documents are returned in no particular order
+		got := users(docs)
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FindMany(%v) = %v, want %v", tt.ids, got, tt.want)
+		}
+	}
+}
+
+func testFindByUser(t *testing.T, db interfaces.Client, collection string) {
+	ctx := context.Background()
+	if err := db.CreateUserIndex(ctx, DATABASE, collection); err != nil {
+		t.Fatalf("CreateUserIndex() error = %v", err)
+	}
+
+	// ids increase with insertion, so "4" comes before "3" as they share their creation time
+	insert(t, db, collection,
+		authored("alice", "1", 1000),
+		authored("bob", "2", 2000),
+		authored("alice", "3", 3000),
+		authored("alice", "4", 3000),
+		authored("alice", "5", 2000),
+		authored("alice", "6", -1000),
+	)
+
+	tests := []struct {
+		user  string
+		limit int
+		want  [][]string
+	}{
+		{user: "alice", limit: 2, want: [][]string{{"4", "3"}, {"5", "1"}, {"6"}}},
+		{user: "alice", limit: 5, want: [][]string{{"4", "3", "5", "1", "6"}}},
+		{user: "bob", limit: 5, want: [][]string{{"2"}}},
+		{user: "carol", limit: 5, want: [][]string{{}}},
+		{user: "ali", limit: 5, want: [][]string{{}}},
+	}
+	for _, tt := range tests {
+		var after *interfaces.Cursor
+		for page, want := range tt.want {
+			docs, err := db.FindByUser(ctx, DATABASE, collection, tt.user, after, tt.limit)
+			if err != nil {
+				t.Fatalf("FindByUser(%v) page %v error = %v", tt.user, page, err)
+			}
+			if got := messages(docs); !reflect.DeepEqual(got, want) {
+				t.Fatalf("FindByUser(%v) page %v = %v, want %v", tt.user, page, got, want)
+			}
+			if len(docs) == 0 {
+				break
+			}
+
+			last := docs[len(docs)-1]
+			id, _ := document.Lookup(last, document.IDFIELD)
+			_, created := document.Author(last)
+			after = &interfaces.Cursor{Created: created, Id: id.(primitive.ObjectID).Hex()}
+		}
+
+		// the last page is followed by an empty one
+		if docs, err := db.FindByUser(ctx, DATABASE, collection, tt.user, after, tt.limit); err != nil || len(docs) != 0 {
+			t.Errorf("FindByUser(%v) after the last page = %v, %v, want none", tt.user, messages(docs), err)
+		}
+	}
+
+	if _, err := db.FindByUser(ctx, DATABASE, collection, "alice", &interfaces.Cursor{Id: "42"}, 5); err == nil {
+		t.Errorf("FindByUser() with an invalid cursor succeeded")
+	}
+}
+
+func testUpdate(t *testing.T, db interfaces.Client, collection string) {
+	ctx := context.Background()
+	ids := insert(t, db, collection, crumb("a", -122.4, 37.8))
+
+	location := mongodb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{2.35, 48.85}}
+	updated, err := db.Update(ctx, DATABASE, collection, ids[0], 0, map[string]interface{}{"message": "bye", "location": location})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	doc, err := db.FindOne(ctx, DATABASE, collection, ids[0])
+	if err != nil {
+		t.Fatalf("FindOne() error = %v", err)
+	}
+	if message, _ := document.Lookup(*doc, "message"); message != "bye" {
+		t.Errorf("Update() message = %v, want bye", message)
+	}
+	if !reflect.DeepEqual(*updated, *doc) {
+		t.Errorf("Update() = %v, want the stored document %v", *updated, *doc)
+	}
+
+	// the crumb moved in the index
+	for _, tt := range []struct {
+		coordinates []float64
+		want        []string
+	}{
+		{coordinates: []float64{-122.4, 37.8}, want: []string{}},
+		{coordinates: []float64{2.35, 48.85}, want: []string{"a"}},
+	} {
+		docs, err := db.SpaitalQuery(ctx, mongodb.POINT_TYPE_POINT, tt.coordinates, DATABASE, collection)
+		if err != nil {
+			t.Fatalf("SpaitalQuery() error = %v", err)
+		}
+		if got := users(docs); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SpaitalQuery(%v) = %v, want %v", tt.coordinates, got, tt.want)
+		}
+	}
+
+	if _, err := db.Update(ctx, DATABASE, collection, primitive.NewObjectID().Hex(), 0, map[string]interface{}{"message": "bye"}); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Update() of a missing document error = %v, want %v", err, mongo.ErrNoDocuments)
+	}
+	if _, err := db.Update(ctx, DATABASE, collection, ids[0], 0, map[string]interface{}{document.IDFIELD: primitive.NewObjectID()}); err == nil {
+		t.Errorf("Update() of the id succeeded")
+	}
+}
+
+// testUpdateVersion checks every update increments the version and the version is compared first
+func testUpdateVersion(t *testing.T, db interfaces.Client, collection string) {
+	ctx := context.Background()
+	ids := insert(t, db, collection, crumb("a", -122.4, 37.8))
+
+	tests := []struct {
+		name        string
+		version     int64
+		message     string
+		wantErr     error
+		wantVersion int64
+		wantMessage string
+	}{
+		// a document without a version is at version zero
+		{name: "unversioned", version: 0, message: "one", wantVersion: 1, wantMessage: "one"},
+		{name: "current version", version: 1, message: "two", wantVersion: 2, wantMessage: "two"},
+		{name: "stale version", version: 1, message: "three", wantErr: interfaces.ErrVersionMismatch, wantVersion: 2, wantMessage: "two"},
+		{name: "future version", version: 5, message: "three", wantErr: interfaces.ErrVersionMismatch, wantVersion: 2, wantMessage: "two"},
+		{name: "missing version", version: 0, message: "three", wantErr: interfaces.ErrVersionMismatch, wantVersion: 2, wantMessage: "two"},
+		{name: "current version again", version: 2, message: "four", wantVersion: 3, wantMessage: "four"},
+	}
+	for _, tt := range tests {
+		updated, err := db.Update(ctx, DATABASE, collection, ids[0], tt.version, map[string]interface{}{"message": tt.message})
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%v: Update() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if err == nil && document.Version(*updated) != tt.wantVersion {
+			t.Errorf("%v: Update() version = %v, want %v", tt.name, document.Version(*updated), tt.wantVersion)
+		}
+
+		doc, err := db.FindOne(ctx, DATABASE, collection, ids[0])
+		if err != nil {
+			t.Fatalf("FindOne() error = %v", err)
+		}
+		if got := document.Version(*doc); got != tt.wantVersion {
+			t.Errorf("%v: version = %v, want %v", tt.name, got, tt.wantVersion)
+		}
+		if got := messages([]bson.D{*doc}); !reflect.DeepEqual(got, []string{tt.wantMessage}) {
+			t.Errorf("%v: message = %v, want %v", tt.name, got, tt.wantMessage)
+		}
+	}
+
+	if _, err := db.Update(ctx, DATABASE, collection, primitive.NewObjectID().Hex(), 1, map[string]interface{}{"message": "bye"}); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Update() of a missing document error = %v, want %v", err, mongo.ErrNoDocuments)
+	}
+	if _, err := db.Update(ctx, DATABASE, collection, ids[0], 0, map[string]interface{}{mongodb.VERSION_KEY: int64(42)}); err == nil {
+		t.Errorf("Update() of the version succeeded")
+	}
+}
+
+func testDelete(t *testing.T, db interfaces.Client, collection string) {
+	ctx := context.Background()
+	ids := insert(t, db, collection, crumb("a", -122.4, 37.8), crumb("b", -122.4, 37.8003))
+
+	if err := db.Delete(ctx, DATABASE, collection, ids[0]); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := db.Delete(ctx, DATABASE, collection, ids[0]); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Delete() of a deleted document error = %v, want %v", err, mongo.ErrNoDocuments)
+	}
+	if _, err := db.FindOne(ctx, DATABASE, collection, ids[0]); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("FindOne() error = %v, want %v", err, mongo.ErrNoDocuments)
+	}
+
+	docs, err := db.SpaitalQuery(ctx, mongodb.POINT_TYPE_POINT, []float64{-122.4, 37.8}, DATABASE, collection)
+	if err != nil {
+		t.Fatalf("SpaitalQuery() error = %v", err)
+	}
+	if got := users(docs); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("SpaitalQuery() = %v, want [b]", got)
+	}
+}
+
+func testNotFound(t *testing.T, db interfaces.Client, collection string) {
+	ctx := context.Background()
+
+	docs, err := db.FindAll(ctx, DATABASE, collection)
+	if err != nil {
+		t.Fatalf("FindAll() of a missing collection error = %v", err)
+	}
+	if len(docs) != 0 {
+		t.Errorf("FindAll() of a missing collection = %v, want none", users(docs))
+	}
+
+	insert(t, db, collection, crumb("a", -122.4, 37.8))
+	for _, id := range []string{primitive.NewObjectID().Hex(), "42"} {
+		if _, err := db.FindOne(ctx, DATABASE, collection, id); !errors.Is(err, mongo.ErrNoDocuments) {
+			t.Errorf("FindOne(%v) error = %v, want %v", id, err, mongo.ErrNoDocuments)
+		}
+		if _, err := db.Update(ctx, DATABASE, collection, id, 0, map[string]interface{}{"message": "bye"}); !errors.Is(err, mongo.ErrNoDocuments) {
+			t.Errorf("Update(%v) error = %v, want %v", id, err, mongo.ErrNoDocuments)
+		}
+		if err := db.Delete(ctx, DATABASE, collection, id); !errors.Is(err, mongo.ErrNoDocuments) {
+			t.Errorf("Delete(%v) error = %v, want %v", id, err, mongo.ErrNoDocuments)
+		}
+	}
+
+	docs, err = db.FindAll(ctx, DATABASE, collection)
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if got := users(docs); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("FindAll() = %v, want [a]", got)
+	}
+}
+
+func testNear(t *testing.T, db interfaces.Client, collection string) {
+	ctx := context.Background()
+	insert(t, db, collection,
+		crumb("far", -122.4, 37.8008),
+		crumb("here", -122.4, 37.8),
+		crumb("near", -122.4, 37.8003),
+		crumb("out of range", -122.4, 37.81),
+	)
+
+	tests := []struct {
+		minDistance int
+		maxDistance int
+		want        []string
+	}{
+		{maxDistance: 100, want: []string{"here", "near", "far"}},
+		{minDistance: 50, maxDistance: 100, want: []string{"far"}},
+		{maxDistance: 10, want: []string{"here"}},
+	}
+	for _, tt := range tests {
+		db.SetDistanceLimits(tt.minDistance, tt.maxDistance)
+		docs, err := db.SpaitalQuery(ctx, mongodb.POINT_TYPE_POINT, []float64{-122.4, 37.8}, DATABASE, collection)
+		if err != nil {
+			t.Fatalf("SpaitalQuery() error = %v", err)
+		}
+		if got := users(docs); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SpaitalQuery() between %v and %v meters = %v, want %v", tt.minDistance, tt.maxDistance, got, tt.want)
+		}
+	}
+}
+
+func testNearWithoutIndex(t *testing.T, db interfaces.Client, collection string) {
+	ctx := context.Background()
+	if _, err := db.InsertRecord(ctx, DATABASE, collection, crumb("here", -122.4, 37.8)); err != nil {
+		t.Fatalf("InsertRecord() error = %v", err)
+	}
+
+	if _, err := db.SpaitalQuery(ctx, mongodb.POINT_TYPE_POINT, []float64{-122.4, 37.8}, DATABASE, collection); err == nil {
+		t.Errorf("SpaitalQuery() without a spatial index succeeded")
+	}
+}
+
+func testWithin(t *testing.T, db interfaces.Client, collection string) {
+	ctx := context.Background()
+	insert(t, db, collection,
+		crumb("far", -122.4, 37.8008),
+		crumb("here", -122.4, 37.8),
+		crumb("near", -122.4, 37.8003),
+		crumb("west", -122.402, 37.8),
+	)
+	square := []float64{-122.401, 37.7995, -122.399, 37.7995, -122.399, 37.8005, -122.401, 37.8005}
+
+	docs, err := db.SpaitalQuery(ctx, mongodb.POINT_TYPE_POLYGON, square, DATABASE, collection)
+	if err != nil {
+		t.Fatalf("SpaitalQuery() error = %v", err)
+	}
+	// $geoWithin does not sort its results
+	got := users(docs)
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, []string{"here", "near"}) {
+		t.Errorf("SpaitalQuery() = %v, want [here near]", got)
+	}
+
+	if _, err := db.SpaitalQuery(ctx, mongodb.POINT_TYPE_POLYGON, square[:4], DATABASE, collection); err == nil {
+		t.Errorf("SpaitalQuery() of a polygon with 2 vertices succeeded")
+	}
+}
+
+func testUnsupportedGeometry(t *testing.T, db interfaces.Client, collection string) {
+	insert(t, db, collection, crumb("here", -122.4, 37.8))
+
+	if _, err := db.SpaitalQuery(context.Background(), "LineString", []float64{-122.4, 37.8, -122.3, 37.8}, DATABASE, collection); err == nil {
+		t.Errorf("SpaitalQuery() of a LineString succeeded")
+	}
+}
+
+func testIntersects(t *testing.T, db interfaces.Client, collection string) {
+	ctx := context.Background()
+	insert(t, db, collection,
+		crumb("point inside", -122.4, 37.8),
+		crumb("point outside", -122.402, 37.8),
+		trail("line crossing", []float64{-122.402, 37.8001}, []float64{-122.398, 37.8001}),
+		trail("line ending inside", []float64{-122.4, 37.802}, []float64{-122.4, 37.8002}),
+		trail("line outside", []float64{-122.402, 37.802}, []float64{-122.398, 37.802}),
+	)
+	square := []float64{-122.401, 37.7995, -122.399, 37.7995, -122.399, 37.8005, -122.401, 37.8005}
+
+	docs, err := db.SpatialIntersects(ctx, DATABASE, collection, mongodb.POINT_TYPE_POLYGON, square)
+	if err != nil {
+		t.Fatalf("SpatialIntersects() error = %v", err)
+	}
+	got := users(docs)
+	sort.Strings(got)
+	if want := []string{"line crossing", "line ending inside", "point inside"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SpatialIntersects() = %v, want %v", got, want)
+	}
+	count, err := db.CountIntersects(ctx, DATABASE, collection, mongodb.POINT_TYPE_POLYGON, square)
+	if err != nil || count != 3 {
+		t.Errorf("CountIntersects() = %v, %v, want 3", count, err)
+	}
+
+	if _, err := db.SpatialIntersects(ctx, DATABASE, collection, mongodb.POINT_TYPE_POLYGON, square[:4]); err == nil {
+		t.Errorf("SpatialIntersects() of a polygon with 2 vertices succeeded")
+	}
+	if _, err := db.SpatialIntersects(ctx, DATABASE, collection, "LineString", []float64{-122.4, 37.8, -122.3, 37.8}); err == nil {
+		t.Errorf("SpatialIntersects() of a LineString succeeded")
+	}
+}
+
+func testIntersectsPoint(t *testing.T, db interfaces.Client, collection string) {
+	ctx := context.Background()
+	insert(t, db, collection,
+		area("square", []float64{-122.401, 37.7995}, []float64{-122.399, 37.7995}, []float64{-122.399, 37.8005}, []float64{-122.401, 37.8005}),
+		area("square west", []float64{-122.403, 37.7995}, []float64{-122.401, 37.7995}, []float64{-122.401, 37.8005}, []float64{-122.403, 37.8005}),
+		crumb("point", -122.4, 37.8),
+		crumb("point aside", -122.4, 37.8001),
+		trail("line through", []float64{-122.4, 37.799}, []float64{-122.4, 37.801}),
+	)
+
+	docs, err := db.SpatialIntersects(ctx, DATABASE, collection, mongodb.POINT_TYPE_POINT, []float64{-122.4, 37.8})
+	if err != nil {
+		t.Fatalf("SpatialIntersects() error = %v", err)
+	}
+	got := users(docs)
+	sort.Strings(got)
+	if want := []string{"line through", "point", "square"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SpatialIntersects() = %v, want %v", got, want)
+	}
+
+	// polygons intersect polygons they overlap, touch or contain
+	inner := []float64{-122.4002, 37.7998, -122.3998, 37.7998, -122.3998, 37.8002, -122.4002, 37.8002}
+	docs, err = db.SpatialIntersects(ctx, DATABASE, collection, mongodb.POINT_TYPE_POLYGON, inner)
+	if err != nil {
+		t.Fatalf("SpatialIntersects() error = %v", err)
+	}
+	got = users(docs)
+	sort.Strings(got)
+	if want := []string{"line through", "point", "point aside", "square"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SpatialIntersects() = %v, want %v", got, want)
+	}
+
+	if _, err := db.SpatialIntersects(ctx, DATABASE, collection, mongodb.POINT_TYPE_POINT, []float64{-122.4}); err == nil {
+		t.Errorf("SpatialIntersects() of a Point with 1 coordinate succeeded")
+	}
+}
+
+func testFindByField(t *testing.T, db interfaces.Client, collection string) {
+	ctx := context.Background()
+	if err := db.CreateFieldIndex(ctx, DATABASE, collection, "tags"); err != nil {
+		t.Fatalf("CreateFieldIndex() error = %v", err)
+	}
+	insert(t, db, collection,
+		append(authored("alice", "one", 1), bson.E{Key: "tags", Value: bson.A{"a", "b"}}),
+		append(authored("bob", "two", 2), bson.E{Key: "tags", Value: bson.A{"b"}}),
+		append(authored("alice", "three", 3), bson.E{Key: "tags", Value: bson.A{}}),
+	)
+
+	tests := []struct {
+		name  string
+		key   string
+		value interface{}
+		want  []string
+	}{
+		{name: "array containing the value", key: "tags", value: "b", want: []string{"one", "two"}},
+		{name: "array containing the value once", key: "tags", value: "a", want: []string{"one"}},
+		{name: "equal value", key: mongodb.USER_INDEX_KEY, value: "alice", want: []string{"one", "three"}},
+		{name: "no match", key: "tags", value: "c", want: []string{}},
+		{name: "missing field", key: "missing", value: "a", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := db.FindByField(ctx, DATABASE, collection, tt.key, tt.value)
+			if err != nil {
+				t.Fatalf("FindByField() error = %v", err)
+			}
+			// the order of the results is unspecified
+			got := messages(docs)
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindByField() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func testUniqueIndex(t *testing.T, db interfaces.Client, collection string) {
+	ctx := context.Background()
+	// the index covers the documents written before it
+	ids := insert(t, db, collection,
+		append(authored("alice", "one", 1), bson.E{Key: "rule_id", Value: "harbor"}),
+		append(authored("alice", "two", 2), bson.E{Key: "rule_id", Value: "plaza"}),
+		authored("alice", "three", 3),
+	)
+	if err := db.CreateUniqueIndex(ctx, DATABASE, collection, "rule_id"); err != nil {
+		t.Fatalf("CreateUniqueIndex() error = %v", err)
+	}
+
+	// documents without the key do not conflict
+	if _, err := db.InsertRecord(ctx, DATABASE, collection, authored("bob", "four", 4)); err != nil {
+		t.Errorf("InsertRecord() without the key error = %v", err)
+	}
+	if _, err := db.InsertRecord(ctx, DATABASE, collection, append(authored("bob", "five", 5), bson.E{Key: "rule_id", Value: "harbor"})); !errors.Is(err, interfaces.ErrDuplicateKey) {
+		t.Errorf("InsertRecord() of a duplicate error = %v, want %v", err, interfaces.ErrDuplicateKey)
+	}
+	if _, err := db.Update(ctx, DATABASE, collection, ids[1], 0, map[string]interface{}{"rule_id": "harbor"}); !errors.Is(err, interfaces.ErrDuplicateKey) {
+		t.Errorf("Update() to a duplicate error = %v, want %v", err, interfaces.ErrDuplicateKey)
+	}
+	// a document keeps its own value
+	if _, err := db.Update(ctx, DATABASE, collection, ids[0], 0, map[string]interface{}{"rule_id": "harbor", "message": "six"}); err != nil {
+		t.Errorf("Update() keeping the value error = %v", err)
+	}
+
+	docs, err := db.FindByField(ctx, DATABASE, collection, "rule_id", "harbor")
+	if err != nil || len(docs) != 1 {
+		t.Errorf("FindByField() = %v, %v, want a document", docs, err)
+	}
+
+	// the values of a document are released when it changes them or is deleted
+	if _, err := db.Update(ctx, DATABASE, collection, ids[1], 0, map[string]interface{}{"rule_id": "park"}); err != nil {
+		t.Errorf("Update() to a new value error = %v", err)
+	}
+	if _, err := db.InsertRecord(ctx, DATABASE, collection, append(authored("bob", "seven", 7), bson.E{Key: "rule_id", Value: "plaza"})); err != nil {
+		t.Errorf("InsertRecord() of a released value error = %v", err)
+	}
+	if err := db.Delete(ctx, DATABASE, collection, ids[0]); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := db.InsertRecord(ctx, DATABASE, collection, append(authored("bob", "eight", 8), bson.E{Key: "rule_id", Value: "harbor"})); err != nil {
+		t.Errorf("InsertRecord() of the value of a deleted document error = %v", err)
+	}
+}
+
+func crumb(user string, lng, lat float64) bson.D {
+	return bson.D{
+		{Key: "location", Value: mongodb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{lng, lat}}},
+		{Key: "user", Value: user},
+		{Key: "message", Value: "hi"},
+	}
+}
+
+// trail returns a document of user located on the line through vertices
+func trail(user string, vertices ...[]float64) bson.D {
+	return bson.D{
+		{Key: "location", Value: mongodb.LineString{Type: mongodb.POINT_TYPE_LINE_STRING, Coordinates: vertices}},
+		{Key: "user", Value: user},
+	}
+}
+
+// area returns a document of user located in the polygon of the vertices, closed here
+func area(user string, vertices ...[]float64) bson.D {
+	ring := append(vertices, vertices[0])
+	return bson.D{
+		{Key: "location", Value: mongodb.Polygon{Type: mongodb.POINT_TYPE_POLYGON, Coordinates: [][][]float64{ring}}},
+		{Key: "user", Value: user},
+	}
+}
+
+// authored returns a crumb of user created at the given unix milliseconds, message identifies it in results
+func authored(user string, message string, created int64) bson.D {
+	return bson.D{
+		{Key: "location", Value: mongodb.Point{Type: mongodb.POINT_TYPE_POINT, Coordinates: []float64{-122.4, 37.8}}},
+		{Key: mongodb.USER_INDEX_KEY, Value: user},
+		{Key: "message", Value: message},
+		{Key: mongodb.CREATED_INDEX_KEY, Value: created},
+	}
+}
+
+// insert stores docs in an indexed collection and returns their hex ids. The ids are generated here as the
+// id format returned by InsertRecord differs between clients
+func insert(t *testing.T, db interfaces.Client, collection string, docs ...bson.D) []string {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := db.CreateSpatialIndex(ctx, DATABASE, collection, mongodb.SPATIAL_INDEX_TYPE); err != nil {
+		t.Fatalf("CreateSpatialIndex() error = %v", err)
+	}
+
+	var ids []string
+	for _, doc := range docs {
+		objId := primitive.NewObjectID()
+		if _, err := db.InsertRecord(ctx, DATABASE, collection, append(bson.D{{Key: document.IDFIELD, Value: objId}}, doc...)); err != nil {
+			t.Fatalf("InsertRecord() error = %v", err)
+		}
+		ids = append(ids, objId.Hex())
+	}
+	return ids
+}
+
+func users(docs []bson.D) []string {
+	names := []string{}
+	for _, doc := range docs {
+		user, _ := document.Lookup(doc, "user")
+		names = append(names, fmt.Sprint(user))
+	}
+	return names
+}
+
+func messages(docs []bson.D) []string {
+	values := []string{}
+	for _, doc := range docs {
+		message, _ := document.Lookup(doc, "message")
+		values = append(values, fmt.Sprint(message))
+	}
+	return values
+}
+
+// collection returns a collection name unique to the test and the run
+func collection(t *testing.T) string {
+	return fmt.Sprintf("%v_%v", strings.ReplaceAll(t.Name(), "/", "_"), time.Now().UnixNano())
+}
@@ -5,6 +5,8 @@ package mocks
 import (
 	context "context"
 
+	interfaces "github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces"
+
 	mock "github.com/stretchr/testify/mock"
 
 	primitive "go.mongodb.org/mongo-driver/bson/primitive"
@@ -33,17 +35,99 @@ func (_m *Client) Connect() error {
 	return r0
 }
 
-// CreateSpatialIndex provides a mock function with given fields: databaseName, collectionName, spatialType
-func (_m *Client) CreateSpatialIndex(databaseName string, collectionName string, spatialType string) error {
-	ret := _m.Called(databaseName, collectionName, spatialType)
+// CountIntersects provides a mock function with given fields: ctx, databaseName, collectionName, pointType, coordinates
+func (_m *Client) CountIntersects(ctx context.Context, databaseName string, collectionName string, pointType string, coordinates []float64) (int64, error) {
+	ret := _m.Called(ctx, databaseName, collectionName, pointType, coordinates)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountIntersects")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, []float64) (int64, error)); ok {
+		return rf(ctx, databaseName, collectionName, pointType, coordinates)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, []float64) int64); ok {
+		r0 = rf(ctx, databaseName, collectionName, pointType, coordinates)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, []float64) error); ok {
+		r1 = rf(ctx, databaseName, collectionName, pointType, coordinates)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateFieldIndex provides a mock function with given fields: ctx, databaseName, collectionName, key
+func (_m *Client) CreateFieldIndex(ctx context.Context, databaseName string, collectionName string, key string) error {
+	ret := _m.Called(ctx, databaseName, collectionName, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateFieldIndex")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, databaseName, collectionName, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateSpatialIndex provides a mock function with given fields: ctx, databaseName, collectionName, spatialType
+func (_m *Client) CreateSpatialIndex(ctx context.Context, databaseName string, collectionName string, spatialType string) error {
+	ret := _m.Called(ctx, databaseName, collectionName, spatialType)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateSpatialIndex")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
-		r0 = rf(databaseName, collectionName, spatialType)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, databaseName, collectionName, spatialType)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateUniqueIndex provides a mock function with given fields: ctx, databaseName, collectionName, key
+func (_m *Client) CreateUniqueIndex(ctx context.Context, databaseName string, collectionName string, key string) error {
+	ret := _m.Called(ctx, databaseName, collectionName, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateUniqueIndex")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, databaseName, collectionName, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateUserIndex provides a mock function with given fields: ctx, databaseName, collectionName
+func (_m *Client) CreateUserIndex(ctx context.Context, databaseName string, collectionName string) error {
+	ret := _m.Called(ctx, databaseName, collectionName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateUserIndex")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, databaseName, collectionName)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -51,17 +135,17 @@ func (_m *Client) CreateSpatialIndex(databaseName string, collectionName string,
 	return r0
 }
 
-// Delete provides a mock function with given fields: databaseName, collectionName, id
-func (_m *Client) Delete(databaseName string, collectionName string, id string) error {
-	ret := _m.Called(databaseName, collectionName, id)
+// Delete provides a mock function with given fields: ctx, databaseName, collectionName, id
+func (_m *Client) Delete(ctx context.Context, databaseName string, collectionName string, id string) error {
+	ret := _m.Called(ctx, databaseName, collectionName, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Delete")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
-		r0 = rf(databaseName, collectionName, id)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, databaseName, collectionName, id)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -87,9 +171,9 @@ func (_m *Client) Disconnect(_a0 context.Context) error {
 	return r0
 }
 
-// FindAll provides a mock function with given fields: databaseName, collectionName
-func (_m *Client) FindAll(databaseName string, collectionName string) ([]primitive.D, error) {
-	ret := _m.Called(databaseName, collectionName)
+// FindAll provides a mock function with given fields: ctx, databaseName, collectionName
+func (_m *Client) FindAll(ctx context.Context, databaseName string, collectionName string) ([]primitive.D, error) {
+	ret := _m.Called(ctx, databaseName, collectionName)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindAll")
@@ -97,19 +181,49 @@ func (_m *Client) FindAll(databaseName string, collectionName string) ([]primiti
 
 	var r0 []primitive.D
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) ([]primitive.D, error)); ok {
-		return rf(databaseName, collectionName)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]primitive.D, error)); ok {
+		return rf(ctx, databaseName, collectionName)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []primitive.D); ok {
+		r0 = rf(ctx, databaseName, collectionName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]primitive.D)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, databaseName, collectionName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByField provides a mock function with given fields: ctx, databaseName, collectionName, key, value
+func (_m *Client) FindByField(ctx context.Context, databaseName string, collectionName string, key string, value interface{}) ([]primitive.D, error) {
+	ret := _m.Called(ctx, databaseName, collectionName, key, value)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByField")
+	}
+
+	var r0 []primitive.D
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, interface{}) ([]primitive.D, error)); ok {
+		return rf(ctx, databaseName, collectionName, key, value)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) []primitive.D); ok {
-		r0 = rf(databaseName, collectionName)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, interface{}) []primitive.D); ok {
+		r0 = rf(ctx, databaseName, collectionName, key, value)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]primitive.D)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(databaseName, collectionName)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, interface{}) error); ok {
+		r1 = rf(ctx, databaseName, collectionName, key, value)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -117,9 +231,69 @@ func (_m *Client) FindAll(databaseName string, collectionName string) ([]primiti
 	return r0, r1
 }
 
-// FindOne provides a mock function with given fields: databaseName, collectionName, id
-func (_m *Client) FindOne(databaseName string, collectionName string, id string) (*primitive.D, error) {
-	ret := _m.Called(databaseName, collectionName, id)
+// FindByUser provides a mock function with given fields: ctx, databaseName, collectionName, user, after, limit
+func (_m *Client) FindByUser(ctx context.Context, databaseName string, collectionName string, user string, after *interfaces.Cursor, limit int) ([]primitive.D, error) {
+	ret := _m.Called(ctx, databaseName, collectionName, user, after, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByUser")
+	}
+
+	var r0 []primitive.D
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, *interfaces.Cursor, int) ([]primitive.D, error)); ok {
+		return rf(ctx, databaseName, collectionName, user, after, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, *interfaces.Cursor, int) []primitive.D); ok {
+		r0 = rf(ctx, databaseName, collectionName, user, after, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]primitive.D)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, *interfaces.Cursor, int) error); ok {
+		r1 = rf(ctx, databaseName, collectionName, user, after, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindMany provides a mock function with given fields: ctx, databaseName, collectionName, ids
+func (_m *Client) FindMany(ctx context.Context, databaseName string, collectionName string, ids []string) ([]primitive.D, error) {
+	ret := _m.Called(ctx, databaseName, collectionName, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindMany")
+	}
+
+	var r0 []primitive.D
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []string) ([]primitive.D, error)); ok {
+		return rf(ctx, databaseName, collectionName, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []string) []primitive.D); ok {
+		r0 = rf(ctx, databaseName, collectionName, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]primitive.D)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, []string) error); ok {
+		r1 = rf(ctx, databaseName, collectionName, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindOne provides a mock function with given fields: ctx, databaseName, collectionName, id
+func (_m *Client) FindOne(ctx context.Context, databaseName string, collectionName string, id string) (*primitive.D, error) {
+	ret := _m.Called(ctx, databaseName, collectionName, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindOne")
@@ -127,19 +301,19 @@ func (_m *Client) FindOne(databaseName string, collectionName string, id string)
 
 	var r0 *primitive.D
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string, string) (*primitive.D, error)); ok {
-		return rf(databaseName, collectionName, id)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (*primitive.D, error)); ok {
+		return rf(ctx, databaseName, collectionName, id)
 	}
-	if rf, ok := ret.Get(0).(func(string, string, string) *primitive.D); ok {
-		r0 = rf(databaseName, collectionName, id)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *primitive.D); ok {
+		r0 = rf(ctx, databaseName, collectionName, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*primitive.D)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
-		r1 = rf(databaseName, collectionName, id)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, databaseName, collectionName, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -147,9 +321,39 @@ func (_m *Client) FindOne(databaseName string, collectionName string, id string)
 	return r0, r1
 }
 
-// InsertRecord provides a mock function with given fields: databaseName, collectionName, doc
-func (_m *Client) InsertRecord(databaseName string, collectionName string, doc interface{}) (string, error) {
-	ret := _m.Called(databaseName, collectionName, doc)
+// InsertMany provides a mock function with given fields: ctx, databaseName, collectionName, docs
+func (_m *Client) InsertMany(ctx context.Context, databaseName string, collectionName string, docs []interface{}) ([]string, error) {
+	ret := _m.Called(ctx, databaseName, collectionName, docs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertMany")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []interface{}) ([]string, error)); ok {
+		return rf(ctx, databaseName, collectionName, docs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []interface{}) []string); ok {
+		r0 = rf(ctx, databaseName, collectionName, docs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, []interface{}) error); ok {
+		r1 = rf(ctx, databaseName, collectionName, docs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InsertRecord provides a mock function with given fields: ctx, databaseName, collectionName, doc
+func (_m *Client) InsertRecord(ctx context.Context, databaseName string, collectionName string, doc interface{}) (string, error) {
+	ret := _m.Called(ctx, databaseName, collectionName, doc)
 
 	if len(ret) == 0 {
 		panic("no return value specified for InsertRecord")
@@ -157,17 +361,17 @@ func (_m *Client) InsertRecord(databaseName string, collectionName string, doc i
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string, interface{}) (string, error)); ok {
-		return rf(databaseName, collectionName, doc)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, interface{}) (string, error)); ok {
+		return rf(ctx, databaseName, collectionName, doc)
 	}
-	if rf, ok := ret.Get(0).(func(string, string, interface{}) string); ok {
-		r0 = rf(databaseName, collectionName, doc)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, interface{}) string); ok {
+		r0 = rf(ctx, databaseName, collectionName, doc)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, interface{}) error); ok {
-		r1 = rf(databaseName, collectionName, doc)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, interface{}) error); ok {
+		r1 = rf(ctx, databaseName, collectionName, doc)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -193,9 +397,14 @@ func (_m *Client) Ping() error {
 	return r0
 }
 
-// SpaitalQuery provides a mock function with given fields: pointType, coordinates, databaseName, collectionName
-func (_m *Client) SpaitalQuery(pointType string, coordinates []float64, databaseName string, collectionName string) ([]primitive.D, error) {
-	ret := _m.Called(pointType, coordinates, databaseName, collectionName)
+// SetDistanceLimits provides a mock function with given fields: minDistance, maxDistance
+func (_m *Client) SetDistanceLimits(minDistance int, maxDistance int) {
+	_m.Called(minDistance, maxDistance)
+}
+
+// SpaitalQuery provides a mock function with given fields: ctx, pointType, coordinates, databaseName, collectionName
+func (_m *Client) SpaitalQuery(ctx context.Context, pointType string, coordinates []float64, databaseName string, collectionName string) ([]primitive.D, error) {
+	ret := _m.Called(ctx, pointType, coordinates, databaseName, collectionName)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SpaitalQuery")
@@ -203,19 +412,19 @@ func (_m *Client) SpaitalQuery(pointType string, coordinates []float64, database
 
 	var r0 []primitive.D
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, []float64, string, string) ([]primitive.D, error)); ok {
-		return rf(pointType, coordinates, databaseName, collectionName)
+	if rf, ok := ret.Get(0).(func(context.Context, string, []float64, string, string) ([]primitive.D, error)); ok {
+		return rf(ctx, pointType, coordinates, databaseName, collectionName)
 	}
-	if rf, ok := ret.Get(0).(func(string, []float64, string, string) []primitive.D); ok {
-		r0 = rf(pointType, coordinates, databaseName, collectionName)
+	if rf, ok := ret.Get(0).(func(context.Context, string, []float64, string, string) []primitive.D); ok {
+		r0 = rf(ctx, pointType, coordinates, databaseName, collectionName)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]primitive.D)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, []float64, string, string) error); ok {
-		r1 = rf(pointType, coordinates, databaseName, collectionName)
+	if rf, ok := ret.Get(1).(func(context.Context, string, []float64, string, string) error); ok {
+		r1 = rf(ctx, pointType, coordinates, databaseName, collectionName)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -223,22 +432,64 @@ func (_m *Client) SpaitalQuery(pointType string, coordinates []float64, database
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: databaseName, collectionName, id, items
-func (_m *Client) Update(databaseName string, collectionName string, id string, items map[string]interface{}) error {
-	ret := _m.Called(databaseName, collectionName, id, items)
+// SpatialIntersects provides a mock function with given fields: ctx, databaseName, collectionName, pointType, coordinates
+func (_m *Client) SpatialIntersects(ctx context.Context, databaseName string, collectionName string, pointType string, coordinates []float64) ([]primitive.D, error) {
+	ret := _m.Called(ctx, databaseName, collectionName, pointType, coordinates)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SpatialIntersects")
+	}
+
+	var r0 []primitive.D
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, []float64) ([]primitive.D, error)); ok {
+		return rf(ctx, databaseName, collectionName, pointType, coordinates)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, []float64) []primitive.D); ok {
+		r0 = rf(ctx, databaseName, collectionName, pointType, coordinates)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]primitive.D)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, []float64) error); ok {
+		r1 = rf(ctx, databaseName, collectionName, pointType, coordinates)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, databaseName, collectionName, id, version, items
+func (_m *Client) Update(ctx context.Context, databaseName string, collectionName string, id string, version int64, items map[string]interface{}) (*primitive.D, error) {
+	ret := _m.Called(ctx, databaseName, collectionName, id, version, items)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, string, map[string]interface{}) error); ok {
-		r0 = rf(databaseName, collectionName, id, items)
+	var r0 *primitive.D
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, int64, map[string]interface{}) (*primitive.D, error)); ok {
+		return rf(ctx, databaseName, collectionName, id, version, items)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, int64, map[string]interface{}) *primitive.D); ok {
+		r0 = rf(ctx, databaseName, collectionName, id, version, items)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*primitive.D)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, int64, map[string]interface{}) error); ok {
+		r1 = rf(ctx, databaseName, collectionName, id, version, items)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // NewClient creates a new instance of Client. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
@@ -2,10 +2,13 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces"
+	appMetrics "github.com/haguru/horus/crumbdb/pkg/prometheus"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	"go.mongodb.org/mongo-driver/bson"
@@ -20,27 +23,37 @@ const (
 	MAXPOOLSIZE        = 20
 	SPATIAL_INDEX_TYPE = "2dsphere"
 	SPATIAL_INDEX_KEY  = "location"
+	USER_INDEX_KEY     = "user"
+	CREATED_INDEX_KEY  = "created_at"
+	VERSION_KEY        = "version"
 	_ID                = "_id"
 )
 
 type MongoDB struct {
-	Uri        string
-	Host       string
-	Port       int
-	ServerOpts *options.ServerAPIOptions
-	timeout    time.Duration
-	Client     *mongo.Client
-	lc         logger.LoggingClient
+	Uri         string
+	Host        string
+	Port        int
+	ServerOpts  *options.ServerAPIOptions
+	timeout     time.Duration
+	Client      *mongo.Client
+	lc          logger.LoggingClient
+	metrics     *appMetrics.Metrics
+	maxDistance int
+	minDistance int
+	mu          sync.RWMutex
 }
 
 // NewMongoDB returns a interface for db client and error if it occurs
-func NewMongoDB(host string, port int, lc logger.LoggingClient, timeout time.Duration, opts *options.ServerAPIOptions) (interfaces.Client, error) {
+func NewMongoDB(host string, port int, lc logger.LoggingClient, metrics *appMetrics.Metrics, timeout time.Duration, opts *options.ServerAPIOptions) (interfaces.Client, error) {
 	db := &MongoDB{
-		Host:       host,
-		Port:       port,
-		lc:         lc,
-		ServerOpts: opts,
-		timeout:    timeout,
+		Host:        host,
+		Port:        port,
+		lc:          lc,
+		metrics:     metrics,
+		ServerOpts:  opts,
+		timeout:     timeout,
+		maxDistance: MAX_DISTANCE,
+		minDistance: MIN_DISTANCE,
 	}
 	err := db.Connect()
 	if err != nil {
@@ -103,13 +116,16 @@ func (db *MongoDB) Disconnect(context context.Context) error {
 
 // CreateSpatialIndex returns error if client is unable to create a spatial index
 // this is needed to search database by (longitude, latitude) coordinates
-func (db *MongoDB) CreateSpatialIndex(databaseName string, collectionName string, spatialType string) error {
+func (db *MongoDB) CreateSpatialIndex(ctx context.Context, databaseName string, collectionName string, spatialType string) (err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_CREATE_INDEX, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
 	collection := db.Client.Database(databaseName).Collection(collectionName)
 	indexModel := mongo.IndexModel{
 		Keys: bson.D{{Key: SPATIAL_INDEX_KEY, Value: spatialType}},
 	}
 
-	_, err := collection.Indexes().CreateOne(context.TODO(), indexModel)
+	_, err = collection.Indexes().CreateOne(ctx, indexModel)
 	if err != nil {
 		return err
 	}
@@ -117,12 +133,62 @@ func (db *MongoDB) CreateSpatialIndex(databaseName string, collectionName string
 	return nil
 }
 
+// CreateFieldIndex returns error if client is unable to create the index of FindByField on key
+func (db *MongoDB) CreateFieldIndex(ctx context.Context, databaseName string, collectionName string, key string) (err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_CREATE_INDEX, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
+	collection := db.Client.Database(databaseName).Collection(collectionName)
+	indexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: key, Value: 1}},
+	}
+
+	_, err = collection.Indexes().CreateOne(ctx, indexModel)
+	return err
+}
+
+// CreateUniqueIndex returns error if client is unable to create a unique index on key. Documents without key
+// are left out of the index, so they never conflict
+func (db *MongoDB) CreateUniqueIndex(ctx context.Context, databaseName string, collectionName string, key string) (err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_CREATE_INDEX, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
+	collection := db.Client.Database(databaseName).Collection(collectionName)
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: key, Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.D{{Key: key, Value: bson.D{{Key: "$exists", Value: true}}}}),
+	}
+
+	_, err = collection.Indexes().CreateOne(ctx, indexModel)
+	return err
+}
+
+// CreateUserIndex returns error if client is unable to create the index of FindByUser
+func (db *MongoDB) CreateUserIndex(ctx context.Context, databaseName string, collectionName string) (err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_CREATE_INDEX, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
+	collection := db.Client.Database(databaseName).Collection(collectionName)
+	indexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: USER_INDEX_KEY, Value: 1}, {Key: CREATED_INDEX_KEY, Value: -1}, {Key: _ID, Value: -1}},
+	}
+
+	_, err = collection.Indexes().CreateOne(ctx, indexModel)
+	return err
+}
+
 // InsertRecord returns ID, as string, and error.
 // if error occurs an empty string is returned along with the error
-func (db *MongoDB) InsertRecord(databaseName string, collectionName string, doc interface{}) (string, error) {
+func (db *MongoDB) InsertRecord(ctx context.Context, databaseName string, collectionName string, doc interface{}) (id string, err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_INSERT, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
 	collection := db.Client.Database(databaseName).Collection(collectionName)
 
-	r, err := collection.InsertOne(context.TODO(), doc)
+	r, err := collection.InsertOne(ctx, doc)
+	if mongo.IsDuplicateKeyError(err) {
+		return "", fmt.Errorf("%w: %v", interfaces.ErrDuplicateKey, err)
+	}
 	if err != nil {
 		return "", err
 	}
@@ -132,26 +198,74 @@ func (db *MongoDB) InsertRecord(databaseName string, collectionName string, doc
 		return "", fmt.Errorf("failed to get objectID")
 	}
 
-	return objId.String(), nil
+	return objId.Hex(), nil
+}
+
+// InsertMany inserts docs unordered, so the documents after one which fails are still inserted
+func (db *MongoDB) InsertMany(ctx context.Context, databaseName string, collectionName string, docs []interface{}) (ids []string, err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_INSERT, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
+	if len(docs) == 0 {
+		return []string{}, nil
+	}
+	collection := db.Client.Database(databaseName).Collection(collectionName)
+
+	r, err := collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	var bulkErr mongo.BulkWriteException
+	if err != nil && (!errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil || r == nil) {
+		return nil, err
+	}
+
+	// the inserted ids are those of every document, including the ones which failed
+	ids = make([]string, len(docs))
+	for i, insertedId := range r.InsertedIDs {
+		objId, ok := insertedId.(primitive.ObjectID)
+		if !ok {
+			return nil, fmt.Errorf("failed to get objectID")
+		}
+		ids[i] = objId.Hex()
+	}
+	if err == nil {
+		return ids, nil
+	}
+
+	failed := map[int]error{}
+	for _, writeErr := range bulkErr.WriteErrors {
+		ids[writeErr.Index] = ""
+		failed[writeErr.Index] = writeErr
+	}
+	return ids, &interfaces.InsertManyError{Errors: failed}
 }
 
 // SpaitalQuery queries database for data based on coordinates. Returns array of bson.D and error
 // if error occurs a nil is returned as well as an error
-func (db *MongoDB) SpaitalQuery(pointType string, coordinates []float64, databaseName string, collectionName string) ([]bson.D, error) {
-	filter, err := NewSpatialQueryCommand(OP_TYPE_NEAR, POINT_TYPE_POINT, coordinates, MAX_DISTANCE, MIN_DISTANCE)
+func (db *MongoDB) SpaitalQuery(ctx context.Context, pointType string, coordinates []float64, databaseName string, collectionName string) (docs []bson.D, err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_FIND, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
+	db.mu.RLock()
+	maxDistance, minDistance := db.maxDistance, db.minDistance
+	db.mu.RUnlock()
+
+	// points return the nearest documents first, polygons the documents inside them
+	opType := OP_TYPE_NEAR
+	if pointType == POINT_TYPE_POLYGON {
+		opType = OP_TYPE_GEO_WITHIN
+	}
+	filter, err := NewSpatialQueryCommand(opType, pointType, coordinates, maxDistance, minDistance)
 	if err != nil {
 		return nil, fmt.Errorf("failed to perforom spatial query: %v", err)
 	}
 
 	collection := db.Client.Database(databaseName).Collection(collectionName)
 
-	output, err := collection.Find(context.TODO(), filter)
+	output, err := collection.Find(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	var docs []bson.D
-	err = output.All(context.TODO(), &docs)
+	err = output.All(ctx, &docs)
 	if err != nil {
 		return nil, err
 	}
@@ -159,21 +273,76 @@ func (db *MongoDB) SpaitalQuery(pointType string, coordinates []float64, databas
 	return docs, nil
 }
 
+// SpatialIntersects retrieves the documents whose location intersects the point or the polygon given as flat
+// [longitude, latitude] pairs of its vertices
+func (db *MongoDB) SpatialIntersects(ctx context.Context, databaseName string, collectionName string, pointType string, coordinates []float64) (docs []bson.D, err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_FIND, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
+	if pointType != POINT_TYPE_POLYGON && pointType != POINT_TYPE_POINT {
+		return nil, fmt.Errorf("point type %v not supported", pointType)
+	}
+	filter, err := NewSpatialQueryCommand(OP_TYPE_GEO_INTERSECTS, pointType, coordinates, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to perforom spatial query: %v", err)
+	}
+
+	collection := db.Client.Database(databaseName).Collection(collectionName)
+
+	cur, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// CountIntersects returns the number of documents whose location intersects the point or the polygon given as
+// flat [longitude, latitude] pairs of its vertices
+func (db *MongoDB) CountIntersects(ctx context.Context, databaseName string, collectionName string, pointType string, coordinates []float64) (count int64, err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_COUNT, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
+	if pointType != POINT_TYPE_POLYGON && pointType != POINT_TYPE_POINT {
+		return 0, fmt.Errorf("point type %v not supported", pointType)
+	}
+	filter, err := NewSpatialQueryCommand(OP_TYPE_GEO_INTERSECTS, pointType, coordinates, 0, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to perforom spatial query: %v", err)
+	}
+
+	collection := db.Client.Database(databaseName).Collection(collectionName)
+	return collection.CountDocuments(ctx, filter)
+}
+
+// SetDistanceLimits sets the distance bounds, in meters, used by SpaitalQuery
+func (db *MongoDB) SetDistanceLimits(minDistance int, maxDistance int) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.minDistance = minDistance
+	db.maxDistance = maxDistance
+}
+
 // FindAll retrieves all documents in the database. Returns an array of bson.D and error.
 // if an error occurs then a nil is return and an error
-func (db *MongoDB) FindAll(databaseName string, collectionName string) ([]bson.D, error) {
+func (db *MongoDB) FindAll(ctx context.Context, databaseName string, collectionName string) (results []bson.D, err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_FIND, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
 	collection := db.Client.Database(databaseName).Collection(collectionName)
 
-	cur, err := collection.Find(context.TODO(), bson.D{{}})
+	cur, err := collection.Find(ctx, bson.D{{}})
 	if err != nil {
 		return nil, err
 	}
 
-	var results []bson.D
-	for cur.Next(context.TODO()) {
+	for cur.Next(ctx) {
 		// Create a value into which the single document can be decoded
 		var elem bson.D
-		err := cur.Decode(&elem)
+		err = cur.Decode(&elem)
 		if err != nil {
 			return nil, err
 		}
@@ -183,15 +352,22 @@ func (db *MongoDB) FindAll(databaseName string, collectionName string) ([]bson.D
 }
 
 // FindOne retrieves a document by ID. Returns a bson.D
-func (db *MongoDB) FindOne(databaseName string, collectionName string, id string) (*bson.D, error) {
+func (db *MongoDB) FindOne(ctx context.Context, databaseName string, collectionName string, id string) (result *bson.D, err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_FIND, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
 	collection := db.Client.Database(databaseName).Collection(collectionName)
 
-	// get bson id filter
-	objid := db.filter(map[string]interface{}{_ID: id})
+	// ids are stored as ObjectIDs, so a malformed id matches no document
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	objid := db.filter(map[string]interface{}{_ID: objectID})
 
-	results := collection.FindOne(context.TODO(), objid)
+	results := collection.FindOne(ctx, objid)
 	var data bson.D
-	err := results.Decode(&data)
+	err = results.Decode(&data)
 	if err != nil {
 		db.lc.Errorf("failed to decode results: %v", err)
 		return nil, err
@@ -199,47 +375,146 @@ func (db *MongoDB) FindOne(databaseName string, collectionName string, id string
 	return &data, nil
 }
 
-// Update modifies a document given a ID. Returns a nil error when sucessful
-func (db *MongoDB) Update(databaseName string, collectionName string, id string, items map[string]interface{}) error {
+// FindMany retrieves the documents with the given IDs in no particular order. IDs matching no document are
+// skipped
+func (db *MongoDB) FindMany(ctx context.Context, databaseName string, collectionName string, ids []string) (results []bson.D, err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_FIND, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
 	collection := db.Client.Database(databaseName).Collection(collectionName)
 
-	setcommand := db.createUpdateSetCommand(items)
+	// like FindOne, a malformed id matches no document
+	objectIDs := bson.A{}
+	for _, id := range ids {
+		if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objectIDs = append(objectIDs, objectID)
+		}
+	}
 
-	objectID, err := primitive.ObjectIDFromHex(id)
+	cur, err := collection.Find(ctx, bson.D{{Key: _ID, Value: bson.D{{Key: "$in", Value: objectIDs}}}})
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, err
 	}
+	return results, nil
+}
+
+// FindByField retrieves the documents whose key equals value, or is an array containing it
+func (db *MongoDB) FindByField(ctx context.Context, databaseName string, collectionName string, key string, value interface{}) (results []bson.D, err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_FIND, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
+	collection := db.Client.Database(databaseName).Collection(collectionName)
 
-	res, err := collection.UpdateByID(context.TODO(), objectID, setcommand)
+	cur, err := collection.Find(ctx, bson.D{{Key: key, Value: value}})
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// FindByUser retrieves at most limit documents of user, newest first, starting after the cursor if it is not nil
+func (db *MongoDB) FindByUser(ctx context.Context, databaseName string, collectionName string, user string, after *interfaces.Cursor, limit int) (results []bson.D, err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_FIND, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
+	collection := db.Client.Database(databaseName).Collection(collectionName)
+
+	filter := bson.D{{Key: USER_INDEX_KEY, Value: user}}
+	if after != nil {
+		objectID, err := primitive.ObjectIDFromHex(after.Id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor id %v", after.Id)
+		}
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.D{{Key: CREATED_INDEX_KEY, Value: bson.D{{Key: "$lt", Value: after.Created}}}},
+			bson.D{{Key: CREATED_INDEX_KEY, Value: after.Created}, {Key: _ID, Value: bson.D{{Key: "$lt", Value: objectID}}}},
+		}})
 	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: CREATED_INDEX_KEY, Value: -1}, {Key: _ID, Value: -1}}).
+		SetLimit(int64(limit))
 
-	db.lc.Debugf("update count: %v\n", res.MatchedCount)
-	if res.MatchedCount == 0 {
-		return fmt.Errorf("document not found")
+	cur, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, err
 	}
+	return results, nil
+}
 
-	return nil
+// Update sets items on the document with ID and increments its version, returning the updated document.
+// The version must match the version of the document, zero matching a document without one
+func (db *MongoDB) Update(ctx context.Context, databaseName string, collectionName string, id string, version int64, items map[string]interface{}) (result *bson.D, err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_UPDATE, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
+	collection := db.Client.Database(databaseName).Collection(collectionName)
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		// like FindOne, a malformed id matches no document
+		return nil, mongo.ErrNoDocuments
+	}
+
+	filter := bson.D{{Key: _ID, Value: objectID}, {Key: VERSION_KEY, Value: version}}
+	if version == 0 {
+		// null also matches a document without a version
+		filter[1].Value = bson.D{{Key: "$in", Value: bson.A{version, nil}}}
+	}
+	update := append(db.createUpdateSetCommand(items), bson.E{Key: "$inc", Value: bson.D{{Key: VERSION_KEY, Value: int64(1)}}})
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var data bson.D
+	err = collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&data)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// the filter also fails to match a document at another version
+		count, countErr := collection.CountDocuments(ctx, bson.D{{Key: _ID, Value: objectID}}, options.Count().SetLimit(1))
+		if countErr != nil {
+			return nil, countErr
+		}
+		if count > 0 {
+			return nil, interfaces.ErrVersionMismatch
+		}
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, fmt.Errorf("%w: %v", interfaces.ErrDuplicateKey, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &data, nil
 }
 
-// Delete removes a document from the database. Returns nil error if successful
-func (db *MongoDB) Delete(databaseName string, collectionName string, id string) error {
+// Delete removes a document from the database. Returns mongo.ErrNoDocuments if there is no document with ID
+func (db *MongoDB) Delete(ctx context.Context, databaseName string, collectionName string, id string) (err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_DELETE, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
 	collection := db.Client.Database(databaseName).Collection(collectionName)
 
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return err
+		// like FindOne, a malformed id matches no document
+		return mongo.ErrNoDocuments
 	}
 	filter := db.filter(map[string]interface{}{_ID: objectID})
-	res, err := collection.DeleteOne(context.TODO(), filter)
+	res, err := collection.DeleteOne(ctx, filter)
 	if err != nil {
 		return err
 	}
 
 	db.lc.Debugf("deleted count: %v\n", res.DeletedCount)
 	if res.DeletedCount == 0 {
-		return fmt.Errorf("document not found")
+		return mongo.ErrNoDocuments
 	}
 	return nil
 }
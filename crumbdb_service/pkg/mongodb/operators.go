@@ -1,11 +1,16 @@
 package mongodb
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/haguru/horus/crumbdb/pkg/geo"
+)
 
 const (
 	POINT_TYPE_POLYGON       = "Polygon"
 	POINT_TYPE_MULTI_POLYGON = "MultiPolygon"
 	POINT_TYPE_POINT         = "Point"
+	POINT_TYPE_LINE_STRING   = "LineString"
 
 	OP_TYPE_GEO_INTERSECTS = "geoIntersects"
 	OP_TYPE_GEO_WITHIN     = "geoWithin"
@@ -56,31 +61,47 @@ type Point struct {
 	Coordinates []float64 `bson:"coordinates"`
 }
 
+// LineString is a GeoJSON line through two or more positions
+type LineString struct {
+	Type        string      `bson:"type"`
+	Coordinates [][]float64 `bson:"coordinates"`
+}
+
+// Polygon is a GeoJSON polygon made of a single closed ring
+type Polygon struct {
+	Type        string        `bson:"type"`
+	Coordinates [][][]float64 `bson:"coordinates"`
+}
+
 type GeometryOP struct {
-	Geometry    Point `bson:"$geometry"`
-	MaxDistance int   `bson:"$maxDistance,omitempty"`
-	MinDistance int   `bson:"$minDistance,omitempty"`
+	Geometry    interface{} `bson:"$geometry"`
+	MaxDistance int         `bson:"$maxDistance,omitempty"`
+	MinDistance int         `bson:"$minDistance,omitempty"`
 }
 
-// NewSpatialQueryCommand returns an interface containing the spatial query operators and error if opType/pointType is unsupported
+// NewSpatialQueryCommand returns an interface containing the spatial query operators and error if opType/pointType is unsupported.
+// The vertices of a Polygon are given as flat [longitude, latitude] pairs, see geo.Ring
 func NewSpatialQueryCommand(opType string, pointType string, coordinates []float64, maxDistance int, minDistance int) (interface{}, error) {
 	cmd := SpatialQueryCommand{}
 	geometryOp := GeometryOP{}
-	geometryOp.Geometry.Coordinates = coordinates
 	// verify point type is allowed
 	switch pointType {
 
 	case POINT_TYPE_POLYGON:
-		geometryOp.Geometry.Type = POINT_TYPE_POLYGON
+		ring, err := geo.Ring(coordinates)
+		if err != nil {
+			return nil, fmt.Errorf("invalid polygon: %v", err)
+		}
+		geometryOp.Geometry = Polygon{Type: POINT_TYPE_POLYGON, Coordinates: [][][]float64{ring}}
 
 	case POINT_TYPE_MULTI_POLYGON:
-		geometryOp.Geometry.Type = POINT_TYPE_MULTI_POLYGON
+		geometryOp.Geometry = Point{Type: POINT_TYPE_MULTI_POLYGON, Coordinates: coordinates}
 
 	case POINT_TYPE_POINT:
 		if opType == OP_TYPE_GEO_WITHIN {
 			return nil, fmt.Errorf("point type %v not supported", pointType)
 		}
-		geometryOp.Geometry.Type = POINT_TYPE_POINT
+		geometryOp.Geometry = Point{Type: POINT_TYPE_POINT, Coordinates: coordinates}
 	default:
 		return nil, fmt.Errorf("point type %v not supported", pointType)
 	}
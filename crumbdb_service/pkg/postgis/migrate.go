@@ -0,0 +1,98 @@
+package postgis
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MIGRATION_LOCK is the advisory lock held while migrating, so replicas starting together migrate once
+const MIGRATION_LOCK = 0x686f727573
+
+// MIGRATIONS are the schema migrations, named <version>_<description>.sql and applied in version order
+//
+//go:embed migrations/*.sql
+var MIGRATIONS embed.FS
+
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// migrations returns the embedded migrations sorted by version
+func migrations() ([]migration, error) {
+	entries, err := MIGRATIONS.ReadDir("migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %v", err)
+	}
+
+	var all []migration
+	versions := map[int]string{}
+	for _, entry := range entries {
+		prefix, _, found := strings.Cut(entry.Name(), "_")
+		version, err := strconv.Atoi(prefix)
+		if !found || err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration name %v", entry.Name())
+		}
+		if existing, ok := versions[version]; ok {
+			return nil, fmt.Errorf("migrations %v and %v have the same version", existing, entry.Name())
+		}
+		versions[version] = entry.Name()
+
+		data, err := MIGRATIONS.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %v: %v", entry.Name(), err)
+		}
+		all = append(all, migration{version: version, name: entry.Name(), sql: string(data)})
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].version < all[j].version })
+	return all, nil
+}
+
+// migrate applies the migrations newer than the schema version in a single transaction
+func (db *PostGIS) migrate(ctx context.Context) error {
+	all, err := migrations()
+	if err != nil {
+		return err
+	}
+
+	return pgx.BeginFunc(ctx, db.Pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", MIGRATION_LOCK); err != nil {
+			return fmt.Errorf("failed to lock migrations: %v", err)
+		}
+		_, err := tx.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version integer PRIMARY KEY,
+			applied_at timestamptz NOT NULL DEFAULT now()
+		)`)
+		if err != nil {
+			return fmt.Errorf("failed to create schema_migrations: %v", err)
+		}
+
+		var current int
+		if err := tx.QueryRow(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current); err != nil {
+			return fmt.Errorf("failed to get schema version: %v", err)
+		}
+
+		for _, m := range all {
+			if m.version <= current {
+				continue
+			}
+			db.lc.Infof("applying migration %v", m.name)
+			if _, err := tx.Exec(ctx, m.sql); err != nil {
+				return fmt.Errorf("failed to apply migration %v: %v", m.name, err)
+			}
+			if _, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", m.version); err != nil {
+				return fmt.Errorf("failed to record migration %v: %v", m.name, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,23 @@
+CREATE EXTENSION IF NOT EXISTS postgis;
+
+-- every collection shares the table, documents are the BSON stored by the other clients
+CREATE TABLE documents (
+    namespace text NOT NULL,
+    id bytea NOT NULL,
+    seq bigserial NOT NULL,
+    doc bytea NOT NULL,
+    location geography(Point, 4326),
+    PRIMARY KEY (namespace, id)
+);
+
+CREATE INDEX documents_namespace_seq_idx ON documents (namespace, seq);
+
+-- ST_DWithin and ST_Distance use the geography index, ST_Within the geometry one
+CREATE INDEX documents_location_idx ON documents USING GIST (location);
+CREATE INDEX documents_location_geometry_idx ON documents USING GIST ((location::geometry));
+
+-- collections on which CreateSpatialIndex was called, near queries fail on the others like in mongodb
+CREATE TABLE spatial_indexes (
+    namespace text PRIMARY KEY,
+    type text NOT NULL
+);
@@ -0,0 +1,5 @@
+-- the user and creation time of the documents, listed newest first by FindByUser. They are filled in when
+-- documents are inserted or updated, so documents older than the columns are listed once updated
+ALTER TABLE documents ADD COLUMN "user" text, ADD COLUMN created_at bigint;
+
+CREATE INDEX documents_namespace_user_created_at_idx ON documents (namespace, "user", created_at DESC, id DESC);
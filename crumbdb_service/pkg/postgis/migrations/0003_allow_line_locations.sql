@@ -0,0 +1,2 @@
+-- locations may be lines, the paths of trails, as well as points. The indexes on the column are rebuilt
+ALTER TABLE documents ALTER COLUMN location TYPE geography(Geometry, 4326);
@@ -0,0 +1,7 @@
+-- keys on which CreateUniqueIndex was called. The documents are only BSON, so the writers of a collection with
+-- unique keys hold an advisory lock on its namespace while comparing the document they write with the others
+CREATE TABLE unique_indexes (
+    namespace text NOT NULL,
+    key text NOT NULL,
+    PRIMARY KEY (namespace, key)
+);
@@ -0,0 +1,15 @@
+-- the values of the documents for the unique keys of their collection, as the BSON of the value. The primary
+-- key rejects a value another document of the collection holds, documents without the key have no row
+CREATE TABLE unique_values (
+    namespace text NOT NULL,
+    key text NOT NULL,
+    value bytea NOT NULL,
+    id bytea NOT NULL,
+    PRIMARY KEY (namespace, key, value),
+    FOREIGN KEY (namespace, id) REFERENCES documents (namespace, id) ON DELETE CASCADE
+);
+
+CREATE INDEX unique_values_namespace_id_idx ON unique_values (namespace, id);
+
+-- unique keys whose values were filled in from the documents written before them by CreateUniqueIndex
+ALTER TABLE unique_indexes ADD COLUMN filled boolean NOT NULL DEFAULT false;
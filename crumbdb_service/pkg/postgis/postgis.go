@@ -0,0 +1,623 @@
+package postgis
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/haguru/horus/crumbdb/pkg/document"
+	"github.com/haguru/horus/crumbdb/pkg/geo"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	SRID = 4326
+
+	// UNIQUE_VIOLATION is the SQLSTATE of a duplicate primary key
+	UNIQUE_VIOLATION = "23505"
+)
+
+// PostGIS is an implementation of interfaces.Client storing documents in PostgreSQL. Documents are kept as
+// BSON next to a geography column holding their location, a point or a line, so near queries map to ST_DWithin
+// ordered by ST_Distance, polygon queries to ST_Within and intersection queries to ST_Intersects
+type PostGIS struct {
+	DSN         string
+	Pool        *pgxpool.Pool
+	timeout     time.Duration
+	lc          logger.LoggingClient
+	maxDistance int
+	minDistance int
+	mu          sync.RWMutex
+}
+
+// NewPostGIS connects to the database at dsn and migrates its schema. Returns a interface for db client and
+// error if it occurs
+func NewPostGIS(dsn string, lc logger.LoggingClient, timeout time.Duration) (interfaces.Client, error) {
+	db := &PostGIS{
+		DSN:         dsn,
+		timeout:     timeout,
+		lc:          lc,
+		maxDistance: mongodb.MAX_DISTANCE,
+		minDistance: mongodb.MIN_DISTANCE,
+	}
+	err := db.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Connect opens the connection pool, pings the database and applies the pending migrations
+func (db *PostGIS) Connect() error {
+	ctx, cancel := context.WithTimeout(context.Background(), db.timeout)
+	defer cancel()
+
+	var err error
+	db.Pool, err = pgxpool.New(ctx, db.DSN)
+	if err != nil {
+		return fmt.Errorf("failed to parse postgres dsn: %v", err)
+	}
+
+	db.lc.Debug("pinging database")
+	if err := db.Pool.Ping(ctx); err != nil {
+		db.Pool.Close()
+		return fmt.Errorf("failed to successfully ping postgres server: %v", err)
+	}
+
+	if err := db.migrate(ctx); err != nil {
+		db.Pool.Close()
+		return err
+	}
+
+	db.lc.Debug("successfully connected to database")
+	return nil
+}
+
+// Ping returns error if postgres is unreachable
+func (db *PostGIS) Ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), db.timeout)
+	defer cancel()
+
+	return db.Pool.Ping(ctx)
+}
+
+// Disconnect closes the connection pool
+func (db *PostGIS) Disconnect(context.Context) error {
+	db.Pool.Close()
+	return nil
+}
+
+// CreateSpatialIndex enables near queries on the collection. The GiST indexes are created by the migrations
+// for every collection, so only 2dsphere indexes are accepted for compatibility with mongodb
+func (db *PostGIS) CreateSpatialIndex(ctx context.Context, databaseName string, collectionName string, spatialType string) error {
+	if spatialType != mongodb.SPATIAL_INDEX_TYPE {
+		return fmt.Errorf("index type %v not supported", spatialType)
+	}
+
+	_, err := db.Pool.Exec(ctx, `INSERT INTO spatial_indexes (namespace, type) VALUES ($1, $2)
+		ON CONFLICT (namespace) DO NOTHING`, namespace(databaseName, collectionName), spatialType)
+	return err
+}
+
+// CreateUserIndex does nothing, the index of FindByUser is created by the migrations for every collection
+func (db *PostGIS) CreateUserIndex(context.Context, string, string) error {
+	return nil
+}
+
+// CreateFieldIndex does nothing, FindByField scans the documents of the collection
+func (db *PostGIS) CreateFieldIndex(context.Context, string, string, string) error {
+	return nil
+}
+
+// CreateUniqueIndex records key in the unique indexes of the collection, see indexUnique. The values of the
+// documents written before it are filled in once, returning ErrDuplicateKey if two of them have the same value
+func (db *PostGIS) CreateUniqueIndex(ctx context.Context, databaseName string, collectionName string, key string) error {
+	name := namespace(databaseName, collectionName)
+
+	return pgx.BeginFunc(ctx, db.Pool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `INSERT INTO unique_indexes (namespace, key) VALUES ($1, $2)
+			ON CONFLICT (namespace, key) DO NOTHING`, name, key)
+		if err != nil {
+			return err
+		}
+		var filled bool
+		err = tx.QueryRow(ctx, `SELECT filled FROM unique_indexes WHERE namespace = $1 AND key = $2 FOR UPDATE`, name, key).Scan(&filled)
+		if err != nil || filled {
+			return err
+		}
+
+		// writers insert or update their document before reading the unique keys, so the ones in progress are
+		// waited for and the next ones see key
+		if _, err := tx.Exec(ctx, "LOCK TABLE documents IN SHARE MODE"); err != nil {
+			return fmt.Errorf("failed to lock documents: %v", err)
+		}
+		rows, err := tx.Query(ctx, `SELECT id, doc FROM documents WHERE namespace = $1`, name)
+		if err != nil {
+			return err
+		}
+		type stored struct {
+			Id  []byte
+			Doc []byte
+		}
+		docs, err := pgx.CollectRows(rows, pgx.RowToStructByPos[stored])
+		if err != nil {
+			return err
+		}
+		for _, d := range docs {
+			var doc bson.D
+			if err := bson.Unmarshal(d.Doc, &doc); err != nil {
+				return fmt.Errorf("failed to unmarshal document: %v", err)
+			}
+			value, found, err := uniqueValue(doc, key)
+			if err != nil {
+				return err
+			}
+			if !found {
+				continue
+			}
+
+			// a value already indexed by the same document, written since key was recorded, is no duplicate
+			var holder []byte
+			err = tx.QueryRow(ctx, `INSERT INTO unique_values (namespace, key, value, id) VALUES ($1, $2, $3, $4)
+				ON CONFLICT (namespace, key, value) DO UPDATE SET id = unique_values.id RETURNING id`,
+				name, key, value, d.Id).Scan(&holder)
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(holder, d.Id) {
+				return fmt.Errorf("%w: %v", interfaces.ErrDuplicateKey, key)
+			}
+		}
+
+		_, err = tx.Exec(ctx, `UPDATE unique_indexes SET filled = true WHERE namespace = $1 AND key = $2`, name, key)
+		return err
+	})
+}
+
+// InsertRecord returns ID, as string, and error.
+// if error occurs an empty string is returned along with the error
+func (db *PostGIS) InsertRecord(ctx context.Context, databaseName string, collectionName string, doc interface{}) (string, error) {
+	record, err := document.ToDocument(doc)
+	if err != nil {
+		return "", err
+	}
+
+	// like mongodb, a missing _id is generated and stored as the first field
+	var objId primitive.ObjectID
+	if value, found := document.Lookup(record, document.IDFIELD); found {
+		var ok bool
+		objId, ok = value.(primitive.ObjectID)
+		if !ok {
+			return "", fmt.Errorf("failed to get objectID")
+		}
+	} else {
+		objId = primitive.NewObjectID()
+		record = append(bson.D{{Key: document.IDFIELD, Value: objId}}, record...)
+	}
+
+	data, err := bson.Marshal(record)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal document: %v", err)
+	}
+
+	name := namespace(databaseName, collectionName)
+	user, created := author(record)
+	err = pgx.BeginFunc(ctx, db.Pool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `INSERT INTO documents (namespace, id, doc, location, "user", created_at)
+			VALUES ($1, $2, $3, ST_GeogFromText($4), $5, $6)`,
+			name, objId[:], data, location(record), user, created)
+		if err != nil {
+			return err
+		}
+		return indexUnique(ctx, tx, name, objId, record)
+	})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == UNIQUE_VIOLATION {
+		return "", fmt.Errorf("duplicate key error: %v", objId.Hex())
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return objId.Hex(), nil
+}
+
+// InsertMany inserts the documents one at a time, see interfaces.InsertEach
+func (db *PostGIS) InsertMany(ctx context.Context, databaseName string, collectionName string, docs []interface{}) ([]string, error) {
+	return interfaces.InsertEach(docs, func(doc interface{}) (string, error) {
+		return db.InsertRecord(ctx, databaseName, collectionName, doc)
+	})
+}
+
+// SpaitalQuery returns the documents within the distance limits of a Point, nearest first, or the documents
+// inside a Polygon in insertion order
+func (db *PostGIS) SpaitalQuery(ctx context.Context, pointType string, coordinates []float64, databaseName string, collectionName string) ([]bson.D, error) {
+	name := namespace(databaseName, collectionName)
+
+	switch pointType {
+	case mongodb.POINT_TYPE_POINT:
+		if !geo.Valid(coordinates) {
+			return nil, fmt.Errorf("failed to perforom spatial query: invalid point %v", coordinates)
+		}
+	case mongodb.POINT_TYPE_POLYGON:
+		ring, err := geo.Ring(coordinates)
+		if err != nil {
+			return nil, fmt.Errorf("failed to perforom spatial query: invalid polygon: %v", err)
+		}
+		// edges are straight lines in longitude and latitude, like geo.InRing
+		return db.query(ctx, `SELECT doc FROM documents WHERE namespace = $1 AND ST_Within(location::geometry, ST_GeomFromEWKT($2))
+			ORDER BY seq`, name, polygonWKT(ring))
+	default:
+		return nil, fmt.Errorf("failed to perforom spatial query: point type %v not supported", pointType)
+	}
+
+	// like mongodb, $near needs the index unless the collection is empty
+	var indexed, exists bool
+	err := db.Pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM spatial_indexes WHERE namespace = $1),
+		EXISTS (SELECT 1 FROM documents WHERE namespace = $1)`, name).Scan(&indexed, &exists)
+	if err != nil {
+		return nil, err
+	}
+	if !indexed {
+		if exists {
+			return nil, fmt.Errorf("unable to find index for $geoNear query")
+		}
+		return nil, nil
+	}
+
+	db.mu.RLock()
+	maxDistance, minDistance := db.maxDistance, db.minDistance
+	db.mu.RUnlock()
+
+	// distances are computed on a sphere like mongodb, a zero max distance does not limit the query
+	query := `SELECT doc FROM documents WHERE namespace = $1 AND ST_Distance(location, ST_GeogFromText($2), false) >= $3`
+	args := []interface{}{name, pointWKT(coordinates), float64(minDistance)}
+	if maxDistance > 0 {
+		query += ` AND ST_DWithin(location, ST_GeogFromText($2), $4, false)`
+		args = append(args, float64(maxDistance))
+	}
+	query += ` ORDER BY ST_Distance(location, ST_GeogFromText($2), false), seq`
+
+	return db.query(ctx, query, args...)
+}
+
+// SpatialIntersects returns the documents whose Point, LineString or Polygon location intersects a Point or a
+// Polygon in insertion order
+func (db *PostGIS) SpatialIntersects(ctx context.Context, databaseName string, collectionName string, pointType string, coordinates []float64) ([]bson.D, error) {
+	geometry, err := document.Geometry(pointType, coordinates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to perforom spatial query: %v", err)
+	}
+	wkt := pointWKT(geometry[0])
+	if pointType == mongodb.POINT_TYPE_POLYGON {
+		wkt = polygonWKT(geometry)
+	}
+
+	// like SpaitalQuery, edges are straight lines in longitude and latitude
+	return db.query(ctx, `SELECT doc FROM documents WHERE namespace = $1 AND ST_Intersects(location::geometry, ST_GeomFromEWKT($2))
+		ORDER BY seq`, namespace(databaseName, collectionName), wkt)
+}
+
+// CountIntersects returns the number of documents SpatialIntersects retrieves
+func (db *PostGIS) CountIntersects(ctx context.Context, databaseName string, collectionName string, pointType string, coordinates []float64) (int64, error) {
+	geometry, err := document.Geometry(pointType, coordinates)
+	if err != nil {
+		return 0, fmt.Errorf("failed to perforom spatial query: %v", err)
+	}
+	wkt := pointWKT(geometry[0])
+	if pointType == mongodb.POINT_TYPE_POLYGON {
+		wkt = polygonWKT(geometry)
+	}
+
+	var count int64
+	err = db.Pool.QueryRow(ctx, `SELECT count(*) FROM documents WHERE namespace = $1 AND ST_Intersects(location::geometry, ST_GeomFromEWKT($2))`,
+		namespace(databaseName, collectionName), wkt).Scan(&count)
+	return count, err
+}
+
+// SetDistanceLimits sets the distance bounds, in meters, used by SpaitalQuery
+func (db *PostGIS) SetDistanceLimits(minDistance int, maxDistance int) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.minDistance = minDistance
+	db.maxDistance = maxDistance
+}
+
+// FindAll retrieves all documents in the collection in insertion order
+func (db *PostGIS) FindAll(ctx context.Context, databaseName string, collectionName string) ([]bson.D, error) {
+	return db.query(ctx, `SELECT doc FROM documents WHERE namespace = $1 ORDER BY seq`, namespace(databaseName, collectionName))
+}
+
+// FindOne retrieves a document by ID. Returns mongo.ErrNoDocuments if there is none
+func (db *PostGIS) FindOne(ctx context.Context, databaseName string, collectionName string, id string) (*bson.D, error) {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	docs, err := db.query(ctx, `SELECT doc FROM documents WHERE namespace = $1 AND id = $2`, namespace(databaseName, collectionName), objId[:])
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &docs[0], nil
+}
+
+// FindMany retrieves the documents with the given IDs in insertion order. IDs matching no document are skipped
+func (db *PostGIS) FindMany(ctx context.Context, databaseName string, collectionName string, ids []string) ([]bson.D, error) {
+	// like FindOne, a malformed id matches no document
+	objIds := [][]byte{}
+	for _, id := range ids {
+		if objId, err := primitive.ObjectIDFromHex(id); err == nil {
+			objIds = append(objIds, objId[:])
+		}
+	}
+
+	return db.query(ctx, `SELECT doc FROM documents WHERE namespace = $1 AND id = ANY($2) ORDER BY seq`,
+		namespace(databaseName, collectionName), objIds)
+}
+
+// FindByField retrieves the documents whose key equals value, or is an array containing it, in insertion order
+func (db *PostGIS) FindByField(ctx context.Context, databaseName string, collectionName string, key string, value interface{}) ([]bson.D, error) {
+	docs, err := db.FindAll(ctx, databaseName, collectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	// fields other than the columns are only in the BSON, so they are compared here
+	var results []bson.D
+	for _, doc := range docs {
+		matches, err := document.Matches(doc, key, value)
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			results = append(results, doc)
+		}
+	}
+	return results, nil
+}
+
+// FindByUser retrieves at most limit documents of user, newest first, starting after the cursor if it is not nil
+func (db *PostGIS) FindByUser(ctx context.Context, databaseName string, collectionName string, user string, after *interfaces.Cursor, limit int) ([]bson.D, error) {
+	query := `SELECT doc FROM documents WHERE namespace = $1 AND "user" = $2`
+	args := []interface{}{namespace(databaseName, collectionName), user}
+	if after != nil {
+		objId, err := primitive.ObjectIDFromHex(after.Id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor id %v", after.Id)
+		}
+		query += ` AND (created_at, id) < ($3, $4)`
+		args = append(args, after.Created, objId[:])
+	}
+	query += ` ORDER BY created_at DESC, id DESC`
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	return db.query(ctx, query, args...)
+}
+
+// Update sets items on the document with ID and increments its version, returning the updated document.
+// The version must match the version of the document, zero matching a document without one
+func (db *PostGIS) Update(ctx context.Context, databaseName string, collectionName string, id string, version int64, items map[string]interface{}) (*bson.D, error) {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		// like FindOne, a malformed id matches no document
+		return nil, mongo.ErrNoDocuments
+	}
+	set, err := document.ToDocument(items)
+	if err != nil {
+		return nil, err
+	}
+	name := namespace(databaseName, collectionName)
+
+	var updated bson.D
+	err = pgx.BeginFunc(ctx, db.Pool, func(tx pgx.Tx) error {
+		var data []byte
+		err := tx.QueryRow(ctx, `SELECT doc FROM documents WHERE namespace = $1 AND id = $2 FOR UPDATE`, name, objId[:]).Scan(&data)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return mongo.ErrNoDocuments
+		}
+		if err != nil {
+			return err
+		}
+
+		var doc bson.D
+		if err := bson.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("failed to unmarshal document: %v", err)
+		}
+		updated, err = document.Update(doc, version, set)
+		if err != nil {
+			return err
+		}
+		data, err = bson.Marshal(updated)
+		if err != nil {
+			return fmt.Errorf("failed to marshal document: %v", err)
+		}
+
+		user, created := author(updated)
+		_, err = tx.Exec(ctx, `UPDATE documents SET doc = $3, location = ST_GeogFromText($4), "user" = $5, created_at = $6
+			WHERE namespace = $1 AND id = $2`,
+			name, objId[:], data, location(updated), user, created)
+		if err != nil {
+			return err
+		}
+
+		// the document releases its previous values, so it keeps the ones it does not change
+		_, err = tx.Exec(ctx, `DELETE FROM unique_values WHERE namespace = $1 AND id = $2`, name, objId[:])
+		if err != nil {
+			return err
+		}
+		return indexUnique(ctx, tx, name, objId, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// Delete removes the document with ID. Returns mongo.ErrNoDocuments if there is no document with ID
+func (db *PostGIS) Delete(ctx context.Context, databaseName string, collectionName string, id string) error {
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		// like FindOne, a malformed id matches no document
+		return mongo.ErrNoDocuments
+	}
+
+	res, err := db.Pool.Exec(ctx, `DELETE FROM documents WHERE namespace = $1 AND id = $2`, namespace(databaseName, collectionName), objId[:])
+	if err != nil {
+		return err
+	}
+
+	db.lc.Debugf("deleted count: %v\n", res.RowsAffected())
+	if res.RowsAffected() == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// indexUnique records the values of doc for the unique keys of the namespace, returning ErrDuplicateKey if
+// another document holds one of them. The primary key of unique_values rejects the value, so concurrent writers
+// of any replica wait for the one holding it rather than comparing documents
+func indexUnique(ctx context.Context, tx pgx.Tx, name string, objId primitive.ObjectID, doc bson.D) error {
+	rows, err := tx.Query(ctx, `SELECT key FROM unique_indexes WHERE namespace = $1`, name)
+	if err != nil {
+		return err
+	}
+	keys, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		value, found, err := uniqueValue(doc, key)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		tag, err := tx.Exec(ctx, `INSERT INTO unique_values (namespace, key, value, id) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (namespace, key, value) DO NOTHING`, name, key, value, objId[:])
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("%w: %v", interfaces.ErrDuplicateKey, key)
+		}
+	}
+	return nil
+}
+
+// uniqueValue returns the BSON of the value of doc for key, equal for the values document.SameValue matches
+func uniqueValue(doc bson.D, key string) ([]byte, bool, error) {
+	value, found := document.Lookup(doc, key)
+	if !found {
+		return nil, false, nil
+	}
+	data, err := bson.Marshal(bson.D{{Key: "value", Value: value}})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal the value of %v: %v", key, err)
+	}
+	return data, true, nil
+}
+
+// query returns the BSON documents of the single column rows of sql
+func (db *PostGIS) query(ctx context.Context, sql string, args ...interface{}) ([]bson.D, error) {
+	rows, err := db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return collectDocs(rows)
+}
+
+// collectDocs returns the BSON documents of single column rows
+func collectDocs(rows pgx.Rows) ([]bson.D, error) {
+	docs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (bson.D, error) {
+		var data []byte
+		if err := row.Scan(&data); err != nil {
+			return nil, err
+		}
+		var doc bson.D
+		if err := bson.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal document: %v", err)
+		}
+		return doc, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// location returns the EWKT of the point, line or polygon of doc, nil to store NULL if it has none
+func location(doc bson.D) interface{} {
+	if area, ok := document.Area(doc); ok {
+		return polygonWKT(area)
+	}
+	shape, ok := document.Shape(doc)
+	if !ok {
+		return nil
+	}
+	if len(shape) == 1 {
+		return pointWKT(shape[0])
+	}
+	return lineWKT(shape)
+}
+
+// author returns the user and creation time columns of doc, nil to store NULL if it has no user
+func author(doc bson.D) (interface{}, interface{}) {
+	user, created := document.Author(doc)
+	if user == "" {
+		return nil, nil
+	}
+	return user, created
+}
+
+func pointWKT(point []float64) string {
+	return fmt.Sprintf("SRID=%v;POINT(%v)", SRID, vertex(point))
+}
+
+func lineWKT(line [][]float64) string {
+	vertices := make([]string, 0, len(line))
+	for _, v := range line {
+		vertices = append(vertices, vertex(v))
+	}
+	return fmt.Sprintf("SRID=%v;LINESTRING(%v)", SRID, strings.Join(vertices, ", "))
+}
+
+func polygonWKT(ring [][]float64) string {
+	vertices := make([]string, 0, len(ring))
+	for _, v := range ring {
+		vertices = append(vertices, vertex(v))
+	}
+	return fmt.Sprintf("SRID=%v;POLYGON((%v))", SRID, strings.Join(vertices, ", "))
+}
+
+func vertex(coordinates []float64) string {
+	return strconv.FormatFloat(coordinates[0], 'f', -1, 64) + " " + strconv.FormatFloat(coordinates[1], 'f', -1, 64)
+}
+
+func namespace(databaseName string, collectionName string) string {
+	return databaseName + "." + collectionName
+}
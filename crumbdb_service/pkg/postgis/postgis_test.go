@@ -0,0 +1,113 @@
+package postgis
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haguru/horus/crumbdb/pkg/geo"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb/interfaces/conformance"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// POSTGIS_ENV is the dsn of the database the conformance suite runs against, the suite is skipped if unset.
+// make postgistest, run by make test, sets it to a postgis container started for the tests
+const POSTGIS_ENV = "HORUS_TEST_POSTGIS"
+
+func TestPostGIS_Conformance(t *testing.T) {
+	dsn := os.Getenv(POSTGIS_ENV)
+	if dsn == "" {
+		t.Skipf("%v is not set", POSTGIS_ENV)
+	}
+
+	db, err := NewPostGIS(dsn, logger.NewMockClient(), 5*time.Second)
+	if err != nil {
+		t.Fatalf("NewPostGIS() error = %v", err)
+	}
+	defer db.Disconnect(context.Background())
+
+	// migrating an up to date schema does nothing
+	if err := db.(*PostGIS).Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	conformance.Run(t, func(*testing.T) interfaces.Client { return db })
+}
+
+func TestMigrations(t *testing.T) {
+	all, err := migrations()
+	if err != nil {
+		t.Fatalf("migrations() error = %v", err)
+	}
+	if len(all) == 0 {
+		t.Fatalf("migrations() returned no migration")
+	}
+
+	for i, m := range all {
+		if m.version != i+1 {
+			t.Errorf("migration %v has version %v, want %v", m.name, m.version, i+1)
+		}
+		if strings.TrimSpace(m.sql) == "" {
+			t.Errorf("migration %v is empty", m.name)
+		}
+	}
+}
+
+func TestWKT(t *testing.T) {
+	if got, want := pointWKT([]float64{-122.4, 0.00001}), "SRID=4326;POINT(-122.4 0.00001)"; got != want {
+		t.Errorf("pointWKT() = %v, want %v", got, want)
+	}
+
+	ring, err := geo.Ring([]float64{0, 0, 1, 0, 1, 1})
+	if err != nil {
+		t.Fatalf("Ring() error = %v", err)
+	}
+	if got, want := polygonWKT(ring), "SRID=4326;POLYGON((0 0, 1 0, 1 1, 0 0))"; got != want {
+		t.Errorf("polygonWKT() = %v, want %v", got, want)
+	}
+}
+
+func TestUniqueValue(t *testing.T) {
+	doc := bson.D{{Key: "rule_id", Value: "harbor"}, {Key: "count", Value: int32(5)}, {Key: "zone", Value: bson.D{{Key: "id", Value: "plaza"}}}}
+
+	tests := []struct {
+		name      string
+		other     bson.D
+		key       string
+		wantFound bool
+		wantSame  bool
+	}{
+		{name: "same value", other: bson.D{{Key: "rule_id", Value: "harbor"}}, key: "rule_id", wantFound: true, wantSame: true},
+		{name: "other value", other: bson.D{{Key: "rule_id", Value: "park"}}, key: "rule_id", wantFound: true, wantSame: false},
+		{name: "other type", other: bson.D{{Key: "count", Value: int64(5)}}, key: "count", wantFound: true, wantSame: false},
+		{name: "document value", other: bson.D{{Key: "zone", Value: bson.D{{Key: "id", Value: "plaza"}}}}, key: "zone", wantFound: true, wantSame: true},
+		{name: "missing key", other: bson.D{{Key: "rule_id", Value: "harbor"}}, key: "name", wantFound: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, found, err := uniqueValue(doc, tt.key)
+			if err != nil {
+				t.Fatalf("uniqueValue() error = %v", err)
+			}
+			if found != tt.wantFound {
+				t.Fatalf("uniqueValue() found = %v, want %v", found, tt.wantFound)
+			}
+			if !found {
+				return
+			}
+			other, _, err := uniqueValue(tt.other, tt.key)
+			if err != nil {
+				t.Fatalf("uniqueValue() error = %v", err)
+			}
+			if got := bytes.Equal(value, other); got != tt.wantSame {
+				t.Errorf("same value = %v, want %v", got, tt.wantSame)
+			}
+		})
+	}
+}
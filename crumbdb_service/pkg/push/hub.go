@@ -0,0 +1,59 @@
+package push
+
+import (
+	"sync"
+
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+)
+
+// SUBSCRIPTION_BUFFER is the number of crumbs queued for a subscriber before new crumbs are dropped
+const SUBSCRIPTION_BUFFER = 64
+
+// Hub fans out the crumbs created through this replica to the connected subscribers
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[chan *pb.Crumb]struct{}
+	lc          logger.LoggingClient
+}
+
+// NewHub returns a Hub without subscribers
+func NewHub(lc logger.LoggingClient) *Hub {
+	return &Hub{
+		subscribers: map[chan *pb.Crumb]struct{}{},
+		lc:          lc,
+	}
+}
+
+// Publish queues crumb for every subscriber. Subscribers that are not keeping up miss the crumb
+func (h *Hub) Publish(crumb *pb.Crumb) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for subscriber := range h.subscribers {
+		select {
+		case subscriber <- crumb:
+		default:
+			h.lc.Warn("dropped crumb for a subscriber that is not keeping up", "id", crumb.GetId())
+		}
+	}
+}
+
+// Subscribe returns the channel receiving published crumbs until Unsubscribe is called with it
+func (h *Hub) Subscribe() chan *pb.Crumb {
+	subscriber := make(chan *pb.Crumb, SUBSCRIPTION_BUFFER)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subscribers[subscriber] = struct{}{}
+
+	return subscriber
+}
+
+// Unsubscribe stops delivering crumbs to subscriber
+func (h *Hub) Unsubscribe(subscriber chan *pb.Crumb) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subscribers, subscriber)
+}
@@ -0,0 +1,39 @@
+package push
+
+import (
+	"testing"
+
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+)
+
+func TestHub_Publish(t *testing.T) {
+	hub := NewHub(logger.NewMockClient())
+	first := hub.Subscribe()
+	second := hub.Subscribe()
+
+	hub.Publish(newCrumb("1", HERE))
+	for _, subscriber := range []chan *pb.Crumb{first, second} {
+		if got := (<-subscriber).GetId(); got != "1" {
+			t.Errorf("received crumb = %v, want 1", got)
+		}
+	}
+
+	hub.Unsubscribe(second)
+	hub.Publish(newCrumb("2", HERE))
+	if got := (<-first).GetId(); got != "2" {
+		t.Errorf("received crumb = %v, want 2", got)
+	}
+	if len(second) != 0 {
+		t.Errorf("unsubscribed channel received %v crumbs", len(second))
+	}
+
+	// a full subscriber does not block publishing
+	for i := 0; i < SUBSCRIPTION_BUFFER+1; i++ {
+		hub.Publish(newCrumb("3", HERE))
+	}
+	if len(first) != SUBSCRIPTION_BUFFER {
+		t.Errorf("subscriber queued %v crumbs, want %v", len(first), SUBSCRIPTION_BUFFER)
+	}
+}
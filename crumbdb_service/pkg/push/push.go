@@ -0,0 +1,392 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/haguru/horus/crumbdb/config"
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	"github.com/haguru/horus/crumbdb/pkg/geo"
+	"github.com/haguru/horus/crumbdb/pkg/geojson"
+	"github.com/haguru/horus/crumbdb/pkg/security"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	PUSH_ENDPOINT = "/v1/crumbs/ws"
+
+	BEARER_PREFIX = "Bearer "
+
+	// PARAM_ACCESS_TOKEN carries the token for clients that cannot set headers on the upgrade request, e.g. browsers
+	PARAM_ACCESS_TOKEN = "access_token"
+
+	DEFAULT_PING_INTERVAL = 30 * time.Second
+	WRITE_TIMEOUT         = 10 * time.Second
+
+	// CIRCLE_VERTICES is the number of vertices of the polygon the snapshot of a position is queried in
+	CIRCLE_VERTICES = 32
+)
+
+// Querier runs the spatial query used for the snapshot sent when a subscriber moves, and reveals the crumbs
+// created since then as GetCrumbs would return them. Both see the subscriber as the caller identified in the
+// context of the stream or ctx
+type Querier interface {
+	GetCrumbs(point *pb.Point, stream pb.CrumbDB_GetCrumbsServer) error
+	Reveal(ctx context.Context, crumb *pb.Crumb) (*pb.Crumb, bool)
+}
+
+// Position is the message subscribers send to set the area they receive crumbs for. Radius is in meters,
+// zero or values above the configured maximum select the maximum
+type Position struct {
+	Location *pb.Point `json:"location" validate:"required"`
+	Radius   float64   `json:"radius" validate:"gte=0"`
+}
+
+// Push serves the WebSocket endpoint streaming the crumbs near the position of a subscriber. After every
+// position update the subscriber receives the crumbs found by the spatial query it has not received yet,
+// followed by the crumbs created near it as they are published to the hub
+type Push struct {
+	config       *config.Push
+	hub          *Hub
+	querier      Querier
+	lc           logger.LoggingClient
+	tokens       []subscriberToken
+	pingInterval time.Duration
+	upgrader     websocket.Upgrader
+	validator    *validator.Validate
+}
+
+// subscriberToken is a token of the token file and the user it authenticates, empty for anonymous subscribers
+type subscriberToken struct {
+	user  string
+	token []byte
+}
+
+// NewPush returns a Push and error if the tokens or ping interval cannot be read
+func NewPush(config *config.Push, hub *Hub, querier Querier, lc logger.LoggingClient) (*Push, error) {
+	tokens, err := readTokens(config.TokenFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pingInterval := DEFAULT_PING_INTERVAL
+	if config.PingInterval != "" {
+		pingInterval, err = time.ParseDuration(config.PingInterval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ping interval: %v", err)
+		}
+	}
+
+	p := &Push{
+		config:       config,
+		hub:          hub,
+		querier:      querier,
+		lc:           lc,
+		tokens:       tokens,
+		pingInterval: pingInterval,
+		validator:    validator.New(),
+	}
+	p.upgrader = websocket.Upgrader{CheckOrigin: p.checkOrigin}
+
+	return p, nil
+}
+
+func (p *Push) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	identity, ok := p.authenticate(r)
+	if !ok {
+		p.lc.Warn("rejected push subscription", "peer", r.RemoteAddr)
+		w.Header().Set("WWW-Authenticate", `Bearer realm="push"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := p.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has already replied to the client
+		p.lc.Debugf("failed to upgrade push subscription: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	ctx := r.Context()
+	if identity != nil {
+		ctx = security.ContextWithIdentity(ctx, identity)
+	}
+	p.lc.Debug("push subscriber connected", "peer", r.RemoteAddr)
+	err = p.serve(ctx, conn)
+	if err != nil {
+		p.lc.Debug("push subscriber disconnected", "peer", r.RemoteAddr, "reason", err.Error())
+	}
+}
+
+// serve writes to conn until the subscriber goes away. Position updates are read on a separate goroutine
+// since gorilla/websocket supports one concurrent reader and one concurrent writer. The crumbs sent are
+// remembered, by their location, until a position update leaves them outside the radius
+func (p *Push) serve(ctx context.Context, conn *websocket.Conn) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	crumbs := p.hub.Subscribe()
+	defer p.hub.Unsubscribe(crumbs)
+
+	positions := make(chan *Position)
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- p.readPositions(ctx, conn, positions)
+	}()
+
+	ticker := time.NewTicker(p.pingInterval)
+	defer ticker.Stop()
+
+	var position *Position
+	sent := map[string]*pb.Point{}
+	for {
+		select {
+		case err := <-readErr:
+			return err
+		case <-ticker.C:
+			err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(WRITE_TIMEOUT))
+			if err != nil {
+				return fmt.Errorf("failed to send ping: %v", err)
+			}
+		case position = <-positions:
+			maps.DeleteFunc(sent, func(_ string, location *pb.Point) bool { return !within(location, position) })
+			snapshot, err := p.snapshot(ctx, position)
+			if err != nil {
+				closeWith(conn, websocket.CloseInternalServerErr, "failed to query crumbs")
+				return err
+			}
+			for _, crumb := range snapshot {
+				if err := p.send(conn, crumb, sent); err != nil {
+					return err
+				}
+			}
+		case crumb := <-crumbs:
+			if position == nil || !within(crumb.GetLocation(), position) {
+				continue
+			}
+			revealed, ok := p.querier.Reveal(ctx, crumb)
+			if !ok {
+				continue
+			}
+			if err := p.send(conn, revealed, sent); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// readPositions decodes the position updates of the subscriber and answers its pongs until the connection fails
+func (p *Push) readPositions(ctx context.Context, conn *websocket.Conn, positions chan<- *Position) error {
+	// a subscriber missing two pings in a row is gone
+	deadline := 2 * p.pingInterval
+	_ = conn.SetReadDeadline(time.Now().Add(deadline))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(deadline))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(deadline))
+
+		position, err := p.parsePosition(data)
+		if err != nil {
+			closeWith(conn, websocket.CloseUnsupportedData, err.Error())
+			return err
+		}
+
+		select {
+		case positions <- position:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (p *Push) parsePosition(data []byte) (*Position, error) {
+	position := &Position{}
+	err := json.Unmarshal(data, position)
+	if err != nil {
+		return nil, fmt.Errorf("invalid position: %v", err)
+	}
+
+	err = p.validator.Struct(position)
+	if err == nil {
+		location := position.Location
+		err = geojson.Validate("location", geojson.Geometry{
+			Type:        location.GetType(),
+			Coordinates: location.GetCoordinates(),
+			Altitude:    location.Altitude,
+			Accuracy:    location.Accuracy,
+		}, geojson.TYPE_POINT)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid position: %v", err)
+	}
+
+	if position.Radius == 0 || (p.config.MaxRadius > 0 && position.Radius > float64(p.config.MaxRadius)) {
+		position.Radius = float64(p.config.MaxRadius)
+	}
+
+	return position, nil
+}
+
+// snapshot returns the crumbs found by the spatial query within the radius of position. The query runs in the
+// polygon circumscribed to the circle of the radius, whose corners are then left out
+func (p *Push) snapshot(ctx context.Context, position *Position) ([]*pb.Crumb, error) {
+	stream := &snapshotStream{ctx: ctx}
+	radius := position.Radius / math.Cos(math.Pi/CIRCLE_VERTICES)
+	area := &pb.Point{
+		Type:        geojson.TYPE_POLYGON,
+		Coordinates: geo.Circle(position.Location.GetCoordinates(), radius, CIRCLE_VERTICES),
+	}
+	err := p.querier.GetCrumbs(area, stream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query crumbs: %v", err)
+	}
+
+	crumbs := make([]*pb.Crumb, 0, len(stream.crumbs))
+	for _, crumb := range stream.crumbs {
+		if within(crumb.GetLocation(), position) {
+			crumbs = append(crumbs, crumb)
+		}
+	}
+	return crumbs, nil
+}
+
+// send writes crumb to the subscriber unless it was sent before
+func (p *Push) send(conn *websocket.Conn, crumb *pb.Crumb, sent map[string]*pb.Point) error {
+	if _, ok := sent[crumb.GetId()]; ok {
+		return nil
+	}
+
+	data, err := protojson.Marshal(crumb)
+	if err != nil {
+		return fmt.Errorf("failed to marshal crumb: %v", err)
+	}
+
+	_ = conn.SetWriteDeadline(time.Now().Add(WRITE_TIMEOUT))
+	err = conn.WriteMessage(websocket.TextMessage, data)
+	if err != nil {
+		return fmt.Errorf("failed to send crumb: %v", err)
+	}
+	sent[crumb.GetId()] = crumb.GetLocation()
+
+	return nil
+}
+
+// authenticate returns the identity of the subscriber of r and true if it is authenticated, by the verified
+// client certificate of the request or by a token of the token file sent as bearer token or PARAM_ACCESS_TOKEN.
+// The identity is nil for the anonymous token
+func (p *Push) authenticate(r *http.Request) (*security.Identity, bool) {
+	if identity, ok := security.IdentityFromTLS(r.TLS); ok {
+		return identity, true
+	}
+
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), BEARER_PREFIX)
+	if !found {
+		token = r.URL.Query().Get(PARAM_ACCESS_TOKEN)
+	}
+	if token == "" {
+		return nil, false
+	}
+	for _, t := range p.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), t.token) != 1 {
+			continue
+		}
+		if t.user == "" {
+			return nil, true
+		}
+		return &security.Identity{Subject: t.user}, true
+	}
+	return nil, false
+}
+
+// readTokens returns the tokens of file, one per line preceded by the user it authenticates and a space. A
+// token alone on its line authenticates anonymous subscribers, who only receive public crumbs
+func readTokens(file string) ([]subscriberToken, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read push tokens: %v", err)
+	}
+
+	var tokens []subscriberToken
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		fields := strings.Fields(string(line))
+		switch len(fields) {
+		case 0:
+			continue
+		case 1:
+			tokens = append(tokens, subscriberToken{token: []byte(fields[0])})
+		case 2:
+			tokens = append(tokens, subscriberToken{user: fields[0], token: []byte(fields[1])})
+		default:
+			return nil, fmt.Errorf("invalid line in push token file %v, want a user and a token", file)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("push token file %v is empty", file)
+	}
+	return tokens, nil
+}
+
+// checkOrigin accepts requests without an Origin header, from the same host or from a configured origin
+func (p *Push) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	return slices.Contains(p.config.Origins, origin)
+}
+
+func closeWith(conn *websocket.Conn, code int, reason string) {
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(WRITE_TIMEOUT))
+}
+
+// within returns true if point lies inside the radius of position
+func within(point *pb.Point, position *Position) bool {
+	if len(point.GetCoordinates()) != 2 {
+		return false
+	}
+	return geo.Distance(point.GetCoordinates(), position.Location.GetCoordinates()) <= position.Radius
+}
+
+// snapshotStream collects the crumbs sent by Querier.GetCrumbs
+type snapshotStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	crumbs []*pb.Crumb
+}
+
+func (s *snapshotStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *snapshotStream) Send(crumb *pb.Crumb) error {
+	s.crumbs = append(s.crumbs, crumb)
+	return nil
+}
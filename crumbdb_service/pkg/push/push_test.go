@@ -0,0 +1,369 @@
+package push
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/haguru/horus/crumbdb/config"
+	pb "github.com/haguru/horus/crumbdb/internal/routes/protos"
+	"github.com/haguru/horus/crumbdb/pkg/geo"
+	"github.com/haguru/horus/crumbdb/pkg/security"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	TEST_TOKEN = "s3cret"
+	// USER_TOKEN authenticates user_1, the owner of the test crumbs
+	USER_TOKEN = "us3r"
+)
+
+var (
+	HERE = []float64{-122.4, 37.8}
+	// about 1.1km north of HERE
+	THERE = []float64{-122.4, 37.81}
+)
+
+type fakeQuerier struct {
+	crumbs  []*pb.Crumb
+	mu      sync.Mutex
+	queried []*pb.Point
+}
+
+// GetCrumbs returns all the crumbs the caller can see, wherever point is
+func (q *fakeQuerier) GetCrumbs(point *pb.Point, stream pb.CrumbDB_GetCrumbsServer) error {
+	q.mu.Lock()
+	q.queried = append(q.queried, point)
+	q.mu.Unlock()
+
+	for _, crumb := range q.crumbs {
+		revealed, ok := q.Reveal(stream.Context(), crumb)
+		if !ok {
+			continue
+		}
+		if err := stream.Send(revealed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reveal hides private crumbs and redacts locked ones from other users than their owner
+func (q *fakeQuerier) Reveal(ctx context.Context, crumb *pb.Crumb) (*pb.Crumb, bool) {
+	var caller string
+	if identity, ok := security.IdentityFromContext(ctx); ok {
+		caller = identity.Subject
+	}
+	if caller == crumb.GetUser() {
+		return crumb, true
+	}
+
+	if crumb.GetVisibility() == pb.Visibility_VISIBILITY_PRIVATE {
+		return nil, false
+	}
+	revealed := proto.Clone(crumb).(*pb.Crumb)
+	if revealed.GetLocked() {
+		revealed.Message = ""
+	}
+	return revealed, true
+}
+
+func (q *fakeQuerier) lastQuery() *pb.Point {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.queried[len(q.queried)-1]
+}
+
+func newCrumb(id string, coordinates []float64) *pb.Crumb {
+	return &pb.Crumb{Id: id, Location: &pb.Point{Type: "Point", Coordinates: coordinates}, User: "user_1", Message: "hi"}
+}
+
+func newTestServer(t *testing.T, pingInterval string, querier Querier) (*httptest.Server, *Hub) {
+	t.Helper()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte(TEST_TOKEN+"\nuser_1 "+USER_TOKEN+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	hub := NewHub(logger.NewMockClient())
+	p, err := NewPush(&config.Push{Enabled: true, TokenFile: tokenFile, PingInterval: pingInterval, MaxRadius: 500}, hub, querier, logger.NewMockClient())
+	if err != nil {
+		t.Fatalf("NewPush() error = %v", err)
+	}
+
+	server := httptest.NewServer(p)
+	t.Cleanup(server.Close)
+	return server, hub
+}
+
+func dial(t *testing.T, server *httptest.Server) *websocket.Conn {
+	t.Helper()
+	return dialWith(t, server, TEST_TOKEN)
+}
+
+func dialWith(t *testing.T, server *httptest.Server, token string) *websocket.Conn {
+	t.Helper()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + PUSH_ENDPOINT + "?" + PARAM_ACCESS_TOKEN + "=" + token
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func sendPosition(t *testing.T, conn *websocket.Conn, position string) {
+	t.Helper()
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(position)); err != nil {
+		t.Fatalf("WriteMessage() error = %v", err)
+	}
+}
+
+func readCrumb(t *testing.T, conn *websocket.Conn) *pb.Crumb {
+	t.Helper()
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	crumb := &pb.Crumb{}
+	if err := protojson.Unmarshal(data, crumb); err != nil {
+		t.Fatalf("failed to unmarshal crumb %s: %v", data, err)
+	}
+	return crumb
+}
+
+func TestPush_ServeHTTP(t *testing.T) {
+	querier := &fakeQuerier{crumbs: []*pb.Crumb{newCrumb("1", HERE), newCrumb("2", THERE)}}
+	server, hub := newTestServer(t, "", querier)
+	conn := dial(t, server)
+
+	sendPosition(t, conn, `{"location":{"type":"Point","coordinates":[-122.4,37.8]},"radius":200}`)
+	if got := readCrumb(t, conn).GetId(); got != "1" {
+		t.Fatalf("snapshot crumb = %v, want 1", got)
+	}
+
+	// the subscription is registered before the snapshot is sent
+	hub.Publish(newCrumb("3", THERE))
+	hub.Publish(newCrumb("4", HERE))
+	if got := readCrumb(t, conn).GetId(); got != "4" {
+		t.Fatalf("pushed crumb = %v, want 4", got)
+	}
+
+	// crumbs are pushed as the querier reveals them
+	private, locked := newCrumb("6", HERE), newCrumb("7", HERE)
+	private.Visibility = pb.Visibility_VISIBILITY_PRIVATE
+	locked.Locked = true
+	hub.Publish(private)
+	hub.Publish(locked)
+	if got := readCrumb(t, conn); got.GetId() != "7" || got.GetMessage() != "" {
+		t.Fatalf("pushed crumb = %v, want 7 without its message", got)
+	}
+
+	// moving sends the crumbs near the new position that were not sent before
+	sendPosition(t, conn, `{"location":{"type":"Point","coordinates":[-122.4,37.81]},"radius":5000}`)
+	if got := readCrumb(t, conn).GetId(); got != "2" {
+		t.Fatalf("snapshot crumb after moving = %v, want 2", got)
+	}
+	hub.Publish(newCrumb("5", THERE))
+	if got := readCrumb(t, conn).GetId(); got != "5" {
+		t.Fatalf("pushed crumb after moving = %v, want 5", got)
+	}
+
+	// the crumbs left outside the radius are forgotten, so coming back sends them again
+	sendPosition(t, conn, `{"location":{"type":"Point","coordinates":[-122.4,37.8]},"radius":200}`)
+	if got := readCrumb(t, conn).GetId(); got != "1" {
+		t.Fatalf("snapshot crumb after coming back = %v, want 1", got)
+	}
+}
+
+func TestPush_ServeHTTP_Snapshot(t *testing.T) {
+	querier := &fakeQuerier{crumbs: []*pb.Crumb{newCrumb("1", HERE)}}
+	server, _ := newTestServer(t, "", querier)
+	conn := dial(t, server)
+
+	sendPosition(t, conn, `{"location":{"type":"Point","coordinates":[-122.4,37.8]},"radius":200}`)
+	readCrumb(t, conn)
+
+	// the query covers the requested radius rather than the maximum distance of the database
+	area := querier.lastQuery()
+	if area.GetType() != "Polygon" || len(area.GetCoordinates()) != 2*CIRCLE_VERTICES {
+		t.Fatalf("queried %v, want a polygon of %v vertices", area, CIRCLE_VERTICES)
+	}
+	for i := 0; i < len(area.GetCoordinates()); i += 2 {
+		if distance := geo.Distance(area.GetCoordinates()[i:i+2], HERE); distance < 200 || distance > 205 {
+			t.Errorf("vertex %v is %v meters away, want just beyond the radius", i/2, distance)
+		}
+	}
+}
+
+func TestPush_ServeHTTP_Subscriber(t *testing.T) {
+	private := func(id string) *pb.Crumb {
+		crumb := newCrumb(id, HERE)
+		crumb.Visibility = pb.Visibility_VISIBILITY_PRIVATE
+		return crumb
+	}
+	tests := []struct {
+		name  string
+		token string
+		want  []string
+	}{
+		{name: "owner", token: USER_TOKEN, want: []string{"private", "public", "new private", "new public"}},
+		{name: "anonymous", token: TEST_TOKEN, want: []string{"public", "new public"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			querier := &fakeQuerier{crumbs: []*pb.Crumb{private("private"), newCrumb("public", HERE)}}
+			server, hub := newTestServer(t, "", querier)
+			conn := dialWith(t, server, tt.token)
+
+			sendPosition(t, conn, `{"location":{"type":"Point","coordinates":[-122.4,37.8]},"radius":200}`)
+			// each public crumb closes what the subscriber receives, before and after publishing
+			got := readUntil(t, conn, "public")
+			hub.Publish(private("new private"))
+			hub.Publish(newCrumb("new public", HERE))
+			got = append(got, readUntil(t, conn, "new public")...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("received crumbs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func readUntil(t *testing.T, conn *websocket.Conn, last string) []string {
+	t.Helper()
+
+	var ids []string
+	for len(ids) == 0 || ids[len(ids)-1] != last {
+		ids = append(ids, readCrumb(t, conn).GetId())
+	}
+	return ids
+}
+
+func TestReadTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []subscriberToken
+		wantErr bool
+	}{
+		{name: "anonymous token", content: "s3cret\n", want: []subscriberToken{{token: []byte("s3cret")}}},
+		{
+			name:    "user tokens",
+			content: "alice a1\n\nbob b2\n",
+			want:    []subscriberToken{{user: "alice", token: []byte("a1")}, {user: "bob", token: []byte("b2")}},
+		},
+		{name: "empty", content: "\n", wantErr: true},
+		{name: "too many fields", content: "alice a1 extra\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "tokens")
+			if err := os.WriteFile(file, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := readTokens(file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readTokens() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readTokens() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPush_ServeHTTP_Unauthorized(t *testing.T) {
+	server, _ := newTestServer(t, "", &fakeQuerier{})
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + PUSH_ENDPOINT
+
+	tests := []struct {
+		name   string
+		header http.Header
+	}{
+		{name: "no token", header: nil},
+		{name: "wrong token", header: http.Header{"Authorization": {BEARER_PREFIX + "guess"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, resp, err := websocket.DefaultDialer.Dial(url, tt.header)
+			if err == nil {
+				t.Fatal("Dial() error = nil, want unauthorized")
+			}
+			if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("Dial() response = %v, want %v", resp, http.StatusUnauthorized)
+			}
+		})
+	}
+}
+
+func TestPush_ServeHTTP_Heartbeat(t *testing.T) {
+	server, _ := newTestServer(t, "20ms", &fakeQuerier{})
+	conn := dial(t, server)
+
+	pinged := make(chan struct{}, 1)
+	conn.SetPingHandler(func(string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return conn.WriteControl(websocket.PongMessage, nil, time.Now().Add(time.Second))
+	})
+
+	// pings are handled while reading
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-pinged:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no ping received")
+	}
+}
+
+func TestPush_ServeHTTP_InvalidPosition(t *testing.T) {
+	server, _ := newTestServer(t, "", &fakeQuerier{})
+
+	tests := []struct {
+		name     string
+		position string
+	}{
+		{name: "not json", position: `here`},
+		{name: "missing location", position: `{"radius":10}`},
+		{name: "not a point", position: `{"location":{"type":"Polygon","coordinates":[1,2]}}`},
+		{name: "latitude out of range", position: `{"location":{"type":"Point","coordinates":[-122.4,97.8]}}`},
+		{name: "negative radius", position: `{"location":{"type":"Point","coordinates":[-122.4,37.8]},"radius":-1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := dial(t, server)
+			sendPosition(t, conn, tt.position)
+
+			_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			_, _, err := conn.ReadMessage()
+			if !websocket.IsCloseError(err, websocket.CloseUnsupportedData) {
+				t.Errorf("ReadMessage() error = %v, want close %v", err, websocket.CloseUnsupportedData)
+			}
+		})
+	}
+}
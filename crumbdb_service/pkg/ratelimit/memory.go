@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/haguru/horus/crumbdb/config"
+)
+
+// SWEEP_INTERVAL is how often full buckets are dropped from a MemoryStore
+const SWEEP_INTERVAL = time.Minute
+
+// MemoryStore keeps the token buckets of a single replica in memory
+type MemoryStore struct {
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+	mu        sync.Mutex
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// full is when the bucket is refilled, after which it is the same as a new bucket
+	full time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:   map[string]*bucket{},
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (m *MemoryStore) Take(_ context.Context, key string, limit config.Limit) (bool, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	size := burst(limit)
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: size, updated: now}
+		m.buckets[key] = b
+	}
+
+	b.tokens = math.Min(size, b.tokens+now.Sub(b.updated).Seconds()*limit.Rate)
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	b.full = now.Add(time.Duration((size - b.tokens) / limit.Rate * float64(time.Second)))
+
+	if !allowed {
+		return false, retryAfter(b.tokens, limit), nil
+	}
+	return true, 0, nil
+}
+
+// sweep drops the buckets that refilled so idle callers do not grow the store forever
+func (m *MemoryStore) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < SWEEP_INTERVAL {
+		return
+	}
+	m.lastSweep = now
+
+	for key, b := range m.buckets {
+		if !now.Before(b.full) {
+			delete(m.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/haguru/horus/crumbdb/config"
+	"github.com/haguru/horus/crumbdb/pkg/mongodb"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	FIELD_TOKENS  = "tokens"
+	FIELD_UPDATED = "updated"
+	FIELD_EXPIRES = "expires"
+	FIELD_ALLOWED = "allowed"
+)
+
+// MongoStore keeps the token buckets in a MongoDB collection so every replica shares the same limits.
+// Buckets are refilled using the server clock and removed by a TTL index once they are full
+type MongoStore struct {
+	db         *mongodb.MongoDB
+	collection *mongo.Collection
+}
+
+type mongoBucket struct {
+	Tokens  float64 `bson:"tokens"`
+	Allowed bool    `bson:"allowed"`
+}
+
+// NewMongoStore returns a MongoStore of the collection of db, whose commands are traced like those of db, and
+// error if the TTL index cannot be created
+func NewMongoStore(ctx context.Context, db *mongodb.MongoDB, databaseName string, collectionName string) (store *MongoStore, err error) {
+	collection := db.Client.Database(databaseName).Collection(collectionName)
+	ctx, end := db.Instrument(ctx, mongodb.OPERATION_CREATE_INDEX, databaseName, collectionName)
+	defer func() { end(err) }()
+
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: FIELD_EXPIRES, Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rate limit ttl index: %v", err)
+	}
+
+	return &MongoStore{db: db, collection: collection}, nil
+}
+
+func (m *MongoStore) Take(ctx context.Context, key string, limit config.Limit) (allowed bool, wait time.Duration, err error) {
+	ctx, end := m.db.Instrument(ctx, mongodb.OPERATION_UPDATE, m.collection.Database().Name(), m.collection.Name())
+	defer func() { end(err) }()
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	result := &mongoBucket{}
+	err = m.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, takePipeline(limit), opts).Decode(result)
+	if mongo.IsDuplicateKeyError(err) {
+		// a concurrent request created the bucket first, the retry updates it
+		err = m.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, takePipeline(limit), opts).Decode(result)
+	}
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to take token: %v", err)
+	}
+
+	if !result.Allowed {
+		return false, retryAfter(result.Tokens, limit), nil
+	}
+	return true, 0, nil
+}
+
+// takePipeline refills the bucket for the time since its last update, takes a token if one is available
+// and pushes the expiry to the time the bucket is full again
+func takePipeline(limit config.Limit) mongo.Pipeline {
+	size := burst(limit)
+	elapsed := bson.M{"$divide": bson.A{
+		bson.M{"$subtract": bson.A{"$$NOW", bson.M{"$ifNull": bson.A{"$" + FIELD_UPDATED, "$$NOW"}}}},
+		1000,
+	}}
+	refilled := bson.M{"$min": bson.A{
+		size,
+		bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$" + FIELD_TOKENS, size}}, bson.M{"$multiply": bson.A{elapsed, limit.Rate}}}},
+	}}
+	hasToken := bson.M{"$gte": bson.A{"$" + FIELD_TOKENS, 1}}
+	untilFull := bson.M{"$multiply": bson.A{
+		bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{size, "$" + FIELD_TOKENS}}, limit.Rate}},
+		1000,
+	}}
+
+	return mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{FIELD_TOKENS: refilled, FIELD_UPDATED: "$$NOW"}}},
+		{{Key: "$set", Value: bson.M{
+			FIELD_ALLOWED: hasToken,
+			FIELD_TOKENS:  bson.M{"$cond": bson.A{hasToken, bson.M{"$subtract": bson.A{"$" + FIELD_TOKENS, 1}}, "$" + FIELD_TOKENS}},
+		}}},
+		{{Key: "$set", Value: bson.M{FIELD_EXPIRES: bson.M{"$add": bson.A{"$$NOW", untilFull}}}}},
+	}
+}
@@ -0,0 +1,211 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/haguru/horus/crumbdb/config"
+	appMetrics "github.com/haguru/horus/crumbdb/pkg/prometheus"
+	"github.com/haguru/horus/crumbdb/pkg/security"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const CREATE_METHOD = "/crumbdb.CrumbDB/Create"
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, config.Limit) (bool, time.Duration, error) {
+	return false, 0, errors.New("connection refused")
+}
+
+func peerContext(ip string) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 5000}})
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	rateLimit := &config.RateLimit{
+		Enabled: true,
+		Default: config.Limit{Rate: 100, Burst: 100},
+		Methods: map[string]config.Limit{CREATE_METHOD: {Rate: 0.001, Burst: 2}},
+	}
+	tests := []struct {
+		name        string
+		config      *config.RateLimit
+		store       Store
+		method      string
+		ctx         context.Context
+		calls       int
+		wantAllowed int
+	}{
+		{
+			name:        "method limit",
+			config:      rateLimit,
+			method:      CREATE_METHOD,
+			ctx:         peerContext("10.0.0.1"),
+			calls:       5,
+			wantAllowed: 2,
+		},
+		{
+			name:        "default limit",
+			config:      rateLimit,
+			method:      "/crumbdb.CrumbDB/Update",
+			ctx:         peerContext("10.0.0.1"),
+			calls:       5,
+			wantAllowed: 5,
+		},
+		{
+			name:        "health checks are not limited",
+			config:      &config.RateLimit{Enabled: true, Default: config.Limit{Rate: 0.001, Burst: 1}},
+			method:      "/grpc.health.v1.Health/Check",
+			ctx:         peerContext("10.0.0.1"),
+			calls:       5,
+			wantAllowed: 5,
+		},
+		{
+			name:        "disabled",
+			config:      &config.RateLimit{Enabled: false, Default: config.Limit{Rate: 0.001, Burst: 1}},
+			method:      CREATE_METHOD,
+			ctx:         peerContext("10.0.0.1"),
+			calls:       5,
+			wantAllowed: 5,
+		},
+		{
+			name:        "store failure lets requests through",
+			config:      rateLimit,
+			store:       failingStore{},
+			method:      CREATE_METHOD,
+			ctx:         peerContext("10.0.0.1"),
+			calls:       5,
+			wantAllowed: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := tt.store
+			if store == nil {
+				store = NewMemoryStore()
+			}
+			metrics := appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"})
+			l := NewLimiter(tt.config, store, logger.NewMockClient(), metrics)
+
+			allowed := 0
+			for i := 0; i < tt.calls; i++ {
+				err := l.Allow(tt.ctx, tt.method)
+				if err == nil {
+					allowed++
+					continue
+				}
+				if status.Code(err) != codes.ResourceExhausted {
+					t.Fatalf("Allow() error = %v, want ResourceExhausted", err)
+				}
+			}
+
+			if allowed != tt.wantAllowed {
+				t.Errorf("Allow() allowed %v of %v calls, want %v", allowed, tt.calls, tt.wantAllowed)
+			}
+			if got := testutil.ToFloat64(metrics.RateLimited.WithLabelValues(tt.method)); got != float64(tt.calls-tt.wantAllowed) {
+				t.Errorf("RateLimited = %v, want %v", got, tt.calls-tt.wantAllowed)
+			}
+		})
+	}
+}
+
+func TestLimiter_Allow_RetryInfo(t *testing.T) {
+	rateLimit := &config.RateLimit{Enabled: true, Default: config.Limit{Rate: 0.5, Burst: 1}}
+	metrics := appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"})
+	l := NewLimiter(rateLimit, NewMemoryStore(), logger.NewMockClient(), metrics)
+
+	ctx := peerContext("10.0.0.1")
+	if err := l.Allow(ctx, CREATE_METHOD); err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	err := l.Allow(ctx, CREATE_METHOD)
+
+	var retryInfo *errdetails.RetryInfo
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			retryInfo = info
+		}
+	}
+	if retryInfo == nil {
+		t.Fatalf("Allow() error = %v, want RetryInfo", err)
+	}
+	if delay := retryInfo.GetRetryDelay().AsDuration(); delay <= time.Second || delay > 2*time.Second {
+		t.Errorf("RetryDelay = %v, want about 2s", delay)
+	}
+
+	// a different caller has its own bucket
+	if err := l.Allow(peerContext("10.0.0.2"), CREATE_METHOD); err != nil {
+		t.Errorf("Allow() for another peer error = %v", err)
+	}
+}
+
+func TestCallerKey(t *testing.T) {
+	type gatewayKey struct{}
+	// the gateway is recognized by its certificate, stood for here by a context value
+	isGateway := func(ctx context.Context) bool { return ctx.Value(gatewayKey{}) != nil }
+	fromGateway := func(ctx context.Context) context.Context { return context.WithValue(ctx, gatewayKey{}, true) }
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{name: "no caller", ctx: context.Background(), want: ""},
+		{name: "peer ip", ctx: peerContext("10.0.0.1"), want: "ip:10.0.0.1"},
+		{
+			name: "forwarded by the gateway",
+			ctx:  fromGateway(metadata.NewIncomingContext(peerContext("127.0.0.1"), metadata.Pairs("x-forwarded-for", "203.0.113.9, 198.51.100.7"))),
+			want: "ip:198.51.100.7",
+		},
+		{
+			name: "forwarded by the gateway over mutual tls",
+			ctx: fromGateway(security.ContextWithIdentity(metadata.NewIncomingContext(peerContext("127.0.0.1"), metadata.Pairs("x-forwarded-for", "198.51.100.7")),
+				&security.Identity{Subject: "crumbdb_service"})),
+			want: "ip:198.51.100.7",
+		},
+		{
+			name: "forwarded header from a remote peer is ignored",
+			ctx:  metadata.NewIncomingContext(peerContext("10.0.0.1"), metadata.Pairs("x-forwarded-for", "198.51.100.7")),
+			want: "ip:10.0.0.1",
+		},
+		{
+			name: "forwarded header from another local process is ignored",
+			ctx:  metadata.NewIncomingContext(peerContext("127.0.0.1"), metadata.Pairs("x-forwarded-for", "198.51.100.7")),
+			want: "ip:127.0.0.1",
+		},
+		{
+			name: "authenticated identity wins over a forged header",
+			ctx: security.ContextWithIdentity(metadata.NewIncomingContext(peerContext("127.0.0.1"), metadata.Pairs("x-forwarded-for", "198.51.100.7")),
+				&security.Identity{Subject: "sidecar"}),
+			want: "user:sidecar",
+		},
+		{
+			name: "spiffe identity",
+			ctx:  security.ContextWithIdentity(peerContext("10.0.0.1"), &security.Identity{SpiffeID: "spiffe://horus/gateway", Subject: "gateway"}),
+			want: "user:spiffe://horus/gateway",
+		},
+		{
+			name: "certificate subject",
+			ctx:  security.ContextWithIdentity(peerContext("10.0.0.1"), &security.Identity{Subject: "gateway"}),
+			want: "user:gateway",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CallerKey(tt.ctx, isGateway); got != tt.want {
+				t.Errorf("CallerKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package security
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	SPIFFE_SCHEME = "spiffe"
+
+	// METADATA_SUBJECT and METADATA_SPIFFE_ID carry the identity of the REST clients proxied by the gateway
+	METADATA_SUBJECT   = "x-horus-subject"
+	METADATA_SPIFFE_ID = "x-horus-spiffe-id"
+)
+
+// Identity describes the caller authenticated by its client certificate
+type Identity struct {
+	// SpiffeID is the spiffe:// URI SAN of the certificate, empty if it has none
+	SpiffeID string
+	// Subject is the common name of the certificate
+	Subject string
+}
+
+type identityKey struct{}
+
+// IdentityFromContext returns the identity stored by the identity interceptors
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok
+}
+
+// ContextWithIdentity returns a copy of ctx holding identity
+func ContextWithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromPeer extracts the identity from the verified client certificate of the connection
+func IdentityFromPeer(ctx context.Context) (*Identity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil, false
+	}
+
+	return IdentityFromTLS(&tlsInfo.State)
+}
+
+// IdentityFromTLS extracts the identity from the verified client certificate of a tls connection
+func IdentityFromTLS(state *tls.ConnectionState) (*Identity, bool) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+
+	return identityFromCert(state.VerifiedChains[0][0]), true
+}
+
+func identityFromCert(cert *x509.Certificate) *Identity {
+	identity := &Identity{
+		Subject: cert.Subject.CommonName,
+	}
+	for _, uri := range cert.URIs {
+		if uri.Scheme == SPIFFE_SCHEME {
+			identity.SpiffeID = uri.String()
+			break
+		}
+	}
+
+	return identity
+}
+
+// IdentityMetadata returns the metadata the gateway forwards the identity of a REST client in
+func IdentityMetadata(identity *Identity) metadata.MD {
+	md := metadata.Pairs(METADATA_SUBJECT, identity.Subject)
+	if identity.SpiffeID != "" {
+		md.Set(METADATA_SPIFFE_ID, identity.SpiffeID)
+	}
+	return md
+}
+
+// identityFromMetadata returns the identity forwarded in the incoming metadata of ctx. Metadata holding
+// several subjects or spiffe ids is ignored rather than trusting any one of them
+func identityFromMetadata(ctx context.Context) (*Identity, bool) {
+	subjects := metadata.ValueFromIncomingContext(ctx, METADATA_SUBJECT)
+	spiffeIDs := metadata.ValueFromIncomingContext(ctx, METADATA_SPIFFE_ID)
+	if len(subjects) != 1 || subjects[0] == "" || len(spiffeIDs) > 1 {
+		return nil, false
+	}
+
+	identity := &Identity{Subject: subjects[0]}
+	if len(spiffeIDs) == 1 {
+		identity.SpiffeID = spiffeIDs[0]
+	}
+	return identity, true
+}
+
+// callerIdentity returns the identity of the caller of ctx. Requests proxied by the REST gateway of the service,
+// recognized by isGateway, carry the identity of the REST client and are anonymous without one. The forwarded
+// identity of any other caller is ignored
+func callerIdentity(ctx context.Context, isGateway func(context.Context) bool) (*Identity, bool) {
+	if isGateway != nil && isGateway(ctx) {
+		return identityFromMetadata(ctx)
+	}
+	return IdentityFromPeer(ctx)
+}
+
+// UnaryServerInterceptor stores the identity of mTLS callers in the request context. isGateway reports whether
+// the caller is the REST gateway of the service, nil when there is none
+func UnaryServerInterceptor(isGateway func(context.Context) bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if identity, ok := callerIdentity(ctx, isGateway); ok {
+			ctx = ContextWithIdentity(ctx, identity)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor stores the identity of mTLS callers in the stream context. isGateway reports whether
+// the caller is the REST gateway of the service, nil when there is none
+func StreamServerInterceptor(isGateway func(context.Context) bool) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		identity, ok := callerIdentity(stream.Context(), isGateway)
+		if !ok {
+			return handler(srv, stream)
+		}
+
+		wrapped := middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = ContextWithIdentity(stream.Context(), identity)
+		return handler(srv, wrapped)
+	}
+}
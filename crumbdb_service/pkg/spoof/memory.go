@@ -0,0 +1,76 @@
+package spoof
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SWEEP_INTERVAL is how often expired histories are dropped from a MemoryStore
+const SWEEP_INTERVAL = time.Minute
+
+// MemoryStore keeps the position histories of a single replica in memory
+type MemoryStore struct {
+	histories map[string]*history
+	lastSweep time.Time
+	now       func() time.Time
+	mu        sync.Mutex
+}
+
+type history struct {
+	fixes   []Fix
+	expires time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		histories: map[string]*history{},
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (m *MemoryStore) History(_ context.Context, user string) ([]Fix, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, ok := m.histories[user]
+	if !ok || !m.now().Before(h.expires) {
+		return nil, nil
+	}
+	return append([]Fix(nil), h.fixes...), nil
+}
+
+func (m *MemoryStore) Add(_ context.Context, user string, fix Fix, size int, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	h, ok := m.histories[user]
+	if !ok || !now.Before(h.expires) {
+		h = &history{}
+		m.histories[user] = h
+	}
+	h.fixes = append(h.fixes, fix)
+	if len(h.fixes) > size {
+		h.fixes = append([]Fix(nil), h.fixes[len(h.fixes)-size:]...)
+	}
+	h.expires = now.Add(ttl)
+	return nil
+}
+
+// sweep drops the expired histories so users who stopped reporting positions do not grow the store forever
+func (m *MemoryStore) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < SWEEP_INTERVAL {
+		return
+	}
+	m.lastSweep = now
+
+	for user, h := range m.histories {
+		if !now.Before(h.expires) {
+			delete(m.histories, user)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package spoof
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMemoryStore()
+	m.now = func() time.Time { return now }
+
+	for i := 0; i < 4; i++ {
+		if err := m.Add(context.Background(), "bob", Fix{Coordinates: []float64{float64(i), 0}, Time: now}, 3, time.Hour); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	fixes, err := m.History(context.Background(), "bob")
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(fixes) != 3 || fixes[0].Coordinates[0] != 1 || fixes[2].Coordinates[0] != 3 {
+		t.Errorf("History() = %v, want the last 3 fixes oldest first", fixes)
+	}
+
+	// the history expires ttl after the last fix
+	now = now.Add(time.Hour)
+	if fixes, _ := m.History(context.Background(), "bob"); len(fixes) != 0 {
+		t.Errorf("History() = %v, want none once expired", fixes)
+	}
+	if err := m.Add(context.Background(), "bob", Fix{Coordinates: []float64{4, 0}, Time: now}, 3, time.Hour); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if fixes, _ := m.History(context.Background(), "bob"); len(fixes) != 1 {
+		t.Errorf("History() = %v, want only the fix added after it expired", fixes)
+	}
+}
+
+func TestMemoryStore_sweep(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMemoryStore()
+	m.lastSweep = now
+	m.now = func() time.Time { return now }
+
+	if err := m.Add(context.Background(), "idle", Fix{Coordinates: []float64{0, 0}, Time: now}, 3, time.Second); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	now = now.Add(SWEEP_INTERVAL)
+	if err := m.Add(context.Background(), "active", Fix{Coordinates: []float64{0, 0}, Time: now}, 3, time.Hour); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if _, ok := m.histories["idle"]; ok {
+		t.Error("expired history was not swept")
+	}
+	if _, ok := m.histories["active"]; !ok {
+		t.Error("active history was swept")
+	}
+}
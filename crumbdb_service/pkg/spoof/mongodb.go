@@ -0,0 +1,82 @@
+package spoof
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/haguru/horus/crumbdb/pkg/mongodb"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	FIELD_FIXES   = "fixes"
+	FIELD_EXPIRES = "expires"
+)
+
+// MongoStore keeps the position histories in a MongoDB collection so every replica checks against the same
+// history. A history is a document per user removed by a TTL index once it expires
+type MongoStore struct {
+	db         *mongodb.MongoDB
+	collection *mongo.Collection
+	now        func() time.Time
+}
+
+type mongoHistory struct {
+	Fixes []Fix `bson:"fixes"`
+}
+
+// NewMongoStore returns a MongoStore of the collection of db, whose commands are traced like those of db, and
+// error if the TTL index cannot be created
+func NewMongoStore(ctx context.Context, db *mongodb.MongoDB, databaseName string, collectionName string) (store *MongoStore, err error) {
+	collection := db.Client.Database(databaseName).Collection(collectionName)
+	ctx, end := db.Instrument(ctx, mongodb.OPERATION_CREATE_INDEX, databaseName, collectionName)
+	defer func() { end(err) }()
+
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: FIELD_EXPIRES, Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create position history ttl index: %v", err)
+	}
+
+	return &MongoStore{db: db, collection: collection, now: time.Now}, nil
+}
+
+func (m *MongoStore) History(ctx context.Context, user string) (fixes []Fix, err error) {
+	ctx, end := m.db.Instrument(ctx, mongodb.OPERATION_FIND, m.collection.Database().Name(), m.collection.Name())
+	defer func() { end(err) }()
+
+	// the TTL monitor runs periodically so expired histories may still be found
+	filter := bson.M{"_id": user, FIELD_EXPIRES: bson.M{"$gt": m.now()}}
+
+	result := &mongoHistory{}
+	err = m.collection.FindOne(ctx, filter).Decode(result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find position history: %v", err)
+	}
+	return result.Fixes, nil
+}
+
+func (m *MongoStore) Add(ctx context.Context, user string, fix Fix, size int, ttl time.Duration) (err error) {
+	ctx, end := m.db.Instrument(ctx, mongodb.OPERATION_UPDATE, m.collection.Database().Name(), m.collection.Name())
+	defer func() { end(err) }()
+
+	update := bson.M{
+		"$push": bson.M{FIELD_FIXES: bson.M{"$each": bson.A{fix}, "$slice": -size}},
+		"$set":  bson.M{FIELD_EXPIRES: m.now().Add(ttl)},
+	}
+	_, err = m.collection.UpdateOne(ctx, bson.M{"_id": user}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to add position: %v", err)
+	}
+	return nil
+}
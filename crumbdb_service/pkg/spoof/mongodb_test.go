@@ -0,0 +1,77 @@
+package spoof
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/haguru/horus/crumbdb/pkg/mongodb"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestMongoStore_History(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		responses []bson.D
+		wantFixes int
+		wantErr   bool
+	}{
+		{
+			name: "history",
+			responses: []bson.D{mtest.CreateCursorResponse(0, "test.positions", mtest.FirstBatch, bson.D{
+				{Key: "_id", Value: "bob"},
+				{Key: FIELD_FIXES, Value: bson.A{
+					bson.D{{Key: "coordinates", Value: bson.A{-122.4, 37.8}}, {Key: "time", Value: now}},
+					bson.D{{Key: "coordinates", Value: bson.A{-122.4, 37.801}}, {Key: "accuracy", Value: 5.0}, {Key: "time", Value: now}},
+				}},
+			})},
+			wantFixes: 2,
+		},
+		{
+			name:      "no history",
+			responses: []bson.D{mtest.CreateCursorResponse(0, "test.positions", mtest.FirstBatch)},
+		},
+		{
+			name:      "failure",
+			responses: []bson.D{mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "failed"})},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(tt.responses...)
+			m := &MongoStore{db: &mongodb.MongoDB{Client: mt.Client}, collection: mt.Coll, now: func() time.Time { return now }}
+
+			fixes, err := m.History(context.Background(), "bob")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("History() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(fixes) != tt.wantFixes {
+				t.Errorf("History() = %v, want %v fixes", fixes, tt.wantFixes)
+			}
+		})
+	}
+}
+
+func TestMongoStore_Add(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("add", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "upserted", Value: bson.A{bson.D{{Key: "index", Value: 0}, {Key: "_id", Value: "bob"}}}}))
+		m := &MongoStore{db: &mongodb.MongoDB{Client: mt.Client}, collection: mt.Coll, now: time.Now}
+
+		if err := m.Add(context.Background(), "bob", Fix{Coordinates: []float64{-122.4, 37.8}, Time: time.Now()}, 10, time.Hour); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("u").Document()
+		if slice := update.Lookup("$push", FIELD_FIXES, "$slice").Int32(); slice != -10 {
+			t.Errorf("$slice = %v, want -10", slice)
+		}
+	})
+}
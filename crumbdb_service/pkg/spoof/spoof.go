@@ -0,0 +1,198 @@
+package spoof
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haguru/horus/crumbdb/config"
+	"github.com/haguru/horus/crumbdb/pkg/geo"
+	appMetrics "github.com/haguru/horus/crumbdb/pkg/prometheus"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	BACKEND_MEMORY  = "memory"
+	BACKEND_MONGODB = "mongodb"
+
+	MODE_REJECT = "reject"
+	MODE_FLAG   = "flag"
+
+	DEFAULT_COLLECTION   = "positions"
+	DEFAULT_HISTORY_SIZE = 10
+	DEFAULT_HISTORY_TTL  = time.Hour
+
+	REASON_SPEED    = "speed"
+	REASON_ACCURACY = "accuracy"
+	REASON_REPEAT   = "repeat"
+
+	RESULT_ACCEPTED = "accepted"
+	RESULT_FLAGGED  = "flagged"
+	RESULT_REJECTED = "rejected"
+)
+
+// Fix is a position reported by a user. Accuracy is the radius in meters of its uncertainty, nil if unknown
+type Fix struct {
+	Coordinates []float64 `bson:"coordinates"`
+	Accuracy    *float64  `bson:"accuracy,omitempty"`
+	Time        time.Time `bson:"time"`
+}
+
+// Store keeps the recent fixes of each user. History returns them oldest first and Add appends fix to them,
+// keeping the last size fixes for ttl
+type Store interface {
+	History(ctx context.Context, user string) ([]Fix, error)
+	Add(ctx context.Context, user string, fix Fix, size int, ttl time.Duration) error
+}
+
+// Detector checks the positions reported by users against their recent history
+type Detector struct {
+	config  *config.Spoofing
+	store   Store
+	ttl     time.Duration
+	lc      logger.LoggingClient
+	metrics *appMetrics.Metrics
+	now     func() time.Time
+}
+
+// NewDetector returns a Detector applying the thresholds in config with the history kept in store, and error if
+// the history ttl cannot be parsed
+func NewDetector(config *config.Spoofing, store Store, lc logger.LoggingClient, metrics *appMetrics.Metrics) (*Detector, error) {
+	ttl := DEFAULT_HISTORY_TTL
+	if config.HistoryTTL != "" {
+		var err error
+		ttl, err = time.ParseDuration(config.HistoryTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse spoofing history ttl: %v", err)
+		}
+	}
+
+	return &Detector{
+		config:  config,
+		store:   store,
+		ttl:     ttl,
+		lc:      lc,
+		metrics: metrics,
+		now:     time.Now,
+	}, nil
+}
+
+// Check returns a FailedPrecondition status if the position user reported to method, with accuracy if known, is
+// suspicious and the mode is reject. Accepted and flagged positions are added to the history of user. Anonymous
+// positions are only checked for their accuracy. Positions are accepted when the store fails so an outage of a
+// shared backend does not take the service down
+func (d *Detector) Check(ctx context.Context, method string, user string, coordinates []float64, accuracy *float64) error {
+	if !d.config.Enabled || len(coordinates) != 2 {
+		return nil
+	}
+
+	fix := Fix{Coordinates: coordinates, Accuracy: accuracy, Time: d.now()}
+	var history []Fix
+	if user != "" {
+		var err error
+		history, err = d.store.History(ctx, user)
+		if err != nil {
+			d.lc.Errorf("failed to read the position history of %v: %v", user, err)
+			return nil
+		}
+	}
+
+	reasons := d.reasons(fix, history)
+	for _, reason := range reasons {
+		appMetrics.IncWithExemplar(ctx, d.metrics.SpoofSignals.WithLabelValues(method, reason))
+	}
+
+	result := RESULT_ACCEPTED
+	switch {
+	case len(reasons) > 0 && d.config.Mode == MODE_FLAG:
+		result = RESULT_FLAGGED
+	case len(reasons) > 0:
+		result = RESULT_REJECTED
+	}
+	appMetrics.IncWithExemplar(ctx, d.metrics.PositionChecks.WithLabelValues(method, result))
+
+	if result == RESULT_REJECTED {
+		d.lc.Warnf("rejected the position of %v reported to %v: %v", user, method, strings.Join(reasons, ", "))
+		return status.Errorf(codes.FailedPrecondition, "position rejected as implausible: %v", strings.Join(reasons, ", "))
+	}
+	if result == RESULT_FLAGGED {
+		d.lc.Warnf("flagged the position of %v reported to %v: %v", user, method, strings.Join(reasons, ", "))
+	}
+
+	if user != "" {
+		if err := d.store.Add(ctx, user, fix, d.historySize(), d.ttl); err != nil {
+			d.lc.Errorf("failed to record the position of %v: %v", user, err)
+		}
+	}
+	return nil
+}
+
+// reasons returns why fix is suspicious given the history of its user, none if it is not
+func (d *Detector) reasons(fix Fix, history []Fix) []string {
+	var reasons []string
+	if len(history) > 0 && d.config.MaxSpeed > 0 && speed(history[len(history)-1], fix) > d.config.MaxSpeed {
+		reasons = append(reasons, REASON_SPEED)
+	}
+	if fix.Accuracy != nil && (*fix.Accuracy < d.config.MinAccuracy || d.config.MaxAccuracy > 0 && *fix.Accuracy > d.config.MaxAccuracy) {
+		reasons = append(reasons, REASON_ACCURACY)
+	}
+	if d.config.MaxRepeats > 0 && repeats(fix, history, d.config.RepeatDecimals) >= d.config.MaxRepeats {
+		reasons = append(reasons, REASON_REPEAT)
+	}
+	return reasons
+}
+
+func (d *Detector) historySize() int {
+	if d.config.HistorySize == 0 {
+		return DEFAULT_HISTORY_SIZE
+	}
+	return d.config.HistorySize
+}
+
+// speed returns the speed, in meters per second, needed to travel from the fix from to the fix to. The distance is
+// shortened by the accuracy of both fixes and the time is at least a second
+func speed(from Fix, to Fix) float64 {
+	distance := geo.Distance(from.Coordinates, to.Coordinates)
+	for _, accuracy := range []*float64{from.Accuracy, to.Accuracy} {
+		if accuracy != nil {
+			distance -= *accuracy
+		}
+	}
+	if distance <= 0 {
+		return 0
+	}
+	return distance / math.Max(to.Time.Sub(from.Time).Seconds(), 1)
+}
+
+// repeats returns how many fixes of history have the coordinates of fix, zero if they have fewer than decimals
+// decimals since rounded coordinates repeat naturally
+func repeats(fix Fix, history []Fix, decimals int) int {
+	for _, coordinate := range fix.Coordinates {
+		if countDecimals(coordinate) < decimals {
+			return 0
+		}
+	}
+
+	count := 0
+	for _, previous := range history {
+		if len(previous.Coordinates) == 2 && previous.Coordinates[0] == fix.Coordinates[0] && previous.Coordinates[1] == fix.Coordinates[1] {
+			count++
+		}
+	}
+	return count
+}
+
+// countDecimals returns the number of decimals of the shortest representation of value
+func countDecimals(value float64) int {
+	formatted := strconv.FormatFloat(value, 'f', -1, 64)
+	if i := strings.IndexByte(formatted, '.'); i >= 0 {
+		return len(formatted) - i - 1
+	}
+	return 0
+}
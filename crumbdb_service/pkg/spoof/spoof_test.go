@@ -0,0 +1,236 @@
+package spoof
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/haguru/horus/crumbdb/config"
+	appMetrics "github.com/haguru/horus/crumbdb/pkg/prometheus"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const METHOD = "/crumbdb.CrumbDB/Create"
+
+// failingStore fails every read and write
+type failingStore struct{}
+
+func (failingStore) History(context.Context, string) ([]Fix, error) {
+	return nil, errors.New("failed")
+}
+
+func (failingStore) Add(context.Context, string, Fix, int, time.Duration) error {
+	return errors.New("failed")
+}
+
+func accuracy(meters float64) *float64 {
+	return &meters
+}
+
+func newTestDetector(t *testing.T, mode string, store Store) (*Detector, *time.Time) {
+	t.Helper()
+
+	spoofing := &config.Spoofing{
+		Enabled:        true,
+		Mode:           mode,
+		HistorySize:    3,
+		MaxSpeed:       100,
+		MinAccuracy:    1,
+		MaxAccuracy:    1000,
+		RepeatDecimals: 6,
+		MaxRepeats:     2,
+	}
+	d, err := NewDetector(spoofing, store, logger.NewMockClient(), appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"}))
+	if err != nil {
+		t.Fatalf("NewDetector() error = %v", err)
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	return d, &now
+}
+
+func TestDetector_Check(t *testing.T) {
+	type report struct {
+		advance     time.Duration
+		user        string
+		coordinates []float64
+		accuracy    *float64
+	}
+	tests := []struct {
+		name       string
+		mode       string
+		history    []report
+		report     report
+		wantReason string
+		wantCode   codes.Code
+	}{
+		{
+			name:    "walking",
+			mode:    MODE_REJECT,
+			history: []report{{user: "bob", coordinates: []float64{-122.4, 37.8}}},
+			report:  report{advance: time.Minute, user: "bob", coordinates: []float64{-122.4, 37.801}},
+		},
+		{
+			name:       "impossible speed",
+			mode:       MODE_REJECT,
+			history:    []report{{user: "bob", coordinates: []float64{-122.4, 37.8}}},
+			report:     report{advance: time.Minute, user: "bob", coordinates: []float64{2.35, 48.85}},
+			wantReason: REASON_SPEED,
+			wantCode:   codes.FailedPrecondition,
+		},
+		{
+			name:       "impossible speed flagged",
+			mode:       MODE_FLAG,
+			history:    []report{{user: "bob", coordinates: []float64{-122.4, 37.8}}},
+			report:     report{advance: time.Minute, user: "bob", coordinates: []float64{2.35, 48.85}},
+			wantReason: REASON_SPEED,
+		},
+		{
+			// 150 meters in a second is within the uncertainty of both positions
+			name:    "jump within the accuracy",
+			mode:    MODE_REJECT,
+			history: []report{{user: "bob", coordinates: []float64{-122.4, 37.8}, accuracy: accuracy(100)}},
+			report:  report{user: "bob", coordinates: []float64{-122.4, 37.80135}, accuracy: accuracy(100)},
+		},
+		{
+			name:    "positions of another user",
+			mode:    MODE_REJECT,
+			history: []report{{user: "carol", coordinates: []float64{2.35, 48.85}}},
+			report:  report{advance: time.Minute, user: "bob", coordinates: []float64{-122.4, 37.8}},
+		},
+		{
+			name:       "accuracy too precise",
+			mode:       MODE_REJECT,
+			report:     report{coordinates: []float64{-122.4, 37.8}, accuracy: accuracy(0.01)},
+			wantReason: REASON_ACCURACY,
+			wantCode:   codes.FailedPrecondition,
+		},
+		{
+			name:       "accuracy too coarse",
+			mode:       MODE_REJECT,
+			report:     report{user: "bob", coordinates: []float64{-122.4, 37.8}, accuracy: accuracy(5000)},
+			wantReason: REASON_ACCURACY,
+			wantCode:   codes.FailedPrecondition,
+		},
+		{
+			name: "precise coordinates repeated",
+			mode: MODE_REJECT,
+			history: []report{
+				{user: "bob", coordinates: []float64{-122.419416, 37.774929}},
+				{advance: time.Minute, user: "bob", coordinates: []float64{-122.419416, 37.774929}},
+			},
+			report:     report{advance: time.Minute, user: "bob", coordinates: []float64{-122.419416, 37.774929}},
+			wantReason: REASON_REPEAT,
+			wantCode:   codes.FailedPrecondition,
+		},
+		{
+			name: "rounded coordinates repeated",
+			mode: MODE_REJECT,
+			history: []report{
+				{user: "bob", coordinates: []float64{-122.4, 37.8}},
+				{advance: time.Minute, user: "bob", coordinates: []float64{-122.4, 37.8}},
+			},
+			report: report{advance: time.Minute, user: "bob", coordinates: []float64{-122.4, 37.8}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, now := newTestDetector(t, tt.mode, NewMemoryStore())
+			for _, r := range tt.history {
+				*now = now.Add(r.advance)
+				if err := d.Check(context.Background(), METHOD, r.user, r.coordinates, r.accuracy); err != nil {
+					t.Fatalf("Check() of the history error = %v", err)
+				}
+			}
+
+			*now = now.Add(tt.report.advance)
+			err := d.Check(context.Background(), METHOD, tt.report.user, tt.report.coordinates, tt.report.accuracy)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Check() error = %v, want code %v", err, tt.wantCode)
+			}
+
+			for _, reason := range []string{REASON_SPEED, REASON_ACCURACY, REASON_REPEAT} {
+				want := 0.0
+				if reason == tt.wantReason {
+					want = 1
+				}
+				if got := testutil.ToFloat64(d.metrics.SpoofSignals.WithLabelValues(METHOD, reason)); got != want {
+					t.Errorf("%v signals = %v, want %v", reason, got, want)
+				}
+			}
+			// the positions of the history are accepted
+			wantResult, want := RESULT_ACCEPTED, float64(len(tt.history)+1)
+			switch {
+			case tt.wantCode != codes.OK:
+				wantResult, want = RESULT_REJECTED, 1
+			case tt.wantReason != "":
+				wantResult, want = RESULT_FLAGGED, 1
+			}
+			if got := testutil.ToFloat64(d.metrics.PositionChecks.WithLabelValues(METHOD, wantResult)); got != want {
+				t.Errorf("%v checks = %v, want %v", wantResult, got, want)
+			}
+		})
+	}
+}
+
+func TestDetector_Check_History(t *testing.T) {
+	store := NewMemoryStore()
+	d, now := newTestDetector(t, MODE_REJECT, store)
+
+	if err := d.Check(context.Background(), METHOD, "bob", []float64{-122.4, 37.8}, nil); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	// a rejected position is not recorded so the next genuine one is checked against the last accepted
+	*now = now.Add(time.Minute)
+	if err := d.Check(context.Background(), METHOD, "bob", []float64{2.35, 48.85}, nil); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Check() error = %v, want FailedPrecondition", err)
+	}
+	*now = now.Add(time.Minute)
+	if err := d.Check(context.Background(), METHOD, "bob", []float64{-122.4, 37.801}, nil); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	fixes, err := store.History(context.Background(), "bob")
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(fixes) != 2 || fixes[1].Coordinates[1] != 37.801 {
+		t.Errorf("History() = %v, want the 2 accepted positions", fixes)
+	}
+}
+
+func TestDetector_Check_Disabled(t *testing.T) {
+	d, _ := newTestDetector(t, MODE_REJECT, failingStore{})
+
+	// positions are accepted when the history cannot be read
+	if err := d.Check(context.Background(), METHOD, "bob", []float64{-122.4, 37.8}, nil); err != nil {
+		t.Errorf("Check() with a failing store error = %v", err)
+	}
+
+	d.config.Enabled = false
+	if err := d.Check(context.Background(), METHOD, "", []float64{-122.4, 37.8}, accuracy(0)); err != nil {
+		t.Errorf("Check() while disabled error = %v", err)
+	}
+}
+
+func Test_countDecimals(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  int
+	}{
+		{value: 37, want: 0},
+		{value: 37.8, want: 1},
+		{value: -122.419416, want: 6},
+		{value: 1e-7, want: 7},
+	}
+	for _, tt := range tests {
+		if got := countDecimals(tt.value); got != tt.want {
+			t.Errorf("countDecimals(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
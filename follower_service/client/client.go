@@ -0,0 +1,179 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	pb "github.com/haguru/horus/follower_service/internal/routes/protos"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	SERVICE_NAME    = "follower_service"
+	DEFAULT_ADDRESS = "localhost:50055"
+
+	AUTHORIZATION_HEADER = "authorization"
+	BEARER_PREFIX        = "Bearer "
+)
+
+// SERVICE_CONFIG balances calls across the resolved instances and retries the idempotent methods while the
+// service is unavailable. AddFollow is never retried since a retry could store the follow twice
+const SERVICE_CONFIG = `{
+	"loadBalancingConfig": [{"round_robin": {}}],
+	"methodConfig": [{
+		"name": [
+			{"service": "followerdb.FollowerDB", "method": "GetFollowers"},
+			{"service": "followerdb.FollowerDB", "method": "GetFollowed"},
+			{"service": "followerdb.FollowerDB", "method": "Unfollow"}
+		],
+		"retryPolicy": {
+			"maxAttempts": 4,
+			"initialBackoff": "0.1s",
+			"maxBackoff": "1s",
+			"backoffMultiplier": 2,
+			"retryableStatusCodes": ["UNAVAILABLE"]
+		}
+	}]
+}`
+
+// Follow and Id are the messages of the FollowerDB API
+type (
+	Follow = pb.Follow
+	Id     = pb.Id
+
+	FollowerDBClient = pb.FollowerDBClient
+)
+
+// IdSeq yields the ids of a stream in order. A failed stream yields its error as the last element.
+// It has the shape of iter.Seq2 so it can be ranged over with Go 1.23 or later
+type IdSeq func(yield func(string, error) bool)
+
+// Client calls the FollowerDB API of the follower service
+type Client struct {
+	conn *grpc.ClientConn
+	api  pb.FollowerDBClient
+}
+
+// New returns a Client connected to DEFAULT_ADDRESS unless configured otherwise by opts
+func New(opts ...Option) (*Client, error) {
+	o := &options{address: DEFAULT_ADDRESS}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	target := o.address
+	dialOptions := []grpc.DialOption{grpc.WithDefaultServiceConfig(SERVICE_CONFIG)}
+
+	if o.consul != "" {
+		builder, err := newConsulBuilder(o.consul)
+		if err != nil {
+			return nil, err
+		}
+		target = CONSUL_SCHEME + ":///" + SERVICE_NAME
+		dialOptions = append(dialOptions, grpc.WithResolvers(builder))
+	}
+
+	creds := o.creds
+	if creds == nil {
+		creds = insecure.NewCredentials()
+	}
+	dialOptions = append(dialOptions, grpc.WithTransportCredentials(creds))
+
+	if o.tokenSource != nil {
+		requireTLS := creds.Info().SecurityProtocol != insecure.NewCredentials().Info().SecurityProtocol
+		dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(&tokenCredentials{source: o.tokenSource, requireTLS: requireTLS}))
+	}
+
+	conn, err := grpc.NewClient(target, append(dialOptions, o.dialOptions...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %v: %v", SERVICE_NAME, err)
+	}
+
+	return &Client{
+		conn: conn,
+		api:  pb.NewFollowerDBClient(conn),
+	}, nil
+}
+
+// Close closes the connection of the Client
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// API returns the generated client for calls not wrapped by Client
+func (c *Client) API() FollowerDBClient {
+	return c.api
+}
+
+// AddFollow makes followerId follow id and returns the id of the follow
+func (c *Client) AddFollow(ctx context.Context, id, followerId string) (string, error) {
+	follow, err := c.api.AddFollow(ctx, &Follow{Id: id, FollowerId: followerId})
+	if err != nil {
+		return "", err
+	}
+	return follow.GetValue(), nil
+}
+
+// GetFollowers returns the ids of the followers of id. The stream is opened when the sequence is iterated
+// and closed when the iteration stops
+func (c *Client) GetFollowers(ctx context.Context, id string) IdSeq {
+	return func(yield func(string, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stream, err := c.api.GetFollowers(ctx, &Id{Value: id})
+		if err != nil {
+			yield("", err)
+			return
+		}
+
+		for {
+			follower, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield("", err)
+				return
+			}
+			if !yield(follower.GetValue(), nil) {
+				return
+			}
+		}
+	}
+}
+
+// GetFollowed returns the ids of ids followed by followerId, in one call
+func (c *Client) GetFollowed(ctx context.Context, followerId string, ids []string) ([]string, error) {
+	followed, err := c.api.GetFollowed(ctx, &pb.FollowedRequest{FollowerId: followerId, Ids: ids})
+	if err != nil {
+		return nil, err
+	}
+	return followed.GetValues(), nil
+}
+
+// Unfollow stops followerId from following id
+func (c *Client) Unfollow(ctx context.Context, id, followerId string) error {
+	_, err := c.api.Unfollow(ctx, &Follow{Id: id, FollowerId: followerId})
+	return err
+}
+
+// Collect returns all ids of the sequence, or the error that ended it
+func (s IdSeq) Collect() ([]string, error) {
+	var ids []string
+	var seqErr error
+	s(func(id string, err error) bool {
+		if err != nil {
+			seqErr = err
+			return false
+		}
+		ids = append(ids, id)
+		return true
+	})
+
+	return ids, seqErr
+}
@@ -0,0 +1,251 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/haguru/horus/follower_service/config"
+	"github.com/haguru/horus/follower_service/internal/routes"
+	pb "github.com/haguru/horus/follower_service/internal/routes/protos"
+	"github.com/haguru/horus/follower_service/pkg/interfaces/mocks"
+	appMetrics "github.com/haguru/horus/follower_service/pkg/prometheus"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testServer hosts a real Route on a bufconn listener. The first call of every method in unavailable fails with
+// UNAVAILABLE and the authorization metadata of every call is recorded
+type testServer struct {
+	mu            sync.Mutex
+	unavailable   map[string]bool
+	attempts      map[string]int
+	authorization []string
+}
+
+func (s *testServer) intercept(ctx context.Context, method string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.authorization = append(s.authorization, md.Get(AUTHORIZATION_HEADER)...)
+
+	s.attempts[method]++
+	if s.unavailable[method] && s.attempts[method] == 1 {
+		return status.Error(codes.Unavailable, "unavailable")
+	}
+	return nil
+}
+
+func (s *testServer) calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts[method]
+}
+
+func newTestClient(t *testing.T, dbClient *mocks.DbClient, unavailable []string, opts ...Option) (*Client, *testServer) {
+	t.Helper()
+
+	ts := &testServer{unavailable: map[string]bool{}, attempts: map[string]int{}}
+	for _, method := range unavailable {
+		ts.unavailable[method] = true
+	}
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := ts.intercept(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := ts.intercept(ss.Context(), info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)
+	route := routes.NewRoute(logger.NewMockClient(), &config.Database{DatabaseName: "test", Collection: "test"}, dbClient,
+		appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"}), validator.New())
+	pb.RegisterFollowerDBServer(server, route)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	opts = append([]Option{
+		WithAddress("passthrough:///bufnet"),
+		WithDialOptions(grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) })),
+	}, opts...)
+	c, err := New(opts...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	return c, ts
+}
+
+func TestClient(t *testing.T) {
+	follows := []bson.D{
+		{{Key: "userId", Value: "user_1"}, {Key: "followerUserId", Value: "user_2"}},
+		{{Key: "userId", Value: "user_1"}, {Key: "followerUserId", Value: "user_3"}},
+		{{Key: "userId", Value: "user_1"}, {Key: "followerUserId", Value: "user_4"}},
+	}
+
+	tests := []struct {
+		name        string
+		unavailable []string
+		setup       func(dbClient *mocks.DbClient)
+		call        func(t *testing.T, c *Client) error
+		wantErr     bool
+		wantCode    codes.Code
+		wantCalls   map[string]int
+	}{
+		{
+			name: "add follow",
+			setup: func(dbClient *mocks.DbClient) {
+				dbClient.On("Create", mock.Anything, "test", "test", mock.Anything).Return("42", nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				id, err := c.AddFollow(context.Background(), "user_1", "user_2")
+				if id != "42" {
+					t.Errorf("AddFollow() = %v, want 42", id)
+				}
+				return err
+			},
+		},
+		{
+			name:        "add follow is not retried",
+			unavailable: []string{"/followerdb.FollowerDB/AddFollow"},
+			setup:       func(dbClient *mocks.DbClient) {},
+			call: func(t *testing.T, c *Client) error {
+				_, err := c.AddFollow(context.Background(), "user_1", "user_2")
+				return err
+			},
+			wantErr:   true,
+			wantCode:  codes.Unavailable,
+			wantCalls: map[string]int{"/followerdb.FollowerDB/AddFollow": 1},
+		},
+		{
+			name: "get followers collects the stream",
+			setup: func(dbClient *mocks.DbClient) {
+				dbClient.On("GetAll", mock.Anything, "test", "test", map[string]interface{}{"userId": "user_1"}).Return(follows, nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				ids, err := c.GetFollowers(context.Background(), "user_1").Collect()
+				if len(ids) != len(follows) || ids[0] != "user_2" {
+					t.Errorf("Collect() = %v, want [user_2 user_3 user_4]", ids)
+				}
+				return err
+			},
+		},
+		{
+			name:        "get followers stops early and is retried",
+			unavailable: []string{"/followerdb.FollowerDB/GetFollowers"},
+			setup: func(dbClient *mocks.DbClient) {
+				dbClient.On("GetAll", mock.Anything, "test", "test", map[string]interface{}{"userId": "user_1"}).Return(follows, nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				var ids []string
+				var seqErr error
+				c.GetFollowers(context.Background(), "user_1")(func(id string, err error) bool {
+					if err != nil {
+						seqErr = err
+						return false
+					}
+					ids = append(ids, id)
+					return len(ids) < 2
+				})
+				if len(ids) != 2 || ids[0] != "user_2" || ids[1] != "user_3" {
+					t.Errorf("ids = %v, want [user_2 user_3]", ids)
+				}
+				return seqErr
+			},
+			wantCalls: map[string]int{"/followerdb.FollowerDB/GetFollowers": 2},
+		},
+		{
+			name: "get followers yields the error of the stream",
+			setup: func(dbClient *mocks.DbClient) {
+				dbClient.On("GetAll", mock.Anything, "test", "test", map[string]interface{}{"userId": "user_1"}).Return(nil, fmt.Errorf("failed"))
+			},
+			call: func(t *testing.T, c *Client) error {
+				_, err := c.GetFollowers(context.Background(), "user_1").Collect()
+				return err
+			},
+			wantErr:  true,
+			wantCode: codes.Unknown,
+		},
+		{
+			name:        "get followed is retried",
+			unavailable: []string{"/followerdb.FollowerDB/GetFollowed"},
+			setup: func(dbClient *mocks.DbClient) {
+				dbClient.On("GetAll", mock.Anything, "test", "test", map[string]interface{}{"followerUserId": "user_2"}).
+					Return([]bson.D{follows[0], {{Key: "userId", Value: "user_5"}, {Key: "followerUserId", Value: "user_2"}}}, nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				ids, err := c.GetFollowed(context.Background(), "user_2", []string{"user_1", "user_6"})
+				if len(ids) != 1 || ids[0] != "user_1" {
+					t.Errorf("GetFollowed() = %v, want [user_1]", ids)
+				}
+				return err
+			},
+			wantCalls: map[string]int{"/followerdb.FollowerDB/GetFollowed": 2},
+		},
+		{
+			name:        "unfollow is retried",
+			unavailable: []string{"/followerdb.FollowerDB/Unfollow"},
+			setup: func(dbClient *mocks.DbClient) {
+				dbClient.On("Delete", mock.Anything, "test", "test", map[string]interface{}{"userId": "user_1", "followerUserId": "user_2"}).Return(nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				return c.Unfollow(context.Background(), "user_1", "user_2")
+			},
+			wantCalls: map[string]int{"/followerdb.FollowerDB/Unfollow": 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbClient := mocks.NewDbClient(t)
+			tt.setup(dbClient)
+			c, ts := newTestClient(t, dbClient, tt.unavailable)
+
+			err := tt.call(t, c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && status.Code(err) != tt.wantCode {
+				t.Errorf("code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+			for method, want := range tt.wantCalls {
+				if got := ts.calls(method); got != want {
+					t.Errorf("%v called %v times, want %v", method, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithToken(t *testing.T) {
+	dbClient := mocks.NewDbClient(t)
+	dbClient.On("Delete", mock.Anything, "test", "test", map[string]interface{}{"userId": "user_1", "followerUserId": "user_2"}).Return(nil)
+	c, ts := newTestClient(t, dbClient, nil, WithToken("secret"))
+
+	if err := c.Unfollow(context.Background(), "user_1", "user_2"); err != nil {
+		t.Fatalf("Unfollow() error = %v", err)
+	}
+	if len(ts.authorization) != 1 || ts.authorization[0] != BEARER_PREFIX+"secret" {
+		t.Errorf("authorization = %v, want [%vsecret]", ts.authorization, BEARER_PREFIX)
+	}
+}
@@ -21,17 +21,21 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	FollowerDB_AddFollow_FullMethodName    = "/followerdb.FollowerDB/AddFollow"
 	FollowerDB_GetFollowers_FullMethodName = "/followerdb.FollowerDB/GetFollowers"
+	FollowerDB_GetFollowed_FullMethodName  = "/followerdb.FollowerDB/GetFollowed"
 	FollowerDB_Unfollow_FullMethodName     = "/followerdb.FollowerDB/Unfollow"
 )
 
 // FollowerDBClient is the client API for FollowerDB service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
-//
-// TODO
 type FollowerDBClient interface {
+	// Create
 	AddFollow(ctx context.Context, in *Follow, opts ...grpc.CallOption) (*Id, error)
+	// Read, streamed as newline delimited JSON or server-sent events over REST
 	GetFollowers(ctx context.Context, in *Id, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Id], error)
+	// Read which users of a batch are followed, so callers checking many follows make one call
+	GetFollowed(ctx context.Context, in *FollowedRequest, opts ...grpc.CallOption) (*Ids, error)
+	// Delete
 	Unfollow(ctx context.Context, in *Follow, opts ...grpc.CallOption) (*Status, error)
 }
 
@@ -72,6 +76,16 @@ func (c *followerDBClient) GetFollowers(ctx context.Context, in *Id, opts ...grp
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type FollowerDB_GetFollowersClient = grpc.ServerStreamingClient[Id]
 
+func (c *followerDBClient) GetFollowed(ctx context.Context, in *FollowedRequest, opts ...grpc.CallOption) (*Ids, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ids)
+	err := c.cc.Invoke(ctx, FollowerDB_GetFollowed_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *followerDBClient) Unfollow(ctx context.Context, in *Follow, opts ...grpc.CallOption) (*Status, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Status)
@@ -85,11 +99,14 @@ func (c *followerDBClient) Unfollow(ctx context.Context, in *Follow, opts ...grp
 // FollowerDBServer is the server API for FollowerDB service.
 // All implementations must embed UnimplementedFollowerDBServer
 // for forward compatibility.
-//
-// TODO
 type FollowerDBServer interface {
+	// Create
 	AddFollow(context.Context, *Follow) (*Id, error)
+	// Read, streamed as newline delimited JSON or server-sent events over REST
 	GetFollowers(*Id, grpc.ServerStreamingServer[Id]) error
+	// Read which users of a batch are followed, so callers checking many follows make one call
+	GetFollowed(context.Context, *FollowedRequest) (*Ids, error)
+	// Delete
 	Unfollow(context.Context, *Follow) (*Status, error)
 	mustEmbedUnimplementedFollowerDBServer()
 }
@@ -107,6 +124,9 @@ func (UnimplementedFollowerDBServer) AddFollow(context.Context, *Follow) (*Id, e
 func (UnimplementedFollowerDBServer) GetFollowers(*Id, grpc.ServerStreamingServer[Id]) error {
 	return status.Errorf(codes.Unimplemented, "method GetFollowers not implemented")
 }
+func (UnimplementedFollowerDBServer) GetFollowed(context.Context, *FollowedRequest) (*Ids, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFollowed not implemented")
+}
 func (UnimplementedFollowerDBServer) Unfollow(context.Context, *Follow) (*Status, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Unfollow not implemented")
 }
@@ -160,6 +180,24 @@ func _FollowerDB_GetFollowers_Handler(srv interface{}, stream grpc.ServerStream)
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type FollowerDB_GetFollowersServer = grpc.ServerStreamingServer[Id]
 
+func _FollowerDB_GetFollowed_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FollowedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FollowerDBServer).GetFollowed(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FollowerDB_GetFollowed_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FollowerDBServer).GetFollowed(ctx, req.(*FollowedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _FollowerDB_Unfollow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Follow)
 	if err := dec(in); err != nil {
@@ -189,6 +227,10 @@ var FollowerDB_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "AddFollow",
 			Handler:    _FollowerDB_AddFollow_Handler,
 		},
+		{
+			MethodName: "GetFollowed",
+			Handler:    _FollowerDB_GetFollowed_Handler,
+		},
 		{
 			MethodName: "Unfollow",
 			Handler:    _FollowerDB_Unfollow_Handler,
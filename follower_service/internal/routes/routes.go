@@ -7,6 +7,8 @@ import (
 	"github.com/haguru/horus/follower_service/config"
 	pb "github.com/haguru/horus/follower_service/internal/routes/protos"
 	"github.com/haguru/horus/follower_service/pkg/interfaces"
+	appLogging "github.com/haguru/horus/follower_service/pkg/logging"
+	appMetrics "github.com/haguru/horus/follower_service/pkg/prometheus"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	"github.com/go-playground/validator/v10"
@@ -17,22 +19,24 @@ type Route struct {
 	dbConfig  *config.Database
 	dbClient  interfaces.DbClient
 	lc        logger.LoggingClient
+	metrics   *appMetrics.Metrics
 	validator *validator.Validate
 	pb.UnimplementedFollowerDBServer
 }
 
 // TODO
-func NewRoute(lc logger.LoggingClient, config *config.Database, dbclient interfaces.DbClient, validator *validator.Validate) *Route {
+func NewRoute(lc logger.LoggingClient, config *config.Database, dbclient interfaces.DbClient, metrics *appMetrics.Metrics, validator *validator.Validate) *Route {
 	return &Route{
 		dbConfig:  config,
 		dbClient:  dbclient,
 		lc:        lc,
+		metrics:   metrics,
 		validator: validator,
 	}
 }
 
 func (r *Route) AddFollow(ctx context.Context, follow *pb.Follow) (*pb.Id, error) {
-	r.lc.Debugf("received AddFollow request")
+	appLogging.FromContext(ctx, r.lc).Debug("received AddFollow request", appLogging.Redact(follow)...)
 	// r.metrics.RequestsCount.Inc()
 
 	// Validate the User struct
@@ -44,20 +48,22 @@ func (r *Route) AddFollow(ctx context.Context, follow *pb.Follow) (*pb.Id, error
 		return nil, fmt.Errorf("validation error: %s", errors)
 	}
 
-	id, err := r.dbClient.Create(r.dbConfig.DatabaseName, r.dbConfig.Collection, follow)
+	id, err := r.dbClient.Create(ctx, r.dbConfig.DatabaseName, r.dbConfig.Collection, follow)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add follow: %v", err)
 	}
+	appMetrics.IncWithExemplar(ctx, r.metrics.Follows)
 
 	return &pb.Id{Value: id}, nil
 }
 
 func (r *Route) GetFollowers(id *pb.Id, stream pb.FollowerDB_GetFollowersServer) error {
-	r.lc.Debugf("received Getfollowers request")
+	lc := appLogging.FromContext(stream.Context(), r.lc)
+	lc.Debug("received GetFollowers request", "id", id.GetValue())
 	// r.metrics.RequestsCount.Inc()
 
 	filter := map[string]interface{}{"userId": id.GetValue()}
-	items, err := r.dbClient.GetAll(r.dbConfig.DatabaseName, r.dbConfig.Collection, filter)
+	items, err := r.dbClient.GetAll(stream.Context(), r.dbConfig.DatabaseName, r.dbConfig.Collection, filter)
 	if err != nil {
 		return fmt.Errorf("failed to retrieve follows for id %v: %v", id.GetValue(), err)
 	}
@@ -65,14 +71,14 @@ func (r *Route) GetFollowers(id *pb.Id, stream pb.FollowerDB_GetFollowersServer)
 		// unmarshall data to grpc data type
 		doc, err := bson.Marshal(item)
 		if err != nil {
-			r.lc.Errorf("failed to marshal an item in data: %v", err)
+			lc.Errorf("failed to marshal an item in data: %v", err)
 			return err
 		}
 
 		follow := &pb.Follow{}
 		err = bson.Unmarshal(doc, follow)
 		if err != nil {
-			r.lc.Errorf("failed to unmarshal an item in data: %v", err)
+			lc.Errorf("failed to unmarshal an item in data: %v", err)
 			return err
 		}
 
@@ -80,15 +86,61 @@ func (r *Route) GetFollowers(id *pb.Id, stream pb.FollowerDB_GetFollowersServer)
 		id := &pb.Id{Value: follow.GetFollowerId()}
 		err = stream.Send(id)
 		if err != nil {
-			r.lc.Errorf("failed to send item in data: %v", err)
+			lc.Errorf("failed to send item in data: %v", err)
 			return err
 		}
 	}
 	return nil
 }
 
+// GetFollowed returns the ids of req followed by its follower. All follows of the follower are read in one query
+// and matched against the ids, since the drivers only filter by equality
+func (r *Route) GetFollowed(ctx context.Context, req *pb.FollowedRequest) (*pb.Ids, error) {
+	lc := appLogging.FromContext(ctx, r.lc)
+	lc.Debug("received GetFollowed request", "follower_id", req.GetFollowerId(), "ids", len(req.GetIds()))
+
+	err := r.validator.Struct(req)
+	if err != nil {
+		errors := err.(validator.ValidationErrors)
+
+		return nil, fmt.Errorf("validation error: %s", errors)
+	}
+
+	filter := map[string]interface{}{"followerUserId": req.GetFollowerId()}
+	items, err := r.dbClient.GetAll(ctx, r.dbConfig.DatabaseName, r.dbConfig.Collection, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve follows of follower %v: %v", req.GetFollowerId(), err)
+	}
+
+	wanted := make(map[string]bool, len(req.GetIds()))
+	for _, id := range req.GetIds() {
+		wanted[id] = true
+	}
+	followed := &pb.Ids{}
+	for _, item := range items.([]bson.D) {
+		doc, err := bson.Marshal(item)
+		if err != nil {
+			lc.Errorf("failed to marshal an item in data: %v", err)
+			return nil, err
+		}
+
+		follow := &pb.Follow{}
+		err = bson.Unmarshal(doc, follow)
+		if err != nil {
+			lc.Errorf("failed to unmarshal an item in data: %v", err)
+			return nil, err
+		}
+
+		if wanted[follow.GetId()] {
+			followed.Values = append(followed.Values, follow.GetId())
+			delete(wanted, follow.GetId())
+		}
+	}
+	return followed, nil
+}
+
 func (r *Route) Unfollow(ctx context.Context, follow *pb.Follow) (*pb.Status, error) {
-	r.lc.Debugf("received Unfollow request")
+	appLogging.FromContext(ctx, r.lc).Debug("received Unfollow request", appLogging.Redact(follow)...)
 	// r.metrics.RequestsCount.Inc()
 
 	// Validate the User struct
@@ -101,10 +153,11 @@ func (r *Route) Unfollow(ctx context.Context, follow *pb.Follow) (*pb.Status, er
 	}
 
 	filter := map[string]interface{}{"userId": follow.GetId(), "followerUserId": follow.GetFollowerId()}
-	err = r.dbClient.Delete(r.dbConfig.DatabaseName, r.dbConfig.Collection, filter)
+	err = r.dbClient.Delete(ctx, r.dbConfig.DatabaseName, r.dbConfig.Collection, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to delete follow: %v", err)
 	}
+	appMetrics.IncWithExemplar(ctx, r.metrics.Unfollows)
 
 	// do I reall want to return a status?
 	return &pb.Status{Value: 200}, nil
@@ -12,6 +12,8 @@ import (
 	pb "github.com/haguru/horus/follower_service/internal/routes/protos"
 	grpcMocks "github.com/haguru/horus/follower_service/internal/routes/protos/mocks"
 	"github.com/haguru/horus/follower_service/pkg/interfaces/mocks"
+	appMetrics "github.com/haguru/horus/follower_service/pkg/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson"
 )
@@ -75,7 +77,7 @@ func TestRoute_AddFollow(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient := mocks.NewDbClient(t)
-			mockClient.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(tt.clientRtn, tt.clientErrRtn).Maybe()
+			mockClient.On("Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.clientRtn, tt.clientErrRtn).Maybe()
 			r := &Route{
 				dbConfig: &config.Database{
 					DatabaseName: "test_database",
@@ -83,9 +85,13 @@ func TestRoute_AddFollow(t *testing.T) {
 				},
 				dbClient:  mockClient,
 				lc:        logger.NewMockClient(),
+				metrics:   appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"}),
 				validator: validator.New(),
 			}
 			got, err := r.AddFollow(tt.args.ctx, tt.args.follow)
+			if want := successCount(tt.wantErr); testutil.ToFloat64(r.metrics.Follows) != want {
+				t.Errorf("Follows = %v, want %v", testutil.ToFloat64(r.metrics.Follows), want)
+			}
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Route.AddFollow() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -149,9 +155,10 @@ func TestRoute_GetFollowers(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient := mocks.NewDbClient(t)
-			mockClient.On("GetAll", mock.Anything, mock.Anything, mock.Anything).Return(tt.clientRtn, tt.clientErrRtn).Maybe()
+			mockClient.On("GetAll", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.clientRtn, tt.clientErrRtn).Maybe()
 			streamServerMock := grpcMocks.NewServerStreamingServer[pb.Id](t)
 			streamServerMock.On("Send", mock.Anything).Return(tt.streamErrRtn).Maybe()
+			streamServerMock.On("Context").Return(context.Background()).Maybe()
 			r := &Route{
 				dbConfig: &config.Database{
 					DatabaseName: "test_database",
@@ -167,6 +174,70 @@ func TestRoute_GetFollowers(t *testing.T) {
 	}
 }
 
+func TestRoute_GetFollowed(t *testing.T) {
+	follows := []bson.D{
+		{{Key: "userId", Value: "user_1"}, {Key: "followerUserId", Value: "follower"}},
+		{{Key: "userId", Value: "user_3"}, {Key: "followerUserId", Value: "follower"}},
+		{{Key: "userId", Value: "user_1"}, {Key: "followerUserId", Value: "follower"}},
+	}
+	tests := []struct {
+		name         string
+		req          *pb.FollowedRequest
+		clientRtn    interface{}
+		clientErrRtn error
+		want         *pb.Ids
+		wantErr      bool
+	}{
+		{
+			name:      "followed ids of the batch",
+			req:       &pb.FollowedRequest{FollowerId: "follower", Ids: []string{"user_1", "user_2", "user_3"}},
+			clientRtn: follows,
+			want:      &pb.Ids{Values: []string{"user_1", "user_3"}},
+		},
+		{
+			name:      "follows nobody",
+			req:       &pb.FollowedRequest{FollowerId: "follower", Ids: []string{"user_1"}},
+			clientRtn: []bson.D{},
+			want:      &pb.Ids{},
+		},
+		{
+			name:    "validation error",
+			req:     &pb.FollowedRequest{Ids: []string{"user_1"}},
+			wantErr: true,
+		},
+		{
+			name:         "client error",
+			req:          &pb.FollowedRequest{FollowerId: "follower", Ids: []string{"user_1"}},
+			clientErrRtn: fmt.Errorf("failed"),
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewDbClient(t)
+			mockClient.On("GetAll", mock.Anything, "test_database", "test_collection", map[string]interface{}{"followerUserId": "follower"}).
+				Return(tt.clientRtn, tt.clientErrRtn).Maybe()
+			r := &Route{
+				dbConfig: &config.Database{
+					DatabaseName: "test_database",
+					Collection:   "test_collection",
+				},
+				dbClient:  mockClient,
+				lc:        logger.NewMockClient(),
+				validator: validator.New(),
+			}
+			got, err := r.GetFollowed(context.Background(), tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Route.GetFollowed() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got.GetValues(), tt.want.GetValues()) {
+				t.Errorf("Route.GetFollowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRoute_Unfollow(t *testing.T) {
 	type args struct {
 		ctx    context.Context
@@ -224,7 +295,7 @@ func TestRoute_Unfollow(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient := mocks.NewDbClient(t)
-			mockClient.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(tt.clientErrRtn).Maybe()
+			mockClient.On("Delete", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.clientErrRtn).Maybe()
 			r := &Route{
 				dbConfig: &config.Database{
 					DatabaseName: "test_database",
@@ -232,9 +303,13 @@ func TestRoute_Unfollow(t *testing.T) {
 				},
 				dbClient:  mockClient,
 				lc:        logger.NewMockClient(),
+				metrics:   appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"}),
 				validator: validator.New(),
 			}
 			got, err := r.Unfollow(tt.args.ctx, tt.args.follow)
+			if want := successCount(tt.wantErr); testutil.ToFloat64(r.metrics.Unfollows) != want {
+				t.Errorf("Unfollows = %v, want %v", testutil.ToFloat64(r.metrics.Unfollows), want)
+			}
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Route.Unfollow() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -245,3 +320,11 @@ func TestRoute_Unfollow(t *testing.T) {
 		})
 	}
 }
+
+// successCount is the expected value of a counter incremented once by a successful call
+func successCount(wantErr bool) float64 {
+	if wantErr {
+		return 0
+	}
+	return 1
+}
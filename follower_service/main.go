@@ -18,6 +18,11 @@ func main() {
 			app.LoggingClient.Errorf("failed to disconnect db server: %v", err)
 		}
 	}()
+	defer func() {
+		if err = app.ShutdownTracing(context.TODO()); err != nil {
+			app.LoggingClient.Errorf("failed to shutdown tracer provider: %v", err)
+		}
+	}()
 
 	err = app.RunServer()
 	if err != nil {
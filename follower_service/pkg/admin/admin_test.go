@@ -0,0 +1,160 @@
+package admin
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/haguru/horus/follower_service/config"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+)
+
+func writeToken(t *testing.T, token string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte(token), 0o600); err != nil {
+		t.Fatalf("failed to write token: %v", err)
+	}
+	return path
+}
+
+func clientCert(commonName string, spiffeID string) *tls.ConnectionState {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	if spiffeID != "" {
+		uri, _ := url.Parse(spiffeID)
+		cert.URIs = []*url.URL{uri}
+	}
+	return &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+}
+
+func TestNewAdmin(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *config.Admin
+		wantErr bool
+	}{
+		{
+			name:    "token file",
+			config:  &config.Admin{Pprof: true, TokenFile: writeToken(t, "s3cret\n")},
+			wantErr: false,
+		},
+		{
+			name:    "identities only",
+			config:  &config.Admin{Channelz: true, Identities: []string{"spiffe://horus/ops"}},
+			wantErr: false,
+		},
+		{
+			name:    "no authorization configured",
+			config:  &config.Admin{Pprof: true},
+			wantErr: true,
+		},
+		{
+			name:    "missing token file",
+			config:  &config.Admin{Pprof: true, TokenFile: filepath.Join(t.TempDir(), "missing")},
+			wantErr: true,
+		},
+		{
+			name:    "empty token file",
+			config:  &config.Admin{Pprof: true, TokenFile: writeToken(t, " \n")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAdmin(tt.config, logger.NewMockClient())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewAdmin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAdmin_Register(t *testing.T) {
+	a, err := NewAdmin(&config.Admin{
+		Pprof:      true,
+		Channelz:   true,
+		TokenFile:  writeToken(t, "s3cret\n"),
+		Identities: []string{"spiffe://horus/ops", "oncall"},
+	}, logger.NewMockClient())
+	if err != nil {
+		t.Fatalf("NewAdmin() error = %v", err)
+	}
+	mux := http.NewServeMux()
+	a.Register(mux)
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		tls           *tls.ConnectionState
+		wantStatus    int
+	}{
+		{name: "pprof without credentials", path: PPROF_ENDPOINT, wantStatus: http.StatusUnauthorized},
+		{name: "pprof with wrong token", path: PPROF_ENDPOINT, authorization: "Bearer nope", wantStatus: http.StatusUnauthorized},
+		{name: "pprof with token", path: PPROF_ENDPOINT, authorization: "Bearer s3cret", wantStatus: http.StatusOK},
+		{name: "pprof with basic scheme", path: PPROF_ENDPOINT, authorization: "Basic s3cret", wantStatus: http.StatusUnauthorized},
+		{name: "allowed spiffe id", path: PPROF_ENDPOINT + "cmdline", tls: clientCert("ops", "spiffe://horus/ops"), wantStatus: http.StatusOK},
+		{name: "allowed common name", path: PPROF_ENDPOINT + "cmdline", tls: clientCert("oncall", ""), wantStatus: http.StatusOK},
+		{name: "unknown identity", path: PPROF_ENDPOINT + "cmdline", tls: clientCert("gateway", "spiffe://horus/gateway"), wantStatus: http.StatusUnauthorized},
+		{name: "channelz with token", path: CHANNELZ_ENDPOINT, authorization: "Bearer s3cret", wantStatus: http.StatusOK},
+		{name: "channelz invalid server id", path: CHANNELZ_ENDPOINT + "?server_id=abc", authorization: "Bearer s3cret", wantStatus: http.StatusBadRequest},
+		{name: "channelz unknown socket", path: CHANNELZ_ENDPOINT + "?socket_id=999999", authorization: "Bearer s3cret", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.TLS = tt.tls
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("%v status = %v, want %v", tt.path, rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAdmin_Channelz(t *testing.T) {
+	a, err := NewAdmin(&config.Admin{Channelz: true, TokenFile: writeToken(t, "s3cret")}, logger.NewMockClient())
+	if err != nil {
+		t.Fatalf("NewAdmin() error = %v", err)
+	}
+	mux := http.NewServeMux()
+	a.Register(mux)
+
+	req := httptest.NewRequest(http.MethodGet, CHANNELZ_ENDPOINT, nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	body := map[string]json.RawMessage{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode channelz response %q: %v", rec.Body.String(), err)
+	}
+	for _, key := range []string{"servers", "top_channels"} {
+		if _, ok := body[key]; !ok {
+			t.Errorf("channelz response is missing %v: %v", key, rec.Body.String())
+		}
+	}
+
+	// pprof is disabled so its endpoints are not registered
+	req = httptest.NewRequest(http.MethodGet, PPROF_ENDPOINT, nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("%v status = %v, want %v", PPROF_ENDPOINT, rec.Code, http.StatusNotFound)
+	}
+}
@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
@@ -10,11 +11,20 @@ import (
 	"github.com/haguru/horus/follower_service/config"
 	"github.com/haguru/horus/follower_service/internal/routes"
 	pb "github.com/haguru/horus/follower_service/internal/routes/protos"
+	"github.com/haguru/horus/follower_service/pkg/admin"
+	"github.com/haguru/horus/follower_service/pkg/boltdb"
 	"github.com/haguru/horus/follower_service/pkg/consul"
+	"github.com/haguru/horus/follower_service/pkg/gateway"
 	"github.com/haguru/horus/follower_service/pkg/healthcheck"
 	"github.com/haguru/horus/follower_service/pkg/interfaces"
+	appLogging "github.com/haguru/horus/follower_service/pkg/logging"
+	"github.com/haguru/horus/follower_service/pkg/memory"
 	"github.com/haguru/horus/follower_service/pkg/mongodb"
 	appMetrics "github.com/haguru/horus/follower_service/pkg/prometheus"
+	"github.com/haguru/horus/follower_service/pkg/ratelimit"
+	"github.com/haguru/horus/follower_service/pkg/reload"
+	"github.com/haguru/horus/follower_service/pkg/security"
+	"github.com/haguru/horus/follower_service/pkg/tracing"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	"github.com/go-playground/validator/v10"
@@ -22,7 +32,10 @@ import (
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
 )
 
 const (
@@ -36,9 +49,13 @@ type App struct {
 	DbServerClient interfaces.DbClient
 	GrpcServer     *grpc.Server
 	LoggingClient  logger.LoggingClient
+	Reloader       *reload.Reloader
 	Route          *routes.Route
 	ServiceConfig  *config.ServiceConfig
+	TracerProvider *sdktrace.TracerProvider
+	limiter        *ratelimit.Limiter
 	metrics        *appMetrics.Metrics
+	validator      *validator.Validate
 }
 
 func NewApp() (*App, error) {
@@ -56,26 +73,49 @@ func NewApp() (*App, error) {
 		return nil, fmt.Errorf("validation error: %s", errors)
 	}
 
-	lc := logger.NewClient(serviceConfig.ServiceName, serviceConfig.LogLevel)
+	lc := appLogging.NewClient(serviceConfig.ServiceName, serviceConfig.LogLevel, serviceConfig.LogFormat)
 
-	host := serviceConfig.Database.Host
-	port := serviceConfig.Database.Port
+	var tp *sdktrace.TracerProvider
+	if serviceConfig.Tracing.Enabled {
+		exporter, err := tracing.NewExporter(context.Background(), &serviceConfig.Tracing)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create trace exporter: %v", err)
+		}
 
-	timeout, err := time.ParseDuration(serviceConfig.Database.Timeout)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse timeout: %v", err)
+		tp, err = tracing.NewTracerProvider(&serviceConfig.Tracing, serviceConfig.ServiceName, exporter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create tracer provider: %v", err)
+		}
 	}
 
-	db, err := mongodb.NewMongoDB(host, port, lc, timeout, nil)
+	metrics := appMetrics.NewMetrics(serviceConfig)
+
+	db, err := newDatabase(&serviceConfig.Database, lc, metrics)
 	if err != nil {
 		lc.Errorf("failed to connect, %v\n", err)
 		return nil, err
 	}
 
-	metrics := appMetrics.NewMetrics(serviceConfig)
-
 	// initiate routes
-	route := routes.NewRoute(lc, &serviceConfig.Database, db, validate)
+	var store ratelimit.Store = ratelimit.NewMemoryStore()
+	if serviceConfig.RateLimit.Enabled && serviceConfig.RateLimit.Backend == ratelimit.BACKEND_MONGODB {
+		mongoDB, ok := db.(*mongodb.MongoDB)
+		if !ok {
+			return nil, fmt.Errorf("rate limit backend %v requires a mongodb database", ratelimit.BACKEND_MONGODB)
+		}
+
+		collection := serviceConfig.RateLimit.Collection
+		if collection == "" {
+			collection = ratelimit.DEFAULT_COLLECTION
+		}
+		store, err = ratelimit.NewMongoStore(context.Background(), mongoDB.Client.Database(serviceConfig.Database.DatabaseName).Collection(collection))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create rate limit store: %v", err)
+		}
+	}
+	limiter := ratelimit.NewLimiter(&serviceConfig.RateLimit, store, lc, metrics)
+
+	route := routes.NewRoute(lc, &serviceConfig.Database, db, metrics, validate)
 
 	consulClient, err := consul.NewConsul(&serviceConfig.Consul)
 	if err != nil {
@@ -89,7 +129,10 @@ func NewApp() (*App, error) {
 		LoggingClient:  lc,
 		Route:          route,
 		ServiceConfig:  serviceConfig,
+		TracerProvider: tp,
+		limiter:        limiter,
 		metrics:        metrics,
+		validator:      validate,
 	}, nil
 }
 
@@ -99,25 +142,72 @@ func (app *App) RunServer() error {
 		return fmt.Errorf("failed to listen: %v", err)
 	}
 
-	err = app.Consul.RegisterService(app.ServiceConfig.ServiceName, app.ServiceConfig.Port)
+	err = app.Consul.RegisterService(app.ServiceConfig.ServiceName, app.ServiceConfig.Port, app.ServiceConfig.TLS.Enabled)
 	if err != nil {
 		return fmt.Errorf("failed to register service: %v", err)
 	}
 
 	// Create a gRPC Server with gRPC interceptor.
-	app.GrpcServer = grpc.NewServer(
+	serverOpts := []grpc.ServerOption{
 		grpc.StatsHandler(otelgrpc.NewServerHandler()),
 		grpc.ChainUnaryInterceptor(
+			security.UnaryServerInterceptor(),
 			grpc.UnaryServerInterceptor(app.metrics.GrpcMetrics.UnaryServerInterceptor(grpcprom.WithExemplarFromContext(appMetrics.ExemplarFromContext))),
-			logging.UnaryServerInterceptor(appMetrics.InterceptorLogger(app.LoggingClient), logging.WithFieldsFromContext(appMetrics.LogTraceID)),
+			logging.UnaryServerInterceptor(appMetrics.InterceptorLogger(app.LoggingClient), logging.WithFieldsFromContext(appLogging.RequestFields)),
+			app.limiter.UnaryServerInterceptor(),
 		),
 		grpc.ChainStreamInterceptor(
+			security.StreamServerInterceptor(),
 			grpc.StreamServerInterceptor(app.metrics.GrpcMetrics.StreamServerInterceptor(grpcprom.WithExemplarFromContext(appMetrics.ExemplarFromContext))),
-			logging.StreamServerInterceptor(appMetrics.InterceptorLogger(app.LoggingClient), logging.WithFieldsFromContext(appMetrics.LogTraceID)),
+			logging.StreamServerInterceptor(appMetrics.InterceptorLogger(app.LoggingClient), logging.WithFieldsFromContext(appLogging.RequestFields)),
+			app.limiter.StreamServerInterceptor(),
 		),
-	)
+	}
+
+	var metricsTLSConfig *tls.Config
+	var gatewayTLSConfig *tls.Config
+	var gatewayCreds credentials.TransportCredentials
+	if app.ServiceConfig.TLS.Enabled {
+		app.LoggingClient.Debug("loading tls certificates")
+		certs, err := security.NewCertReloader(&app.ServiceConfig.TLS, app.LoggingClient)
+		if err != nil {
+			return fmt.Errorf("failed to load tls certificates: %v", err)
+		}
+
+		grpcTLSConfig, err := certs.ServerTLSConfig(security.ALPN_HTTP2)
+		if err != nil {
+			return fmt.Errorf("failed to create tls config: %v", err)
+		}
+
+		metricsTLSConfig, err = certs.ServerTLSConfig()
+		if err != nil {
+			return fmt.Errorf("failed to create tls config: %v", err)
+		}
+
+		gatewayTLSConfig, err = certs.ServerTLSConfig()
+		if err != nil {
+			return fmt.Errorf("failed to create tls config: %v", err)
+		}
+
+		loopbackTLSConfig, err := certs.LoopbackTLSConfig()
+		if err != nil {
+			return fmt.Errorf("failed to create tls config: %v", err)
+		}
+		gatewayCreds = credentials.NewTLS(loopbackTLSConfig)
+
+		go func() {
+			if err := certs.Watch(app.AppCtx); err != nil {
+				app.LoggingClient.Errorf("failed to watch tls certificates: %v", err)
+			}
+		}()
+
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(grpcTLSConfig)))
+	}
+	app.GrpcServer = grpc.NewServer(serverOpts...)
 
 	pb.RegisterFollowerDBServer(app.GrpcServer, app.Route)
+	// server reflection lets horusctl and grpcurl describe the methods
+	reflection.Register(app.GrpcServer)
 	app.metrics.GrpcMetrics.InitializeMetrics(app.GrpcServer)
 
 	pingInterval, err := time.ParseDuration(app.ServiceConfig.Database.PingInterval)
@@ -135,21 +225,72 @@ func (app *App) RunServer() error {
 	app.LoggingClient.Debug("starting healthcheck service")
 	go health.StartHealthCheckService(app.DbServerClient)
 
+	app.Reloader = reload.NewReloader(config.CONFIG_PATH, app.ServiceConfig, app.LoggingClient, app.validator, app.metrics, app.Consul, health)
+	go func() {
+		if err := app.Reloader.Start(app.AppCtx); err != nil {
+			app.LoggingClient.Errorf("failed to watch for config changes: %v", err)
+		}
+	}()
+
+	var adminEndpoints *admin.Admin
+	if app.ServiceConfig.Admin.Pprof || app.ServiceConfig.Admin.Channelz {
+		adminEndpoints, err = admin.NewAdmin(&app.ServiceConfig.Admin, app.LoggingClient)
+		if err != nil {
+			return fmt.Errorf("failed to create admin endpoints: %v", err)
+		}
+	}
+
 	go func() {
 		addr := fmt.Sprintf(":%d", app.ServiceConfig.Metrics.Port)
-		metricsServer := &http.Server{Addr: addr, ReadTimeout: READ_TIMEOUT}
+		metricsServer := &http.Server{Addr: addr, ReadTimeout: READ_TIMEOUT, TLSConfig: metricsTLSConfig}
 		muxHandler := http.NewServeMux()
 		muxHandler.Handle(METRICS_ENDPOINT, promhttp.HandlerFor(app.metrics.Registry, promhttp.HandlerOpts{
 			EnableOpenMetrics: true,
 		}))
+		if adminEndpoints != nil {
+			adminEndpoints.Register(muxHandler)
+		}
 
 		metricsServer.Handler = muxHandler
 		app.LoggingClient.Debugf("server(prometheus) listening at %v", app.ServiceConfig.Metrics.Port)
-		if err := metricsServer.ListenAndServe(); err != nil {
+		var err error
+		if metricsServer.TLSConfig != nil {
+			// certificates are served by the tls config
+			err = metricsServer.ListenAndServeTLS("", "")
+		} else {
+			err = metricsServer.ListenAndServe()
+		}
+		if err != nil {
 			app.LoggingClient.Error("failed to start prometheus client")
 		}
 	}()
 
+	if app.ServiceConfig.Gateway.Enabled {
+		conn, err := gateway.Dial(app.ServiceConfig.Port, gatewayCreds)
+		if err != nil {
+			return fmt.Errorf("failed to create gateway: %v", err)
+		}
+		handler, err := gateway.NewHandler(app.AppCtx, conn)
+		if err != nil {
+			return fmt.Errorf("failed to create gateway: %v", err)
+		}
+
+		go func() {
+			addr := fmt.Sprintf(":%d", app.ServiceConfig.Gateway.Port)
+			gatewayServer := &http.Server{Addr: addr, Handler: handler, ReadHeaderTimeout: READ_TIMEOUT, TLSConfig: gatewayTLSConfig}
+			app.LoggingClient.Debugf("server(gateway) listening at %v", app.ServiceConfig.Gateway.Port)
+			var err error
+			if gatewayServer.TLSConfig != nil {
+				err = gatewayServer.ListenAndServeTLS("", "")
+			} else {
+				err = gatewayServer.ListenAndServe()
+			}
+			if err != nil {
+				app.LoggingClient.Errorf("failed to start gateway: %v", err)
+			}
+		}()
+	}
+
 	app.LoggingClient.Debugf("server(GRPC) listening at %v", lis.Addr())
 	err = app.GrpcServer.Serve(lis)
 	if err != nil {
@@ -158,3 +299,29 @@ func (app *App) RunServer() error {
 
 	return nil
 }
+
+// ShutdownTracing flushes any buffered spans and stops the tracer provider
+func (app *App) ShutdownTracing(ctx context.Context) error {
+	if app.TracerProvider == nil {
+		return nil
+	}
+
+	return app.TracerProvider.Shutdown(ctx)
+}
+
+// newDatabase returns the client of the database selected by dbConfig.Driver
+func newDatabase(dbConfig *config.Database, lc logger.LoggingClient, metrics *appMetrics.Metrics) (interfaces.DbClient, error) {
+	timeout, err := time.ParseDuration(dbConfig.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse timeout: %v", err)
+	}
+
+	switch dbConfig.Driver {
+	case config.DRIVER_MEMORY:
+		lc.Warn("using the in-memory database, documents are lost when the service stops")
+		return memory.NewMemory(lc), nil
+	case config.DRIVER_BBOLT:
+		return boltdb.NewBoltDB(dbConfig.Path, lc, timeout)
+	}
+	return mongodb.NewMongoDB(dbConfig.Host, dbConfig.Port, lc, metrics, timeout, nil)
+}
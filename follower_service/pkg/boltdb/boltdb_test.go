@@ -0,0 +1,96 @@
+package boltdb
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/haguru/horus/follower_service/pkg/document"
+	"github.com/haguru/horus/follower_service/pkg/interfaces"
+	"github.com/haguru/horus/follower_service/pkg/interfaces/conformance"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	DATABASE   = "horus"
+	COLLECTION = "users"
+	TIMEOUT    = time.Second
+)
+
+func newTestBoltDB(t *testing.T, path string) interfaces.DbClient {
+	t.Helper()
+
+	db, err := NewBoltDB(path, logger.NewMockClient(), TIMEOUT)
+	if err != nil {
+		t.Fatalf("NewBoltDB() error = %v", err)
+	}
+	t.Cleanup(func() { db.Disconnect(context.Background()) })
+	return db
+}
+
+func TestBoltDB_Conformance(t *testing.T) {
+	conformance.Run(t, func(t *testing.T) interfaces.DbClient {
+		return newTestBoltDB(t, filepath.Join(t.TempDir(), "horus.db"))
+	})
+}
+
+func TestBoltDB_Reopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "data", "horus.db")
+
+	db := newTestBoltDB(t, path)
+	var ids []string
+	for _, user := range []string{"b", "a", "c"} {
+		id, err := db.Create(ctx, DATABASE, COLLECTION, bson.D{{Key: "id", Value: user}})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := db.Delete(ctx, DATABASE, COLLECTION, map[string]interface{}{"id": "a"}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := db.Disconnect(ctx); err != nil {
+		t.Fatalf("Disconnect() error = %v", err)
+	}
+	if err := db.Ping(); err == nil {
+		t.Errorf("Ping() of a closed database succeeded")
+	}
+
+	db = newTestBoltDB(t, path)
+	got, err := db.GetAll(ctx, DATABASE, COLLECTION, nil)
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	var users []interface{}
+	for _, doc := range got.([]bson.D) {
+		user, _ := document.Lookup(doc, "id")
+		users = append(users, user)
+	}
+	if !document.Equal(bson.A(users), bson.A{"b", "c"}) {
+		t.Errorf("GetAll() after reopening = %v, want [b c]", users)
+	}
+
+	// the id index survives too
+	objId, _ := primitive.ObjectIDFromHex(ids[0])
+	if _, err := db.Create(ctx, DATABASE, COLLECTION, bson.D{{Key: document.IDFIELD, Value: objId}}); err == nil {
+		t.Errorf("Create() of a duplicate id succeeded")
+	}
+	objId, _ = primitive.ObjectIDFromHex(ids[1])
+	if _, err := db.Create(ctx, DATABASE, COLLECTION, bson.D{{Key: document.IDFIELD, Value: objId}}); err != nil {
+		t.Errorf("Create() of a deleted id error = %v", err)
+	}
+}
+
+func TestBoltDB_Locked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "horus.db")
+	newTestBoltDB(t, path)
+
+	if _, err := NewBoltDB(path, logger.NewMockClient(), 10*time.Millisecond); err == nil {
+		t.Errorf("NewBoltDB() of a file held by another client succeeded")
+	}
+}
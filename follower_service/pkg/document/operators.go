@@ -0,0 +1,194 @@
+package document
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	OPERATOR_CURRENT_DATE  = "currentDate"
+	OPERATOR_INC           = "inc"
+	OPERATOR_MAX           = "max"
+	OPERATOR_MIN           = "min"
+	OPERATOR_MUL           = "mul"
+	OPERATOR_RENAME        = "rename"
+	OPERATOR_SET           = "set"
+	OPERATOR_SET_ON_INSERT = "setOnInsert"
+	OPERATOR_UNSET         = "unset"
+
+	CURRENT_DATE_TYPE      = "$type"
+	CURRENT_DATE_TIMESTAMP = "timestamp"
+)
+
+// OPERATORS are the update operators supported by the mongodb client, see mongodb.updateCommand
+var OPERATORS = map[string]bool{
+	OPERATOR_CURRENT_DATE:  true,
+	OPERATOR_INC:           true,
+	OPERATOR_MAX:           true,
+	OPERATOR_MIN:           true,
+	OPERATOR_MUL:           true,
+	OPERATOR_RENAME:        true,
+	OPERATOR_SET:           true,
+	OPERATOR_SET_ON_INSERT: true,
+	OPERATOR_UNSET:         true,
+}
+
+// ApplyUpdate returns a copy of doc with operator applied to every field of items. doc is left untouched if
+// the update fails
+func ApplyUpdate(doc bson.D, operator string, items bson.D) (bson.D, error) {
+	updated, err := ToDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if item.Key == IDFIELD || operator == OPERATOR_RENAME && item.Value == IDFIELD {
+			return nil, fmt.Errorf("performing an update on the path '_id' would modify the immutable field '_id'")
+		}
+
+		current, found := Get(updated, item.Key)
+		switch operator {
+		case OPERATOR_SET:
+			updated, err = Set(updated, item.Key, item.Value)
+
+		// upserts are not supported, so no update ever inserts a document
+		case OPERATOR_SET_ON_INSERT:
+
+		case OPERATOR_UNSET:
+			updated = Unset(updated, item.Key)
+
+		case OPERATOR_RENAME:
+			to, ok := item.Value.(string)
+			if !ok {
+				return nil, fmt.Errorf("the 'to' field for $rename must be a string: %v: %v", item.Key, item.Value)
+			}
+			if found {
+				updated, err = Set(Unset(updated, item.Key), to, current)
+			}
+
+		case OPERATOR_CURRENT_DATE:
+			var now interface{} = primitive.NewDateTimeFromTime(time.Now())
+			if spec, ok := item.Value.(bson.D); ok {
+				if value, _ := Lookup(spec, CURRENT_DATE_TYPE); value == CURRENT_DATE_TIMESTAMP {
+					now = primitive.Timestamp{T: uint32(time.Now().Unix())}
+				}
+			}
+			updated, err = Set(updated, item.Key, now)
+
+		case OPERATOR_INC, OPERATOR_MUL:
+			var result interface{}
+			result, err = arithmetic(operator, current, found, item.Value)
+			if err == nil {
+				updated, err = Set(updated, item.Key, result)
+			}
+
+		case OPERATOR_MAX, OPERATOR_MIN:
+			if found {
+				var cmp int
+				cmp, err = compare(item.Value, current)
+				if err != nil || operator == OPERATOR_MAX && cmp <= 0 || operator == OPERATOR_MIN && cmp >= 0 {
+					break
+				}
+			}
+			updated, err = Set(updated, item.Key, item.Value)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return updated, nil
+}
+
+// arithmetic returns the result of $inc or $mul. Like mongodb, a missing field is set to value, or zero for $mul
+func arithmetic(operator string, current interface{}, found bool, value interface{}) (interface{}, error) {
+	operand, ok := number(value)
+	if !ok {
+		return nil, fmt.Errorf("cannot %v with non-numeric argument: %v", operator, value)
+	}
+	if !found {
+		current = value
+		if operator == OPERATOR_MUL {
+			current = convert(0, value, value)
+		}
+		return current, nil
+	}
+
+	base, ok := number(current)
+	if !ok {
+		return nil, fmt.Errorf("cannot apply $%v to a value of non-numeric type %T", operator, current)
+	}
+
+	if operator == OPERATOR_INC {
+		return convert(base+operand, current, value), nil
+	}
+	return convert(base*operand, current, value), nil
+}
+
+// convert returns result with the widest numeric type of a and b, like mongodb does for arithmetic
+func convert(result float64, a, b interface{}) interface{} {
+	_, aDouble := a.(float64)
+	_, bDouble := b.(float64)
+	_, aLong := a.(int64)
+	_, bLong := b.(int64)
+
+	switch {
+	case aDouble || bDouble:
+		return result
+	case aLong || bLong || result > math.MaxInt32 || result < math.MinInt32:
+		return int64(result)
+	}
+	return int32(result)
+}
+
+// compare returns -1, 0 or 1 if a is less than, equal to or greater than b. Only values of the same kind compare
+func compare(a, b interface{}) (int, error) {
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			return cmp(x < y, x > y), nil
+		}
+	}
+
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return cmp(x < y, x > y), nil
+		}
+	case primitive.DateTime:
+		if y, ok := b.(primitive.DateTime); ok {
+			return cmp(x < y, x > y), nil
+		}
+	case primitive.Timestamp:
+		if y, ok := b.(primitive.Timestamp); ok {
+			return primitive.CompareTimestamp(x, y), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %T with %T", a, b)
+}
+
+func cmp(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// number returns v as float64 if it is one of the numeric types bson decodes to
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
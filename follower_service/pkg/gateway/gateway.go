@@ -0,0 +1,139 @@
+package gateway
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	pb "github.com/haguru/horus/follower_service/internal/routes/protos"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	OPENAPI_ENDPOINT = "/openapi.yaml"
+
+	MIME_NDJSON  = "application/x-ndjson"
+	MIME_SSE     = "text/event-stream"
+	MIME_OPENAPI = "application/yaml"
+
+	HEADER_RETRY_AFTER = "Retry-After"
+)
+
+// FORWARDED_HEADERS are the HTTP headers passed to the gRPC server as metadata in addition to the gateway defaults
+var FORWARDED_HEADERS = []string{"traceparent", "tracestate", "baggage"}
+
+// openAPI is the OpenAPI v3 document generated from the service protos
+//
+//go:embed openapi.yaml
+var openAPI []byte
+
+// NewHandler returns the REST/JSON handler translating requests into calls on conn and serving the OpenAPI
+// document. Server streams are written as newline delimited JSON, or as server-sent events for clients
+// accepting MIME_SSE
+func NewHandler(ctx context.Context, conn *grpc.ClientConn) (http.Handler, error) {
+	gwmux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(MIME_NDJSON, &ndjsonMarshaler{JSONPb: newJSONPb()}),
+		runtime.WithMarshalerOption(MIME_SSE, &sseMarshaler{JSONPb: newJSONPb()}),
+		runtime.WithIncomingHeaderMatcher(headerMatcher),
+		runtime.WithErrorHandler(errorHandler),
+	)
+
+	err := pb.RegisterFollowerDBHandler(ctx, gwmux, conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register gateway handlers: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", gwmux)
+	mux.HandleFunc(OPENAPI_ENDPOINT, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", MIME_OPENAPI)
+		_, _ = w.Write(openAPI)
+	})
+
+	return mux, nil
+}
+
+// Dial returns a connection to the gRPC server of the service listening on port. creds are the transport
+// credentials of the server, nil when it serves plaintext
+func Dial(port int, creds credentials.TransportCredentials) (*grpc.ClientConn, error) {
+	if creds == nil {
+		creds = insecure.NewCredentials()
+	}
+
+	conn, err := grpc.NewClient(fmt.Sprintf("localhost:%d", port), grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to grpc server: %v", err)
+	}
+
+	return conn, nil
+}
+
+func headerMatcher(key string) (string, bool) {
+	for _, header := range FORWARDED_HEADERS {
+		if strings.EqualFold(key, header) {
+			return header, true
+		}
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// errorHandler adds a Retry-After header to errors carrying RetryInfo, e.g. rate limited requests
+func errorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			// Retry-After is in whole seconds, round up so clients do not retry too early
+			seconds := math.Ceil(info.GetRetryDelay().AsDuration().Seconds())
+			w.Header().Set(HEADER_RETRY_AFTER, strconv.FormatFloat(seconds, 'f', 0, 64))
+		}
+	}
+
+	runtime.DefaultHTTPErrorHandler(ctx, mux, marshaler, w, r, err)
+}
+
+func newJSONPb() runtime.JSONPb {
+	return runtime.JSONPb{
+		MarshalOptions:   protojson.MarshalOptions{EmitUnpopulated: true},
+		UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+	}
+}
+
+// ndjsonMarshaler writes newline delimited JSON under the MIME_NDJSON content type
+type ndjsonMarshaler struct {
+	runtime.JSONPb
+}
+
+func (m *ndjsonMarshaler) ContentType(_ interface{}) string {
+	return MIME_NDJSON
+}
+
+// sseMarshaler writes every message as a server-sent event
+type sseMarshaler struct {
+	runtime.JSONPb
+}
+
+func (m *sseMarshaler) ContentType(_ interface{}) string {
+	return MIME_SSE
+}
+
+func (m *sseMarshaler) Marshal(v interface{}) ([]byte, error) {
+	data, err := m.JSONPb.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("data: "), data...), nil
+}
+
+func (m *sseMarshaler) Delimiter() []byte {
+	return []byte("\n\n")
+}
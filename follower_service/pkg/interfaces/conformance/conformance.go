@@ -0,0 +1,360 @@
+// Package conformance is the test suite every interfaces.DbClient implementation must pass, so the service
+// behaves the same whichever database.driver is configured
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haguru/horus/follower_service/pkg/document"
+	"github.com/haguru/horus/follower_service/pkg/interfaces"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const DATABASE = "horus_conformance"
+
+// Run runs the suite against the clients returned by newClient. Every test writes to its own collection, so
+// newClient may return the same database each time
+func Run(t *testing.T, newClient func(t *testing.T) interfaces.DbClient) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, db interfaces.DbClient, collection string)
+	}{
+		{name: "create and get", test: testCreateGet},
+		{name: "get missing", test: testGetMissing},
+		{name: "get all", test: testGetAll},
+		{name: "update", test: testUpdate},
+		{name: "update operators", test: testUpdateOperators},
+		{name: "current date", test: testCurrentDate},
+		{name: "delete", test: testDelete},
+		{name: "document exist", test: testDocumentExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newClient(t)
+			if err := db.Ping(); err != nil {
+				t.Fatalf("Ping() error = %v", err)
+			}
+			tt.test(t, db, collection(t))
+		})
+	}
+}
+
+func testCreateGet(t *testing.T, db interfaces.DbClient, collection string) {
+	ctx := context.Background()
+
+	id, err := db.Create(ctx, DATABASE, collection, bson.D{{Key: "id", Value: "a"}, {Key: "followers", Value: bson.A{"b", "c"}}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if id == "" {
+		t.Errorf("Create() returned an empty id")
+	}
+
+	got, err := db.Get(ctx, DATABASE, collection, map[string]interface{}{"id": "a"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	doc := *got.(*bson.D)
+	if doc[0].Key != document.IDFIELD {
+		t.Errorf("Get() first field = %v, want %v", doc[0].Key, document.IDFIELD)
+	}
+	if followers, _ := document.Lookup(doc, "followers"); !document.Equal(followers, bson.A{"b", "c"}) {
+		t.Errorf("Get() followers = %v, want [b c]", followers)
+	}
+}
+
+func testGetMissing(t *testing.T, db interfaces.DbClient, collection string) {
+	ctx := context.Background()
+
+	if _, err := db.Get(ctx, DATABASE, collection, map[string]interface{}{"id": "a"}); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Get() of a missing collection error = %v, want %v", err, mongo.ErrNoDocuments)
+	}
+
+	create(t, db, collection, bson.D{{Key: "id", Value: "a"}})
+	if _, err := db.Get(ctx, DATABASE, collection, map[string]interface{}{"id": "b"}); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("Get() of a missing document error = %v, want %v", err, mongo.ErrNoDocuments)
+	}
+}
+
+func testGetAll(t *testing.T, db interfaces.DbClient, collection string) {
+	ctx := context.Background()
+
+	got, err := db.GetAll(ctx, DATABASE, collection, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("GetAll() of a missing collection error = %v", err)
+	}
+	if len(got.([]bson.D)) != 0 {
+		t.Errorf("GetAll() of a missing collection = %v, want none", got)
+	}
+
+	create(t, db, collection,
+		bson.D{{Key: "id", Value: "a"}, {Key: "followers", Value: bson.A{"c"}}, {Key: "private", Value: true}},
+		bson.D{{Key: "id", Value: "b"}, {Key: "followers", Value: bson.A{"d"}}, {Key: "private", Value: false}},
+		bson.D{{Key: "id", Value: "c"}, {Key: "followers", Value: bson.A{"c", "d"}}, {Key: "private", Value: true}},
+	)
+
+	tests := []struct {
+		filter map[string]interface{}
+		want   []string
+	}{
+		{filter: map[string]interface{}{}, want: []string{"a", "b", "c"}},
+		{filter: map[string]interface{}{"followers": "d"}, want: []string{"b", "c"}},
+		{filter: map[string]interface{}{"followers": "d", "private": true}, want: []string{"c"}},
+		{filter: map[string]interface{}{"id": "a"}, want: []string{"a"}},
+		{filter: map[string]interface{}{"followers": "e"}, want: []string{}},
+		{filter: map[string]interface{}{"missing": "a"}, want: []string{}},
+	}
+	for _, tt := range tests {
+		got, err := db.GetAll(ctx, DATABASE, collection, tt.filter)
+		if err != nil {
+			t.Fatalf("GetAll(%v) error = %v", tt.filter, err)
+		}
+
+		ids := []string{}
+		for _, doc := range got.([]bson.D) {
+			id, _ := document.Lookup(doc, "id")
+			ids = append(ids, fmt.Sprint(id))
+		}
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("GetAll(%v) ids = %v, want %v", tt.filter, ids, tt.want)
+		}
+	}
+}
+
+func testUpdate(t *testing.T, db interfaces.DbClient, collection string) {
+	ctx := context.Background()
+	create(t, db, collection, bson.D{{Key: "id", Value: "a"}, {Key: "count", Value: int32(1)}})
+	filter := map[string]interface{}{"id": "a"}
+
+	if err := db.Update(ctx, DATABASE, collection, filter, document.OPERATOR_INC, map[string]interface{}{"count": int32(2)}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, err := db.Get(ctx, DATABASE, collection, filter)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if count, _ := document.Lookup(*got.(*bson.D), "count"); !document.Equal(count, int32(3)) {
+		t.Errorf("Update() count = %v, want 3", count)
+	}
+
+	missing := map[string]interface{}{"id": "b"}
+	if err := db.Update(ctx, DATABASE, collection, missing, document.OPERATOR_SET, map[string]interface{}{"count": 1}); err == nil {
+		t.Errorf("Update() of a missing document succeeded")
+	}
+	if err := db.Update(ctx, DATABASE, collection, filter, "push", map[string]interface{}{"count": 1}); err == nil {
+		t.Errorf("Update() with an unsupported operator succeeded")
+	}
+	if err := db.Update(ctx, DATABASE, collection, filter, document.OPERATOR_SET, map[string]interface{}{document.IDFIELD: "a"}); err == nil {
+		t.Errorf("Update() of the id succeeded")
+	}
+}
+
+// testUpdateOperators applies every operator of document.OPERATORS to its own copy of the same document
+func testUpdateOperators(t *testing.T, db interfaces.DbClient, collection string) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		operator string
+		items    map[string]interface{}
+		want     map[string]interface{}
+		unset    []string
+	}{
+		{
+			name:     "set",
+			operator: document.OPERATOR_SET,
+			items:    map[string]interface{}{"name": "b", "profile.city": "Paris"},
+			want:     map[string]interface{}{"name": "b", "profile.city": "Paris", "profile.country": "France"},
+		},
+		{
+			name:     "set on insert",
+			operator: document.OPERATOR_SET_ON_INSERT,
+			items:    map[string]interface{}{"name": "b"},
+			want:     map[string]interface{}{"name": "a"},
+		},
+		{
+			name:     "unset",
+			operator: document.OPERATOR_UNSET,
+			items:    map[string]interface{}{"name": "", "profile.country": "", "missing": ""},
+			want:     map[string]interface{}{"count": int32(2)},
+			unset:    []string{"name", "profile.country"},
+		},
+		{
+			name:     "rename",
+			operator: document.OPERATOR_RENAME,
+			items:    map[string]interface{}{"name": "title", "missing": "other"},
+			want:     map[string]interface{}{"title": "a"},
+			unset:    []string{"name", "missing", "other"},
+		},
+		{
+			name:     "inc",
+			operator: document.OPERATOR_INC,
+			items:    map[string]interface{}{"count": int32(3), "score": int32(-1), "missing": int32(4)},
+			want:     map[string]interface{}{"count": int32(5), "score": 0.5, "missing": int32(4)},
+		},
+		{
+			name:     "mul",
+			operator: document.OPERATOR_MUL,
+			items:    map[string]interface{}{"count": int32(3), "score": int32(2), "missing": int32(4)},
+			want:     map[string]interface{}{"count": int32(6), "score": 3.0, "missing": int32(0)},
+		},
+		{
+			name:     "min",
+			operator: document.OPERATOR_MIN,
+			items:    map[string]interface{}{"count": int32(1), "score": 2.5, "missing": int32(4)},
+			want:     map[string]interface{}{"count": int32(1), "score": 1.5, "missing": int32(4)},
+		},
+		{
+			name:     "max",
+			operator: document.OPERATOR_MAX,
+			items:    map[string]interface{}{"count": int32(1), "score": 2.5, "name": "b"},
+			want:     map[string]interface{}{"count": int32(2), "score": 2.5, "name": "b"},
+		},
+	}
+	for _, tt := range tests {
+		create(t, db, collection, bson.D{
+			{Key: "id", Value: tt.name},
+			{Key: "name", Value: "a"},
+			{Key: "count", Value: int32(2)},
+			{Key: "score", Value: 1.5},
+			{Key: "profile", Value: bson.D{{Key: "country", Value: "France"}}},
+		})
+		filter := map[string]interface{}{"id": tt.name}
+
+		if err := db.Update(ctx, DATABASE, collection, filter, tt.operator, tt.items); err != nil {
+			t.Fatalf("Update(%v) error = %v", tt.operator, err)
+		}
+		got, err := db.Get(ctx, DATABASE, collection, filter)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		doc := *got.(*bson.D)
+
+		for path, want := range tt.want {
+			if value, _ := document.Get(doc, path); !document.Equal(value, want) {
+				t.Errorf("Update(%v) %v = %v (%T), want %v (%T)", tt.operator, path, value, value, want, want)
+			}
+		}
+		for _, path := range tt.unset {
+			if value, found := document.Get(doc, path); found {
+				t.Errorf("Update(%v) %v = %v, want unset", tt.operator, path, value)
+			}
+		}
+	}
+
+	create(t, db, collection, bson.D{{Key: "id", Value: "text"}, {Key: "name", Value: "a"}})
+	filter := map[string]interface{}{"id": "text"}
+	for _, operator := range []string{document.OPERATOR_INC, document.OPERATOR_MUL} {
+		if err := db.Update(ctx, DATABASE, collection, filter, operator, map[string]interface{}{"name": int32(1)}); err == nil {
+			t.Errorf("Update(%v) of a string succeeded", operator)
+		}
+	}
+}
+
+func testCurrentDate(t *testing.T, db interfaces.DbClient, collection string) {
+	ctx := context.Background()
+	create(t, db, collection, bson.D{{Key: "id", Value: "a"}})
+	filter := map[string]interface{}{"id": "a"}
+
+	before := time.Now().Add(-time.Second)
+	items := map[string]interface{}{
+		"updated": true,
+		"stamp":   bson.D{{Key: document.CURRENT_DATE_TYPE, Value: document.CURRENT_DATE_TIMESTAMP}},
+	}
+	if err := db.Update(ctx, DATABASE, collection, filter, document.OPERATOR_CURRENT_DATE, items); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, err := db.Get(ctx, DATABASE, collection, filter)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	doc := *got.(*bson.D)
+
+	updated, _ := document.Lookup(doc, "updated")
+	if date, ok := updated.(primitive.DateTime); !ok || date.Time().Before(before) {
+		t.Errorf("Update() updated = %v (%T), want the current date", updated, updated)
+	}
+	stamp, _ := document.Lookup(doc, "stamp")
+	if timestamp, ok := stamp.(primitive.Timestamp); !ok || int64(timestamp.T) < before.Unix() {
+		t.Errorf("Update() stamp = %v (%T), want the current timestamp", stamp, stamp)
+	}
+}
+
+func testDelete(t *testing.T, db interfaces.DbClient, collection string) {
+	ctx := context.Background()
+	create(t, db, collection, bson.D{{Key: "id", Value: "a"}}, bson.D{{Key: "id", Value: "b"}})
+	filter := map[string]interface{}{"id": "a"}
+
+	if err := db.Delete(ctx, DATABASE, collection, filter); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := db.Delete(ctx, DATABASE, collection, filter); err == nil {
+		t.Errorf("Delete() of a deleted document succeeded")
+	}
+
+	exist, err := db.DocumentExist(ctx, DATABASE, collection, filter)
+	if err != nil || exist {
+		t.Errorf("DocumentExist() = %v, %v, want false", exist, err)
+	}
+	exist, err = db.DocumentExist(ctx, DATABASE, collection, map[string]interface{}{"id": "b"})
+	if err != nil || !exist {
+		t.Errorf("DocumentExist() = %v, %v, want true", exist, err)
+	}
+}
+
+func testDocumentExist(t *testing.T, db interfaces.DbClient, collection string) {
+	ctx := context.Background()
+
+	exist, err := db.DocumentExist(ctx, DATABASE, collection, map[string]interface{}{"id": "a"})
+	if err != nil || exist {
+		t.Errorf("DocumentExist() in a missing collection = %v, %v, want false", exist, err)
+	}
+
+	create(t, db, collection, bson.D{{Key: "id", Value: "a"}, {Key: "followers", Value: bson.A{"b", "c"}}, {Key: "count", Value: int32(2)}})
+
+	tests := []struct {
+		filter map[string]interface{}
+		want   bool
+	}{
+		{filter: map[string]interface{}{}, want: true},
+		{filter: map[string]interface{}{"id": "a"}, want: true},
+		{filter: map[string]interface{}{"followers": "c"}, want: true},
+		{filter: map[string]interface{}{"id": "a", "count": int32(2)}, want: true},
+		{filter: map[string]interface{}{"id": "a", "count": int32(3)}, want: false},
+		{filter: map[string]interface{}{"id": "b"}, want: false},
+		{filter: map[string]interface{}{"missing": "a"}, want: false},
+	}
+	for _, tt := range tests {
+		exist, err := db.DocumentExist(ctx, DATABASE, collection, tt.filter)
+		if err != nil {
+			t.Fatalf("DocumentExist(%v) error = %v", tt.filter, err)
+		}
+		if exist != tt.want {
+			t.Errorf("DocumentExist(%v) = %v, want %v", tt.filter, exist, tt.want)
+		}
+	}
+}
+
+func create(t *testing.T, db interfaces.DbClient, collection string, docs ...bson.D) {
+	t.Helper()
+
+	for _, doc := range docs {
+		if _, err := db.Create(context.Background(), DATABASE, collection, doc); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+}
+
+// collection returns a collection name unique to the test and the run
+func collection(t *testing.T) string {
+	return fmt.Sprintf("%v_%v", strings.ReplaceAll(t.Name(), "/", "_"), time.Now().UnixNano())
+}
@@ -0,0 +1,41 @@
+package logging
+
+import (
+	"reflect"
+	"testing"
+
+	pb "github.com/haguru/horus/follower_service/internal/routes/protos"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  proto.Message
+		want []interface{}
+	}{
+		{
+			name: "nil message",
+			msg:  nil,
+			want: nil,
+		},
+		{
+			name: "follow",
+			msg:  &pb.Follow{Id: "user_1", FollowerId: "user_2"},
+			want: []interface{}{"id", "user_1", "follower_id", "user_2"},
+		},
+		{
+			name: "unset fields are skipped",
+			msg:  &pb.Follow{Id: "user_1"},
+			want: []interface{}{"id", "user_1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Redact(tt.msg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Redact() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/haguru/horus/follower_service/pkg/document"
+	"github.com/haguru/horus/follower_service/pkg/interfaces"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Memory is an in-memory implementation of interfaces.DbClient for tests and local development. Documents are
+// kept as bson.D so they decode like the ones returned by mongodb. Filters match fields, dotted paths included,
+// by equality
+type Memory struct {
+	collections map[string][]bson.D
+	lc          logger.LoggingClient
+	mu          sync.RWMutex
+}
+
+// NewMemory returns an empty in-memory database
+func NewMemory(lc logger.LoggingClient) interfaces.DbClient {
+	return &Memory{
+		collections: map[string][]bson.D{},
+		lc:          lc,
+	}
+}
+
+// Ping always succeeds
+func (db *Memory) Ping() error {
+	return nil
+}
+
+// Disconnect does nothing, the documents are kept until the process exits
+func (db *Memory) Disconnect(context.Context) error {
+	return nil
+}
+
+// Create stores doc and returns its ID
+func (db *Memory) Create(_ context.Context, databaseName string, collectionName string, doc interface{}) (string, error) {
+	record, err := document.ToDocument(doc)
+	if err != nil {
+		return "", err
+	}
+
+	// like mongodb, a missing _id is generated and stored as the first field
+	var objId primitive.ObjectID
+	if value, found := document.Lookup(record, document.IDFIELD); found {
+		var ok bool
+		objId, ok = value.(primitive.ObjectID)
+		if !ok {
+			return "", fmt.Errorf("failed to get objectID")
+		}
+	} else {
+		objId = primitive.NewObjectID()
+		record = append(bson.D{{Key: document.IDFIELD, Value: objId}}, record...)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	name := namespace(databaseName, collectionName)
+	for _, existing := range db.collections[name] {
+		if id, _ := document.Lookup(existing, document.IDFIELD); id == objId {
+			return "", fmt.Errorf("duplicate key error: %v", objId.Hex())
+		}
+	}
+	db.collections[name] = append(db.collections[name], record)
+
+	return objId.Hex(), nil
+}
+
+// Get retrieves the first document matching filterParams. Returns mongo.ErrNoDocuments if there is none
+func (db *Memory) Get(_ context.Context, databaseName string, collectionName string, filterParams map[string]interface{}) (interface{}, error) {
+	filter, err := document.ToDocument(filterParams)
+	if err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	i := db.find(databaseName, collectionName, filter)
+	if i < 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	data, err := document.ToDocument(db.collections[namespace(databaseName, collectionName)][i])
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// GetAll retrieves every document matching filterParams as []bson.D in insertion order
+func (db *Memory) GetAll(_ context.Context, databaseName string, collectionName string, filterParams map[string]interface{}) (interface{}, error) {
+	filter, err := document.ToDocument(filterParams)
+	if err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var docs []bson.D
+	for _, doc := range db.collections[namespace(databaseName, collectionName)] {
+		if !document.Matches(doc, filter) {
+			continue
+		}
+		data, err := document.ToDocument(doc)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, data)
+	}
+
+	return docs, nil
+}
+
+// Update applies updateOperator with items to the first document matching filterParams
+func (db *Memory) Update(_ context.Context, databaseName string, collectionName string, filterParams map[string]interface{}, updateOperator string, items map[string]interface{}) error {
+	filter, err := document.ToDocument(filterParams)
+	if err != nil {
+		return err
+	}
+	update, err := document.ToDocument(items)
+	if err != nil {
+		return err
+	}
+	if _, ok := document.OPERATORS[updateOperator]; !ok {
+		return fmt.Errorf("failed to create update command")
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	i := db.find(databaseName, collectionName, filter)
+	if i < 0 {
+		return fmt.Errorf("document not found")
+	}
+
+	name := namespace(databaseName, collectionName)
+	updated, err := document.ApplyUpdate(db.collections[name][i], updateOperator, update)
+	if err != nil {
+		return err
+	}
+	db.collections[name][i] = updated
+
+	return nil
+}
+
+// Delete removes the first document matching filterParams
+func (db *Memory) Delete(_ context.Context, databaseName string, collectionName string, filterParams map[string]interface{}) error {
+	filter, err := document.ToDocument(filterParams)
+	if err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	i := db.find(databaseName, collectionName, filter)
+	if i < 0 {
+		return fmt.Errorf("document not found")
+	}
+
+	name := namespace(databaseName, collectionName)
+	db.collections[name] = append(db.collections[name][:i], db.collections[name][i+1:]...)
+
+	return nil
+}
+
+// DocumentExist returns true if a document matches filterParams
+func (db *Memory) DocumentExist(_ context.Context, databaseName string, collectionName string, filterParams map[string]interface{}) (bool, error) {
+	filter, err := document.ToDocument(filterParams)
+	if err != nil {
+		return false, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.find(databaseName, collectionName, filter) >= 0, nil
+}
+
+// find returns the index of the first document of the collection matching filter, -1 if there is none.
+// db.mu must be held
+func (db *Memory) find(databaseName string, collectionName string, filter bson.D) int {
+	for i, doc := range db.collections[namespace(databaseName, collectionName)] {
+		if document.Matches(doc, filter) {
+			return i
+		}
+	}
+	return -1
+}
+
+func namespace(databaseName string, collectionName string) string {
+	return databaseName + "." + collectionName
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	"github.com/haguru/horus/follower_service/pkg/interfaces"
+	appMetrics "github.com/haguru/horus/follower_service/pkg/prometheus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -21,6 +22,7 @@ type MongoDB struct {
 	Client     *mongo.Client
 	timeout    time.Duration
 	lc         logger.LoggingClient
+	metrics    *appMetrics.Metrics
 }
 
 const (
@@ -29,11 +31,12 @@ const (
 )
 
 // NewMongoDB returns a interface for db client and error if it occurs
-func NewMongoDB(host string, port int, lc logger.LoggingClient, timeout time.Duration, opts *options.ServerAPIOptions) (interfaces.DbClient, error) {
+func NewMongoDB(host string, port int, lc logger.LoggingClient, metrics *appMetrics.Metrics, timeout time.Duration, opts *options.ServerAPIOptions) (interfaces.DbClient, error) {
 	db := &MongoDB{
 		Host:       host,
 		Port:       port,
 		lc:         lc,
+		metrics:    metrics,
 		ServerOpts: opts,
 		timeout:    timeout,
 	}
@@ -97,10 +100,13 @@ func (db *MongoDB) Disconnect(context context.Context) error {
 }
 
 // Create a new docment returns object id string and error if client fails to insert document into database
-func (db *MongoDB) Create(databaseName string, collectionName string, doc interface{}) (string, error) {
+func (db *MongoDB) Create(ctx context.Context, databaseName string, collectionName string, doc interface{}) (id string, err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_INSERT, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
 	collection := db.Client.Database(databaseName).Collection(collectionName)
 
-	r, err := collection.InsertOne(context.TODO(), doc)
+	r, err := collection.InsertOne(ctx, doc)
 	if err != nil {
 		return "", err
 	}
@@ -114,14 +120,17 @@ func (db *MongoDB) Create(databaseName string, collectionName string, doc interf
 }
 
 // Get reteives a document from database. Returns an interface containing the document and error if client fails to decode data.
-func (db *MongoDB) Get(databaseName string, collectionName string, filterParams map[string]interface{}) (interface{}, error) {
+func (db *MongoDB) Get(ctx context.Context, databaseName string, collectionName string, filterParams map[string]interface{}) (result interface{}, err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_FIND, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
 	collection := db.Client.Database(databaseName).Collection(collectionName)
 
 	filter := db.filter(bson.M{}, filterParams)
 
-	results := collection.FindOne(context.TODO(), filter)
+	results := collection.FindOne(ctx, filter)
 	var data bson.D
-	err := results.Decode(&data)
+	err = results.Decode(&data)
 	if err != nil {
 		db.lc.Errorf("failed to decode results: %v", err)
 		return nil, err
@@ -130,20 +139,23 @@ func (db *MongoDB) Get(databaseName string, collectionName string, filterParams
 }
 
 // GetAll reteives all documents from database based on filter. Returns an interface containing the document and error if client fails to decode data.
-func (db *MongoDB) GetAll(databaseName string, collectionName string, filterParams map[string]interface{}) (interface{}, error) {
+func (db *MongoDB) GetAll(ctx context.Context, databaseName string, collectionName string, filterParams map[string]interface{}) (result interface{}, err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_FIND, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
 	collection := db.Client.Database(databaseName).Collection(collectionName)
 
 	filter := db.filter(bson.M{}, filterParams)
 
-	cur, err := collection.Find(context.TODO(), filter)
+	cur, err := collection.Find(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 	var docs []bson.D
-	for cur.Next(context.TODO()) {
+	for cur.Next(ctx) {
 		// Create a value into which the single document can be decoded
 		var elem bson.D
-		err := cur.Decode(&elem)
+		err = cur.Decode(&elem)
 		if err != nil {
 			return nil, err
 		}
@@ -154,7 +166,10 @@ func (db *MongoDB) GetAll(databaseName string, collectionName string, filterPara
 }
 
 // Update updates a single document in database. Returns error if client fails to  update document or build update command
-func (db *MongoDB) Update(databaseName string, collectionName string, filterParams map[string]interface{}, updateOperator string, items map[string]interface{}) error {
+func (db *MongoDB) Update(ctx context.Context, databaseName string, collectionName string, filterParams map[string]interface{}, updateOperator string, items map[string]interface{}) (err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_UPDATE, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
 	collection := db.Client.Database(databaseName).Collection(collectionName)
 
 	filter := db.filter(bson.M{}, filterParams)
@@ -164,7 +179,7 @@ func (db *MongoDB) Update(databaseName string, collectionName string, filterPara
 		return err
 	}
 
-	res, err := collection.UpdateOne(context.TODO(), filter, updateItems)
+	res, err := collection.UpdateOne(ctx, filter, updateItems)
 	if err != nil {
 		return err
 	}
@@ -178,12 +193,15 @@ func (db *MongoDB) Update(databaseName string, collectionName string, filterPara
 }
 
 // Delete removes  a single document from database. Returns error if client fails to remove document
-func (db *MongoDB) Delete(databaseName string, collectionName string, filterParams map[string]interface{}) error {
+func (db *MongoDB) Delete(ctx context.Context, databaseName string, collectionName string, filterParams map[string]interface{}) (err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_DELETE, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
 	collection := db.Client.Database(databaseName).Collection(collectionName)
 
 	filter := db.filter(bson.M{}, filterParams)
 
-	res, err := collection.DeleteOne(context.TODO(), filter)
+	res, err := collection.DeleteOne(ctx, filter)
 	if err != nil {
 		return err
 	}
@@ -205,12 +223,15 @@ func (db *MongoDB) filter(bsonMap bson.M, searchParams map[string]interface{}) b
 }
 
 // DocumentExist checks to see if a document exists in database. Returns bool and error if client fails to run command.
-func (db *MongoDB) DocumentExist(databaseName string, collectionName string, filterParams map[string]interface{}) (bool, error) {
+func (db *MongoDB) DocumentExist(ctx context.Context, databaseName string, collectionName string, filterParams map[string]interface{}) (exist bool, err error) {
+	ctx, op := db.startOperation(ctx, OPERATION_COUNT, databaseName, collectionName)
+	defer func() { op.end(err) }()
+
 	collection := db.Client.Database(databaseName).Collection(collectionName)
 
 	filter := db.filter(bson.M{}, filterParams)
 
-	found, err := collection.CountDocuments(context.Background(), filter, options.Count().SetLimit(1))
+	found, err := collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
 	if err != nil {
 		return false, err
 	}
@@ -12,6 +12,7 @@ import (
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"google.golang.org/grpc/codes"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/status"
@@ -19,10 +20,20 @@ import (
 
 var BUCKETS = []float64{0.001, 0.01, 0.1, 0.3, 0.6, 1, 3, 6, 9, 20, 30, 60, 90, 120}
 
+// DB_BUCKETS are the latency buckets, in seconds, of database operations
+var DB_BUCKETS = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
 type Metrics struct {
-	Registry     *prometheus.Registry
-	HealthMetric prometheus.Gauge
-	GrpcMetrics  *grpc_prometheus.ServerMetrics
+	Registry            *prometheus.Registry
+	HealthMetric        prometheus.Gauge
+	GrpcMetrics         *grpc_prometheus.ServerMetrics
+	ConfigReloads       *prometheus.CounterVec
+	LastConfigReload    prometheus.Gauge
+	Follows             prometheus.Counter
+	Unfollows           prometheus.Counter
+	DbOperationDuration *prometheus.HistogramVec
+	DbOperationErrors   *prometheus.CounterVec
+	RateLimited         *prometheus.CounterVec
 }
 
 func NewMetrics(config *config.ServiceConfig) *Metrics {
@@ -32,6 +43,49 @@ func NewMetrics(config *config.ServiceConfig) *Metrics {
 			Name:      "health",
 			Help:      "Checks the health of the connection to DB",
 		})
+	configReloads := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.ServiceName,
+			Name:      "config_reloads_total",
+			Help:      "Number of runtime configuration reloads by result",
+		}, []string{"result"})
+	lastConfigReload := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: config.ServiceName,
+			Name:      "config_last_reload_timestamp_seconds",
+			Help:      "Unix timestamp of the last successful runtime configuration reload",
+		})
+	follows := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: config.ServiceName,
+			Name:      "follows_total",
+			Help:      "Number of follows added",
+		})
+	unfollows := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: config.ServiceName,
+			Name:      "unfollows_total",
+			Help:      "Number of follows removed",
+		})
+	dbOperationDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: config.ServiceName,
+			Name:      "db_operation_duration_seconds",
+			Help:      "Latency of database operations by operation and collection",
+			Buckets:   DB_BUCKETS,
+		}, []string{"operation", "collection"})
+	dbOperationErrors := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.ServiceName,
+			Name:      "db_operation_errors_total",
+			Help:      "Number of failed database operations by operation and collection",
+		}, []string{"operation", "collection"})
+	rateLimited := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.ServiceName,
+			Name:      "rate_limited_requests_total",
+			Help:      "Number of requests rejected by the rate limiter by method",
+		}, []string{"method"})
 	serverMetrics := grpc_prometheus.NewServerMetrics(
 		grpc_prometheus.WithServerHandlingTimeHistogram(
 			grpc_prometheus.WithHistogramBuckets(BUCKETS),
@@ -39,12 +93,23 @@ func NewMetrics(config *config.ServiceConfig) *Metrics {
 	)
 
 	metrics := &Metrics{
-		Registry:     prometheus.NewRegistry(),
-		HealthMetric: healthMetric,
-		GrpcMetrics:  serverMetrics,
+		Registry:            prometheus.NewRegistry(),
+		HealthMetric:        healthMetric,
+		GrpcMetrics:         serverMetrics,
+		ConfigReloads:       configReloads,
+		LastConfigReload:    lastConfigReload,
+		Follows:             follows,
+		Unfollows:           unfollows,
+		DbOperationDuration: dbOperationDuration,
+		DbOperationErrors:   dbOperationErrors,
+		RateLimited:         rateLimited,
 	}
 
-	metrics.Registry.MustRegister(metrics.GrpcMetrics, healthMetric)
+	// runtime and process collectors report goroutines, GC, heap, RSS and open file descriptors
+	metrics.Registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	metrics.Registry.MustRegister(metrics.GrpcMetrics, healthMetric, configReloads, lastConfigReload,
+		follows, unfollows, dbOperationDuration, dbOperationErrors,
+		rateLimited)
 
 	return metrics
 }
@@ -70,29 +135,44 @@ func InterceptorLogger(lc logger.LoggingClient) logging.Logger {
 	return logging.LoggerFunc(func(_ context.Context, lvl logging.Level, msg string, fields ...any) {
 		switch lvl {
 		case logging.LevelDebug:
-			lc.Debug(msg)
+			lc.Debug(msg, fields...)
 		case logging.LevelInfo:
-			lc.Info(msg)
+			lc.Info(msg, fields...)
 		case logging.LevelWarn:
-			lc.Warn(msg)
+			lc.Warn(msg, fields...)
 		case logging.LevelError:
-			lc.Error(msg)
+			lc.Error(msg, fields...)
 		default:
-			lc.Debugf("unknown level %v", lvl)
+			lc.Debug(msg, fields...)
 		}
 	})
 }
 
-func LogTraceID(ctx context.Context) logging.Fields {
+func ExemplarFromContext(ctx context.Context) prometheus.Labels {
 	if span := trace.SpanContextFromContext(ctx); span.IsSampled() {
-		return logging.Fields{"traceID", span.TraceID().String()}
+		return prometheus.Labels{"traceID": span.TraceID().String()}
 	}
 	return nil
 }
 
-func ExemplarFromContext(ctx context.Context) prometheus.Labels {
-	if span := trace.SpanContextFromContext(ctx); span.IsSampled() {
-		return prometheus.Labels{"traceID": span.TraceID().String()}
+// IncWithExemplar increments counter and links the increment to the trace of ctx when it is sampled
+func IncWithExemplar(ctx context.Context, counter prometheus.Counter) {
+	if exemplar := ExemplarFromContext(ctx); exemplar != nil {
+		if adder, ok := counter.(prometheus.ExemplarAdder); ok {
+			adder.AddWithExemplar(1, exemplar)
+			return
+		}
 	}
-	return nil
+	counter.Inc()
+}
+
+// ObserveWithExemplar records value and links the observation to the trace of ctx when it is sampled
+func ObserveWithExemplar(ctx context.Context, observer prometheus.Observer, value float64) {
+	if exemplar := ExemplarFromContext(ctx); exemplar != nil {
+		if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok {
+			exemplarObserver.ObserveWithExemplar(value, exemplar)
+			return
+		}
+	}
+	observer.Observe(value)
 }
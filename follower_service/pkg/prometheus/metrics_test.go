@@ -0,0 +1,101 @@
+package prometheus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/haguru/horus/follower_service/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func sampledContext(t *testing.T) context.Context {
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+}
+
+func TestIncWithExemplar(t *testing.T) {
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		wantExemplar bool
+	}{
+		{name: "sampled request", ctx: sampledContext(t), wantExemplar: true},
+		{name: "no trace", ctx: context.Background(), wantExemplar: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_total"})
+			IncWithExemplar(tt.ctx, counter)
+
+			m := &dto.Metric{}
+			if err := counter.Write(m); err != nil {
+				t.Fatal(err)
+			}
+			if m.GetCounter().GetValue() != 1 {
+				t.Errorf("IncWithExemplar() value = %v, want 1", m.GetCounter().GetValue())
+			}
+			if got := m.GetCounter().GetExemplar() != nil; got != tt.wantExemplar {
+				t.Fatalf("IncWithExemplar() exemplar = %v, want %v", got, tt.wantExemplar)
+			}
+			if tt.wantExemplar && m.GetCounter().GetExemplar().GetLabel()[0].GetValue() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+				t.Errorf("IncWithExemplar() exemplar label = %v", m.GetCounter().GetExemplar().GetLabel())
+			}
+		})
+	}
+}
+
+func TestObserveWithExemplar(t *testing.T) {
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_seconds", Buckets: DB_BUCKETS})
+	ObserveWithExemplar(sampledContext(t), histogram, 0.002)
+
+	m := &dto.Metric{}
+	if err := histogram.Write(m); err != nil {
+		t.Fatal(err)
+	}
+	if m.GetHistogram().GetSampleCount() != 1 {
+		t.Errorf("ObserveWithExemplar() count = %v, want 1", m.GetHistogram().GetSampleCount())
+	}
+
+	found := false
+	for _, bucket := range m.GetHistogram().GetBucket() {
+		if bucket.GetExemplar() != nil {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("ObserveWithExemplar() did not attach an exemplar")
+	}
+}
+
+func TestNewMetrics_RuntimeCollectors(t *testing.T) {
+	metrics := NewMetrics(&config.ServiceConfig{ServiceName: "test"})
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	found := map[string]bool{}
+	for _, family := range families {
+		found[family.GetName()] = true
+	}
+	for _, name := range []string{"go_goroutines", "go_memstats_heap_alloc_bytes", "process_resident_memory_bytes", "process_open_fds"} {
+		if !found[name] {
+			t.Errorf("Registry is missing %v", name)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/haguru/horus/follower_service/config"
+	appMetrics "github.com/haguru/horus/follower_service/pkg/prometheus"
+	"github.com/haguru/horus/follower_service/pkg/security"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	BACKEND_MEMORY  = "memory"
+	BACKEND_MONGODB = "mongodb"
+
+	DEFAULT_COLLECTION = "rate_limits"
+
+	KEY_PREFIX_USER = "user:"
+	KEY_PREFIX_IP   = "ip:"
+
+	HEADER_FORWARDED_FOR = "x-forwarded-for"
+)
+
+// Store keeps the token buckets. Take removes a token from the bucket of key and returns false with the time
+// until the next token is available when the bucket is empty
+type Store interface {
+	Take(ctx context.Context, key string, limit config.Limit) (bool, time.Duration, error)
+}
+
+// Limiter rejects requests once the caller has used up the token bucket of the method
+type Limiter struct {
+	config  *config.RateLimit
+	store   Store
+	lc      logger.LoggingClient
+	metrics *appMetrics.Metrics
+}
+
+// NewLimiter returns a Limiter applying the limits in config with the buckets kept in store
+func NewLimiter(config *config.RateLimit, store Store, lc logger.LoggingClient, metrics *appMetrics.Metrics) *Limiter {
+	return &Limiter{
+		config:  config,
+		store:   store,
+		lc:      lc,
+		metrics: metrics,
+	}
+}
+
+// Allow returns a ResourceExhausted status carrying RetryInfo if the caller of ctx exceeded the limit of method.
+// Requests are let through when the store fails so an outage of a shared backend does not take the service down
+func (l *Limiter) Allow(ctx context.Context, method string) error {
+	if !l.config.Enabled {
+		return nil
+	}
+
+	limit := l.limit(method)
+	if limit.Rate <= 0 {
+		return nil
+	}
+
+	caller := CallerKey(ctx)
+	if caller == "" {
+		return nil
+	}
+
+	allowed, retryAfter, err := l.store.Take(ctx, method+"|"+caller, limit)
+	if err != nil {
+		l.lc.Errorf("failed to check rate limit of %v: %v", method, err)
+		return nil
+	}
+	if allowed {
+		return nil
+	}
+
+	appMetrics.IncWithExemplar(ctx, l.metrics.RateLimited.WithLabelValues(method))
+
+	st, err := status.New(codes.ResourceExhausted, fmt.Sprintf("rate limit exceeded, retry in %v", retryAfter)).
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	return st.Err()
+}
+
+// UnaryServerInterceptor rejects unary calls over the limit of their method
+func (l *Limiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := l.Allow(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor rejects streams over the limit of their method. Each stream takes a single token
+func (l *Limiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := l.Allow(stream.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+// limit returns the limit configured for method, the default limit if it has none and no limit for health checks
+func (l *Limiter) limit(method string) config.Limit {
+	if strings.HasPrefix(method, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") {
+		return config.Limit{}
+	}
+	if limit, ok := l.config.Methods[method]; ok {
+		return limit
+	}
+	return l.config.Default
+}
+
+// CallerKey identifies the caller of ctx by its authenticated identity, falling back to the peer IP.
+// Requests proxied by the REST gateway of the service are keyed by the client IP forwarded by the gateway
+func CallerKey(ctx context.Context) string {
+	host, ok := peerHost(ctx)
+	if !ok {
+		return ""
+	}
+
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		if forwarded := metadata.ValueFromIncomingContext(ctx, HEADER_FORWARDED_FOR); len(forwarded) > 0 {
+			// the gateway appends the address it received the request from as the last entry
+			hops := strings.Split(forwarded[len(forwarded)-1], ",")
+			return KEY_PREFIX_IP + strings.TrimSpace(hops[len(hops)-1])
+		}
+	}
+
+	if identity, ok := security.IdentityFromContext(ctx); ok {
+		if identity.SpiffeID != "" {
+			return KEY_PREFIX_USER + identity.SpiffeID
+		}
+		if identity.Subject != "" {
+			return KEY_PREFIX_USER + identity.Subject
+		}
+	}
+
+	return KEY_PREFIX_IP + host
+}
+
+func peerHost(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "", false
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String(), true
+	}
+	return host, true
+}
+
+// burst returns the bucket size of limit, which holds at least one token
+func burst(limit config.Limit) float64 {
+	if limit.Burst < 1 {
+		return 1
+	}
+	return float64(limit.Burst)
+}
+
+// retryAfter returns the time until a bucket holding tokens has a full token again
+func retryAfter(tokens float64, limit config.Limit) time.Duration {
+	return time.Duration((1 - tokens) / limit.Rate * float64(time.Second))
+}
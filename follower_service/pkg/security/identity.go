@@ -0,0 +1,97 @@
+package security
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+const SPIFFE_SCHEME = "spiffe"
+
+// Identity describes the caller authenticated by its client certificate
+type Identity struct {
+	// SpiffeID is the spiffe:// URI SAN of the certificate, empty if it has none
+	SpiffeID string
+	// Subject is the common name of the certificate
+	Subject string
+}
+
+type identityKey struct{}
+
+// IdentityFromContext returns the identity stored by the identity interceptors
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok
+}
+
+// ContextWithIdentity returns a copy of ctx holding identity
+func ContextWithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromPeer extracts the identity from the verified client certificate of the connection
+func IdentityFromPeer(ctx context.Context) (*Identity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil, false
+	}
+
+	return IdentityFromTLS(&tlsInfo.State)
+}
+
+// IdentityFromTLS extracts the identity from the verified client certificate of a tls connection
+func IdentityFromTLS(state *tls.ConnectionState) (*Identity, bool) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+
+	return identityFromCert(state.VerifiedChains[0][0]), true
+}
+
+func identityFromCert(cert *x509.Certificate) *Identity {
+	identity := &Identity{
+		Subject: cert.Subject.CommonName,
+	}
+	for _, uri := range cert.URIs {
+		if uri.Scheme == SPIFFE_SCHEME {
+			identity.SpiffeID = uri.String()
+			break
+		}
+	}
+
+	return identity
+}
+
+// UnaryServerInterceptor stores the identity of mTLS callers in the request context
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if identity, ok := IdentityFromPeer(ctx); ok {
+			ctx = ContextWithIdentity(ctx, identity)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor stores the identity of mTLS callers in the stream context
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		identity, ok := IdentityFromPeer(stream.Context())
+		if !ok {
+			return handler(srv, stream)
+		}
+
+		wrapped := middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = ContextWithIdentity(stream.Context(), identity)
+		return handler(srv, wrapped)
+	}
+}
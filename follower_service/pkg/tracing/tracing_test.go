@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/haguru/horus/follower_service/config"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewExporter(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *config.Tracing
+		wantErr bool
+	}{
+		{
+			name:    "otlp grpc",
+			config:  &config.Tracing{Exporter: EXPORTER_OTLP_GRPC, Endpoint: "localhost:4317", Insecure: true},
+			wantErr: false,
+		},
+		{
+			name:    "otlp http",
+			config:  &config.Tracing{Exporter: EXPORTER_OTLP_HTTP, Endpoint: "localhost:4318", Insecure: true},
+			wantErr: false,
+		},
+		{
+			name:    "unknown exporter",
+			config:  &config.Tracing{Exporter: "zipkin"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewExporter(context.Background(), tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewExporter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != nil {
+				got.Shutdown(context.Background())
+			}
+		})
+	}
+}
+
+func TestNewTracerProvider(t *testing.T) {
+	tests := []struct {
+		name      string
+		ratio     float64
+		wantSpans int
+	}{
+		{name: "sample all", ratio: 1, wantSpans: 1},
+		{name: "sample none", ratio: 0, wantSpans: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			cfg := &config.Tracing{SampleRatio: tt.ratio, Attributes: map[string]string{"deployment.environment": "test"}}
+
+			tp, err := NewTracerProvider(cfg, "follower_service", exporter)
+			if err != nil {
+				t.Fatalf("NewTracerProvider() error = %v", err)
+			}
+			defer tp.Shutdown(context.Background())
+
+			_, span := tp.Tracer(TRACER_NAME).Start(context.Background(), "test")
+			span.End()
+			tp.ForceFlush(context.Background())
+
+			spans := exporter.GetSpans()
+			if len(spans) != tt.wantSpans {
+				t.Fatalf("NewTracerProvider() exported %v spans, want %v", len(spans), tt.wantSpans)
+			}
+			if tt.wantSpans == 0 {
+				return
+			}
+
+			attrs := attribute.NewSet(spans[0].Resource.Attributes()...)
+			if v, _ := attrs.Value("service.name"); v.AsString() != "follower_service" {
+				t.Errorf("NewTracerProvider() service.name = %v, want follower_service", v.AsString())
+			}
+			if v, _ := attrs.Value("deployment.environment"); v.AsString() != "test" {
+				t.Errorf("NewTracerProvider() deployment.environment = %v, want test", v.AsString())
+			}
+		})
+	}
+}
@@ -0,0 +1,551 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	crumbpb "github.com/haguru/horus/horusctl/internal/protos/crumbdb"
+	followerpb "github.com/haguru/horus/horusctl/internal/protos/followerdb"
+	useracctpb "github.com/haguru/horus/horusctl/internal/protos/useracctdb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type crumbServer struct {
+	crumbpb.UnimplementedCrumbDBServer
+	created *crumbpb.Crumb
+	update  *crumbpb.UpdateCrumbRequest
+	near    *crumbpb.FindTrailsNearRequest
+	export  *crumbpb.ExportRequest
+	imports []*crumbpb.ImportCrumbsRequest
+	zone    *crumbpb.Zone
+}
+
+func (s *crumbServer) Create(_ context.Context, crumb *crumbpb.Crumb) (*crumbpb.Id, error) {
+	s.created = crumb
+	return &crumbpb.Id{Value: "crumb_" + crumb.GetUser()}, nil
+}
+
+func (s *crumbServer) UnlockCrumb(_ context.Context, req *crumbpb.UnlockCrumbRequest) (*crumbpb.UnlockCrumbResponse, error) {
+	if req.GetPosition().GetCoordinates()[1] != 37.8 {
+		return nil, status.Error(codes.FailedPrecondition, "too far")
+	}
+	return &crumbpb.UnlockCrumbResponse{Crumb: &crumbpb.Crumb{Id: req.GetId(), Message: "secret", Locked: true}, Distance: 3}, nil
+}
+
+func (s *crumbServer) GetCrumbs(point *crumbpb.Point, stream grpc.ServerStreamingServer[crumbpb.Crumb]) error {
+	for _, id := range []string{"1", "2"} {
+		if err := stream.Send(&crumbpb.Crumb{Id: id, Location: point, User: "user_1", Message: "hi"}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *crumbServer) GetCrumb(_ context.Context, id *crumbpb.Id) (*crumbpb.Crumb, error) {
+	return &crumbpb.Crumb{Id: id.GetValue(), User: "user_1", Message: "hi", CreatedAt: 1000}, nil
+}
+
+func (s *crumbServer) BatchGetCrumbs(_ context.Context, ids *crumbpb.Ids) (*crumbpb.Crumbs, error) {
+	crumbs := &crumbpb.Crumbs{}
+	for _, id := range ids.GetValues() {
+		crumbs.Crumbs = append(crumbs.Crumbs, &crumbpb.Crumb{Id: id, User: "user_1", Message: "hi"})
+	}
+	return crumbs, nil
+}
+
+// ListCrumbsByUser returns the crumbs of the user in two pages
+func (s *crumbServer) ListCrumbsByUser(_ context.Context, req *crumbpb.ListCrumbsByUserRequest) (*crumbpb.ListCrumbsByUserResponse, error) {
+	if req.GetPageToken() == "" {
+		return &crumbpb.ListCrumbsByUserResponse{
+			Crumbs:        []*crumbpb.Crumb{{Id: "page_1", User: req.GetUser()}},
+			NextPageToken: "next",
+		}, nil
+	}
+	return &crumbpb.ListCrumbsByUserResponse{Crumbs: []*crumbpb.Crumb{{Id: "page_2", User: req.GetUser()}}}, nil
+}
+
+// Update returns the crumb of the request at the next version
+func (s *crumbServer) Update(_ context.Context, req *crumbpb.UpdateCrumbRequest) (*crumbpb.Crumb, error) {
+	s.update = req
+	crumb := proto.Clone(req.GetCrumb()).(*crumbpb.Crumb)
+	crumb.Version++
+	return crumb, nil
+}
+
+func (s *crumbServer) CreateTrail(_ context.Context, trail *crumbpb.Trail) (*crumbpb.Trail, error) {
+	created := proto.Clone(trail).(*crumbpb.Trail)
+	created.Id = "trail_" + trail.GetUser()
+	created.Version = 1
+	return created, nil
+}
+
+// AppendToTrail returns the trail of the request with the crumbs appended at the next version
+func (s *crumbServer) AppendToTrail(_ context.Context, req *crumbpb.AppendToTrailRequest) (*crumbpb.Trail, error) {
+	return &crumbpb.Trail{
+		Id:       req.GetTrailId(),
+		User:     "user_1",
+		CrumbIds: append([]string{"first"}, req.GetCrumbIds()...),
+		Version:  req.GetVersion() + 1,
+	}, nil
+}
+
+func (s *crumbServer) FindTrailsNear(_ context.Context, req *crumbpb.FindTrailsNearRequest) (*crumbpb.Trails, error) {
+	s.near = req
+	return &crumbpb.Trails{Trails: []*crumbpb.Trail{{Id: "trail_1", User: "user_1", Distance: 120.5}}}, nil
+}
+
+// PutZone returns the zone of the request with an id
+func (s *crumbServer) PutZone(_ context.Context, zone *crumbpb.Zone) (*crumbpb.Zone, error) {
+	s.zone = zone
+	saved := proto.Clone(zone).(*crumbpb.Zone)
+	saved.Id = "zone_" + zone.GetRuleId()
+	return saved, nil
+}
+
+func (s *crumbServer) DeleteZone(_ context.Context, id *crumbpb.Id) (*crumbpb.Id, error) {
+	return id, nil
+}
+
+func (s *crumbServer) ListZones(_ context.Context, _ *crumbpb.ListZonesRequest) (*crumbpb.Zones, error) {
+	return &crumbpb.Zones{Zones: []*crumbpb.Zone{{Id: "zone_harbor", RuleId: "harbor", Name: "Harbor", MaxCrumbs: 3}}}, nil
+}
+
+// Export streams a document of the requested format in two chunks
+func (s *crumbServer) Export(req *crumbpb.ExportRequest, stream grpc.ServerStreamingServer[crumbpb.ExportChunk]) error {
+	s.export = req
+	for _, data := range []string{"<" + req.GetFormat().String() + ">", "</document>"} {
+		if err := stream.Send(&crumbpb.ExportChunk{Data: []byte(data)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportCrumbs accepts the waypoints and rejects the features
+func (s *crumbServer) ImportCrumbs(stream grpc.ClientStreamingServer[crumbpb.ImportCrumbsRequest, crumbpb.ImportReport]) error {
+	s.imports = nil
+	report := &crumbpb.ImportReport{}
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(report)
+		}
+		if err != nil {
+			return err
+		}
+		s.imports = append(s.imports, req)
+		result := &crumbpb.ImportResult{Index: int32(len(s.imports) - 1)}
+		if req.GetWaypoint() != "" {
+			result.Accepted = true
+			result.Id = fmt.Sprintf("imported_%v", result.Index)
+			report.Accepted++
+		} else {
+			result.Reason = "not a waypoint"
+			report.Rejected++
+		}
+		report.Results = append(report.Results, result)
+	}
+}
+
+type userAcctServer struct {
+	useracctpb.UnimplementedUserAcctDBServer
+	password string
+}
+
+func (s *userAcctServer) GetUser(_ context.Context, req *useracctpb.UserRequest) (*useracctpb.User, error) {
+	return &useracctpb.User{Id: "42", Email: req.GetEmail(), Username: "user_1", Password: "hash"}, nil
+}
+
+func (s *userAcctServer) UpdatePassword(_ context.Context, req *useracctpb.PasswordRequest) (*useracctpb.Status, error) {
+	s.password = req.GetPassword()
+	return &useracctpb.Status{Value: 1}, nil
+}
+
+type followerServer struct {
+	followerpb.UnimplementedFollowerDBServer
+}
+
+func (s *followerServer) GetFollowers(_ *followerpb.Id, stream grpc.ServerStreamingServer[followerpb.Id]) error {
+	return stream.Send(&followerpb.Id{Value: "user_2"})
+}
+
+// serve starts a gRPC server with health and reflection like the services and returns its address
+func serve(t *testing.T, service string, register func(*grpc.Server), status healthpb.HealthCheckResponse_ServingStatus) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	server := grpc.NewServer()
+	register(server)
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(service, status)
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	return lis.Addr().String()
+}
+
+func writeFile(t *testing.T, name string, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write %v: %v", path, err)
+	}
+	return path
+}
+
+func TestRootCommand(t *testing.T) {
+	users := &userAcctServer{}
+	crumbs := &crumbServer{}
+	crumbAddr := serve(t, "crumbdb_service", func(s *grpc.Server) { crumbpb.RegisterCrumbDBServer(s, crumbs) }, healthpb.HealthCheckResponse_SERVING)
+	userAddr := serve(t, "useracct_service", func(s *grpc.Server) { useracctpb.RegisterUserAcctDBServer(s, users) }, healthpb.HealthCheckResponse_SERVING)
+	followerAddr := serve(t, "follower_service", func(s *grpc.Server) { followerpb.RegisterFollowerDBServer(s, &followerServer{}) }, healthpb.HealthCheckResponse_NOT_SERVING)
+	addrs := []string{"--crumbdb-addr", crumbAddr, "--useracct-addr", userAddr, "--follower-addr", followerAddr}
+	exportFile := filepath.Join(t.TempDir(), "crumbs.kml")
+	gpxFile := writeFile(t, "legacy.gpx", `<?xml version="1.0"?>
+<gpx xmlns="http://www.topografix.com/GPX/1/1"><wpt lat="1" lon="2"><name>one</name></wpt>
+<wpt lat="3" lon="4"/><trk><trkseg><trkpt lat="5" lon="6"/></trkseg></trk></gpx>`)
+	geoJSONFile := writeFile(t, "legacy.json", `{"type":"FeatureCollection","features":[{"type":"Feature","properties":{"message":"hi"}}]}`)
+	emptyFile := writeFile(t, "empty.gpx", `<gpx></gpx>`)
+
+	tests := []struct {
+		name     string
+		args     []string
+		stdin    string
+		want     []string
+		wantNot  []string
+		wantErr  bool
+		validate func(t *testing.T)
+	}{
+		{
+			name: "crumbs create",
+			args: []string{"crumbs", "create", "--user", "user_1", "--message", "hi", "--lng", "-122.4", "--lat", "37.8"},
+			want: []string{"ID\n", "crumb_user_1\n"},
+			validate: func(t *testing.T) {
+				if location := crumbs.created.GetLocation(); location.Altitude != nil || location.Accuracy != nil {
+					t.Errorf("location = %v, want no altitude or accuracy", location)
+				}
+			},
+		},
+		{
+			name: "crumbs create with altitude and accuracy",
+			args: []string{"crumbs", "create", "--user", "user_1", "--message", "hi", "--lng", "-122.4", "--lat", "37.8", "--altitude", "0", "--accuracy", "12.5"},
+			want: []string{"crumb_user_1\n"},
+			validate: func(t *testing.T) {
+				if location := crumbs.created.GetLocation(); location.Altitude == nil || location.GetAccuracy() != 12.5 {
+					t.Errorf("location = %v, want altitude 0 and accuracy 12.5", location)
+				}
+			},
+		},
+		{
+			name: "crumbs create locked",
+			args: []string{"crumbs", "create", "--user", "user_1", "--message", "hi", "--lng", "-122.4", "--lat", "37.8", "--locked", "--unlock-radius", "20"},
+			want: []string{"crumb_user_1\n"},
+			validate: func(t *testing.T) {
+				if created := crumbs.created; !created.GetLocked() || created.GetUnlockRadius() != 20 {
+					t.Errorf("created crumb = %v, want locked within 20 meters", created)
+				}
+			},
+		},
+		{
+			name: "crumbs unlock",
+			args: []string{"crumbs", "unlock", "abc", "--lng", "-122.4", "--lat", "37.8"},
+			want: []string{"abc", "secret"},
+		},
+		{
+			name:    "crumbs unlock from too far",
+			args:    []string{"crumbs", "unlock", "abc", "--lng", "-122.4", "--lat", "37.9"},
+			wantErr: true,
+		},
+		{
+			name: "crumbs near as json",
+			args: []string{"-o", "json", "crumbs", "near", "--lng", "-122.4", "--lat", "37.8"},
+			want: []string{`"id": "1"`, `"id": "2"`, `"coordinates": [`},
+		},
+		{
+			name: "crumbs get",
+			args: []string{"crumbs", "get", "abc"},
+			want: []string{"abc", "user_1", "1000"},
+		},
+		{
+			name: "crumbs get several",
+			args: []string{"-o", "json", "crumbs", "get", "abc", "def"},
+			want: []string{`"id": "abc"`, `"id": "def"`},
+		},
+		{
+			name: "crumbs list follows the pages",
+			args: []string{"crumbs", "list", "--user", "user_2"},
+			want: []string{"page_1", "page_2", "user_2"},
+		},
+		{
+			name: "crumbs update sends the changed fields",
+			args: []string{"crumbs", "update", "abc", "--lat", "48.85", "--lng", "2.35", "--visibility", "followers", "--version", "3"},
+			want: []string{"abc", "4\n"},
+			validate: func(t *testing.T) {
+				want := []string{"visibility", "location"}
+				if got := crumbs.update.GetUpdateMask().GetPaths(); strings.Join(got, ",") != strings.Join(want, ",") {
+					t.Errorf("update mask = %v, want %v", got, want)
+				}
+				if got := crumbs.update.GetCrumb(); got.GetVisibility() != crumbpb.Visibility_VISIBILITY_FOLLOWERS || got.GetVersion() != 3 {
+					t.Errorf("updated crumb = %v, want followers only at version 3", got)
+				}
+			},
+		},
+		{
+			name: "crumbs update shares a crumb with specific users",
+			args: []string{"crumbs", "update", "abc", "--visibility", "specific_users", "--allowed-users", "alice,bob", "--version", "1"},
+			want: []string{"abc"},
+			validate: func(t *testing.T) {
+				want := []string{"visibility", "allowed_users"}
+				if got := crumbs.update.GetUpdateMask().GetPaths(); strings.Join(got, ",") != strings.Join(want, ",") {
+					t.Errorf("update mask = %v, want %v", got, want)
+				}
+				if got := crumbs.update.GetCrumb(); got.GetVisibility() != crumbpb.Visibility_VISIBILITY_SPECIFIC_USERS || strings.Join(got.GetAllowedUsers(), ",") != "alice,bob" {
+					t.Errorf("updated crumb = %v, want visible to alice and bob", got)
+				}
+			},
+		},
+		{
+			name:    "crumbs update without changes",
+			args:    []string{"crumbs", "update", "abc", "--version", "3"},
+			wantErr: true,
+		},
+		{
+			name:    "crumbs update with an invalid visibility",
+			args:    []string{"crumbs", "update", "abc", "--visibility", "everyone", "--version", "3"},
+			wantErr: true,
+		},
+		{
+			name: "trails create",
+			args: []string{"trails", "create", "--user", "user_1", "--name", "walk", "abc", "def"},
+			want: []string{"trail_user_1", "walk", "abc", "def"},
+		},
+		{
+			name: "trails append",
+			args: []string{"-o", "json", "trails", "append", "trail_1", "abc", "--version", "2"},
+			want: []string{`"id": "trail_1"`, `"first"`, `"abc"`, `"version": "3"`},
+		},
+		{
+			name:    "trails append without crumbs",
+			args:    []string{"trails", "append", "trail_1"},
+			wantErr: true,
+		},
+		{
+			name: "zones put",
+			args: []string{"zones", "put", "--rule", "harbor", "--max-crumbs", "3", "--area", "-122.31,37.69 -122.29,37.69 -122.29,37.71 -122.31,37.69"},
+			want: []string{"zone_harbor", "harbor", "3"},
+			validate: func(t *testing.T) {
+				if area := crumbs.zone.GetArea(); area.GetType() != "Polygon" || len(area.GetCoordinates()) != 8 || area.GetCoordinates()[0] != -122.31 {
+					t.Errorf("area = %v, want the polygon of the four vertices", area)
+				}
+			},
+		},
+		{
+			name:    "zones put with an invalid vertex",
+			args:    []string{"zones", "put", "--rule", "harbor", "--area", "-122.31 37.69"},
+			wantErr: true,
+		},
+		{
+			name: "zones list",
+			args: []string{"zones", "list"},
+			want: []string{"RULE_ID", "zone_harbor", "Harbor"},
+		},
+		{
+			name: "zones delete",
+			args: []string{"zones", "delete", "zone_harbor"},
+			want: []string{"zone_harbor\n"},
+		},
+		{
+			name: "trails near",
+			args: []string{"trails", "near", "--lng", "2.35", "--lat", "48.85", "--radius", "250"},
+			want: []string{"trail_1", "120.5"},
+			validate: func(t *testing.T) {
+				if got := crumbs.near; got.GetRadius() != 250 || got.GetLocation().GetCoordinates()[1] != 48.85 {
+					t.Errorf("request = %v, want 250 meters around [2.35, 48.85]", got)
+				}
+			},
+		},
+		{
+			name: "export of a user",
+			args: []string{"export", "--user", "user_1"},
+			want: []string{"<EXPORT_FORMAT_GEOJSON></document>"},
+		},
+		{
+			name: "export near a location to a file",
+			args: []string{"export", "--lng", "2.35", "--lat", "48.85", "--format", "kml", "--file", exportFile},
+			validate: func(t *testing.T) {
+				data, err := os.ReadFile(exportFile)
+				if err != nil || string(data) != "<EXPORT_FORMAT_KML></document>" {
+					t.Errorf("exported file = %q, %v, want the kml document", data, err)
+				}
+				if got := crumbs.export; got.GetUser() != "" || got.GetLocation().GetCoordinates()[0] != 2.35 {
+					t.Errorf("request = %v, want the crumbs around [2.35, 48.85]", got)
+				}
+			},
+		},
+		{
+			name:    "export of an unknown format",
+			args:    []string{"export", "--user", "user_1", "--format", "shp"},
+			wantErr: true,
+		},
+		{
+			name:    "export of a user and a location",
+			args:    []string{"export", "--user", "user_1", "--lng", "2.35", "--lat", "48.85"},
+			wantErr: true,
+		},
+		{
+			name: "crumbs import of the waypoints of a gpx file",
+			args: []string{"crumbs", "import", gpxFile, "--user", "user_1", "--dry-run"},
+			want: []string{"imported_0", "imported_1"},
+			validate: func(t *testing.T) {
+				if len(crumbs.imports) != 2 || !crumbs.imports[0].GetDryRun() || crumbs.imports[0].GetUser() != "user_1" {
+					t.Fatalf("imports = %v, want 2 waypoints with the options on the first", crumbs.imports)
+				}
+				if got := crumbs.imports[1].GetWaypoint(); got != `<wpt lat="3" lon="4"/>` {
+					t.Errorf("waypoint = %v, want the element of the file", got)
+				}
+			},
+		},
+		{
+			name: "crumbs import of a geojson file",
+			args: []string{"-o", "json", "crumbs", "import", geoJSONFile},
+			want: []string{`"reason": "not a waypoint"`},
+			validate: func(t *testing.T) {
+				if len(crumbs.imports) != 1 || !strings.Contains(crumbs.imports[0].GetFeature(), `"message":"hi"`) {
+					t.Errorf("imports = %v, want the feature of the file", crumbs.imports)
+				}
+			},
+		},
+		{
+			name:    "crumbs import of a file without crumbs",
+			args:    []string{"crumbs", "import", emptyFile},
+			wantErr: true,
+		},
+		{
+			name:    "crumbs import of a gpx file as geojson",
+			args:    []string{"crumbs", "import", gpxFile, "--format", "geojson"},
+			wantErr: true,
+		},
+		{
+			name:    "users get hides the password",
+			args:    []string{"-o", "yaml", "users", "get", "user@example.com"},
+			want:    []string{"email: user@example.com", "username: user_1"},
+			wantNot: []string{"password", "hash"},
+		},
+		{
+			name:  "users reset-password from stdin",
+			args:  []string{"users", "reset-password", "user@example.com", "--password-stdin"},
+			stdin: "0123456789\n",
+			want:  []string{"user@example.com  1"},
+			validate: func(t *testing.T) {
+				if users.password != "0123456789" {
+					t.Errorf("password = %q, want 0123456789", users.password)
+				}
+			},
+		},
+		{
+			name: "follows list",
+			args: []string{"follows", "list", "user_1"},
+			want: []string{"FOLLOWER_ID\nuser_2\n"},
+		},
+		{
+			name:    "health fails when a service is not serving",
+			args:    []string{"health"},
+			want:    []string{"crumbdb_service", "SERVING", "follower_service", "NOT_SERVING"},
+			wantErr: true,
+		},
+		{
+			name: "health of a single service",
+			args: []string{"health", "crumbdb"},
+			want: []string{"crumbdb_service"},
+		},
+		{
+			name: "describe lists services",
+			args: []string{"describe", "crumbdb"},
+			want: []string{"crumbdb.CrumbDB", "grpc.health.v1.Health"},
+		},
+		{
+			name: "describe service",
+			args: []string{"describe", "crumbdb", "crumbdb.CrumbDB"},
+			want: []string{"crumbdb.CrumbDB.ListCrumbsByUser  crumbdb.ListCrumbsByUserRequest     crumbdb.ListCrumbsByUserResponse", "stream crumbdb.Crumb", "stream crumbdb.ImportCrumbsRequest  crumbdb.ImportReport"},
+		},
+		{
+			name: "describe message",
+			args: []string{"describe", "follower", "followerdb.Follow"},
+			want: []string{"follower_id  2       string"},
+		},
+		{
+			name:    "unknown output format",
+			args:    []string{"-o", "xml", "health"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			root := NewRootCommand()
+			root.SetArgs(append(addrs, tt.args...))
+			root.SetIn(strings.NewReader(tt.stdin))
+			root.SetOut(stdout)
+			root.SetErr(&bytes.Buffer{})
+
+			err := root.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("output %q does not contain %q", stdout.String(), want)
+				}
+			}
+			for _, unwanted := range tt.wantNot {
+				if strings.Contains(stdout.String(), unwanted) {
+					t.Errorf("output %q contains %q", stdout.String(), unwanted)
+				}
+			}
+			if tt.validate != nil {
+				tt.validate(t)
+			}
+		})
+	}
+}
+
+func TestRootCommand_Commands(t *testing.T) {
+	// the commands horusctl was asked for, crumbs get and export included
+	commands := []string{
+		"crumbs create", "crumbs near", "crumbs get", "crumbs delete", "export",
+		"users create", "users get", "users delete", "users reset-password",
+		"follows add", "follows list", "follows unfollow",
+		"health", "services", "describe",
+	}
+	root := NewRootCommand()
+	for _, command := range commands {
+		found, _, err := root.Find(strings.Fields(command))
+		if err != nil || found.CommandPath() != "horusctl "+command {
+			t.Errorf("Find(%q) = %v, %v, want the %q command", command, found.CommandPath(), err, command)
+		}
+	}
+}
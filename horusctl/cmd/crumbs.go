@@ -0,0 +1,513 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/haguru/horus/horusctl/internal/output"
+	pb "github.com/haguru/horus/horusctl/internal/protos/crumbdb"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+const POINT_TYPE = "Point"
+
+// CRUMB_COLUMNS are the crumb fields shown in tables
+var CRUMB_COLUMNS = []string{"id", "user", "message", "location", "created_at", "version"}
+
+// VISIBILITY_PREFIX is the prefix of the Visibility values left out of the --visibility flag
+const VISIBILITY_PREFIX = "VISIBILITY_"
+
+// IMPORT_COLUMNS are the fields of the import results shown in tables
+var IMPORT_COLUMNS = []string{"index", "accepted", "id", "reason"}
+
+const (
+	IMPORT_FORMAT_GEOJSON = "geojson"
+	IMPORT_FORMAT_GPX     = "gpx"
+)
+
+func newCrumbsCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "crumbs",
+		Short: "Manage crumbs",
+	}
+
+	cmd.AddCommand(
+		newCrumbsCreateCommand(opts),
+		newCrumbsGetCommand(opts),
+		newCrumbsListCommand(opts),
+		newCrumbsNearCommand(opts),
+		newCrumbsUpdateCommand(opts),
+		newCrumbsUnlockCommand(opts),
+		newCrumbsDeleteCommand(opts),
+		newCrumbsImportCommand(opts),
+	)
+
+	return cmd
+}
+
+func newCrumbsCreateCommand(opts *options) *cobra.Command {
+	var user, message string
+	var lng, lat, altitude, accuracy, unlockRadius float64
+	var locked bool
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Drop a crumb at a location",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			location := &pb.Point{Type: POINT_TYPE, Coordinates: []float64{lng, lat}}
+			if cmd.Flags().Changed("altitude") {
+				location.Altitude = &altitude
+			}
+			if cmd.Flags().Changed("accuracy") {
+				location.Accuracy = &accuracy
+			}
+
+			client, err := crumbDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			id, err := client.Create(ctx, &pb.Crumb{
+				Location:     location,
+				User:         user,
+				Message:      message,
+				Locked:       locked,
+				UnlockRadius: unlockRadius,
+			})
+			if err != nil {
+				return err
+			}
+
+			return opts.printer.PrintOne([]string{"id"}, output.Record{"id": id.GetValue()})
+		},
+	}
+
+	cmd.Flags().StringVar(&user, "user", "", "user dropping the crumb")
+	cmd.Flags().StringVar(&message, "message", "", "message of the crumb")
+	cmd.Flags().Float64Var(&lng, "lng", 0, "longitude of the crumb")
+	cmd.Flags().Float64Var(&lat, "lat", 0, "latitude of the crumb")
+	cmd.Flags().Float64Var(&altitude, "altitude", 0, "altitude of the crumb in meters, optional")
+	cmd.Flags().Float64Var(&accuracy, "accuracy", 0, "horizontal accuracy of the location in meters, optional")
+	cmd.Flags().BoolVar(&locked, "locked", false, "only show the message to users within the unlock radius")
+	cmd.Flags().Float64Var(&unlockRadius, "unlock-radius", 0, "unlock radius of a locked crumb in meters, the service default if 0")
+	for _, flag := range []string{"user", "message", "lng", "lat"} {
+		_ = cmd.MarkFlagRequired(flag)
+	}
+
+	return cmd
+}
+
+func newCrumbsGetCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <id>...",
+		Short: "Show crumbs by id",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := crumbDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			if len(args) == 1 {
+				crumb, err := client.GetCrumb(ctx, &pb.Id{Value: args[0]})
+				if err != nil {
+					return err
+				}
+				record, err := output.FromProto(crumb)
+				if err != nil {
+					return err
+				}
+				return opts.printer.PrintOne(CRUMB_COLUMNS, record)
+			}
+
+			crumbs, err := client.BatchGetCrumbs(ctx, &pb.Ids{Values: args})
+			if err != nil {
+				return err
+			}
+			return printCrumbs(opts, crumbs.GetCrumbs())
+		},
+	}
+}
+
+func newCrumbsListCommand(opts *options) *cobra.Command {
+	var user string
+	var pageSize int32
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the crumbs of a user, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := crumbDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			var crumbs []*pb.Crumb
+			req := &pb.ListCrumbsByUserRequest{User: user, PageSize: pageSize}
+			for {
+				page, err := client.ListCrumbsByUser(ctx, req)
+				if err != nil {
+					return err
+				}
+				crumbs = append(crumbs, page.GetCrumbs()...)
+				if page.GetNextPageToken() == "" {
+					break
+				}
+				req.PageToken = page.GetNextPageToken()
+			}
+
+			return printCrumbs(opts, crumbs)
+		},
+	}
+
+	cmd.Flags().StringVar(&user, "user", "", "user who dropped the crumbs")
+	cmd.Flags().Int32Var(&pageSize, "page-size", 0, "crumbs requested per call, the service default if 0")
+	_ = cmd.MarkFlagRequired("user")
+
+	return cmd
+}
+
+func newCrumbsNearCommand(opts *options) *cobra.Command {
+	var lng, lat float64
+
+	cmd := &cobra.Command{
+		Use:   "near",
+		Short: "List the crumbs near a location",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := crumbDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			stream, err := client.GetCrumbs(ctx, &pb.Point{Type: POINT_TYPE, Coordinates: []float64{lng, lat}})
+			if err != nil {
+				return err
+			}
+
+			var crumbs []*pb.Crumb
+			for {
+				crumb, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					return err
+				}
+				crumbs = append(crumbs, crumb)
+			}
+
+			return printCrumbs(opts, crumbs)
+		},
+	}
+
+	cmd.Flags().Float64Var(&lng, "lng", 0, "longitude to search around")
+	cmd.Flags().Float64Var(&lat, "lat", 0, "latitude to search around")
+	_ = cmd.MarkFlagRequired("lng")
+	_ = cmd.MarkFlagRequired("lat")
+
+	return cmd
+}
+
+func newCrumbsUpdateCommand(opts *options) *cobra.Command {
+	var message, visibility string
+	var lng, lat float64
+	var tags, allowedUsers []string
+	var version int64
+
+	cmd := &cobra.Command{
+		Use:   "update <id>",
+		Short: "Change the message, location, visibility or tags of a crumb",
+		Long: "Change the fields of a crumb given by flags, leaving the others as they are. The update fails if\n" +
+			"another one was made since --version, the version the crumb was read at",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			crumb := &pb.Crumb{Id: args[0], Message: message, Tags: tags, AllowedUsers: allowedUsers, Version: version}
+			mask := &fieldmaskpb.FieldMask{}
+			for _, field := range []string{"message", "visibility", "tags"} {
+				if cmd.Flags().Changed(field) {
+					mask.Paths = append(mask.Paths, field)
+				}
+			}
+			if cmd.Flags().Changed("allowed-users") {
+				mask.Paths = append(mask.Paths, "allowed_users")
+			}
+			if cmd.Flags().Changed("lng") || cmd.Flags().Changed("lat") {
+				crumb.Location = &pb.Point{Type: POINT_TYPE, Coordinates: []float64{lng, lat}}
+				mask.Paths = append(mask.Paths, "location")
+			}
+			if len(mask.Paths) == 0 {
+				return fmt.Errorf("nothing to update, set --message, --lng and --lat, --visibility, --tags or --allowed-users")
+			}
+			if cmd.Flags().Changed("visibility") {
+				value, ok := pb.Visibility_value[VISIBILITY_PREFIX+strings.ToUpper(visibility)]
+				if !ok {
+					return fmt.Errorf("invalid visibility %q", visibility)
+				}
+				crumb.Visibility = pb.Visibility(value)
+			}
+
+			client, err := crumbDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			updated, err := client.Update(ctx, &pb.UpdateCrumbRequest{Crumb: crumb, UpdateMask: mask})
+			if err != nil {
+				return err
+			}
+			record, err := output.FromProto(updated)
+			if err != nil {
+				return err
+			}
+
+			return opts.printer.PrintOne(CRUMB_COLUMNS, record)
+		},
+	}
+
+	cmd.Flags().StringVar(&message, "message", "", "new message of the crumb")
+	cmd.Flags().Float64Var(&lng, "lng", 0, "new longitude of the crumb, requires --lat")
+	cmd.Flags().Float64Var(&lat, "lat", 0, "new latitude of the crumb, requires --lng")
+	cmd.Flags().StringVar(&visibility, "visibility", "", "who can see the crumb: public, followers, private or specific_users")
+	cmd.Flags().StringSliceVar(&tags, "tags", nil, "new tags of the crumb, replacing the current ones")
+	cmd.Flags().StringSliceVar(&allowedUsers, "allowed-users", nil, "users who can see a crumb of specific_users visibility, replacing the current ones")
+	cmd.Flags().Int64Var(&version, "version", 0, "version the crumb must be at, as shown by crumbs get")
+	cmd.MarkFlagsRequiredTogether("lng", "lat")
+	_ = cmd.MarkFlagRequired("version")
+
+	return cmd
+}
+
+func newCrumbsUnlockCommand(opts *options) *cobra.Command {
+	var lng, lat float64
+
+	cmd := &cobra.Command{
+		Use:   "unlock <id>",
+		Short: "Read the message of a locked crumb from where you are",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := crumbDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			unlocked, err := client.UnlockCrumb(ctx, &pb.UnlockCrumbRequest{
+				Id:       args[0],
+				Position: &pb.Point{Type: POINT_TYPE, Coordinates: []float64{lng, lat}},
+			})
+			if err != nil {
+				return err
+			}
+			record, err := output.FromProto(unlocked.GetCrumb())
+			if err != nil {
+				return err
+			}
+
+			return opts.printer.PrintOne(CRUMB_COLUMNS, record)
+		},
+	}
+
+	cmd.Flags().Float64Var(&lng, "lng", 0, "longitude you are at")
+	cmd.Flags().Float64Var(&lat, "lat", 0, "latitude you are at")
+	_ = cmd.MarkFlagRequired("lng")
+	_ = cmd.MarkFlagRequired("lat")
+
+	return cmd
+}
+
+func newCrumbsDeleteCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <id>",
+		Short: "Delete a crumb",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := crumbDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			id, err := client.Delete(ctx, &pb.Id{Value: args[0]})
+			if err != nil {
+				return err
+			}
+
+			return opts.printer.PrintOne([]string{"id"}, output.Record{"id": id.GetValue()})
+		},
+	}
+}
+
+func newCrumbsImportCommand(opts *options) *cobra.Command {
+	var user, format string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import crumbs from a GeoJSON FeatureCollection or the waypoints of a GPX file",
+		Long: "Import the Point features of a GeoJSON FeatureCollection, or the waypoints of a GPX file, as crumbs and\n" +
+			"show which were accepted and why the others were rejected. The format is taken from the extension of\n" +
+			"the file unless --format is set",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format == "" {
+				format = IMPORT_FORMAT_GEOJSON
+				if strings.EqualFold(filepath.Ext(args[0]), "."+IMPORT_FORMAT_GPX) {
+					format = IMPORT_FORMAT_GPX
+				}
+			}
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to read %v: %v", args[0], err)
+			}
+			var records []*pb.ImportCrumbsRequest
+			switch format {
+			case IMPORT_FORMAT_GEOJSON:
+				records, err = geoJSONRecords(data)
+			case IMPORT_FORMAT_GPX:
+				records, err = gpxRecords(data)
+			default:
+				return fmt.Errorf("invalid format %q, want geojson or gpx", format)
+			}
+			if err != nil {
+				return err
+			}
+			if len(records) == 0 {
+				return fmt.Errorf("no crumbs to import in %v", args[0])
+			}
+			records[0].User = user
+			records[0].DryRun = dryRun
+
+			client, err := crumbDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			stream, err := client.ImportCrumbs(ctx)
+			if err != nil {
+				return err
+			}
+			for _, record := range records {
+				// the error of a failed send is returned by CloseAndRecv
+				if err := stream.Send(record); err != nil {
+					break
+				}
+			}
+			report, err := stream.CloseAndRecv()
+			if err != nil {
+				return err
+			}
+
+			results := make([]output.Record, 0, len(report.GetResults()))
+			for _, result := range report.GetResults() {
+				record, err := output.FromProto(result)
+				if err != nil {
+					return err
+				}
+				results = append(results, record)
+			}
+			if err := opts.printer.PrintList(IMPORT_COLUMNS, results); err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(cmd.ErrOrStderr(), "%v accepted, %v rejected\n", report.GetAccepted(), report.GetRejected())
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&user, "user", "", "user of the crumbs without one")
+	cmd.Flags().StringVar(&format, "format", "", "format of the file: geojson or gpx")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "validate the crumbs without importing them")
+
+	return cmd
+}
+
+// geoJSONRecords returns the import records of the features of a GeoJSON FeatureCollection
+func geoJSONRecords(data []byte) ([]*pb.ImportCrumbsRequest, error) {
+	var collection struct {
+		Type     string            `json:"type"`
+		Features []json.RawMessage `json:"features"`
+	}
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("invalid geojson: %v", err)
+	}
+	if collection.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("geojson type must be FeatureCollection, got %q", collection.Type)
+	}
+
+	records := make([]*pb.ImportCrumbsRequest, 0, len(collection.Features))
+	for _, feature := range collection.Features {
+		records = append(records, &pb.ImportCrumbsRequest{Record: &pb.ImportCrumbsRequest_Feature{Feature: string(feature)}})
+	}
+	return records, nil
+}
+
+// gpxRecords returns the import records of the wpt elements of a GPX document, as they are in the document
+func gpxRecords(data []byte) ([]*pb.ImportCrumbsRequest, error) {
+	var records []*pb.ImportCrumbsRequest
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		start := dec.InputOffset()
+		token, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid gpx: %v", err)
+		}
+		element, ok := token.(xml.StartElement)
+		if !ok || element.Name.Local != "wpt" {
+			continue
+		}
+		if err := dec.Skip(); err != nil {
+			return nil, fmt.Errorf("invalid gpx: %v", err)
+		}
+		waypoint := string(data[start:dec.InputOffset()])
+		records = append(records, &pb.ImportCrumbsRequest{Record: &pb.ImportCrumbsRequest_Waypoint{Waypoint: waypoint}})
+	}
+}
+
+func printCrumbs(opts *options, crumbs []*pb.Crumb) error {
+	records := make([]output.Record, 0, len(crumbs))
+	for _, crumb := range crumbs {
+		record, err := output.FromProto(crumb)
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+	}
+
+	return opts.printer.PrintList(CRUMB_COLUMNS, records)
+}
+
+func crumbDBClient(opts *options) (pb.CrumbDBClient, error) {
+	conn, err := opts.dial(BACKEND_CRUMBDB)
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewCrumbDBClient(conn), nil
+}
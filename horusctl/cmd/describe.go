@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/haguru/horus/horusctl/internal/output"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func newDescribeCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "describe <service> [symbol]",
+		Short: "Describe the gRPC API of a service through server reflection",
+		Long: "Describe the gRPC API of a service through server reflection. Without a symbol the exposed gRPC " +
+			"services are listed, a service symbol lists its methods and a message symbol lists its fields",
+		Example:   "  horusctl describe crumbdb crumbdb.CrumbDB\n  horusctl describe crumbdb crumbdb.Crumb",
+		ValidArgs: BACKENDS,
+		Args:      cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validBackend(args[0]); err != nil {
+				return err
+			}
+			conn, err := opts.dial(args[0])
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			if len(args) == 1 {
+				names, err := listServices(ctx, conn)
+				if err != nil {
+					return err
+				}
+				records := make([]output.Record, 0, len(names))
+				for _, name := range names {
+					records = append(records, output.Record{"service": name})
+				}
+				return opts.printer.PrintList([]string{"service"}, records)
+			}
+
+			descriptor, err := resolveSymbol(ctx, conn, args[1])
+			if err != nil {
+				return err
+			}
+
+			switch d := descriptor.(type) {
+			case protoreflect.ServiceDescriptor:
+				return opts.printer.PrintList([]string{"method", "request", "response"}, describeMethods(d))
+			case protoreflect.MethodDescriptor:
+				return opts.printer.PrintOne([]string{"method", "request", "response"}, describeMethod(d))
+			case protoreflect.MessageDescriptor:
+				return opts.printer.PrintList([]string{"field", "number", "type"}, describeFields(d))
+			default:
+				return fmt.Errorf("%v is not a service, method or message", args[1])
+			}
+		},
+	}
+}
+
+// listServices returns the names of the gRPC services exposed on conn
+func listServices(ctx context.Context, conn *grpc.ClientConn) ([]string, error) {
+	resp, err := reflectionRequest(ctx, conn, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		names = append(names, service.GetName())
+	}
+	return names, nil
+}
+
+// resolveSymbol returns the descriptor of the fully qualified symbol built from the files the server returns
+func resolveSymbol(ctx context.Context, conn *grpc.ClientConn, symbol string) (protoreflect.Descriptor, error) {
+	resp, err := reflectionRequest(ctx, conn, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, data := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+		file := &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(data, file); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal file descriptor: %v", err)
+		}
+		set.File = append(set.File, file)
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build file descriptors: %v", err)
+	}
+
+	return files.FindDescriptorByName(protoreflect.FullName(symbol))
+}
+
+// reflectionRequest sends a single request on the reflection stream of conn
+func reflectionRequest(ctx context.Context, conn *grpc.ClientConn, req *reflectionpb.ServerReflectionRequest) (*reflectionpb.ServerReflectionResponse, error) {
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open reflection stream: %v", err)
+	}
+	defer func() {
+		_ = stream.CloseSend()
+	}()
+
+	err = stream.Send(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send reflection request: %v", err)
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to receive reflection response: %v", err)
+	}
+	if errResp := resp.GetErrorResponse(); errResp != nil {
+		return nil, fmt.Errorf("reflection failed: %v", errResp.GetErrorMessage())
+	}
+
+	return resp, nil
+}
+
+func describeMethods(service protoreflect.ServiceDescriptor) []output.Record {
+	methods := service.Methods()
+	records := make([]output.Record, 0, methods.Len())
+	for i := 0; i < methods.Len(); i++ {
+		records = append(records, describeMethod(methods.Get(i)))
+	}
+	return records
+}
+
+func describeMethod(method protoreflect.MethodDescriptor) output.Record {
+	request := string(method.Input().FullName())
+	if method.IsStreamingClient() {
+		request = "stream " + request
+	}
+	response := string(method.Output().FullName())
+	if method.IsStreamingServer() {
+		response = "stream " + response
+	}
+
+	return output.Record{"method": string(method.FullName()), "request": request, "response": response}
+}
+
+func describeFields(message protoreflect.MessageDescriptor) []output.Record {
+	fields := message.Fields()
+	records := make([]output.Record, 0, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+
+		kind := field.Kind().String()
+		switch {
+		case field.Message() != nil:
+			kind = string(field.Message().FullName())
+		case field.Enum() != nil:
+			kind = string(field.Enum().FullName())
+		}
+		if field.Cardinality() == protoreflect.Repeated {
+			kind = "repeated " + kind
+		}
+
+		records = append(records, output.Record{"field": string(field.Name()), "number": int(field.Number()), "type": kind})
+	}
+	return records
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	pb "github.com/haguru/horus/horusctl/internal/protos/crumbdb"
+
+	"github.com/spf13/cobra"
+)
+
+// EXPORT_FORMAT_PREFIX is the prefix of the ExportFormat values left out of the --format flag
+const EXPORT_FORMAT_PREFIX = "EXPORT_FORMAT_"
+
+func newExportCommand(opts *options) *cobra.Command {
+	var user, format, file string
+	var lng, lat float64
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export crumbs and trails to other mapping tools",
+		Long: "Export the crumbs and trails of a user, or the crumbs near a location, as a GeoJSON FeatureCollection,\n" +
+			"GPX or KML document. The document is written as it is received, to standard output unless --file is set",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			value, ok := pb.ExportFormat_value[EXPORT_FORMAT_PREFIX+strings.ToUpper(format)]
+			if !ok || value == int32(pb.ExportFormat_EXPORT_FORMAT_UNSPECIFIED) {
+				return fmt.Errorf("invalid format %q, want geojson, gpx or kml", format)
+			}
+			req := &pb.ExportRequest{User: user, Format: pb.ExportFormat(value)}
+			if cmd.Flags().Changed("lng") {
+				req.Location = &pb.Point{Type: POINT_TYPE, Coordinates: []float64{lng, lat}}
+			}
+
+			client, err := crumbDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			stream, err := client.Export(ctx, req)
+			if err != nil {
+				return err
+			}
+
+			w := cmd.OutOrStdout()
+			if file != "" {
+				f, err := os.Create(file)
+				if err != nil {
+					return fmt.Errorf("failed to create %v: %v", file, err)
+				}
+				defer f.Close()
+				w = f
+			}
+
+			for {
+				chunk, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				if _, err := w.Write(chunk.GetData()); err != nil {
+					return fmt.Errorf("failed to write export: %v", err)
+				}
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&user, "user", "", "user whose crumbs and trails are exported")
+	cmd.Flags().Float64Var(&lng, "lng", 0, "longitude to export the crumbs around, requires --lat")
+	cmd.Flags().Float64Var(&lat, "lat", 0, "latitude to export the crumbs around, requires --lng")
+	cmd.Flags().StringVar(&format, "format", "geojson", "format of the document: geojson, gpx or kml")
+	cmd.Flags().StringVar(&file, "file", "", "file the document is written to instead of standard output")
+	cmd.MarkFlagsRequiredTogether("lng", "lat")
+	cmd.MarkFlagsMutuallyExclusive("user", "lng")
+	cmd.MarkFlagsOneRequired("user", "lng")
+
+	return cmd
+}
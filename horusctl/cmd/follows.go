@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+
+	"github.com/haguru/horus/horusctl/internal/output"
+	pb "github.com/haguru/horus/horusctl/internal/protos/followerdb"
+
+	"github.com/spf13/cobra"
+)
+
+func newFollowsCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "follows",
+		Short: "Manage who follows whom",
+	}
+
+	cmd.AddCommand(
+		newFollowsAddCommand(opts),
+		newFollowsListCommand(opts),
+		newFollowsUnfollowCommand(opts),
+	)
+
+	return cmd
+}
+
+func newFollowsAddCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "add <user-id> <follower-id>",
+		Short: "Make a user follow another user",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := followerDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			id, err := client.AddFollow(ctx, &pb.Follow{Id: args[0], FollowerId: args[1]})
+			if err != nil {
+				return err
+			}
+
+			return opts.printer.PrintOne([]string{"id"}, output.Record{"id": id.GetValue()})
+		},
+	}
+}
+
+func newFollowsListCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list <user-id>",
+		Short: "List the followers of a user",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := followerDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			stream, err := client.GetFollowers(ctx, &pb.Id{Value: args[0]})
+			if err != nil {
+				return err
+			}
+
+			var records []output.Record
+			for {
+				id, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					return err
+				}
+				records = append(records, output.Record{"follower_id": id.GetValue()})
+			}
+
+			return opts.printer.PrintList([]string{"follower_id"}, records)
+		},
+	}
+}
+
+func newFollowsUnfollowCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "unfollow <user-id> <follower-id>",
+		Short: "Stop a user from following another user",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := followerDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			status, err := client.Unfollow(ctx, &pb.Follow{Id: args[0], FollowerId: args[1]})
+			if err != nil {
+				return err
+			}
+
+			record := output.Record{"id": args[0], "follower_id": args[1], "status": status.GetValue()}
+			return opts.printer.PrintOne([]string{"id", "follower_id", "status"}, record)
+		},
+	}
+}
+
+func followerDBClient(opts *options) (pb.FollowerDBClient, error) {
+	conn, err := opts.dial(BACKEND_FOLLOWER)
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewFollowerDBClient(conn), nil
+}
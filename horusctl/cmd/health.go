@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/haguru/horus/horusctl/internal/output"
+
+	"github.com/spf13/cobra"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const STATUS_UNREACHABLE = "UNREACHABLE"
+
+// BACKENDS are the services checked by default, in order
+var BACKENDS = []string{BACKEND_CRUMBDB, BACKEND_USERACCT, BACKEND_FOLLOWER}
+
+func newHealthCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:       "health [service...]",
+		Short:     "Check the gRPC health of the services",
+		Long:      "Check the gRPC health of the services, all of them when none is given. Fails unless every service is serving",
+		ValidArgs: BACKENDS,
+		Args:      cobra.OnlyValidArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				args = BACKENDS
+			}
+
+			records := make([]output.Record, 0, len(args))
+			notServing := 0
+			for _, name := range args {
+				record := checkHealth(cmd, opts, name)
+				if record["status"] != healthpb.HealthCheckResponse_SERVING.String() {
+					notServing++
+				}
+				records = append(records, record)
+			}
+
+			err := opts.printer.PrintList([]string{"service", "address", "status", "error"}, records)
+			if err != nil {
+				return err
+			}
+			if notServing > 0 {
+				return fmt.Errorf("%v of %v services are not serving", notServing, len(records))
+			}
+			return nil
+		},
+	}
+}
+
+// checkHealth returns the health of backend name, the error is recorded instead of returned
+func checkHealth(cmd *cobra.Command, opts *options, name string) output.Record {
+	record := output.Record{"service": opts.backends[name].service, "status": STATUS_UNREACHABLE}
+
+	addr, err := opts.address(name)
+	if err != nil {
+		record["error"] = err.Error()
+		return record
+	}
+	record["address"] = addr
+
+	conn, err := opts.dial(name)
+	if err != nil {
+		record["error"] = err.Error()
+		return record
+	}
+
+	ctx, cancel := opts.callContext(cmd)
+	defer cancel()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: opts.backends[name].service})
+	if err != nil {
+		record["error"] = err.Error()
+		return record
+	}
+	record["status"] = resp.GetStatus().String()
+
+	return record
+}
+
+// validBackend returns an error unless name is one of BACKENDS
+func validBackend(name string) error {
+	if !slices.Contains(BACKENDS, name) {
+		return fmt.Errorf("unknown service %q, must be one of %v", name, BACKENDS)
+	}
+	return nil
+}
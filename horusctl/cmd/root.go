@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/haguru/horus/horusctl/internal/output"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	BACKEND_CRUMBDB  = "crumbdb"
+	BACKEND_USERACCT = "useracct"
+	BACKEND_FOLLOWER = "follower"
+
+	DEFAULT_TIMEOUT = 10 * time.Second
+)
+
+// backend is a service horusctl talks to, addr is used unless the address is resolved through Consul
+type backend struct {
+	service string
+	addr    string
+}
+
+// options are the global flags shared by every command
+type options struct {
+	output   string
+	timeout  time.Duration
+	consul   string
+	backends map[string]*backend
+
+	tls           bool
+	tlsCA         string
+	tlsCert       string
+	tlsKey        string
+	tlsServerName string
+
+	printer *output.Printer
+	conns   map[string]*grpc.ClientConn
+}
+
+// NewRootCommand returns the horusctl command with all subcommands
+func NewRootCommand() *cobra.Command {
+	opts := &options{
+		backends: map[string]*backend{
+			BACKEND_CRUMBDB:  {service: "crumbdb_service", addr: "localhost:50051"},
+			BACKEND_USERACCT: {service: "useracct_service", addr: "localhost:50053"},
+			BACKEND_FOLLOWER: {service: "follower_service", addr: "localhost:50055"},
+		},
+		conns: map[string]*grpc.ClientConn{},
+	}
+
+	root := &cobra.Command{
+		Use:          "horusctl",
+		Short:        "Operate the horus services",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			printer, err := output.NewPrinter(opts.output, cmd.OutOrStdout())
+			if err != nil {
+				return err
+			}
+			opts.printer = printer
+			return nil
+		},
+		PersistentPostRun: func(*cobra.Command, []string) {
+			for _, conn := range opts.conns {
+				conn.Close()
+			}
+		},
+	}
+
+	flags := root.PersistentFlags()
+	flags.StringVarP(&opts.output, "output", "o", output.FORMAT_TABLE, "output format: table, json or yaml")
+	flags.DurationVar(&opts.timeout, "timeout", DEFAULT_TIMEOUT, "timeout of every call")
+	flags.StringVar(&opts.consul, "consul", "", "Consul address, e.g. localhost:8500, used to resolve the service addresses")
+	flags.StringVar(&opts.backends[BACKEND_CRUMBDB].addr, "crumbdb-addr", opts.backends[BACKEND_CRUMBDB].addr, "address of the crumbdb service")
+	flags.StringVar(&opts.backends[BACKEND_USERACCT].addr, "useracct-addr", opts.backends[BACKEND_USERACCT].addr, "address of the user account service")
+	flags.StringVar(&opts.backends[BACKEND_FOLLOWER].addr, "follower-addr", opts.backends[BACKEND_FOLLOWER].addr, "address of the follower service")
+	flags.BoolVar(&opts.tls, "tls", false, "connect with TLS")
+	flags.StringVar(&opts.tlsCA, "tls-ca", "", "CA certificate verifying the services, the system pool when empty")
+	flags.StringVar(&opts.tlsCert, "tls-cert", "", "client certificate for services requiring mutual TLS")
+	flags.StringVar(&opts.tlsKey, "tls-key", "", "key of the client certificate")
+	flags.StringVar(&opts.tlsServerName, "tls-server-name", "", "server name expected in the service certificates")
+
+	root.AddCommand(
+		newCrumbsCommand(opts),
+		newTrailsCommand(opts),
+		newZonesCommand(opts),
+		newExportCommand(opts),
+		newUsersCommand(opts),
+		newFollowsCommand(opts),
+		newHealthCommand(opts),
+		newServicesCommand(opts),
+		newDescribeCommand(opts),
+	)
+
+	return root
+}
+
+// callContext returns the context of a call bounded by the timeout flag
+func (o *options) callContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(cmd.Context(), o.timeout)
+}
+
+// dial returns the connection to backend, reusing it across calls of the same command
+func (o *options) dial(name string) (*grpc.ClientConn, error) {
+	if conn, ok := o.conns[name]; ok {
+		return conn, nil
+	}
+
+	addr, err := o.address(name)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := o.credentials()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %v at %v: %v", name, addr, err)
+	}
+	o.conns[name] = conn
+
+	return conn, nil
+}
+
+// address returns the address of backend, the first healthy instance registered in Consul when --consul is set
+func (o *options) address(name string) (string, error) {
+	b, ok := o.backends[name]
+	if !ok {
+		return "", fmt.Errorf("unknown service %q", name)
+	}
+	if o.consul == "" {
+		return b.addr, nil
+	}
+
+	client, err := o.consulClient()
+	if err != nil {
+		return "", err
+	}
+
+	entries, _, err := client.Health().Service(b.service, "", true, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %v in consul: %v", b.service, err)
+	}
+	if len(entries) == 0 {
+		return "", fmt.Errorf("no healthy instance of %v registered in consul", b.service)
+	}
+
+	service := entries[0].Service
+	host := service.Address
+	if host == "" {
+		host = entries[0].Node.Address
+	}
+	return fmt.Sprintf("%v:%v", host, service.Port), nil
+}
+
+func (o *options) consulClient() (*consulapi.Client, error) {
+	client, err := consulapi.NewClient(&consulapi.Config{Address: o.consul})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consul client: %v", err)
+	}
+	return client, nil
+}
+
+func (o *options) credentials() (credentials.TransportCredentials, error) {
+	if !o.tls {
+		return insecure.NewCredentials(), nil
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: o.tlsServerName,
+	}
+
+	if o.tlsCA != "" {
+		ca, err := os.ReadFile(o.tlsCA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca certificate: %v", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in %v", o.tlsCA)
+		}
+	}
+
+	if o.tlsCert != "" || o.tlsKey != "" {
+		cert, err := tls.LoadX509KeyPair(o.tlsCert, o.tlsKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return credentials.NewTLS(config), nil
+}
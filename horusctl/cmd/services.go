@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/haguru/horus/horusctl/internal/output"
+
+	"github.com/spf13/cobra"
+)
+
+func newServicesCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "services",
+		Short: "List the service instances registered in Consul",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if opts.consul == "" {
+				return fmt.Errorf("--consul is required to list services")
+			}
+			client, err := opts.consulClient()
+			if err != nil {
+				return err
+			}
+
+			services, _, err := client.Catalog().Services(nil)
+			if err != nil {
+				return fmt.Errorf("failed to list services: %v", err)
+			}
+			names := make([]string, 0, len(services))
+			for name := range services {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			var records []output.Record
+			for _, name := range names {
+				entries, _, err := client.Health().Service(name, "", false, nil)
+				if err != nil {
+					return fmt.Errorf("failed to list instances of %v: %v", name, err)
+				}
+
+				for _, entry := range entries {
+					address := entry.Service.Address
+					if address == "" {
+						address = entry.Node.Address
+					}
+					records = append(records, output.Record{
+						"service": name,
+						"id":      entry.Service.ID,
+						"address": address,
+						"port":    entry.Service.Port,
+						"status":  entry.Checks.AggregatedStatus(),
+					})
+				}
+			}
+
+			return opts.printer.PrintList([]string{"service", "id", "address", "port", "status"}, records)
+		},
+	}
+}
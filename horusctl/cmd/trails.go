@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"github.com/haguru/horus/horusctl/internal/output"
+	pb "github.com/haguru/horus/horusctl/internal/protos/crumbdb"
+
+	"github.com/spf13/cobra"
+)
+
+// TRAIL_COLUMNS are the trail fields shown in tables
+var TRAIL_COLUMNS = []string{"id", "user", "name", "crumb_ids", "distance", "version"}
+
+func newTrailsCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trails",
+		Short: "Manage trails of crumbs",
+	}
+
+	cmd.AddCommand(
+		newTrailsCreateCommand(opts),
+		newTrailsAppendCommand(opts),
+		newTrailsGetCommand(opts),
+		newTrailsNearCommand(opts),
+	)
+
+	return cmd
+}
+
+func newTrailsCreateCommand(opts *options) *cobra.Command {
+	var user, name string
+
+	cmd := &cobra.Command{
+		Use:   "create [crumb id]...",
+		Short: "Create a trail through crumbs of a user",
+		Args:  cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := crumbDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			trail, err := client.CreateTrail(ctx, &pb.Trail{User: user, Name: name, CrumbIds: args})
+			if err != nil {
+				return err
+			}
+			return printTrail(opts, trail)
+		},
+	}
+
+	cmd.Flags().StringVar(&user, "user", "", "user the trail belongs to")
+	cmd.Flags().StringVar(&name, "name", "", "name of the trail")
+	_ = cmd.MarkFlagRequired("user")
+
+	return cmd
+}
+
+func newTrailsAppendCommand(opts *options) *cobra.Command {
+	var version int64
+
+	cmd := &cobra.Command{
+		Use:   "append <trail id> <crumb id>...",
+		Short: "Append crumbs to a trail",
+		Long:  "Append crumbs to the end of a trail in order. With --version the append fails if the trail changed since",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := crumbDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			trail, err := client.AppendToTrail(ctx, &pb.AppendToTrailRequest{
+				TrailId:  args[0],
+				CrumbIds: args[1:],
+				Version:  version,
+			})
+			if err != nil {
+				return err
+			}
+			return printTrail(opts, trail)
+		},
+	}
+
+	cmd.Flags().Int64Var(&version, "version", 0, "version the trail must be at, any if 0")
+
+	return cmd
+}
+
+func newTrailsGetCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <id>",
+		Short: "Show a trail",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := crumbDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			trail, err := client.GetTrail(ctx, &pb.Id{Value: args[0]})
+			if err != nil {
+				return err
+			}
+			return printTrail(opts, trail)
+		},
+	}
+}
+
+func newTrailsNearCommand(opts *options) *cobra.Command {
+	var lng, lat, radius float64
+
+	cmd := &cobra.Command{
+		Use:   "near",
+		Short: "List the trails passing near a location",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := crumbDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			trails, err := client.FindTrailsNear(ctx, &pb.FindTrailsNearRequest{
+				Location: &pb.Point{Type: POINT_TYPE, Coordinates: []float64{lng, lat}},
+				Radius:   radius,
+			})
+			if err != nil {
+				return err
+			}
+
+			records := make([]output.Record, 0, len(trails.GetTrails()))
+			for _, trail := range trails.GetTrails() {
+				record, err := output.FromProto(trail)
+				if err != nil {
+					return err
+				}
+				records = append(records, record)
+			}
+			return opts.printer.PrintList(TRAIL_COLUMNS, records)
+		},
+	}
+
+	cmd.Flags().Float64Var(&lng, "lng", 0, "longitude to search around")
+	cmd.Flags().Float64Var(&lat, "lat", 0, "latitude to search around")
+	cmd.Flags().Float64Var(&radius, "radius", 0, "meters around the location, the service default if 0")
+	_ = cmd.MarkFlagRequired("lng")
+	_ = cmd.MarkFlagRequired("lat")
+
+	return cmd
+}
+
+func printTrail(opts *options, trail *pb.Trail) error {
+	record, err := output.FromProto(trail)
+	if err != nil {
+		return err
+	}
+	return opts.printer.PrintOne(TRAIL_COLUMNS, record)
+}
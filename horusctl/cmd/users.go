@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/haguru/horus/horusctl/internal/output"
+	pb "github.com/haguru/horus/horusctl/internal/protos/useracctdb"
+
+	"github.com/spf13/cobra"
+)
+
+// USER_COLUMNS are the user fields shown in tables, the password is never printed
+var USER_COLUMNS = []string{"id", "email", "username"}
+
+func newUsersCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "users",
+		Short: "Manage user accounts",
+	}
+
+	cmd.AddCommand(
+		newUsersCreateCommand(opts),
+		newUsersGetCommand(opts),
+		newUsersDeleteCommand(opts),
+		newUsersResetPasswordCommand(opts),
+	)
+
+	return cmd
+}
+
+func newUsersCreateCommand(opts *options) *cobra.Command {
+	var email, username string
+	var password passwordFlags
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a user account",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			passwd, err := password.read(cmd)
+			if err != nil {
+				return err
+			}
+			client, err := userAcctDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			id, err := client.Create(ctx, &pb.User{Email: email, Username: username, Password: passwd})
+			if err != nil {
+				return err
+			}
+
+			return opts.printer.PrintOne([]string{"id"}, output.Record{"id": id.GetValue()})
+		},
+	}
+
+	cmd.Flags().StringVar(&email, "email", "", "email address of the user")
+	cmd.Flags().StringVar(&username, "username", "", "name of the user")
+	_ = cmd.MarkFlagRequired("email")
+	_ = cmd.MarkFlagRequired("username")
+	password.register(cmd)
+
+	return cmd
+}
+
+func newUsersGetCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <email>",
+		Short: "Show a user account",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := userAcctDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			user, err := client.GetUser(ctx, &pb.UserRequest{Email: args[0]})
+			if err != nil {
+				return err
+			}
+
+			record, err := output.FromProto(user)
+			if err != nil {
+				return err
+			}
+			delete(record, "password")
+
+			return opts.printer.PrintOne(USER_COLUMNS, record)
+		},
+	}
+}
+
+func newUsersDeleteCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <email>",
+		Short: "Delete a user account",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := userAcctDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			status, err := client.Delete(ctx, &pb.UserRequest{Email: args[0]})
+			if err != nil {
+				return err
+			}
+
+			return opts.printer.PrintOne([]string{"email", "status"}, output.Record{"email": args[0], "status": status.GetValue()})
+		},
+	}
+}
+
+func newUsersResetPasswordCommand(opts *options) *cobra.Command {
+	var password passwordFlags
+
+	cmd := &cobra.Command{
+		Use:   "reset-password <email>",
+		Short: "Set a new password for a user account",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			passwd, err := password.read(cmd)
+			if err != nil {
+				return err
+			}
+			client, err := userAcctDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			status, err := client.UpdatePassword(ctx, &pb.PasswordRequest{Email: args[0], Password: passwd})
+			if err != nil {
+				return err
+			}
+
+			return opts.printer.PrintOne([]string{"email", "status"}, output.Record{"email": args[0], "status": status.GetValue()})
+		},
+	}
+	password.register(cmd)
+
+	return cmd
+}
+
+// passwordFlags take the password from a flag or, keeping it out of the shell history, from stdin
+type passwordFlags struct {
+	value string
+	stdin bool
+}
+
+func (p *passwordFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&p.value, "password", "", "password of the user")
+	cmd.Flags().BoolVar(&p.stdin, "password-stdin", false, "read the password from stdin")
+	cmd.MarkFlagsOneRequired("password", "password-stdin")
+	cmd.MarkFlagsMutuallyExclusive("password", "password-stdin")
+}
+
+func (p *passwordFlags) read(cmd *cobra.Command) (string, error) {
+	if !p.stdin {
+		return p.value, nil
+	}
+
+	line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	line = strings.TrimRight(line, "\r\n")
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read password from stdin: %v", err)
+	}
+	if line == "" {
+		return "", fmt.Errorf("empty password read from stdin")
+	}
+	return line, nil
+}
+
+func userAcctDBClient(opts *options) (pb.UserAcctDBClient, error) {
+	conn, err := opts.dial(BACKEND_USERACCT)
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewUserAcctDBClient(conn), nil
+}
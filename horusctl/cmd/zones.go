@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/haguru/horus/horusctl/internal/output"
+	pb "github.com/haguru/horus/horusctl/internal/protos/crumbdb"
+
+	"github.com/spf13/cobra"
+)
+
+const POLYGON_TYPE = "Polygon"
+
+// ZONE_COLUMNS are the zone fields shown in tables
+var ZONE_COLUMNS = []string{"id", "rule_id", "name", "max_crumbs"}
+
+func newZonesCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "zones",
+		Short: "Manage the zones restricting crumbs, as an operator",
+	}
+
+	cmd.AddCommand(
+		newZonesListCommand(opts),
+		newZonesPutCommand(opts),
+		newZonesDeleteCommand(opts),
+	)
+
+	return cmd
+}
+
+func newZonesListCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the zones",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := crumbDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			zones, err := client.ListZones(ctx, &pb.ListZonesRequest{})
+			if err != nil {
+				return err
+			}
+
+			records := make([]output.Record, 0, len(zones.GetZones()))
+			for _, zone := range zones.GetZones() {
+				record, err := output.FromProto(zone)
+				if err != nil {
+					return err
+				}
+				records = append(records, record)
+			}
+			return opts.printer.PrintList(ZONE_COLUMNS, records)
+		},
+	}
+}
+
+func newZonesPutCommand(opts *options) *cobra.Command {
+	var id, rule, name, area string
+	var maxCrumbs int64
+
+	cmd := &cobra.Command{
+		Use:   "put",
+		Short: "Create or replace a zone",
+		Long: "Create a zone, or replace the zone with --id. The area is a closed ring of \"lng,lat\" vertices " +
+			"separated by spaces. A zone with --max-crumbs 0 forbids crumbs",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			coordinates, err := parseRing(area)
+			if err != nil {
+				return err
+			}
+
+			client, err := crumbDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			zone, err := client.PutZone(ctx, &pb.Zone{
+				Id:        id,
+				RuleId:    rule,
+				Name:      name,
+				Area:      &pb.Point{Type: POLYGON_TYPE, Coordinates: coordinates},
+				MaxCrumbs: maxCrumbs,
+			})
+			if err != nil {
+				return err
+			}
+			record, err := output.FromProto(zone)
+			if err != nil {
+				return err
+			}
+			return opts.printer.PrintOne(ZONE_COLUMNS, record)
+		},
+	}
+
+	cmd.Flags().StringVar(&id, "id", "", "id of the zone to replace")
+	cmd.Flags().StringVar(&rule, "rule", "", "rule id reported when the zone rejects a crumb")
+	cmd.Flags().StringVar(&name, "name", "", "name of the zone")
+	cmd.Flags().StringVar(&area, "area", "", "vertices of the zone, such as \"2.34,48.85 2.36,48.85 2.36,48.86 2.34,48.85\"")
+	cmd.Flags().Int64Var(&maxCrumbs, "max-crumbs", 0, "most crumbs in the zone, none if 0")
+	_ = cmd.MarkFlagRequired("rule")
+	_ = cmd.MarkFlagRequired("area")
+
+	return cmd
+}
+
+func newZonesDeleteCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <id>",
+		Short: "Delete a zone",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := crumbDBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.callContext(cmd)
+			defer cancel()
+
+			id, err := client.DeleteZone(ctx, &pb.Id{Value: args[0]})
+			if err != nil {
+				return err
+			}
+
+			return opts.printer.PrintOne([]string{"id"}, output.Record{"id": id.GetValue()})
+		},
+	}
+}
+
+// parseRing returns the flat coordinates of the "lng,lat" vertices of ring separated by spaces
+func parseRing(ring string) ([]float64, error) {
+	var coordinates []float64
+	for _, vertex := range strings.Fields(ring) {
+		lng, lat, ok := strings.Cut(vertex, ",")
+		if !ok {
+			return nil, fmt.Errorf("invalid vertex %q, want lng,lat", vertex)
+		}
+		for _, value := range []string{lng, lat} {
+			coordinate, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid vertex %q: %v", vertex, err)
+			}
+			coordinates = append(coordinates, coordinate)
+		}
+	}
+	return coordinates, nil
+}
@@ -0,0 +1,134 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FORMAT_TABLE = "table"
+	FORMAT_JSON  = "json"
+	FORMAT_YAML  = "yaml"
+)
+
+// FORMATS are the supported output formats
+var FORMATS = []string{FORMAT_TABLE, FORMAT_JSON, FORMAT_YAML}
+
+// Record is a single result keyed by field name
+type Record map[string]interface{}
+
+// FromProto returns the fields of message keyed by their proto names, including unset fields
+func FromProto(message proto.Message) (Record, error) {
+	data, err := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %v: %v", message.ProtoReflect().Descriptor().FullName(), err)
+	}
+
+	record := Record{}
+	err = json.Unmarshal(data, &record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %v: %v", message.ProtoReflect().Descriptor().FullName(), err)
+	}
+
+	return record, nil
+}
+
+// Printer writes records to w in one of FORMATS
+type Printer struct {
+	format string
+	w      io.Writer
+}
+
+// NewPrinter returns a Printer and error if format is not one of FORMATS
+func NewPrinter(format string, w io.Writer) (*Printer, error) {
+	switch format {
+	case FORMAT_TABLE, FORMAT_JSON, FORMAT_YAML:
+	default:
+		return nil, fmt.Errorf("unsupported output format %q, must be one of %v", format, strings.Join(FORMATS, ", "))
+	}
+
+	return &Printer{format: format, w: w}, nil
+}
+
+// PrintOne writes a single record. Tables show the given columns
+func (p *Printer) PrintOne(columns []string, record Record) error {
+	if p.format == FORMAT_TABLE {
+		return p.table(columns, []Record{record})
+	}
+	return p.encode(record)
+}
+
+// PrintList writes records as a list, an empty one when there are none. Tables show the given columns
+func (p *Printer) PrintList(columns []string, records []Record) error {
+	if p.format == FORMAT_TABLE {
+		return p.table(columns, records)
+	}
+	if records == nil {
+		records = []Record{}
+	}
+	return p.encode(records)
+}
+
+func (p *Printer) encode(v interface{}) error {
+	if p.format == FORMAT_YAML {
+		encoder := yaml.NewEncoder(p.w)
+		encoder.SetIndent(2)
+		err := encoder.Encode(v)
+		if err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+
+	encoder := json.NewEncoder(p.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func (p *Printer) table(columns []string, records []Record) error {
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = strings.ToUpper(column)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, record := range records {
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			cells[i] = cell(record[column])
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// cell formats a value for a table, nested values as compact JSON
+func cell(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(data)
+	}
+}
@@ -0,0 +1,114 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	pb "github.com/haguru/horus/horusctl/internal/protos/crumbdb"
+)
+
+func TestPrinter(t *testing.T) {
+	crumb, err := FromProto(&pb.Crumb{Id: "1", Location: &pb.Point{Type: "Point", Coordinates: []float64{-122.4, 37.8}}, User: "user_1"})
+	if err != nil {
+		t.Fatalf("FromProto() error = %v", err)
+	}
+	columns := []string{"id", "user", "message", "location"}
+
+	tests := []struct {
+		name    string
+		format  string
+		list    bool
+		records []Record
+		want    string
+	}{
+		{
+			name:    "table",
+			format:  FORMAT_TABLE,
+			list:    true,
+			records: []Record{crumb},
+			want: "ID  USER    MESSAGE  LOCATION\n" +
+				"1   user_1           {\"coordinates\":[-122.4,37.8],\"type\":\"Point\"}\n",
+		},
+		{
+			name:    "json object",
+			format:  FORMAT_JSON,
+			records: []Record{crumb},
+			want: `{
+  "allowed_users": [],
+  "created_at": "0",
+  "id": "1",
+  "location": {
+    "coordinates": [
+      -122.4,
+      37.8
+    ],
+    "type": "Point"
+  },
+  "locked": false,
+  "message": "",
+  "tags": [],
+  "unlock_radius": 0,
+  "user": "user_1",
+  "version": "0",
+  "visibility": "VISIBILITY_PUBLIC"
+}
+`,
+		},
+		{
+			name:    "empty json list",
+			format:  FORMAT_JSON,
+			list:    true,
+			records: nil,
+			want:    "[]\n",
+		},
+		{
+			name:    "yaml list",
+			format:  FORMAT_YAML,
+			list:    true,
+			records: []Record{crumb},
+			want: `- allowed_users: []
+  created_at: "0"
+  id: "1"
+  location:
+    coordinates:
+      - -122.4
+      - 37.8
+    type: Point
+  locked: false
+  message: ""
+  tags: []
+  unlock_radius: 0
+  user: user_1
+  version: "0"
+  visibility: VISIBILITY_PUBLIC
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			p, err := NewPrinter(tt.format, buf)
+			if err != nil {
+				t.Fatalf("NewPrinter() error = %v", err)
+			}
+
+			if tt.list {
+				err = p.PrintList(columns, tt.records)
+			} else {
+				err = p.PrintOne(columns, tt.records[0])
+			}
+			if err != nil {
+				t.Fatalf("Print() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Print() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPrinter_UnsupportedFormat(t *testing.T) {
+	if _, err := NewPrinter("xml", &bytes.Buffer{}); err == nil {
+		t.Error("NewPrinter() error = nil, want unsupported format")
+	}
+}
@@ -0,0 +1,2438 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v3.6.1
+// source: routegrpc.proto
+
+package crumbdb
+
+import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// who can see a crumb in GetCrumbs besides its owner
+type Visibility int32
+
+const (
+	Visibility_VISIBILITY_PUBLIC Visibility = 0
+	// the users following the owner
+	Visibility_VISIBILITY_FOLLOWERS Visibility = 1
+	Visibility_VISIBILITY_PRIVATE   Visibility = 2
+	// the allowed users of the crumb
+	Visibility_VISIBILITY_SPECIFIC_USERS Visibility = 3
+)
+
+// Enum value maps for Visibility.
+var (
+	Visibility_name = map[int32]string{
+		0: "VISIBILITY_PUBLIC",
+		1: "VISIBILITY_FOLLOWERS",
+		2: "VISIBILITY_PRIVATE",
+		3: "VISIBILITY_SPECIFIC_USERS",
+	}
+	Visibility_value = map[string]int32{
+		"VISIBILITY_PUBLIC":         0,
+		"VISIBILITY_FOLLOWERS":      1,
+		"VISIBILITY_PRIVATE":        2,
+		"VISIBILITY_SPECIFIC_USERS": 3,
+	}
+)
+
+func (x Visibility) Enum() *Visibility {
+	p := new(Visibility)
+	*p = x
+	return p
+}
+
+func (x Visibility) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Visibility) Descriptor() protoreflect.EnumDescriptor {
+	return file_routegrpc_proto_enumTypes[0].Descriptor()
+}
+
+func (Visibility) Type() protoreflect.EnumType {
+	return &file_routegrpc_proto_enumTypes[0]
+}
+
+func (x Visibility) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Visibility.Descriptor instead.
+func (Visibility) EnumDescriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{0}
+}
+
+type ExportFormat int32
+
+const (
+	// GeoJSON
+	ExportFormat_EXPORT_FORMAT_UNSPECIFIED ExportFormat = 0
+	ExportFormat_EXPORT_FORMAT_GEOJSON     ExportFormat = 1
+	ExportFormat_EXPORT_FORMAT_GPX         ExportFormat = 2
+	ExportFormat_EXPORT_FORMAT_KML         ExportFormat = 3
+)
+
+// Enum value maps for ExportFormat.
+var (
+	ExportFormat_name = map[int32]string{
+		0: "EXPORT_FORMAT_UNSPECIFIED",
+		1: "EXPORT_FORMAT_GEOJSON",
+		2: "EXPORT_FORMAT_GPX",
+		3: "EXPORT_FORMAT_KML",
+	}
+	ExportFormat_value = map[string]int32{
+		"EXPORT_FORMAT_UNSPECIFIED": 0,
+		"EXPORT_FORMAT_GEOJSON":     1,
+		"EXPORT_FORMAT_GPX":         2,
+		"EXPORT_FORMAT_KML":         3,
+	}
+)
+
+func (x ExportFormat) Enum() *ExportFormat {
+	p := new(ExportFormat)
+	*p = x
+	return p
+}
+
+func (x ExportFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ExportFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_routegrpc_proto_enumTypes[1].Descriptor()
+}
+
+func (ExportFormat) Type() protoreflect.EnumType {
+	return &file_routegrpc_proto_enumTypes[1]
+}
+
+func (x ExportFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ExportFormat.Descriptor instead.
+func (ExportFormat) EnumDescriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{1}
+}
+
+type Crumb struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id       string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`             // @gotags: bson:"_id,omitempty"
+	Location *Point `protobuf:"bytes,2,opt,name=location,proto3" json:"location,omitempty"` // @gotags: bson:"location" validate:"required"
+	User     string `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`         // @gotags: bson:"user" validate:"required"
+	Message  string `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`   // @gotags: bson:"message" validate:"required"
+	// set by Create, in unix milliseconds
+	CreatedAt int64 `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // @gotags: bson:"created_at"
+	// incremented by every update, starting at 1
+	Version    int64      `protobuf:"varint,6,opt,name=version,proto3" json:"version,omitempty"`                               // @gotags: bson:"version"
+	Visibility Visibility `protobuf:"varint,7,opt,name=visibility,proto3,enum=crumbdb.Visibility" json:"visibility,omitempty"` // @gotags: bson:"visibility"
+	Tags       []string   `protobuf:"bytes,8,rep,name=tags,proto3" json:"tags,omitempty"`                                      // @gotags: bson:"tags"
+	// the users who can see a crumb of VISIBILITY_SPECIFIC_USERS besides its owner
+	AllowedUsers []string `protobuf:"bytes,9,rep,name=allowed_users,json=allowedUsers,proto3" json:"allowed_users,omitempty"` // @gotags: bson:"allowed_users" validate:"max=100,dive,required"
+	// the message of a locked crumb is only returned to its owner and by UnlockCrumb, to callers within
+	// unlock_radius of it
+	Locked bool `protobuf:"varint,10,opt,name=locked,proto3" json:"locked,omitempty"` // @gotags: bson:"locked"
+	// in meters, 50 if zero
+	UnlockRadius float64 `protobuf:"fixed64,11,opt,name=unlock_radius,json=unlockRadius,proto3" json:"unlock_radius,omitempty"` // @gotags: bson:"unlock_radius" validate:"gte=0,lte=10000"
+}
+
+func (x *Crumb) Reset() {
+	*x = Crumb{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Crumb) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Crumb) ProtoMessage() {}
+
+func (x *Crumb) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Crumb.ProtoReflect.Descriptor instead.
+func (*Crumb) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Crumb) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Crumb) GetLocation() *Point {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *Crumb) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *Crumb) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Crumb) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Crumb) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Crumb) GetVisibility() Visibility {
+	if x != nil {
+		return x.Visibility
+	}
+	return Visibility_VISIBILITY_PUBLIC
+}
+
+func (x *Crumb) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Crumb) GetAllowedUsers() []string {
+	if x != nil {
+		return x.AllowedUsers
+	}
+	return nil
+}
+
+func (x *Crumb) GetLocked() bool {
+	if x != nil {
+		return x.Locked
+	}
+	return false
+}
+
+func (x *Crumb) GetUnlockRadius() float64 {
+	if x != nil {
+		return x.UnlockRadius
+	}
+	return 0
+}
+
+// a GeoJSON Point, or a Polygon given as flat [longitude, latitude] pairs of its counterclockwise vertices
+type Point struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type        string    `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`                        // @gotags: bson:"type"
+	Coordinates []float64 `protobuf:"fixed64,2,rep,packed,name=coordinates,proto3" json:"coordinates,omitempty"` // @gotags: bson:"coordinates"
+	// meters above the WGS84 ellipsoid
+	Altitude *float64 `protobuf:"fixed64,3,opt,name=altitude,proto3,oneof" json:"altitude,omitempty"` // @gotags: bson:"altitude,omitempty"
+	// radius in meters of the horizontal uncertainty of the coordinates
+	Accuracy *float64 `protobuf:"fixed64,4,opt,name=accuracy,proto3,oneof" json:"accuracy,omitempty"` // @gotags: bson:"accuracy,omitempty"
+}
+
+func (x *Point) Reset() {
+	*x = Point{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Point) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Point) ProtoMessage() {}
+
+func (x *Point) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Point.ProtoReflect.Descriptor instead.
+func (*Point) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Point) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Point) GetCoordinates() []float64 {
+	if x != nil {
+		return x.Coordinates
+	}
+	return nil
+}
+
+func (x *Point) GetAltitude() float64 {
+	if x != nil && x.Altitude != nil {
+		return *x.Altitude
+	}
+	return 0
+}
+
+func (x *Point) GetAccuracy() float64 {
+	if x != nil && x.Accuracy != nil {
+		return *x.Accuracy
+	}
+	return 0
+}
+
+type Id struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Value string `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *Id) Reset() {
+	*x = Id{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Id) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Id) ProtoMessage() {}
+
+func (x *Id) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Id.ProtoReflect.Descriptor instead.
+func (*Id) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Id) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type Ids struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Values []string `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+}
+
+func (x *Ids) Reset() {
+	*x = Ids{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Ids) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ids) ProtoMessage() {}
+
+func (x *Ids) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ids.ProtoReflect.Descriptor instead.
+func (*Ids) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Ids) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type Crumbs struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Crumbs []*Crumb `protobuf:"bytes,1,rep,name=crumbs,proto3" json:"crumbs,omitempty"`
+}
+
+func (x *Crumbs) Reset() {
+	*x = Crumbs{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Crumbs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Crumbs) ProtoMessage() {}
+
+func (x *Crumbs) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Crumbs.ProtoReflect.Descriptor instead.
+func (*Crumbs) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Crumbs) GetCrumbs() []*Crumb {
+	if x != nil {
+		return x.Crumbs
+	}
+	return nil
+}
+
+type ListCrumbsByUserRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	User string `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	// at most 500, defaults to 50
+	PageSize int32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token of the previous page, empty for the first page
+	PageToken string `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+}
+
+func (x *ListCrumbsByUserRequest) Reset() {
+	*x = ListCrumbsByUserRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListCrumbsByUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCrumbsByUserRequest) ProtoMessage() {}
+
+func (x *ListCrumbsByUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCrumbsByUserRequest.ProtoReflect.Descriptor instead.
+func (*ListCrumbsByUserRequest) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListCrumbsByUserRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *ListCrumbsByUserRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListCrumbsByUserRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListCrumbsByUserResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// newest first
+	Crumbs []*Crumb `protobuf:"bytes,1,rep,name=crumbs,proto3" json:"crumbs,omitempty"`
+	// empty on the last page
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+}
+
+func (x *ListCrumbsByUserResponse) Reset() {
+	*x = ListCrumbsByUserResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListCrumbsByUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCrumbsByUserResponse) ProtoMessage() {}
+
+func (x *ListCrumbsByUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCrumbsByUserResponse.ProtoReflect.Descriptor instead.
+func (*ListCrumbsByUserResponse) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListCrumbsByUserResponse) GetCrumbs() []*Crumb {
+	if x != nil {
+		return x.Crumbs
+	}
+	return nil
+}
+
+func (x *ListCrumbsByUserResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type UpdateCrumbRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the crumb identified by id with the new values of the fields in update_mask
+	Crumb *Crumb `protobuf:"bytes,1,opt,name=crumb,proto3" json:"crumb,omitempty"`
+	// any of message, location, visibility, tags, allowed_users, locked and unlock_radius, defaults to
+	// message
+	UpdateMask *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+}
+
+func (x *UpdateCrumbRequest) Reset() {
+	*x = UpdateCrumbRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateCrumbRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCrumbRequest) ProtoMessage() {}
+
+func (x *UpdateCrumbRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCrumbRequest.ProtoReflect.Descriptor instead.
+func (*UpdateCrumbRequest) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdateCrumbRequest) GetCrumb() *Crumb {
+	if x != nil {
+		return x.Crumb
+	}
+	return nil
+}
+
+func (x *UpdateCrumbRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+// an ordered sequence of crumbs of a user
+type Trail struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id   string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	User string `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	Name string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// in the order they were walked, a crumb may appear more than once
+	CrumbIds []string `protobuf:"bytes,4,rep,name=crumb_ids,json=crumbIds,proto3" json:"crumb_ids,omitempty"`
+	// derived from the locations of the crumbs, a LineString given as flat [longitude, latitude] pairs, a Point
+	// if they are all at the same location and unset if there are none
+	Path *Point `protobuf:"bytes,5,opt,name=path,proto3" json:"path,omitempty"`
+	// length in meters of the path
+	Distance float64 `protobuf:"fixed64,6,opt,name=distance,proto3" json:"distance,omitempty"`
+	// [min longitude, min latitude, max longitude, max latitude] of the path, empty if it is unset
+	Bbox []float64 `protobuf:"fixed64,7,rep,packed,name=bbox,proto3" json:"bbox,omitempty"`
+	// set by CreateTrail, in unix milliseconds
+	CreatedAt int64 `protobuf:"varint,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// incremented by every change, starting at 1
+	Version int64 `protobuf:"varint,9,opt,name=version,proto3" json:"version,omitempty"`
+}
+
+func (x *Trail) Reset() {
+	*x = Trail{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Trail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Trail) ProtoMessage() {}
+
+func (x *Trail) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Trail.ProtoReflect.Descriptor instead.
+func (*Trail) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Trail) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Trail) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *Trail) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Trail) GetCrumbIds() []string {
+	if x != nil {
+		return x.CrumbIds
+	}
+	return nil
+}
+
+func (x *Trail) GetPath() *Point {
+	if x != nil {
+		return x.Path
+	}
+	return nil
+}
+
+func (x *Trail) GetDistance() float64 {
+	if x != nil {
+		return x.Distance
+	}
+	return 0
+}
+
+func (x *Trail) GetBbox() []float64 {
+	if x != nil {
+		return x.Bbox
+	}
+	return nil
+}
+
+func (x *Trail) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Trail) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type AppendToTrailRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TrailId string `protobuf:"bytes,1,opt,name=trail_id,json=trailId,proto3" json:"trail_id,omitempty"`
+	// crumbs of the user of the trail, appended in order
+	CrumbIds []string `protobuf:"bytes,2,rep,name=crumb_ids,json=crumbIds,proto3" json:"crumb_ids,omitempty"`
+	// a non zero version must be the current version of the trail
+	Version int64 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+}
+
+func (x *AppendToTrailRequest) Reset() {
+	*x = AppendToTrailRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AppendToTrailRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppendToTrailRequest) ProtoMessage() {}
+
+func (x *AppendToTrailRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppendToTrailRequest.ProtoReflect.Descriptor instead.
+func (*AppendToTrailRequest) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AppendToTrailRequest) GetTrailId() string {
+	if x != nil {
+		return x.TrailId
+	}
+	return ""
+}
+
+func (x *AppendToTrailRequest) GetCrumbIds() []string {
+	if x != nil {
+		return x.CrumbIds
+	}
+	return nil
+}
+
+func (x *AppendToTrailRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type FindTrailsNearRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// a Point, or a Polygon given as flat [longitude, latitude] pairs of its counterclockwise vertices
+	Location *Point `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	// meters around a Point, at most 10000, defaults to 100
+	Radius float64 `protobuf:"fixed64,2,opt,name=radius,proto3" json:"radius,omitempty"`
+}
+
+func (x *FindTrailsNearRequest) Reset() {
+	*x = FindTrailsNearRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FindTrailsNearRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindTrailsNearRequest) ProtoMessage() {}
+
+func (x *FindTrailsNearRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindTrailsNearRequest.ProtoReflect.Descriptor instead.
+func (*FindTrailsNearRequest) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *FindTrailsNearRequest) GetLocation() *Point {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *FindTrailsNearRequest) GetRadius() float64 {
+	if x != nil {
+		return x.Radius
+	}
+	return 0
+}
+
+type Trails struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Trails []*Trail `protobuf:"bytes,1,rep,name=trails,proto3" json:"trails,omitempty"`
+}
+
+func (x *Trails) Reset() {
+	*x = Trails{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Trails) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Trails) ProtoMessage() {}
+
+func (x *Trails) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Trails.ProtoReflect.Descriptor instead.
+func (*Trails) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Trails) GetTrails() []*Trail {
+	if x != nil {
+		return x.Trails
+	}
+	return nil
+}
+
+// an area where crumbs are restricted, such as a school or private property
+type Zone struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// names the rule in the errors of the crumbs the zone rejects and is the id of its feature in the zones file
+	RuleId string `protobuf:"bytes,2,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"` // @gotags: validate:"required,max=100"
+	Name   string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`                   // @gotags: validate:"max=200"
+	// a Polygon given as flat [longitude, latitude] pairs of its vertices
+	Area *Point `protobuf:"bytes,4,opt,name=area,proto3" json:"area,omitempty"` // @gotags: validate:"required"
+	// most crumbs the zone holds, zero forbids crumbs in it
+	MaxCrumbs int64 `protobuf:"varint,5,opt,name=max_crumbs,json=maxCrumbs,proto3" json:"max_crumbs,omitempty"` // @gotags: validate:"gte=0"
+}
+
+func (x *Zone) Reset() {
+	*x = Zone{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Zone) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Zone) ProtoMessage() {}
+
+func (x *Zone) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Zone.ProtoReflect.Descriptor instead.
+func (*Zone) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Zone) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Zone) GetRuleId() string {
+	if x != nil {
+		return x.RuleId
+	}
+	return ""
+}
+
+func (x *Zone) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Zone) GetArea() *Point {
+	if x != nil {
+		return x.Area
+	}
+	return nil
+}
+
+func (x *Zone) GetMaxCrumbs() int64 {
+	if x != nil {
+		return x.MaxCrumbs
+	}
+	return 0
+}
+
+type ListZonesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListZonesRequest) Reset() {
+	*x = ListZonesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListZonesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListZonesRequest) ProtoMessage() {}
+
+func (x *ListZonesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListZonesRequest.ProtoReflect.Descriptor instead.
+func (*ListZonesRequest) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{13}
+}
+
+type Zones struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Zones []*Zone `protobuf:"bytes,1,rep,name=zones,proto3" json:"zones,omitempty"`
+}
+
+func (x *Zones) Reset() {
+	*x = Zones{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Zones) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Zones) ProtoMessage() {}
+
+func (x *Zones) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Zones.ProtoReflect.Descriptor instead.
+func (*Zones) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Zones) GetZones() []*Zone {
+	if x != nil {
+		return x.Zones
+	}
+	return nil
+}
+
+type ExportRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Format ExportFormat `protobuf:"varint,1,opt,name=format,proto3,enum=crumbdb.ExportFormat" json:"format,omitempty"`
+	// exports the crumbs and trails of user, newest first
+	User string `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	// or exports the crumbs GetCrumbs returns for location
+	Location *Point `protobuf:"bytes,3,opt,name=location,proto3" json:"location,omitempty"`
+}
+
+func (x *ExportRequest) Reset() {
+	*x = ExportRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportRequest) ProtoMessage() {}
+
+func (x *ExportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportRequest.ProtoReflect.Descriptor instead.
+func (*ExportRequest) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ExportRequest) GetFormat() ExportFormat {
+	if x != nil {
+		return x.Format
+	}
+	return ExportFormat_EXPORT_FORMAT_UNSPECIFIED
+}
+
+func (x *ExportRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *ExportRequest) GetLocation() *Point {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+// a part of an exported document, the document is the data of the chunks in order
+type ExportChunk struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// media type of the document, set on the first chunk
+	ContentType string `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+}
+
+func (x *ExportChunk) Reset() {
+	*x = ExportChunk{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportChunk) ProtoMessage() {}
+
+func (x *ExportChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportChunk.ProtoReflect.Descriptor instead.
+func (*ExportChunk) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ExportChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ExportChunk) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+// a crumb to import, as exported by Export
+type ImportCrumbsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Record:
+	//	*ImportCrumbsRequest_Feature
+	//	*ImportCrumbsRequest_Waypoint
+	Record isImportCrumbsRequest_Record `protobuf_oneof:"record"`
+	// validates the records without inserting them, only read from the first request
+	DryRun bool `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// user of the records, only read from the first request. It is the caller identified by its client
+	// certificate if any, and records with another user property are rejected
+	User string `protobuf:"bytes,4,opt,name=user,proto3" json:"user,omitempty"`
+}
+
+func (x *ImportCrumbsRequest) Reset() {
+	*x = ImportCrumbsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImportCrumbsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportCrumbsRequest) ProtoMessage() {}
+
+func (x *ImportCrumbsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportCrumbsRequest.ProtoReflect.Descriptor instead.
+func (*ImportCrumbsRequest) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{17}
+}
+
+func (m *ImportCrumbsRequest) GetRecord() isImportCrumbsRequest_Record {
+	if m != nil {
+		return m.Record
+	}
+	return nil
+}
+
+func (x *ImportCrumbsRequest) GetFeature() string {
+	if x, ok := x.GetRecord().(*ImportCrumbsRequest_Feature); ok {
+		return x.Feature
+	}
+	return ""
+}
+
+func (x *ImportCrumbsRequest) GetWaypoint() string {
+	if x, ok := x.GetRecord().(*ImportCrumbsRequest_Waypoint); ok {
+		return x.Waypoint
+	}
+	return ""
+}
+
+func (x *ImportCrumbsRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *ImportCrumbsRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+type isImportCrumbsRequest_Record interface {
+	isImportCrumbsRequest_Record()
+}
+
+type ImportCrumbsRequest_Feature struct {
+	// a GeoJSON Feature with a Point geometry
+	Feature string `protobuf:"bytes,1,opt,name=feature,proto3,oneof"`
+}
+
+type ImportCrumbsRequest_Waypoint struct {
+	// a GPX wpt element
+	Waypoint string `protobuf:"bytes,2,opt,name=waypoint,proto3,oneof"`
+}
+
+func (*ImportCrumbsRequest_Feature) isImportCrumbsRequest_Record() {}
+
+func (*ImportCrumbsRequest_Waypoint) isImportCrumbsRequest_Record() {}
+
+type ImportResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// position of the record in the requests, from 0
+	Index    int32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Accepted bool  `protobuf:"varint,2,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	// id of the inserted crumb, empty on a dry run
+	Id string `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`
+	// why the record was rejected
+	Reason string `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (x *ImportResult) Reset() {
+	*x = ImportResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImportResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportResult) ProtoMessage() {}
+
+func (x *ImportResult) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportResult.ProtoReflect.Descriptor instead.
+func (*ImportResult) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ImportResult) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *ImportResult) GetAccepted() bool {
+	if x != nil {
+		return x.Accepted
+	}
+	return false
+}
+
+func (x *ImportResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ImportResult) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ImportReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the result of every record, in order
+	Results  []*ImportResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	Accepted int32           `protobuf:"varint,2,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	Rejected int32           `protobuf:"varint,3,opt,name=rejected,proto3" json:"rejected,omitempty"`
+	DryRun   bool            `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+}
+
+func (x *ImportReport) Reset() {
+	*x = ImportReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImportReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportReport) ProtoMessage() {}
+
+func (x *ImportReport) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportReport.ProtoReflect.Descriptor instead.
+func (*ImportReport) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ImportReport) GetResults() []*ImportResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *ImportReport) GetAccepted() int32 {
+	if x != nil {
+		return x.Accepted
+	}
+	return 0
+}
+
+func (x *ImportReport) GetRejected() int32 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+func (x *ImportReport) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type UnlockCrumbRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// the Point the caller is at
+	Position *Point `protobuf:"bytes,2,opt,name=position,proto3" json:"position,omitempty"`
+}
+
+func (x *UnlockCrumbRequest) Reset() {
+	*x = UnlockCrumbRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnlockCrumbRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnlockCrumbRequest) ProtoMessage() {}
+
+func (x *UnlockCrumbRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnlockCrumbRequest.ProtoReflect.Descriptor instead.
+func (*UnlockCrumbRequest) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *UnlockCrumbRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UnlockCrumbRequest) GetPosition() *Point {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+type UnlockCrumbResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the crumb with its message
+	Crumb *Crumb `protobuf:"bytes,1,opt,name=crumb,proto3" json:"crumb,omitempty"`
+	// in meters, between the position and the crumb
+	Distance float64 `protobuf:"fixed64,2,opt,name=distance,proto3" json:"distance,omitempty"`
+}
+
+func (x *UnlockCrumbResponse) Reset() {
+	*x = UnlockCrumbResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnlockCrumbResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnlockCrumbResponse) ProtoMessage() {}
+
+func (x *UnlockCrumbResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnlockCrumbResponse.ProtoReflect.Descriptor instead.
+func (*UnlockCrumbResponse) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *UnlockCrumbResponse) GetCrumb() *Crumb {
+	if x != nil {
+		return x.Crumb
+	}
+	return nil
+}
+
+func (x *UnlockCrumbResponse) GetDistance() float64 {
+	if x != nil {
+		return x.Distance
+	}
+	return 0
+}
+
+// the record of a crumb unlocked by UnlockCrumb
+type Unlock struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id      string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                          // @gotags: bson:"_id,omitempty"
+	CrumbId string `protobuf:"bytes,2,opt,name=crumb_id,json=crumbId,proto3" json:"crumb_id,omitempty"` // @gotags: bson:"crumb_id"
+	// the caller, empty if anonymous
+	User     string  `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`           // @gotags: bson:"user"
+	Position *Point  `protobuf:"bytes,4,opt,name=position,proto3" json:"position,omitempty"`   // @gotags: bson:"position"
+	Distance float64 `protobuf:"fixed64,5,opt,name=distance,proto3" json:"distance,omitempty"` // @gotags: bson:"distance"
+	// in unix milliseconds
+	UnlockedAt int64 `protobuf:"varint,6,opt,name=unlocked_at,json=unlockedAt,proto3" json:"unlocked_at,omitempty"` // @gotags: bson:"unlocked_at"
+}
+
+func (x *Unlock) Reset() {
+	*x = Unlock{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Unlock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Unlock) ProtoMessage() {}
+
+func (x *Unlock) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Unlock.ProtoReflect.Descriptor instead.
+func (*Unlock) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *Unlock) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Unlock) GetCrumbId() string {
+	if x != nil {
+		return x.CrumbId
+	}
+	return ""
+}
+
+func (x *Unlock) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *Unlock) GetPosition() *Point {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+func (x *Unlock) GetDistance() float64 {
+	if x != nil {
+		return x.Distance
+	}
+	return 0
+}
+
+func (x *Unlock) GetUnlockedAt() int64 {
+	if x != nil {
+		return x.UnlockedAt
+	}
+	return 0
+}
+
+type Status struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Value int32 `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *Status) Reset() {
+	*x = Status{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_routegrpc_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Status) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Status) ProtoMessage() {}
+
+func (x *Status) ProtoReflect() protoreflect.Message {
+	mi := &file_routegrpc_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Status.ProtoReflect.Descriptor instead.
+func (*Status) Descriptor() ([]byte, []int) {
+	return file_routegrpc_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *Status) GetValue() int32 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+var File_routegrpc_proto protoreflect.FileDescriptor
+
+var file_routegrpc_proto_rawDesc = []byte{
+	0x0a, 0x0f, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x67, 0x72, 0x70, 0x63, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x07, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x20, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x5f,
+	0x6d, 0x61, 0x73, 0x6b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xd5, 0x02, 0x0a, 0x05, 0x43,
+	0x72, 0x75, 0x6d, 0x62, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x64, 0x12, 0x2a, 0x0a, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62,
+	0x2e, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x75, 0x73, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1d,
+	0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x18, 0x0a,
+	0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x33, 0x0a, 0x0a, 0x76, 0x69, 0x73, 0x69, 0x62,
+	0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x63, 0x72,
+	0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x56, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79,
+	0x52, 0x0a, 0x76, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x12, 0x0a, 0x04,
+	0x74, 0x61, 0x67, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73,
+	0x12, 0x23, 0x0a, 0x0d, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x75, 0x73, 0x65, 0x72,
+	0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64,
+	0x55, 0x73, 0x65, 0x72, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x12, 0x23, 0x0a,
+	0x0d, 0x75, 0x6e, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x72, 0x61, 0x64, 0x69, 0x75, 0x73, 0x18, 0x0b,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0c, 0x75, 0x6e, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x61, 0x64, 0x69,
+	0x75, 0x73, 0x22, 0x99, 0x01, 0x0a, 0x05, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x01, 0x52, 0x0b, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74,
+	0x65, 0x73, 0x12, 0x1f, 0x0a, 0x08, 0x61, 0x6c, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x08, 0x61, 0x6c, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65,
+	0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a, 0x08, 0x61, 0x63, 0x63, 0x75, 0x72, 0x61, 0x63, 0x79, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x01, 0x48, 0x01, 0x52, 0x08, 0x61, 0x63, 0x63, 0x75, 0x72, 0x61, 0x63,
+	0x79, 0x88, 0x01, 0x01, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x61, 0x6c, 0x74, 0x69, 0x74, 0x75, 0x64,
+	0x65, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x61, 0x63, 0x63, 0x75, 0x72, 0x61, 0x63, 0x79, 0x22, 0x1a,
+	0x0a, 0x02, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x1d, 0x0a, 0x03, 0x49, 0x64,
+	0x73, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0x30, 0x0a, 0x06, 0x43, 0x72, 0x75,
+	0x6d, 0x62, 0x73, 0x12, 0x26, 0x0a, 0x06, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x43, 0x72,
+	0x75, 0x6d, 0x62, 0x52, 0x06, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x22, 0x69, 0x0a, 0x17, 0x4c,
+	0x69, 0x73, 0x74, 0x43, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x42, 0x79, 0x55, 0x73, 0x65, 0x72, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61,
+	0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70,
+	0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x65, 0x5f,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x61, 0x67,
+	0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x6a, 0x0a, 0x18, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x72,
+	0x75, 0x6d, 0x62, 0x73, 0x42, 0x79, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x26, 0x0a, 0x06, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x43, 0x72, 0x75,
+	0x6d, 0x62, 0x52, 0x06, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6e, 0x65,
+	0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x22, 0x77, 0x0a, 0x12, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x43, 0x72, 0x75, 0x6d,
+	0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x05, 0x63, 0x72, 0x75, 0x6d,
+	0x62, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64,
+	0x62, 0x2e, 0x43, 0x72, 0x75, 0x6d, 0x62, 0x52, 0x05, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x12, 0x3b,
+	0x0a, 0x0b, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x6d, 0x61, 0x73, 0x6b, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4d, 0x61, 0x73, 0x6b, 0x52,
+	0x0a, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4d, 0x61, 0x73, 0x6b, 0x22, 0xe9, 0x01, 0x0a, 0x05,
+	0x54, 0x72, 0x61, 0x69, 0x6c, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1b, 0x0a,
+	0x09, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x08, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x49, 0x64, 0x73, 0x12, 0x22, 0x0a, 0x04, 0x70, 0x61,
+	0x74, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62,
+	0x64, 0x62, 0x2e, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x1a,
+	0x0a, 0x08, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x08, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x62,
+	0x6f, 0x78, 0x18, 0x07, 0x20, 0x03, 0x28, 0x01, 0x52, 0x04, 0x62, 0x62, 0x6f, 0x78, 0x12, 0x1d,
+	0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x18, 0x0a,
+	0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x68, 0x0a, 0x14, 0x41, 0x70, 0x70, 0x65, 0x6e,
+	0x64, 0x54, 0x6f, 0x54, 0x72, 0x61, 0x69, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x19, 0x0a, 0x08, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x72,
+	0x75, 0x6d, 0x62, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x63,
+	0x72, 0x75, 0x6d, 0x62, 0x49, 0x64, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x22, 0x5b, 0x0a, 0x15, 0x46, 0x69, 0x6e, 0x64, 0x54, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x4e,
+	0x65, 0x61, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2a, 0x0a, 0x08, 0x6c, 0x6f,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x63,
+	0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x08, 0x6c, 0x6f,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x61, 0x64, 0x69, 0x75, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x72, 0x61, 0x64, 0x69, 0x75, 0x73, 0x22, 0x30,
+	0x0a, 0x06, 0x54, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x12, 0x26, 0x0a, 0x06, 0x74, 0x72, 0x61, 0x69,
+	0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62,
+	0x64, 0x62, 0x2e, 0x54, 0x72, 0x61, 0x69, 0x6c, 0x52, 0x06, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73,
+	0x22, 0x86, 0x01, 0x0a, 0x04, 0x5a, 0x6f, 0x6e, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x75, 0x6c,
+	0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x75, 0x6c, 0x65,
+	0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x22, 0x0a, 0x04, 0x61, 0x72, 0x65, 0x61, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x50,
+	0x6f, 0x69, 0x6e, 0x74, 0x52, 0x04, 0x61, 0x72, 0x65, 0x61, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x61,
+	0x78, 0x5f, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09,
+	0x6d, 0x61, 0x78, 0x43, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x22, 0x12, 0x0a, 0x10, 0x4c, 0x69, 0x73,
+	0x74, 0x5a, 0x6f, 0x6e, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x2c, 0x0a,
+	0x05, 0x5a, 0x6f, 0x6e, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x05, 0x7a, 0x6f, 0x6e, 0x65, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e,
+	0x5a, 0x6f, 0x6e, 0x65, 0x52, 0x05, 0x7a, 0x6f, 0x6e, 0x65, 0x73, 0x22, 0x7e, 0x0a, 0x0d, 0x45,
+	0x78, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2d, 0x0a, 0x06,
+	0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x63,
+	0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x46, 0x6f, 0x72,
+	0x6d, 0x61, 0x74, 0x52, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x75,
+	0x73, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12,
+	0x2a, 0x0a, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0e, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x50, 0x6f, 0x69, 0x6e,
+	0x74, 0x52, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x44, 0x0a, 0x0b, 0x45,
+	0x78, 0x70, 0x6f, 0x72, 0x74, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61,
+	0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x21,
+	0x0a, 0x0c, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70,
+	0x65, 0x22, 0x86, 0x01, 0x0a, 0x13, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x43, 0x72, 0x75, 0x6d,
+	0x62, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x07, 0x66, 0x65, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x07, 0x66, 0x65,
+	0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x1c, 0x0a, 0x08, 0x77, 0x61, 0x79, 0x70, 0x6f, 0x69, 0x6e,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x08, 0x77, 0x61, 0x79, 0x70, 0x6f,
+	0x69, 0x6e, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x72, 0x79, 0x5f, 0x72, 0x75, 0x6e, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x12, 0x12, 0x0a, 0x04,
+	0x75, 0x73, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72,
+	0x42, 0x08, 0x0a, 0x06, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x22, 0x68, 0x0a, 0x0c, 0x49, 0x6d,
+	0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e,
+	0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78,
+	0x12, 0x1a, 0x0a, 0x08, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x08, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06,
+	0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x22, 0x90, 0x01, 0x0a, 0x0c, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x52,
+	0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x2f, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62,
+	0x2e, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x72,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74,
+	0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74,
+	0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x65, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x65, 0x64, 0x12, 0x17,
+	0x0a, 0x07, 0x64, 0x72, 0x79, 0x5f, 0x72, 0x75, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x06, 0x64, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x22, 0x50, 0x0a, 0x12, 0x55, 0x6e, 0x6c, 0x6f, 0x63,
+	0x6b, 0x43, 0x72, 0x75, 0x6d, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x2a, 0x0a,
+	0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0e, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52,
+	0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x57, 0x0a, 0x13, 0x55, 0x6e, 0x6c,
+	0x6f, 0x63, 0x6b, 0x43, 0x72, 0x75, 0x6d, 0x62, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x24, 0x0a, 0x05, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0e, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x43, 0x72, 0x75, 0x6d, 0x62, 0x52,
+	0x05, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e,
+	0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e,
+	0x63, 0x65, 0x22, 0xb0, 0x01, 0x0a, 0x06, 0x55, 0x6e, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x19, 0x0a,
+	0x08, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x2a, 0x0a, 0x08,
+	0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e,
+	0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x08,
+	0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x69, 0x73, 0x74,
+	0x61, 0x6e, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x64, 0x69, 0x73, 0x74,
+	0x61, 0x6e, 0x63, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x75, 0x6e, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64,
+	0x5f, 0x61, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x75, 0x6e, 0x6c, 0x6f, 0x63,
+	0x6b, 0x65, 0x64, 0x41, 0x74, 0x22, 0x1e, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x2a, 0x74, 0x0a, 0x0a, 0x56, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c,
+	0x69, 0x74, 0x79, 0x12, 0x15, 0x0a, 0x11, 0x56, 0x49, 0x53, 0x49, 0x42, 0x49, 0x4c, 0x49, 0x54,
+	0x59, 0x5f, 0x50, 0x55, 0x42, 0x4c, 0x49, 0x43, 0x10, 0x00, 0x12, 0x18, 0x0a, 0x14, 0x56, 0x49,
+	0x53, 0x49, 0x42, 0x49, 0x4c, 0x49, 0x54, 0x59, 0x5f, 0x46, 0x4f, 0x4c, 0x4c, 0x4f, 0x57, 0x45,
+	0x52, 0x53, 0x10, 0x01, 0x12, 0x16, 0x0a, 0x12, 0x56, 0x49, 0x53, 0x49, 0x42, 0x49, 0x4c, 0x49,
+	0x54, 0x59, 0x5f, 0x50, 0x52, 0x49, 0x56, 0x41, 0x54, 0x45, 0x10, 0x02, 0x12, 0x1d, 0x0a, 0x19,
+	0x56, 0x49, 0x53, 0x49, 0x42, 0x49, 0x4c, 0x49, 0x54, 0x59, 0x5f, 0x53, 0x50, 0x45, 0x43, 0x49,
+	0x46, 0x49, 0x43, 0x5f, 0x55, 0x53, 0x45, 0x52, 0x53, 0x10, 0x03, 0x2a, 0x76, 0x0a, 0x0c, 0x45,
+	0x78, 0x70, 0x6f, 0x72, 0x74, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x1d, 0x0a, 0x19, 0x45,
+	0x58, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x55, 0x4e, 0x53,
+	0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x19, 0x0a, 0x15, 0x45, 0x58,
+	0x50, 0x4f, 0x52, 0x54, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x47, 0x45, 0x4f, 0x4a,
+	0x53, 0x4f, 0x4e, 0x10, 0x01, 0x12, 0x15, 0x0a, 0x11, 0x45, 0x58, 0x50, 0x4f, 0x52, 0x54, 0x5f,
+	0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x47, 0x50, 0x58, 0x10, 0x02, 0x12, 0x15, 0x0a, 0x11,
+	0x45, 0x58, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x4b, 0x4d,
+	0x4c, 0x10, 0x03, 0x32, 0xf1, 0x0a, 0x0a, 0x07, 0x43, 0x72, 0x75, 0x6d, 0x62, 0x44, 0x42, 0x12,
+	0x3c, 0x0a, 0x06, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0x0e, 0x2e, 0x63, 0x72, 0x75, 0x6d,
+	0x62, 0x64, 0x62, 0x2e, 0x43, 0x72, 0x75, 0x6d, 0x62, 0x1a, 0x0b, 0x2e, 0x63, 0x72, 0x75, 0x6d,
+	0x62, 0x64, 0x62, 0x2e, 0x49, 0x64, 0x22, 0x15, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0f, 0x3a, 0x01,
+	0x2a, 0x22, 0x0a, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x12, 0x41, 0x0a,
+	0x09, 0x47, 0x65, 0x74, 0x43, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x12, 0x0e, 0x2e, 0x63, 0x72, 0x75,
+	0x6d, 0x62, 0x64, 0x62, 0x2e, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x1a, 0x0e, 0x2e, 0x63, 0x72, 0x75,
+	0x6d, 0x62, 0x64, 0x62, 0x2e, 0x43, 0x72, 0x75, 0x6d, 0x62, 0x22, 0x12, 0x82, 0xd3, 0xe4, 0x93,
+	0x02, 0x0c, 0x12, 0x0a, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x30, 0x01,
+	0x12, 0x43, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x43, 0x72, 0x75, 0x6d, 0x62, 0x12, 0x0b, 0x2e, 0x63,
+	0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x49, 0x64, 0x1a, 0x0e, 0x2e, 0x63, 0x72, 0x75, 0x6d,
+	0x62, 0x64, 0x62, 0x2e, 0x43, 0x72, 0x75, 0x6d, 0x62, 0x22, 0x1a, 0x82, 0xd3, 0xe4, 0x93, 0x02,
+	0x14, 0x12, 0x12, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x2f, 0x7b, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x7d, 0x12, 0x4c, 0x0a, 0x0e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65,
+	0x74, 0x43, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x12, 0x0c, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64,
+	0x62, 0x2e, 0x49, 0x64, 0x73, 0x1a, 0x0f, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e,
+	0x43, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x22, 0x1b, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x15, 0x12, 0x13,
+	0x2f, 0x76, 0x31, 0x2f, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x3a, 0x62, 0x61, 0x74, 0x63, 0x68,
+	0x47, 0x65, 0x74, 0x12, 0x78, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x72, 0x75, 0x6d, 0x62,
+	0x73, 0x42, 0x79, 0x55, 0x73, 0x65, 0x72, 0x12, 0x20, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64,
+	0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x42, 0x79, 0x55, 0x73,
+	0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x63, 0x72, 0x75, 0x6d,
+	0x62, 0x64, 0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x42, 0x79,
+	0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1f, 0x82, 0xd3,
+	0xe4, 0x93, 0x02, 0x19, 0x12, 0x17, 0x2f, 0x76, 0x31, 0x2f, 0x75, 0x73, 0x65, 0x72, 0x73, 0x2f,
+	0x7b, 0x75, 0x73, 0x65, 0x72, 0x7d, 0x2f, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x12, 0x5b, 0x0a,
+	0x06, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x1b, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64,
+	0x62, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x43, 0x72, 0x75, 0x6d, 0x62, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x43,
+	0x72, 0x75, 0x6d, 0x62, 0x22, 0x24, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1e, 0x3a, 0x05, 0x63, 0x72,
+	0x75, 0x6d, 0x62, 0x32, 0x15, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x2f,
+	0x7b, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x2e, 0x69, 0x64, 0x7d, 0x12, 0x3e, 0x0a, 0x06, 0x44, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x12, 0x0b, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x49,
+	0x64, 0x1a, 0x0b, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x49, 0x64, 0x22, 0x1a,
+	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x14, 0x2a, 0x12, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x72, 0x75, 0x6d,
+	0x62, 0x73, 0x2f, 0x7b, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x7d, 0x12, 0x44, 0x0a, 0x0b, 0x43, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x54, 0x72, 0x61, 0x69, 0x6c, 0x12, 0x0e, 0x2e, 0x63, 0x72, 0x75, 0x6d,
+	0x62, 0x64, 0x62, 0x2e, 0x54, 0x72, 0x61, 0x69, 0x6c, 0x1a, 0x0e, 0x2e, 0x63, 0x72, 0x75, 0x6d,
+	0x62, 0x64, 0x62, 0x2e, 0x54, 0x72, 0x61, 0x69, 0x6c, 0x22, 0x15, 0x82, 0xd3, 0xe4, 0x93, 0x02,
+	0x0f, 0x3a, 0x01, 0x2a, 0x22, 0x0a, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73,
+	0x12, 0x67, 0x0a, 0x0d, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x54, 0x6f, 0x54, 0x72, 0x61, 0x69,
+	0x6c, 0x12, 0x1d, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x41, 0x70, 0x70, 0x65,
+	0x6e, 0x64, 0x54, 0x6f, 0x54, 0x72, 0x61, 0x69, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x0e, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x54, 0x72, 0x61, 0x69, 0x6c,
+	0x22, 0x27, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x21, 0x3a, 0x01, 0x2a, 0x22, 0x1c, 0x2f, 0x76, 0x31,
+	0x2f, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x2f, 0x7b, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x5f, 0x69,
+	0x64, 0x7d, 0x3a, 0x61, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x12, 0x43, 0x0a, 0x08, 0x47, 0x65, 0x74,
+	0x54, 0x72, 0x61, 0x69, 0x6c, 0x12, 0x0b, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e,
+	0x49, 0x64, 0x1a, 0x0e, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x54, 0x72, 0x61,
+	0x69, 0x6c, 0x22, 0x1a, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x14, 0x12, 0x12, 0x2f, 0x76, 0x31, 0x2f,
+	0x74, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x2f, 0x7b, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x7d, 0x12, 0x5a,
+	0x0a, 0x0e, 0x46, 0x69, 0x6e, 0x64, 0x54, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x4e, 0x65, 0x61, 0x72,
+	0x12, 0x1e, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x46, 0x69, 0x6e, 0x64, 0x54,
+	0x72, 0x61, 0x69, 0x6c, 0x73, 0x4e, 0x65, 0x61, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x0f, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x54, 0x72, 0x61, 0x69, 0x6c,
+	0x73, 0x22, 0x17, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x11, 0x12, 0x0f, 0x2f, 0x76, 0x31, 0x2f, 0x74,
+	0x72, 0x61, 0x69, 0x6c, 0x73, 0x3a, 0x6e, 0x65, 0x61, 0x72, 0x12, 0x4c, 0x0a, 0x06, 0x45, 0x78,
+	0x70, 0x6f, 0x72, 0x74, 0x12, 0x16, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x45,
+	0x78, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x63,
+	0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x43, 0x68, 0x75,
+	0x6e, 0x6b, 0x22, 0x12, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0c, 0x12, 0x0a, 0x2f, 0x76, 0x31, 0x2f,
+	0x65, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x30, 0x01, 0x12, 0x63, 0x0a, 0x0c, 0x49, 0x6d, 0x70, 0x6f,
+	0x72, 0x74, 0x43, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x12, 0x1c, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62,
+	0x64, 0x62, 0x2e, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x43, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62,
+	0x2e, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x22, 0x1c, 0x82,
+	0xd3, 0xe4, 0x93, 0x02, 0x16, 0x3a, 0x01, 0x2a, 0x22, 0x11, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x72,
+	0x75, 0x6d, 0x62, 0x73, 0x3a, 0x69, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x28, 0x01, 0x12, 0x6b, 0x0a,
+	0x0b, 0x55, 0x6e, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x72, 0x75, 0x6d, 0x62, 0x12, 0x1b, 0x2e, 0x63,
+	0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x55, 0x6e, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x72, 0x75,
+	0x6d, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x63, 0x72, 0x75, 0x6d,
+	0x62, 0x64, 0x62, 0x2e, 0x55, 0x6e, 0x6c, 0x6f, 0x63, 0x6b, 0x43, 0x72, 0x75, 0x6d, 0x62, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x21, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1b, 0x3a,
+	0x01, 0x2a, 0x22, 0x16, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x73, 0x2f, 0x7b,
+	0x69, 0x64, 0x7d, 0x3a, 0x75, 0x6e, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x3d, 0x0a, 0x07, 0x50, 0x75,
+	0x74, 0x5a, 0x6f, 0x6e, 0x65, 0x12, 0x0d, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e,
+	0x5a, 0x6f, 0x6e, 0x65, 0x1a, 0x0d, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x5a,
+	0x6f, 0x6e, 0x65, 0x22, 0x14, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0e, 0x3a, 0x01, 0x2a, 0x22, 0x09,
+	0x2f, 0x76, 0x31, 0x2f, 0x7a, 0x6f, 0x6e, 0x65, 0x73, 0x12, 0x41, 0x0a, 0x0a, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x5a, 0x6f, 0x6e, 0x65, 0x12, 0x0b, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64,
+	0x62, 0x2e, 0x49, 0x64, 0x1a, 0x0b, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x49,
+	0x64, 0x22, 0x19, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x13, 0x2a, 0x11, 0x2f, 0x76, 0x31, 0x2f, 0x7a,
+	0x6f, 0x6e, 0x65, 0x73, 0x2f, 0x7b, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x7d, 0x12, 0x49, 0x0a, 0x09,
+	0x4c, 0x69, 0x73, 0x74, 0x5a, 0x6f, 0x6e, 0x65, 0x73, 0x12, 0x19, 0x2e, 0x63, 0x72, 0x75, 0x6d,
+	0x62, 0x64, 0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x5a, 0x6f, 0x6e, 0x65, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2e, 0x5a,
+	0x6f, 0x6e, 0x65, 0x73, 0x22, 0x11, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0b, 0x12, 0x09, 0x2f, 0x76,
+	0x31, 0x2f, 0x7a, 0x6f, 0x6e, 0x65, 0x73, 0x42, 0x38, 0x5a, 0x36, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x68, 0x61, 0x67, 0x75, 0x72, 0x75, 0x2f, 0x68, 0x6f, 0x72,
+	0x75, 0x73, 0x2f, 0x63, 0x72, 0x75, 0x6d, 0x62, 0x64, 0x62, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72,
+	0x6e, 0x61, 0x6c, 0x2f, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_routegrpc_proto_rawDescOnce sync.Once
+	file_routegrpc_proto_rawDescData = file_routegrpc_proto_rawDesc
+)
+
+func file_routegrpc_proto_rawDescGZIP() []byte {
+	file_routegrpc_proto_rawDescOnce.Do(func() {
+		file_routegrpc_proto_rawDescData = protoimpl.X.CompressGZIP(file_routegrpc_proto_rawDescData)
+	})
+	return file_routegrpc_proto_rawDescData
+}
+
+var file_routegrpc_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_routegrpc_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
+var file_routegrpc_proto_goTypes = []any{
+	(Visibility)(0),                  // 0: crumbdb.Visibility
+	(ExportFormat)(0),                // 1: crumbdb.ExportFormat
+	(*Crumb)(nil),                    // 2: crumbdb.Crumb
+	(*Point)(nil),                    // 3: crumbdb.Point
+	(*Id)(nil),                       // 4: crumbdb.Id
+	(*Ids)(nil),                      // 5: crumbdb.Ids
+	(*Crumbs)(nil),                   // 6: crumbdb.Crumbs
+	(*ListCrumbsByUserRequest)(nil),  // 7: crumbdb.ListCrumbsByUserRequest
+	(*ListCrumbsByUserResponse)(nil), // 8: crumbdb.ListCrumbsByUserResponse
+	(*UpdateCrumbRequest)(nil),       // 9: crumbdb.UpdateCrumbRequest
+	(*Trail)(nil),                    // 10: crumbdb.Trail
+	(*AppendToTrailRequest)(nil),     // 11: crumbdb.AppendToTrailRequest
+	(*FindTrailsNearRequest)(nil),    // 12: crumbdb.FindTrailsNearRequest
+	(*Trails)(nil),                   // 13: crumbdb.Trails
+	(*Zone)(nil),                     // 14: crumbdb.Zone
+	(*ListZonesRequest)(nil),         // 15: crumbdb.ListZonesRequest
+	(*Zones)(nil),                    // 16: crumbdb.Zones
+	(*ExportRequest)(nil),            // 17: crumbdb.ExportRequest
+	(*ExportChunk)(nil),              // 18: crumbdb.ExportChunk
+	(*ImportCrumbsRequest)(nil),      // 19: crumbdb.ImportCrumbsRequest
+	(*ImportResult)(nil),             // 20: crumbdb.ImportResult
+	(*ImportReport)(nil),             // 21: crumbdb.ImportReport
+	(*UnlockCrumbRequest)(nil),       // 22: crumbdb.UnlockCrumbRequest
+	(*UnlockCrumbResponse)(nil),      // 23: crumbdb.UnlockCrumbResponse
+	(*Unlock)(nil),                   // 24: crumbdb.Unlock
+	(*Status)(nil),                   // 25: crumbdb.Status
+	(*fieldmaskpb.FieldMask)(nil),    // 26: google.protobuf.FieldMask
+}
+var file_routegrpc_proto_depIdxs = []int32{
+	3,  // 0: crumbdb.Crumb.location:type_name -> crumbdb.Point
+	0,  // 1: crumbdb.Crumb.visibility:type_name -> crumbdb.Visibility
+	2,  // 2: crumbdb.Crumbs.crumbs:type_name -> crumbdb.Crumb
+	2,  // 3: crumbdb.ListCrumbsByUserResponse.crumbs:type_name -> crumbdb.Crumb
+	2,  // 4: crumbdb.UpdateCrumbRequest.crumb:type_name -> crumbdb.Crumb
+	26, // 5: crumbdb.UpdateCrumbRequest.update_mask:type_name -> google.protobuf.FieldMask
+	3,  // 6: crumbdb.Trail.path:type_name -> crumbdb.Point
+	3,  // 7: crumbdb.FindTrailsNearRequest.location:type_name -> crumbdb.Point
+	10, // 8: crumbdb.Trails.trails:type_name -> crumbdb.Trail
+	3,  // 9: crumbdb.Zone.area:type_name -> crumbdb.Point
+	14, // 10: crumbdb.Zones.zones:type_name -> crumbdb.Zone
+	1,  // 11: crumbdb.ExportRequest.format:type_name -> crumbdb.ExportFormat
+	3,  // 12: crumbdb.ExportRequest.location:type_name -> crumbdb.Point
+	20, // 13: crumbdb.ImportReport.results:type_name -> crumbdb.ImportResult
+	3,  // 14: crumbdb.UnlockCrumbRequest.position:type_name -> crumbdb.Point
+	2,  // 15: crumbdb.UnlockCrumbResponse.crumb:type_name -> crumbdb.Crumb
+	3,  // 16: crumbdb.Unlock.position:type_name -> crumbdb.Point
+	2,  // 17: crumbdb.CrumbDB.Create:input_type -> crumbdb.Crumb
+	3,  // 18: crumbdb.CrumbDB.GetCrumbs:input_type -> crumbdb.Point
+	4,  // 19: crumbdb.CrumbDB.GetCrumb:input_type -> crumbdb.Id
+	5,  // 20: crumbdb.CrumbDB.BatchGetCrumbs:input_type -> crumbdb.Ids
+	7,  // 21: crumbdb.CrumbDB.ListCrumbsByUser:input_type -> crumbdb.ListCrumbsByUserRequest
+	9,  // 22: crumbdb.CrumbDB.Update:input_type -> crumbdb.UpdateCrumbRequest
+	4,  // 23: crumbdb.CrumbDB.Delete:input_type -> crumbdb.Id
+	10, // 24: crumbdb.CrumbDB.CreateTrail:input_type -> crumbdb.Trail
+	11, // 25: crumbdb.CrumbDB.AppendToTrail:input_type -> crumbdb.AppendToTrailRequest
+	4,  // 26: crumbdb.CrumbDB.GetTrail:input_type -> crumbdb.Id
+	12, // 27: crumbdb.CrumbDB.FindTrailsNear:input_type -> crumbdb.FindTrailsNearRequest
+	17, // 28: crumbdb.CrumbDB.Export:input_type -> crumbdb.ExportRequest
+	19, // 29: crumbdb.CrumbDB.ImportCrumbs:input_type -> crumbdb.ImportCrumbsRequest
+	22, // 30: crumbdb.CrumbDB.UnlockCrumb:input_type -> crumbdb.UnlockCrumbRequest
+	14, // 31: crumbdb.CrumbDB.PutZone:input_type -> crumbdb.Zone
+	4,  // 32: crumbdb.CrumbDB.DeleteZone:input_type -> crumbdb.Id
+	15, // 33: crumbdb.CrumbDB.ListZones:input_type -> crumbdb.ListZonesRequest
+	4,  // 34: crumbdb.CrumbDB.Create:output_type -> crumbdb.Id
+	2,  // 35: crumbdb.CrumbDB.GetCrumbs:output_type -> crumbdb.Crumb
+	2,  // 36: crumbdb.CrumbDB.GetCrumb:output_type -> crumbdb.Crumb
+	6,  // 37: crumbdb.CrumbDB.BatchGetCrumbs:output_type -> crumbdb.Crumbs
+	8,  // 38: crumbdb.CrumbDB.ListCrumbsByUser:output_type -> crumbdb.ListCrumbsByUserResponse
+	2,  // 39: crumbdb.CrumbDB.Update:output_type -> crumbdb.Crumb
+	4,  // 40: crumbdb.CrumbDB.Delete:output_type -> crumbdb.Id
+	10, // 41: crumbdb.CrumbDB.CreateTrail:output_type -> crumbdb.Trail
+	10, // 42: crumbdb.CrumbDB.AppendToTrail:output_type -> crumbdb.Trail
+	10, // 43: crumbdb.CrumbDB.GetTrail:output_type -> crumbdb.Trail
+	13, // 44: crumbdb.CrumbDB.FindTrailsNear:output_type -> crumbdb.Trails
+	18, // 45: crumbdb.CrumbDB.Export:output_type -> crumbdb.ExportChunk
+	21, // 46: crumbdb.CrumbDB.ImportCrumbs:output_type -> crumbdb.ImportReport
+	23, // 47: crumbdb.CrumbDB.UnlockCrumb:output_type -> crumbdb.UnlockCrumbResponse
+	14, // 48: crumbdb.CrumbDB.PutZone:output_type -> crumbdb.Zone
+	4,  // 49: crumbdb.CrumbDB.DeleteZone:output_type -> crumbdb.Id
+	16, // 50: crumbdb.CrumbDB.ListZones:output_type -> crumbdb.Zones
+	34, // [34:51] is the sub-list for method output_type
+	17, // [17:34] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
+}
+
+func init() { file_routegrpc_proto_init() }
+func file_routegrpc_proto_init() {
+	if File_routegrpc_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_routegrpc_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*Crumb); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*Point); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*Id); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*Ids); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*Crumbs); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*ListCrumbsByUserRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*ListCrumbsByUserResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*UpdateCrumbRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*Trail); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*AppendToTrailRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*FindTrailsNearRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*Trails); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[12].Exporter = func(v any, i int) any {
+			switch v := v.(*Zone); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[13].Exporter = func(v any, i int) any {
+			switch v := v.(*ListZonesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[14].Exporter = func(v any, i int) any {
+			switch v := v.(*Zones); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[15].Exporter = func(v any, i int) any {
+			switch v := v.(*ExportRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[16].Exporter = func(v any, i int) any {
+			switch v := v.(*ExportChunk); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[17].Exporter = func(v any, i int) any {
+			switch v := v.(*ImportCrumbsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[18].Exporter = func(v any, i int) any {
+			switch v := v.(*ImportResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[19].Exporter = func(v any, i int) any {
+			switch v := v.(*ImportReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[20].Exporter = func(v any, i int) any {
+			switch v := v.(*UnlockCrumbRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[21].Exporter = func(v any, i int) any {
+			switch v := v.(*UnlockCrumbResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[22].Exporter = func(v any, i int) any {
+			switch v := v.(*Unlock); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_routegrpc_proto_msgTypes[23].Exporter = func(v any, i int) any {
+			switch v := v.(*Status); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_routegrpc_proto_msgTypes[1].OneofWrappers = []any{}
+	file_routegrpc_proto_msgTypes[17].OneofWrappers = []any{
+		(*ImportCrumbsRequest_Feature)(nil),
+		(*ImportCrumbsRequest_Waypoint)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_routegrpc_proto_rawDesc,
+			NumEnums:      2,
+			NumMessages:   24,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_routegrpc_proto_goTypes,
+		DependencyIndexes: file_routegrpc_proto_depIdxs,
+		EnumInfos:         file_routegrpc_proto_enumTypes,
+		MessageInfos:      file_routegrpc_proto_msgTypes,
+	}.Build()
+	File_routegrpc_proto = out.File
+	file_routegrpc_proto_rawDesc = nil
+	file_routegrpc_proto_goTypes = nil
+	file_routegrpc_proto_depIdxs = nil
+}
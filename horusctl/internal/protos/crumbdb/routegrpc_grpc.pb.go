@@ -0,0 +1,797 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v3.6.1
+// source: routegrpc.proto
+
+package crumbdb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CrumbDB_Create_FullMethodName           = "/crumbdb.CrumbDB/Create"
+	CrumbDB_GetCrumbs_FullMethodName        = "/crumbdb.CrumbDB/GetCrumbs"
+	CrumbDB_GetCrumb_FullMethodName         = "/crumbdb.CrumbDB/GetCrumb"
+	CrumbDB_BatchGetCrumbs_FullMethodName   = "/crumbdb.CrumbDB/BatchGetCrumbs"
+	CrumbDB_ListCrumbsByUser_FullMethodName = "/crumbdb.CrumbDB/ListCrumbsByUser"
+	CrumbDB_Update_FullMethodName           = "/crumbdb.CrumbDB/Update"
+	CrumbDB_Delete_FullMethodName           = "/crumbdb.CrumbDB/Delete"
+	CrumbDB_CreateTrail_FullMethodName      = "/crumbdb.CrumbDB/CreateTrail"
+	CrumbDB_AppendToTrail_FullMethodName    = "/crumbdb.CrumbDB/AppendToTrail"
+	CrumbDB_GetTrail_FullMethodName         = "/crumbdb.CrumbDB/GetTrail"
+	CrumbDB_FindTrailsNear_FullMethodName   = "/crumbdb.CrumbDB/FindTrailsNear"
+	CrumbDB_Export_FullMethodName           = "/crumbdb.CrumbDB/Export"
+	CrumbDB_ImportCrumbs_FullMethodName     = "/crumbdb.CrumbDB/ImportCrumbs"
+	CrumbDB_UnlockCrumb_FullMethodName      = "/crumbdb.CrumbDB/UnlockCrumb"
+	CrumbDB_PutZone_FullMethodName          = "/crumbdb.CrumbDB/PutZone"
+	CrumbDB_DeleteZone_FullMethodName       = "/crumbdb.CrumbDB/DeleteZone"
+	CrumbDB_ListZones_FullMethodName        = "/crumbdb.CrumbDB/ListZones"
+)
+
+// CrumbDBClient is the client API for CrumbDB service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CrumbDBClient interface {
+	// Create a crumb. An authenticated caller only creates its own crumbs, the user of a crumb defaulting to the caller
+	Create(ctx context.Context, in *Crumb, opts ...grpc.CallOption) (*Id, error)
+	// Read, streamed as newline delimited JSON or server-sent events over REST. Only the crumbs visible to the
+	// caller identified by its client certificate are returned. Locked crumbs of other users are returned without
+	// their message, which only UnlockCrumb reveals
+	GetCrumbs(ctx context.Context, in *Point, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Crumb], error)
+	// Read a crumb by id. Fails with NotFound if the caller cannot see it
+	GetCrumb(ctx context.Context, in *Id, opts ...grpc.CallOption) (*Crumb, error)
+	// Read up to 100 crumbs by id, in the order of the ids. Fails with NotFound if any is missing or hidden from
+	// the caller
+	BatchGetCrumbs(ctx context.Context, in *Ids, opts ...grpc.CallOption) (*Crumbs, error)
+	// Read the crumbs of a user the caller can see, newest first, a page at a time. A page may hold fewer crumbs
+	// than its size when some are hidden
+	ListCrumbsByUser(ctx context.Context, in *ListCrumbsByUserRequest, opts ...grpc.CallOption) (*ListCrumbsByUserResponse, error)
+	// Update the fields of a crumb in the update mask and return it. Only its owner updates a crumb, other callers
+	// get PermissionDenied, or NotFound when they cannot see it. The version must be the current version of the
+	// crumb, otherwise it fails with Aborted, or with FailedPrecondition if it is missing
+	Update(ctx context.Context, in *UpdateCrumbRequest, opts ...grpc.CallOption) (*Crumb, error)
+	// Delete, removing the crumb from the trails containing it. Only its owner deletes a crumb, other callers get
+	// PermissionDenied, or NotFound when they cannot see it
+	Delete(ctx context.Context, in *Id, opts ...grpc.CallOption) (*Id, error)
+	// Create a trail of existing crumbs of its user and return it. An authenticated caller only creates its own
+	// trails. Crumbs of other users fail with NotFound, like missing crumbs
+	CreateTrail(ctx context.Context, in *Trail, opts ...grpc.CallOption) (*Trail, error)
+	// Append crumbs to a trail and return it. Only the owner of the trail appends to it, and only its own crumbs.
+	// A non zero version must be the current version of the trail, otherwise it fails with Aborted
+	AppendToTrail(ctx context.Context, in *AppendToTrailRequest, opts ...grpc.CallOption) (*Trail, error)
+	// Read a trail by id. The crumbs of other users hidden from the caller are left out of it and of its path
+	GetTrail(ctx context.Context, in *Id, opts ...grpc.CallOption) (*Trail, error)
+	// Read the trails whose path, made of the crumbs the caller can see, passes within the radius of a Point or
+	// through a Polygon
+	FindTrailsNear(ctx context.Context, in *FindTrailsNearRequest, opts ...grpc.CallOption) (*Trails, error)
+	// Export the crumbs and trails of a user, or the crumbs near a location, as a GeoJSON FeatureCollection, GPX
+	// or KML document streamed in chunks. Only the crumbs the caller can see are exported, and the trails go through
+	// those crumbs only
+	Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportChunk], error)
+	// Import crumbs from GeoJSON features or GPX waypoints, a record per request or per line of newline
+	// delimited JSON over REST, and report which were accepted and why the others were rejected. Fails with
+	// PermissionDenied if the caller imports the crumbs of another user
+	ImportCrumbs(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportCrumbsRequest, ImportReport], error)
+	// Read the message of a locked crumb from a position within its unlock radius and record the unlock. Fails
+	// with FailedPrecondition if the position is too far or rejected as spoofed
+	UnlockCrumb(ctx context.Context, in *UnlockCrumbRequest, opts ...grpc.CallOption) (*UnlockCrumbResponse, error)
+	// Create a zone, or replace the zone with its id, and return it. Only operators can manage zones. Create and
+	// Update fail with FailedPrecondition, naming the rule id, when a crumb would be in a zone forbidding crumbs or
+	// holding its most crumbs
+	PutZone(ctx context.Context, in *Zone, opts ...grpc.CallOption) (*Zone, error)
+	// Delete the zone with id
+	DeleteZone(ctx context.Context, in *Id, opts ...grpc.CallOption) (*Id, error)
+	// Read the zones
+	ListZones(ctx context.Context, in *ListZonesRequest, opts ...grpc.CallOption) (*Zones, error)
+}
+
+type crumbDBClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCrumbDBClient(cc grpc.ClientConnInterface) CrumbDBClient {
+	return &crumbDBClient{cc}
+}
+
+func (c *crumbDBClient) Create(ctx context.Context, in *Crumb, opts ...grpc.CallOption) (*Id, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Id)
+	err := c.cc.Invoke(ctx, CrumbDB_Create_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crumbDBClient) GetCrumbs(ctx context.Context, in *Point, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Crumb], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CrumbDB_ServiceDesc.Streams[0], CrumbDB_GetCrumbs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[Point, Crumb]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CrumbDB_GetCrumbsClient = grpc.ServerStreamingClient[Crumb]
+
+func (c *crumbDBClient) GetCrumb(ctx context.Context, in *Id, opts ...grpc.CallOption) (*Crumb, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Crumb)
+	err := c.cc.Invoke(ctx, CrumbDB_GetCrumb_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crumbDBClient) BatchGetCrumbs(ctx context.Context, in *Ids, opts ...grpc.CallOption) (*Crumbs, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Crumbs)
+	err := c.cc.Invoke(ctx, CrumbDB_BatchGetCrumbs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crumbDBClient) ListCrumbsByUser(ctx context.Context, in *ListCrumbsByUserRequest, opts ...grpc.CallOption) (*ListCrumbsByUserResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCrumbsByUserResponse)
+	err := c.cc.Invoke(ctx, CrumbDB_ListCrumbsByUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crumbDBClient) Update(ctx context.Context, in *UpdateCrumbRequest, opts ...grpc.CallOption) (*Crumb, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Crumb)
+	err := c.cc.Invoke(ctx, CrumbDB_Update_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crumbDBClient) Delete(ctx context.Context, in *Id, opts ...grpc.CallOption) (*Id, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Id)
+	err := c.cc.Invoke(ctx, CrumbDB_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crumbDBClient) CreateTrail(ctx context.Context, in *Trail, opts ...grpc.CallOption) (*Trail, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Trail)
+	err := c.cc.Invoke(ctx, CrumbDB_CreateTrail_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crumbDBClient) AppendToTrail(ctx context.Context, in *AppendToTrailRequest, opts ...grpc.CallOption) (*Trail, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Trail)
+	err := c.cc.Invoke(ctx, CrumbDB_AppendToTrail_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crumbDBClient) GetTrail(ctx context.Context, in *Id, opts ...grpc.CallOption) (*Trail, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Trail)
+	err := c.cc.Invoke(ctx, CrumbDB_GetTrail_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crumbDBClient) FindTrailsNear(ctx context.Context, in *FindTrailsNearRequest, opts ...grpc.CallOption) (*Trails, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Trails)
+	err := c.cc.Invoke(ctx, CrumbDB_FindTrailsNear_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crumbDBClient) Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CrumbDB_ServiceDesc.Streams[1], CrumbDB_Export_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportRequest, ExportChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CrumbDB_ExportClient = grpc.ServerStreamingClient[ExportChunk]
+
+func (c *crumbDBClient) ImportCrumbs(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportCrumbsRequest, ImportReport], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CrumbDB_ServiceDesc.Streams[2], CrumbDB_ImportCrumbs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportCrumbsRequest, ImportReport]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CrumbDB_ImportCrumbsClient = grpc.ClientStreamingClient[ImportCrumbsRequest, ImportReport]
+
+func (c *crumbDBClient) UnlockCrumb(ctx context.Context, in *UnlockCrumbRequest, opts ...grpc.CallOption) (*UnlockCrumbResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnlockCrumbResponse)
+	err := c.cc.Invoke(ctx, CrumbDB_UnlockCrumb_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crumbDBClient) PutZone(ctx context.Context, in *Zone, opts ...grpc.CallOption) (*Zone, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Zone)
+	err := c.cc.Invoke(ctx, CrumbDB_PutZone_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crumbDBClient) DeleteZone(ctx context.Context, in *Id, opts ...grpc.CallOption) (*Id, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Id)
+	err := c.cc.Invoke(ctx, CrumbDB_DeleteZone_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crumbDBClient) ListZones(ctx context.Context, in *ListZonesRequest, opts ...grpc.CallOption) (*Zones, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Zones)
+	err := c.cc.Invoke(ctx, CrumbDB_ListZones_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CrumbDBServer is the server API for CrumbDB service.
+// All implementations must embed UnimplementedCrumbDBServer
+// for forward compatibility.
+type CrumbDBServer interface {
+	// Create a crumb. An authenticated caller only creates its own crumbs, the user of a crumb defaulting to the caller
+	Create(context.Context, *Crumb) (*Id, error)
+	// Read, streamed as newline delimited JSON or server-sent events over REST. Only the crumbs visible to the
+	// caller identified by its client certificate are returned. Locked crumbs of other users are returned without
+	// their message, which only UnlockCrumb reveals
+	GetCrumbs(*Point, grpc.ServerStreamingServer[Crumb]) error
+	// Read a crumb by id. Fails with NotFound if the caller cannot see it
+	GetCrumb(context.Context, *Id) (*Crumb, error)
+	// Read up to 100 crumbs by id, in the order of the ids. Fails with NotFound if any is missing or hidden from
+	// the caller
+	BatchGetCrumbs(context.Context, *Ids) (*Crumbs, error)
+	// Read the crumbs of a user the caller can see, newest first, a page at a time. A page may hold fewer crumbs
+	// than its size when some are hidden
+	ListCrumbsByUser(context.Context, *ListCrumbsByUserRequest) (*ListCrumbsByUserResponse, error)
+	// Update the fields of a crumb in the update mask and return it. Only its owner updates a crumb, other callers
+	// get PermissionDenied, or NotFound when they cannot see it. The version must be the current version of the
+	// crumb, otherwise it fails with Aborted, or with FailedPrecondition if it is missing
+	Update(context.Context, *UpdateCrumbRequest) (*Crumb, error)
+	// Delete, removing the crumb from the trails containing it. Only its owner deletes a crumb, other callers get
+	// PermissionDenied, or NotFound when they cannot see it
+	Delete(context.Context, *Id) (*Id, error)
+	// Create a trail of existing crumbs of its user and return it. An authenticated caller only creates its own
+	// trails. Crumbs of other users fail with NotFound, like missing crumbs
+	CreateTrail(context.Context, *Trail) (*Trail, error)
+	// Append crumbs to a trail and return it. Only the owner of the trail appends to it, and only its own crumbs.
+	// A non zero version must be the current version of the trail, otherwise it fails with Aborted
+	AppendToTrail(context.Context, *AppendToTrailRequest) (*Trail, error)
+	// Read a trail by id. The crumbs of other users hidden from the caller are left out of it and of its path
+	GetTrail(context.Context, *Id) (*Trail, error)
+	// Read the trails whose path, made of the crumbs the caller can see, passes within the radius of a Point or
+	// through a Polygon
+	FindTrailsNear(context.Context, *FindTrailsNearRequest) (*Trails, error)
+	// Export the crumbs and trails of a user, or the crumbs near a location, as a GeoJSON FeatureCollection, GPX
+	// or KML document streamed in chunks. Only the crumbs the caller can see are exported, and the trails go through
+	// those crumbs only
+	Export(*ExportRequest, grpc.ServerStreamingServer[ExportChunk]) error
+	// Import crumbs from GeoJSON features or GPX waypoints, a record per request or per line of newline
+	// delimited JSON over REST, and report which were accepted and why the others were rejected. Fails with
+	// PermissionDenied if the caller imports the crumbs of another user
+	ImportCrumbs(grpc.ClientStreamingServer[ImportCrumbsRequest, ImportReport]) error
+	// Read the message of a locked crumb from a position within its unlock radius and record the unlock. Fails
+	// with FailedPrecondition if the position is too far or rejected as spoofed
+	UnlockCrumb(context.Context, *UnlockCrumbRequest) (*UnlockCrumbResponse, error)
+	// Create a zone, or replace the zone with its id, and return it. Only operators can manage zones. Create and
+	// Update fail with FailedPrecondition, naming the rule id, when a crumb would be in a zone forbidding crumbs or
+	// holding its most crumbs
+	PutZone(context.Context, *Zone) (*Zone, error)
+	// Delete the zone with id
+	DeleteZone(context.Context, *Id) (*Id, error)
+	// Read the zones
+	ListZones(context.Context, *ListZonesRequest) (*Zones, error)
+	mustEmbedUnimplementedCrumbDBServer()
+}
+
+// UnimplementedCrumbDBServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCrumbDBServer struct{}
+
+func (UnimplementedCrumbDBServer) Create(context.Context, *Crumb) (*Id, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedCrumbDBServer) GetCrumbs(*Point, grpc.ServerStreamingServer[Crumb]) error {
+	return status.Errorf(codes.Unimplemented, "method GetCrumbs not implemented")
+}
+func (UnimplementedCrumbDBServer) GetCrumb(context.Context, *Id) (*Crumb, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCrumb not implemented")
+}
+func (UnimplementedCrumbDBServer) BatchGetCrumbs(context.Context, *Ids) (*Crumbs, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetCrumbs not implemented")
+}
+func (UnimplementedCrumbDBServer) ListCrumbsByUser(context.Context, *ListCrumbsByUserRequest) (*ListCrumbsByUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCrumbsByUser not implemented")
+}
+func (UnimplementedCrumbDBServer) Update(context.Context, *UpdateCrumbRequest) (*Crumb, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedCrumbDBServer) Delete(context.Context, *Id) (*Id, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedCrumbDBServer) CreateTrail(context.Context, *Trail) (*Trail, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTrail not implemented")
+}
+func (UnimplementedCrumbDBServer) AppendToTrail(context.Context, *AppendToTrailRequest) (*Trail, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AppendToTrail not implemented")
+}
+func (UnimplementedCrumbDBServer) GetTrail(context.Context, *Id) (*Trail, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTrail not implemented")
+}
+func (UnimplementedCrumbDBServer) FindTrailsNear(context.Context, *FindTrailsNearRequest) (*Trails, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindTrailsNear not implemented")
+}
+func (UnimplementedCrumbDBServer) Export(*ExportRequest, grpc.ServerStreamingServer[ExportChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
+func (UnimplementedCrumbDBServer) ImportCrumbs(grpc.ClientStreamingServer[ImportCrumbsRequest, ImportReport]) error {
+	return status.Errorf(codes.Unimplemented, "method ImportCrumbs not implemented")
+}
+func (UnimplementedCrumbDBServer) UnlockCrumb(context.Context, *UnlockCrumbRequest) (*UnlockCrumbResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnlockCrumb not implemented")
+}
+func (UnimplementedCrumbDBServer) PutZone(context.Context, *Zone) (*Zone, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutZone not implemented")
+}
+func (UnimplementedCrumbDBServer) DeleteZone(context.Context, *Id) (*Id, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteZone not implemented")
+}
+func (UnimplementedCrumbDBServer) ListZones(context.Context, *ListZonesRequest) (*Zones, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListZones not implemented")
+}
+func (UnimplementedCrumbDBServer) mustEmbedUnimplementedCrumbDBServer() {}
+func (UnimplementedCrumbDBServer) testEmbeddedByValue()                 {}
+
+// UnsafeCrumbDBServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CrumbDBServer will
+// result in compilation errors.
+type UnsafeCrumbDBServer interface {
+	mustEmbedUnimplementedCrumbDBServer()
+}
+
+func RegisterCrumbDBServer(s grpc.ServiceRegistrar, srv CrumbDBServer) {
+	// If the following call pancis, it indicates UnimplementedCrumbDBServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CrumbDB_ServiceDesc, srv)
+}
+
+func _CrumbDB_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Crumb)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrumbDBServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrumbDB_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrumbDBServer).Create(ctx, req.(*Crumb))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrumbDB_GetCrumbs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Point)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CrumbDBServer).GetCrumbs(m, &grpc.GenericServerStream[Point, Crumb]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CrumbDB_GetCrumbsServer = grpc.ServerStreamingServer[Crumb]
+
+func _CrumbDB_GetCrumb_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Id)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrumbDBServer).GetCrumb(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrumbDB_GetCrumb_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrumbDBServer).GetCrumb(ctx, req.(*Id))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrumbDB_BatchGetCrumbs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Ids)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrumbDBServer).BatchGetCrumbs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrumbDB_BatchGetCrumbs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrumbDBServer).BatchGetCrumbs(ctx, req.(*Ids))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrumbDB_ListCrumbsByUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCrumbsByUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrumbDBServer).ListCrumbsByUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrumbDB_ListCrumbsByUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrumbDBServer).ListCrumbsByUser(ctx, req.(*ListCrumbsByUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrumbDB_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCrumbRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrumbDBServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrumbDB_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrumbDBServer).Update(ctx, req.(*UpdateCrumbRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrumbDB_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Id)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrumbDBServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrumbDB_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrumbDBServer).Delete(ctx, req.(*Id))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrumbDB_CreateTrail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Trail)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrumbDBServer).CreateTrail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrumbDB_CreateTrail_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrumbDBServer).CreateTrail(ctx, req.(*Trail))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrumbDB_AppendToTrail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AppendToTrailRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrumbDBServer).AppendToTrail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrumbDB_AppendToTrail_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrumbDBServer).AppendToTrail(ctx, req.(*AppendToTrailRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrumbDB_GetTrail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Id)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrumbDBServer).GetTrail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrumbDB_GetTrail_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrumbDBServer).GetTrail(ctx, req.(*Id))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrumbDB_FindTrailsNear_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindTrailsNearRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrumbDBServer).FindTrailsNear(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrumbDB_FindTrailsNear_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrumbDBServer).FindTrailsNear(ctx, req.(*FindTrailsNearRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrumbDB_Export_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CrumbDBServer).Export(m, &grpc.GenericServerStream[ExportRequest, ExportChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CrumbDB_ExportServer = grpc.ServerStreamingServer[ExportChunk]
+
+func _CrumbDB_ImportCrumbs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CrumbDBServer).ImportCrumbs(&grpc.GenericServerStream[ImportCrumbsRequest, ImportReport]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CrumbDB_ImportCrumbsServer = grpc.ClientStreamingServer[ImportCrumbsRequest, ImportReport]
+
+func _CrumbDB_UnlockCrumb_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnlockCrumbRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrumbDBServer).UnlockCrumb(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrumbDB_UnlockCrumb_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrumbDBServer).UnlockCrumb(ctx, req.(*UnlockCrumbRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrumbDB_PutZone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Zone)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrumbDBServer).PutZone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrumbDB_PutZone_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrumbDBServer).PutZone(ctx, req.(*Zone))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrumbDB_DeleteZone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Id)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrumbDBServer).DeleteZone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrumbDB_DeleteZone_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrumbDBServer).DeleteZone(ctx, req.(*Id))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrumbDB_ListZones_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListZonesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrumbDBServer).ListZones(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrumbDB_ListZones_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrumbDBServer).ListZones(ctx, req.(*ListZonesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CrumbDB_ServiceDesc is the grpc.ServiceDesc for CrumbDB service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CrumbDB_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "crumbdb.CrumbDB",
+	HandlerType: (*CrumbDBServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _CrumbDB_Create_Handler,
+		},
+		{
+			MethodName: "GetCrumb",
+			Handler:    _CrumbDB_GetCrumb_Handler,
+		},
+		{
+			MethodName: "BatchGetCrumbs",
+			Handler:    _CrumbDB_BatchGetCrumbs_Handler,
+		},
+		{
+			MethodName: "ListCrumbsByUser",
+			Handler:    _CrumbDB_ListCrumbsByUser_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _CrumbDB_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _CrumbDB_Delete_Handler,
+		},
+		{
+			MethodName: "CreateTrail",
+			Handler:    _CrumbDB_CreateTrail_Handler,
+		},
+		{
+			MethodName: "AppendToTrail",
+			Handler:    _CrumbDB_AppendToTrail_Handler,
+		},
+		{
+			MethodName: "GetTrail",
+			Handler:    _CrumbDB_GetTrail_Handler,
+		},
+		{
+			MethodName: "FindTrailsNear",
+			Handler:    _CrumbDB_FindTrailsNear_Handler,
+		},
+		{
+			MethodName: "UnlockCrumb",
+			Handler:    _CrumbDB_UnlockCrumb_Handler,
+		},
+		{
+			MethodName: "PutZone",
+			Handler:    _CrumbDB_PutZone_Handler,
+		},
+		{
+			MethodName: "DeleteZone",
+			Handler:    _CrumbDB_DeleteZone_Handler,
+		},
+		{
+			MethodName: "ListZones",
+			Handler:    _CrumbDB_ListZones_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetCrumbs",
+			Handler:       _CrumbDB_GetCrumbs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Export",
+			Handler:       _CrumbDB_Export_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportCrumbs",
+			Handler:       _CrumbDB_ImportCrumbs_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "routegrpc.proto",
+}
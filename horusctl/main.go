@@ -0,0 +1,14 @@
+package main
+
+import (
+	"os"
+
+	"github.com/haguru/horus/horusctl/cmd"
+)
+
+func main() {
+	err := cmd.NewRootCommand().Execute()
+	if err != nil {
+		os.Exit(1)
+	}
+}
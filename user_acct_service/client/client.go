@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/haguru/horus/useracctdb/internal/routes/protos"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	SERVICE_NAME    = "useracct_service"
+	DEFAULT_ADDRESS = "localhost:50053"
+
+	AUTHORIZATION_HEADER = "authorization"
+	BEARER_PREFIX        = "Bearer "
+)
+
+// SERVICE_CONFIG balances calls across the resolved instances and retries the idempotent methods while the
+// service is unavailable. Create is never retried since a retry after a lost response fails on the existing user
+const SERVICE_CONFIG = `{
+	"loadBalancingConfig": [{"round_robin": {}}],
+	"methodConfig": [{
+		"name": [
+			{"service": "useracctdb.UserAcctDB", "method": "GetUser"},
+			{"service": "useracctdb.UserAcctDB", "method": "UpdatePassword"},
+			{"service": "useracctdb.UserAcctDB", "method": "Delete"}
+		],
+		"retryPolicy": {
+			"maxAttempts": 4,
+			"initialBackoff": "0.1s",
+			"maxBackoff": "1s",
+			"backoffMultiplier": 2,
+			"retryableStatusCodes": ["UNAVAILABLE"]
+		}
+	}]
+}`
+
+// User, UserRequest and PasswordRequest are the messages of the UserAcctDB API
+type (
+	User            = pb.User
+	UserRequest     = pb.UserRequest
+	PasswordRequest = pb.PasswordRequest
+
+	UserAcctDBClient = pb.UserAcctDBClient
+)
+
+// Client calls the UserAcctDB API of the useracct service
+type Client struct {
+	conn *grpc.ClientConn
+	api  pb.UserAcctDBClient
+}
+
+// New returns a Client connected to DEFAULT_ADDRESS unless configured otherwise by opts
+func New(opts ...Option) (*Client, error) {
+	o := &options{address: DEFAULT_ADDRESS}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	target := o.address
+	dialOptions := []grpc.DialOption{grpc.WithDefaultServiceConfig(SERVICE_CONFIG)}
+
+	if o.consul != "" {
+		builder, err := newConsulBuilder(o.consul)
+		if err != nil {
+			return nil, err
+		}
+		target = CONSUL_SCHEME + ":///" + SERVICE_NAME
+		dialOptions = append(dialOptions, grpc.WithResolvers(builder))
+	}
+
+	creds := o.creds
+	if creds == nil {
+		creds = insecure.NewCredentials()
+	}
+	dialOptions = append(dialOptions, grpc.WithTransportCredentials(creds))
+
+	if o.tokenSource != nil {
+		requireTLS := creds.Info().SecurityProtocol != insecure.NewCredentials().Info().SecurityProtocol
+		dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(&tokenCredentials{source: o.tokenSource, requireTLS: requireTLS}))
+	}
+
+	conn, err := grpc.NewClient(target, append(dialOptions, o.dialOptions...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %v: %v", SERVICE_NAME, err)
+	}
+
+	return &Client{
+		conn: conn,
+		api:  pb.NewUserAcctDBClient(conn),
+	}, nil
+}
+
+// Close closes the connection of the Client
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// API returns the generated client for calls not wrapped by Client
+func (c *Client) API() UserAcctDBClient {
+	return c.api
+}
+
+// Create signs up user and returns its id
+func (c *Client) Create(ctx context.Context, user *User) (string, error) {
+	id, err := c.api.Create(ctx, user)
+	if err != nil {
+		return "", err
+	}
+	return id.GetValue(), nil
+}
+
+// GetUser returns the user with email
+func (c *Client) GetUser(ctx context.Context, email string) (*User, error) {
+	return c.api.GetUser(ctx, &UserRequest{Email: email})
+}
+
+// UpdatePassword changes the password of the user with email
+func (c *Client) UpdatePassword(ctx context.Context, email, password string) error {
+	_, err := c.api.UpdatePassword(ctx, &PasswordRequest{Email: email, Password: password})
+	return err
+}
+
+// Delete removes the user with email
+func (c *Client) Delete(ctx context.Context, email string) error {
+	_, err := c.api.Delete(ctx, &UserRequest{Email: email})
+	return err
+}
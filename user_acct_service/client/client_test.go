@@ -0,0 +1,220 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/haguru/horus/useracctdb/config"
+	"github.com/haguru/horus/useracctdb/internal/routes"
+	pb "github.com/haguru/horus/useracctdb/internal/routes/protos"
+	"github.com/haguru/horus/useracctdb/pkg/interfaces/mocks"
+	appMetrics "github.com/haguru/horus/useracctdb/pkg/prometheus"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testServer hosts a real Route on a bufconn listener. The first call of every method in unavailable fails with
+// UNAVAILABLE and the authorization metadata of every call is recorded
+type testServer struct {
+	mu            sync.Mutex
+	unavailable   map[string]bool
+	attempts      map[string]int
+	authorization []string
+}
+
+func (s *testServer) intercept(ctx context.Context, method string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.authorization = append(s.authorization, md.Get(AUTHORIZATION_HEADER)...)
+
+	s.attempts[method]++
+	if s.unavailable[method] && s.attempts[method] == 1 {
+		return status.Error(codes.Unavailable, "unavailable")
+	}
+	return nil
+}
+
+func (s *testServer) calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts[method]
+}
+
+func newTestClient(t *testing.T, dbClient *mocks.DbClient, unavailable []string, opts ...Option) (*Client, *testServer) {
+	t.Helper()
+
+	ts := &testServer{unavailable: map[string]bool{}, attempts: map[string]int{}}
+	for _, method := range unavailable {
+		ts.unavailable[method] = true
+	}
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := ts.intercept(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := ts.intercept(ss.Context(), info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)
+	route := routes.NewRoute(logger.NewMockClient(), &config.Database{DatabaseName: "test", Collection: "test"}, dbClient,
+		appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"}), validator.New())
+	pb.RegisterUserAcctDBServer(server, route)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	opts = append([]Option{
+		WithAddress("passthrough:///bufnet"),
+		WithDialOptions(grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) })),
+	}, opts...)
+	c, err := New(opts...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	return c, ts
+}
+
+func TestClient(t *testing.T) {
+	email := "user@example.com"
+	doc := bson.D{{Key: "_id", Value: "42"}, {Key: "email", Value: email}, {Key: "username", Value: "user_1"}}
+
+	tests := []struct {
+		name        string
+		unavailable []string
+		setup       func(dbClient *mocks.DbClient)
+		call        func(t *testing.T, c *Client) error
+		wantErr     bool
+		wantCode    codes.Code
+		wantCalls   map[string]int
+	}{
+		{
+			name: "create",
+			setup: func(dbClient *mocks.DbClient) {
+				dbClient.On("DocumentExist", mock.Anything, "test", "test", map[string]interface{}{"email": email}).Return(false, nil)
+				dbClient.On("Create", mock.Anything, "test", "test", mock.Anything).Return("42", nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				id, err := c.Create(context.Background(), &User{Email: email, Username: "user_1", Password: "0123456789"})
+				if id != "42" {
+					t.Errorf("Create() = %v, want 42", id)
+				}
+				return err
+			},
+		},
+		{
+			name:        "create is not retried",
+			unavailable: []string{"/useracctdb.UserAcctDB/Create"},
+			setup:       func(dbClient *mocks.DbClient) {},
+			call: func(t *testing.T, c *Client) error {
+				_, err := c.Create(context.Background(), &User{Email: email, Username: "user_1", Password: "0123456789"})
+				return err
+			},
+			wantErr:   true,
+			wantCode:  codes.Unavailable,
+			wantCalls: map[string]int{"/useracctdb.UserAcctDB/Create": 1},
+		},
+		{
+			name:        "get user is retried",
+			unavailable: []string{"/useracctdb.UserAcctDB/GetUser"},
+			setup: func(dbClient *mocks.DbClient) {
+				dbClient.On("Get", mock.Anything, "test", "test", map[string]interface{}{"email": email}).Return(doc, nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				user, err := c.GetUser(context.Background(), email)
+				if user.GetId() != "42" || user.GetUsername() != "user_1" {
+					t.Errorf("GetUser() = %v, want id 42 and username user_1", user)
+				}
+				return err
+			},
+			wantCalls: map[string]int{"/useracctdb.UserAcctDB/GetUser": 2},
+		},
+		{
+			name:  "get user with invalid email",
+			setup: func(dbClient *mocks.DbClient) {},
+			call: func(t *testing.T, c *Client) error {
+				_, err := c.GetUser(context.Background(), "user")
+				return err
+			},
+			wantErr:  true,
+			wantCode: codes.Unknown,
+		},
+		{
+			name:        "update password is retried",
+			unavailable: []string{"/useracctdb.UserAcctDB/UpdatePassword"},
+			setup: func(dbClient *mocks.DbClient) {
+				dbClient.On("Update", mock.Anything, "test", "test", map[string]interface{}{"email": email}, "set",
+					map[string]interface{}{"password": "0123456789"}).Return(nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				return c.UpdatePassword(context.Background(), email, "0123456789")
+			},
+			wantCalls: map[string]int{"/useracctdb.UserAcctDB/UpdatePassword": 2},
+		},
+		{
+			name:        "delete is retried",
+			unavailable: []string{"/useracctdb.UserAcctDB/Delete"},
+			setup: func(dbClient *mocks.DbClient) {
+				dbClient.On("Delete", mock.Anything, "test", "test", map[string]interface{}{"email": email}).Return(nil)
+			},
+			call: func(t *testing.T, c *Client) error {
+				return c.Delete(context.Background(), email)
+			},
+			wantCalls: map[string]int{"/useracctdb.UserAcctDB/Delete": 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbClient := mocks.NewDbClient(t)
+			tt.setup(dbClient)
+			c, ts := newTestClient(t, dbClient, tt.unavailable)
+
+			err := tt.call(t, c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && status.Code(err) != tt.wantCode {
+				t.Errorf("code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+			for method, want := range tt.wantCalls {
+				if got := ts.calls(method); got != want {
+					t.Errorf("%v called %v times, want %v", method, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithToken(t *testing.T) {
+	dbClient := mocks.NewDbClient(t)
+	dbClient.On("Delete", mock.Anything, "test", "test", map[string]interface{}{"email": "user@example.com"}).Return(nil)
+	c, ts := newTestClient(t, dbClient, nil, WithToken("secret"))
+
+	if err := c.Delete(context.Background(), "user@example.com"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if len(ts.authorization) != 1 || ts.authorization[0] != BEARER_PREFIX+"secret" {
+		t.Errorf("authorization = %v, want [%vsecret]", ts.authorization, BEARER_PREFIX)
+	}
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/haguru/horus/useracctdb/config"
+	"github.com/haguru/horus/useracctdb/internal/routes"
+	pb "github.com/haguru/horus/useracctdb/internal/routes/protos"
+	"github.com/haguru/horus/useracctdb/pkg/interfaces/mocks"
+	appMetrics "github.com/haguru/horus/useracctdb/pkg/prometheus"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+)
+
+// newFakeConsul returns the address of a Consul agent that reports addr as the only healthy instance of
+// SERVICE_NAME. Blocking queries wait until the client gives up
+func newFakeConsul(t *testing.T, addr string) string {
+	t.Helper()
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("failed to split %v: %v", addr, err)
+	}
+	entries := []map[string]any{{
+		"Node":    map[string]any{"Address": "127.0.0.2"},
+		"Service": map[string]any{"Service": SERVICE_NAME, "Address": host, "Port": json.Number(port)},
+	}}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/health/service/"+SERVICE_NAME {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("index") != "" {
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("X-Consul-Index", "7")
+		_ = json.NewEncoder(w).Encode(entries)
+	}))
+	t.Cleanup(server.Close)
+
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func TestWithConsul(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	dbClient := mocks.NewDbClient(t)
+	dbClient.On("Delete", mock.Anything, "test", "test", map[string]interface{}{"email": "user@example.com"}).Return(nil)
+	server := grpc.NewServer()
+	pb.RegisterUserAcctDBServer(server, routes.NewRoute(logger.NewMockClient(), &config.Database{DatabaseName: "test", Collection: "test"},
+		dbClient, appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"}), validator.New()))
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	c, err := New(WithConsul(newFakeConsul(t, lis.Addr().String())))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer c.Close()
+
+	if err := c.Delete(context.Background(), "user@example.com"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+}
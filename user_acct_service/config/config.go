@@ -2,26 +2,46 @@ package config
 
 import (
 	"os"
+	"reflect"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 const (
 	CONFIG_PATH = "./res/config.yaml"
+
+	DRIVER_MONGODB = "mongodb"
+	DRIVER_MEMORY  = "memory"
+	DRIVER_BBOLT   = "bbolt"
+
+	SETTING_LOG_LEVEL     = "loglevel"
+	SETTING_PING_INTERVAL = "database.ping_interval"
 )
 
+// RELOADABLE_SETTINGS are the settings that can be changed while the service is running
+var RELOADABLE_SETTINGS = []string{SETTING_LOG_LEVEL, SETTING_PING_INTERVAL}
+
 type ServiceConfig struct {
-	ServiceName string   `yaml:"service_name" validate:"required"`
-	Consul      Consul   `yaml:"consul" validate:"required"`
-	LogLevel    string   `yaml:"loglevel" validate:"required"`
-	Port        int      `yaml:"port" validate:"required"`
-	Database    Database `yaml:"database" validate:"required"`
-	Metrics     Metrics  `yaml:"metrics" validate:"required"`
+	ServiceName string    `yaml:"service_name" validate:"required"`
+	Consul      Consul    `yaml:"consul" validate:"required"`
+	LogLevel    string    `yaml:"loglevel" validate:"required"`
+	LogFormat   string    `yaml:"log_format" validate:"omitempty,oneof=logfmt json"`
+	Port        int       `yaml:"port" validate:"required"`
+	Database    Database  `yaml:"database" validate:"required"`
+	Metrics     Metrics   `yaml:"metrics" validate:"required"`
+	TLS         TLS       `yaml:"tls"`
+	Tracing     Tracing   `yaml:"tracing"`
+	Admin       Admin     `yaml:"admin"`
+	RateLimit   RateLimit `yaml:"rate_limit"`
+	Gateway     Gateway   `yaml:"gateway"`
 }
 
 type Database struct {
-	Host         string        `yaml:"host" validate:"required"`
-	Port         int           `yaml:"port" validate:"required"`
+	Driver       string        `yaml:"driver" validate:"omitempty,oneof=mongodb memory bbolt"`
+	Host         string        `yaml:"host" validate:"required_unless=Driver memory Driver bbolt"`
+	Port         int           `yaml:"port" validate:"required_unless=Driver memory Driver bbolt"`
+	Path         string        `yaml:"path" validate:"required_if=Driver bbolt"`
 	DatabaseName string        `yaml:"database_name" validate:"required"`
 	Timeout      string        `yaml:"timeout" validate:"required"`
 	PingInterval string        `yaml:"ping_interval" validate:"required"`
@@ -34,8 +54,61 @@ type Metrics struct {
 }
 
 type Consul struct {
-	Host string `yaml:"host" validate:"required"`
-	Port int    `yaml:"port" validate:"required"`
+	Host     string `yaml:"host" validate:"required"`
+	Port     int    `yaml:"port" validate:"required"`
+	KVPrefix string `yaml:"kv_prefix"`
+}
+
+// TLS configures transport security for the gRPC server and the metrics endpoint
+type TLS struct {
+	Enabled      bool   `yaml:"enabled"`
+	CertFile     string `yaml:"cert_file"`
+	KeyFile      string `yaml:"key_file"`
+	ClientCAFile string `yaml:"client_ca_file"`
+	MinVersion   string `yaml:"min_version" validate:"omitempty,oneof=1.0 1.1 1.2 1.3"`
+	MutualTLS    bool   `yaml:"mutual_tls"`
+}
+
+// Tracing configures the OpenTelemetry span exporter
+type Tracing struct {
+	Enabled     bool              `yaml:"enabled"`
+	Exporter    string            `yaml:"exporter" validate:"omitempty,oneof=otlpgrpc otlphttp"`
+	Endpoint    string            `yaml:"endpoint"`
+	Insecure    bool              `yaml:"insecure"`
+	SampleRatio float64           `yaml:"sample_ratio" validate:"gte=0,lte=1"`
+	Attributes  map[string]string `yaml:"attributes,omitempty"`
+}
+
+// Admin configures the diagnostic endpoints served on the metrics port. Callers must present the bearer
+// token stored in TokenFile or a client certificate whose SPIFFE ID or common name is listed in Identities
+type Admin struct {
+	Pprof      bool     `yaml:"pprof"`
+	Channelz   bool     `yaml:"channelz"`
+	TokenFile  string   `yaml:"token_file"`
+	Identities []string `yaml:"identities,omitempty"`
+}
+
+// Gateway configures the REST/JSON gateway served in process that proxies to the gRPC server
+type Gateway struct {
+	Enabled bool `yaml:"enabled"`
+	Port    int  `yaml:"port" validate:"required_with=Enabled"`
+}
+
+// RateLimit configures the token buckets applied per caller, the authenticated identity or else the peer IP,
+// and method. Methods without an entry in Methods use Default. Backend "mongodb" shares the buckets across replicas
+type RateLimit struct {
+	Enabled    bool             `yaml:"enabled"`
+	Backend    string           `yaml:"backend" validate:"omitempty,oneof=memory mongodb"`
+	Collection string           `yaml:"collection"`
+	Default    Limit            `yaml:"default"`
+	Methods    map[string]Limit `yaml:"methods,omitempty" validate:"dive"`
+}
+
+// Limit is a token bucket refilled at Rate requests per second that holds at most Burst requests.
+// A zero Rate disables the limit
+type Limit struct {
+	Rate  float64 `yaml:"rate" validate:"gte=0"`
+	Burst int     `yaml:"burst" validate:"gte=0"`
 }
 
 type ServerOptions struct {
@@ -58,3 +131,61 @@ func ReadLocalConfig(configPath string) (*ServiceConfig, error) {
 
 	return config, nil
 }
+
+// MergeYAML returns a copy of the config with the values found in data applied on top of it.
+// Settings missing from data keep their current value
+func (c *ServiceConfig) MergeYAML(data []byte) (*ServiceConfig, error) {
+	// round trip through yaml so that maps are copied rather than shared with c
+	current, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := &ServiceConfig{}
+	err = yaml.Unmarshal(current, merged)
+	if err != nil {
+		return nil, err
+	}
+
+	err = yaml.Unmarshal(data, merged)
+	if err != nil {
+		return nil, err
+	}
+
+	return merged, nil
+}
+
+// Changed returns the yaml path of every setting that differs between c and next, e.g. "database.host"
+func (c *ServiceConfig) Changed(next *ServiceConfig) []string {
+	var changed []string
+	diffFields("", reflect.ValueOf(*c), reflect.ValueOf(*next), &changed)
+	return changed
+}
+
+// IsReloadable returns true if setting can be applied without restarting the service
+func IsReloadable(setting string) bool {
+	for _, reloadable := range RELOADABLE_SETTINGS {
+		if setting == reloadable {
+			return true
+		}
+	}
+	return false
+}
+
+func diffFields(prefix string, current reflect.Value, next reflect.Value, changed *[]string) {
+	for i := 0; i < current.NumField(); i++ {
+		name := strings.Split(current.Type().Field(i).Tag.Get("yaml"), ",")[0]
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		if current.Field(i).Kind() == reflect.Struct {
+			diffFields(name, current.Field(i), next.Field(i), changed)
+			continue
+		}
+
+		if !reflect.DeepEqual(current.Field(i).Interface(), next.Field(i).Interface()) {
+			*changed = append(*changed, name)
+		}
+	}
+}
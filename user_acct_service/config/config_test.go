@@ -3,6 +3,8 @@ package config
 import (
 	"reflect"
 	"testing"
+
+	"github.com/go-playground/validator/v10"
 )
 
 func TestReadLocalConfig(t *testing.T) {
@@ -21,18 +23,67 @@ func TestReadLocalConfig(t *testing.T) {
 				configPath: "../res/config.yaml",
 			},
 			want: &ServiceConfig{
-				ServiceName: "crumbdb",
-				Port:        50051,
-				LogLevel:    "DEBUG",
+				ServiceName: "useracct_service",
+				Consul: Consul{
+					Host:     "consul",
+					Port:     8500,
+					KVPrefix: "horus/config",
+				},
+				Port:      50053,
+				LogLevel:  "DEBUG",
+				LogFormat: "logfmt",
 				Database: Database{
-					Host:         "localhost",
+					Driver:       "mongodb",
+					Host:         "useracctdb",
 					Port:         27017,
+					Path:         "./data/useracct.db",
 					DatabaseName: "horus",
-					Collection:   "crumbs",
+					Collection:   "users",
 					Options: ServerOptions{
 						SetStrict:            true,
 						SetDeprecationErrors: true,
 					},
+					PingInterval: "5s",
+					Timeout:      "5s",
+				},
+				Metrics: Metrics{
+					Port: 52112,
+				},
+				TLS: TLS{
+					Enabled:      false,
+					CertFile:     "./res/tls/server.crt",
+					KeyFile:      "./res/tls/server.key",
+					ClientCAFile: "./res/tls/ca.crt",
+					MinVersion:   "1.2",
+					MutualTLS:    false,
+				},
+				Tracing: Tracing{
+					Enabled:     false,
+					Exporter:    "otlpgrpc",
+					Endpoint:    "otel-collector:4317",
+					Insecure:    true,
+					SampleRatio: 1,
+					Attributes: map[string]string{
+						"deployment.environment": "development",
+					},
+				},
+				Admin: Admin{
+					Pprof:     false,
+					Channelz:  false,
+					TokenFile: "./res/admin/token",
+				},
+				Gateway: Gateway{
+					Enabled: true,
+					Port:    8053,
+				},
+				RateLimit: RateLimit{
+					Enabled:    true,
+					Backend:    "memory",
+					Collection: "rate_limits",
+					Default:    Limit{Rate: 50, Burst: 100},
+					Methods: map[string]Limit{
+						"/useracctdb.UserAcctDB/Create": {Rate: 1, Burst: 10},
+					},
 				},
 			},
 			wantErr: false,
@@ -59,3 +110,175 @@ func TestReadLocalConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceConfig_MergeYAML(t *testing.T) {
+	base := &ServiceConfig{
+		ServiceName: "useracct_service",
+		LogLevel:    "DEBUG",
+		Database: Database{
+			Host:         "useracctdb",
+			PingInterval: "5s",
+		},
+		Tracing: Tracing{
+			Attributes: map[string]string{"team": "geo"},
+		},
+	}
+	tests := []struct {
+		name    string
+		data    []byte
+		want    *ServiceConfig
+		wantErr bool
+	}{
+		{
+			name: "overrides nested setting",
+			data: []byte("loglevel: INFO\ndatabase:\n  ping_interval: 10s\n"),
+			want: &ServiceConfig{
+				ServiceName: "useracct_service",
+				LogLevel:    "INFO",
+				Database: Database{
+					Host:         "useracctdb",
+					PingInterval: "10s",
+				},
+				Tracing: Tracing{
+					Attributes: map[string]string{"team": "geo"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "adds map entries without sharing the map",
+			data: []byte("tracing:\n  attributes:\n    region: eu\n"),
+			want: &ServiceConfig{
+				ServiceName: "useracct_service",
+				LogLevel:    "DEBUG",
+				Database: Database{
+					Host:         "useracctdb",
+					PingInterval: "5s",
+				},
+				Tracing: Tracing{
+					Attributes: map[string]string{"team": "geo", "region": "eu"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "invalid yaml",
+			data:    []byte("loglevel: [INFO"),
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := base.MergeYAML(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ServiceConfig.MergeYAML() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ServiceConfig.MergeYAML() = %v, want %v", got, tt.want)
+			}
+			if base.LogLevel != "DEBUG" || len(base.Tracing.Attributes) != 1 {
+				t.Errorf("ServiceConfig.MergeYAML() modified the original config")
+			}
+		})
+	}
+}
+
+func TestServiceConfig_Changed(t *testing.T) {
+	tests := []struct {
+		name string
+		next func(c ServiceConfig) ServiceConfig
+		want []string
+	}{
+		{
+			name: "nothing changed",
+			next: func(c ServiceConfig) ServiceConfig { return c },
+			want: nil,
+		},
+		{
+			name: "reloadable and restart settings changed",
+			next: func(c ServiceConfig) ServiceConfig {
+				c.LogLevel = "INFO"
+				c.Database.Host = "otherdb"
+				c.Database.PingInterval = "10s"
+				return c
+			},
+			want: []string{SETTING_LOG_LEVEL, "database.host", SETTING_PING_INTERVAL},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := ServiceConfig{LogLevel: "DEBUG", Database: Database{Host: "useracctdb"}}
+			next := tt.next(current)
+			if got := current.Changed(&next); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ServiceConfig.Changed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsReloadable(t *testing.T) {
+	tests := []struct {
+		setting string
+		want    bool
+	}{
+		{setting: SETTING_LOG_LEVEL, want: true},
+		{setting: SETTING_PING_INTERVAL, want: true},
+		{setting: "database.host", want: false},
+		{setting: "port", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.setting, func(t *testing.T) {
+			if got := IsReloadable(tt.setting); got != tt.want {
+				t.Errorf("IsReloadable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDatabase_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		database Database
+		wantErr  bool
+	}{
+		{
+			name:     "mongodb requires host and port",
+			database: Database{Driver: DRIVER_MONGODB, DatabaseName: "horus", Collection: "test", PingInterval: "5s", Timeout: "5s"},
+			wantErr:  true,
+		},
+		{
+			name:     "default driver requires host and port",
+			database: Database{DatabaseName: "horus", Collection: "test", PingInterval: "5s", Timeout: "5s"},
+			wantErr:  true,
+		},
+		{
+			name:     "memory needs no host and port",
+			database: Database{Driver: DRIVER_MEMORY, DatabaseName: "horus", Collection: "test", PingInterval: "5s", Timeout: "5s"},
+			wantErr:  false,
+		},
+		{
+			name:     "bbolt needs a path instead of host and port",
+			database: Database{Driver: DRIVER_BBOLT, Path: "./data/horus.db", DatabaseName: "horus", Collection: "test", PingInterval: "5s", Timeout: "5s"},
+			wantErr:  false,
+		},
+		{
+			name:     "bbolt requires a path",
+			database: Database{Driver: DRIVER_BBOLT, DatabaseName: "horus", Collection: "test", PingInterval: "5s", Timeout: "5s"},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown driver",
+			database: Database{Driver: "redis", Host: "redis", Port: 6379, DatabaseName: "horus", Collection: "test", PingInterval: "5s", Timeout: "5s"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validator.New().Struct(tt.database); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
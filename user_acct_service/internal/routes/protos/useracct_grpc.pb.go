@@ -29,9 +29,13 @@ const (
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type UserAcctDBClient interface {
+	// Create
 	Create(ctx context.Context, in *User, opts ...grpc.CallOption) (*Id, error)
+	// Read
 	GetUser(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*User, error)
+	// Update
 	UpdatePassword(ctx context.Context, in *PasswordRequest, opts ...grpc.CallOption) (*Status, error)
+	// Delete
 	Delete(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*Status, error)
 }
 
@@ -87,9 +91,13 @@ func (c *userAcctDBClient) Delete(ctx context.Context, in *UserRequest, opts ...
 // All implementations must embed UnimplementedUserAcctDBServer
 // for forward compatibility.
 type UserAcctDBServer interface {
+	// Create
 	Create(context.Context, *User) (*Id, error)
+	// Read
 	GetUser(context.Context, *UserRequest) (*User, error)
+	// Update
 	UpdatePassword(context.Context, *PasswordRequest) (*Status, error)
+	// Delete
 	Delete(context.Context, *UserRequest) (*Status, error)
 	mustEmbedUnimplementedUserAcctDBServer()
 }
@@ -12,6 +12,8 @@ import (
 	"github.com/haguru/horus/useracctdb/config"
 	pb "github.com/haguru/horus/useracctdb/internal/routes/protos"
 	"github.com/haguru/horus/useracctdb/pkg/interfaces"
+	appLogging "github.com/haguru/horus/useracctdb/pkg/logging"
+	appMetrics "github.com/haguru/horus/useracctdb/pkg/prometheus"
 )
 
 const (
@@ -24,21 +26,25 @@ type Route struct {
 	dbConfig  *config.Database
 	dbClient  interfaces.DbClient
 	lc        logger.LoggingClient
+	metrics   *appMetrics.Metrics
 	validator *validator.Validate
 
 	pb.UnimplementedUserAcctDBServer
 }
 
-func NewRoute(lc logger.LoggingClient, config *config.Database, dbclient interfaces.DbClient, validator *validator.Validate) *Route {
+func NewRoute(lc logger.LoggingClient, config *config.Database, dbclient interfaces.DbClient, metrics *appMetrics.Metrics, validator *validator.Validate) *Route {
 	return &Route{
 		dbConfig:  config,
 		dbClient:  dbclient,
 		lc:        lc,
+		metrics:   metrics,
 		validator: validator,
 	}
 }
 
 func (r *Route) Create(ctx context.Context, user *pb.User) (*pb.Id, error) {
+	appLogging.FromContext(ctx, r.lc).Debug("received Create request", appLogging.Redact(user)...)
+
 	// Validate the User struct
 	err := r.validator.Struct(user)
 	if err != nil {
@@ -50,7 +56,7 @@ func (r *Route) Create(ctx context.Context, user *pb.User) (*pb.Id, error) {
 
 	// Verify user does not exist
 	filterParams := map[string]interface{}{"email": user.GetEmail()}
-	exist, err := r.dbClient.DocumentExist(r.dbConfig.DatabaseName, r.dbConfig.Collection, filterParams)
+	exist, err := r.dbClient.DocumentExist(ctx, r.dbConfig.DatabaseName, r.dbConfig.Collection, filterParams)
 	if err != nil {
 		return nil, err
 	}
@@ -60,15 +66,18 @@ func (r *Route) Create(ctx context.Context, user *pb.User) (*pb.Id, error) {
 	}
 
 	id := &pb.Id{}
-	id.Value, err = r.dbClient.Create(r.dbConfig.DatabaseName, r.dbConfig.Collection, user)
+	id.Value, err = r.dbClient.Create(ctx, r.dbConfig.DatabaseName, r.dbConfig.Collection, user)
 	if err != nil {
 		return nil, fmt.Errorf("database failed to create user: %v", err)
 	}
+	appMetrics.IncWithExemplar(ctx, r.metrics.Signups)
 
 	return id, nil
 }
 
 func (r *Route) GetUser(ctx context.Context, userReq *pb.UserRequest) (*pb.User, error) {
+	appLogging.FromContext(ctx, r.lc).Debug("received GetUser request", appLogging.Redact(userReq)...)
+
 	// Validate the UserRequest struct
 	err := r.validator.Struct(userReq)
 	if err != nil {
@@ -79,7 +88,7 @@ func (r *Route) GetUser(ctx context.Context, userReq *pb.UserRequest) (*pb.User,
 
 	user := &pb.User{}
 	filterParams := map[string]interface{}{"email": userReq.GetEmail()}
-	res, err := r.dbClient.Get(r.dbConfig.DatabaseName, r.dbConfig.Collection, filterParams)
+	res, err := r.dbClient.Get(ctx, r.dbConfig.DatabaseName, r.dbConfig.Collection, filterParams)
 	if err != nil {
 		return nil, fmt.Errorf("database failed to retrieve user data: %v", err)
 	}
@@ -93,6 +102,8 @@ func (r *Route) GetUser(ctx context.Context, userReq *pb.UserRequest) (*pb.User,
 }
 
 func (r *Route) UpdatePassword(ctx context.Context, passwdReq *pb.PasswordRequest) (*pb.Status, error) {
+	appLogging.FromContext(ctx, r.lc).Debug("received UpdatePassword request", appLogging.Redact(passwdReq)...)
+
 	status := &pb.Status{}
 	// Validate the UserRequest struct
 	err := r.validator.Struct(passwdReq)
@@ -105,7 +116,7 @@ func (r *Route) UpdatePassword(ctx context.Context, passwdReq *pb.PasswordReques
 
 	filterParams := map[string]interface{}{"email": passwdReq.Email}
 	updateItem := map[string]interface{}{"password": passwdReq.Password}
-	err = r.dbClient.Update(r.dbConfig.DatabaseName, r.dbConfig.Collection, filterParams, UPDATE_OPERATOR, updateItem)
+	err = r.dbClient.Update(ctx, r.dbConfig.DatabaseName, r.dbConfig.Collection, filterParams, UPDATE_OPERATOR, updateItem)
 	if err != nil {
 		status.Value = http.StatusInternalServerError
 		return status, fmt.Errorf("database failed to update password: %v", err)
@@ -116,6 +127,8 @@ func (r *Route) UpdatePassword(ctx context.Context, passwdReq *pb.PasswordReques
 }
 
 func (r *Route) Delete(ctx context.Context, userReq *pb.UserRequest) (*pb.Status, error) {
+	appLogging.FromContext(ctx, r.lc).Debug("received Delete request", appLogging.Redact(userReq)...)
+
 	status := &pb.Status{}
 	// Validate the UserRequest struct
 	err := r.validator.Struct(userReq)
@@ -126,7 +139,7 @@ func (r *Route) Delete(ctx context.Context, userReq *pb.UserRequest) (*pb.Status
 		return status, fmt.Errorf("validation error: %s", errors)
 	}
 	filterParams := map[string]interface{}{"email": userReq.GetEmail()}
-	err = r.dbClient.Delete(r.dbConfig.DatabaseName, r.dbConfig.Collection, filterParams)
+	err = r.dbClient.Delete(ctx, r.dbConfig.DatabaseName, r.dbConfig.Collection, filterParams)
 	if err != nil {
 		status.Value = http.StatusInternalServerError
 		return status, fmt.Errorf("database failed to delete user: %v", err)
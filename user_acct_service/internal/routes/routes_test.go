@@ -12,6 +12,8 @@ import (
 	"github.com/haguru/horus/useracctdb/config"
 	pb "github.com/haguru/horus/useracctdb/internal/routes/protos"
 	"github.com/haguru/horus/useracctdb/pkg/interfaces/mocks"
+	appMetrics "github.com/haguru/horus/useracctdb/pkg/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -191,15 +193,19 @@ func TestRoute_Create(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient := mocks.NewDbClient(t)
-			mockClient.On("Create", mock.Anything, mock.Anything, tt.args.user).Return(tt.createClientIDRtn, tt.createClientErrRtn).Maybe()
-			mockClient.On("DocumentExist", mock.Anything, mock.Anything, mock.Anything).Return(tt.existClientRtn, tt.existClientErrRtn).Maybe()
+			mockClient.On("Create", mock.Anything, mock.Anything, mock.Anything, tt.args.user).Return(tt.createClientIDRtn, tt.createClientErrRtn).Maybe()
+			mockClient.On("DocumentExist", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.existClientRtn, tt.existClientErrRtn).Maybe()
 			r := &Route{
 				dbConfig:  tt.fields.dbConfig,
 				dbClient:  mockClient,
 				lc:        tt.fields.lc,
+				metrics:   appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"}),
 				validator: validator.New(),
 			}
 			got, err := r.Create(tt.args.ctx, tt.args.user)
+			if want := successCount(tt.wantErr); testutil.ToFloat64(r.metrics.Signups) != want {
+				t.Errorf("Signups = %v, want %v", testutil.ToFloat64(r.metrics.Signups), want)
+			}
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Route.Create() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -303,11 +309,12 @@ func TestRoute_GetUser(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient := mocks.NewDbClient(t)
-			mockClient.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(tt.dbUserRtn, tt.dbCLientRtn).Maybe()
+			mockClient.On("Get", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.dbUserRtn, tt.dbCLientRtn).Maybe()
 			r := &Route{
 				dbConfig:  tt.fields.dbCconfig,
 				dbClient:  mockClient,
 				lc:        tt.fields.lc,
+				metrics:   appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"}),
 				validator: validator.New(),
 			}
 			got, err := r.GetUser(tt.args.ctx, tt.args.userReq)
@@ -431,11 +438,12 @@ func TestRoute_UpdatePassword(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient := mocks.NewDbClient(t)
-			mockClient.On("Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.dbClientRtn).Maybe()
+			mockClient.On("Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.dbClientRtn).Maybe()
 			r := &Route{
 				dbConfig:  tt.fields.dbCconfig,
 				dbClient:  mockClient,
 				lc:        tt.fields.lc,
+				metrics:   appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"}),
 				validator: validator.New(),
 			}
 			got, err := r.UpdatePassword(tt.args.ctx, tt.args.passwdReq)
@@ -534,11 +542,12 @@ func TestRoute_Delete(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient := mocks.NewDbClient(t)
-			mockClient.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(tt.dbCLientRtn).Maybe()
+			mockClient.On("Delete", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.dbCLientRtn).Maybe()
 			r := &Route{
 				dbConfig:  tt.fields.dbCconfig,
 				dbClient:  mockClient,
 				lc:        tt.fields.lc,
+				metrics:   appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"}),
 				validator: validator.New(),
 			}
 			got, err := r.Delete(tt.args.ctx, tt.args.userReq)
@@ -552,3 +561,11 @@ func TestRoute_Delete(t *testing.T) {
 		})
 	}
 }
+
+// successCount is the expected value of a counter incremented once by a successful call
+func successCount(wantErr bool) float64 {
+	if wantErr {
+		return 0
+	}
+	return 1
+}
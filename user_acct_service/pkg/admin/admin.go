@@ -0,0 +1,214 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/haguru/horus/useracctdb/config"
+	"github.com/haguru/horus/useracctdb/pkg/security"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"google.golang.org/grpc"
+	channelzgrpc "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	channelzservice "google.golang.org/grpc/channelz/service"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	PPROF_ENDPOINT    = "/debug/pprof/"
+	CHANNELZ_ENDPOINT = "/debug/channelz"
+
+	BEARER_PREFIX = "Bearer "
+
+	// query parameters of CHANNELZ_ENDPOINT selecting a single server or socket instead of the overview
+	PARAM_SERVER_ID = "server_id"
+	PARAM_SOCKET_ID = "socket_id"
+)
+
+// Admin serves the pprof and channelz diagnostic endpoints to authorized callers
+type Admin struct {
+	config   *config.Admin
+	lc       logger.LoggingClient
+	token    []byte
+	channelz channelzgrpc.ChannelzServer
+}
+
+// NewAdmin returns an Admin and error if no way to authorize callers is configured or the token cannot be read
+func NewAdmin(config *config.Admin, lc logger.LoggingClient) (*Admin, error) {
+	if config.TokenFile == "" && len(config.Identities) == 0 {
+		return nil, fmt.Errorf("token_file or identities is required when admin endpoints are enabled")
+	}
+
+	a := &Admin{
+		config: config,
+		lc:     lc,
+	}
+
+	if config.TokenFile != "" {
+		token, err := os.ReadFile(config.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read admin token: %v", err)
+		}
+		a.token = bytes.TrimSpace(token)
+		if len(a.token) == 0 {
+			return nil, fmt.Errorf("admin token file %v is empty", config.TokenFile)
+		}
+	}
+
+	if config.Channelz {
+		registrar := &channelzRegistrar{}
+		channelzservice.RegisterChannelzServiceToServer(registrar)
+		a.channelz = registrar.server
+	}
+
+	return a, nil
+}
+
+// Register adds the enabled endpoints to mux
+func (a *Admin) Register(mux *http.ServeMux) {
+	if a.config.Pprof {
+		mux.Handle(PPROF_ENDPOINT, a.authorize(http.HandlerFunc(pprof.Index)))
+		mux.Handle(PPROF_ENDPOINT+"cmdline", a.authorize(http.HandlerFunc(pprof.Cmdline)))
+		mux.Handle(PPROF_ENDPOINT+"profile", a.authorize(http.HandlerFunc(pprof.Profile)))
+		mux.Handle(PPROF_ENDPOINT+"symbol", a.authorize(http.HandlerFunc(pprof.Symbol)))
+		mux.Handle(PPROF_ENDPOINT+"trace", a.authorize(http.HandlerFunc(pprof.Trace)))
+	}
+
+	if a.config.Channelz {
+		mux.Handle(CHANNELZ_ENDPOINT, a.authorize(http.HandlerFunc(a.serveChannelz)))
+	}
+}
+
+func (a *Admin) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.authorized(r) {
+			a.lc.Warn("rejected admin request", "path", r.URL.Path, "peer", r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authorized returns true if the request carries a client certificate listed in Identities or the admin token
+func (a *Admin) authorized(r *http.Request) bool {
+	if identity, ok := security.IdentityFromTLS(r.TLS); ok {
+		for _, allowed := range a.config.Identities {
+			if allowed != "" && (allowed == identity.SpiffeID || allowed == identity.Subject) {
+				return true
+			}
+		}
+	}
+
+	if len(a.token) == 0 {
+		return false
+	}
+
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), BEARER_PREFIX)
+	return found && subtle.ConstantTimeCompare([]byte(token), a.token) == 1
+}
+
+// serveChannelz writes the live gRPC servers and client channels of the process as JSON. A single server and
+// its sockets, or a single socket with its stream and message counts, is returned when selected by id
+func (a *Admin) serveChannelz(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	var dump map[string]proto.Message
+	var err error
+	switch {
+	case query.Has(PARAM_SOCKET_ID):
+		dump, err = a.socket(ctx, query.Get(PARAM_SOCKET_ID))
+	case query.Has(PARAM_SERVER_ID):
+		dump, err = a.server(ctx, query.Get(PARAM_SERVER_ID))
+	default:
+		dump, err = a.overview(ctx)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	body := map[string]json.RawMessage{}
+	for key, msg := range dump {
+		body[key], err = protojson.Marshal(msg)
+		if err != nil {
+			a.lc.Errorf("failed to marshal channelz %v: %v", key, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		a.lc.Errorf("failed to write channelz response: %v", err)
+	}
+}
+
+func (a *Admin) overview(ctx context.Context) (map[string]proto.Message, error) {
+	servers, err := a.channelz.GetServers(ctx, &channelzgrpc.GetServersRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get servers: %v", err)
+	}
+
+	channels, err := a.channelz.GetTopChannels(ctx, &channelzgrpc.GetTopChannelsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channels: %v", err)
+	}
+
+	return map[string]proto.Message{"servers": servers, "top_channels": channels}, nil
+}
+
+func (a *Admin) server(ctx context.Context, value string) (map[string]proto.Message, error) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %v: %v", PARAM_SERVER_ID, value)
+	}
+
+	server, err := a.channelz.GetServer(ctx, &channelzgrpc.GetServerRequest{ServerId: id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server %v: %v", id, err)
+	}
+
+	sockets, err := a.channelz.GetServerSockets(ctx, &channelzgrpc.GetServerSocketsRequest{ServerId: id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sockets of server %v: %v", id, err)
+	}
+
+	return map[string]proto.Message{"server": server, "sockets": sockets}, nil
+}
+
+func (a *Admin) socket(ctx context.Context, value string) (map[string]proto.Message, error) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %v: %v", PARAM_SOCKET_ID, value)
+	}
+
+	socket, err := a.channelz.GetSocket(ctx, &channelzgrpc.GetSocketRequest{SocketId: id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get socket %v: %v", id, err)
+	}
+
+	return map[string]proto.Message{"socket": socket}, nil
+}
+
+// channelzRegistrar captures the channelz service implementation so it can be queried in process
+// instead of being exposed on the public gRPC server
+type channelzRegistrar struct {
+	server channelzgrpc.ChannelzServer
+}
+
+func (c *channelzRegistrar) RegisterService(_ *grpc.ServiceDesc, impl any) {
+	c.server = impl.(channelzgrpc.ChannelzServer)
+}
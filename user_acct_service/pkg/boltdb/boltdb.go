@@ -0,0 +1,297 @@
+package boltdb
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/haguru/horus/useracctdb/pkg/document"
+	"github.com/haguru/horus/useracctdb/pkg/interfaces"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	FILE_MODE = 0600
+	DIR_MODE  = 0700
+
+	DOCUMENTS_BUCKET = "documents"
+	IDS_BUCKET       = "ids"
+)
+
+// BoltDB is an implementation of interfaces.DbClient storing documents in a single bbolt file for single-node
+// deployments. Every collection is a bucket holding the BSON documents, keyed by insertion sequence, and an
+// index of their ids. Filters match like the in-memory database, see document.Matches
+type BoltDB struct {
+	Path    string
+	DB      *bbolt.DB
+	timeout time.Duration
+	lc      logger.LoggingClient
+}
+
+// NewBoltDB opens the database file at path. Returns a interface for db client and error if it occurs
+func NewBoltDB(path string, lc logger.LoggingClient, timeout time.Duration) (interfaces.DbClient, error) {
+	db := &BoltDB{
+		Path:    path,
+		timeout: timeout,
+		lc:      lc,
+	}
+	err := db.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Connect opens the database file, creating it and its directory if needed. Fails if another process holds
+// the file for longer than the timeout
+func (db *BoltDB) Connect() error {
+	if err := os.MkdirAll(filepath.Dir(db.Path), DIR_MODE); err != nil {
+		return fmt.Errorf("failed to create database directory: %v", err)
+	}
+
+	db.lc.Debugf("opening database: %v", db.Path)
+	var err error
+	db.DB, err = bbolt.Open(db.Path, FILE_MODE, &bbolt.Options{Timeout: db.timeout})
+	if err != nil {
+		return fmt.Errorf("failed to open database %v: %v", db.Path, err)
+	}
+
+	db.lc.Debugf("successfully opened database: %v", db.Path)
+	return nil
+}
+
+// Ping returns error if the database file is closed
+func (db *BoltDB) Ping() error {
+	return db.DB.View(func(*bbolt.Tx) error { return nil })
+}
+
+// Disconnect closes the database file
+func (db *BoltDB) Disconnect(context.Context) error {
+	return db.DB.Close()
+}
+
+// Create stores doc and returns its ID
+func (db *BoltDB) Create(_ context.Context, databaseName string, collectionName string, doc interface{}) (string, error) {
+	record, err := document.ToDocument(doc)
+	if err != nil {
+		return "", err
+	}
+
+	// like mongodb, a missing _id is generated and stored as the first field
+	var objId primitive.ObjectID
+	if value, found := document.Lookup(record, document.IDFIELD); found {
+		var ok bool
+		objId, ok = value.(primitive.ObjectID)
+		if !ok {
+			return "", fmt.Errorf("failed to get objectID")
+		}
+	} else {
+		objId = primitive.NewObjectID()
+		record = append(bson.D{{Key: document.IDFIELD, Value: objId}}, record...)
+	}
+
+	data, err := bson.Marshal(record)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal document: %v", err)
+	}
+
+	err = db.DB.Update(func(tx *bbolt.Tx) error {
+		docs, ids, err := createCollection(tx, databaseName, collectionName)
+		if err != nil {
+			return err
+		}
+		if ids.Get(objId[:]) != nil {
+			return fmt.Errorf("duplicate key error: %v", objId.Hex())
+		}
+
+		seq, err := docs.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := binary.BigEndian.AppendUint64(nil, seq)
+		if err := docs.Put(key, data); err != nil {
+			return err
+		}
+		return ids.Put(objId[:], key)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return objId.Hex(), nil
+}
+
+// Get retrieves the first document matching filterParams. Returns mongo.ErrNoDocuments if there is none
+func (db *BoltDB) Get(_ context.Context, databaseName string, collectionName string, filterParams map[string]interface{}) (interface{}, error) {
+	filter, err := document.ToDocument(filterParams)
+	if err != nil {
+		return nil, err
+	}
+
+	var data bson.D
+	err = db.DB.View(func(tx *bbolt.Tx) error {
+		_, doc, err := find(tx, databaseName, collectionName, filter)
+		data = doc
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &data, nil
+}
+
+// Update applies updateOperator with items to the first document matching filterParams
+func (db *BoltDB) Update(_ context.Context, databaseName string, collectionName string, filterParams map[string]interface{}, updateOperator string, items map[string]interface{}) error {
+	filter, err := document.ToDocument(filterParams)
+	if err != nil {
+		return err
+	}
+	update, err := document.ToDocument(items)
+	if err != nil {
+		return err
+	}
+	if _, ok := document.OPERATORS[updateOperator]; !ok {
+		return fmt.Errorf("failed to create update command")
+	}
+
+	return db.DB.Update(func(tx *bbolt.Tx) error {
+		key, doc, err := find(tx, databaseName, collectionName, filter)
+		if err != nil {
+			return err
+		}
+		if doc == nil {
+			return fmt.Errorf("document not found")
+		}
+
+		updated, err := document.ApplyUpdate(doc, updateOperator, update)
+		if err != nil {
+			return err
+		}
+		data, err := bson.Marshal(updated)
+		if err != nil {
+			return fmt.Errorf("failed to marshal document: %v", err)
+		}
+		docs, _ := collection(tx, databaseName, collectionName)
+		return docs.Put(key, data)
+	})
+}
+
+// Delete removes the first document matching filterParams
+func (db *BoltDB) Delete(_ context.Context, databaseName string, collectionName string, filterParams map[string]interface{}) error {
+	filter, err := document.ToDocument(filterParams)
+	if err != nil {
+		return err
+	}
+
+	return db.DB.Update(func(tx *bbolt.Tx) error {
+		key, doc, err := find(tx, databaseName, collectionName, filter)
+		if err != nil {
+			return err
+		}
+		if doc == nil {
+			return fmt.Errorf("document not found")
+		}
+
+		docs, ids := collection(tx, databaseName, collectionName)
+		// every stored document has an ObjectID, see Create
+		id, _ := document.Lookup(doc, document.IDFIELD)
+		objId := id.(primitive.ObjectID)
+		if err := ids.Delete(objId[:]); err != nil {
+			return err
+		}
+		return docs.Delete(key)
+	})
+}
+
+// DocumentExist returns true if a document matches filterParams
+func (db *BoltDB) DocumentExist(_ context.Context, databaseName string, collectionName string, filterParams map[string]interface{}) (bool, error) {
+	filter, err := document.ToDocument(filterParams)
+	if err != nil {
+		return false, err
+	}
+
+	var exist bool
+	err = db.DB.View(func(tx *bbolt.Tx) error {
+		_, doc, err := find(tx, databaseName, collectionName, filter)
+		exist = doc != nil
+		return err
+	})
+
+	return exist, err
+}
+
+// find returns the key and the first document of the collection matching filter, a nil document if there is none
+func find(tx *bbolt.Tx, databaseName string, collectionName string, filter bson.D) ([]byte, bson.D, error) {
+	var key []byte
+	var found bson.D
+	err := scan(tx, databaseName, collectionName, filter, func(k []byte, doc bson.D) bool {
+		key, found = k, doc
+		return false
+	})
+	return key, found, err
+}
+
+// scan calls fn with the documents of the collection matching filter in insertion order until fn returns false
+func scan(tx *bbolt.Tx, databaseName string, collectionName string, filter bson.D, fn func(key []byte, doc bson.D) bool) error {
+	docs, _ := collection(tx, databaseName, collectionName)
+	if docs == nil {
+		return nil
+	}
+
+	c := docs.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		var doc bson.D
+		if err := bson.Unmarshal(v, &doc); err != nil {
+			return fmt.Errorf("failed to unmarshal document: %v", err)
+		}
+		if !document.Matches(doc, filter) {
+			continue
+		}
+		if !fn(k, doc) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// collection returns the document and id buckets of the collection, nil if it was never written to
+func collection(tx *bbolt.Tx, databaseName string, collectionName string) (*bbolt.Bucket, *bbolt.Bucket) {
+	bucket := tx.Bucket([]byte(namespace(databaseName, collectionName)))
+	if bucket == nil {
+		return nil, nil
+	}
+	return bucket.Bucket([]byte(DOCUMENTS_BUCKET)), bucket.Bucket([]byte(IDS_BUCKET))
+}
+
+// createCollection returns the buckets of the collection, creating them like mongodb does on first use
+func createCollection(tx *bbolt.Tx, databaseName string, collectionName string) (*bbolt.Bucket, *bbolt.Bucket, error) {
+	bucket, err := tx.CreateBucketIfNotExists([]byte(namespace(databaseName, collectionName)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create collection: %v", err)
+	}
+	docs, err := bucket.CreateBucketIfNotExists([]byte(DOCUMENTS_BUCKET))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create collection: %v", err)
+	}
+	ids, err := bucket.CreateBucketIfNotExists([]byte(IDS_BUCKET))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create collection: %v", err)
+	}
+	return docs, ids, nil
+}
+
+func namespace(databaseName string, collectionName string) string {
+	return databaseName + "." + collectionName
+}
@@ -1,8 +1,10 @@
 package consul
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/haguru/horus/useracctdb/config"
 	consulapi "github.com/hashicorp/consul/api"
@@ -12,6 +14,9 @@ const (
 	CHECK_INTERVAL   = "5s"
 	CHECK_TIMEOUT    = "30s"
 	DEREGISTER_AFTER = "10s"
+
+	KV_WAIT_TIME      = 5 * time.Minute
+	KV_RETRY_INTERVAL = 5 * time.Second
 )
 
 type Consul struct {
@@ -37,7 +42,9 @@ func NewConsul(config config.Consul) (*Consul, error) {
 	}, nil
 }
 
-func (c *Consul) RegisterService(serviceName string, port int) error {
+// RegisterService registers the service and its gRPC health check with Consul.
+// useTLS must be true when the gRPC server only accepts TLS connections
+func (c *Consul) RegisterService(serviceName string, port int, useTLS bool) error {
 	address, err := os.Hostname()
 	if err != nil {
 		return err
@@ -51,6 +58,7 @@ func (c *Consul) RegisterService(serviceName string, port int) error {
 
 		Check: &consulapi.AgentServiceCheck{
 			GRPC:                           fmt.Sprintf("%v:%v/%v", address, port, serviceName),
+			GRPCUseTLS:                     useTLS,
 			Interval:                       CHECK_INTERVAL,
 			Timeout:                        CHECK_TIMEOUT,
 			DeregisterCriticalServiceAfter: DEREGISTER_AFTER,
@@ -64,3 +72,39 @@ func (c *Consul) RegisterService(serviceName string, port int) error {
 
 	return nil
 }
+
+// WatchKey calls onChange with the value stored under key in the KV store every time it changes.
+// onChange receives nil when the key does not exist. WatchKey blocks until ctx is done
+func (c *Consul) WatchKey(ctx context.Context, key string, onChange func([]byte)) error {
+	var waitIndex uint64
+	for {
+		opts := (&consulapi.QueryOptions{WaitIndex: waitIndex, WaitTime: KV_WAIT_TIME}).WithContext(ctx)
+		pair, meta, err := c.client.KV().Get(key, opts)
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(KV_RETRY_INTERVAL):
+				continue
+			}
+		}
+
+		// the blocking query timed out without any change
+		if meta.LastIndex == waitIndex {
+			continue
+		}
+
+		// the index went backwards, start over as recommended by Consul
+		if meta.LastIndex < waitIndex {
+			waitIndex = 0
+			continue
+		}
+		waitIndex = meta.LastIndex
+
+		if pair == nil {
+			onChange(nil)
+			continue
+		}
+		onChange(pair.Value)
+	}
+}
@@ -0,0 +1,148 @@
+package document
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const IDFIELD = "_id"
+
+// Matches returns true if every field of filter equals the field at the same path of doc. Like mongodb, a nil
+// value also matches missing fields and a value matches arrays containing it
+func Matches(doc bson.D, filter bson.D) bool {
+	for _, e := range filter {
+		value, found := Get(doc, e.Key)
+		if !found {
+			if e.Value != nil {
+				return false
+			}
+			continue
+		}
+		if Equal(value, e.Value) {
+			continue
+		}
+		if array, ok := value.(bson.A); ok && contains(array, e.Value) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func contains(array bson.A, value interface{}) bool {
+	for _, item := range array {
+		if Equal(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Equal compares values like mongodb, numbers are equal regardless of their type
+func Equal(a, b interface{}) bool {
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			return x == y
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Get returns the value at the dotted path of doc
+func Get(doc bson.D, path string) (interface{}, bool) {
+	key, rest, nested := strings.Cut(path, ".")
+	value, found := Lookup(doc, key)
+	if !found || !nested {
+		return value, found
+	}
+
+	sub, ok := value.(bson.D)
+	if !ok {
+		return nil, false
+	}
+	return Get(sub, rest)
+}
+
+// Set returns doc with value stored at the dotted path, creating the embedded documents on the way
+func Set(doc bson.D, path string, value interface{}) (bson.D, error) {
+	key, rest, nested := strings.Cut(path, ".")
+	i := index(doc, key)
+
+	if !nested {
+		if i < 0 {
+			return append(doc, bson.E{Key: key, Value: value}), nil
+		}
+		doc[i].Value = value
+		return doc, nil
+	}
+
+	sub := bson.D{}
+	if i >= 0 {
+		var ok bool
+		sub, ok = doc[i].Value.(bson.D)
+		if !ok {
+			return nil, fmt.Errorf("cannot create field '%v' in element {%v: %v}", rest, key, doc[i].Value)
+		}
+	}
+	sub, err := Set(sub, rest, value)
+	if err != nil {
+		return nil, err
+	}
+	if i < 0 {
+		return append(doc, bson.E{Key: key, Value: sub}), nil
+	}
+	doc[i].Value = sub
+	return doc, nil
+}
+
+// Unset returns doc without the field at the dotted path
+func Unset(doc bson.D, path string) bson.D {
+	key, rest, nested := strings.Cut(path, ".")
+	i := index(doc, key)
+	if i < 0 {
+		return doc
+	}
+
+	if !nested {
+		return append(doc[:i], doc[i+1:]...)
+	}
+	if sub, ok := doc[i].Value.(bson.D); ok {
+		doc[i].Value = Unset(sub, rest)
+	}
+	return doc
+}
+
+func index(doc bson.D, key string) int {
+	for i, e := range doc {
+		if e.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Lookup returns the value of the top level field key of doc
+func Lookup(doc bson.D, key string) (interface{}, bool) {
+	if i := index(doc, key); i >= 0 {
+		return doc[i].Value, true
+	}
+	return nil, false
+}
+
+// ToDocument converts v to the bson.D mongodb would store for it. The result never shares memory with v
+func ToDocument(v interface{}) (bson.D, error) {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal document: %v", err)
+	}
+
+	var doc bson.D
+	err = bson.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal document: %v", err)
+	}
+	return doc, nil
+}
@@ -0,0 +1,140 @@
+package gateway
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/haguru/horus/useracctdb/internal/routes/protos"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+type userAcctServer struct {
+	pb.UnimplementedUserAcctDBServer
+}
+
+func (s *userAcctServer) Create(_ context.Context, user *pb.User) (*pb.Id, error) {
+	if user.GetUsername() == "flooder" {
+		st, _ := status.New(codes.ResourceExhausted, "rate limit exceeded").
+			WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(1500 * time.Millisecond)})
+		return nil, st.Err()
+	}
+	return &pb.Id{Value: "42"}, nil
+}
+
+func (s *userAcctServer) GetUser(_ context.Context, req *pb.UserRequest) (*pb.User, error) {
+	return &pb.User{Id: "42", Email: req.GetEmail(), Username: "user_1"}, nil
+}
+
+func newTestHandler(t *testing.T) http.Handler {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	pb.RegisterUserAcctDBServer(server, &userAcctServer{})
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	handler, err := NewHandler(context.Background(), conn)
+	if err != nil {
+		t.Fatalf("NewHandler() error = %v", err)
+	}
+	return handler
+}
+
+func TestNewHandler(t *testing.T) {
+	handler := newTestHandler(t)
+
+	tests := []struct {
+		name            string
+		method          string
+		path            string
+		body            string
+		accept          string
+		wantStatus      int
+		wantContentType string
+		wantBody        []string
+		wantRetryAfter  string
+	}{
+		{
+			name:            "unary",
+			method:          http.MethodPost,
+			path:            "/v1/users",
+			body:            `{"email":"user@example.com","username":"user_1","password":"0123456789"}`,
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        []string{`{"value":"42"}`},
+		},
+		{
+			name:            "path parameter",
+			method:          http.MethodGet,
+			path:            "/v1/users/user@example.com",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        []string{`"email":"user@example.com"`},
+		},
+		{
+			name:            "rate limited",
+			method:          http.MethodPost,
+			path:            "/v1/users",
+			body:            `{"username":"flooder"}`,
+			wantStatus:      http.StatusTooManyRequests,
+			wantContentType: "application/json",
+			wantRetryAfter:  "2",
+		},
+		{
+			name:            "openapi document",
+			method:          http.MethodGet,
+			path:            OPENAPI_ENDPOINT,
+			wantStatus:      http.StatusOK,
+			wantContentType: MIME_OPENAPI,
+			wantBody:        []string{"openapi: 3.0.3", "/v1/users/{email}/password:"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %v, want %v: %v", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Content-Type = %v, want %v", got, tt.wantContentType)
+			}
+			if got := rec.Header().Get(HEADER_RETRY_AFTER); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("body %q does not contain %q", rec.Body.String(), want)
+				}
+			}
+		})
+	}
+}
@@ -45,6 +45,11 @@ func (h *HealthCheck) SetStatus(status healthpb.HealthCheckResponse_ServingStatu
 	h.Health.SetServingStatus(h.ServiceConfig.ServiceName, status)
 }
 
+// SetPingInterval changes how often the database is pinged
+func (h *HealthCheck) SetPingInterval(pingInterval time.Duration) {
+	h.ticker.Reset(pingInterval)
+}
+
 func (h *HealthCheck) StartHealthCheckService(client interfaces.DbClient) {
 	for {
 		select {
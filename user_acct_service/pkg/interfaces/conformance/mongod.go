@@ -0,0 +1,82 @@
+package conformance
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const (
+	// MONGODB_ENV is the host:port of the mongod to run the suite against
+	MONGODB_ENV = "HORUS_TEST_MONGODB"
+
+	// MONGOD is the binary started when MONGODB_ENV is unset
+	MONGOD = "mongod"
+
+	// MONGOD_START_TIMEOUT is how long a started mongod has to accept connections
+	MONGOD_START_TIMEOUT = 30 * time.Second
+)
+
+// Mongod returns the host and port of the mongod set in MONGODB_ENV. Otherwise it starts the mongod found on
+// PATH with a temporary database for the duration of the test, and skips the test if there is none
+func Mongod(t *testing.T) (string, int) {
+	t.Helper()
+
+	address := os.Getenv(MONGODB_ENV)
+	if address == "" {
+		address = startMongod(t)
+	}
+
+	host, portString, err := net.SplitHostPort(address)
+	if err != nil {
+		t.Fatalf("invalid %v: %v", MONGODB_ENV, err)
+	}
+	port, err := strconv.Atoi(portString)
+	if err != nil {
+		t.Fatalf("invalid %v: %v", MONGODB_ENV, err)
+	}
+	return host, port
+}
+
+// startMongod starts a mongod on a free local port and returns its address once it accepts connections
+func startMongod(t *testing.T) string {
+	t.Helper()
+
+	binary, err := exec.LookPath(MONGOD)
+	if err != nil {
+		t.Skipf("%v is not set and %v is not installed", MONGODB_ENV, MONGOD)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	address := listener.Addr().String()
+	_, port, _ := net.SplitHostPort(address)
+	listener.Close()
+
+	cmd := exec.Command(binary, "--dbpath", t.TempDir(), "--bind_ip", "127.0.0.1", "--port", port, "--quiet")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start %v: %v", MONGOD, err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	deadline := time.Now().Add(MONGOD_START_TIMEOUT)
+	for {
+		conn, err := net.DialTimeout("tcp", address, time.Second)
+		if err == nil {
+			conn.Close()
+			return address
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%v did not accept connections on %v: %v", MONGOD, address, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
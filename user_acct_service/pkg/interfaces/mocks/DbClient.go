@@ -13,9 +13,9 @@ type DbClient struct {
 	mock.Mock
 }
 
-// Create provides a mock function with given fields: databaseName, collectionName, doc
-func (_m *DbClient) Create(databaseName string, collectionName string, doc interface{}) (string, error) {
-	ret := _m.Called(databaseName, collectionName, doc)
+// Create provides a mock function with given fields: ctx, databaseName, collectionName, doc
+func (_m *DbClient) Create(ctx context.Context, databaseName string, collectionName string, doc interface{}) (string, error) {
+	ret := _m.Called(ctx, databaseName, collectionName, doc)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
@@ -23,17 +23,17 @@ func (_m *DbClient) Create(databaseName string, collectionName string, doc inter
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string, interface{}) (string, error)); ok {
-		return rf(databaseName, collectionName, doc)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, interface{}) (string, error)); ok {
+		return rf(ctx, databaseName, collectionName, doc)
 	}
-	if rf, ok := ret.Get(0).(func(string, string, interface{}) string); ok {
-		r0 = rf(databaseName, collectionName, doc)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, interface{}) string); ok {
+		r0 = rf(ctx, databaseName, collectionName, doc)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, interface{}) error); ok {
-		r1 = rf(databaseName, collectionName, doc)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, interface{}) error); ok {
+		r1 = rf(ctx, databaseName, collectionName, doc)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -41,17 +41,17 @@ func (_m *DbClient) Create(databaseName string, collectionName string, doc inter
 	return r0, r1
 }
 
-// Delete provides a mock function with given fields: databaseName, collectionName, filterParms
-func (_m *DbClient) Delete(databaseName string, collectionName string, filterParms map[string]interface{}) error {
-	ret := _m.Called(databaseName, collectionName, filterParms)
+// Delete provides a mock function with given fields: ctx, databaseName, collectionName, filterParms
+func (_m *DbClient) Delete(ctx context.Context, databaseName string, collectionName string, filterParms map[string]interface{}) error {
+	ret := _m.Called(ctx, databaseName, collectionName, filterParms)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Delete")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, map[string]interface{}) error); ok {
-		r0 = rf(databaseName, collectionName, filterParms)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, map[string]interface{}) error); ok {
+		r0 = rf(ctx, databaseName, collectionName, filterParms)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -77,9 +77,9 @@ func (_m *DbClient) Disconnect(_a0 context.Context) error {
 	return r0
 }
 
-// DocumentExist provides a mock function with given fields: databaseName, collectionName, filterParams
-func (_m *DbClient) DocumentExist(databaseName string, collectionName string, filterParams map[string]interface{}) (bool, error) {
-	ret := _m.Called(databaseName, collectionName, filterParams)
+// DocumentExist provides a mock function with given fields: ctx, databaseName, collectionName, filterParams
+func (_m *DbClient) DocumentExist(ctx context.Context, databaseName string, collectionName string, filterParams map[string]interface{}) (bool, error) {
+	ret := _m.Called(ctx, databaseName, collectionName, filterParams)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DocumentExist")
@@ -87,17 +87,17 @@ func (_m *DbClient) DocumentExist(databaseName string, collectionName string, fi
 
 	var r0 bool
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string, map[string]interface{}) (bool, error)); ok {
-		return rf(databaseName, collectionName, filterParams)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, map[string]interface{}) (bool, error)); ok {
+		return rf(ctx, databaseName, collectionName, filterParams)
 	}
-	if rf, ok := ret.Get(0).(func(string, string, map[string]interface{}) bool); ok {
-		r0 = rf(databaseName, collectionName, filterParams)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, map[string]interface{}) bool); ok {
+		r0 = rf(ctx, databaseName, collectionName, filterParams)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, map[string]interface{}) error); ok {
-		r1 = rf(databaseName, collectionName, filterParams)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, map[string]interface{}) error); ok {
+		r1 = rf(ctx, databaseName, collectionName, filterParams)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -105,9 +105,9 @@ func (_m *DbClient) DocumentExist(databaseName string, collectionName string, fi
 	return r0, r1
 }
 
-// Get provides a mock function with given fields: databaseName, collectionName, filterParams
-func (_m *DbClient) Get(databaseName string, collectionName string, filterParams map[string]interface{}) (interface{}, error) {
-	ret := _m.Called(databaseName, collectionName, filterParams)
+// Get provides a mock function with given fields: ctx, databaseName, collectionName, filterParams
+func (_m *DbClient) Get(ctx context.Context, databaseName string, collectionName string, filterParams map[string]interface{}) (interface{}, error) {
+	ret := _m.Called(ctx, databaseName, collectionName, filterParams)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Get")
@@ -115,19 +115,19 @@ func (_m *DbClient) Get(databaseName string, collectionName string, filterParams
 
 	var r0 interface{}
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string, map[string]interface{}) (interface{}, error)); ok {
-		return rf(databaseName, collectionName, filterParams)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, map[string]interface{}) (interface{}, error)); ok {
+		return rf(ctx, databaseName, collectionName, filterParams)
 	}
-	if rf, ok := ret.Get(0).(func(string, string, map[string]interface{}) interface{}); ok {
-		r0 = rf(databaseName, collectionName, filterParams)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, map[string]interface{}) interface{}); ok {
+		r0 = rf(ctx, databaseName, collectionName, filterParams)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(interface{})
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, map[string]interface{}) error); ok {
-		r1 = rf(databaseName, collectionName, filterParams)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, map[string]interface{}) error); ok {
+		r1 = rf(ctx, databaseName, collectionName, filterParams)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -153,17 +153,17 @@ func (_m *DbClient) Ping() error {
 	return r0
 }
 
-// Update provides a mock function with given fields: databaseName, collectionName, filterParams, updateType, items
-func (_m *DbClient) Update(databaseName string, collectionName string, filterParams map[string]interface{}, updateType string, items map[string]interface{}) error {
-	ret := _m.Called(databaseName, collectionName, filterParams, updateType, items)
+// Update provides a mock function with given fields: ctx, databaseName, collectionName, filterParams, updateType, items
+func (_m *DbClient) Update(ctx context.Context, databaseName string, collectionName string, filterParams map[string]interface{}, updateType string, items map[string]interface{}) error {
+	ret := _m.Called(ctx, databaseName, collectionName, filterParams, updateType, items)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, map[string]interface{}, string, map[string]interface{}) error); ok {
-		r0 = rf(databaseName, collectionName, filterParams, updateType, items)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, map[string]interface{}, string, map[string]interface{}) error); ok {
+		r0 = rf(ctx, databaseName, collectionName, filterParams, updateType, items)
 	} else {
 		r0 = ret.Error(0)
 	}
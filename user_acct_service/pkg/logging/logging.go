@@ -0,0 +1,170 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+
+	"github.com/haguru/horus/useracctdb/pkg/security"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/types"
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/go-kit/kit/log"
+	grpclogging "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	FORMAT_LOGFMT = "logfmt"
+	FORMAT_JSON   = "json"
+
+	// CALLER_DEPTH is the number of frames between the caller of a log method and the go-kit Caller valuer
+	CALLER_DEPTH = 5
+)
+
+var LOG_LEVELS = []string{models.TraceLog, models.DebugLog, models.InfoLog, models.WarnLog, models.ErrorLog}
+
+// Client is a logger.LoggingClient that writes logfmt or JSON lines and carries fields that are added to every entry
+type Client struct {
+	// index in LOG_LEVELS of the lowest level written, shared with the clients of With and set by SetLogLevel
+	// while others log
+	logLevel *atomic.Int32
+	logger   log.Logger
+	fields   []interface{}
+}
+
+// NewClient returns a LoggingClient writing to stdout in the given format. Unknown log levels default to INFO
+// and unknown formats default to logfmt
+func NewClient(serviceName string, logLevel string, format string) *Client {
+	return newClient(os.Stdout, serviceName, logLevel, format)
+}
+
+func newClient(w io.Writer, serviceName string, logLevel string, format string) *Client {
+	index, ok := levelIndex(logLevel)
+	if !ok {
+		index, _ = levelIndex(models.InfoLog)
+	}
+	level := &atomic.Int32{}
+	level.Store(index)
+
+	var root log.Logger
+	switch format {
+	case FORMAT_JSON:
+		root = log.NewJSONLogger(w)
+	default:
+		root = log.NewLogfmtLogger(w)
+	}
+
+	return &Client{
+		logLevel: level,
+		logger:   log.With(root, "ts", log.DefaultTimestampUTC, "app", serviceName, "source", log.Caller(CALLER_DEPTH)),
+	}
+}
+
+// With returns a Client that adds the key value pairs in fields to every entry. The log level is shared with c
+func (c *Client) With(fields ...interface{}) *Client {
+	if len(fields)%2 == 1 {
+		fields = append(fields, "")
+	}
+
+	return &Client{
+		logLevel: c.logLevel,
+		logger:   c.logger,
+		fields:   append(append([]interface{}{}, c.fields...), fields...),
+	}
+}
+
+// FromContext returns lc with the request scoped fields found in ctx. lc is returned unchanged if it is not a *Client
+func FromContext(ctx context.Context, lc logger.LoggingClient) logger.LoggingClient {
+	client, ok := lc.(*Client)
+	if !ok {
+		return lc
+	}
+
+	return client.With(RequestFields(ctx)...)
+}
+
+// RequestFields returns the trace ID, caller identity and peer address of the request in ctx when they are known
+func RequestFields(ctx context.Context) grpclogging.Fields {
+	fields := grpclogging.Fields{}
+	if span := trace.SpanContextFromContext(ctx); span.IsSampled() {
+		fields = append(fields, "traceID", span.TraceID().String())
+	}
+	if identity, ok := security.IdentityFromContext(ctx); ok {
+		caller := identity.SpiffeID
+		if caller == "" {
+			caller = identity.Subject
+		}
+		fields = append(fields, "caller", caller)
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields = append(fields, "peer", p.Addr.String())
+	}
+
+	return fields
+}
+
+func (c *Client) SetLogLevel(logLevel string) error {
+	index, ok := levelIndex(logLevel)
+	if !ok {
+		return types.ErrNotFound{}
+	}
+
+	c.logLevel.Store(index)
+	return nil
+}
+
+func (c *Client) LogLevel() string {
+	return LOG_LEVELS[c.logLevel.Load()]
+}
+
+func (c *Client) Trace(msg string, args ...interface{}) { c.log(models.TraceLog, false, msg, args...) }
+func (c *Client) Debug(msg string, args ...interface{}) { c.log(models.DebugLog, false, msg, args...) }
+func (c *Client) Info(msg string, args ...interface{})  { c.log(models.InfoLog, false, msg, args...) }
+func (c *Client) Warn(msg string, args ...interface{})  { c.log(models.WarnLog, false, msg, args...) }
+func (c *Client) Error(msg string, args ...interface{}) { c.log(models.ErrorLog, false, msg, args...) }
+
+func (c *Client) Tracef(msg string, args ...interface{}) { c.log(models.TraceLog, true, msg, args...) }
+func (c *Client) Debugf(msg string, args ...interface{}) { c.log(models.DebugLog, true, msg, args...) }
+func (c *Client) Infof(msg string, args ...interface{})  { c.log(models.InfoLog, true, msg, args...) }
+func (c *Client) Warnf(msg string, args ...interface{})  { c.log(models.WarnLog, true, msg, args...) }
+func (c *Client) Errorf(msg string, args ...interface{}) { c.log(models.ErrorLog, true, msg, args...) }
+
+func (c *Client) log(logLevel string, formatted bool, msg string, args ...interface{}) {
+	if !c.enabled(logLevel) {
+		return
+	}
+
+	keyvals := append([]interface{}{"level", logLevel}, c.fields...)
+	if formatted {
+		msg = fmt.Sprintf(msg, args...)
+	} else {
+		if len(args)%2 == 1 {
+			args = append(args, "")
+		}
+		keyvals = append(keyvals, args...)
+	}
+	keyvals = append(keyvals, "msg", msg)
+
+	// the logger writes to stdout, there is nowhere left to report a failed write
+	_ = c.logger.Log(keyvals...)
+}
+
+func (c *Client) enabled(logLevel string) bool {
+	index, ok := levelIndex(logLevel)
+	return !ok || index >= c.logLevel.Load()
+}
+
+// levelIndex returns the index of logLevel in LOG_LEVELS, false if it is not a log level
+func levelIndex(logLevel string) (int32, bool) {
+	for i, name := range LOG_LEVELS {
+		if name == logLevel {
+			return int32(i), true
+		}
+	}
+	return 0, false
+}
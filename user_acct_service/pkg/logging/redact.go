@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	REDACTED = "[REDACTED]"
+
+	// COORDINATE_DECIMALS is the precision coordinates are written to the logs with, about a kilometer
+	COORDINATE_DECIMALS = 2
+)
+
+// REDACTED_FIELDS are the message fields whose values are never written to the logs
+var REDACTED_FIELDS = []string{"password", "token", "secret", "message"}
+
+// COARSENED_FIELDS are the message fields holding coordinates, never written to the logs at full precision
+var COARSENED_FIELDS = []string{"coordinates", "bbox"}
+
+// Redact returns the populated fields of msg as key value pairs, e.g. "location.type", "Point".
+// Values of REDACTED_FIELDS are replaced with REDACTED and values of COARSENED_FIELDS are rounded to
+// COORDINATE_DECIMALS
+func Redact(msg proto.Message) []interface{} {
+	if msg == nil {
+		return nil
+	}
+
+	var fields []interface{}
+	redact("", msg.ProtoReflect(), &fields)
+	return fields
+}
+
+func redact(prefix string, msg protoreflect.Message, fields *[]interface{}) {
+	// fields are walked in declaration order so entries are stable
+	fds := msg.Descriptor().Fields()
+	for i := 0; i < fds.Len(); i++ {
+		fd := fds.Get(i)
+		if !msg.Has(fd) {
+			continue
+		}
+		v := msg.Get(fd)
+
+		name := string(fd.Name())
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		switch {
+		case matches(string(fd.Name()), REDACTED_FIELDS):
+			*fields = append(*fields, name, REDACTED)
+		case fd.IsMap():
+			*fields = append(*fields, name, fmt.Sprintf("%d entries", v.Map().Len()))
+		case fd.IsList() && fd.Kind() == protoreflect.MessageKind:
+			*fields = append(*fields, name, fmt.Sprintf("%d items", v.List().Len()))
+		case fd.Kind() == protoreflect.MessageKind:
+			redact(name, v.Message(), fields)
+		case fd.IsList():
+			coarsened := matches(string(fd.Name()), COARSENED_FIELDS)
+			values := make([]string, v.List().Len())
+			for i := range values {
+				value := v.List().Get(i).Interface()
+				if f, ok := value.(float64); ok && coarsened {
+					values[i] = strconv.FormatFloat(f, 'f', COORDINATE_DECIMALS, 64)
+					continue
+				}
+				values[i] = fmt.Sprint(value)
+			}
+			*fields = append(*fields, name, "["+strings.Join(values, " ")+"]")
+		default:
+			*fields = append(*fields, name, v.Interface())
+		}
+	}
+}
+
+func matches(name string, names []string) bool {
+	for _, other := range names {
+		if strings.EqualFold(name, other) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package logging
+
+import (
+	"reflect"
+	"testing"
+
+	pb "github.com/haguru/horus/useracctdb/internal/routes/protos"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  proto.Message
+		want []interface{}
+	}{
+		{
+			name: "nil message",
+			msg:  nil,
+			want: nil,
+		},
+		{
+			name: "user",
+			msg: &pb.User{
+				Id:       "42",
+				Email:    "user@example.com",
+				Username: "user_1",
+				Password: "correct horse battery staple",
+			},
+			want: []interface{}{
+				"id", "42",
+				"email", "user@example.com",
+				"username", "user_1",
+				"password", REDACTED,
+			},
+		},
+		{
+			name: "unset fields are skipped",
+			msg:  &pb.PasswordRequest{Password: "correct horse battery staple"},
+			want: []interface{}{"password", REDACTED},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Redact(tt.msg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Redact() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
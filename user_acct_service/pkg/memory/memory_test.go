@@ -0,0 +1,250 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/haguru/horus/useracctdb/pkg/document"
+	"github.com/haguru/horus/useracctdb/pkg/interfaces"
+	"github.com/haguru/horus/useracctdb/pkg/interfaces/conformance"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	DATABASE   = "horus"
+	COLLECTION = "users"
+)
+
+func newTestMemory(t *testing.T, docs ...interface{}) *Memory {
+	t.Helper()
+
+	db := NewMemory(logger.NewMockClient()).(*Memory)
+	for _, doc := range docs {
+		if _, err := db.Create(context.Background(), DATABASE, COLLECTION, doc); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	return db
+}
+
+func TestMemory_Get(t *testing.T) {
+	db := newTestMemory(t,
+		bson.D{{Key: "email", Value: "a@example.com"}, {Key: "age", Value: int32(30)}, {Key: "tags", Value: bson.A{"admin", "dev"}}},
+		bson.D{{Key: "email", Value: "b@example.com"}, {Key: "profile", Value: bson.D{{Key: "city", Value: "Portland"}}}},
+	)
+
+	tests := []struct {
+		name      string
+		filter    map[string]interface{}
+		wantEmail string
+		wantErr   error
+	}{
+		{name: "equality", filter: map[string]interface{}{"email": "b@example.com"}, wantEmail: "b@example.com"},
+		{name: "numbers of different types", filter: map[string]interface{}{"age": 30.0}, wantEmail: "a@example.com"},
+		{name: "array contains", filter: map[string]interface{}{"tags": "dev"}, wantEmail: "a@example.com"},
+		{name: "dotted path", filter: map[string]interface{}{"profile.city": "Portland"}, wantEmail: "b@example.com"},
+		{name: "nil matches missing fields", filter: map[string]interface{}{"age": nil}, wantEmail: "b@example.com"},
+		{name: "every field must match", filter: map[string]interface{}{"email": "a@example.com", "age": 31}, wantErr: mongo.ErrNoDocuments},
+		{name: "empty filter matches the first document", filter: nil, wantEmail: "a@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := db.Get(context.Background(), DATABASE, COLLECTION, tt.filter)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Get() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if email, _ := document.Lookup(*got.(*bson.D), "email"); email != tt.wantEmail {
+				t.Errorf("Get() email = %v, want %v", email, tt.wantEmail)
+			}
+		})
+	}
+}
+
+func TestMemory_Update(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator string
+		items    map[string]interface{}
+		want     bson.D
+		wantErr  bool
+	}{
+		{
+			name:     "set",
+			operator: document.OPERATOR_SET,
+			items:    map[string]interface{}{"password": "secret", "profile.city": "Salem"},
+			want: bson.D{{Key: "password", Value: "secret"}, {Key: "count", Value: int32(2)}, {Key: "ratio", Value: 1.5},
+				{Key: "profile", Value: bson.D{{Key: "city", Value: "Salem"}}}},
+		},
+		{
+			name:     "unset",
+			operator: document.OPERATOR_UNSET,
+			items:    map[string]interface{}{"password": "", "missing": ""},
+			want:     bson.D{{Key: "count", Value: int32(2)}, {Key: "ratio", Value: 1.5}},
+		},
+		{
+			name:     "inc keeps the widest type",
+			operator: document.OPERATOR_INC,
+			items:    map[string]interface{}{"count": int32(3), "ratio": int32(1), "logins": int64(1)},
+			want: bson.D{{Key: "password", Value: "hash"}, {Key: "count", Value: int32(5)}, {Key: "ratio", Value: 2.5},
+				{Key: "logins", Value: int64(1)}},
+		},
+		{
+			name:     "inc of a string",
+			operator: document.OPERATOR_INC,
+			items:    map[string]interface{}{"password": int32(1)},
+			wantErr:  true,
+		},
+		{
+			name:     "mul sets missing fields to zero",
+			operator: document.OPERATOR_MUL,
+			items:    map[string]interface{}{"count": int32(4), "logins": int32(2)},
+			want: bson.D{{Key: "password", Value: "hash"}, {Key: "count", Value: int32(8)}, {Key: "ratio", Value: 1.5},
+				{Key: "logins", Value: int32(0)}},
+		},
+		{
+			name:     "max and min only move towards the value",
+			operator: document.OPERATOR_MAX,
+			items:    map[string]interface{}{"count": int32(1), "ratio": 2.0},
+			want:     bson.D{{Key: "password", Value: "hash"}, {Key: "count", Value: int32(2)}, {Key: "ratio", Value: 2.0}},
+		},
+		{
+			name:     "min",
+			operator: document.OPERATOR_MIN,
+			items:    map[string]interface{}{"count": int32(1), "ratio": 2.0},
+			want:     bson.D{{Key: "password", Value: "hash"}, {Key: "count", Value: int32(1)}, {Key: "ratio", Value: 1.5}},
+		},
+		{
+			name:     "rename",
+			operator: document.OPERATOR_RENAME,
+			items:    map[string]interface{}{"password": "hash", "missing": "other"},
+			want:     bson.D{{Key: "count", Value: int32(2)}, {Key: "ratio", Value: 1.5}, {Key: "hash", Value: "hash"}},
+		},
+		{
+			name:     "set on insert does not change existing documents",
+			operator: document.OPERATOR_SET_ON_INSERT,
+			items:    map[string]interface{}{"password": "secret"},
+			want:     bson.D{{Key: "password", Value: "hash"}, {Key: "count", Value: int32(2)}, {Key: "ratio", Value: 1.5}},
+		},
+		{
+			name:     "immutable id",
+			operator: document.OPERATOR_SET,
+			items:    map[string]interface{}{document.IDFIELD: "42"},
+			wantErr:  true,
+		},
+		{
+			name:     "unsupported operator",
+			operator: "push",
+			items:    map[string]interface{}{"tags": "dev"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestMemory(t, bson.D{{Key: "email", Value: "a@example.com"}, {Key: "password", Value: "hash"},
+				{Key: "count", Value: int32(2)}, {Key: "ratio", Value: 1.5}})
+			filter := map[string]interface{}{"email": "a@example.com"}
+
+			err := db.Update(context.Background(), DATABASE, COLLECTION, filter, tt.operator, tt.items)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Update() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got, err := db.Get(context.Background(), DATABASE, COLLECTION, filter)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			// drop _id and email to compare the updated fields only
+			doc := (*got.(*bson.D))[2:]
+			if tt.wantErr {
+				tt.want = bson.D{{Key: "password", Value: "hash"}, {Key: "count", Value: int32(2)}, {Key: "ratio", Value: 1.5}}
+			}
+			if !reflect.DeepEqual(doc, tt.want) {
+				t.Errorf("Update() = %v, want %v", doc, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemory_CurrentDate(t *testing.T) {
+	db := newTestMemory(t, bson.D{{Key: "email", Value: "a@example.com"}})
+	filter := map[string]interface{}{"email": "a@example.com"}
+	items := map[string]interface{}{"updated": true, "seen": bson.D{{Key: document.CURRENT_DATE_TYPE, Value: document.CURRENT_DATE_TIMESTAMP}}}
+
+	if err := db.Update(context.Background(), DATABASE, COLLECTION, filter, document.OPERATOR_CURRENT_DATE, items); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	got, _ := db.Get(context.Background(), DATABASE, COLLECTION, filter)
+	if updated, _ := document.Lookup(*got.(*bson.D), "updated"); reflect.TypeOf(updated) != reflect.TypeOf(primitive.DateTime(0)) {
+		t.Errorf("updated = %T, want primitive.DateTime", updated)
+	}
+	if seen, _ := document.Lookup(*got.(*bson.D), "seen"); reflect.TypeOf(seen) != reflect.TypeOf(primitive.Timestamp{}) {
+		t.Errorf("seen = %T, want primitive.Timestamp", seen)
+	}
+}
+
+func TestMemory_Delete(t *testing.T) {
+	db := newTestMemory(t, bson.D{{Key: "email", Value: "a@example.com"}}, bson.D{{Key: "email", Value: "b@example.com"}})
+	ctx := context.Background()
+	filter := map[string]interface{}{"email": "a@example.com"}
+
+	if err := db.Delete(ctx, DATABASE, COLLECTION, filter); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := db.Delete(ctx, DATABASE, COLLECTION, filter); err == nil {
+		t.Errorf("Delete() of a deleted document succeeded")
+	}
+	if err := db.Update(ctx, DATABASE, COLLECTION, filter, document.OPERATOR_SET, map[string]interface{}{"password": "secret"}); err == nil {
+		t.Errorf("Update() of a deleted document succeeded")
+	}
+
+	exist, err := db.DocumentExist(ctx, DATABASE, COLLECTION, filter)
+	if err != nil || exist {
+		t.Errorf("DocumentExist() = %v, %v, want false", exist, err)
+	}
+	exist, err = db.DocumentExist(ctx, DATABASE, COLLECTION, map[string]interface{}{"email": "b@example.com"})
+	if err != nil || !exist {
+		t.Errorf("DocumentExist() = %v, %v, want true", exist, err)
+	}
+}
+
+func TestMemory_Create(t *testing.T) {
+	db := newTestMemory(t)
+	ctx := context.Background()
+
+	id, err := db.Create(ctx, DATABASE, COLLECTION, bson.D{{Key: "email", Value: "a@example.com"}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		t.Fatalf("Create() = %v, want a hex object id", id)
+	}
+
+	got, err := db.Get(ctx, DATABASE, COLLECTION, map[string]interface{}{document.IDFIELD: objId})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if (*got.(*bson.D))[0].Key != document.IDFIELD {
+		t.Errorf("Get() first field = %v, want %v", (*got.(*bson.D))[0].Key, document.IDFIELD)
+	}
+
+	if _, err := db.Create(ctx, DATABASE, COLLECTION, bson.D{{Key: document.IDFIELD, Value: objId}}); err == nil {
+		t.Errorf("Create() of a duplicate id succeeded")
+	}
+}
+
+func TestMemory_Conformance(t *testing.T) {
+	conformance.Run(t, func(t *testing.T) interfaces.DbClient {
+		return NewMemory(logger.NewMockClient())
+	})
+}
@@ -0,0 +1,85 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/haguru/horus/useracctdb/config"
+	appMetrics "github.com/haguru/horus/useracctdb/pkg/prometheus"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMongoDB_startOperation(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus codes.Code
+		wantErrors float64
+	}{
+		{name: "successful operation", err: nil, wantStatus: codes.Unset, wantErrors: 0},
+		{name: "failed operation", err: fmt.Errorf("document not found"), wantStatus: codes.Error, wantErrors: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			otel.SetTracerProvider(tp)
+			defer tp.Shutdown(context.Background())
+
+			parentCtx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+			metrics := appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"})
+			db := &MongoDB{Host: "useracctdb", Port: 27017, metrics: metrics}
+			_, op := db.startOperation(parentCtx, OPERATION_FIND, "horus", "crumbs")
+			op.end(tt.err)
+			parent.End()
+
+			if got := testutil.CollectAndCount(metrics.DbOperationDuration); got != 1 {
+				t.Errorf("end() recorded %v latency series, want 1", got)
+			}
+			if got := testutil.ToFloat64(metrics.DbOperationErrors.WithLabelValues(OPERATION_FIND, "crumbs")); got != tt.wantErrors {
+				t.Errorf("end() recorded %v errors, want %v", got, tt.wantErrors)
+			}
+
+			spans := exporter.GetSpans()
+			if len(spans) != 2 {
+				t.Fatalf("expected 2 spans, got %v", len(spans))
+			}
+
+			got := spans[0]
+			if got.Name != "find crumbs" {
+				t.Errorf("startOperation() name = %v, want find crumbs", got.Name)
+			}
+			if got.SpanKind != trace.SpanKindClient {
+				t.Errorf("startOperation() kind = %v, want client", got.SpanKind)
+			}
+			if got.Parent.SpanID() != parent.SpanContext().SpanID() {
+				t.Errorf("startOperation() span is not a child of the request span")
+			}
+			if got.Status.Code != tt.wantStatus {
+				t.Errorf("end() status = %v, want %v", got.Status.Code, tt.wantStatus)
+			}
+
+			attrs := attribute.NewSet(got.Attributes...)
+			wantAttrs := map[attribute.Key]string{
+				"db.system":          "mongodb",
+				"db.namespace":       "horus",
+				"db.collection.name": "crumbs",
+				"db.operation.name":  OPERATION_FIND,
+				"server.address":     "useracctdb",
+			}
+			for key, want := range wantAttrs {
+				if v, _ := attrs.Value(key); v.AsString() != want {
+					t.Errorf("startOperation() attribute %v = %v, want %v", key, v.AsString(), want)
+				}
+			}
+		})
+	}
+}
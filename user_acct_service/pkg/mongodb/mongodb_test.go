@@ -0,0 +1,28 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/haguru/horus/useracctdb/config"
+	"github.com/haguru/horus/useracctdb/pkg/interfaces"
+	"github.com/haguru/horus/useracctdb/pkg/interfaces/conformance"
+	appMetrics "github.com/haguru/horus/useracctdb/pkg/prometheus"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+)
+
+// TestMongoDB_Conformance runs against conformance.MONGODB_ENV or a local mongod, and is skipped without either
+func TestMongoDB_Conformance(t *testing.T) {
+	host, port := conformance.Mongod(t)
+
+	metrics := appMetrics.NewMetrics(&config.ServiceConfig{ServiceName: "test"})
+	db, err := NewMongoDB(host, port, logger.NewMockClient(), metrics, 5*time.Second, nil)
+	if err != nil {
+		t.Fatalf("NewMongoDB() error = %v", err)
+	}
+	defer db.Disconnect(context.Background())
+
+	conformance.Run(t, func(*testing.T) interfaces.DbClient { return db })
+}
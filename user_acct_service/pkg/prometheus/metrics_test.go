@@ -0,0 +1,135 @@
+package prometheus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/haguru/horus/useracctdb/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+func sampledContext(t *testing.T) context.Context {
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+}
+
+func TestIncWithExemplar(t *testing.T) {
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		wantExemplar bool
+	}{
+		{name: "sampled request", ctx: sampledContext(t), wantExemplar: true},
+		{name: "no trace", ctx: context.Background(), wantExemplar: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_total"})
+			IncWithExemplar(tt.ctx, counter)
+
+			m := &dto.Metric{}
+			if err := counter.Write(m); err != nil {
+				t.Fatal(err)
+			}
+			if m.GetCounter().GetValue() != 1 {
+				t.Errorf("IncWithExemplar() value = %v, want 1", m.GetCounter().GetValue())
+			}
+			if got := m.GetCounter().GetExemplar() != nil; got != tt.wantExemplar {
+				t.Fatalf("IncWithExemplar() exemplar = %v, want %v", got, tt.wantExemplar)
+			}
+			if tt.wantExemplar && m.GetCounter().GetExemplar().GetLabel()[0].GetValue() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+				t.Errorf("IncWithExemplar() exemplar label = %v", m.GetCounter().GetExemplar().GetLabel())
+			}
+		})
+	}
+}
+
+func TestObserveWithExemplar(t *testing.T) {
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_seconds", Buckets: DB_BUCKETS})
+	ObserveWithExemplar(sampledContext(t), histogram, 0.002)
+
+	m := &dto.Metric{}
+	if err := histogram.Write(m); err != nil {
+		t.Fatal(err)
+	}
+	if m.GetHistogram().GetSampleCount() != 1 {
+		t.Errorf("ObserveWithExemplar() count = %v, want 1", m.GetHistogram().GetSampleCount())
+	}
+
+	found := false
+	for _, bucket := range m.GetHistogram().GetBucket() {
+		if bucket.GetExemplar() != nil {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("ObserveWithExemplar() did not attach an exemplar")
+	}
+}
+
+func TestMetrics_Auth(t *testing.T) {
+	tests := []struct {
+		name       string
+		md         metadata.MD
+		wantReason string
+		wantErr    bool
+	}{
+		{name: "valid token", md: metadata.Pairs("authorization", "bearer yolo"), wantReason: "", wantErr: false},
+		{name: "missing token", md: metadata.MD{}, wantReason: LOGIN_FAILURE_MISSING_TOKEN, wantErr: true},
+		{name: "invalid token", md: metadata.Pairs("authorization", "bearer nope"), wantReason: LOGIN_FAILURE_INVALID_TOKEN, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMetrics(&config.ServiceConfig{ServiceName: "test"})
+
+			_, err := m.Auth(metadata.NewIncomingContext(context.Background(), tt.md))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Metrics.Auth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantReason == "" {
+				if got := testutil.CollectAndCount(m.FailedLogins); got != 0 {
+					t.Errorf("Metrics.Auth() counted %v failed logins, want 0", got)
+				}
+				return
+			}
+			if got := testutil.ToFloat64(m.FailedLogins.WithLabelValues(tt.wantReason)); got != 1 {
+				t.Errorf("Metrics.Auth() failed logins for %v = %v, want 1", tt.wantReason, got)
+			}
+		})
+	}
+}
+
+func TestNewMetrics_RuntimeCollectors(t *testing.T) {
+	metrics := NewMetrics(&config.ServiceConfig{ServiceName: "test"})
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	found := map[string]bool{}
+	for _, family := range families {
+		found[family.GetName()] = true
+	}
+	for _, name := range []string{"go_goroutines", "go_memstats_heap_alloc_bytes", "process_resident_memory_bytes", "process_open_fds"} {
+		if !found[name] {
+			t.Errorf("Registry is missing %v", name)
+		}
+	}
+}
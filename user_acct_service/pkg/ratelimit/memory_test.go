@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/haguru/horus/useracctdb/config"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMemoryStore()
+	m.now = func() time.Time { return now }
+	limit := config.Limit{Rate: 2, Burst: 2}
+
+	steps := []struct {
+		advance        time.Duration
+		wantAllowed    bool
+		wantRetryAfter time.Duration
+	}{
+		{advance: 0, wantAllowed: true},
+		{advance: 0, wantAllowed: true},
+		{advance: 0, wantAllowed: false, wantRetryAfter: 500 * time.Millisecond},
+		{advance: 250 * time.Millisecond, wantAllowed: false, wantRetryAfter: 250 * time.Millisecond},
+		{advance: 250 * time.Millisecond, wantAllowed: true},
+		// refill never exceeds the burst
+		{advance: time.Hour, wantAllowed: true},
+		{advance: 0, wantAllowed: true},
+		{advance: 0, wantAllowed: false, wantRetryAfter: 500 * time.Millisecond},
+	}
+	for i, step := range steps {
+		now = now.Add(step.advance)
+		allowed, retryAfter, err := m.Take(context.Background(), "key", limit)
+		if err != nil {
+			t.Fatalf("step %v: Take() error = %v", i, err)
+		}
+		if allowed != step.wantAllowed || retryAfter != step.wantRetryAfter {
+			t.Errorf("step %v: Take() = %v, %v, want %v, %v", i, allowed, retryAfter, step.wantAllowed, step.wantRetryAfter)
+		}
+	}
+}
+
+func TestMemoryStore_sweep(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMemoryStore()
+	m.lastSweep = now
+	m.now = func() time.Time { return now }
+
+	if _, _, err := m.Take(context.Background(), "idle", config.Limit{Rate: 1, Burst: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := m.Take(context.Background(), "busy", config.Limit{Rate: 0.001, Burst: 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(SWEEP_INTERVAL)
+	if _, _, err := m.Take(context.Background(), "new", config.Limit{Rate: 1, Burst: 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := m.buckets["idle"]; ok {
+		t.Errorf("sweep() kept the refilled bucket")
+	}
+	if _, ok := m.buckets["busy"]; !ok {
+		t.Errorf("sweep() dropped a bucket that is not full")
+	}
+}
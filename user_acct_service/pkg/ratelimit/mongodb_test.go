@@ -0,0 +1,49 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/haguru/horus/useracctdb/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestMongoStore_Take(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	tests := []struct {
+		name           string
+		bucket         bson.D
+		wantAllowed    bool
+		wantRetryAfter time.Duration
+	}{
+		{
+			name:        "token taken",
+			bucket:      bson.D{{Key: "_id", Value: "key"}, {Key: FIELD_TOKENS, Value: 1.0}, {Key: FIELD_ALLOWED, Value: true}},
+			wantAllowed: true,
+		},
+		{
+			name:           "bucket empty",
+			bucket:         bson.D{{Key: "_id", Value: "key"}, {Key: FIELD_TOKENS, Value: 0.5}, {Key: FIELD_ALLOWED, Value: false}},
+			wantAllowed:    false,
+			wantRetryAfter: 250 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: tt.bucket}})
+			m := &MongoStore{collection: mt.Coll}
+
+			allowed, retryAfter, err := m.Take(context.Background(), "key", config.Limit{Rate: 2, Burst: 5})
+			if err != nil {
+				t.Fatalf("Take() error = %v", err)
+			}
+			if allowed != tt.wantAllowed || retryAfter != tt.wantRetryAfter {
+				t.Errorf("Take() = %v, %v, want %v, %v", allowed, retryAfter, tt.wantAllowed, tt.wantRetryAfter)
+			}
+		})
+	}
+}
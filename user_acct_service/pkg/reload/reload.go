@@ -0,0 +1,208 @@
+package reload
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/haguru/horus/useracctdb/config"
+	"github.com/haguru/horus/useracctdb/pkg/consul"
+	"github.com/haguru/horus/useracctdb/pkg/healthcheck"
+	appMetrics "github.com/haguru/horus/useracctdb/pkg/prometheus"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	RESULT_SUCCESS  = "success"
+	RESULT_REJECTED = "rejected"
+	RESULT_FAILED   = "failed"
+)
+
+// Reloader watches the local config file and the Consul KV store and applies
+// the settings that are safe to change while the service is running.
+// Settings that require a restart are rejected with a warning
+type Reloader struct {
+	configPath string
+	consul     *consul.Consul
+	current    *config.ServiceConfig
+	health     *healthcheck.HealthCheck
+	kvOverlay  []byte
+	lc         logger.LoggingClient
+	metrics    *appMetrics.Metrics
+	validator  *validator.Validate
+	mu         sync.Mutex
+}
+
+func NewReloader(configPath string, current *config.ServiceConfig, lc logger.LoggingClient, validator *validator.Validate, metrics *appMetrics.Metrics, consul *consul.Consul, health *healthcheck.HealthCheck) *Reloader {
+	running := *current
+
+	return &Reloader{
+		configPath: configPath,
+		consul:     consul,
+		current:    &running,
+		health:     health,
+		lc:         lc,
+		metrics:    metrics,
+		validator:  validator,
+	}
+}
+
+// Current returns a copy of the configuration the service is running with
+func (r *Reloader) Current() config.ServiceConfig {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return *r.current
+}
+
+// Start watches the config file, and the service key under the Consul KV prefix when one is configured,
+// until ctx is done
+func (r *Reloader) Start(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config file watcher: %v", err)
+	}
+	defer watcher.Close()
+
+	// watch the directory so the file is still tracked when editors or
+	// orchestrators replace it rather than writing to it
+	err = watcher.Add(filepath.Dir(r.configPath))
+	if err != nil {
+		return fmt.Errorf("failed to watch config file: %v", err)
+	}
+
+	current := r.Current()
+	if current.Consul.KVPrefix != "" {
+		key := fmt.Sprintf("%v/%v", current.Consul.KVPrefix, current.ServiceName)
+		go func() {
+			err := r.consul.WatchKey(ctx, key, r.onKVChange)
+			if err != nil && ctx.Err() == nil {
+				r.lc.Errorf("stopped watching consul key '%v': %v", key, err)
+			}
+		}()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) != filepath.Clean(r.configPath) {
+				continue
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			r.lc.Debugf("config file %v changed", event.Name)
+			r.Reload()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			r.lc.Errorf("config file watcher error: %v", err)
+		}
+	}
+}
+
+func (r *Reloader) onKVChange(value []byte) {
+	r.mu.Lock()
+	r.kvOverlay = value
+	r.mu.Unlock()
+
+	r.lc.Debug("consul config changed")
+	r.Reload()
+}
+
+// Reload reads the config file, applies the Consul overlay on top of it and
+// applies every reloadable setting that changed
+func (r *Reloader) Reload() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := r.load()
+	if err != nil {
+		r.lc.Errorf("failed to reload config: %v", err)
+		r.metrics.ConfigReloads.WithLabelValues(RESULT_FAILED).Inc()
+		return
+	}
+
+	result := RESULT_SUCCESS
+	applied := *r.current
+	for _, setting := range r.current.Changed(next) {
+		if !config.IsReloadable(setting) {
+			r.lc.Warnf("rejected change to '%v': restart the service to apply it", setting)
+			result = RESULT_REJECTED
+			continue
+		}
+
+		err = r.apply(setting, next, &applied)
+		if err != nil {
+			r.lc.Errorf("failed to apply '%v': %v", setting, err)
+			result = RESULT_FAILED
+			continue
+		}
+		r.lc.Infof("applied new value for '%v'", setting)
+	}
+	r.current = &applied
+
+	r.metrics.ConfigReloads.WithLabelValues(result).Inc()
+	if result == RESULT_SUCCESS {
+		r.metrics.LastConfigReload.SetToCurrentTime()
+	}
+}
+
+func (r *Reloader) load() (*config.ServiceConfig, error) {
+	next, err := config.ReadLocalConfig(r.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(r.kvOverlay) > 0 {
+		next, err = next.MergeYAML(r.kvOverlay)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse consul config: %v", err)
+		}
+	}
+
+	err = r.validator.Struct(next)
+	if err != nil {
+		return nil, fmt.Errorf("validation error: %v", err)
+	}
+
+	return next, nil
+}
+
+func (r *Reloader) apply(setting string, next *config.ServiceConfig, applied *config.ServiceConfig) error {
+	switch setting {
+	case config.SETTING_LOG_LEVEL:
+		err := r.lc.SetLogLevel(next.LogLevel)
+		if err != nil {
+			return fmt.Errorf("invalid log level %v", next.LogLevel)
+		}
+		applied.LogLevel = next.LogLevel
+
+	case config.SETTING_PING_INTERVAL:
+		pingInterval, err := time.ParseDuration(next.Database.PingInterval)
+		if err != nil {
+			return err
+		}
+		if pingInterval <= 0 {
+			return fmt.Errorf("ping interval must be positive")
+		}
+		r.health.SetPingInterval(pingInterval)
+		applied.Database.PingInterval = next.Database.PingInterval
+
+	default:
+		return fmt.Errorf("%v is not reloadable", setting)
+	}
+
+	return nil
+}
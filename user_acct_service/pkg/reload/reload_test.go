@@ -0,0 +1,156 @@
+package reload
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/haguru/horus/useracctdb/config"
+	"github.com/haguru/horus/useracctdb/pkg/healthcheck"
+	appMetrics "github.com/haguru/horus/useracctdb/pkg/prometheus"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/go-playground/validator/v10"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gopkg.in/yaml.v3"
+)
+
+const BASE_CONFIG_PATH = "../../res/config.yaml"
+
+// recordingLogger records the warnings and the log level set on it
+type recordingLogger struct {
+	logger.MockLogger
+	level    string
+	warnings []string
+}
+
+func (lc *recordingLogger) SetLogLevel(level string) error {
+	lc.level = level
+	return nil
+}
+
+func (lc *recordingLogger) Warnf(msg string, args ...interface{}) {
+	lc.warnings = append(lc.warnings, fmt.Sprintf(msg, args...))
+}
+
+// writeConfig writes the base config with overlay applied on top of it to path
+func writeConfig(t *testing.T, path string, base *config.ServiceConfig, overlay string) {
+	t.Helper()
+
+	next, err := base.MergeYAML([]byte(overlay))
+	if err != nil {
+		t.Fatalf("MergeYAML() error = %v", err)
+	}
+	data, err := yaml.Marshal(next)
+	if err != nil {
+		t.Fatalf("yaml.Marshal() error = %v", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloader_Reload(t *testing.T) {
+	base, err := config.ReadLocalConfig(BASE_CONFIG_PATH)
+	if err != nil {
+		t.Fatalf("ReadLocalConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		overlay      string
+		content      string
+		wantResult   string
+		wantWarning  bool
+		wantLogLevel string
+		wantPing     string
+	}{
+		{
+			name:         "log level",
+			overlay:      "loglevel: ERROR",
+			wantResult:   RESULT_SUCCESS,
+			wantLogLevel: "ERROR",
+		},
+		{
+			name:       "ping interval",
+			overlay:    "database: {ping_interval: 1m}",
+			wantResult: RESULT_SUCCESS,
+			wantPing:   "1m",
+		},
+		{
+			name:         "non reloadable setting",
+			overlay:      "{port: 1234, loglevel: ERROR}",
+			wantResult:   RESULT_REJECTED,
+			wantWarning:  true,
+			wantLogLevel: "ERROR",
+		},
+		{
+			name:       "invalid yaml",
+			content:    "loglevel: [",
+			wantResult: RESULT_FAILED,
+		},
+		{
+			name:       "invalid config",
+			overlay:    "loglevel: ''",
+			wantResult: RESULT_FAILED,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			writeConfig(t, path, base, "")
+
+			lc := &recordingLogger{}
+			metrics := appMetrics.NewMetrics(base)
+			health, err := healthcheck.NewHealthCheck(base, metrics, time.Second)
+			if err != nil {
+				t.Fatalf("NewHealthCheck() error = %v", err)
+			}
+			r := NewReloader(path, base, lc, validator.New(), metrics, nil, health)
+
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				writeConfig(t, path, base, tt.overlay)
+			}
+			r.Reload()
+
+			for _, result := range []string{RESULT_SUCCESS, RESULT_REJECTED, RESULT_FAILED} {
+				want := 0.0
+				if result == tt.wantResult {
+					want = 1
+				}
+				if got := testutil.ToFloat64(metrics.ConfigReloads.WithLabelValues(result)); got != want {
+					t.Errorf("ConfigReloads{result=%v} = %v, want %v", result, got, want)
+				}
+			}
+			if got := testutil.ToFloat64(metrics.LastConfigReload) != 0; got != (tt.wantResult == RESULT_SUCCESS) {
+				t.Errorf("LastConfigReload set = %v, want %v", got, tt.wantResult == RESULT_SUCCESS)
+			}
+			if got := len(lc.warnings) > 0; got != tt.wantWarning {
+				t.Errorf("warnings = %v, want warning %v", lc.warnings, tt.wantWarning)
+			}
+
+			want := *base
+			if tt.wantLogLevel != "" {
+				want.LogLevel = tt.wantLogLevel
+			}
+			if lc.level != tt.wantLogLevel {
+				t.Errorf("log level set = %q, want %q", lc.level, tt.wantLogLevel)
+			}
+			if tt.wantPing != "" {
+				want.Database.PingInterval = tt.wantPing
+			}
+
+			// settings that were rejected or failed keep their previous value
+			current := r.Current()
+			if len(current.Changed(&want)) > 0 {
+				t.Errorf("Current() differs from the expected config on %v", current.Changed(&want))
+			}
+		})
+	}
+}
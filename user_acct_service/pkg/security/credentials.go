@@ -0,0 +1,57 @@
+package security
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// REJECTED_MISSING_CERTIFICATE and REJECTED_INVALID_CERTIFICATE are the reasons ServerCredentials reports the
+// clients it rejects with
+const (
+	REJECTED_MISSING_CERTIFICATE = "missing_certificate"
+	REJECTED_INVALID_CERTIFICATE = "invalid_certificate"
+)
+
+// ErrNoClientCertificate fails the handshake of a client without a certificate when mutual tls is required
+var ErrNoClientCertificate = errors.New("tls: client didn't provide a certificate")
+
+// rejectingCredentials are tls transport credentials reporting the handshakes which rejected the client
+type rejectingCredentials struct {
+	credentials.TransportCredentials
+	rejected func(reason string)
+}
+
+// ServerCredentials returns the grpc transport credentials of tlsConfig, calling rejected with the reason of every
+// handshake which failed to authenticate the client. Other failures, such as connections closed during the
+// handshake, are not reported
+func ServerCredentials(tlsConfig *tls.Config, rejected func(reason string)) credentials.TransportCredentials {
+	return &rejectingCredentials{TransportCredentials: credentials.NewTLS(tlsConfig), rejected: rejected}
+}
+
+func (c *rejectingCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	secured, info, err := c.TransportCredentials.ServerHandshake(conn)
+	if reason, ok := rejectedReason(err); ok {
+		c.rejected(reason)
+	}
+	return secured, info, err
+}
+
+func (c *rejectingCredentials) Clone() credentials.TransportCredentials {
+	return &rejectingCredentials{TransportCredentials: c.TransportCredentials.Clone(), rejected: c.rejected}
+}
+
+// rejectedReason returns the reason a handshake failing with err rejected the client, false if it failed for
+// another reason
+func rejectedReason(err error) (string, bool) {
+	var verificationErr *tls.CertificateVerificationError
+	switch {
+	case errors.Is(err, ErrNoClientCertificate):
+		return REJECTED_MISSING_CERTIFICATE, true
+	case errors.As(err, &verificationErr):
+		return REJECTED_INVALID_CERTIFICATE, true
+	}
+	return "", false
+}
@@ -0,0 +1,106 @@
+package security
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/haguru/horus/useracctdb/config"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+)
+
+func TestServerCredentials(t *testing.T) {
+	dir := t.TempDir()
+	writeCert(t, dir, "useracct_service")
+	server, err := NewCertReloader(&config.TLS{
+		CertFile:     filepath.Join(dir, "server.crt"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+		MutualTLS:    true,
+	}, logger.NewMockClient())
+	if err != nil {
+		t.Fatalf("NewCertReloader() error = %v", err)
+	}
+	trusted, err := tls.LoadX509KeyPair(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherDir := t.TempDir()
+	writeCert(t, otherDir, "other")
+	untrusted, err := tls.LoadX509KeyPair(filepath.Join(otherDir, "server.crt"), filepath.Join(otherDir, "server.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		cert       *tls.Certificate
+		wantReason string
+	}{
+		{name: "trusted certificate", cert: &trusted},
+		{name: "no certificate", wantReason: REJECTED_MISSING_CERTIFICATE},
+		{name: "untrusted certificate", cert: &untrusted, wantReason: REJECTED_INVALID_CERTIFICATE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverConfig, err := server.ServerTLSConfig(ALPN_HTTP2)
+			if err != nil {
+				t.Fatalf("ServerTLSConfig() error = %v", err)
+			}
+			var reasons []string
+			creds := ServerCredentials(serverConfig, func(reason string) { reasons = append(reasons, reason) }).Clone()
+
+			// a tcp connection buffers the alert of the server, which a net.Pipe would block on
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer listener.Close()
+			clientConn, err := net.Dial("tcp", listener.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer clientConn.Close()
+			serverConn, err := listener.Accept()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer serverConn.Close()
+
+			client := tls.Client(clientConn, &tls.Config{
+				InsecureSkipVerify: true, // #nosec G402
+				NextProtos:         []string{ALPN_HTTP2},
+				GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+					if tt.cert == nil {
+						return &tls.Certificate{}, nil
+					}
+					return tt.cert, nil
+				},
+			})
+			go func() {
+				// with tls 1.3 the client is done before the server rejects its certificate
+				if client.Handshake() == nil {
+					_, _ = io.Copy(io.Discard, client)
+				}
+			}()
+
+			_, _, err = creds.ServerHandshake(serverConn)
+			if (err != nil) != (tt.wantReason != "") {
+				t.Fatalf("ServerHandshake() error = %v, want reason %q", err, tt.wantReason)
+			}
+			if tt.wantReason == "" {
+				if len(reasons) != 0 {
+					t.Errorf("rejected with %v, want none", reasons)
+				}
+				return
+			}
+			if len(reasons) != 1 || reasons[0] != tt.wantReason {
+				t.Errorf("rejected with %v, want %v", reasons, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/haguru/horus/useracctdb/config"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	DEFAULT_MIN_VERSION = "1.2"
+	ALPN_HTTP2          = "h2"
+)
+
+var TLS_VERSIONS = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// CertReloader keeps the server certificate and the client CA pool in sync with the files on disk
+// so certificates can be rotated without restarting the service
+type CertReloader struct {
+	config    *config.TLS
+	lc        logger.LoggingClient
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	mu        sync.RWMutex
+}
+
+// NewCertReloader returns a CertReloader with the certificates loaded and error if the settings are
+// incomplete or the certificates cannot be loaded
+func NewCertReloader(config *config.TLS, lc logger.LoggingClient) (*CertReloader, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, fmt.Errorf("cert_file and key_file are required when tls is enabled")
+	}
+	if config.MutualTLS && config.ClientCAFile == "" {
+		return nil, fmt.Errorf("client_ca_file is required when mutual_tls is enabled")
+	}
+
+	c := &CertReloader{
+		config: config,
+		lc:     lc,
+	}
+	err := c.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Reload reads the certificate, key and client CA files. The previous certificates are kept if an error occurs
+func (c *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(c.config.CertFile, c.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load key pair: %v", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if c.config.ClientCAFile != "" {
+		pem, err := os.ReadFile(c.config.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA file: %v", err)
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in client CA file %v", c.config.ClientCAFile)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cert = &cert
+	c.clientCAs = clientCAs
+
+	return nil
+}
+
+// Watch reloads the certificates every time a file in their directories changes. Watch blocks until ctx is done
+func (c *CertReloader) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create certificate watcher: %v", err)
+	}
+	defer watcher.Close()
+
+	// directories are watched so rotations done by swapping symlinks,
+	// as Kubernetes does for mounted secrets, are picked up as well
+	for _, file := range []string{c.config.CertFile, c.config.KeyFile, c.config.ClientCAFile} {
+		if file == "" {
+			continue
+		}
+		err = watcher.Add(filepath.Dir(file))
+		if err != nil {
+			return fmt.Errorf("failed to watch %v: %v", file, err)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			err = c.Reload()
+			if err != nil {
+				// the cert and key are usually written one after the other,
+				// the next event will pick up the complete pair
+				c.lc.Debugf("certificates not reloaded: %v", err)
+				continue
+			}
+			c.lc.Info("reloaded tls certificates")
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			c.lc.Errorf("certificate watcher error: %v", err)
+		}
+	}
+}
+
+// ServerTLSConfig returns a tls config that always serves the latest certificates.
+// nextProtos are the ALPN protocols announced to clients
+func (c *CertReloader) ServerTLSConfig(nextProtos ...string) (*tls.Config, error) {
+	minVersion, err := ParseTLSVersion(c.config.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	clientAuth := tls.NoClientCert
+	if c.config.MutualTLS || c.config.ClientCAFile != "" {
+		clientAuth = tls.VerifyClientCertIfGiven
+	}
+	// mutual tls requires the certificate here rather than with tls.RequireAndVerifyClientCert, so a missing
+	// certificate fails the handshake with ErrNoClientCertificate
+	var verifyConnection func(tls.ConnectionState) error
+	if c.config.MutualTLS {
+		verifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return ErrNoClientCertificate
+			}
+			return nil
+		}
+	}
+
+	return &tls.Config{
+		MinVersion:     minVersion,
+		NextProtos:     nextProtos,
+		GetCertificate: c.getCertificate,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			c.mu.RLock()
+			defer c.mu.RUnlock()
+
+			return &tls.Config{
+				MinVersion:       minVersion,
+				NextProtos:       nextProtos,
+				Certificates:     []tls.Certificate{*c.cert},
+				ClientAuth:       clientAuth,
+				ClientCAs:        c.clientCAs,
+				VerifyConnection: verifyConnection,
+			}, nil
+		},
+	}, nil
+}
+
+// LoopbackTLSConfig returns a tls config for connections from the service to its own gRPC server, e.g. from the
+// REST gateway. The server must present the service certificate, which is also sent as the client certificate
+func (c *CertReloader) LoopbackTLSConfig() (*tls.Config, error) {
+	minVersion, err := ParseTLSVersion(c.config.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		MinVersion: minVersion,
+		// the server certificate is pinned by VerifyPeerCertificate instead of being verified against a CA
+		InsecureSkipVerify: true, // #nosec G402
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			cert, _ := c.getCertificate(nil)
+			if len(rawCerts) == 0 || !bytes.Equal(rawCerts[0], cert.Certificate[0]) {
+				return fmt.Errorf("server did not present the service certificate")
+			}
+			return nil
+		},
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return c.getCertificate(nil)
+		},
+	}, nil
+}
+
+func (c *CertReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.cert, nil
+}
+
+// ParseTLSVersion returns the tls version matching version, e.g. "1.2". An empty version returns DEFAULT_MIN_VERSION
+func ParseTLSVersion(version string) (uint16, error) {
+	if version == "" {
+		version = DEFAULT_MIN_VERSION
+	}
+
+	v, ok := TLS_VERSIONS[version]
+	if !ok {
+		return 0, fmt.Errorf("tls version %v not supported", version)
+	}
+
+	return v, nil
+}
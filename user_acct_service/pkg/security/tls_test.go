@@ -0,0 +1,293 @@
+package security
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/haguru/horus/useracctdb/config"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+)
+
+// writeCert writes a self-signed certificate and its key to dir and returns the certificate
+func writeCert(t *testing.T, dir string, commonName string, uris ...*url.URL) *x509.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatalf("failed to generate serial: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		URIs:                  uris,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	if err := os.WriteFile(filepath.Join(dir, "server.crt"), certPem, 0o600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "server.key"), keyPem, 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ca.crt"), certPem, 0o600); err != nil {
+		t.Fatalf("failed to write ca: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return cert
+}
+
+func TestNewCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	writeCert(t, dir, "useracct_service")
+
+	tests := []struct {
+		name    string
+		config  *config.TLS
+		wantErr bool
+	}{
+		{
+			name: "server certificate only",
+			config: &config.TLS{
+				CertFile: filepath.Join(dir, "server.crt"),
+				KeyFile:  filepath.Join(dir, "server.key"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "mutual tls",
+			config: &config.TLS{
+				CertFile:     filepath.Join(dir, "server.crt"),
+				KeyFile:      filepath.Join(dir, "server.key"),
+				ClientCAFile: filepath.Join(dir, "ca.crt"),
+				MutualTLS:    true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "mutual tls without client ca",
+			config: &config.TLS{
+				CertFile:  filepath.Join(dir, "server.crt"),
+				KeyFile:   filepath.Join(dir, "server.key"),
+				MutualTLS: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing key file",
+			config: &config.TLS{
+				CertFile: filepath.Join(dir, "server.crt"),
+				KeyFile:  filepath.Join(dir, "missing.key"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCertReloader(tt.config, logger.NewMockClient())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewCertReloader() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCertReloader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	first := writeCert(t, dir, "first")
+
+	certs, err := NewCertReloader(&config.TLS{
+		CertFile:     filepath.Join(dir, "server.crt"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+		MutualTLS:    true,
+	}, logger.NewMockClient())
+	if err != nil {
+		t.Fatalf("NewCertReloader() error = %v", err)
+	}
+
+	tlsConfig, err := certs.ServerTLSConfig(ALPN_HTTP2)
+	if err != nil {
+		t.Fatalf("CertReloader.ServerTLSConfig() error = %v", err)
+	}
+
+	served, err := tlsConfig.GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatalf("GetConfigForClient() error = %v", err)
+	}
+	if !bytes.Equal(served.Certificates[0].Certificate[0], first.Raw) {
+		t.Errorf("GetConfigForClient() served an unexpected certificate")
+	}
+	if served.ClientAuth != tls.VerifyClientCertIfGiven || served.VerifyConnection == nil {
+		t.Errorf("GetConfigForClient() ClientAuth = %v, want %v requiring a certificate", served.ClientAuth, tls.VerifyClientCertIfGiven)
+	}
+
+	second := writeCert(t, dir, "second")
+	if err := certs.Reload(); err != nil {
+		t.Fatalf("CertReloader.Reload() error = %v", err)
+	}
+
+	served, err = tlsConfig.GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatalf("GetConfigForClient() error = %v", err)
+	}
+	if !bytes.Equal(served.Certificates[0].Certificate[0], second.Raw) {
+		t.Errorf("GetConfigForClient() did not serve the rotated certificate")
+	}
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		want    uint16
+		wantErr bool
+	}{
+		{name: "default", version: "", want: tls.VersionTLS12, wantErr: false},
+		{name: "tls 1.3", version: "1.3", want: tls.VersionTLS13, wantErr: false},
+		{name: "unsupported", version: "2.0", want: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTLSVersion(tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseTLSVersion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseTLSVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentityFromCert(t *testing.T) {
+	spiffeID, _ := url.Parse("spiffe://horus/ns/default/sa/useracct")
+	otherURI, _ := url.Parse("https://horus.example")
+
+	tests := []struct {
+		name string
+		uris []*url.URL
+		want Identity
+	}{
+		{
+			name: "spiffe id",
+			uris: []*url.URL{otherURI, spiffeID},
+			want: Identity{SpiffeID: "spiffe://horus/ns/default/sa/useracct", Subject: "client"},
+		},
+		{
+			name: "no spiffe id",
+			uris: []*url.URL{otherURI},
+			want: Identity{SpiffeID: "", Subject: "client"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := writeCert(t, t.TempDir(), "client", tt.uris...)
+			if got := identityFromCert(cert); *got != tt.want {
+				t.Errorf("identityFromCert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCertReloader_LoopbackTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeCert(t, dir, "useracct_service")
+	server, err := NewCertReloader(&config.TLS{
+		CertFile:     filepath.Join(dir, "server.crt"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+		MutualTLS:    true,
+	}, logger.NewMockClient())
+	if err != nil {
+		t.Fatalf("NewCertReloader() error = %v", err)
+	}
+
+	otherDir := t.TempDir()
+	writeCert(t, otherDir, "other")
+	other, err := NewCertReloader(&config.TLS{
+		CertFile: filepath.Join(otherDir, "server.crt"),
+		KeyFile:  filepath.Join(otherDir, "server.key"),
+	}, logger.NewMockClient())
+	if err != nil {
+		t.Fatalf("NewCertReloader() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		client  *CertReloader
+		wantErr bool
+	}{
+		{name: "same certificate", client: server, wantErr: false},
+		{name: "different certificate", client: other, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverConfig, err := server.ServerTLSConfig()
+			if err != nil {
+				t.Fatalf("ServerTLSConfig() error = %v", err)
+			}
+			clientConfig, err := tt.client.LoopbackTLSConfig()
+			if err != nil {
+				t.Fatalf("LoopbackTLSConfig() error = %v", err)
+			}
+
+			serverConn, clientConn := net.Pipe()
+			defer serverConn.Close()
+			defer clientConn.Close()
+
+			serverErr := make(chan error, 1)
+			go func() {
+				serverErr <- tls.Server(serverConn, serverConfig).Handshake()
+			}()
+			err = tls.Client(clientConn, clientConfig).Handshake()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Handshake() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				// unblock the server side of the handshake
+				clientConn.Close()
+			}
+			if err := <-serverErr; err != nil && !tt.wantErr {
+				t.Errorf("server Handshake() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/haguru/horus/useracctdb/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	EXPORTER_OTLP_GRPC = "otlpgrpc"
+	EXPORTER_OTLP_HTTP = "otlphttp"
+
+	// TRACER_NAME is the instrumentation scope used for spans created by the service
+	TRACER_NAME = "github.com/haguru/horus/useracctdb"
+)
+
+// NewExporter returns an OTLP span exporter for the configured protocol and error if the exporter is unknown
+func NewExporter(ctx context.Context, config *config.Tracing) (sdktrace.SpanExporter, error) {
+	switch config.Exporter {
+	case EXPORTER_OTLP_GRPC, "":
+		opts := []otlptracegrpc.Option{}
+		if config.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case EXPORTER_OTLP_HTTP:
+		opts := []otlptracehttp.Option{}
+		if config.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %v", config.Exporter)
+	}
+}
+
+// NewTracerProvider returns a TracerProvider that samples a ratio of new traces, follows the sampling decision
+// of incoming traces and batches spans to the exporter. The provider and the W3C trace context propagator
+// are installed globally
+func NewTracerProvider(config *config.Tracing, serviceName string, exporter sdktrace.SpanExporter) (*sdktrace.TracerProvider, error) {
+	attrs := []attribute.KeyValue{semconv.ServiceName(serviceName)}
+	for key, value := range config.Attributes {
+		attrs = append(attrs, attribute.String(key, value))
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attrs...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %v", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(res),
+		sdktrace.WithBatcher(exporter),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return tp, nil
+}